@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+)
+
+// Resultado final de la saga publicado por Order
+const (
+	RKOrderPaid   = "order.paid"
+	RKOrderFailed = "order.failed"
+)
+
+type OrderOutcomePayload struct {
+	OrderID    int64  `json:"order_id"`
+	UserID     int64  `json:"user_id"`
+	CheckoutID string `json:"checkout_id"`
+	Status     string `json:"status"`
+}
+
+// StartConsumers resuelve los checkouts congelados según el resultado de la orden.
+func (s *CartServer) StartConsumers(rb *Rabbit) error {
+	const queue = "cart-service"
+	if err := rb.ConsumeTopic(queue, []string{RKOrderPaid, RKOrderFailed}, s.handleEvent); err != nil {
+		return err
+	}
+	metrics.QueueDepth(rb.QueueDepth, queue)
+	return nil
+}
+
+func (s *CartServer) handleEvent(ctx context.Context, rk string, body []byte) error {
+	var p OrderOutcomePayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		slog.ErrorContext(ctx, "payload inválido", "rk", rk, "err", err)
+		return nil // descartamos: reencolar no lo arregla
+	}
+	if p.CheckoutID == "" {
+		return nil
+	}
+	switch rk {
+	case RKOrderPaid:
+		slog.InfoContext(ctx, "checkout completado", "checkout_id", p.CheckoutID, "order_id", p.OrderID)
+		return s.repo.CompleteCheckout(ctx, p.CheckoutID)
+	case RKOrderFailed:
+		slog.InfoContext(ctx, "checkout restaurado", "checkout_id", p.CheckoutID, "order_id", p.OrderID, "status", p.Status)
+		return s.repo.RestoreCheckout(ctx, p.CheckoutID)
+	}
+	return nil
+}
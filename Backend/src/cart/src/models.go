@@ -13,6 +13,22 @@ type CartItem struct {
 	Title          string
 	UnitPriceCents int64
 	Qty            int32
+	Category       string
+	WeightGrams    int32
+}
+
+// Estados del snapshot de checkout
+const (
+	CheckoutFrozen    = "FROZEN"
+	CheckoutCompleted = "COMPLETED"
+	CheckoutRestored  = "RESTORED"
+)
+
+type Checkout struct {
+	ID     string
+	UserID int64
+	State  string
+	Items  []CartItem
 }
 
 type Money struct{ Cents int64 }
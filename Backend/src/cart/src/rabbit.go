@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
+)
+
+type Rabbit struct {
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+	exchange string
+}
+
+func NewRabbit(url, exchange string) (*Rabbit, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := ch.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &Rabbit{conn: conn, ch: ch, exchange: exchange}, nil
+}
+
+func (r *Rabbit) Close() {
+	if r.ch != nil {
+		_ = r.ch.Close()
+	}
+	if r.conn != nil {
+		_ = r.conn.Close()
+	}
+}
+
+// QueueDepth lee del broker los mensajes en espera de queue (pkg/metrics). Usa
+// un canal propio: la declaración pasiva de una cola inexistente cierra el canal.
+func (r *Rabbit) QueueDepth(queue string) (int, error) {
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return 0, err
+	}
+	return q.Messages, nil
+}
+
+// ConsumerHandler recibe el contexto con el correlation_id y el span del mensaje.
+type ConsumerHandler func(ctx context.Context, rk string, body []byte) error
+
+// ConsumeTopic confirma cada mensaje solo si el handler no falla; si falla lo reencola.
+func (r *Rabbit) ConsumeTopic(queueName string, bindings []string, handler ConsumerHandler) error {
+	q, err := r.ch.QueueDeclare(queueName, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	for _, rk := range bindings {
+		if err := r.ch.QueueBind(q.Name, rk, r.exchange, false, nil); err != nil {
+			return err
+		}
+	}
+	msgs, err := r.ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for d := range msgs {
+			ctx := logging.FromHeaders(context.Background(), d.Headers)
+			ctx, span := tracing.StartConsume(ctx, d.Headers, queueName, d.RoutingKey)
+			metrics.Received(queueName, d.RoutingKey, d.Timestamp)
+			err := handler(ctx, d.RoutingKey, d.Body)
+			tracing.End(span, err)
+			metrics.Consumed(queueName, d.RoutingKey, err)
+			if err != nil {
+				slog.ErrorContext(ctx, "handler falló; se reencola", "rk", d.RoutingKey, "err", err)
+				_ = d.Nack(false, true)
+				continue
+			}
+			_ = d.Ack(false)
+		}
+		slog.Warn("consumidor detenido", "queue", queueName)
+	}()
+	return nil
+}
@@ -7,14 +7,24 @@ import (
 	"errors"
 )
 
-var ErrNotFound = errors.New("not found")
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrCheckoutOwner = errors.New("checkout pertenece a otro usuario")
+	ErrCheckoutDone  = errors.New("checkout ya resuelto")
+)
 
 type CartRepository interface {
 	GetOrCreateCart(ctx context.Context, userID int64) (*Cart, error)
 	GetCart(ctx context.Context, userID int64) (*Cart, error)
-	AddItem(ctx context.Context, userID, bookID int64, title string, unitPriceCents int64, qty int32) (*Cart, error)
+	AddItem(ctx context.Context, userID, bookID int64, title string, unitPriceCents int64, category string, weightGrams int32, qty int32) (*Cart, error)
 	RemoveItem(ctx context.Context, userID, bookID int64, qty int32) (*Cart, error) // qty==0 => borra línea
 	Clear(ctx context.Context, userID int64) (*Cart, error)
+
+	// Checkout: congelar, completar (descartar) o restaurar las líneas al carrito
+	Snapshot(ctx context.Context, userID int64, checkoutID string) (*Checkout, error)
+	GetCheckout(ctx context.Context, checkoutID string) (*Checkout, error)
+	CompleteCheckout(ctx context.Context, checkoutID string) error
+	RestoreCheckout(ctx context.Context, checkoutID string) error
 }
 
 type sqliteRepo struct{ db *sql.DB }
@@ -60,7 +70,7 @@ func (r *sqliteRepo) GetCart(ctx context.Context, userID int64) (*Cart, error) {
 	}
 
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, cart_id, book_id, title, unit_price_cents, qty
+		SELECT id, cart_id, book_id, title, unit_price_cents, qty, category, weight_grams
 		FROM cart_items WHERE cart_id=?`, cart.ID)
 	if err != nil {
 		return nil, err
@@ -69,7 +79,7 @@ func (r *sqliteRepo) GetCart(ctx context.Context, userID int64) (*Cart, error) {
 
 	for rows.Next() {
 		var it CartItem
-		if err := rows.Scan(&it.ID, &it.CartID, &it.BookID, &it.Title, &it.UnitPriceCents, &it.Qty); err != nil {
+		if err := rows.Scan(&it.ID, &it.CartID, &it.BookID, &it.Title, &it.UnitPriceCents, &it.Qty, &it.Category, &it.WeightGrams); err != nil {
 			return nil, err
 		}
 		cart.Items = append(cart.Items, it)
@@ -77,7 +87,7 @@ func (r *sqliteRepo) GetCart(ctx context.Context, userID int64) (*Cart, error) {
 	return &cart, nil
 }
 
-func (r *sqliteRepo) AddItem(ctx context.Context, userID, bookID int64, title string, unitPriceCents int64, qty int32) (*Cart, error) {
+func (r *sqliteRepo) AddItem(ctx context.Context, userID, bookID int64, title string, unitPriceCents int64, category string, weightGrams int32, qty int32) (*Cart, error) {
 	if qty <= 0 {
 		qty = 1
 	}
@@ -87,11 +97,11 @@ func (r *sqliteRepo) AddItem(ctx context.Context, userID, bookID int64, title st
 	}
 
 	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO cart_items(cart_id, book_id, title, unit_price_cents, qty)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO cart_items(cart_id, book_id, title, unit_price_cents, qty, category, weight_grams)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(cart_id, book_id)
 		DO UPDATE SET qty = qty + excluded.qty
-	`, cart.ID, bookID, title, unitPriceCents, qty)
+	`, cart.ID, bookID, title, unitPriceCents, qty, category, weightGrams)
 	if err != nil {
 		return nil, err
 	}
@@ -161,3 +171,154 @@ func (r *sqliteRepo) Clear(ctx context.Context, userID int64) (*Cart, error) {
 	}
 	return r.GetCart(ctx, userID)
 }
+
+// Snapshot mueve las líneas del carrito a un checkout congelado en una sola transacción.
+// Si el checkout ya existe devuelve el snapshot original (reintentos de Order).
+// Un checkout RESTORED no llegó a tener orden, así que un reintento con la misma
+// clave lo vuelve a congelar con el carrito actual.
+// Con el carrito vacío no se persiste nada y se devuelve un checkout sin líneas.
+func (r *sqliteRepo) Snapshot(ctx context.Context, userID int64, checkoutID string) (*Checkout, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var owner int64
+	var state string
+	exists := true
+	err = tx.QueryRowContext(ctx, `SELECT user_id, state FROM checkouts WHERE id=?`, checkoutID).Scan(&owner, &state)
+	switch {
+	case err == sql.ErrNoRows:
+		exists = false
+	case err != nil:
+		return nil, err
+	case owner != userID:
+		return nil, ErrCheckoutOwner
+	case state == CheckoutFrozen:
+		_ = tx.Rollback()
+		return r.GetCheckout(ctx, checkoutID)
+	case state != CheckoutRestored:
+		return nil, ErrCheckoutDone
+	}
+
+	empty := &Checkout{ID: checkoutID, UserID: userID, State: CheckoutFrozen}
+	var cartID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM carts WHERE user_id=?`, userID).Scan(&cartID)
+	if err == sql.ErrNoRows {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// La primera escritura toma el lock: nada puede colarse entre copiar y borrar
+	if exists {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE checkouts SET state=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, CheckoutFrozen, checkoutID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM checkout_items WHERE checkout_id=?`, checkoutID); err != nil {
+			return nil, err
+		}
+	} else if _, err := tx.ExecContext(ctx, `INSERT INTO checkouts(id, user_id, state) VALUES (?, ?, ?)`,
+		checkoutID, userID, CheckoutFrozen); err != nil {
+		return nil, err
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO checkout_items(checkout_id, book_id, title, unit_price_cents, qty, category, weight_grams)
+		SELECT ?, book_id, title, unit_price_cents, qty, category, weight_grams FROM cart_items WHERE cart_id=?`,
+		checkoutID, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return empty, nil
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cart_items WHERE cart_id=?`, cartID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetCheckout(ctx, checkoutID)
+}
+
+// GetCheckout devuelve ErrNotFound si el checkout no existe.
+func (r *sqliteRepo) GetCheckout(ctx context.Context, checkoutID string) (*Checkout, error) {
+	var co Checkout
+	err := r.db.QueryRowContext(ctx, `SELECT id, user_id, state FROM checkouts WHERE id=?`, checkoutID).
+		Scan(&co.ID, &co.UserID, &co.State)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, book_id, title, unit_price_cents, qty, category, weight_grams
+		FROM checkout_items WHERE checkout_id=? ORDER BY id`, checkoutID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it CartItem
+		if err := rows.Scan(&it.ID, &it.BookID, &it.Title, &it.UnitPriceCents, &it.Qty, &it.Category, &it.WeightGrams); err != nil {
+			return nil, err
+		}
+		co.Items = append(co.Items, it)
+	}
+	return &co, rows.Err()
+}
+
+// CompleteCheckout descarta definitivamente las líneas congeladas (orden pagada).
+// Es idempotente: solo actúa sobre checkouts en FROZEN.
+func (r *sqliteRepo) CompleteCheckout(ctx context.Context, checkoutID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE checkouts SET state=?, updated_at=CURRENT_TIMESTAMP
+		WHERE id=? AND state=?`, CheckoutCompleted, checkoutID, CheckoutFrozen)
+	return err
+}
+
+// RestoreCheckout devuelve las líneas congeladas al carrito del usuario, sumando
+// cantidades a lo que haya agregado mientras tanto. Idempotente igual que CompleteCheckout.
+func (r *sqliteRepo) RestoreCheckout(ctx context.Context, checkoutID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE checkouts SET state=?, updated_at=CURRENT_TIMESTAMP
+		WHERE id=? AND state=?`, CheckoutRestored, checkoutID, CheckoutFrozen)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err // ya resuelto o inexistente
+	}
+
+	var userID int64
+	if err := tx.QueryRowContext(ctx, `SELECT user_id FROM checkouts WHERE id=?`, checkoutID).Scan(&userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO carts(user_id) VALUES (?) ON CONFLICT(user_id) DO NOTHING`, userID); err != nil {
+		return err
+	}
+	// "WHERE true" evita la ambigüedad de SQLite entre INSERT ... SELECT y ON CONFLICT
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO cart_items(cart_id, book_id, title, unit_price_cents, qty, category, weight_grams)
+		SELECT (SELECT id FROM carts WHERE user_id=?), book_id, title, unit_price_cents, qty, category, weight_grams
+		FROM checkout_items WHERE checkout_id=? AND true
+		ON CONFLICT(cart_id, book_id)
+		DO UPDATE SET qty = qty + excluded.qty`, userID, checkoutID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
+)
+
+func newTestRepo(t *testing.T) CartRepository {
+	t.Helper()
+	db, err := tracing.OpenSQLite("sqlite", filepath.Join(t.TempDir(), "cart.db")+"?_pragma=busy_timeout=5000&_pragma=foreign_keys(ON)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := migrate(context.Background(), db, "sql/cart_esquema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	return NewSQLiteRepo(db)
+}
+
+func add(t *testing.T, r CartRepository, userID, bookID int64, qty int32) {
+	t.Helper()
+	if _, err := r.AddItem(context.Background(), userID, bookID, "libro", 1000, "libros", 300, qty); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// qtys resume las líneas como book_id → cantidad.
+func qtys(items []CartItem) map[int64]int32 {
+	out := map[int64]int32{}
+	for _, it := range items {
+		out[it.BookID] = it.Qty
+	}
+	return out
+}
+
+func cartQtys(t *testing.T, r CartRepository, userID int64) map[int64]int32 {
+	t.Helper()
+	c, err := r.GetCart(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return qtys(c.Items)
+}
+
+func TestSnapshotAndComplete(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	add(t, r, 1, 10, 2)
+	add(t, r, 1, 11, 1)
+
+	co, err := r.Snapshot(ctx, 1, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := qtys(co.Items); len(got) != 2 || got[10] != 2 || got[11] != 1 {
+		t.Fatalf("snapshot = %v", got)
+	}
+	if got := cartQtys(t, r, 1); len(got) != 0 {
+		t.Fatalf("las líneas siguen en el carrito: %v", got)
+	}
+
+	// Lo agregado después no entra en el snapshot ya congelado
+	add(t, r, 1, 12, 1)
+	again, err := r.Snapshot(ctx, 1, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := qtys(again.Items); len(got) != 2 || got[12] != 0 {
+		t.Fatalf("el reintento devolvió otro snapshot: %v", got)
+	}
+	if _, err := r.Snapshot(ctx, 2, "k1"); !errors.Is(err, ErrCheckoutOwner) {
+		t.Fatalf("otro usuario: err = %v", err)
+	}
+
+	if err := r.CompleteCheckout(ctx, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	// Completado: ni se restaura ni se vuelve a congelar
+	if err := r.RestoreCheckout(ctx, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := cartQtys(t, r, 1); len(got) != 1 || got[12] != 1 {
+		t.Fatalf("carrito tras completar = %v", got)
+	}
+	if _, err := r.Snapshot(ctx, 1, "k1"); !errors.Is(err, ErrCheckoutDone) {
+		t.Fatalf("snapshot de un checkout completado: err = %v", err)
+	}
+}
+
+func TestRestoreAndRefreeze(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	add(t, r, 1, 10, 2)
+	if _, err := r.Snapshot(ctx, 1, "k1"); err != nil {
+		t.Fatal(err)
+	}
+	add(t, r, 1, 10, 1) // el usuario vuelve a agregar el mismo libro mientras tanto
+
+	for range 2 { // la segunda vez no hace nada
+		if err := r.RestoreCheckout(ctx, "k1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := cartQtys(t, r, 1); got[10] != 3 {
+		t.Fatalf("cantidad restaurada = %d, se esperaban 3", got[10])
+	}
+
+	// Sin orden para la clave, el reintento congela el carrito actual
+	co, err := r.Snapshot(ctx, 1, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if co.State != CheckoutFrozen || qtys(co.Items)[10] != 3 || len(co.Items) != 1 {
+		t.Fatalf("recongelado = %+v", co)
+	}
+	if got := cartQtys(t, r, 1); len(got) != 0 {
+		t.Fatalf("las líneas siguen en el carrito: %v", got)
+	}
+}
+
+func TestSnapshotEmptyCart(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	co, err := r.Snapshot(ctx, 1, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(co.Items) != 0 {
+		t.Fatalf("items = %v", co.Items)
+	}
+	if _, err := r.GetCheckout(ctx, "k1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("un carrito vacío no debería guardar checkout: err = %v", err)
+	}
+}
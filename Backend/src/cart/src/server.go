@@ -3,72 +3,160 @@ package main
 
 import (
 	"context"
+	"errors"
 
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/pricing"
 	cartpb "github.com/ahinestrog/mybookstore/proto/gen/cart"
 	catalogpb "github.com/ahinestrog/mybookstore/proto/gen/catalog"
 	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type CartServer struct {
 	cartpb.UnimplementedCartServer
 	repo    CartRepository
 	catalog catalogpb.CatalogClient
+	pricing *pricing.Rules
 }
 
-func NewCartServer(repo CartRepository, catalog catalogpb.CatalogClient) *CartServer {
-	return &CartServer{repo: repo, catalog: catalog}
+func NewCartServer(repo CartRepository, catalog catalogpb.CatalogClient, rules *pricing.Rules) *CartServer {
+	return &CartServer{repo: repo, catalog: catalog, pricing: rules}
 }
 
 func (s *CartServer) GetCart(ctx context.Context, req *commonpb.UserRef) (*cartpb.CartView, error) {
+	if err := auth.CheckUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
 	c, err := s.repo.GetOrCreateCart(ctx, req.GetUserId())
 	if err != nil {
 		return nil, err
 	}
-	return toCartView(c), nil
+	return s.toCartView(c), nil
 }
 
 func (s *CartServer) AddItem(ctx context.Context, req *cartpb.AddItemRequest) (*cartpb.CartView, error) {
+	if err := auth.CheckUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
 	b, err := s.catalog.GetBook(ctx, &catalogpb.GetBookRequest{Id: req.BookId})
 	if err != nil {
 		return nil, err
 	}
-	c, err := s.repo.AddItem(ctx, req.UserId, req.BookId, b.GetTitle(), b.GetPrice().GetCents(), req.Qty)
+	c, err := s.repo.AddItem(ctx, req.UserId, req.BookId, b.GetTitle(), b.GetPrice().GetCents(), b.GetCategory(), b.GetWeightGrams(), req.Qty)
 	if err != nil {
 		return nil, err
 	}
-	return toCartView(c), nil
+	metrics.CartAdd()
+	return s.toCartView(c), nil
 }
 
 func (s *CartServer) RemoveItem(ctx context.Context, req *cartpb.RemoveItemRequest) (*cartpb.CartView, error) {
+	if err := auth.CheckUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
 	c, err := s.repo.RemoveItem(ctx, req.UserId, req.BookId, req.Qty)
 	if err != nil {
 		return nil, err
 	}
-	return toCartView(c), nil
+	return s.toCartView(c), nil
 }
 
 func (s *CartServer) ClearCart(ctx context.Context, req *commonpb.UserRef) (*cartpb.CartView, error) {
+	if err := auth.CheckUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
 	c, err := s.repo.Clear(ctx, req.UserId)
 	if err != nil {
 		return nil, err
 	}
-	return toCartView(c), nil
+	return s.toCartView(c), nil
+}
+
+func (s *CartServer) CheckoutSnapshot(ctx context.Context, req *cartpb.CheckoutSnapshotRequest) (*cartpb.CheckoutSnapshotResponse, error) {
+	if req.GetUserId() == 0 || req.GetCheckoutId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id y checkout_id requeridos")
+	}
+	if err := auth.CheckUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+	co, err := s.repo.Snapshot(ctx, req.GetUserId(), req.GetCheckoutId())
+	if errors.Is(err, ErrCheckoutOwner) {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, ErrCheckoutDone) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cartpb.CheckoutSnapshotResponse{
+		CheckoutId: co.ID,
+		Cart:       s.toCartView(&Cart{UserID: co.UserID, Items: co.Items}),
+	}, nil
 }
 
-func toCartView(c *Cart) *cartpb.CartView {
+func (s *CartServer) RestoreCheckout(ctx context.Context, req *cartpb.RestoreCheckoutRequest) (*commonpb.Ack, error) {
+	if req.GetUserId() == 0 || req.GetCheckoutId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id y checkout_id requeridos")
+	}
+	if err := auth.CheckUser(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+	co, err := s.repo.GetCheckout(ctx, req.GetCheckoutId())
+	if errors.Is(err, ErrNotFound) {
+		// Order se cayó antes de congelar: no hay nada que devolver
+		return &commonpb.Ack{Ok: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if co.UserID != req.GetUserId() {
+		return nil, status.Error(codes.PermissionDenied, ErrCheckoutOwner.Error())
+	}
+	if err := s.repo.RestoreCheckout(ctx, co.ID); err != nil {
+		return nil, err
+	}
+	return &commonpb.Ack{Ok: true}, nil
+}
+
+func (s *CartServer) toCartView(c *Cart) *cartpb.CartView {
 	view := &cartpb.CartView{}
 	var total int64
+	lines := make([]pricing.Line, 0, len(c.Items))
 	for _, it := range c.Items {
 		line := it.UnitPriceCents * int64(it.Qty)
 		total += line
 		view.Items = append(view.Items, &cartpb.CartItem{
-			BookId:    it.BookID,
-			Title:     it.Title,
-			Qty:       it.Qty,
-			UnitPrice: &commonpb.Money{Cents: it.UnitPriceCents},
-			LineTotal: &commonpb.Money{Cents: line},
+			BookId:      it.BookID,
+			Title:       it.Title,
+			Qty:         it.Qty,
+			UnitPrice:   &commonpb.Money{Cents: it.UnitPriceCents},
+			LineTotal:   &commonpb.Money{Cents: line},
+			Category:    it.Category,
+			WeightGrams: it.WeightGrams,
+		})
+		lines = append(lines, pricing.Line{
+			Category:    it.Category,
+			UnitCents:   it.UnitPriceCents,
+			Qty:         it.Qty,
+			WeightGrams: it.WeightGrams,
 		})
 	}
 	view.Total = &commonpb.Money{Cents: total}
+	// Sin dirección todavía: el envío se estima con la región por defecto
+	view.Estimate = breakdownToPB(s.pricing.Quote(lines, nil))
 	return view
 }
+
+func breakdownToPB(b pricing.Breakdown) *commonpb.PriceBreakdown {
+	return &commonpb.PriceBreakdown{
+		Subtotal:   &commonpb.Money{Cents: b.SubtotalCents},
+		Discount:   &commonpb.Money{Cents: b.DiscountCents},
+		Tax:        &commonpb.Money{Cents: b.TaxCents},
+		Shipping:   &commonpb.Money{Cents: b.ShippingCents},
+		GrandTotal: &commonpb.Money{Cents: b.GrandTotalCents},
+	}
+}
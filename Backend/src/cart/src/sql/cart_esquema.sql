@@ -14,6 +14,8 @@ CREATE TABLE IF NOT EXISTS cart_items (
   title TEXT NOT NULL,
   unit_price_cents INTEGER NOT NULL,       -- precio en centavos
   qty INTEGER NOT NULL CHECK (qty > 0),
+  category TEXT NOT NULL DEFAULT '',       -- copiados del catálogo para el estimado de IVA/envío
+  weight_grams INTEGER NOT NULL DEFAULT 0,
   UNIQUE(cart_id, book_id),
   FOREIGN KEY(cart_id) REFERENCES carts(id) ON DELETE CASCADE
 );
@@ -23,3 +25,28 @@ AFTER UPDATE ON carts
 BEGIN
   UPDATE carts SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
 END;
+
+-- Snapshots de checkout: las líneas congeladas salen del carrito vivo y quedan
+-- aquí hasta que Order publica el resultado final de la saga.
+CREATE TABLE IF NOT EXISTS checkouts (
+  id TEXT PRIMARY KEY,                     -- checkout_id asignado por Order
+  user_id INTEGER NOT NULL,
+  state TEXT NOT NULL DEFAULT 'FROZEN',    -- FROZEN | COMPLETED | RESTORED
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS checkout_items (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  checkout_id TEXT NOT NULL,
+  book_id INTEGER NOT NULL,
+  title TEXT NOT NULL,
+  unit_price_cents INTEGER NOT NULL,
+  qty INTEGER NOT NULL CHECK (qty > 0),
+  category TEXT NOT NULL DEFAULT '',
+  weight_grams INTEGER NOT NULL DEFAULT 0,
+  FOREIGN KEY(checkout_id) REFERENCES checkouts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_checkouts_user ON checkouts(user_id);
+CREATE INDEX IF NOT EXISTS idx_checkout_items_checkout ON checkout_items(checkout_id);
@@ -3,8 +3,12 @@ package main
 import (
 	"context"
 	"database/sql"
-	_ "modernc.org/sqlite"
+	"fmt"
 	"os"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
 func openSQLite(dbPath string) (*sql.DB, error) {
@@ -13,7 +17,7 @@ func openSQLite(dbPath string) (*sql.DB, error) {
 	}
 	// Busy timeout + WAL para concurrencia
 	dsn := dbPath
-	return sql.Open("sqlite", dsn+"?_pragma=busy_timeout=5000&_pragma=journal_mode=WAL")
+	return tracing.OpenSQLite("sqlite", dsn+"?_pragma=busy_timeout=5000&_pragma=journal_mode=WAL")
 }
 
 func migrate(ctx context.Context, db *sql.DB, schemaFile string) error {
@@ -21,6 +25,42 @@ func migrate(ctx context.Context, db *sql.DB, schemaFile string) error {
 	if err != nil {
 		return err
 	}
-	_, err = db.ExecContext(ctx, string(b))
+	if _, err := db.ExecContext(ctx, string(b)); err != nil {
+		return err
+	}
+	// Columnas agregadas después de la primera versión del esquema
+	for _, table := range []string{"cart_items", "checkout_items"} {
+		if err := ensureColumn(ctx, db, table, "category", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+		if err := ensureColumn(ctx, db, table, "weight_grams", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureColumn agrega la columna si la tabla fue creada con un esquema anterior.
+func ensureColumn(ctx context.Context, db *sql.DB, table, column, def string) error {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, def))
 	return err
 }
@@ -6,7 +6,9 @@ CREATE TABLE IF NOT EXISTS books (
   author        TEXT NOT NULL,
   price_cents   INTEGER NOT NULL DEFAULT 0,  
   cover_url     TEXT DEFAULT '',
-  created_unix  INTEGER NOT NULL             
+  created_unix  INTEGER NOT NULL,
+  category      TEXT NOT NULL DEFAULT 'libros',  -- define la tarifa de IVA
+  weight_grams  INTEGER NOT NULL DEFAULT 500     -- para el costo de envío
 );
 
 CREATE INDEX IF NOT EXISTS idx_books_title  ON books(title);
@@ -4,11 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"os"
 	"time"
 
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/mtls"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 	catalogpb "github.com/ahinestrog/mybookstore/proto/gen/catalog"
 	_ "github.com/mattn/go-sqlite3"
 	"google.golang.org/grpc"
@@ -32,7 +38,7 @@ func openSQLite(path string) (*sql.DB, error) {
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, err
 	}
-	db, err := sql.Open("sqlite3", fmt.Sprintf("%s?_busy_timeout=5000&_foreign_keys=on", path))
+	db, err := tracing.OpenSQLite("sqlite3", fmt.Sprintf("%s?_busy_timeout=5000&_foreign_keys=on", path))
 	if err != nil {
 		return nil, err
 	}
@@ -41,13 +47,23 @@ func openSQLite(path string) (*sql.DB, error) {
 }
 
 func main() {
+	logging.Setup("catalog")
+	flushTraces, err := tracing.Setup("catalog")
+	if err != nil {
+		logging.Fatal("tracing", err)
+	}
+	defer flushTraces(context.Background())
+	// /metrics en CATALOG_METRICS_ADDR
+	if err := metrics.Setup("catalog"); err != nil {
+		logging.Fatal("metrics", err)
+	}
 	port := getenv("GRPC_PORT", defaultPort)
 	dbPath := getenv("CATALOG_DB_PATH", defaultDBPath)
 
 	// DB + migración + seed opcional
 	db, err := openSQLite(dbPath)
 	if err != nil {
-		log.Fatalf("open db: %v", err)
+		logging.Fatal("open db", err)
 	}
 	defer db.Close()
 
@@ -55,27 +71,77 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if _, err := db.ExecContext(ctx, mustRead("/srv/db/db.sql")); err != nil {
-		log.Fatalf("migrate: %v", err)
+		logging.Fatal("migrate", err)
+	}
+	// Columnas agregadas después de la primera versión del esquema
+	for col, def := range map[string]string{
+		"category":     "TEXT NOT NULL DEFAULT 'libros'",
+		"weight_grams": "INTEGER NOT NULL DEFAULT 500",
+	} {
+		if err := ensureColumn(ctx, db, "books", col, def); err != nil {
+			logging.Fatal("migrate "+col, err)
+		}
 	}
 	// Seed si está vacío
 	var c int64
 	if err := db.QueryRowContext(ctx, `SELECT COUNT(1) FROM books`).Scan(&c); err == nil && c == 0 {
 		if _, err := db.ExecContext(ctx, mustRead("./db/seed.sql")); err != nil {
-			log.Printf("seed warn: %v", err)
+			slog.Warn("seed", "err", err)
 		}
 	}
 
 	// gRPC
 	lis, err := net.Listen("tcp", ":"+port)
 	if err != nil {
-		log.Fatalf("listen: %v", err)
+		logging.Fatal("listen", err)
+	}
+	// El catálogo es público; el token solo se valida si viene
+	authority, err := auth.NewFromEnv()
+	if err != nil {
+		logging.Fatal("auth", err)
+	}
+	tlsOpts, err := mtls.Setup("catalog", mtls.Peers{"/catalog.Catalog/": {"storefront", "cart"}})
+	if err != nil {
+		logging.Fatal("mtls", err)
 	}
-	s := grpc.NewServer()
+	opts := grpcclient.ServerOptions()
+	opts = append(opts, tracing.ServerOptions()...)
+	opts = append(opts, metrics.ServerOptions()...)
+	opts = append(opts, logging.ServerOptions()...)
+	opts = append(opts, tlsOpts...)
+	s := grpc.NewServer(append(opts, authority.ServerOptions(auth.Rules{Public: []string{"/catalog.Catalog/"}})...)...)
 	catalogpb.RegisterCatalogServer(s, NewCatalogServer(repo))
-	log.Printf("Catalog service listening :%s  db=%s", port, dbPath)
+	grpcclient.RegisterHealth(s)
+	slog.Info("gRPC escuchando", "port", port, "db", dbPath)
 	if err := s.Serve(lis); err != nil {
-		log.Fatalf("serve: %v", err)
+		logging.Fatal("serve", err)
+	}
+}
+
+// ensureColumn agrega la columna si la tabla fue creada con un esquema anterior.
+func ensureColumn(ctx context.Context, db *sql.DB, table, column, def string) error {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
+	rows.Close()
+	_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, def))
+	return err
 }
 
 func getenv(k, def string) string {
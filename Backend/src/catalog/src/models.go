@@ -12,6 +12,8 @@ type Book struct {
 	PriceCents  int64
 	CoverURL    string
 	CreatedUnix int64
+	Category    string
+	WeightGrams int32
 }
 
 // ---- mapping entidad <-> protobuf ----
@@ -24,6 +26,8 @@ func bookToPB(b *Book) *catalogpb.Book {
 		Price:      &commonpb.Money{Cents: b.PriceCents},
 		CoverUrl:   b.CoverURL,
 		CreatedUnix:b.CreatedUnix,
+		Category:   b.Category,
+		WeightGrams:b.WeightGrams,
 	}
 }
 
@@ -5,6 +5,10 @@ import (
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
 type Rabbit struct {
@@ -26,9 +30,14 @@ func NewRabbit(url, exchange string) (*Rabbit, error) {
 
 func (r *Rabbit) Publish(ctx context.Context, key string, body []byte) error {
 	if r == nil || r.ch == nil { return nil }
-	return r.ch.PublishWithContext(ctx, r.exchange, key, false, false, amqp.Publishing{
+	ctx, span := tracing.StartPublish(ctx, r.exchange, key)
+	err := r.ch.PublishWithContext(ctx, r.exchange, key, false, false, amqp.Publishing{
 		ContentType: "application/json",
+		Headers:     tracing.Inject(ctx, logging.Headers(ctx)),
 		Body:        body,
 		Timestamp:   time.Now(),
 	})
+	tracing.End(span, err)
+	metrics.Published(key, err)
+	return err
 }
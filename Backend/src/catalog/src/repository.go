@@ -42,12 +42,12 @@ func (r *sqliteRepo) List(ctx context.Context, q string, limit, offset int32) ([
 	var err error
 	if strings.TrimSpace(q) == "" {
 		rows, err = r.db.QueryContext(ctx, `
-			SELECT id,title,author,price_cents,cover_url,created_unix
+			SELECT id,title,author,price_cents,cover_url,created_unix,category,weight_grams
 			FROM books ORDER BY id DESC LIMIT ? OFFSET ?`, limit, offset)
 	} else {
 		qp := "%" + strings.ToLower(q) + "%"
 		rows, err = r.db.QueryContext(ctx, `
-			SELECT id,title,author,price_cents,cover_url,created_unix
+			SELECT id,title,author,price_cents,cover_url,created_unix,category,weight_grams
 			FROM books
 			WHERE lower(title) LIKE ? OR lower(author) LIKE ?
 			ORDER BY id DESC LIMIT ? OFFSET ?`, qp, qp, limit, offset)
@@ -58,7 +58,7 @@ func (r *sqliteRepo) List(ctx context.Context, q string, limit, offset int32) ([
 	var out []*Book
 	for rows.Next() {
 		var b Book
-		if err := rows.Scan(&b.ID, &b.Title, &b.Author, &b.PriceCents, &b.CoverURL, &b.CreatedUnix); err != nil {
+		if err := rows.Scan(&b.ID, &b.Title, &b.Author, &b.PriceCents, &b.CoverURL, &b.CreatedUnix, &b.Category, &b.WeightGrams); err != nil {
 			return nil, err
 		}
 		out = append(out, &b)
@@ -69,9 +69,9 @@ func (r *sqliteRepo) List(ctx context.Context, q string, limit, offset int32) ([
 func (r *sqliteRepo) Get(ctx context.Context, id int64) (*Book, error) {
 	var b Book
 	err := r.db.QueryRowContext(ctx, `
-		SELECT id,title,author,price_cents,cover_url,created_unix
+		SELECT id,title,author,price_cents,cover_url,created_unix,category,weight_grams
 		FROM books WHERE id=?`, id).
-		Scan(&b.ID, &b.Title, &b.Author, &b.PriceCents, &b.CoverURL, &b.CreatedUnix)
+		Scan(&b.ID, &b.Title, &b.Author, &b.PriceCents, &b.CoverURL, &b.CreatedUnix, &b.Category, &b.WeightGrams)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("book %d not found", id)
@@ -16,6 +16,9 @@ type Config struct {
 	QReserveRes  string
 	QConfirmReq  string
 	QReleaseReq  string
+	QRestockReq  string
+	// Exchange de eventos de dominio (devoluciones)
+	EventsExchange string
 }
 
 func getenv(key, def string) string {
@@ -37,6 +40,9 @@ func LoadConfig() Config {
 		QReserveRes: getenv("Q_INVENTORY_RESERVE_RESULT",  "inventory.reserve.result"),
 		QConfirmReq: getenv("Q_INVENTORY_CONFIRM_REQUEST", "inventory.confirm.request"),
 		QReleaseReq: getenv("Q_INVENTORY_RELEASE_REQUEST", "inventory.release.request"),
+		QRestockReq: getenv("Q_INVENTORY_RESTOCK_REQUEST", "inventory.restock.request"),
+
+		EventsExchange: getenv("INVENTORY_EVENTS_EXCHANGE", "mybookstore.events"),
 	}
 }
 
@@ -8,24 +8,30 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
+	"log/slog"
+
 	"google.golang.org/grpc"
 
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/mtls"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 	inventorypb "github.com/ahinestrog/mybookstore/proto/gen/inventory"
 )
 
 func main() {
-	// Logger
-	zerolog.TimeFieldFormat = time.RFC3339
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stdout})
+	// Logger JSON (INVENTORY_LOG_LEVEL)
+	logging.Setup("inventory")
+	flushTraces, err := tracing.Setup("inventory")
+	if err != nil { logging.Fatal("tracing", err) }
+	defer flushTraces(context.Background())
+	// /metrics en INVENTORY_METRICS_ADDR
+	if err := metrics.Setup("inventory"); err != nil { logging.Fatal("metrics", err) }
 
 	cfg := LoadConfig()
-	log.Info().
-		Str("addr", cfg.GRPCAddr).
-		Str("db", cfg.DBPath).
-		Str("rabbit", cfg.RabbitURL).
-		Msg("starting inventory service")
+	slog.Info("starting inventory service", "addr", cfg.GRPCAddr, "db", cfg.DBPath)
 
 	// Repo
 	repo, err := NewRepository(cfg.DBPath)
@@ -34,7 +40,7 @@ func main() {
 
 	if cfg.SeedOnStart {
 		must(repo.Seed(context.Background()))
-		log.Info().Msg("seeded initial stock")
+		slog.Info("seeded initial stock")
 	}
 
 	// Rabbit
@@ -44,32 +50,45 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	must(rabbit.StartConsumers(ctx))
-	log.Info().Msg("rabbit consumers started")
+	slog.Info("rabbit consumers started")
 
 	// gRPC server
 	lis, err := net.Listen("tcp", cfg.GRPCAddr)
 	must(err)
-	grpcSrv := grpc.NewServer()
+	// La disponibilidad es pública; el token solo se valida si viene
+	authority, err := auth.NewFromEnv()
+	must(err)
+	tlsOpts, err := mtls.Setup("inventory", mtls.Peers{
+		"/inventory.Inventory/": {"storefront"},
+	})
+	must(err)
+	opts := grpcclient.ServerOptions()
+	opts = append(opts, tracing.ServerOptions()...)
+	opts = append(opts, metrics.ServerOptions()...)
+	opts = append(opts, logging.ServerOptions()...)
+	opts = append(opts, tlsOpts...)
+	grpcSrv := grpc.NewServer(append(opts, authority.ServerOptions(auth.Rules{Public: []string{"/inventory.Inventory/"}})...)...)
 	inventorypb.RegisterInventoryServer(grpcSrv, &InventoryServer{Repo: repo})
+	grpcclient.RegisterHealth(grpcSrv)
 
 	// Señales para apagado limpio
 	go func() {
 		ch := make(chan os.Signal, 1)
 		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
 		<-ch
-		log.Warn().Msg("shutting down...")
+		slog.Warn("shutting down...")
 		grpcSrv.GracefulStop()
 		cancel()
 		time.Sleep(ShutdownGrace)
 		os.Exit(0)
 	}()
 
-	log.Info().Msg("gRPC listening")
+	slog.Info("gRPC listening", "addr", cfg.GRPCAddr)
 	must(grpcSrv.Serve(lis))
 }
 
 func must(err error) {
 	if err != nil {
-		log.Fatal().Err(err).Msg("fatal")
+		logging.Fatal("fatal", err)
 	}
 }
@@ -5,8 +5,13 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"log/slog"
+
 	"github.com/streadway/amqp"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
 type Rabbit struct {
@@ -23,13 +28,27 @@ func NewRabbit(cfg Config, repo *Repository) (*Rabbit, error) {
 	if err != nil { conn.Close(); return nil, err }
 
 	r := &Rabbit{cfg: cfg, conn: conn, ch: ch, repo: repo}
-	qNames := []string{cfg.QReserveReq, cfg.QReserveRes, cfg.QConfirmReq, cfg.QReleaseReq}
+	qNames := []string{cfg.QReserveReq, cfg.QReserveRes, cfg.QConfirmReq, cfg.QReleaseReq, cfg.QRestockReq}
 	for _, q := range qNames {
 		if _, err := ch.QueueDeclare(q, true, false, false, false, nil); err != nil {
 			r.Close()
 			return nil, err
 		}
 	}
+	// Las devoluciones llegan como eventos de dominio de Order (return.received)
+	if err := ch.ExchangeDeclare(cfg.EventsExchange, "topic", true, false, false, false, nil); err != nil {
+		r.Close()
+		return nil, err
+	}
+	if err := ch.QueueBind(cfg.QRestockReq, "return.received", cfg.EventsExchange, false, nil); err != nil {
+		r.Close()
+		return nil, err
+	}
+	// Una orden vencida (order.expired) libera su reserva como un release.request
+	if err := ch.QueueBind(cfg.QReleaseReq, "order.expired", cfg.EventsExchange, false, nil); err != nil {
+		r.Close()
+		return nil, err
+	}
 	return r, nil
 }
 
@@ -54,18 +73,70 @@ type ConfirmRequest struct {
 	OrderID int64       `json:"order_id"`
 	Items   []OrderItem `json:"items"`
 }
+// También llega como order.expired, que trae los mismos campos (y Reason).
 type ReleaseRequest struct {
 	OrderID int64       `json:"order_id"`
 	Items   []OrderItem `json:"items"`
+	Reason  string      `json:"reason,omitempty"`
+}
+
+// Publicado por Order al recibir una devolución en bodega (return.received).
+type RestockRequest struct {
+	ReturnID int64       `json:"return_id"`
+	OrderID  int64       `json:"order_id"`
+	Items    []OrderItem `json:"items"`
+}
+
+// inventory.restocked: confirma a Order que el stock ya se reingresó.
+type RestockResult struct {
+	OrderID  int64 `json:"order_id"`
+	ReturnID int64 `json:"return_id"`
 }
 
-// Helpers para los publicadores
-func (r *Rabbit) publishJSON(q string, v any) error {
+// Helpers para los publicadores; los headers llevan el correlation_id y el contexto de traza de ctx
+func (r *Rabbit) publishJSON(ctx context.Context, q string, v any) error {
+	return r.publish(ctx, "", q, v)
+}
+
+func (r *Rabbit) publishEvent(ctx context.Context, rk string, v any) error {
+	return r.publish(ctx, r.cfg.EventsExchange, rk, v)
+}
+
+func (r *Rabbit) publish(ctx context.Context, exchange, rk string, v any) error {
 	body, _ := json.Marshal(v)
-	return r.ch.Publish("", q, false, false, amqp.Publishing{
+	ctx, span := tracing.StartPublish(ctx, exchange, rk)
+	err := r.ch.Publish(exchange, rk, false, false, amqp.Publishing{
 		ContentType: "application/json",
+		Headers:     tracing.Inject(ctx, logging.Headers(ctx)),
 		Body:        body,
+		Timestamp:   time.Now(),
 	})
+	tracing.End(span, err)
+	metrics.Published(rk, err)
+	return err
+}
+
+// deliver procesa un mensaje con su correlation_id y un span de consumidor que
+// termina cuando fn retorna; el error de fn marca el span y cuenta como fallo
+// en las métricas (el ack o nack lo decide fn).
+func deliver(ctx context.Context, queue string, m amqp.Delivery, fn func(ctx context.Context) error) {
+	ctx = logging.FromHeaders(ctx, m.Headers)
+	ctx, span := tracing.StartConsume(ctx, m.Headers, queue, m.RoutingKey)
+	metrics.Received(queue, m.RoutingKey, m.Timestamp)
+	err := fn(ctx)
+	tracing.End(span, err)
+	metrics.Consumed(queue, m.RoutingKey, err)
+}
+
+// QueueDepth lee del broker los mensajes en espera de queue (pkg/metrics). Usa
+// un canal propio: la declaración pasiva de una cola inexistente cierra el canal.
+func (r *Rabbit) QueueDepth(queue string) (int, error) {
+	ch, err := r.conn.Channel()
+	if err != nil { return 0, err }
+	defer ch.Close()
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil { return 0, err }
+	return q.Messages, nil
 }
 
 // Consumidores RabbitMQ
@@ -74,6 +145,8 @@ func (r *Rabbit) StartConsumers(ctx context.Context) error {
 	if err := r.consumeReserve(ctx); err != nil { return err }
 	if err := r.consumeConfirm(ctx); err != nil { return err }
 	if err := r.consumeRelease(ctx); err != nil { return err }
+	if err := r.consumeRestock(ctx); err != nil { return err }
+	metrics.QueueDepth(r.QueueDepth, r.cfg.QReserveReq, r.cfg.QConfirmReq, r.cfg.QReleaseReq, r.cfg.QRestockReq)
 	return nil
 }
 
@@ -83,46 +156,68 @@ func (r *Rabbit) consumeReserve(ctx context.Context) error {
 
 	go func() {
 		for m := range msgs {
-			var req ReserveRequest
-			if err := json.Unmarshal(m.Body, &req); err != nil {
-				log.Error().Err(err).Msg("reserve: invalid json")
+			deliver(ctx, r.cfg.QReserveReq, m, func(ctx context.Context) error {
+				var req ReserveRequest
+				if err := json.Unmarshal(m.Body, &req); err != nil {
+					slog.ErrorContext(ctx, "reserve: invalid json", "err", err)
+					_ = m.Ack(false)
+					return err
+				}
+				slog.InfoContext(ctx, "reserve: received", "order_id", req.OrderID)
+				res := ReserveResult{OrderID: req.OrderID}
+
+				// Intentar reservar
+				if err := r.repo.TryReserve(ctx, req.OrderID, req.Items); err != nil {
+					res.State = "FAILED"
+					res.Reason = err.Error()
+					metrics.ReservationRejected(rejectReason(err))
+				} else {
+					res.State = "RESERVED"
+				}
+				err := r.publishJSON(ctx, r.cfg.QReserveRes, res)
+				if err != nil {
+					slog.ErrorContext(ctx, "reserve: publish result failed", "err", err)
+				}
 				_ = m.Ack(false)
-				continue
-			}
-			log.Info().Int64("order", req.OrderID).Msg("reserve: received")
-			res := ReserveResult{OrderID: req.OrderID}
-
-			// Intentar reservar
-			if err := r.repo.TryReserve(ctx, req.Items); err != nil {
-				res.State = "FAILED"
-				res.Reason = err.Error()
-			} else {
-				res.State = "RESERVED"
-			}
-			if err := r.publishJSON(r.cfg.QReserveRes, res); err != nil {
-				log.Error().Err(err).Msg("reserve: publish result failed")
-			}
-			_ = m.Ack(false)
+				return err
+			})
 		}
 	}()
 	return nil
 }
 
+// rejectReason clasifica el rechazo de una reserva para las métricas.
+func rejectReason(err error) string {
+	switch err.(type) {
+	case ErrInsufficient:
+		return "insufficient_stock"
+	case ErrNoStockForBook:
+		return "unknown_book"
+	case ErrOrderReleased:
+		return "order_released"
+	}
+	return "error"
+}
+
 func (r *Rabbit) consumeConfirm(ctx context.Context) error {
 	msgs, err := r.ch.Consume(r.cfg.QConfirmReq, "inventory-confirm-worker", false, false, false, false, nil)
 	if err != nil { return err }
 	go func() {
 		for m := range msgs {
-			var req ConfirmRequest
-			if err := json.Unmarshal(m.Body, &req); err != nil {
-				log.Error().Err(err).Msg("confirm: invalid json")
-				_ = m.Ack(false); continue
-			}
-			log.Info().Int64("order", req.OrderID).Msg("confirm: received")
-			if err := r.repo.Confirm(ctx, req.Items); err != nil {
-				log.Error().Err(err).Msg("confirm: repo error")
-			}
-			_ = m.Ack(false)
+			deliver(ctx, r.cfg.QConfirmReq, m, func(ctx context.Context) error {
+				var req ConfirmRequest
+				if err := json.Unmarshal(m.Body, &req); err != nil {
+					slog.ErrorContext(ctx, "confirm: invalid json", "err", err)
+					_ = m.Ack(false); return err
+				}
+				slog.InfoContext(ctx, "confirm: received", "order_id", req.OrderID)
+				err := r.repo.Confirm(ctx, req.OrderID)
+				if err != nil {
+					slog.ErrorContext(ctx, "confirm: repo error", "err", err)
+				}
+				_ = m.Ack(false)
+				return err
+			})
 		}
 	}()
 	return nil
@@ -133,16 +228,52 @@ func (r *Rabbit) consumeRelease(ctx context.Context) error {
 	if err != nil { return err }
 	go func() {
 		for m := range msgs {
-			var req ReleaseRequest
-			if err := json.Unmarshal(m.Body, &req); err != nil {
-				log.Error().Err(err).Msg("release: invalid json")
-				_ = m.Ack(false); continue
-			}
-			log.Info().Int64("order", req.OrderID).Msg("release: received")
-			if err := r.repo.Release(ctx, req.Items); err != nil {
-				log.Error().Err(err).Msg("release: repo error")
-			}
-			_ = m.Ack(false)
+			deliver(ctx, r.cfg.QReleaseReq, m, func(ctx context.Context) error {
+				var req ReleaseRequest
+				if err := json.Unmarshal(m.Body, &req); err != nil {
+					slog.ErrorContext(ctx, "release: invalid json", "err", err)
+					_ = m.Ack(false); return err
+				}
+				slog.InfoContext(ctx, "release: received", "order_id", req.OrderID, "reason", req.Reason)
+				if err := r.repo.Release(ctx, req.OrderID, req.Items); err != nil {
+					slog.ErrorContext(ctx, "release: repo error", "err", err)
+					_ = m.Nack(false, true); return err
+				}
+				_ = m.Ack(false)
+				return nil
+			})
+		}
+	}()
+	return nil
+}
+
+func (r *Rabbit) consumeRestock(ctx context.Context) error {
+	msgs, err := r.ch.Consume(r.cfg.QRestockReq, "inventory-restock-worker", false, false, false, false, nil)
+	if err != nil { return err }
+	go func() {
+		for m := range msgs {
+			deliver(ctx, r.cfg.QRestockReq, m, func(ctx context.Context) error {
+				var req RestockRequest
+				if err := json.Unmarshal(m.Body, &req); err != nil {
+					slog.ErrorContext(ctx, "restock: invalid json", "err", err)
+					_ = m.Ack(false); return err
+				}
+				slog.InfoContext(ctx, "restock: received", "order_id", req.OrderID, "return_id", req.ReturnID)
+				// Idempotente: un return_id repetido no vuelve a sumar stock
+				if err := r.repo.Restock(ctx, req.OrderID, req.ReturnID, req.Items); err != nil {
+					slog.ErrorContext(ctx, "restock: repo error", "err", err)
+					_ = m.Nack(false, true); return err
+				}
+				// Si el evento no sale se reencola: el reingreso ya aplicado no se repite
+				// (UNIQUE(ref, book_id)) y Order sigue esperando inventory.restocked
+				res := RestockResult{OrderID: req.OrderID, ReturnID: req.ReturnID}
+				if err := r.publishEvent(ctx, "inventory.restocked", res); err != nil {
+					slog.ErrorContext(ctx, "restock: publish result failed", "err", err)
+					_ = m.Nack(false, true); return err
+				}
+				_ = m.Ack(false)
+				return nil
+			})
 		}
 	}()
 	return nil
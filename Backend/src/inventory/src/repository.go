@@ -3,9 +3,12 @@ package main
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	_ "modernc.org/sqlite"
+
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
 type Repository struct {
@@ -15,7 +18,7 @@ type Repository struct {
 func NewRepository(dbPath string) (*Repository, error) {
 	// _pragma busy_timeout para evitar "database is locked" -> No puede pasar, de lo contrario no nos dejería ingresar en los datos
 	dsn := dbPath + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout=5000"
-	db, err := sql.Open("sqlite", dsn)
+	db, err := tracing.OpenSQLite("sqlite", dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +41,33 @@ CREATE TABLE IF NOT EXISTS stock(
   updated_at   INTEGER NOT NULL DEFAULT (strftime('%s','now'))
 );
 CREATE INDEX IF NOT EXISTS idx_stock_updated ON stock(updated_at);
+
+-- Movimientos que cambian total_qty fuera de la saga de compra (p. ej. devoluciones).
+-- (ref, book_id) es único para que reprocesar un mensaje no duplique el ajuste.
+CREATE TABLE IF NOT EXISTS stock_movements(
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  book_id    INTEGER NOT NULL,
+  delta      INTEGER NOT NULL,
+  reason     TEXT NOT NULL,
+  ref        TEXT NOT NULL,
+  order_id   INTEGER NOT NULL DEFAULT 0,
+  created_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+  UNIQUE(ref, book_id)
+);
+CREATE INDEX IF NOT EXISTS idx_movements_order ON stock_movements(order_id);
+
+-- Reservas por orden: liberar o confirmar solo toca lo que esa orden reservó, y
+-- repetir el mensaje no vuelve a mover reserved_qty.
+-- state: RESERVED, CONFIRMED o RELEASED (una liberación sin reserva previa deja
+-- filas RELEASED con qty 0 para que una reserva tardía se rechace).
+CREATE TABLE IF NOT EXISTS reservations(
+  order_id   INTEGER NOT NULL,
+  book_id    INTEGER NOT NULL,
+  qty        INTEGER NOT NULL,
+  state      TEXT NOT NULL,
+  updated_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+  PRIMARY KEY(order_id, book_id)
+);
 `
 	_, err := r.DB.ExecContext(ctx, schema)
 	return err
@@ -126,13 +156,43 @@ type OrderItem struct {
 	Qty    int32 `json:"qty"`
 }
 
-func (r *Repository) TryReserve(ctx context.Context, items []OrderItem) error {
+const (
+	reservationReserved  = "RESERVED"
+	reservationConfirmed = "CONFIRMED"
+	reservationReleased  = "RELEASED"
+)
+
+// orderReservationState devuelve el estado de las reservas de la orden ("" si
+// no tiene). Basta una fila: todas cambian de estado juntas.
+func orderReservationState(ctx context.Context, tx *sql.Tx, orderID int64) (string, error) {
+	var state string
+	err := tx.QueryRowContext(ctx, `SELECT state FROM reservations WHERE order_id=? LIMIT 1`, orderID).Scan(&state)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return state, err
+}
+
+// TryReserve reserva los ítems para la orden. Si la orden ya tiene reserva no
+// hace nada (mensaje repetido); si ya se liberó (la orden venció) la rechaza.
+func (r *Repository) TryReserve(ctx context.Context, orderID int64, items []OrderItem) error {
 	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	state, err := orderReservationState(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	switch state {
+	case reservationReleased:
+		return ErrOrderReleased{OrderID: orderID}
+	case reservationReserved, reservationConfirmed:
+		return nil
+	}
+
 	// Valida disponibilidad
 	for _, it := range items {
 		var tot, res int32
@@ -158,17 +218,48 @@ func (r *Repository) TryReserve(ctx context.Context, items []OrderItem) error {
 			it.Qty, it.BookID); err != nil {
 			return err
 		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO reservations(order_id,book_id,qty,state) VALUES(?,?,?,?)
+ON CONFLICT(order_id,book_id) DO UPDATE SET qty=qty+excluded.qty`,
+			orderID, it.BookID, it.Qty, reservationReserved); err != nil {
+			return err
+		}
 	}
 	return tx.Commit()
 }
 
-func (r *Repository) Confirm(ctx context.Context, items []OrderItem) error {
+// reserved devuelve las líneas que la orden tiene en estado RESERVED.
+func reserved(ctx context.Context, tx *sql.Tx, orderID int64) ([]OrderItem, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT book_id,qty FROM reservations WHERE order_id=? AND state=?`, orderID, reservationReserved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []OrderItem
+	for rows.Next() {
+		var it OrderItem
+		if err := rows.Scan(&it.BookID, &it.Qty); err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// Confirm descuenta del stock lo que la orden tenía reservado. Repetirlo no
+// vuelve a descontar.
+func (r *Repository) Confirm(ctx context.Context, orderID int64) error {
 	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	items, err := reserved(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
 	for _, it := range items {
 		// Descuenta del total y libera de reserved (cantidad reservada)
 		_, err := tx.ExecContext(ctx, `
@@ -181,17 +272,47 @@ WHERE book_id=?`, it.Qty, it.Qty, it.BookID)
 			return err
 		}
 	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE reservations SET state=?, updated_at=strftime('%s','now') WHERE order_id=? AND state=?`,
+		reservationConfirmed, orderID, reservationReserved); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
-func (r *Repository) Release(ctx context.Context, items []OrderItem) error {
+// Release devuelve lo que la orden tenía reservado. Si la orden no alcanzó a
+// reservar, deja constancia con los ítems del mensaje para que la reserva que
+// llegue después se rechace. Una orden ya confirmada no se toca.
+func (r *Repository) Release(ctx context.Context, orderID int64, items []OrderItem) error {
 	tx, err := r.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	for _, it := range items {
+	state, err := orderReservationState(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	switch state {
+	case reservationConfirmed, reservationReleased:
+		return nil
+	case "":
+		for _, it := range items {
+			if _, err := tx.ExecContext(ctx, `
+INSERT INTO reservations(order_id,book_id,qty,state) VALUES(?,?,0,?)
+ON CONFLICT(order_id,book_id) DO NOTHING`, orderID, it.BookID, reservationReleased); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	}
+
+	held, err := reserved(ctx, tx, orderID)
+	if err != nil {
+		return err
+	}
+	for _, it := range held {
 		_, err := tx.ExecContext(ctx, `
 UPDATE stock
 SET reserved_qty = CASE
@@ -203,6 +324,46 @@ WHERE book_id=?`, it.Qty, it.Qty, it.BookID)
 			return err
 		}
 	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE reservations SET state=?, updated_at=strftime('%s','now') WHERE order_id=? AND state=?`,
+		reservationReleased, orderID, reservationReserved); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Restock reingresa al stock las unidades de una devolución registrando un
+// movimiento por libro. Si el movimiento ya existe no vuelve a sumar.
+func (r *Repository) Restock(ctx context.Context, orderID, returnID int64, items []OrderItem) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ref := fmt.Sprintf("return:%d", returnID)
+	for _, it := range items {
+		if it.Qty <= 0 {
+			continue
+		}
+		res, err := tx.ExecContext(ctx, `
+INSERT INTO stock_movements(book_id,delta,reason,ref,order_id)
+VALUES(?,?,'return',?,?)
+ON CONFLICT(ref,book_id) DO NOTHING`, it.BookID, it.Qty, ref, orderID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO stock(book_id,total_qty,reserved_qty,updated_at)
+VALUES(?,?,0,strftime('%s','now'))
+ON CONFLICT(book_id) DO UPDATE SET total_qty=total_qty+excluded.total_qty, updated_at=strftime('%s','now')`,
+			it.BookID, it.Qty); err != nil {
+			return err
+		}
+	}
 	return tx.Commit()
 }
 
@@ -232,3 +393,8 @@ type ErrInsufficient struct {
 }
 
 func (e ErrInsufficient) Error() string { return "stock insuficiente" }
+
+// ErrOrderReleased: la orden ya se liberó (venció) antes de que llegara la reserva.
+type ErrOrderReleased struct{ OrderID int64 }
+
+func (e ErrOrderReleased) Error() string { return "la orden ya fue liberada" }
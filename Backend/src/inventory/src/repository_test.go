@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func newTestRepo(t *testing.T) *Repository {
+	t.Helper()
+	r, err := NewRepository(filepath.Join(t.TempDir(), "inventory.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	if err := r.Seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// stock devuelve total_qty y reserved_qty del libro.
+func stock(t *testing.T, r *Repository, bookID int64) (total, reserved int32) {
+	t.Helper()
+	if err := r.DB.QueryRow(`SELECT total_qty,reserved_qty FROM stock WHERE book_id=?`, bookID).Scan(&total, &reserved); err != nil {
+		t.Fatal(err)
+	}
+	return total, reserved
+}
+
+func TestRestockDuplicateReturn(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	items := []OrderItem{{BookID: 1, Qty: 2}, {BookID: 9, Qty: 1}}
+
+	// El mismo return_id reentregado (nack, reinicio) suma una sola vez
+	for i := 0; i < 3; i++ {
+		if err := r.Restock(ctx, 7, 70, items); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if total, _ := stock(t, r, 1); total != 12 {
+		t.Fatalf("total libro 1 = %d; quiero 12", total)
+	}
+	// Un libro sin fila de stock se crea con lo devuelto
+	if total, _ := stock(t, r, 9); total != 1 {
+		t.Fatalf("total libro 9 = %d; quiero 1", total)
+	}
+
+	// Otra devolución de la misma orden sí suma
+	if err := r.Restock(ctx, 7, 71, []OrderItem{{BookID: 1, Qty: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if total, _ := stock(t, r, 1); total != 13 {
+		t.Fatalf("total libro 1 = %d; quiero 13", total)
+	}
+}
+
+func TestLateReserveAfterRelease(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	items := []OrderItem{{BookID: 2, Qty: 3}}
+
+	// La orden venció antes de que llegara la reserva
+	if err := r.Release(ctx, 8, items); err != nil {
+		t.Fatal(err)
+	}
+	err := r.TryReserve(ctx, 8, items)
+	var released ErrOrderReleased
+	if !errors.As(err, &released) || released.OrderID != 8 {
+		t.Fatalf("reserva tardía = %v; quiero ErrOrderReleased", err)
+	}
+	if _, reserved := stock(t, r, 2); reserved != 0 {
+		t.Fatalf("reserved libro 2 = %d; quiero 0", reserved)
+	}
+
+	// Una orden reservada y luego liberada tampoco vuelve a reservar
+	if err := r.TryReserve(ctx, 9, items); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Release(ctx, 9, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.TryReserve(ctx, 9, items); !errors.As(err, &released) {
+		t.Fatalf("reserva repetida tras liberar = %v; quiero ErrOrderReleased", err)
+	}
+	if total, reserved := stock(t, r, 2); total != 10 || reserved != 0 {
+		t.Fatalf("libro 2 = %d/%d; quiero 10/0", total, reserved)
+	}
+}
+
+func TestConfirmAndRelease(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRepo(t)
+	items := []OrderItem{{BookID: 3, Qty: 4}}
+
+	// Confirmar después de liberar no descuenta nada
+	if err := r.TryReserve(ctx, 10, items); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Release(ctx, 10, items); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Confirm(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if total, reserved := stock(t, r, 3); total != 10 || reserved != 0 {
+		t.Fatalf("confirmar tras liberar: libro 3 = %d/%d; quiero 10/0", total, reserved)
+	}
+
+	// Confirmar dos veces descuenta una sola vez y liberar después no devuelve
+	if err := r.TryReserve(ctx, 11, items); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.Confirm(ctx, 11); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Release(ctx, 11, items); err != nil {
+		t.Fatal(err)
+	}
+	if total, reserved := stock(t, r, 3); total != 6 || reserved != 0 {
+		t.Fatalf("confirmar: libro 3 = %d/%d; quiero 6/0", total, reserved)
+	}
+}
@@ -3,7 +3,8 @@ package main
 import (
 	"context"
 
-	"github.com/rs/zerolog/log"
+	"log/slog"
+
 	inventorypb "github.com/ahinestrog/mybookstore/proto/gen/inventory"
 )
 
@@ -23,6 +24,6 @@ func (s *InventoryServer) GetAvailability(ctx context.Context, req *inventorypb.
 			AvailableQty: int32(q),
 		})
 	}
-	log.Debug().Int("count", len(resp.Items)).Msg("GetAvailability")
+	slog.DebugContext(ctx, "GetAvailability", "count", len(resp.Items))
 	return resp, nil
 }
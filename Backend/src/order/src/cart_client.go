@@ -2,27 +2,30 @@ package main
 
 import (
 	"context"
-	"time"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
 	cartpb "github.com/ahinestrog/mybookstore/proto/gen/cart"
-	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 )
 
+// CartClient usa la conexión compartida del pool; el deadline y los reintentos
+// de CheckoutSnapshot vienen del service config de grpcclient.
 type CartClient struct {
-	addr string
+	cli cartpb.CartClient
 }
 
-func NewCartClient(addr string) *CartClient { return &CartClient{addr: addr} }
-
-func (c *CartClient) GetCart(ctx context.Context, userID int64) (*cartpb.CartView, error) {
-	cc, err := grpc.DialContext(ctx, c.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+func NewCartClient(addr string) (*CartClient, error) {
+	cc, err := grpcclient.Conn(addr)
 	if err != nil { return nil, err }
-	defer cc.Close()
-	client := cartpb.NewCartClient(cc)
-	ctx, cancel := context.WithTimeout(ctx, 4*time.Second)
-	defer cancel()
-	return client.GetCart(ctx, &commonpb.UserRef{UserId: userID})
+	return &CartClient{cli: cartpb.NewCartClient(cc)}, nil
+}
+
+// CheckoutSnapshot congela el carrito bajo checkoutID; reintentos devuelven el mismo snapshot.
+func (c *CartClient) CheckoutSnapshot(ctx context.Context, userID int64, checkoutID string) (*cartpb.CheckoutSnapshotResponse, error) {
+	return c.cli.CheckoutSnapshot(ctx, &cartpb.CheckoutSnapshotRequest{UserId: userID, CheckoutId: checkoutID})
+}
+
+// RestoreCheckout devuelve al carrito un checkout que no llegó a tener orden.
+func (c *CartClient) RestoreCheckout(ctx context.Context, userID int64, checkoutID string) error {
+	_, err := c.cli.RestoreCheckout(ctx, &cartpb.RestoreCheckoutRequest{UserId: userID, CheckoutId: checkoutID})
+	return err
 }
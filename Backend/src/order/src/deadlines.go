@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+)
+
+// Plazos de la saga: cada paso que espera una respuesta (reserva de inventario,
+// cobro) tiene un vencimiento guardado en saga_deadlines. Si la respuesta no
+// llega a tiempo la orden se cancela, se publica order.expired para liberar la
+// reserva y anular el cobro, y order.failed para que Cart restaure el checkout.
+
+// SagaTimeouts son los plazos por paso (ver Config).
+type SagaTimeouts struct {
+	Inventory time.Duration
+	Payment   time.Duration
+	Checkout  time.Duration // snapshot sin orden guardada; 0 = no se barre
+}
+
+func (t SagaTimeouts) deadline(orderID int64, step string) *SagaDeadline {
+	d := t.Inventory
+	if step == SagaStepPayment {
+		d = t.Payment
+	}
+	return &SagaDeadline{OrderID: orderID, Step: step, DueUnix: time.Now().Add(d).Unix()}
+}
+
+// RunDeadlines revisa los plazos cada interval hasta que ctx termina. Como los
+// plazos están en la base, los que vencieron con el servicio caído se procesan
+// en la primera pasada.
+func (s *OrderServer) RunDeadlines(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		s.expireDue(ctx, time.Now())
+		s.sweepCheckouts(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *OrderServer) expireDue(ctx context.Context, now time.Time) {
+	due, err := s.repo.DueDeadlines(ctx, now.Unix())
+	if err != nil {
+		slog.ErrorContext(ctx, "deadlines", "err", err)
+		return
+	}
+	for _, d := range due {
+		// Cada vencimiento es una operación propia: su correlation_id sigue a order.expired y order.failed
+		ctx := logging.NewContext(ctx, logging.NewID())
+		if err := s.expireOrder(ctx, d); err != nil {
+			slog.ErrorContext(ctx, "expire", "order_id", d.OrderID, "err", err)
+		}
+	}
+}
+
+// sweepCheckouts devuelve al carrito los snapshots anotados hace más de
+// timeouts.Checkout que no llegaron a tener orden: el proceso cayó entre
+// CheckoutSnapshot y guardar la orden.
+func (s *OrderServer) sweepCheckouts(ctx context.Context, now time.Time) {
+	if s.timeouts.Checkout <= 0 { return }
+	stale, err := s.repo.ClaimStaleCheckouts(ctx, now.Add(-s.timeouts.Checkout).Unix())
+	if err != nil {
+		slog.ErrorContext(ctx, "checkouts huérfanos", "err", err)
+		return
+	}
+	for _, ci := range stale {
+		ctx := logging.NewContext(ctx, logging.NewID())
+		slog.InfoContext(ctx, "checkout sin orden; se devuelve al carrito", "checkout_id", ci.CheckoutID, "user_id", ci.UserID)
+		s.restoreCheckout(ctx, ci)
+	}
+}
+
+// restoreCheckout pide a Cart devolver el snapshot y borra la anotación. Si Cart
+// no responde, la anotación queda (o vuelve) para la próxima pasada del barrido.
+func (s *OrderServer) restoreCheckout(ctx context.Context, ci CheckoutIntent) {
+	if err := s.cart.RestoreCheckout(ctx, ci.UserID, ci.CheckoutID); err != nil {
+		slog.WarnContext(ctx, "restore checkout", "checkout_id", ci.CheckoutID, "err", err)
+		if ci.CreatedUnix != 0 {
+			if err := s.repo.SaveCheckoutIntent(ctx, ci); err != nil {
+				slog.ErrorContext(ctx, "checkout intent", "checkout_id", ci.CheckoutID, "err", err)
+			}
+		}
+		return
+	}
+	if err := s.repo.DropCheckoutIntent(ctx, ci.CheckoutID); err != nil {
+		slog.WarnContext(ctx, "checkout intent", "checkout_id", ci.CheckoutID, "err", err)
+	}
+}
+
+// expireOrder cancela la orden si sigue esperando el paso vencido.
+func (s *OrderServer) expireOrder(ctx context.Context, d SagaDeadline) error {
+	reason := d.Step + "_timeout"
+	changed, err := s.repo.ExpireOrder(ctx, d.OrderID, reason)
+	if err != nil { return err }
+	if !changed { return nil } // la respuesta llegó justo antes; solo se borró el plazo
+	metrics.OrderFinished(statusLabel(OrderStatusCancelled))
+	o, err := s.repo.GetOrder(ctx, d.OrderID)
+	if err != nil { return err }
+	slog.InfoContext(ctx, "orden vencida", "order_id", o.ID, "step", d.Step, "due", time.Unix(d.DueUnix, 0).Format(time.RFC3339))
+	s.publishExpired(ctx, o)
+	s.publishOutcome(ctx, o, OrderStatusCancelled)
+	return nil
+}
+
+// publishExpired también se usa cuando la respuesta del paso llega después de
+// vencer: repetir el evento libera lo que se alcanzó a reservar.
+func (s *OrderServer) publishExpired(ctx context.Context, o *Order) {
+	items := make([]OrderItemEvt, 0, len(o.Items))
+	for _, it := range o.Items {
+		items = append(items, OrderItemEvt{BookID: it.BookID, Title: it.Title, Qty: it.Qty, UnitCents: it.UnitCents, LineCents: it.LineCents})
+	}
+	payload := OrderExpiredPayload{
+		OrderID:    o.ID,
+		UserID:     o.UserID,
+		CheckoutID: o.CheckoutID,
+		Reason:     o.StatusReason,
+		Items:      items,
+		At:         nowUnix(),
+	}
+	if err := s.rabbit.PublishJSON(ctx, RKOrderExpired, payload); err != nil {
+		slog.WarnContext(ctx, "publish", "rk", RKOrderExpired, "err", err)
+	}
+}
+
+// isExpired indica si la orden se canceló por un plazo vencido.
+func isExpired(o *Order) bool {
+	return o.Status == OrderStatusCancelled && strings.HasSuffix(o.StatusReason, "_timeout")
+}
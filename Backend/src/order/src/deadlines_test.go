@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+// TestInventoryTimeout: Inventory no responde; al vencer el plazo la orden se
+// cancela y se publica order.expired y order.failed. La reserva que llega
+// después solo repite order.expired para liberarse.
+func TestInventoryTimeout(t *testing.T) {
+	ctx := context.Background()
+	s, cart, rb := newTestServer(t)
+	cart.add(1, 10, 2, 1000)
+	resp, err := s.CreateOrder(asUser(1), &orderpb.CreateOrderRequest{UserId: 1, IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := resp.GetOrderId()
+
+	// Antes del plazo no pasa nada
+	s.expireDue(ctx, time.Now())
+	if o, _ := s.repo.GetOrder(ctx, id); o.Status != OrderStatusCreated {
+		t.Fatalf("estado = %d antes del plazo", o.Status)
+	}
+
+	s.expireDue(ctx, time.Now().Add(2*time.Minute))
+	o, err := s.repo.GetOrder(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != OrderStatusCancelled || o.StatusReason != "inventory_timeout" {
+		t.Fatalf("orden = %d/%q, se esperaba CANCELLED por inventory_timeout", o.Status, o.StatusReason)
+	}
+	want := []string{RKOrderCreated, RKOrderExpired, RKOrderFailed}
+	if got := rb.keys(); !slices.Equal(got, want) {
+		t.Fatalf("publicados = %v, se esperaba %v", got, want)
+	}
+	var ev OrderExpiredPayload
+	if err := json.Unmarshal(rb.out[1].body, &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.OrderID != id || ev.CheckoutID != "k1" || len(ev.Items) != 1 || ev.Items[0].Qty != 2 {
+		t.Fatalf("order.expired = %+v", ev)
+	}
+
+	// El plazo se consumió: otra pasada no vuelve a cancelar
+	s.expireDue(ctx, time.Now().Add(time.Hour))
+	if len(rb.keys()) != 3 {
+		t.Fatalf("publicados = %v tras la segunda pasada", rb.keys())
+	}
+
+	// Reserva tardía: no se cobra, se libera otra vez
+	body, _ := json.Marshal(InventoryResultPayload{OrderID: id, OK: true})
+	if err := s.handleEvent(ctx, RKInventoryReserved, body); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, RKOrderExpired)
+	if got := rb.keys(); !slices.Equal(got, want) {
+		t.Fatalf("publicados = %v, se esperaba %v", got, want)
+	}
+}
@@ -3,15 +3,31 @@ package main
 // Eventos enviados por Order a otros servicios
 const (
 	RKOrderCreated        = "order.created"
-	RKPaymentCharge       = "payment.charge" // publicado por Order cuando inventario confirma
+	RKPaymentCharge       = "payment.charge.requested" // publicado por Order cuando inventario confirma
+	RKOrderPaid           = "order.paid"     // resultado final: Cart descarta el checkout
+	RKOrderFailed         = "order.failed"   // resultado final: Cart restaura el checkout
+	RKOrderExpired        = "order.expired"  // un paso de la saga venció: Inventory libera, Payment anula
+	RKOrderShipped        = "order.shipped"
+	RKOrderDelivered      = "order.delivered"
+	RKReturnRequested     = "return.requested"
+	RKReturnApproved      = "return.approved"
+	RKReturnRejected      = "return.rejected"
+	RKReturnReceived      = "return.received"    // Inventory reingresa el stock
+	RKReturnRefunded      = "return.refunded"
+	RKReturnRefundFailed  = "return.refund_failed"
+	RKPaymentRefund       = "payment.refund.requested"
 )
 
 // Eventos recibidos por Order
 const (
 	RKInventoryReserved   = "inventory.reserved"
 	RKInventoryRejected   = "inventory.rejected"
-	RKPaymentCaptured     = "payment.captured"
+	RKPaymentSucceeded    = "payment.succeeded"
+	RKPaymentDeferred     = "payment.deferred" // contra entrega: despachar sin cobrar
 	RKPaymentFailed       = "payment.failed"
+	RKInventoryRestocked  = "inventory.restocked"
+	RKPaymentRefunded     = "payment.refunded"
+	RKPaymentRefundFailed = "payment.refund_failed"
 )
 
 type OrderCreatedPayload struct {
@@ -36,14 +52,80 @@ type InventoryResultPayload struct {
 }
 
 type PaymentChargePayload struct {
+	OrderID      int64  `json:"order_id"`
+	UserID       int64  `json:"user_id"`
+	AmountCents  int64  `json:"amount_cents"`
+	Method       string `json:"method,omitempty"` // nombre de common.PaymentMethod
+	InstrumentID int64  `json:"instrument_id,omitempty"`
+	WalletCents  int64  `json:"wallet_cents,omitempty"` // parte a pagar con el saldo a favor
+}
+
+// Resultado de payment.succeeded / payment.deferred / payment.failed
+type PaymentResultPayload struct {
+	OrderID     int64  `json:"order_id"`
+	ProviderRef string `json:"provider_ref"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+
+type OrderOutcomePayload struct {
 	OrderID    int64  `json:"order_id"`
 	UserID     int64  `json:"user_id"`
-	TotalCents int64  `json:"total_cents"`
+	CheckoutID string `json:"checkout_id"`
+	Status     string `json:"status"`
 }
 
-type PaymentResultPayload struct {
-	OrderID int64  `json:"order_id"`
-	OK      bool   `json:"ok"`
-	Reason  string `json:"reason,omitempty"`
+// OrderExpiredPayload: Inventory libera la reserva de Items y Payment anula o
+// reembolsa el cobro. Reason es "<paso>_timeout".
+type OrderExpiredPayload struct {
+	OrderID    int64          `json:"order_id"`
+	UserID     int64          `json:"user_id"`
+	CheckoutID string         `json:"checkout_id"`
+	Reason     string         `json:"reason"`
+	Items      []OrderItemEvt `json:"items"`
+	At         int64          `json:"at_unix"`
+}
+
+type OrderShipmentPayload struct {
+	OrderID        int64  `json:"order_id"`
+	UserID         int64  `json:"user_id"`
+	Carrier        string `json:"carrier"`
+	TrackingNumber string `json:"tracking_number"`
+	At             int64  `json:"at_unix"`
+}
+
+// ReturnPayload acompaña a todos los eventos return.*; Items son las unidades devueltas.
+type ReturnPayload struct {
+	ReturnID    int64           `json:"return_id"`
+	OrderID     int64           `json:"order_id"`
+	UserID      int64           `json:"user_id"`
+	State       string          `json:"state"`
+	Items       []ReturnItemEvt `json:"items"`
+	RefundCents int64           `json:"refund_cents"`
+	Note        string          `json:"note,omitempty"`
+}
+
+type ReturnItemEvt struct {
+	BookID int64 `json:"book_id"`
+	Qty    int32 `json:"qty"`
+}
+
+type RefundRequestPayload struct {
+	OrderID     int64  `json:"order_id"`
+	ReturnID    int64  `json:"return_id"`
+	AmountCents int64  `json:"amount_cents"`
+	Reason      string `json:"reason"`
 }
 
+type RefundResultPayload struct {
+	OrderID     int64  `json:"order_id"`
+	ReturnID    int64  `json:"return_id"`
+	AmountCents int64  `json:"amount_cents"`
+	ProviderRef string `json:"provider_ref"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type RestockedPayload struct {
+	OrderID  int64 `json:"order_id"`
+	ReturnID int64 `json:"return_id"`
+}
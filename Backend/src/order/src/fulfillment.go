@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+// OrderAdminServer expone las operaciones de bodega sobre el despacho.
+type OrderAdminServer struct {
+	orderpb.UnimplementedOrderAdminServer
+	repo   *Repository
+	rabbit broker
+}
+
+func NewOrderAdminServer(repo *Repository, rb broker) *OrderAdminServer {
+	return &OrderAdminServer{repo: repo, rabbit: rb}
+}
+
+func (s *OrderAdminServer) UpdateFulfillment(ctx context.Context, req *orderpb.UpdateFulfillmentRequest) (*orderpb.Fulfillment, error) {
+	target := int32(req.GetState())
+	if target < FulfillmentPicking || target > FulfillmentDelivered {
+		return nil, status.Error(codes.InvalidArgument, "state debe ser PICKING, PACKED, SHIPPED o DELIVERED")
+	}
+	o, err := s.repo.GetOrder(ctx, req.GetOrderId())
+	if err != nil { return nil, orderErr(err) }
+	f, err := s.repo.GetFulfillment(ctx, o.ID)
+	if err != nil { return nil, err }
+	if f == nil {
+		return nil, status.Error(codes.FailedPrecondition, "la orden no está pagada")
+	}
+
+	// Repetir el estado actual no cambia nada (los reintentos del admin son seguros)
+	if target == f.State { return fulfillmentToPB(f), nil }
+	if target < f.State {
+		return nil, status.Errorf(codes.FailedPrecondition, "el despacho ya está en %s", orderpb.FulfillmentState(f.State))
+	}
+	// Un paso a la vez: PENDING → PICKING → PACKED → SHIPPED → DELIVERED
+	if target != f.State+1 {
+		return nil, status.Errorf(codes.FailedPrecondition, "el despacho está en %s; el siguiente paso es %s",
+			orderpb.FulfillmentState(f.State), orderpb.FulfillmentState(f.State+1))
+	}
+
+	from := f.State
+	now := nowUnix()
+	switch target {
+	case FulfillmentShipped:
+		if o.ShippingAddress == nil {
+			return nil, status.Error(codes.FailedPrecondition, "la orden no tiene dirección de envío")
+		}
+		if req.GetCarrier() == "" || req.GetTrackingNumber() == "" {
+			return nil, status.Error(codes.InvalidArgument, "carrier y tracking_number son obligatorios para SHIPPED")
+		}
+		f.Carrier = req.GetCarrier()
+		f.TrackingNumber = req.GetTrackingNumber()
+		f.ShippedUnix = now
+	case FulfillmentDelivered:
+		f.DeliveredUnix = now
+	}
+	f.State = target
+	f.UpdatedUnix = now
+
+	if err := s.repo.UpdateFulfillment(ctx, f, from); err != nil {
+		if errors.Is(err, ErrFulfillmentChanged) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, err
+	}
+
+	switch target {
+	case FulfillmentShipped:
+		s.publishShipment(ctx, RKOrderShipped, o, f, f.ShippedUnix)
+	case FulfillmentDelivered:
+		s.publishShipment(ctx, RKOrderDelivered, o, f, f.DeliveredUnix)
+	}
+	return fulfillmentToPB(f), nil
+}
+
+func (s *OrderAdminServer) publishShipment(ctx context.Context, rk string, o *Order, f *Fulfillment, at int64) {
+	payload := OrderShipmentPayload{
+		OrderID:        o.ID,
+		UserID:         o.UserID,
+		Carrier:        f.Carrier,
+		TrackingNumber: f.TrackingNumber,
+		At:             at,
+	}
+	if err := s.rabbit.PublishJSON(ctx, rk, payload); err != nil {
+		slog.WarnContext(ctx, "publish", "rk", rk, "err", err)
+	}
+}
+
+// orderErr traduce el error de GetOrder: NotFound solo si la orden no existe.
+func orderErr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, "orden no encontrada")
+	}
+	return err
+}
+
+func fulfillmentToPB(f *Fulfillment) *orderpb.Fulfillment {
+	if f == nil { return nil }
+	return &orderpb.Fulfillment{
+		OrderId:        f.OrderID,
+		State:          orderpb.FulfillmentState(f.State),
+		Carrier:        f.Carrier,
+		TrackingNumber: f.TrackingNumber,
+		ShippedUnix:    f.ShippedUnix,
+		DeliveredUnix:  f.DeliveredUnix,
+		UpdatedUnix:    f.UpdatedUnix,
+	}
+}
+
+func addressToPB(a *ShippingAddress) *commonpb.Address {
+	if a == nil { return nil }
+	return &commonpb.Address{
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+	}
+}
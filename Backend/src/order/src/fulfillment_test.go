@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+// paidOrder guarda una orden con dirección y la cierra como pagada, lo que abre
+// el despacho en PENDING.
+func paidOrder(t *testing.T, s *OrderServer) int64 {
+	t.Helper()
+	ctx := context.Background()
+	now := nowUnix()
+	id, err := s.repo.CreateOrder(ctx, &Order{
+		UserID: 1, Status: OrderStatusCreated, TotalCents: 2000, SubtotalCents: 2000,
+		CreatedUnix: now, UpdatedUnix: now,
+		Items:           []OrderItem{{BookID: 10, Title: "libro", Qty: 2, UnitCents: 1000, LineCents: 2000}},
+		ShippingAddress: &ShippingAddress{Recipient: "Ana", Line1: "Calle 1", City: "Medellín", Country: "CO"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.finishOrder(ctx, id, OrderStatusPaid); err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestEnsureFulfillmentRecordsEvent(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestServer(t)
+	id := paidOrder(t, s)
+
+	last, err := s.repo.LastEvent(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last == nil || last.Status != OrderStatusPaid || last.FulfillmentState != FulfillmentPending {
+		t.Fatalf("último evento = %+v, se esperaba PAID con despacho PENDING", last)
+	}
+	// Abrirlo de nuevo no agrega historial
+	if err := s.repo.EnsureFulfillment(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.repo.LastEvent(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != last.ID {
+		t.Fatalf("evento repetido: %+v", again)
+	}
+}
+
+func TestUpdateFulfillment(t *testing.T) {
+	ctx := context.Background()
+	s, _, rb := newTestServer(t)
+	admin := NewOrderAdminServer(s.repo, rb)
+	id := paidOrder(t, s)
+
+	step := func(state orderpb.FulfillmentState) (*orderpb.Fulfillment, error) {
+		return admin.UpdateFulfillment(ctx, &orderpb.UpdateFulfillmentRequest{
+			OrderId: id, State: state, Carrier: "Servientrega", TrackingNumber: "SV-1",
+		})
+	}
+
+	// No se salta pasos
+	if _, err := step(orderpb.FulfillmentState_FULFILLMENT_STATE_SHIPPED); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("PENDING → SHIPPED: err = %v, se esperaba FailedPrecondition", err)
+	}
+	for _, st := range []orderpb.FulfillmentState{
+		orderpb.FulfillmentState_FULFILLMENT_STATE_PICKING,
+		orderpb.FulfillmentState_FULFILLMENT_STATE_PICKING, // repetido: no cambia nada
+		orderpb.FulfillmentState_FULFILLMENT_STATE_PACKED,
+		orderpb.FulfillmentState_FULFILLMENT_STATE_SHIPPED,
+		orderpb.FulfillmentState_FULFILLMENT_STATE_DELIVERED,
+	} {
+		f, err := step(st)
+		if err != nil {
+			t.Fatalf("%s: %v", st, err)
+		}
+		if f.GetState() != st {
+			t.Fatalf("estado = %s, se esperaba %s", f.GetState(), st)
+		}
+	}
+	if _, err := step(orderpb.FulfillmentState_FULFILLMENT_STATE_PACKED); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("DELIVERED → PACKED: err = %v", err)
+	}
+
+	got := rb.keys()
+	want := []string{RKOrderPaid, RKOrderShipped, RKOrderDelivered}
+	if len(got) != len(want) {
+		t.Fatalf("publicados = %v, se esperaba %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("publicados = %v, se esperaba %v", got, want)
+		}
+	}
+
+	// Historial: creada, pagada, PENDING y un evento por cada paso real
+	events, err := s.repo.ListEvents(ctx, id, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 7 || events[len(events)-1].FulfillmentState != FulfillmentDelivered {
+		t.Fatalf("eventos = %+v", events)
+	}
+}
+
+func TestUpdateFulfillmentNotFound(t *testing.T) {
+	s, _, rb := newTestServer(t)
+	admin := NewOrderAdminServer(s.repo, rb)
+	_, err := admin.UpdateFulfillment(context.Background(), &orderpb.UpdateFulfillmentRequest{
+		OrderId: 99, State: orderpb.FulfillmentState_FULFILLMENT_STATE_PICKING,
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("err = %v, se esperaba NotFound", err)
+	}
+}
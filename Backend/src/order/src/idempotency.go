@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+// hashCreateOrderRequest resume el payload (sin la clave) para detectar reusos con otro contenido.
+// wallet_amount queda fuera: el cliente lo arma con el saldo que ve, y tras el
+// primer intento Payment ya debitó el monedero, así que el reintento llega con
+// otro monto y tiene que devolver la orden original.
+func hashCreateOrderRequest(req *orderpb.CreateOrderRequest) string {
+	c := proto.Clone(req).(*orderpb.CreateOrderRequest)
+	c.IdempotencyKey = ""
+	c.WalletAmount = nil
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(c)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// replayCreateOrder devuelve la respuesta guardada para la clave, (nil, nil) si la clave
+// es nueva, o un error si la clave ya se usó con un payload distinto.
+func (s *OrderServer) replayCreateOrder(ctx context.Context, key, reqHash string) (*orderpb.CreateOrderResponse, error) {
+	rec, err := s.repo.GetIdempotencyKey(ctx, key)
+	if err != nil { return nil, err }
+	if rec == nil { return nil, nil }
+	if rec.RequestHash != reqHash {
+		return nil, status.Error(codes.InvalidArgument, "idempotency_key ya usada con otra solicitud")
+	}
+	var resp orderpb.CreateOrderResponse
+	if err := proto.Unmarshal(rec.StoredResponse, &resp); err != nil { return nil, err }
+	return &resp, nil
+}
@@ -1,43 +1,87 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/mtls"
+	"github.com/ahinestrog/mybookstore/pkg/pricing"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
 )
 
 func main() {
+	logging.Setup("order")
+	flushTraces, err := tracing.Setup("order")
+	if err != nil { logging.Fatal("tracing", err) }
+	defer flushTraces(context.Background())
+	// /metrics en ORDER_METRICS_ADDR
+	if err := metrics.Setup("order"); err != nil { logging.Fatal("metrics", err) }
 	cfg := LoadConfig()
 
 	repo, err := NewRepository(cfg.DBPath)
-	if err != nil { log.Fatalf("db err: %v", err) }
+	if err != nil { logging.Fatal("db", err) }
 	defer repo.Close()
 
 	rb, err := NewRabbit(cfg.RabbitURL, cfg.RabbitExchange)
-	if err != nil { log.Fatalf("rabbit err: %v", err) }
+	if err != nil { logging.Fatal("rabbit", err) }
 	defer rb.Close()
 
-	cartClient := NewCartClient(cfg.CartGRPCAddr)
+	// mTLS (GRPC_TLS_MODE): la gestión solo desde herramientas con certificado admin
+	tlsOpts, err := mtls.Setup("order", mtls.Peers{
+		"/order.Order/":      {"storefront"},
+		"/order.OrderAdmin/": {"admin"},
+	})
+	if err != nil { logging.Fatal("mtls", err) }
 
-	srv := NewOrderServer(repo, rb, cartClient)
+	// Tokens de gRPC: las llamadas a cart/user llevan al usuario del RPC entrante,
+	// o al servicio order en consumidores y vencimientos
+	authority, err := auth.SetupClients(auth.Service("order"))
+	if err != nil { logging.Fatal("auth", err) }
+
+	cartClient, err := NewCartClient(cfg.CartGRPCAddr)
+	if err != nil { logging.Fatal("cart client", err) }
+	defer grpcclient.CloseAll()
+
+	userClient, err := NewUserClient(cfg.UserGRPCAddr)
+	if err != nil { logging.Fatal("user client", err) }
+
+	rules, err := pricing.Load(cfg.PricingRules)
+	if err != nil { logging.Fatal("pricing", err) }
+
+	srv := NewOrderServer(repo, rb, cartClient, userClient, rules, cfg.ReturnWindow,
+		SagaTimeouts{Inventory: cfg.InventoryTimeout, Payment: cfg.PaymentTimeout, Checkout: cfg.CheckoutTTL})
 	if err := srv.StartConsumers(); err != nil {
-		log.Fatalf("consumers err: %v", err)
+		logging.Fatal("consumers", err)
 	}
+	go srv.RunDeadlines(context.Background(), cfg.DeadlineScan)
 
-	grpcServer := grpc.NewServer()
+	opts := grpcclient.ServerOptions()
+	opts = append(opts, tracing.ServerOptions()...)
+	opts = append(opts, metrics.ServerOptions()...)
+	opts = append(opts, logging.ServerOptions()...)
+	opts = append(opts, tlsOpts...)
+	grpcServer := grpc.NewServer(append(opts,
+		authority.ServerOptions(auth.Rules{Admin: []string{"/order.OrderAdmin/"}})...)...)
 	orderpb.RegisterOrderServer(grpcServer, srv)
+	orderpb.RegisterOrderAdminServer(grpcServer, NewOrderAdminServer(repo, rb))
+	grpcclient.RegisterHealth(grpcServer)
 	reflection.Register(grpcServer)
 
 	lis, err := net.Listen("tcp", cfg.GRPCAddr)
-	if err != nil { log.Fatalf("listen err: %v", err) }
-	log.Printf("[order] gRPC listening on %s", cfg.GRPCAddr)
+	if err != nil { logging.Fatal("listen", err) }
+	slog.Info("gRPC escuchando", "addr", cfg.GRPCAddr)
 
 	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("serve err: %v", err)
+		logging.Fatal("serve", err)
 	}
 }
 
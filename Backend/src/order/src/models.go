@@ -9,16 +9,118 @@ const (
 	OrderStatusPaid        = 2
 	OrderStatusCancelled   = 3
 	OrderStatusFailed      = 4
+	OrderStatusConfirmed   = 5 // contra entrega: en despacho, se cobra al entregar
 )
 
 type Order struct {
 	ID          int64     `db:"id"`
 	UserID      int64     `db:"user_id"`
 	Status      int32     `db:"status"`
-	TotalCents  int64     `db:"total_cents"`
+	TotalCents  int64     `db:"total_cents"` // total a pagar (subtotal - descuento + IVA + envío)
+	SubtotalCents int64   `db:"subtotal_cents"`
+	DiscountCents int64   `db:"discount_cents"`
+	TaxCents      int64   `db:"tax_cents"`
+	ShippingCents int64   `db:"shipping_cents"`
+	CheckoutID  string    `db:"checkout_id"` // snapshot congelado en Cart
+	PaymentMethod int32   `db:"payment_method"` // common.PaymentMethod
+	InstrumentID  int64   `db:"instrument_id"`  // tarjeta guardada en Payment
+	WalletCents   int64   `db:"wallet_cents"`   // parte pagada con el saldo a favor
+	StatusReason  string  `db:"status_reason"`  // por qué se canceló (p.ej. payment_timeout)
 	CreatedUnix int64     `db:"created_unix"`
 	UpdatedUnix int64     `db:"updated_unix"`
 	Items       []OrderItem
+	ShippingAddress *ShippingAddress // nil si la orden se creó sin dirección
+	Deadline    *SagaDeadline `db:"-"` // plazo del primer paso; se guarda con la orden
+}
+
+// Pasos de la saga con plazo; al vencer, la orden se cancela con "<paso>_timeout".
+const (
+	SagaStepInventory = "inventory" // esperando inventory.reserved/rejected
+	SagaStepPayment   = "payment"   // esperando payment.succeeded/deferred/failed
+)
+
+// SagaDeadline es el plazo del paso en curso de una orden CREATED. Vive en la
+// base para que un reinicio no lo pierda.
+type SagaDeadline struct {
+	OrderID int64  `db:"order_id"`
+	Step    string `db:"step"`
+	DueUnix int64  `db:"due_unix"`
+}
+
+// CheckoutIntent anota un CheckoutSnapshot pedido a Cart que todavía no tiene
+// orden guardada.
+type CheckoutIntent struct {
+	CheckoutID  string `db:"checkout_id"`
+	UserID      int64  `db:"user_id"`
+	CreatedUnix int64  `db:"created_unix"`
+}
+
+// ShippingAddress es la copia congelada de la dirección elegida en el checkout.
+type ShippingAddress struct {
+	Recipient  string `db:"recipient"`
+	Line1      string `db:"line1"`
+	Line2      string `db:"line2"`
+	City       string `db:"city"`
+	Region     string `db:"region"`
+	PostalCode string `db:"postal_code"`
+	Country    string `db:"country"`
+	Phone      string `db:"phone"`
+}
+
+// Estados del despacho con la firma de FulfillmentState en order.proto
+const (
+	FulfillmentPending   = 1
+	FulfillmentPicking   = 2
+	FulfillmentPacked    = 3
+	FulfillmentShipped   = 4
+	FulfillmentDelivered = 5
+)
+
+type Fulfillment struct {
+	OrderID        int64  `db:"order_id"`
+	State          int32  `db:"state"`
+	Carrier        string `db:"carrier"`
+	TrackingNumber string `db:"tracking_number"`
+	ShippedUnix    int64  `db:"shipped_unix"`
+	DeliveredUnix  int64  `db:"delivered_unix"`
+	UpdatedUnix    int64  `db:"updated_unix"`
+}
+
+// Estados de la devolución con la firma de ReturnState en order.proto
+const (
+	ReturnRequested    = 1
+	ReturnApproved     = 2
+	ReturnRejected     = 3
+	ReturnReceived     = 4
+	ReturnRefunded     = 5
+	ReturnRefundFailed = 6
+)
+
+// Return es una solicitud de devolución (RMA) sobre una orden entregada.
+type Return struct {
+	ID                  int64  `db:"id"`
+	OrderID             int64  `db:"order_id"`
+	UserID              int64  `db:"user_id"`
+	State               int32  `db:"state"`
+	Note                string `db:"note"`
+	ReviewNote          string `db:"review_note"`
+	RefundCents         int64  `db:"refund_cents"`          // líneas + envío reembolsado
+	ShippingRefundCents int64  `db:"shipping_refund_cents"` // solo si la orden queda devuelta por completo
+	RefundRef           string `db:"refund_ref"`
+	RestockedUnix       int64  `db:"restocked_unix"`
+	CreatedUnix         int64  `db:"created_unix"`
+	UpdatedUnix         int64  `db:"updated_unix"`
+	Lines               []ReturnLine
+}
+
+type ReturnLine struct {
+	ID          int64  `db:"id"`
+	ReturnID    int64  `db:"return_id"`
+	BookID      int64  `db:"book_id"`
+	Title       string `db:"title"`
+	Qty         int32  `db:"qty"`
+	Reason      int32  `db:"reason"` // ReturnReason de order.proto
+	RefundCents int64  `db:"refund_cents"`
 }
 
 type OrderItem struct {
@@ -29,6 +131,31 @@ type OrderItem struct {
 	Qty        int32  `db:"qty"`
 	UnitCents  int64  `db:"unit_cents"`
 	LineCents  int64  `db:"line_cents"`
+	TaxCents   int64  `db:"tax_cents"`
+}
+
+// OrderEvent es una fila de order_events: el estado de la orden después de
+// cada transición (creación, resultado de la saga, cobro contra entrega, despacho).
+type OrderEvent struct {
+	ID               int64  `db:"id"`
+	OrderID          int64  `db:"order_id"`
+	Status           int32  `db:"status"`
+	StatusReason     string `db:"status_reason"`
+	FulfillmentState int32  `db:"fulfillment_state"`
+	CreatedUnix      int64  `db:"created_unix"`
+}
+
+// IdempotencyRecord guarda la primera respuesta de CreateOrder para una clave dada.
+type IdempotencyRecord struct {
+	Key            string `db:"idem_key"`
+	UserID         int64  `db:"user_id"`
+	RequestHash    string `db:"request_hash"`
+	OrderID        int64  `db:"order_id"`
+	StoredResponse []byte `db:"response"`
+	CreatedUnix    int64  `db:"created_unix"`
+
+	// Response serializa la respuesta al insertar (no se persiste como tal).
+	Response func(orderID int64) ([]byte, error) `db:"-"`
 }
 
 func nowUnix() int64 { return time.Now().Unix() }
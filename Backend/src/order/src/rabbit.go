@@ -3,11 +3,23 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
+// broker es lo que los servidores usan de RabbitMQ; en producción es *Rabbit.
+type broker interface {
+	PublishJSON(ctx context.Context, routingKey string, v any) error
+	ConsumeTopic(queueName string, bindings []string, handler ConsumerHandler) error
+	QueueDepth(queue string) (int, error)
+}
+
 type Rabbit struct {
 	conn     *amqp.Connection
 	ch       *amqp.Channel
@@ -30,16 +42,35 @@ func (r *Rabbit) Close() {
 	if r.conn != nil { _ = r.conn.Close() }
 }
 
-func (r *Rabbit) PublishJSON(routingKey string, v any) error {
+// PublishJSON publica v con el correlation_id y el contexto de traza de ctx en los headers.
+func (r *Rabbit) PublishJSON(ctx context.Context, routingKey string, v any) error {
 	body, err := json.Marshal(v)
 	if err != nil { return err }
-	return r.ch.PublishWithContext(context.Background(), r.exchange, routingKey, false, false, amqp.Publishing{
+	ctx, span := tracing.StartPublish(ctx, r.exchange, routingKey)
+	err = r.ch.PublishWithContext(ctx, r.exchange, routingKey, false, false, amqp.Publishing{
 		ContentType: "application/json",
+		Headers:     tracing.Inject(ctx, logging.Headers(ctx)),
 		Body:        body,
+		Timestamp:   time.Now(),
 	})
+	tracing.End(span, err)
+	metrics.Published(routingKey, err)
+	return err
+}
+
+// QueueDepth lee del broker los mensajes en espera de queue (pkg/metrics). Usa
+// un canal propio: la declaración pasiva de una cola inexistente cierra el canal.
+func (r *Rabbit) QueueDepth(queue string) (int, error) {
+	ch, err := r.conn.Channel()
+	if err != nil { return 0, err }
+	defer ch.Close()
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil { return 0, err }
+	return q.Messages, nil
 }
 
-type ConsumerHandler func(rk string, body []byte) error
+// ConsumerHandler recibe en ctx el correlation_id y el span del mensaje.
+type ConsumerHandler func(ctx context.Context, rk string, body []byte) error
 
 func (r *Rabbit) ConsumeTopic(queueName string, bindings []string, handler ConsumerHandler) error {
 	q, err := r.ch.QueueDeclare(queueName, true, false, false, false, nil)
@@ -54,11 +85,17 @@ func (r *Rabbit) ConsumeTopic(queueName string, bindings []string, handler Consu
 
 	go func() {
 		for d := range msgs {
-			if err := handler(d.RoutingKey, d.Body); err != nil {
-				log.Printf("[rabbit] handler error for rk=%s: %v", d.RoutingKey, err)
+			ctx := logging.FromHeaders(context.Background(), d.Headers)
+			ctx, span := tracing.StartConsume(ctx, d.Headers, queueName, d.RoutingKey)
+			metrics.Received(queueName, d.RoutingKey, d.Timestamp)
+			err := handler(ctx, d.RoutingKey, d.Body)
+			if err != nil {
+				slog.ErrorContext(ctx, "rabbit: handler", "rk", d.RoutingKey, "err", err)
 			}
+			tracing.End(span, err)
+			metrics.Consumed(queueName, d.RoutingKey, err)
 		}
-		log.Printf("[rabbit] consumer %s stopped", queueName)
+		slog.Warn("rabbit: consumidor detenido", "queue", queueName)
 	}()
 	return nil
 }
@@ -3,25 +3,34 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 
 	_ "modernc.org/sqlite" // driver 100% Go
+
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
 type Repository struct {
-	db *sql.DB
+	db       *sql.DB
+	watchers *orderWatchers // streams WatchOrder abiertos; se avisan al guardar un evento
 }
 
 func NewRepository(dbPath string) (*Repository, error) {
-	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(ON)", dbPath)
-	db, err := sql.Open("sqlite", dsn)
+	// El consumidor de la saga, el barrido de plazos y los RPC escriben a la vez:
+	// una sola conexión serializa las transacciones, y busy_timeout espera en vez
+	// de fallar con "database is locked" si otro proceso tiene el archivo.
+	dsn := fmt.Sprintf("file:%s?_pragma=busy_timeout=5000&_pragma=foreign_keys(ON)", dbPath)
+	db, err := tracing.OpenSQLite("sqlite", dsn)
 	if err != nil {
 		return nil, err
 	}
+	db.SetMaxOpenConns(1)
 	if err := migrate(db); err != nil {
 		return nil, err
 	}
-	return &Repository{db: db}, nil
+	return &Repository{db: db, watchers: newOrderWatchers()}, nil
 }
 
 func migrate(db *sql.DB) error {
@@ -44,77 +53,644 @@ CREATE TABLE IF NOT EXISTS order_items(
   line_cents INTEGER NOT NULL,
   FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE
 );
+CREATE TABLE IF NOT EXISTS idempotency_keys(
+  idem_key TEXT PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  request_hash TEXT NOT NULL,
+  order_id INTEGER NOT NULL,
+  response BLOB NOT NULL,
+  created_unix INTEGER NOT NULL,
+  FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS order_addresses(
+  order_id INTEGER PRIMARY KEY,
+  recipient TEXT NOT NULL,
+  line1 TEXT NOT NULL,
+  line2 TEXT NOT NULL DEFAULT '',
+  city TEXT NOT NULL,
+  region TEXT NOT NULL DEFAULT '',
+  postal_code TEXT NOT NULL DEFAULT '',
+  country TEXT NOT NULL,
+  phone TEXT NOT NULL DEFAULT '',
+  FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS fulfillments(
+  order_id INTEGER PRIMARY KEY,
+  state INTEGER NOT NULL,
+  carrier TEXT NOT NULL DEFAULT '',
+  tracking_number TEXT NOT NULL DEFAULT '',
+  shipped_unix INTEGER NOT NULL DEFAULT 0,
+  delivered_unix INTEGER NOT NULL DEFAULT 0,
+  updated_unix INTEGER NOT NULL,
+  FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS returns(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  order_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  state INTEGER NOT NULL,
+  note TEXT NOT NULL DEFAULT '',
+  review_note TEXT NOT NULL DEFAULT '',
+  refund_cents INTEGER NOT NULL,
+  shipping_refund_cents INTEGER NOT NULL DEFAULT 0,
+  refund_ref TEXT NOT NULL DEFAULT '',
+  restocked_unix INTEGER NOT NULL DEFAULT 0,
+  created_unix INTEGER NOT NULL,
+  updated_unix INTEGER NOT NULL,
+  FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS return_lines(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  return_id INTEGER NOT NULL,
+  book_id INTEGER NOT NULL,
+  title TEXT NOT NULL,
+  qty INTEGER NOT NULL,
+  reason INTEGER NOT NULL,
+  refund_cents INTEGER NOT NULL,
+  FOREIGN KEY(return_id) REFERENCES returns(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS saga_deadlines(
+  order_id INTEGER PRIMARY KEY,
+  step TEXT NOT NULL,
+  due_unix INTEGER NOT NULL,
+  FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS order_events(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  order_id INTEGER NOT NULL,
+  status INTEGER NOT NULL,
+  status_reason TEXT NOT NULL,
+  fulfillment_state INTEGER NOT NULL,
+  created_unix INTEGER NOT NULL,
+  FOREIGN KEY(order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+CREATE TABLE IF NOT EXISTS checkout_intents(
+  checkout_id TEXT PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  created_unix INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_order_events_order ON order_events(order_id, id);
+CREATE INDEX IF NOT EXISTS idx_deadlines_due ON saga_deadlines(due_unix);
 CREATE INDEX IF NOT EXISTS idx_orders_user ON orders(user_id);
+CREATE INDEX IF NOT EXISTS idx_returns_order ON returns(order_id);
+CREATE INDEX IF NOT EXISTS idx_return_lines_return ON return_lines(return_id);
 CREATE INDEX IF NOT EXISTS idx_items_order ON order_items(order_id);
 `
-	_, err := db.Exec(schema)
+	if _, err := db.Exec(schema); err != nil { return err }
+	// Columnas agregadas después de la primera versión del esquema
+	for _, c := range []struct{ table, column, def string }{
+		{"orders", "checkout_id", "TEXT NOT NULL DEFAULT ''"},
+		{"orders", "subtotal_cents", "INTEGER NOT NULL DEFAULT 0"},
+		{"orders", "discount_cents", "INTEGER NOT NULL DEFAULT 0"},
+		{"orders", "tax_cents", "INTEGER NOT NULL DEFAULT 0"},
+		{"orders", "shipping_cents", "INTEGER NOT NULL DEFAULT 0"},
+		{"order_items", "tax_cents", "INTEGER NOT NULL DEFAULT 0"},
+		{"orders", "payment_method", "INTEGER NOT NULL DEFAULT 0"},
+		{"orders", "instrument_id", "INTEGER NOT NULL DEFAULT 0"},
+		{"orders", "wallet_cents", "INTEGER NOT NULL DEFAULT 0"},
+		{"orders", "status_reason", "TEXT NOT NULL DEFAULT ''"},
+	} {
+		if err := ensureColumn(db, c.table, c.column, c.def); err != nil { return err }
+	}
+	// El barrido de checkouts busca órdenes por checkout_id
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_checkout ON orders(checkout_id)`); err != nil { return err }
+	// Órdenes anteriores al desglose: todo el total era subtotal
+	_, err := db.Exec(`UPDATE orders SET subtotal_cents=total_cents
+  WHERE subtotal_cents=0 AND discount_cents=0 AND tax_cents=0 AND shipping_cents=0`)
+	return err
+}
+
+// ensureColumn agrega la columna si la tabla fue creada con un esquema anterior.
+func ensureColumn(db *sql.DB, table, column, def string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil { return err }
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil { return err }
+		if name == column { return nil }
+	}
+	if err := rows.Err(); err != nil { return err }
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, def))
 	return err
 }
 
 func (r *Repository) Close() error { return r.db.Close() }
 
+// ErrIdempotencyKeyTaken indica que otra solicitud registró la misma clave primero.
+var ErrIdempotencyKeyTaken = errors.New("idempotency key ya registrada")
+
+// ErrCheckoutExpired indica que el barrido ya devolvió el snapshot al carrito.
+var ErrCheckoutExpired = errors.New("checkout vencido; las líneas volvieron al carrito")
+
 func (r *Repository) CreateOrder(ctx context.Context, o *Order) (int64, error) {
+	return r.CreateOrderIdempotent(ctx, o, nil)
+}
+
+// CreateOrderIdempotent inserta la orden y, si idem != nil, registra la clave de idempotencia
+// en la misma transacción. La respuesta se serializa con idem.Response una vez conocido el order_id.
+func (r *Repository) CreateOrderIdempotent(ctx context.Context, o *Order, idem *IdempotencyRecord) (int64, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil { return 0, err }
 	defer func() { _ = tx.Rollback() }()
 
 	res, err := tx.ExecContext(ctx, `
-  INSERT INTO orders(user_id, status, total_cents, created_unix, updated_unix)
-  VALUES(?,?,?,?,?)`,
-		o.UserID, o.Status, o.TotalCents, o.CreatedUnix, o.UpdatedUnix)
+  INSERT INTO orders(user_id, status, total_cents, subtotal_cents, discount_cents, tax_cents, shipping_cents,
+                     checkout_id, payment_method, instrument_id, wallet_cents, created_unix, updated_unix)
+  VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`,
+		o.UserID, o.Status, o.TotalCents, o.SubtotalCents, o.DiscountCents, o.TaxCents, o.ShippingCents,
+		o.CheckoutID, o.PaymentMethod, o.InstrumentID, o.WalletCents, o.CreatedUnix, o.UpdatedUnix)
 	if err != nil { return 0, err }
 
 	oid, err := res.LastInsertId()
 	if err != nil { return 0, err }
 
 	stmt, err := tx.PrepareContext(ctx, `
-  INSERT INTO order_items(order_id, book_id, title, qty, unit_cents, line_cents)
-  VALUES(?,?,?,?,?,?)`)
+  INSERT INTO order_items(order_id, book_id, title, qty, unit_cents, line_cents, tax_cents)
+  VALUES(?,?,?,?,?,?,?)`)
 	if err != nil { return 0, err }
 	defer stmt.Close()
 
 	for _, it := range o.Items {
 		if _, err := stmt.ExecContext(ctx,
-			oid, it.BookID, it.Title, it.Qty, it.UnitCents, it.LineCents); err != nil {
+			oid, it.BookID, it.Title, it.Qty, it.UnitCents, it.LineCents, it.TaxCents); err != nil {
+			return 0, err
+		}
+	}
+
+	if a := o.ShippingAddress; a != nil {
+		if _, err := tx.ExecContext(ctx, `
+  INSERT INTO order_addresses(order_id, recipient, line1, line2, city, region, postal_code, country, phone)
+  VALUES(?,?,?,?,?,?,?,?,?)`,
+			oid, a.Recipient, a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country, a.Phone); err != nil {
+			return 0, err
+		}
+	}
+
+	if d := o.Deadline; d != nil {
+		d.OrderID = oid
+		if _, err := tx.ExecContext(ctx, `
+  INSERT INTO saga_deadlines(order_id, step, due_unix) VALUES(?,?,?)`,
+			oid, d.Step, d.DueUnix); err != nil {
 			return 0, err
 		}
 	}
 
+	if idem != nil {
+		resp, err := idem.Response(oid)
+		if err != nil { return 0, err }
+		if _, err := tx.ExecContext(ctx, `
+  INSERT INTO idempotency_keys(idem_key, user_id, request_hash, order_id, response, created_unix)
+  VALUES(?,?,?,?,?,?)`,
+			idem.Key, idem.UserID, idem.RequestHash, oid, resp, nowUnix()); err != nil {
+			if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+				return 0, ErrIdempotencyKeyTaken
+			}
+			return 0, err
+		}
+	}
+
+	// El snapshot pasa a la orden; si el barrido ya lo reclamó, Cart lo restauró
+	if o.CheckoutID != "" {
+		res, err := tx.ExecContext(ctx, `DELETE FROM checkout_intents WHERE checkout_id=?`, o.CheckoutID)
+		if err != nil { return 0, err }
+		if n, err := res.RowsAffected(); err != nil {
+			return 0, err
+		} else if n == 0 {
+			return 0, ErrCheckoutExpired
+		}
+	}
+
+	if err := recordEvent(ctx, tx, oid); err != nil { return 0, err }
+
 	if err := tx.Commit(); err != nil { return 0, err }
 	return oid, nil
 }
 
+// SaveCheckoutIntent anota que checkoutID se va a congelar para userID. Si el
+// proceso cae antes de guardar la orden, ClaimStaleCheckouts lo encuentra.
+// Repetirla (reintento con la misma clave) renueva created_unix.
+func (r *Repository) SaveCheckoutIntent(ctx context.Context, ci CheckoutIntent) error {
+	_, err := r.db.ExecContext(ctx, `
+  INSERT INTO checkout_intents(checkout_id, user_id, created_unix) VALUES(?,?,?)
+  ON CONFLICT(checkout_id) DO UPDATE SET created_unix=excluded.created_unix`,
+		ci.CheckoutID, ci.UserID, ci.CreatedUnix)
+	return err
+}
+
+// DropCheckoutIntent borra la anotación cuando ya no hay nada congelado que devolver.
+func (r *Repository) DropCheckoutIntent(ctx context.Context, checkoutID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM checkout_intents WHERE checkout_id=?`, checkoutID)
+	return err
+}
+
+// ClaimStaleCheckouts retira y devuelve las anotaciones anteriores a before que
+// no llegaron a tener orden. Las que sí la tienen (las dejó un reintento
+// concurrente con la misma clave) solo se borran.
+func (r *Repository) ClaimStaleCheckouts(ctx context.Context, before int64) ([]CheckoutIntent, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil { return nil, err }
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+  DELETE FROM checkout_intents
+  WHERE created_unix<=? AND checkout_id IN (SELECT checkout_id FROM orders)`, before); err != nil {
+		return nil, err
+	}
+	rows, err := tx.QueryContext(ctx, `
+    SELECT checkout_id, user_id, created_unix FROM checkout_intents WHERE created_unix<=? ORDER BY created_unix`, before)
+	if err != nil { return nil, err }
+	var out []CheckoutIntent
+	for rows.Next() {
+		var ci CheckoutIntent
+		if err := rows.Scan(&ci.CheckoutID, &ci.UserID, &ci.CreatedUnix); err != nil { rows.Close(); return nil, err }
+		out = append(out, ci)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil { return nil, err }
+	if _, err := tx.ExecContext(ctx, `DELETE FROM checkout_intents WHERE created_unix<=?`, before); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil { return nil, err }
+	return out, nil
+}
+
+// GetIdempotencyKey devuelve (nil, nil) si la clave no existe.
+func (r *Repository) GetIdempotencyKey(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	row := r.db.QueryRowContext(ctx, `
+    SELECT idem_key, user_id, request_hash, order_id, response, created_unix
+    FROM idempotency_keys WHERE idem_key=?`, key)
+	var rec IdempotencyRecord
+	if err := row.Scan(&rec.Key, &rec.UserID, &rec.RequestHash, &rec.OrderID, &rec.StoredResponse, &rec.CreatedUnix); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+		return nil, err
+	}
+	return &rec, nil
+}
+
 func (r *Repository) UpdateStatus(ctx context.Context, orderID int64, status int32) error {
-	_, err := r.db.ExecContext(ctx,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx,
 		`UPDATE orders SET status=?, updated_unix=? WHERE id=?`,
-		status, nowUnix(), orderID)
+		status, nowUnix(), orderID); err != nil {
+		return err
+	}
+	if err := recordEvent(ctx, tx, orderID); err != nil { return err }
+	if err := tx.Commit(); err != nil { return err }
+	r.watchers.notify(orderID)
+	return nil
+}
+
+// FinishSaga fija el resultado de la saga solo si la orden sigue CREATED
+// (compare-and-set) y borra su plazo. Devuelve false si ya estaba resuelta o
+// vencida: el evento llegó repetido o tarde.
+func (r *Repository) FinishSaga(ctx context.Context, orderID int64, status int32) (bool, error) {
+	return r.closeSaga(ctx, orderID, status, "")
+}
+
+// ExpireOrder cancela la orden CREATED cuyo plazo venció, con el motivo, y borra
+// el plazo. Devuelve false si la saga ya había terminado.
+func (r *Repository) ExpireOrder(ctx context.Context, orderID int64, reason string) (bool, error) {
+	return r.closeSaga(ctx, orderID, OrderStatusCancelled, reason)
+}
+
+func (r *Repository) closeSaga(ctx context.Context, orderID int64, status int32, reason string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil { return false, err }
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+  UPDATE orders SET status=?, status_reason=?, updated_unix=? WHERE id=? AND status=?`,
+		status, reason, nowUnix(), orderID, OrderStatusCreated)
+	if err != nil { return false, err }
+	n, err := res.RowsAffected()
+	if err != nil { return false, err }
+	if _, err := tx.ExecContext(ctx, `DELETE FROM saga_deadlines WHERE order_id=?`, orderID); err != nil {
+		return false, err
+	}
+	if n > 0 {
+		if err := recordEvent(ctx, tx, orderID); err != nil { return false, err }
+	}
+	if err := tx.Commit(); err != nil { return false, err }
+	if n > 0 {
+		r.watchers.notify(orderID)
+	}
+	return n > 0, nil
+}
+
+// SetDeadline mueve el plazo de la orden al paso indicado.
+func (r *Repository) SetDeadline(ctx context.Context, d SagaDeadline) error {
+	_, err := r.db.ExecContext(ctx, `
+  INSERT INTO saga_deadlines(order_id, step, due_unix) VALUES(?,?,?)
+  ON CONFLICT(order_id) DO UPDATE SET step=excluded.step, due_unix=excluded.due_unix`,
+		d.OrderID, d.Step, d.DueUnix)
 	return err
 }
 
+// DueDeadlines devuelve los plazos vencidos a la fecha, del más antiguo al más reciente.
+func (r *Repository) DueDeadlines(ctx context.Context, now int64) ([]SagaDeadline, error) {
+	rows, err := r.db.QueryContext(ctx, `
+    SELECT order_id, step, due_unix FROM saga_deadlines WHERE due_unix<=? ORDER BY due_unix`, now)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []SagaDeadline
+	for rows.Next() {
+		var d SagaDeadline
+		if err := rows.Scan(&d.OrderID, &d.Step, &d.DueUnix); err != nil { return nil, err }
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
 func (r *Repository) GetOrder(ctx context.Context, orderID int64) (*Order, error) {
 	row := r.db.QueryRowContext(ctx, `
-    SELECT id, user_id, status, total_cents, created_unix, updated_unix
+    SELECT id, user_id, status, total_cents, subtotal_cents, discount_cents, tax_cents, shipping_cents,
+           checkout_id, payment_method, instrument_id, wallet_cents, status_reason, created_unix, updated_unix
     FROM orders WHERE id=?`, orderID)
 	var o Order
-	if err := row.Scan(&o.ID, &o.UserID, &o.Status, &o.TotalCents, &o.CreatedUnix, &o.UpdatedUnix); err != nil {
+	if err := row.Scan(&o.ID, &o.UserID, &o.Status, &o.TotalCents, &o.SubtotalCents, &o.DiscountCents, &o.TaxCents, &o.ShippingCents,
+		&o.CheckoutID, &o.PaymentMethod, &o.InstrumentID, &o.WalletCents, &o.StatusReason, &o.CreatedUnix, &o.UpdatedUnix); err != nil {
 		return nil, err
 	}
 	items, err := r.listItems(ctx, orderID)
 	if err != nil { return nil, err }
 	o.Items = items
+	addr, err := r.getShippingAddress(ctx, orderID)
+	if err != nil { return nil, err }
+	o.ShippingAddress = addr
 	return &o, nil
 }
 
+func (r *Repository) getShippingAddress(ctx context.Context, orderID int64) (*ShippingAddress, error) {
+	row := r.db.QueryRowContext(ctx, `
+    SELECT recipient, line1, line2, city, region, postal_code, country, phone
+    FROM order_addresses WHERE order_id=?`, orderID)
+	var a ShippingAddress
+	if err := row.Scan(&a.Recipient, &a.Line1, &a.Line2, &a.City, &a.Region, &a.PostalCode, &a.Country, &a.Phone); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ErrFulfillmentChanged indica que otro proceso movió el despacho mientras se actualizaba.
+var ErrFulfillmentChanged = errors.New("despacho modificado concurrentemente")
+
+// EnsureFulfillment abre el despacho en PENDING y lo deja en el historial; no
+// hace nada si ya existe.
+func (r *Repository) EnsureFulfillment(ctx context.Context, orderID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+  INSERT INTO fulfillments(order_id, state, updated_unix) VALUES(?,?,?)
+  ON CONFLICT(order_id) DO NOTHING`,
+		orderID, FulfillmentPending, nowUnix())
+	if err != nil { return err }
+	n, err := res.RowsAffected()
+	if err != nil { return err }
+	if n == 0 { return nil }
+	if err := recordEvent(ctx, tx, orderID); err != nil { return err }
+	if err := tx.Commit(); err != nil { return err }
+	r.watchers.notify(orderID)
+	return nil
+}
+
+// GetFulfillment devuelve (nil, nil) si la orden aún no tiene despacho.
+func (r *Repository) GetFulfillment(ctx context.Context, orderID int64) (*Fulfillment, error) {
+	row := r.db.QueryRowContext(ctx, `
+    SELECT order_id, state, carrier, tracking_number, shipped_unix, delivered_unix, updated_unix
+    FROM fulfillments WHERE order_id=?`, orderID)
+	var f Fulfillment
+	if err := row.Scan(&f.OrderID, &f.State, &f.Carrier, &f.TrackingNumber, &f.ShippedUnix, &f.DeliveredUnix, &f.UpdatedUnix); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+		return nil, err
+	}
+	return &f, nil
+}
+
+// UpdateFulfillment guarda f solo si el estado sigue siendo from (compare-and-set).
+func (r *Repository) UpdateFulfillment(ctx context.Context, f *Fulfillment, from int32) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil { return err }
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+  UPDATE fulfillments
+  SET state=?, carrier=?, tracking_number=?, shipped_unix=?, delivered_unix=?, updated_unix=?
+  WHERE order_id=? AND state=?`,
+		f.State, f.Carrier, f.TrackingNumber, f.ShippedUnix, f.DeliveredUnix, f.UpdatedUnix, f.OrderID, from)
+	if err != nil { return err }
+	n, err := res.RowsAffected()
+	if err != nil { return err }
+	if n == 0 { return ErrFulfillmentChanged }
+	if err := recordEvent(ctx, tx, f.OrderID); err != nil { return err }
+	if err := tx.Commit(); err != nil { return err }
+	r.watchers.notify(f.OrderID)
+	return nil
+}
+
+// ErrReturnChanged indica que otro proceso movió la devolución mientras se actualizaba.
+var ErrReturnChanged = errors.New("devolución modificada concurrentemente")
+
+// ReturnedSoFar resume las devoluciones vigentes (no rechazadas) de una orden.
+type ReturnedSoFar struct {
+	Qty                 map[int64]int32 // unidades por book_id
+	RefundCents         map[int64]int64 // reembolsado por book_id
+	ShippingRefundCents int64
+}
+
+// CreateReturn inserta la devolución en una transacción. prepare recibe lo ya
+// devuelto de la orden (leído en la misma transacción) para validar cantidades y
+// calcular los reembolsos de rt antes de insertarla.
+func (r *Repository) CreateReturn(ctx context.Context, rt *Return, prepare func(ReturnedSoFar) error) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil { return 0, err }
+	defer func() { _ = tx.Rollback() }()
+
+	done := ReturnedSoFar{Qty: map[int64]int32{}, RefundCents: map[int64]int64{}}
+	rows, err := tx.QueryContext(ctx, `
+    SELECT l.book_id, SUM(l.qty), SUM(l.refund_cents)
+    FROM return_lines l JOIN returns rt ON rt.id=l.return_id
+    WHERE rt.order_id=? AND rt.state<>?
+    GROUP BY l.book_id`, rt.OrderID, ReturnRejected)
+	if err != nil { return 0, err }
+	for rows.Next() {
+		var bookID, refund int64
+		var qty int32
+		if err := rows.Scan(&bookID, &qty, &refund); err != nil { rows.Close(); return 0, err }
+		done.Qty[bookID] = qty
+		done.RefundCents[bookID] = refund
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil { return 0, err }
+	if err := tx.QueryRowContext(ctx, `
+    SELECT COALESCE(SUM(shipping_refund_cents),0) FROM returns WHERE order_id=? AND state<>?`,
+		rt.OrderID, ReturnRejected).Scan(&done.ShippingRefundCents); err != nil {
+		return 0, err
+	}
+
+	if err := prepare(done); err != nil { return 0, err }
+
+	res, err := tx.ExecContext(ctx, `
+  INSERT INTO returns(order_id, user_id, state, note, refund_cents, shipping_refund_cents, created_unix, updated_unix)
+  VALUES(?,?,?,?,?,?,?,?)`,
+		rt.OrderID, rt.UserID, rt.State, rt.Note, rt.RefundCents, rt.ShippingRefundCents, rt.CreatedUnix, rt.UpdatedUnix)
+	if err != nil { return 0, err }
+	rid, err := res.LastInsertId()
+	if err != nil { return 0, err }
+	for _, l := range rt.Lines {
+		if _, err := tx.ExecContext(ctx, `
+  INSERT INTO return_lines(return_id, book_id, title, qty, reason, refund_cents)
+  VALUES(?,?,?,?,?,?)`,
+			rid, l.BookID, l.Title, l.Qty, l.Reason, l.RefundCents); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil { return 0, err }
+	return rid, nil
+}
+
+// GetReturn devuelve (nil, nil) si la devolución no existe.
+func (r *Repository) GetReturn(ctx context.Context, returnID int64) (*Return, error) {
+	row := r.db.QueryRowContext(ctx, `
+    SELECT id, order_id, user_id, state, note, review_note, refund_cents, shipping_refund_cents,
+           refund_ref, restocked_unix, created_unix, updated_unix
+    FROM returns WHERE id=?`, returnID)
+	rt, err := scanReturn(row)
+	if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+	if err != nil { return nil, err }
+	if rt.Lines, err = r.listReturnLines(ctx, rt.ID); err != nil { return nil, err }
+	return rt, nil
+}
+
+// ListReturns devuelve las devoluciones de la orden, de la más reciente a la más antigua.
+func (r *Repository) ListReturns(ctx context.Context, orderID int64) ([]*Return, error) {
+	rows, err := r.db.QueryContext(ctx, `
+    SELECT id, order_id, user_id, state, note, review_note, refund_cents, shipping_refund_cents,
+           refund_ref, restocked_unix, created_unix, updated_unix
+    FROM returns WHERE order_id=? ORDER BY id DESC`, orderID)
+	if err != nil { return nil, err }
+	var out []*Return
+	for rows.Next() {
+		rt, err := scanReturn(rows)
+		if err != nil { rows.Close(); return nil, err }
+		out = append(out, rt)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil { return nil, err }
+	for _, rt := range out {
+		if rt.Lines, err = r.listReturnLines(ctx, rt.ID); err != nil { return nil, err }
+	}
+	return out, nil
+}
+
+func scanReturn(row interface{ Scan(...any) error }) (*Return, error) {
+	var rt Return
+	if err := row.Scan(&rt.ID, &rt.OrderID, &rt.UserID, &rt.State, &rt.Note, &rt.ReviewNote, &rt.RefundCents, &rt.ShippingRefundCents,
+		&rt.RefundRef, &rt.RestockedUnix, &rt.CreatedUnix, &rt.UpdatedUnix); err != nil {
+		return nil, err
+	}
+	return &rt, nil
+}
+
+func (r *Repository) listReturnLines(ctx context.Context, returnID int64) ([]ReturnLine, error) {
+	rows, err := r.db.QueryContext(ctx, `
+    SELECT id, return_id, book_id, title, qty, reason, refund_cents
+    FROM return_lines WHERE return_id=? ORDER BY id`, returnID)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []ReturnLine
+	for rows.Next() {
+		var l ReturnLine
+		if err := rows.Scan(&l.ID, &l.ReturnID, &l.BookID, &l.Title, &l.Qty, &l.Reason, &l.RefundCents); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// UpdateReturn guarda estado, notas y referencia de reembolso solo si el estado
+// sigue siendo from (compare-and-set).
+func (r *Repository) UpdateReturn(ctx context.Context, rt *Return, from int32) error {
+	res, err := r.db.ExecContext(ctx, `
+  UPDATE returns SET state=?, review_note=?, refund_ref=?, updated_unix=?
+  WHERE id=? AND state=?`,
+		rt.State, rt.ReviewNote, rt.RefundRef, rt.UpdatedUnix, rt.ID, from)
+	if err != nil { return err }
+	n, err := res.RowsAffected()
+	if err != nil { return err }
+	if n == 0 { return ErrReturnChanged }
+	return nil
+}
+
+// MarkRestocked registra que Inventory reingresó las unidades; es idempotente.
+func (r *Repository) MarkRestocked(ctx context.Context, returnID int64) error {
+	_, err := r.db.ExecContext(ctx, `
+  UPDATE returns SET restocked_unix=?, updated_unix=? WHERE id=? AND restocked_unix=0`,
+		nowUnix(), nowUnix(), returnID)
+	return err
+}
+
+// recordEvent guarda en order_events el estado de la orden (y de su despacho)
+// tal como queda en tx. Se llama en la misma transacción que el cambio, así el
+// historial que lee WatchOrder no puede perder ni adelantar una transición.
+func recordEvent(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	_, err := tx.ExecContext(ctx, `
+  INSERT INTO order_events(order_id, status, status_reason, fulfillment_state, created_unix)
+  SELECT o.id, o.status, o.status_reason, COALESCE(f.state, 0), ?
+  FROM orders o LEFT JOIN fulfillments f ON f.order_id=o.id WHERE o.id=?`,
+		nowUnix(), orderID)
+	return err
+}
+
+// ListEvents devuelve los eventos de la orden con id > afterID, en orden.
+func (r *Repository) ListEvents(ctx context.Context, orderID, afterID int64) ([]OrderEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+    SELECT id, order_id, status, status_reason, fulfillment_state, created_unix
+    FROM order_events WHERE order_id=? AND id>? ORDER BY id`, orderID, afterID)
+	if err != nil { return nil, err }
+	defer rows.Close()
+	var out []OrderEvent
+	for rows.Next() {
+		var e OrderEvent
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.Status, &e.StatusReason, &e.FulfillmentState, &e.CreatedUnix); err != nil { return nil, err }
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// LastEvent devuelve el evento más reciente de la orden, o (nil, nil) si la
+// orden es anterior a order_events.
+func (r *Repository) LastEvent(ctx context.Context, orderID int64) (*OrderEvent, error) {
+	row := r.db.QueryRowContext(ctx, `
+    SELECT id, order_id, status, status_reason, fulfillment_state, created_unix
+    FROM order_events WHERE order_id=? ORDER BY id DESC LIMIT 1`, orderID)
+	var e OrderEvent
+	if err := row.Scan(&e.ID, &e.OrderID, &e.Status, &e.StatusReason, &e.FulfillmentState, &e.CreatedUnix); err != nil {
+		if errors.Is(err, sql.ErrNoRows) { return nil, nil }
+		return nil, err
+	}
+	return &e, nil
+}
+
 func (r *Repository) listItems(ctx context.Context, orderID int64) ([]OrderItem, error) {
 	rows, err := r.db.QueryContext(ctx, `
-    SELECT id, order_id, book_id, title, qty, unit_cents, line_cents
+    SELECT id, order_id, book_id, title, qty, unit_cents, line_cents, tax_cents
     FROM order_items WHERE order_id=?`, orderID)
 	if err != nil { return nil, err }
 	defer rows.Close()
 	var out []OrderItem
 	for rows.Next() {
 		var it OrderItem
-		if err := rows.Scan(&it.ID, &it.OrderID, &it.BookID, &it.Title, &it.Qty, &it.UnitCents, &it.LineCents); err != nil {
+		if err := rows.Scan(&it.ID, &it.OrderID, &it.BookID, &it.Title, &it.Qty, &it.UnitCents, &it.LineCents, &it.TaxCents); err != nil {
 			return nil, err
 		}
 		out = append(out, it)
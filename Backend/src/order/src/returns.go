@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/pricing"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+// Flujo de devoluciones (RMA):
+// REQUESTED → APPROVED/REJECTED (admin) → RECEIVED (bodega; return.received hace
+// que Inventory reingrese el stock y se pide el reembolso a Payment) →
+// REFUNDED/REFUND_FAILED según payment.refunded / payment.refund_failed.
+
+func (s *OrderServer) RequestReturn(ctx context.Context, req *orderpb.RequestReturnRequest) (*orderpb.Return, error) {
+	if req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id requerido")
+	}
+	if err := auth.CheckUser(ctx, req.GetUserId()); err != nil { return nil, err }
+	if len(req.GetLines()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "la devolución necesita al menos una línea")
+	}
+	o, err := s.repo.GetOrder(ctx, req.GetOrderId())
+	if err != nil { return nil, orderErr(err) }
+	if o.UserID != req.GetUserId() {
+		return nil, status.Error(codes.PermissionDenied, "la orden no pertenece al usuario")
+	}
+	f, err := s.repo.GetFulfillment(ctx, o.ID)
+	if err != nil { return nil, err }
+	if f == nil || f.State != FulfillmentDelivered {
+		return nil, status.Error(codes.FailedPrecondition, "solo se pueden devolver órdenes entregadas")
+	}
+	if s.returnWindow > 0 && time.Since(time.Unix(f.DeliveredUnix, 0)) > s.returnWindow {
+		return nil, status.Error(codes.FailedPrecondition, "venció el plazo para devolver esta orden")
+	}
+
+	byBook := map[int64]OrderItem{}
+	for _, it := range o.Items {
+		byBook[it.BookID] = it
+	}
+	rt := &Return{
+		OrderID:     o.ID,
+		UserID:      o.UserID,
+		State:       ReturnRequested,
+		Note:        strings.TrimSpace(req.GetNote()),
+		CreatedUnix: nowUnix(),
+	}
+	rt.UpdatedUnix = rt.CreatedUnix
+	for _, l := range req.GetLines() {
+		it, ok := byBook[l.GetBookId()]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "book_id %d no está en la orden", l.GetBookId())
+		}
+		for _, prev := range rt.Lines {
+			if prev.BookID == it.BookID {
+				return nil, status.Errorf(codes.InvalidArgument, "book_id %d repetido", it.BookID)
+			}
+		}
+		if l.GetQty() <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "qty inválida para book_id %d", it.BookID)
+		}
+		if l.GetReason() == orderpb.ReturnReason_RETURN_REASON_UNSPECIFIED {
+			return nil, status.Errorf(codes.InvalidArgument, "reason requerido para book_id %d", it.BookID)
+		}
+		rt.Lines = append(rt.Lines, ReturnLine{
+			BookID: it.BookID,
+			Title:  it.Title,
+			Qty:    l.GetQty(),
+			Reason: int32(l.GetReason()),
+		})
+	}
+
+	rid, err := s.repo.CreateReturn(ctx, rt, func(done ReturnedSoFar) error {
+		return priceReturn(o, rt, done)
+	})
+	if err != nil { return nil, err }
+	rt.ID = rid
+
+	publishReturn(ctx, s.rabbit, RKReturnRequested, rt, "")
+	return returnToPB(rt), nil
+}
+
+func (s *OrderServer) ListReturns(ctx context.Context, req *commonpb.OrderRef) (*orderpb.ListReturnsResponse, error) {
+	o, err := s.repo.GetOrder(ctx, req.GetOrderId())
+	if err != nil { return nil, orderErr(err) }
+	if err := auth.CheckUser(ctx, o.UserID); err != nil { return nil, err }
+	rts, err := s.repo.ListReturns(ctx, o.ID)
+	if err != nil { return nil, err }
+	resp := &orderpb.ListReturnsResponse{Returns: make([]*orderpb.Return, 0, len(rts))}
+	for _, rt := range rts {
+		resp.Returns = append(resp.Returns, returnToPB(rt))
+	}
+	return resp, nil
+}
+
+// priceReturn calcula el reembolso de cada línea sobre lo cobrado (base con
+// descuento + IVA). Se prorratea por unidades acumuladas, así la suma de varias
+// devoluciones parciales nunca supera lo cobrado por la línea. El envío solo se
+// reembolsa cuando la orden queda devuelta por completo.
+func priceReturn(o *Order, rt *Return, done ReturnedSoFar) error {
+	lineCents := make([]int64, len(o.Items))
+	for i, it := range o.Items {
+		lineCents[i] = it.LineCents
+	}
+	nets := pricing.NetLines(lineCents, o.DiscountCents)
+
+	returned := map[int64]int32{}
+	for id, q := range done.Qty {
+		returned[id] = q
+	}
+	rt.RefundCents = 0
+	for i := range rt.Lines {
+		l := &rt.Lines[i]
+		for j, it := range o.Items {
+			if it.BookID != l.BookID { continue }
+			prev := done.Qty[it.BookID]
+			if prev+l.Qty > it.Qty {
+				return status.Errorf(codes.FailedPrecondition,
+					"book_id %d: se compraron %d y ya hay %d en devolución", it.BookID, it.Qty, prev)
+			}
+			paid := nets[j] + it.TaxCents
+			l.RefundCents = paid*int64(prev+l.Qty)/int64(it.Qty) - done.RefundCents[it.BookID]
+			returned[it.BookID] = prev + l.Qty
+		}
+		rt.RefundCents += l.RefundCents
+	}
+
+	for _, it := range o.Items {
+		if returned[it.BookID] < it.Qty { return nil }
+	}
+	rt.ShippingRefundCents = o.ShippingCents - done.ShippingRefundCents
+	rt.RefundCents += rt.ShippingRefundCents
+	return nil
+}
+
+func (s *OrderAdminServer) ReviewReturn(ctx context.Context, req *orderpb.ReviewReturnRequest) (*orderpb.Return, error) {
+	rt, err := s.repo.GetReturn(ctx, req.GetReturnId())
+	if err != nil { return nil, err }
+	if rt == nil { return nil, status.Error(codes.NotFound, "devolución no encontrada") }
+
+	target, rk := int32(ReturnRejected), RKReturnRejected
+	if req.GetApprove() {
+		target, rk = ReturnApproved, RKReturnApproved
+	}
+	// Repetir la misma decisión no cambia nada
+	if rt.State == target { return returnToPB(rt), nil }
+	if rt.State != ReturnRequested {
+		return nil, status.Errorf(codes.FailedPrecondition, "la devolución ya está en %s", orderpb.ReturnState(rt.State))
+	}
+	note := strings.TrimSpace(req.GetNote())
+	if !req.GetApprove() && note == "" {
+		return nil, status.Error(codes.InvalidArgument, "note es obligatorio para rechazar")
+	}
+
+	rt.State = target
+	rt.ReviewNote = note
+	rt.UpdatedUnix = nowUnix()
+	if err := s.repo.UpdateReturn(ctx, rt, ReturnRequested); err != nil {
+		if errors.Is(err, ErrReturnChanged) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, err
+	}
+	publishReturn(ctx, s.rabbit, rk, rt, note)
+	return returnToPB(rt), nil
+}
+
+func (s *OrderAdminServer) ReceiveReturn(ctx context.Context, req *orderpb.ReturnRef) (*orderpb.Return, error) {
+	rt, err := s.repo.GetReturn(ctx, req.GetReturnId())
+	if err != nil { return nil, err }
+	if rt == nil { return nil, status.Error(codes.NotFound, "devolución no encontrada") }
+
+	from := rt.State
+	switch from {
+	case ReturnReceived, ReturnRefunded:
+		return returnToPB(rt), nil
+	case ReturnApproved, ReturnRefundFailed:
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "solo se recibe una devolución aprobada (está en %s)", orderpb.ReturnState(from))
+	}
+
+	rt.State = ReturnReceived
+	if rt.RefundCents == 0 {
+		rt.State = ReturnRefunded
+	}
+	rt.UpdatedUnix = nowUnix()
+	if err := s.repo.UpdateReturn(ctx, rt, from); err != nil {
+		if errors.Is(err, ErrReturnChanged) {
+			return nil, status.Error(codes.Aborted, err.Error())
+		}
+		return nil, err
+	}
+
+	// El stock se reingresa una sola vez; un REFUND_FAILED solo reintenta el reembolso
+	if from == ReturnApproved {
+		publishReturn(ctx, s.rabbit, RKReturnReceived, rt, "")
+	}
+	if rt.State == ReturnRefunded {
+		publishReturn(ctx, s.rabbit, RKReturnRefunded, rt, "")
+		return returnToPB(rt), nil
+	}
+	refund := RefundRequestPayload{
+		OrderID:     rt.OrderID,
+		ReturnID:    rt.ID,
+		AmountCents: rt.RefundCents,
+		Reason:      fmt.Sprintf("return:%d", rt.ID),
+	}
+	if err := s.rabbit.PublishJSON(ctx, RKPaymentRefund, refund); err != nil {
+		slog.WarnContext(ctx, "publish", "rk", RKPaymentRefund, "err", err)
+	}
+	return returnToPB(rt), nil
+}
+
+// handleReturnEvent procesa las respuestas de Inventory y Payment a una devolución recibida.
+func (s *OrderServer) handleReturnEvent(ctx context.Context, rk string, body []byte) error {
+	if rk == RKInventoryRestocked {
+		var p RestockedPayload
+		if err := json.Unmarshal(body, &p); err != nil { return err }
+		return s.repo.MarkRestocked(ctx, p.ReturnID)
+	}
+
+	var p RefundResultPayload
+	if err := json.Unmarshal(body, &p); err != nil { return err }
+	rt, err := s.repo.GetReturn(ctx, p.ReturnID)
+	if err != nil { return err }
+	// Duplicado o resultado de un intento anterior
+	if rt == nil || rt.State != ReturnReceived { return nil }
+
+	out := RKReturnRefundFailed
+	rt.State = ReturnRefundFailed
+	if rk == RKPaymentRefunded {
+		out = RKReturnRefunded
+		rt.State = ReturnRefunded
+	}
+	rt.RefundRef = p.ProviderRef
+	rt.UpdatedUnix = nowUnix()
+	if err := s.repo.UpdateReturn(ctx, rt, ReturnReceived); err != nil {
+		if errors.Is(err, ErrReturnChanged) { return nil }
+		return err
+	}
+	publishReturn(ctx, s.rabbit, out, rt, p.Reason)
+	return nil
+}
+
+func publishReturn(ctx context.Context, rb broker, rk string, rt *Return, note string) {
+	payload := ReturnPayload{
+		ReturnID:    rt.ID,
+		OrderID:     rt.OrderID,
+		UserID:      rt.UserID,
+		State:       orderpb.ReturnState(rt.State).String(),
+		Items:       make([]ReturnItemEvt, 0, len(rt.Lines)),
+		RefundCents: rt.RefundCents,
+		Note:        note,
+	}
+	for _, l := range rt.Lines {
+		payload.Items = append(payload.Items, ReturnItemEvt{BookID: l.BookID, Qty: l.Qty})
+	}
+	if err := rb.PublishJSON(ctx, rk, payload); err != nil {
+		slog.WarnContext(ctx, "publish", "rk", rk, "err", err)
+	}
+}
+
+func returnToPB(rt *Return) *orderpb.Return {
+	out := &orderpb.Return{
+		ReturnId:    rt.ID,
+		OrderId:     rt.OrderID,
+		UserId:      rt.UserID,
+		State:       orderpb.ReturnState(rt.State),
+		Note:        rt.Note,
+		ReviewNote:  rt.ReviewNote,
+		RefundTotal: &commonpb.Money{Cents: rt.RefundCents},
+		Restocked:   rt.RestockedUnix != 0,
+		RefundRef:   rt.RefundRef,
+		CreatedUnix: rt.CreatedUnix,
+		UpdatedUnix: rt.UpdatedUnix,
+	}
+	for _, l := range rt.Lines {
+		out.Lines = append(out.Lines, &orderpb.ReturnLine{
+			BookId: l.BookID,
+			Qty:    l.Qty,
+			Reason: orderpb.ReturnReason(l.Reason),
+			Title:  l.Title,
+			Refund: &commonpb.Money{Cents: l.RefundCents},
+		})
+	}
+	return out
+}
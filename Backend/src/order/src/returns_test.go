@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestPriceReturnProration devuelve la orden en tres partes: cada parte se
+// prorratea por unidades acumuladas, el envío entra solo con la última y la
+// suma es exactamente lo cobrado.
+func TestPriceReturnProration(t *testing.T) {
+	// Descuento de 1000 repartido: 749 a la primera línea y 251 a la segunda
+	o := &Order{
+		SubtotalCents: 40001, DiscountCents: 1000, TaxCents: 1853, ShippingCents: 7500, TotalCents: 48354,
+		Items: []OrderItem{
+			{BookID: 10, Qty: 3, UnitCents: 10000, LineCents: 30000},
+			{BookID: 11, Qty: 1, UnitCents: 10001, LineCents: 10001, TaxCents: 1853},
+		},
+	}
+	done := ReturnedSoFar{Qty: map[int64]int32{}, RefundCents: map[int64]int64{}}
+	var total int64
+	for i, step := range []struct {
+		lines    []ReturnLine
+		want     []int64 // reembolso por línea
+		shipping int64
+	}{
+		{[]ReturnLine{{BookID: 10, Qty: 1}}, []int64{9750}, 0},                                 // 29251/3 = 9750,33
+		{[]ReturnLine{{BookID: 10, Qty: 1}}, []int64{9750}, 0},                                 // 58502/3 = 19500,67
+		{[]ReturnLine{{BookID: 10, Qty: 1}, {BookID: 11, Qty: 1}}, []int64{9751, 11603}, 7500}, // el residuo y el envío
+	} {
+		rt := &Return{Lines: step.lines}
+		if err := priceReturn(o, rt, done); err != nil {
+			t.Fatalf("parte %d: %v", i+1, err)
+		}
+		sum := rt.ShippingRefundCents
+		for j, l := range rt.Lines {
+			if l.RefundCents != step.want[j] {
+				t.Fatalf("parte %d, book_id %d: reembolso %d, se esperaba %d", i+1, l.BookID, l.RefundCents, step.want[j])
+			}
+			sum += l.RefundCents
+			done.Qty[l.BookID] += l.Qty
+			done.RefundCents[l.BookID] += l.RefundCents
+		}
+		if rt.ShippingRefundCents != step.shipping || rt.RefundCents != sum {
+			t.Fatalf("parte %d: envío %d, total %d (líneas + envío = %d)", i+1, rt.ShippingRefundCents, rt.RefundCents, sum)
+		}
+		done.ShippingRefundCents += rt.ShippingRefundCents
+		total += rt.RefundCents
+	}
+	if total != o.TotalCents {
+		t.Fatalf("reembolsado %d, se cobró %d", total, o.TotalCents)
+	}
+
+	// Ya no queda nada por devolver
+	err := priceReturn(o, &Return{Lines: []ReturnLine{{BookID: 10, Qty: 1}}}, done)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("err = %v, se esperaba FailedPrecondition", err)
+	}
+}
@@ -2,10 +2,21 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
+	"strings"
+	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/pricing"
 	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
 	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 )
@@ -13,33 +24,99 @@ import (
 type OrderServer struct {
 	orderpb.UnimplementedOrderServer
 	repo   *Repository
-	rabbit *Rabbit
+	rabbit broker
 	cart   *CartClient
+	users  *UserClient
+	pricing *pricing.Rules
+	returnWindow time.Duration // plazo para pedir devoluciones desde la entrega; 0 = sin límite
+	timeouts SagaTimeouts
 }
 
-func NewOrderServer(repo *Repository, rb *Rabbit, cart *CartClient) *OrderServer {
-	return &OrderServer{repo: repo, rabbit: rb, cart: cart}
+func NewOrderServer(repo *Repository, rb broker, cart *CartClient, users *UserClient, rules *pricing.Rules, returnWindow time.Duration, timeouts SagaTimeouts) *OrderServer {
+	return &OrderServer{repo: repo, rabbit: rb, cart: cart, users: users, pricing: rules, returnWindow: returnWindow, timeouts: timeouts}
 }
 
 func (s *OrderServer) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
 	if req.GetUserId() == 0 {
 		return nil, errors.New("user_id requerido")
 	}
-	// 1) Obtener carrito
-	cv, err := s.cart.GetCart(ctx, req.GetUserId())
+	if err := auth.CheckUser(ctx, req.GetUserId()); err != nil { return nil, err }
+	// 0) Reintento con la misma idempotency_key → devolver la orden original
+	key := req.GetIdempotencyKey()
+	var reqHash string
+	if key != "" {
+		reqHash = hashCreateOrderRequest(req)
+		resp, err := s.replayCreateOrder(ctx, key, reqHash)
+		if resp != nil || err != nil {
+			return resp, err
+		}
+	}
+
+	// 1) Resolver la dirección antes de congelar el carrito; se copia a la orden
+	var shipTo *ShippingAddress
+	if aid := req.GetAddressId(); aid != 0 {
+		a, err := s.users.GetAddress(ctx, req.GetUserId(), aid)
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Error(codes.InvalidArgument, "address_id no pertenece al usuario")
+		}
+		if err != nil { return nil, err }
+		shipTo = a
+	}
+	// La tarjeta (dueño, vigencia del token) la valida Payment al cobrar
+	method := req.GetPaymentMethod()
+	if _, ok := commonpb.PaymentMethod_name[int32(method)]; !ok {
+		return nil, status.Error(codes.InvalidArgument, "payment_method inválido")
+	}
+	if method == commonpb.PaymentMethod_PAYMENT_METHOD_CARD && req.GetInstrumentId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "instrument_id requerido para pagar con tarjeta")
+	}
+	if method != commonpb.PaymentMethod_PAYMENT_METHOD_CARD && req.GetInstrumentId() != 0 {
+		return nil, status.Error(codes.InvalidArgument, "instrument_id solo aplica a PAYMENT_METHOD_CARD")
+	}
+	if method == commonpb.PaymentMethod_PAYMENT_METHOD_CASH_ON_DELIVERY && shipTo == nil {
+		return nil, status.Error(codes.InvalidArgument, "contra entrega requiere address_id")
+	}
+	// El saldo disponible lo verifica Payment al debitar; aquí solo se recorta al total
+	wallet := req.GetWalletAmount().GetCents()
+	if wallet < 0 {
+		return nil, status.Error(codes.InvalidArgument, "wallet_amount no puede ser negativo")
+	}
+
+	// 2) Congelar el carrito: la orden se arma del snapshot, no de una lectura en vivo.
+	// Con idempotency_key el reintento recupera el mismo snapshot.
+	checkoutID := key
+	if checkoutID == "" {
+		checkoutID = newCheckoutID()
+	}
+	// Se anota antes de congelar: si el proceso cae antes de guardar la orden, el
+	// barrido de checkouts devuelve las líneas al carrito.
+	if err := s.repo.SaveCheckoutIntent(ctx, CheckoutIntent{CheckoutID: checkoutID, UserID: req.GetUserId(), CreatedUnix: nowUnix()}); err != nil {
+		return nil, err
+	}
+	snap, err := s.cart.CheckoutSnapshot(ctx, req.GetUserId(), checkoutID)
 	if err != nil { return nil, err }
-	if len(cv.Items) == 0 {
-		return nil, errors.New("carrito vacío")
+	cv := snap.GetCart()
+	if len(cv.GetItems()) == 0 {
+		// No quedó nada congelado
+		if err := s.repo.DropCheckoutIntent(ctx, checkoutID); err != nil {
+			slog.WarnContext(ctx, "checkout intent", "checkout_id", checkoutID, "err", err)
+		}
+		return nil, status.Error(codes.FailedPrecondition, "carrito vacío")
 	}
 
-	// 2) Mapear ítems y totales
+	// 3) Mapear ítems y cotizar (IVA por categoría, envío según la región de destino)
 	var o Order
 	o.UserID = req.GetUserId()
+	o.ShippingAddress = shipTo
 	o.Status = OrderStatusCreated
+	o.CheckoutID = snap.GetCheckoutId()
+	o.PaymentMethod = int32(method)
+	o.InstrumentID = req.GetInstrumentId()
 	o.CreatedUnix = nowUnix()
 	o.UpdatedUnix = o.CreatedUnix
 
 	var itemsEvt []OrderItemEvt
+	lines := make([]pricing.Line, 0, len(cv.Items))
 	for _, it := range cv.Items {
 		unit := it.UnitPrice.GetCents()
 		line := it.LineTotal.GetCents()
@@ -57,50 +134,128 @@ func (s *OrderServer) CreateOrder(ctx context.Context, req *orderpb.CreateOrderR
 			UnitCents: unit,
 			LineCents: line,
 		})
-		o.TotalCents += line
+		lines = append(lines, pricing.Line{
+			Category:    it.GetCategory(),
+			UnitCents:   unit,
+			Qty:         it.Qty,
+			WeightGrams: it.GetWeightGrams(),
+		})
 	}
+	var dest *pricing.Destination
+	if shipTo != nil {
+		dest = &pricing.Destination{Region: shipTo.Region, Country: shipTo.Country}
+	}
+	q := s.pricing.Quote(lines, dest)
+	for i := range o.Items {
+		o.Items[i].TaxCents = q.LineTaxCents[i]
+	}
+	o.SubtotalCents = q.SubtotalCents
+	o.DiscountCents = q.DiscountCents
+	o.TaxCents = q.TaxCents
+	o.ShippingCents = q.ShippingCents
+	o.TotalCents = q.GrandTotalCents
+	if method == commonpb.PaymentMethod_PAYMENT_METHOD_STORE_CREDIT || wallet > o.TotalCents {
+		wallet = o.TotalCents
+	}
+	if wallet > 0 && wallet == o.TotalCents {
+		o.PaymentMethod = int32(commonpb.PaymentMethod_PAYMENT_METHOD_STORE_CREDIT)
+		o.InstrumentID = 0
+	}
+	o.WalletCents = wallet
+	o.Deadline = s.timeouts.deadline(0, SagaStepInventory)
 
-	oid, err := s.repo.CreateOrder(ctx, &o)
-	if err != nil { return nil, err }
+	var idem *IdempotencyRecord
+	if key != "" {
+		idem = &IdempotencyRecord{
+			Key:         key,
+			UserID:      o.UserID,
+			RequestHash: reqHash,
+			Response: func(oid int64) ([]byte, error) {
+				return proto.Marshal(createOrderResponse(oid, &o))
+			},
+		}
+	}
+	oid, err := s.repo.CreateOrderIdempotent(ctx, &o, idem)
+	if errors.Is(err, ErrIdempotencyKeyTaken) {
+		// Otra solicitud concurrente con la misma clave ganó la carrera
+		return s.replayCreateOrder(ctx, key, reqHash)
+	}
+	if errors.Is(err, ErrCheckoutExpired) {
+		return nil, status.Error(codes.Aborted, "el checkout venció antes de guardar la orden; intenta de nuevo")
+	}
+	if err != nil {
+		// Sin orden no habrá resultado de saga: devolver las líneas al carrito ya
+		s.restoreCheckout(ctx, CheckoutIntent{CheckoutID: o.CheckoutID, UserID: o.UserID})
+		return nil, err
+	}
 
-	// 3) Publicar evento order.created
+	// 4) Publicar evento order.created
 	payload := OrderCreatedPayload{
 		OrderID:    oid,
 		UserID:     o.UserID,
 		Items:      itemsEvt,
 		TotalCents: o.TotalCents,
 	}
-	if err := s.rabbit.PublishJSON(RKOrderCreated, payload); err != nil {
-		log.Printf("[order] WARN publish order.created failed: %v", err)
+	if err := s.rabbit.PublishJSON(ctx, RKOrderCreated, payload); err != nil {
+		slog.WarnContext(ctx, "publish", "rk", RKOrderCreated, "err", err)
+	}
+
+	// 5) Responder
+	return createOrderResponse(oid, &o), nil
+}
+
+func createOrderResponse(oid int64, o *Order) *orderpb.CreateOrderResponse {
+	return &orderpb.CreateOrderResponse{
+		OrderId:   oid,
+		Status:    orderpb.OrderStatus_ORDER_STATUS_CREATED,
+		Items:     orderItemsToPB(o.Items),
+		Total:     &commonpb.Money{Cents: o.TotalCents},
+		Breakdown: breakdownToPB(o),
 	}
+}
 
-	// 4) Responder
-	respItems := make([]*orderpb.OrderItem, 0, len(o.Items))
-	for _, it := range o.Items {
-		respItems = append(respItems, &orderpb.OrderItem{
+func orderItemsToPB(items []OrderItem) []*orderpb.OrderItem {
+	out := make([]*orderpb.OrderItem, 0, len(items))
+	for _, it := range items {
+		out = append(out, &orderpb.OrderItem{
 			BookId:   it.BookID,
 			Title:    it.Title,
 			Qty:      it.Qty,
 			UnitPrice: &commonpb.Money{Cents: it.UnitCents},
 			LineTotal: &commonpb.Money{Cents: it.LineCents},
+			Tax:       &commonpb.Money{Cents: it.TaxCents},
 		})
 	}
-	return &orderpb.CreateOrderResponse{
-		OrderId: oid,
-		Status:  orderpb.OrderStatus_ORDER_STATUS_CREATED,
-		Items:   respItems,
-		Total:   &commonpb.Money{Cents: o.TotalCents},
-	}, nil
+	return out
+}
+
+func breakdownToPB(o *Order) *commonpb.PriceBreakdown {
+	return &commonpb.PriceBreakdown{
+		Subtotal:   &commonpb.Money{Cents: o.SubtotalCents},
+		Discount:   &commonpb.Money{Cents: o.DiscountCents},
+		Tax:        &commonpb.Money{Cents: o.TaxCents},
+		Shipping:   &commonpb.Money{Cents: o.ShippingCents},
+		GrandTotal: &commonpb.Money{Cents: o.TotalCents},
+	}
 }
 
 func (s *OrderServer) GetOrderStatus(ctx context.Context, req *orderpb.GetOrderStatusRequest) (*orderpb.GetOrderStatusResponse, error) {
 	o, err := s.repo.GetOrder(ctx, req.GetOrderId())
 	if err != nil { return nil, err }
+	if err := auth.CheckUser(ctx, o.UserID); err != nil { return nil, err }
+	f, err := s.repo.GetFulfillment(ctx, o.ID)
+	if err != nil { return nil, err }
 	return &orderpb.GetOrderStatusResponse{
-		OrderId:     o.ID,
-		Status:      orderStatusToPB(o.Status),
-		Total:       &commonpb.Money{Cents: o.TotalCents},
-		UpdatedUnix: o.UpdatedUnix,
+		OrderId:         o.ID,
+		Status:          orderStatusToPB(o.Status),
+		Total:           &commonpb.Money{Cents: o.TotalCents},
+		UpdatedUnix:     o.UpdatedUnix,
+		ShippingAddress: addressToPB(o.ShippingAddress),
+		Fulfillment:     fulfillmentToPB(f),
+		Breakdown:       breakdownToPB(o),
+		Items:           orderItemsToPB(o.Items),
+		PaymentMethod:   commonpb.PaymentMethod(o.PaymentMethod),
+		StatusReason:    o.StatusReason,
 	}, nil
 }
 
@@ -114,6 +269,8 @@ func orderStatusToPB(st int32) orderpb.OrderStatus {
 		return orderpb.OrderStatus_ORDER_STATUS_CANCELLED
 	case OrderStatusFailed:
 		return orderpb.OrderStatus_ORDER_STATUS_FAILED
+	case OrderStatusConfirmed:
+		return orderpb.OrderStatus_ORDER_STATUS_CONFIRMED
 	default:
 		return orderpb.OrderStatus_ORDER_STATUS_UNSPECIFIED
 	}
@@ -123,51 +280,134 @@ func orderStatusToPB(st int32) orderpb.OrderStatus {
 
 func (s *OrderServer) StartConsumers() error {
 	// Cola dedicada del servicio order
-	return s.rabbit.ConsumeTopic("order-service",
-		[]string{RKInventoryReserved, RKInventoryRejected, RKPaymentCaptured, RKPaymentFailed},
-		s.handleEvent)
+	const queue = "order-service"
+	if err := s.rabbit.ConsumeTopic(queue,
+		[]string{RKInventoryReserved, RKInventoryRejected, RKPaymentSucceeded, RKPaymentDeferred, RKPaymentFailed,
+			RKInventoryRestocked, RKPaymentRefunded, RKPaymentRefundFailed},
+		s.handleEvent); err != nil {
+		return err
+	}
+	metrics.QueueDepth(s.rabbit.QueueDepth, queue)
+	return nil
 }
 
-func (s *OrderServer) handleEvent(rk string, body []byte) error {
+func (s *OrderServer) handleEvent(ctx context.Context, rk string, body []byte) error {
 	switch rk {
 	case RKInventoryReserved:
 		var p InventoryResultPayload
 		if err := json.Unmarshal(body, &p); err != nil { return err }
 		if p.OK {
 			// Ya confirmado stock → solicitar cobro
-			o, err := s.repo.GetOrder(context.Background(), p.OrderID)
+			o, err := s.repo.GetOrder(ctx, p.OrderID)
 			if err != nil { return err }
+			if o.Status != OrderStatusCreated {
+				// Reserva tardía de una orden vencida: repetir order.expired para liberarla
+				if isExpired(o) {
+					s.publishExpired(ctx, o)
+				}
+				slog.InfoContext(ctx, "evento ignorado: la orden ya no está CREATED", "rk", rk, "order_id", o.ID)
+				return nil
+			}
+			if err := s.repo.SetDeadline(ctx, *s.timeouts.deadline(o.ID, SagaStepPayment)); err != nil { return err }
 			ch := PaymentChargePayload{
-				OrderID:    o.ID,
-				UserID:     o.UserID,
-				TotalCents: o.TotalCents,
+				OrderID:      o.ID,
+				UserID:       o.UserID,
+				AmountCents:  o.TotalCents,
+				InstrumentID: o.InstrumentID,
+				WalletCents:  o.WalletCents,
+			}
+			if o.PaymentMethod != 0 {
+				ch.Method = commonpb.PaymentMethod(o.PaymentMethod).String()
 			}
-			if err := s.rabbit.PublishJSON(RKPaymentCharge, ch); err != nil {
-				log.Printf("[order] publish payment.charge error: %v", err)
+			if err := s.rabbit.PublishJSON(ctx, RKPaymentCharge, ch); err != nil {
+				slog.WarnContext(ctx, "publish", "rk", RKPaymentCharge, "err", err)
 			}
 		} else {
-			_ = s.repo.UpdateStatus(context.Background(), p.OrderID, OrderStatusFailed)
+			return s.finishOrder(ctx, p.OrderID, OrderStatusFailed)
 		}
 
 	case RKInventoryRejected:
 		var p InventoryResultPayload
 		if err := json.Unmarshal(body, &p); err != nil { return err }
-		_ = s.repo.UpdateStatus(context.Background(), p.OrderID, OrderStatusFailed)
+		return s.finishOrder(ctx, p.OrderID, OrderStatusFailed)
 
-	case RKPaymentCaptured:
+	case RKPaymentSucceeded:
 		var p PaymentResultPayload
 		if err := json.Unmarshal(body, &p); err != nil { return err }
-		if p.OK {
-			_ = s.repo.UpdateStatus(context.Background(), p.OrderID, OrderStatusPaid)
-		} else {
-			_ = s.repo.UpdateStatus(context.Background(), p.OrderID, OrderStatusFailed)
+		o, err := s.repo.GetOrder(ctx, p.OrderID)
+		if err != nil { return err }
+		// Contra entrega cobrada al entregar: la saga ya terminó, solo cambia el estado
+		if o.Status == OrderStatusConfirmed {
+			return s.repo.UpdateStatus(ctx, o.ID, OrderStatusPaid)
 		}
+		// Cobro tardío de una orden vencida: repetir order.expired para que Payment reembolse
+		if isExpired(o) {
+			s.publishExpired(ctx, o)
+			return nil
+		}
+		return s.finishOrder(ctx, p.OrderID, OrderStatusPaid)
+
+	case RKPaymentDeferred:
+		var p PaymentResultPayload
+		if err := json.Unmarshal(body, &p); err != nil { return err }
+		return s.finishOrder(ctx, p.OrderID, OrderStatusConfirmed)
 
 	case RKPaymentFailed:
 		var p PaymentResultPayload
 		if err := json.Unmarshal(body, &p); err != nil { return err }
-		_ = s.repo.UpdateStatus(context.Background(), p.OrderID, OrderStatusFailed)
+		return s.finishOrder(ctx, p.OrderID, OrderStatusFailed)
+
+	case RKInventoryRestocked, RKPaymentRefunded, RKPaymentRefundFailed:
+		return s.handleReturnEvent(ctx, rk, body)
+	}
+	return nil
+}
+
+
+// finishOrder fija el estado final de la saga y lo publica para que Cart resuelva el checkout.
+// Si la saga ya terminó (evento repetido, o llegó después del plazo) no hace nada.
+func (s *OrderServer) finishOrder(ctx context.Context, orderID int64, status int32) error {
+	changed, err := s.repo.FinishSaga(ctx, orderID, status)
+	if err != nil { return err }
+	if !changed {
+		slog.InfoContext(ctx, "orden ya resuelta; se ignora el resultado", "order_id", orderID, "status", orderStatusToPB(status).String())
+		return nil
 	}
+	metrics.OrderFinished(statusLabel(status))
+	// Orden pagada (o contra entrega) → queda pendiente de despacho en bodega
+	if status == OrderStatusPaid || status == OrderStatusConfirmed {
+		if err := s.repo.EnsureFulfillment(ctx, orderID); err != nil { return err }
+	}
+	o, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil { return err }
+	s.publishOutcome(ctx, o, status)
 	return nil
 }
 
+func (s *OrderServer) publishOutcome(ctx context.Context, o *Order, status int32) {
+	// Contra entrega también cierra el checkout con order.paid; Status lo distingue
+	rk := RKOrderFailed
+	if status == OrderStatusPaid || status == OrderStatusConfirmed {
+		rk = RKOrderPaid
+	}
+	payload := OrderOutcomePayload{
+		OrderID:    o.ID,
+		UserID:     o.UserID,
+		CheckoutID: o.CheckoutID,
+		Status:     orderStatusToPB(status).String(),
+	}
+	if err := s.rabbit.PublishJSON(ctx, rk, payload); err != nil {
+		slog.WarnContext(ctx, "publish", "rk", rk, "err", err)
+	}
+}
+
+// statusLabel es el estado para las métricas: ORDER_STATUS_PAID → "paid".
+func statusLabel(status int32) string {
+	return strings.ToLower(strings.TrimPrefix(orderStatusToPB(status).String(), "ORDER_STATUS_"))
+}
+
+func newCheckoutID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/pricing"
+	cartpb "github.com/ahinestrog/mybookstore/proto/gen/cart"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+// fakeBroker guarda lo publicado en vez de enviarlo a RabbitMQ.
+type fakeBroker struct {
+	mu  sync.Mutex
+	out []publishedMsg
+}
+
+type publishedMsg struct {
+	rk   string
+	body []byte
+}
+
+func (b *fakeBroker) PublishJSON(ctx context.Context, rk string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.out = append(b.out, publishedMsg{rk, body})
+	b.mu.Unlock()
+	return nil
+}
+
+func (b *fakeBroker) ConsumeTopic(string, []string, ConsumerHandler) error { return nil }
+func (b *fakeBroker) QueueDepth(string) (int, error)                       { return 0, nil }
+
+// keys devuelve las routing keys publicadas, en orden.
+func (b *fakeBroker) keys() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	for _, m := range b.out {
+		out = append(out, m.rk)
+	}
+	return out
+}
+
+// fakeCart imita a Cart: el snapshot mueve el carrito vivo a checkout_id y un
+// reintento con el mismo id devuelve lo congelado.
+type fakeCart struct {
+	cartpb.CartClient
+	mu       sync.Mutex
+	live     map[int64][]*cartpb.CartItem
+	frozen   map[string][]*cartpb.CartItem
+	restored []string
+	// afterSnapshot corre después de congelar (simula lo que pasa mientras Order guarda)
+	afterSnapshot func(checkoutID string)
+}
+
+func newFakeCart() *fakeCart {
+	return &fakeCart{live: map[int64][]*cartpb.CartItem{}, frozen: map[string][]*cartpb.CartItem{}}
+}
+
+func (c *fakeCart) add(userID, bookID int64, qty int32, unitCents int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.live[userID] = append(c.live[userID], &cartpb.CartItem{
+		BookId:      bookID,
+		Title:       "libro",
+		Qty:         qty,
+		UnitPrice:   &commonpb.Money{Cents: unitCents},
+		LineTotal:   &commonpb.Money{Cents: unitCents * int64(qty)},
+		Category:    "libros",
+		WeightGrams: 300,
+	})
+}
+
+func (c *fakeCart) CheckoutSnapshot(ctx context.Context, req *cartpb.CheckoutSnapshotRequest, _ ...grpc.CallOption) (*cartpb.CheckoutSnapshotResponse, error) {
+	c.mu.Lock()
+	items, ok := c.frozen[req.GetCheckoutId()]
+	if !ok {
+		items = c.live[req.GetUserId()]
+		delete(c.live, req.GetUserId())
+		if len(items) > 0 {
+			c.frozen[req.GetCheckoutId()] = items
+		}
+	}
+	hook := c.afterSnapshot
+	c.mu.Unlock()
+	if hook != nil {
+		hook(req.GetCheckoutId())
+	}
+	return &cartpb.CheckoutSnapshotResponse{CheckoutId: req.GetCheckoutId(), Cart: &cartpb.CartView{Items: items}}, nil
+}
+
+func (c *fakeCart) RestoreCheckout(ctx context.Context, req *cartpb.RestoreCheckoutRequest, _ ...grpc.CallOption) (*commonpb.Ack, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if items, ok := c.frozen[req.GetCheckoutId()]; ok {
+		c.live[req.GetUserId()] = append(c.live[req.GetUserId()], items...)
+		delete(c.frozen, req.GetCheckoutId())
+	}
+	c.restored = append(c.restored, req.GetCheckoutId())
+	return &commonpb.Ack{Ok: true}, nil
+}
+
+func newTestServer(t *testing.T) (*OrderServer, *fakeCart, *fakeBroker) {
+	t.Helper()
+	repo, err := NewRepository(filepath.Join(t.TempDir(), "order.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	cart, rb := newFakeCart(), &fakeBroker{}
+	s := NewOrderServer(repo, rb, &CartClient{cli: cart}, nil, pricing.Default(), 0,
+		SagaTimeouts{Inventory: time.Minute, Payment: time.Minute, Checkout: time.Minute})
+	return s, cart, rb
+}
+
+// asUser es el contexto de un RPC autenticado como userID.
+func asUser(userID int64) context.Context {
+	return auth.NewContext(context.Background(), auth.User(userID))
+}
+
+func TestCreateOrderEmptyCart(t *testing.T) {
+	s, _, _ := newTestServer(t)
+	_, err := s.CreateOrder(asUser(1), &orderpb.CreateOrderRequest{UserId: 1, IdempotencyKey: "k1"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("err = %v, se esperaba FailedPrecondition", err)
+	}
+	stale, err := s.repo.ClaimStaleCheckouts(context.Background(), time.Now().Add(time.Hour).Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 0 {
+		t.Fatalf("quedó anotado un checkout vacío: %+v", stale)
+	}
+}
+
+// TestSweepOrphanCheckouts: Order cayó entre CheckoutSnapshot y guardar la
+// orden; el barrido devuelve esas líneas y no toca las que sí tienen orden.
+func TestSweepOrphanCheckouts(t *testing.T) {
+	ctx := context.Background()
+	s, cart, _ := newTestServer(t)
+	old := time.Now().Add(-time.Hour).Unix()
+
+	// Huérfano: anotado y congelado, sin orden
+	cart.add(1, 10, 2, 1000)
+	if err := s.repo.SaveCheckoutIntent(ctx, CheckoutIntent{CheckoutID: "orphan", UserID: 1, CreatedUnix: old}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cart.CheckoutSnapshot(ctx, &cartpb.CheckoutSnapshotRequest{UserId: 1, CheckoutId: "orphan"}); err != nil {
+		t.Fatal(err)
+	}
+	// Con orden, y anotado de nuevo por un reintento concurrente con la misma clave
+	cart.add(2, 11, 1, 1000)
+	if _, err := s.CreateOrder(asUser(2), &orderpb.CreateOrderRequest{UserId: 2, IdempotencyKey: "ordered"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.repo.SaveCheckoutIntent(ctx, CheckoutIntent{CheckoutID: "ordered", UserID: 2, CreatedUnix: old}); err != nil {
+		t.Fatal(err)
+	}
+	// Reciente: Order aún puede estar guardándolo
+	if err := s.repo.SaveCheckoutIntent(ctx, CheckoutIntent{CheckoutID: "fresh", UserID: 3, CreatedUnix: nowUnix()}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.sweepCheckouts(ctx, time.Now())
+	if len(cart.restored) != 1 || cart.restored[0] != "orphan" {
+		t.Fatalf("restaurados = %v, se esperaba solo orphan", cart.restored)
+	}
+	if len(cart.live[1]) != 1 || cart.live[1][0].GetQty() != 2 {
+		t.Fatalf("carrito del usuario 1 = %v", cart.live[1])
+	}
+	// Una segunda pasada no repite nada
+	s.sweepCheckouts(ctx, time.Now())
+	if len(cart.restored) != 1 {
+		t.Fatalf("restaurados = %v tras la segunda pasada", cart.restored)
+	}
+}
+
+// TestCreateOrderAfterSweep: si el barrido reclama el checkout mientras Order
+// guarda la orden, la orden no se crea; el reintento con la misma clave vuelve
+// a congelar el carrito y sale bien.
+func TestCreateOrderAfterSweep(t *testing.T) {
+	s, cart, _ := newTestServer(t)
+	cart.add(1, 10, 2, 1000)
+	cart.afterSnapshot = func(string) {
+		cart.afterSnapshot = nil
+		s.sweepCheckouts(context.Background(), time.Now().Add(time.Hour))
+	}
+	req := &orderpb.CreateOrderRequest{UserId: 1, IdempotencyKey: "k1"}
+	if _, err := s.CreateOrder(asUser(1), req); status.Code(err) != codes.Aborted {
+		t.Fatalf("err = %v, se esperaba Aborted", err)
+	}
+	if len(cart.live[1]) != 1 {
+		t.Fatalf("las líneas no volvieron al carrito: %v", cart.live[1])
+	}
+
+	resp, err := s.CreateOrder(asUser(1), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetOrderId() == 0 || len(resp.GetItems()) != 1 {
+		t.Fatalf("respuesta = %v", resp)
+	}
+	if len(cart.live[1]) != 0 {
+		t.Fatalf("el carrito debería estar congelado: %v", cart.live[1])
+	}
+}
+
+func TestCreateOrderIdempotentReplay(t *testing.T) {
+	s, cart, rb := newTestServer(t)
+	cart.add(1, 10, 2, 1000)
+	req := &orderpb.CreateOrderRequest{UserId: 1, IdempotencyKey: "k1", PaymentMethod: commonpb.PaymentMethod_PAYMENT_METHOD_PSE}
+
+	first, err := s.CreateOrder(asUser(1), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Doble clic: el carrito ya está congelado y la respuesta sale de la clave
+	again, err := s.CreateOrder(asUser(1), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.GetOrderId() != first.GetOrderId() || again.GetTotal().GetCents() != first.GetTotal().GetCents() {
+		t.Fatalf("replay = %v, original = %v", again, first)
+	}
+	if got := rb.keys(); len(got) != 1 || got[0] != RKOrderCreated {
+		t.Fatalf("publicados = %v, se esperaba un solo order.created", got)
+	}
+
+	// La misma clave con otro contenido es un error del cliente
+	other := &orderpb.CreateOrderRequest{UserId: 1, IdempotencyKey: "k1", PaymentMethod: commonpb.PaymentMethod_PAYMENT_METHOD_STORE_CREDIT}
+	_, err = s.CreateOrder(asUser(1), other)
+	if status.Code(err) != codes.InvalidArgument || !strings.Contains(err.Error(), "idempotency_key") {
+		t.Fatalf("err = %v, se esperaba InvalidArgument por la clave reusada", err)
+	}
+}
+
+// TestCreateOrderReplayNewWalletAmount: tras el primer intento Payment ya debitó
+// el monedero, así que el reintento llega con el saldo que queda; igual es la
+// misma solicitud y devuelve la orden original.
+func TestCreateOrderReplayNewWalletAmount(t *testing.T) {
+	s, cart, _ := newTestServer(t)
+	cart.add(1, 10, 2, 1000)
+	req := &orderpb.CreateOrderRequest{UserId: 1, IdempotencyKey: "k1",
+		PaymentMethod: commonpb.PaymentMethod_PAYMENT_METHOD_PSE, WalletAmount: &commonpb.Money{Cents: 500}}
+
+	first, err := s.CreateOrder(asUser(1), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.WalletAmount = &commonpb.Money{Cents: 200}
+	again, err := s.CreateOrder(asUser(1), req)
+	if err != nil {
+		t.Fatalf("reintento con otro saldo: %v", err)
+	}
+	if again.GetOrderId() != first.GetOrderId() {
+		t.Fatalf("replay = %v, original = %v", again, first)
+	}
+	o, err := s.repo.GetOrder(context.Background(), first.GetOrderId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.WalletCents != 500 {
+		t.Fatalf("wallet_cents = %d, se esperaba el del primer intento", o.WalletCents)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	userpb "github.com/ahinestrog/mybookstore/proto/gen/user"
+)
+
+// UserClient consulta la libreta de direcciones del servicio de usuarios.
+type UserClient struct {
+	cli userpb.UserClient
+}
+
+func NewUserClient(addr string) (*UserClient, error) {
+	cc, err := grpcclient.Conn(addr)
+	if err != nil { return nil, err }
+	return &UserClient{cli: userpb.NewUserClient(cc)}, nil
+}
+
+// GetAddress devuelve la dirección solo si pertenece a userID.
+func (c *UserClient) GetAddress(ctx context.Context, userID, addressID int64) (*ShippingAddress, error) {
+	sa, err := c.cli.GetAddress(ctx, &userpb.AddressRef{UserId: userID, AddressId: addressID})
+	if err != nil { return nil, err }
+	a := sa.GetAddress()
+	return &ShippingAddress{
+		Recipient:  a.GetRecipient(),
+		Line1:      a.GetLine1(),
+		Line2:      a.GetLine2(),
+		City:       a.GetCity(),
+		Region:     a.GetRegion(),
+		PostalCode: a.GetPostalCode(),
+		Country:    a.GetCountry(),
+		Phone:      a.GetPhone(),
+	}, nil
+}
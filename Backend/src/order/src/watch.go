@@ -0,0 +1,131 @@
+package main
+
+import (
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+// orderWatchers despierta a los streams WatchOrder de una orden cuando el
+// repositorio guarda un evento suyo. El aviso no lleva datos: cada stream lee
+// order_events desde su último id, así un aviso perdido o repetido no cambia lo
+// que recibe el cliente. Basta en memoria porque order corre con una réplica.
+type orderWatchers struct {
+	mu   sync.Mutex
+	subs map[int64]map[chan struct{}]struct{}
+}
+
+func newOrderWatchers() *orderWatchers {
+	return &orderWatchers{subs: map[int64]map[chan struct{}]struct{}{}}
+}
+
+// subscribe devuelve un canal que recibe un aviso (acumulado, de capacidad 1)
+// por cada cambio de la orden, y la función para dejar de escuchar.
+func (w *orderWatchers) subscribe(orderID int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	w.mu.Lock()
+	if w.subs[orderID] == nil {
+		w.subs[orderID] = map[chan struct{}]struct{}{}
+	}
+	w.subs[orderID][ch] = struct{}{}
+	w.mu.Unlock()
+	return ch, func() {
+		w.mu.Lock()
+		delete(w.subs[orderID], ch)
+		if len(w.subs[orderID]) == 0 {
+			delete(w.subs, orderID)
+		}
+		w.mu.Unlock()
+	}
+}
+
+func (w *orderWatchers) notify(orderID int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for ch := range w.subs[orderID] {
+		select {
+		case ch <- struct{}{}:
+		default: // ya tiene un aviso pendiente
+		}
+	}
+}
+
+func (s *OrderServer) WatchOrder(req *orderpb.WatchOrderRequest, stream orderpb.Order_WatchOrderServer) error {
+	ctx := stream.Context()
+	if req.GetAfterEventId() < 0 {
+		return status.Error(codes.InvalidArgument, "after_event_id no puede ser negativo")
+	}
+	o, err := s.repo.GetOrder(ctx, req.GetOrderId())
+	if err != nil { return orderErr(err) }
+	if err := auth.CheckUser(ctx, o.UserID); err != nil { return err }
+
+	// Suscribirse antes de leer: un cambio entre la lectura y la espera deja un aviso pendiente
+	kick, stop := s.repo.watchers.subscribe(o.ID)
+	defer stop()
+
+	last, err := s.repo.LastEvent(ctx, o.ID)
+	if err != nil { return err }
+	after := req.GetAfterEventId()
+	switch {
+	case after == 0:
+		// Primer mensaje: el estado actual (el último evento, o la orden si no tiene historial)
+		if last == nil {
+			f, err := s.repo.GetFulfillment(ctx, o.ID)
+			if err != nil { return err }
+			last = &OrderEvent{OrderID: o.ID, Status: o.Status, StatusReason: o.StatusReason, CreatedUnix: o.UpdatedUnix}
+			if f != nil {
+				last.FulfillmentState = f.State
+			}
+		}
+		ev := orderEventToPB(last)
+		if err := stream.Send(ev); err != nil { return err }
+		if ev.GetFinal() { return nil }
+		after = last.ID
+	case last != nil && last.ID <= after && isFinal(last):
+		// Reconexión después del evento final
+		return nil
+	}
+	for {
+		events, err := s.repo.ListEvents(ctx, o.ID, after)
+		if err != nil { return err }
+		for i := range events {
+			ev := orderEventToPB(&events[i])
+			if err := stream.Send(ev); err != nil { return err }
+			if ev.GetFinal() { return nil }
+			after = events[i].ID
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-kick:
+		}
+	}
+}
+
+func orderEventToPB(e *OrderEvent) *orderpb.OrderEvent {
+	return &orderpb.OrderEvent{
+		EventId:          e.ID,
+		OrderId:          e.OrderID,
+		Status:           orderStatusToPB(e.Status),
+		StatusReason:     e.StatusReason,
+		FulfillmentState: orderpb.FulfillmentState(e.FulfillmentState),
+		CreatedUnix:      e.CreatedUnix,
+		Final:            isFinal(e),
+	}
+}
+
+// isFinal indica si la orden ya no puede cambiar: la saga falló o se canceló, o
+// está cobrada y entregada (contra entrega aún pasa de CONFIRMED a PAID).
+func isFinal(e *OrderEvent) bool {
+	switch e.Status {
+	case OrderStatusFailed, OrderStatusCancelled:
+		return true
+	case OrderStatusPaid:
+		return e.FulfillmentState == FulfillmentDelivered
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+// watchStream es el lado servidor de WatchOrder: lo enviado sale por events.
+type watchStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *orderpb.OrderEvent
+}
+
+func (w *watchStream) Context() context.Context { return w.ctx }
+
+func (w *watchStream) Send(ev *orderpb.OrderEvent) error {
+	w.events <- ev
+	return nil
+}
+
+// watch abre WatchOrder en otra goroutine; done recibe lo que devuelve.
+func watch(t *testing.T, s *OrderServer, userID int64, req *orderpb.WatchOrderRequest) (*watchStream, <-chan error) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(asUser(userID))
+	t.Cleanup(cancel)
+	w := &watchStream{ctx: ctx, events: make(chan *orderpb.OrderEvent, 16)}
+	done := make(chan error, 1)
+	go func() { done <- s.WatchOrder(req, w) }()
+	return w, done
+}
+
+func (w *watchStream) next(t *testing.T) *orderpb.OrderEvent {
+	t.Helper()
+	select {
+	case ev := <-w.events:
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("no llegó el evento")
+		return nil
+	}
+}
+
+func waitDone(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchOrder no terminó")
+		return nil
+	}
+}
+
+// TestWatchOrderResume: el cliente se reconecta con el último evento que vio,
+// recibe lo que se perdió, espera los cambios siguientes y el stream termina
+// con la entrega.
+func TestWatchOrderResume(t *testing.T) {
+	ctx := context.Background()
+	s, _, rb := newTestServer(t)
+	admin := NewOrderAdminServer(s.repo, rb)
+	id := paidOrder(t, s) // eventos 1 CREATED, 2 PAID, 3 PAID/PENDING
+
+	w, done := watch(t, s, 1, &orderpb.WatchOrderRequest{OrderId: id, AfterEventId: 2})
+	ev := w.next(t)
+	if ev.GetEventId() != 3 || ev.GetFulfillmentState() != orderpb.FulfillmentState_FULFILLMENT_STATE_PENDING || ev.GetFinal() {
+		t.Fatalf("primer evento = %v", ev)
+	}
+
+	for _, st := range []orderpb.FulfillmentState{
+		orderpb.FulfillmentState_FULFILLMENT_STATE_PICKING,
+		orderpb.FulfillmentState_FULFILLMENT_STATE_PACKED,
+		orderpb.FulfillmentState_FULFILLMENT_STATE_SHIPPED,
+		orderpb.FulfillmentState_FULFILLMENT_STATE_DELIVERED,
+	} {
+		if _, err := admin.UpdateFulfillment(ctx, &orderpb.UpdateFulfillmentRequest{
+			OrderId: id, State: st, Carrier: "Servientrega", TrackingNumber: "SV-1",
+		}); err != nil {
+			t.Fatal(err)
+		}
+		ev := w.next(t)
+		if ev.GetFulfillmentState() != st || ev.GetFinal() != (st == orderpb.FulfillmentState_FULFILLMENT_STATE_DELIVERED) {
+			t.Fatalf("evento = %v, se esperaba %s", ev, st)
+		}
+	}
+	if err := waitDone(t, done); err != nil {
+		t.Fatalf("el stream terminó con %v", err)
+	}
+
+	// Reconexión después del evento final: termina sin enviar nada
+	w, done = watch(t, s, 1, &orderpb.WatchOrderRequest{OrderId: id, AfterEventId: 7})
+	if err := waitDone(t, done); err != nil || len(w.events) != 0 {
+		t.Fatalf("err = %v, eventos = %d", err, len(w.events))
+	}
+}
+
+// TestWatchOrderEndsOnFailure: sin after_event_id el primer mensaje es el
+// estado actual, y el rechazo de Inventory cierra el stream.
+func TestWatchOrderEndsOnFailure(t *testing.T) {
+	s, cart, _ := newTestServer(t)
+	cart.add(1, 10, 2, 1000)
+	resp, err := s.CreateOrder(asUser(1), &orderpb.CreateOrderRequest{UserId: 1, IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, done := watch(t, s, 1, &orderpb.WatchOrderRequest{OrderId: resp.GetOrderId()})
+	if ev := w.next(t); ev.GetStatus() != orderpb.OrderStatus_ORDER_STATUS_CREATED || ev.GetFinal() {
+		t.Fatalf("estado actual = %v", ev)
+	}
+	body, _ := json.Marshal(InventoryResultPayload{OrderID: resp.GetOrderId(), Reason: "sin stock"})
+	if err := s.handleEvent(context.Background(), RKInventoryRejected, body); err != nil {
+		t.Fatal(err)
+	}
+	if ev := w.next(t); ev.GetStatus() != orderpb.OrderStatus_ORDER_STATUS_FAILED || !ev.GetFinal() {
+		t.Fatalf("evento final = %v", ev)
+	}
+	if err := waitDone(t, done); err != nil {
+		t.Fatalf("el stream terminó con %v", err)
+	}
+}
+
+func TestWatchOrderAccess(t *testing.T) {
+	s, _, _ := newTestServer(t)
+	id := paidOrder(t, s)
+
+	_, done := watch(t, s, 2, &orderpb.WatchOrderRequest{OrderId: id})
+	if err := waitDone(t, done); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("otro usuario: err = %v", err)
+	}
+	_, done = watch(t, s, 1, &orderpb.WatchOrderRequest{OrderId: id + 1})
+	if err := waitDone(t, done); status.Code(err) != codes.NotFound {
+		t.Fatalf("orden inexistente: err = %v", err)
+	}
+}
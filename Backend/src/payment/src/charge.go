@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// charge ejecuta el intento de cobro abierto del pago (o abre uno) en la
+// pasarela de su medio de pago. Si la pasarela no responde se devuelve el error
+// para que el mensaje se reencole y se reintente con la misma clave; pasados
+// ChargeMaxRetries el pago queda PENDING y lo retoma el reconciliador.
+//
+// La parte del monedero (WalletCents) se descuenta antes de ir a la pasarela,
+// con una clave por intento; si el pago falla, SetResult la devuelve.
+func (s *service) charge(ctx context.Context, p *Payment) error {
+	orderID := p.OrderID
+	external := p.AmountCents - p.WalletCents
+	var provider PaymentProvider
+	req := ChargeRequest{OrderID: orderID, UserID: p.UserID, Method: p.Method}
+	if external > 0 {
+		var err error
+		if provider, err = s.providers.forMethod(p.Method); err != nil {
+			slog.WarnContext(ctx, "charge", "order_id", orderID, "err", err)
+			return s.settle(ctx, orderID, ChargeResult{Status: ChargeDeclined, FailReason: "method_unavailable"}, "charge")
+		}
+	}
+	if p.Method == MethodCard && external > 0 {
+		in, err := s.repo.GetInstrument(ctx, p.InstrumentID)
+		if err != nil {
+			return err
+		}
+		// Eliminarla después del checkout no cancela la compra hecha con ella
+		if in == nil || in.UserID != p.UserID {
+			return s.settle(ctx, orderID, ChargeResult{Status: ChargeDeclined, FailReason: "invalid_instrument"}, "charge")
+		}
+		req.InstrumentToken = in.Token
+	}
+
+	a, err := s.repo.OpenAttempt(ctx, orderID, external)
+	if err != nil {
+		return err
+	}
+	if a.State == AttemptPending {
+		return nil // la pasarela ya lo tiene; falta el webhook (o la entrega)
+	}
+	if p.WalletCents > 0 {
+		_, err := s.repo.ApplyWallet(ctx, &WalletEntry{
+			UserID:      p.UserID,
+			Kind:        WalletPayment,
+			AmountCents: -p.WalletCents,
+			OrderID:     orderID,
+			Reference:   fmt.Sprintf("orden #%d", orderID),
+			IdemKey:     "debit:" + a.IdemKey,
+		})
+		if errors.Is(err, ErrInsufficientFunds) {
+			return s.settle(ctx, orderID, ChargeResult{Status: ChargeDeclined, FailReason: "insufficient_store_credit"}, "charge")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if a.AmountCents == 0 {
+		// Todo con saldo a favor: no hay nada que cobrar en la pasarela
+		return s.settle(ctx, orderID, ChargeResult{Status: ChargeApproved, ProviderRef: walletRef(orderID)}, "charge")
+	}
+
+	req.AmountCents = a.AmountCents
+	req.IdempotencyKey = a.IdemKey
+	cctx, cancel := context.WithTimeout(ctx, s.cfg.ChargeTimeout)
+	res, err := provider.Charge(cctx, req)
+	cancel()
+	if err != nil {
+		if !isRetryable(err) {
+			slog.WarnContext(ctx, "charge", "order_id", orderID, "key", a.IdemKey, "err", err)
+			return s.settle(ctx, orderID, ChargeResult{Status: ChargeDeclined, FailReason: "provider_rejected_request"}, "charge")
+		}
+		a.Retries++
+		a.FailReason = err.Error()
+		if uerr := s.repo.UpdateAttempt(ctx, a); uerr != nil {
+			return uerr
+		}
+		if a.Retries < s.cfg.ChargeMaxRetries {
+			return err
+		}
+		slog.WarnContext(ctx, "charge sin respuesta; queda PENDING para el reconciliador",
+			"order_id", orderID, "key", a.IdemKey, "retries", a.Retries, "err", err)
+		return nil
+	}
+
+	switch res.Status {
+	case ChargeApproved, ChargeDeclined:
+		return s.settle(ctx, orderID, res, "charge")
+	case ChargeDeferred:
+		return s.deferCharge(ctx, a, res)
+	}
+	// Pasarela asíncrona: el resultado llega por webhook o lo busca el reconciliador.
+	// El intento se marca al final: si algo falla antes, el reintento repite el cobro.
+	if err := s.repo.SetPending(ctx, orderID, res.ProviderRef, res.RedirectURL); err != nil {
+		return err
+	}
+	a.State = AttemptPending
+	a.ProviderRef = res.ProviderRef
+	a.FailReason = ""
+	if err := s.repo.UpdateAttempt(ctx, a); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "PENDING", "order_id", orderID, "key", a.IdemKey, "ref", res.ProviderRef)
+	return nil
+}
+
+// deferCharge deja el pago contra entrega esperando order.delivered y avisa a
+// Order para que despache sin cobrar.
+func (s *service) deferCharge(ctx context.Context, a *ChargeAttempt, res ChargeResult) error {
+	if err := s.repo.SetDeferred(ctx, a.OrderID, res.ProviderRef); err != nil {
+		return err
+	}
+	a.State = AttemptPending
+	a.ProviderRef = res.ProviderRef
+	a.FailReason = ""
+	if err := s.repo.UpdateAttempt(ctx, a); err != nil {
+		return err
+	}
+	s.publishJSON(ctx, "payment.deferred", PaymentDeferred{OrderID: a.OrderID, ProviderRef: res.ProviderRef})
+	slog.InfoContext(ctx, "AWAITING_DELIVERY", "order_id", a.OrderID, "ref", res.ProviderRef)
+	return nil
+}
@@ -0,0 +1,540 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Pruebas de idempotencia del cobro: se inyectan timeouts en cada paso
+// (pasarela y base de datos) y se comprueba que, tras los reintentos, el banco
+// cobró una sola vez, con la misma clave, y se publicó un solo evento.
+
+type fault int
+
+const (
+	noFault      fault = iota
+	lostRequest        // timeout antes de llegar al banco
+	lostResponse       // el banco cobró pero la respuesta no llegó
+	hang               // la pasarela no responde hasta que vence el contexto
+	badRequest         // la pasarela rechaza la petición (4xx)
+)
+
+// testBank simula una pasarela que respeta la clave de idempotencia.
+type testBank struct {
+	mu      sync.Mutex
+	async   bool             // deja los cobros PENDING hasta resolve
+	decline map[int64]string // order → motivo de rechazo
+	faults  []fault          // una por llamada a Charge, en orden
+	byKey   map[string]ChargeResult
+	charges int // cobros reales (claves distintas procesadas)
+	keys    []string
+
+	refundFaults  []fault                 // una por llamada a Refund, en orden
+	refundDecline string                  // motivo para rechazar los reembolsos
+	refunds       map[string]RefundResult // reembolsos hechos por clave
+	refundKeys    []string
+	lastRefund    RefundRequest
+}
+
+func newTestBank() *testBank {
+	return &testBank{decline: map[int64]string{}, byKey: map[string]ChargeResult{}, refunds: map[string]RefundResult{}}
+}
+
+func (b *testBank) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	b.mu.Lock()
+	f := noFault
+	if len(b.faults) > 0 {
+		f, b.faults = b.faults[0], b.faults[1:]
+	}
+	b.keys = append(b.keys, req.IdempotencyKey)
+	b.mu.Unlock()
+
+	switch f {
+	case lostRequest:
+		return ChargeResult{}, &ProviderError{Retryable: true, Err: context.DeadlineExceeded}
+	case hang:
+		<-ctx.Done()
+		return ChargeResult{}, &ProviderError{Retryable: true, Err: ctx.Err()}
+	case badRequest:
+		return ChargeResult{}, &ProviderError{Err: errors.New("400 Bad Request")}
+	}
+
+	b.mu.Lock()
+	res, seen := b.byKey[req.IdempotencyKey]
+	if !seen {
+		b.charges++
+		res = ChargeResult{Status: ChargeApproved, ProviderRef: "BANK-" + req.IdempotencyKey}
+		if reason, ok := b.decline[req.OrderID]; ok {
+			res.Status, res.FailReason = ChargeDeclined, reason
+		} else if b.async {
+			res.Status = ChargePending
+		}
+		b.byKey[req.IdempotencyKey] = res
+	}
+	b.mu.Unlock()
+
+	if f == lostResponse {
+		return ChargeResult{}, &ProviderError{Retryable: true, Err: context.DeadlineExceeded}
+	}
+	return res, nil
+}
+
+func (b *testBank) QueryCharge(ctx context.Context, orderID int64, providerRef string) (ChargeResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, res := range b.byKey {
+		if res.ProviderRef == providerRef {
+			return res, nil
+		}
+	}
+	return ChargeResult{}, fmt.Errorf("ref %s desconocida", providerRef)
+}
+
+// Refund respeta la clave igual que Charge: la misma clave no reembolsa dos veces.
+func (b *testBank) Refund(ctx context.Context, req RefundRequest) (RefundResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	f := noFault
+	if len(b.refundFaults) > 0 {
+		f, b.refundFaults = b.refundFaults[0], b.refundFaults[1:]
+	}
+	b.refundKeys = append(b.refundKeys, req.IdempotencyKey)
+	b.lastRefund = req
+	switch f {
+	case lostRequest:
+		return RefundResult{}, &ProviderError{Retryable: true, Err: context.DeadlineExceeded}
+	case badRequest:
+		return RefundResult{}, &ProviderError{Err: errors.New("400 Bad Request")}
+	}
+	if b.refundDecline != "" {
+		return RefundResult{FailReason: b.refundDecline}, nil
+	}
+	res, seen := b.refunds[req.IdempotencyKey]
+	if !seen {
+		res = RefundResult{Approved: true, ProviderRef: "BANK-RFD-" + req.IdempotencyKey}
+		b.refunds[req.IdempotencyKey] = res
+	}
+	if f == lostResponse {
+		return RefundResult{}, &ProviderError{Retryable: true, Err: context.DeadlineExceeded}
+	}
+	return res, nil
+}
+
+// resolve aprueba los cobros pendientes, como haría el cliente en el banco.
+func (b *testBank) resolve() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for k, res := range b.byKey {
+		if res.Status == ChargePending {
+			res.Status = ChargeApproved
+			b.byKey[k] = res
+		}
+	}
+}
+
+// faultyRepo devuelve un timeout en la próxima llamada a los métodos indicados.
+type faultyRepo struct {
+	Repository
+	mu   sync.Mutex
+	fail map[string]int
+}
+
+func (r *faultyRepo) inject(method string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail[method] > 0 {
+		r.fail[method]--
+		return fmt.Errorf("%s: %w", method, context.DeadlineExceeded)
+	}
+	return nil
+}
+
+func (r *faultyRepo) GetByOrderID(ctx context.Context, orderID int64) (*Payment, error) {
+	if err := r.inject("GetByOrderID"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByOrderID(ctx, orderID)
+}
+
+func (r *faultyRepo) UpsertPending(ctx context.Context, p Payment) error {
+	if err := r.inject("UpsertPending"); err != nil {
+		return err
+	}
+	return r.Repository.UpsertPending(ctx, p)
+}
+
+func (r *faultyRepo) OpenAttempt(ctx context.Context, orderID, amountCents int64) (*ChargeAttempt, error) {
+	if err := r.inject("OpenAttempt"); err != nil {
+		return nil, err
+	}
+	return r.Repository.OpenAttempt(ctx, orderID, amountCents)
+}
+
+func (r *faultyRepo) UpdateAttempt(ctx context.Context, a *ChargeAttempt) error {
+	if err := r.inject("UpdateAttempt"); err != nil {
+		return err
+	}
+	return r.Repository.UpdateAttempt(ctx, a)
+}
+
+func (r *faultyRepo) SetPending(ctx context.Context, orderID int64, providerRef, redirectURL string) error {
+	if err := r.inject("SetPending"); err != nil {
+		return err
+	}
+	return r.Repository.SetPending(ctx, orderID, providerRef, redirectURL)
+}
+
+func (r *faultyRepo) SetResult(ctx context.Context, orderID int64, state PaymentState, providerRef, failReason string) (bool, error) {
+	if err := r.inject("SetResult"); err != nil {
+		return false, err
+	}
+	return r.Repository.SetResult(ctx, orderID, state, providerRef, failReason)
+}
+
+type recordingPublisher struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (p *recordingPublisher) publishJSON(ctx context.Context, key string, v any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, key)
+}
+
+func (p *recordingPublisher) count(key string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, k := range p.keys {
+		if k == key {
+			n++
+		}
+	}
+	return n
+}
+
+type harness struct {
+	svc  *service
+	repo *faultyRepo
+	bank *testBank
+	pub  *recordingPublisher
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	base, err := newSQLiteRepo(filepath.Join(t.TempDir(), "payment.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := base.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	h := &harness{
+		repo: &faultyRepo{Repository: base, fail: map[string]int{}},
+		bank: newTestBank(),
+		pub:  &recordingPublisher{},
+	}
+	h.svc = &service{
+		cfg: Config{
+			ChargeTimeout:    50 * time.Millisecond,
+			ChargeMaxRetries: 5,
+			PendingExpiry:    time.Hour,
+		},
+		repo:      h.repo,
+		providers: providerRoutes{MethodUnspecified: h.bank},
+		br:        h.pub,
+	}
+	return h
+}
+
+// deliver entrega el mensaje de cobro como RabbitMQ: se reencola mientras el
+// handler devuelva error. Devuelve cuántas entregas hicieron falta.
+func (h *harness) deliver(t *testing.T, orderID, amountCents int64) int {
+	t.Helper()
+	body := []byte(fmt.Sprintf(`{"order_id":%d,"user_id":1,"amount_cents":%d}`, orderID, amountCents))
+	for n := 1; n <= 10; n++ {
+		if err := h.svc.handlePaymentRequested(context.Background(), body); err == nil {
+			return n
+		}
+	}
+	t.Fatalf("order %d: el mensaje sigue fallando tras 10 entregas", orderID)
+	return 0
+}
+
+func (h *harness) payment(t *testing.T, orderID int64) *Payment {
+	t.Helper()
+	p, err := h.repo.GetByOrderID(context.Background(), orderID)
+	if err != nil || p == nil {
+		t.Fatalf("order %d: pago no encontrado (%v)", orderID, err)
+	}
+	return p
+}
+
+// assertChargedOnce comprueba el invariante principal: un cobro en el banco,
+// un CHARGE en el libro y un payment.succeeded.
+func (h *harness) assertChargedOnce(t *testing.T, orderID int64) {
+	t.Helper()
+	if p := h.payment(t, orderID); p.State != PaymentStateSucceeded {
+		t.Fatalf("estado = %s, se esperaba SUCCEEDED", p.State)
+	}
+	if h.bank.charges != 1 {
+		t.Fatalf("el banco cobró %d veces", h.bank.charges)
+	}
+	want := chargeKey(orderID, 1)
+	for _, k := range h.bank.keys {
+		if k != want {
+			t.Fatalf("clave %q en un reintento; se esperaba siempre %q", k, want)
+		}
+	}
+	captured, _, err := h.repo.Balance(context.Background(), orderID)
+	if err != nil || captured != 1500 {
+		t.Fatalf("capturado = %d (%v), se esperaba 1500", captured, err)
+	}
+	if n := h.pub.count("payment.succeeded"); n != 1 {
+		t.Fatalf("payment.succeeded publicado %d veces", n)
+	}
+}
+
+func TestChargeTimeoutAtEachStep(t *testing.T) {
+	cases := []struct {
+		name      string
+		bank      []fault
+		repo      string
+		wantTries int
+	}{
+		{name: "sin fallas", wantTries: 1},
+		{name: "request perdido", bank: []fault{lostRequest}, wantTries: 2},
+		{name: "respuesta perdida", bank: []fault{lostResponse}, wantTries: 2},
+		{name: "pasarela colgada", bank: []fault{hang}, wantTries: 2},
+		{name: "varios timeouts seguidos", bank: []fault{hang, lostResponse, lostRequest}, wantTries: 4},
+		{name: "timeout leyendo el pago", repo: "GetByOrderID", wantTries: 2},
+		{name: "timeout creando el pago", repo: "UpsertPending", wantTries: 2},
+		{name: "timeout abriendo el intento", repo: "OpenAttempt", wantTries: 2},
+		{name: "timeout guardando el resultado", repo: "SetResult", wantTries: 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newHarness(t)
+			h.bank.faults = tc.bank
+			if tc.repo != "" {
+				h.repo.fail[tc.repo] = 1
+			}
+			if n := h.deliver(t, 42, 1500); n != tc.wantTries {
+				t.Errorf("entregas = %d, se esperaban %d", n, tc.wantTries)
+			}
+			h.assertChargedOnce(t, 42)
+		})
+	}
+}
+
+func TestChargeAsyncTimeouts(t *testing.T) {
+	for _, step := range []string{"", "UpdateAttempt", "SetPending"} {
+		t.Run("falla "+step, func(t *testing.T) {
+			h := newHarness(t)
+			h.bank.async = true
+			if step != "" {
+				h.repo.fail[step] = 1
+			}
+			h.deliver(t, 42, 1500)
+			if p := h.payment(t, 42); p.State != PaymentStatePending || p.ProviderRef == "" {
+				t.Fatalf("pago = %+v, se esperaba PENDING con referencia", p)
+			}
+			// Un redelivery mientras espera al banco no vuelve a llamar a la pasarela
+			calls := len(h.bank.keys)
+			h.deliver(t, 42, 1500)
+			if len(h.bank.keys) != calls {
+				t.Fatalf("redelivery de un pago PENDING llamó a la pasarela")
+			}
+
+			h.bank.resolve()
+			h.svc.reconcilePending(context.Background(), time.Now().Add(time.Minute))
+			h.assertChargedOnce(t, 42)
+		})
+	}
+}
+
+func TestChargeRetriesExhaustedThenReconciler(t *testing.T) {
+	h := newHarness(t)
+	h.svc.cfg.ChargeMaxRetries = 3
+	h.bank.faults = []fault{lostResponse, hang, lostRequest}
+	// Tres entregas fallidas: a la tercera el mensaje se confirma y el pago queda PENDING
+	if n := h.deliver(t, 42, 1500); n != 3 {
+		t.Fatalf("entregas = %d, se esperaban 3", n)
+	}
+	if p := h.payment(t, 42); p.State != PaymentStatePending {
+		t.Fatalf("estado = %s, se esperaba PENDING", p.State)
+	}
+	if n := h.pub.count("payment.succeeded") + h.pub.count("payment.failed"); n != 0 {
+		t.Fatalf("se publicaron %d resultados antes de saberlo", n)
+	}
+	h.svc.reconcilePending(context.Background(), time.Now().Add(time.Minute))
+	h.assertChargedOnce(t, 42)
+}
+
+func TestChargeExpiresWithoutAnswer(t *testing.T) {
+	h := newHarness(t)
+	h.svc.cfg.ChargeMaxRetries = 1
+	h.bank.faults = []fault{lostRequest}
+	h.deliver(t, 42, 1500)
+	h.svc.reconcilePending(context.Background(), time.Now().Add(2*time.Hour))
+	if p := h.payment(t, 42); p.State != PaymentStateFailed {
+		t.Fatalf("estado = %s, se esperaba FAILED", p.State)
+	}
+	if n := h.pub.count("payment.failed"); n != 1 {
+		t.Fatalf("payment.failed publicado %d veces", n)
+	}
+}
+
+func TestChargeFinalProviderError(t *testing.T) {
+	h := newHarness(t)
+	h.bank.faults = []fault{badRequest}
+	if n := h.deliver(t, 42, 1500); n != 1 {
+		t.Fatalf("un error final no debe reencolar (entregas = %d)", n)
+	}
+	if p := h.payment(t, 42); p.State != PaymentStateFailed {
+		t.Fatalf("estado = %s, se esperaba FAILED", p.State)
+	}
+	if h.bank.charges != 0 {
+		t.Fatalf("el banco cobró %d veces", h.bank.charges)
+	}
+}
+
+func TestChargeNewAttemptAfterDecline(t *testing.T) {
+	h := newHarness(t)
+	h.bank.decline[42] = "insufficient_funds"
+	h.deliver(t, 42, 1500)
+	if p := h.payment(t, 42); p.State != PaymentStateFailed {
+		t.Fatalf("estado = %s, se esperaba FAILED", p.State)
+	}
+
+	delete(h.bank.decline, 42)
+	h.deliver(t, 42, 1500)
+	if p := h.payment(t, 42); p.State != PaymentStateSucceeded {
+		t.Fatalf("estado = %s, se esperaba SUCCEEDED", p.State)
+	}
+	want := []string{chargeKey(42, 1), chargeKey(42, 2)}
+	if fmt.Sprint(h.bank.keys) != fmt.Sprint(want) {
+		t.Fatalf("claves = %v, se esperaban %v", h.bank.keys, want)
+	}
+}
+
+func TestDeclineLabel(t *testing.T) {
+	for reason, want := range map[string]string{
+		"insufficient_funds":                 "insufficient_funds",
+		"risk_rejected":                      "risk_rejected",
+		"bank_timeout":                       "bank_timeout",
+		"declined_by_bank":                   "rejected_by_bank",
+		"":                                   "unknown",
+		"Tarjeta bloqueada por el banco 123": "other",
+		`Post "http://pse/tx": EOF`:          "other",
+	} {
+		if got := declineLabel(reason); got != want {
+			t.Errorf("declineLabel(%q) = %q, se esperaba %q", reason, got, want)
+		}
+	}
+}
+
+func TestChargeRedeliveryAfterSuccess(t *testing.T) {
+	h := newHarness(t)
+	h.deliver(t, 42, 1500)
+	h.deliver(t, 42, 1500)
+	if len(h.bank.keys) != 1 {
+		t.Fatalf("la pasarela recibió %d llamadas", len(h.bank.keys))
+	}
+	// El evento se repite para que Order lo reciba si se perdió la primera vez
+	if n := h.pub.count("payment.succeeded"); n != 2 {
+		t.Fatalf("payment.succeeded publicado %d veces, se esperaban 2", n)
+	}
+}
+
+func TestOrderExpiredVoidsOrRefunds(t *testing.T) {
+	h := newHarness(t)
+	expire := func(orderID int64) {
+		t.Helper()
+		body := []byte(fmt.Sprintf(`{"order_id":%d,"reason":"payment_timeout"}`, orderID))
+		if err := h.svc.handleOrderExpired(context.Background(), body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Sin respuesta del banco: el pago falla y una aprobación tardía ya no cuenta
+	h.bank.async = true
+	h.deliver(t, 42, 1500)
+	expire(42)
+	if p := h.payment(t, 42); p.State != PaymentStateFailed {
+		t.Fatalf("estado = %s, se esperaba FAILED", p.State)
+	}
+	h.bank.resolve()
+	h.svc.reconcilePending(context.Background(), time.Now().Add(time.Minute))
+	if h.pub.count("payment.succeeded") != 0 || h.pub.count("payment.failed") != 1 {
+		t.Fatalf("eventos publicados: %v", h.pub.keys)
+	}
+
+	// Ya cobrado: se reembolsa completo una sola vez aunque el evento se repita
+	h.bank.async = false
+	h.deliver(t, 43, 2000)
+	expire(43)
+	expire(43)
+	if p := h.payment(t, 43); p.State != PaymentStateRefunded {
+		t.Fatalf("estado = %s, se esperaba REFUNDED", p.State)
+	}
+	if captured, refunded, err := h.repo.Balance(context.Background(), 43); err != nil || captured != 2000 || refunded != 2000 {
+		t.Fatalf("capturado = %d, reembolsado = %d (%v)", captured, refunded, err)
+	}
+
+	// Una orden sin cobro no hace nada
+	expire(44)
+}
+
+func TestPSEErrorClassification(t *testing.T) {
+	cases := []struct {
+		name      string
+		handler   http.HandlerFunc
+		retryable bool
+	}{
+		{"503", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "down", http.StatusServiceUnavailable) }, true},
+		{"429", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "slow down", http.StatusTooManyRequests) }, true},
+		{"400", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "bad", http.StatusBadRequest) }, false},
+		{"422", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "reused", http.StatusUnprocessableEntity) }, false},
+		{"respuesta cortada", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(`{"id":`)) }, true},
+		{"timeout", func(w http.ResponseWriter, r *http.Request) { time.Sleep(200 * time.Millisecond) }, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// El handler del timeout sigue corriendo cuando Charge ya volvió
+			var mu sync.Mutex
+			var gotKey string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				gotKey = r.Header.Get("Idempotency-Key")
+				mu.Unlock()
+				tc.handler(w, r)
+			}))
+			defer srv.Close()
+			p, err := newPSEProvider(Config{PSEBaseURL: srv.URL, PSEHTTPTimeout: 50 * time.Millisecond})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = p.Charge(context.Background(), ChargeRequest{OrderID: 7, AmountCents: 100, IdempotencyKey: chargeKey(7, 1)})
+			if err == nil {
+				t.Fatal("se esperaba error")
+			}
+			if got := isRetryable(err); got != tc.retryable {
+				t.Fatalf("isRetryable = %v, se esperaba %v (%v)", got, tc.retryable, err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if gotKey != chargeKey(7, 1) {
+				t.Fatalf("Idempotency-Key = %q", gotKey)
+			}
+		})
+	}
+}
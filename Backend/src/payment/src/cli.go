@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/mtls"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+)
+
+const cliUsage = `uso:
+  payment-service reconcile import -file liquidacion.csv [-from AAAA-MM-DD] [-to AAAA-MM-DD]
+  payment-service reconcile report -from AAAA-MM-DD [-to AAAA-MM-DD] [-all]
+  payment-service giftcard issue -amount CENTAVOS [-expires AAAA-MM-DD]
+  payment-service review list [-all]
+  payment-service review approve|reject -order N -reviewer NOMBRE [-note TEXTO]
+  payment-service token admin [-ttl 1h]
+
+Todos hablan por gRPC con el servicio (-addr o PAYMENT_GRPC_ADDR) con rol admin,
+firmando con GRPC_AUTH_SECRET. "token admin" imprime un token para grpcurl
+(-H "authorization: Bearer ...") contra los RPCs de gestión de cualquier servicio.
+`
+
+// runCLI atiende los subcomandos administrativos del binario y devuelve el
+// código de salida.
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 2 || (args[0] != "reconcile" && args[0] != "giftcard" && args[0] != "review" && args[0] != "token") {
+		fmt.Fprint(stderr, cliUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet(args[0]+" "+args[1], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", getEnv("PAYMENT_GRPC_ADDR", "localhost:50053"), "dirección gRPC de Payment")
+	from := fs.String("from", "", "fecha inicial (AAAA-MM-DD)")
+	to := fs.String("to", "", "fecha final (AAAA-MM-DD)")
+	file := fs.String("file", "", "archivo de liquidación (import)")
+	all := fs.Bool("all", false, "incluir diferencias o revisiones ya resueltas (report, list)")
+	amount := fs.Int64("amount", 0, "saldo de la tarjeta de regalo en centavos (issue)")
+	expires := fs.String("expires", "", "vencimiento de la tarjeta de regalo, AAAA-MM-DD (issue)")
+	order := fs.Int64("order", 0, "orden del pago en revisión (approve, reject)")
+	reviewer := fs.String("reviewer", "", "quién toma la decisión (approve, reject)")
+	note := fs.String("note", "", "motivo de la decisión (approve, reject)")
+	ttl := fs.Duration("ttl", time.Hour, "vigencia del token (token admin)")
+	if err := fs.Parse(args[2:]); err != nil {
+		return 2
+	}
+
+	authority, err := auth.NewFromEnv()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	// Con mTLS el CLI usa el certificado del servicio, que tiene acceso a la gestión
+	if _, err := mtls.Setup("payment", nil); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	admin := &auth.Principal{Service: "payment-cli", Roles: []string{auth.RoleAdmin}}
+	if args[0] == "token" {
+		if args[1] != "admin" {
+			fmt.Fprint(stderr, cliUsage)
+			return 2
+		}
+		token, err := authority.IssueFor(admin, *ttl)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		fmt.Fprintln(stdout, token)
+		return 0
+	}
+
+	cc, err := grpcclient.Dial(*addr, authority.DialOptions(admin)...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer cc.Close()
+	cli := paymentpb.NewPaymentClient(cc)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	switch args[0] + " " + args[1] {
+	case "reconcile import":
+		if *file == "" {
+			fmt.Fprint(stderr, cliUsage)
+			return 2
+		}
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		run, err := cli.ImportSettlement(ctx, &paymentpb.ImportSettlementRequest{
+			Source:     filepath.Base(*file),
+			Csv:        data,
+			PeriodFrom: *from,
+			PeriodTo:   *to,
+		})
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		if run.Duplicate {
+			fmt.Fprintf(stdout, "el archivo ya se había importado en la corrida #%d\n", run.RunId)
+		}
+		fmt.Fprintf(stdout, "corrida #%d %s (%s..%s): %d líneas, %d conciliadas, %d diferencias\n",
+			run.RunId, run.Source, run.PeriodFrom, run.PeriodTo, run.Lines, run.Matched, run.Discrepancies)
+		if run.Discrepancies > 0 {
+			fmt.Fprintf(stdout, "ver: payment-service reconcile report -from %s -to %s\n", run.PeriodFrom, run.PeriodTo)
+		}
+		return 0
+
+	case "reconcile report":
+		rep, err := cli.GetReconciliationReport(ctx, &paymentpb.ReconciliationReportRequest{
+			FromDate:        *from,
+			ToDate:          *to,
+			IncludeResolved: *all,
+		})
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		printReport(stdout, rep)
+		if rep.Missing+rep.Extra+rep.AmountMismatch+rep.RefundFailed > 0 {
+			return 3 // útil para alertar desde cron
+		}
+		return 0
+
+	case "giftcard issue":
+		req := &paymentpb.IssueGiftCardRequest{Amount: &commonpb.Money{Cents: *amount}}
+		if *expires != "" {
+			t, err := time.ParseInLocation("2006-01-02", *expires, time.Local)
+			if err != nil {
+				fmt.Fprintln(stderr, "-expires:", err)
+				return 2
+			}
+			req.ExpiresUnix = t.AddDate(0, 0, 1).Unix() // vale durante todo ese día
+		}
+		g, err := cli.IssueGiftCard(ctx, req)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "%s\t%d\n", g.Code, g.Amount.GetCents())
+		return 0
+
+	case "review list":
+		resp, err := cli.ListRiskReviews(ctx, &paymentpb.ListRiskReviewsRequest{IncludeResolved: *all, Limit: 200})
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		printReviews(stdout, resp.Reviews)
+		return 0
+
+	case "review approve", "review reject":
+		req := &paymentpb.ReviewDecisionRequest{OrderId: *order, Reviewer: *reviewer, Note: *note}
+		call := cli.ApproveReview
+		if args[1] == "reject" {
+			call = cli.RejectReview
+		}
+		a, err := call(ctx, req)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		printReviews(stdout, []*paymentpb.RiskAssessment{a})
+		return 0
+	}
+	fmt.Fprint(stderr, cliUsage)
+	return 2
+}
+
+func printReport(w io.Writer, rep *paymentpb.ReconciliationReport) {
+	for _, r := range rep.Runs {
+		fmt.Fprintf(w, "corrida #%d %s (%s..%s): %d líneas, %d conciliadas, %d diferencias\n",
+			r.RunId, r.Source, r.PeriodFrom, r.PeriodTo, r.Lines, r.Matched, r.Discrepancies)
+	}
+	fmt.Fprintf(w, "\nMISSING %d · EXTRA %d · AMOUNT_MISMATCH %d · REFUND_FAILED %d\n\n", rep.Missing, rep.Extra, rep.AmountMismatch, rep.RefundFailed)
+	if len(rep.Discrepancies) == 0 {
+		fmt.Fprintln(w, "sin diferencias")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FECHA\tTIPO\tREFERENCIA\tORDEN\tMOVIMIENTO\tREGISTRADO\tLIQUIDADO\tNOTA")
+	for _, d := range rep.Discrepancies {
+		kind := d.Kind.String()[len("DISCREPANCY_KIND_"):]
+		if d.Resolved {
+			kind += " (resuelto)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%d\t%d\t%s\n", d.Date, kind, d.ProviderRef, d.OrderId,
+			d.TransactionKind.String()[len("TRANSACTION_KIND_"):], d.Recorded.GetCents(), d.Settled.GetCents(), d.Note)
+	}
+	tw.Flush()
+}
+
+func printReviews(w io.Writer, reviews []*paymentpb.RiskAssessment) {
+	if len(reviews) == 0 {
+		fmt.Fprintln(w, "sin pagos en revisión")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ORDEN\tUSUARIO\tMONTO\tPUNTAJE\tESTADO\tFECHA\tMOTIVOS")
+	for _, r := range reviews {
+		state := r.ReviewState.String()[len("REVIEW_STATE_"):]
+		if r.Reviewer != "" {
+			state += " (" + r.Reviewer + ")"
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%d\t%s\t%s\t%s\n", r.OrderId, r.UserId, r.Amount.GetCents(), r.Score, state,
+			time.Unix(r.CreatedUnix, 0).Format("2006-01-02 15:04"), strings.Join(r.Reasons, "; "))
+	}
+	tw.Flush()
+}
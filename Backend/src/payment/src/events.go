@@ -3,16 +3,23 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
+
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 )
 
 // Mensajes que viajan por RabbitMQ (JSON).
 
 // Publicado por Order → consumido por Payment.
 type PaymentRequested struct {
-	OrderID     int64 `json:"order_id"`
-	UserID      int64 `json:"user_id"`
-	AmountCents int64 `json:"amount_cents"`
+	OrderID      int64  `json:"order_id"`
+	UserID       int64  `json:"user_id"`
+	AmountCents  int64  `json:"amount_cents"`
+	Method       string `json:"method,omitempty"` // nombre de common.PaymentMethod; vacío = PAYMENT_PROVIDER
+	InstrumentID int64  `json:"instrument_id,omitempty"`
+	WalletCents  int64  `json:"wallet_cents,omitempty"` // parte a pagar con el saldo a favor
 }
 
 // Publicado por Payment → consumido por Order.
@@ -27,38 +34,194 @@ type PaymentFailed struct {
 	ProviderRef string `json:"provider_ref"`
 }
 
+// payment.deferred: contra entrega aceptado; Order despacha y el cobro llega
+// como payment.succeeded cuando se entrega.
+type PaymentDeferred struct {
+	OrderID     int64  `json:"order_id"`
+	ProviderRef string `json:"provider_ref"`
+}
+
 func (s *service) handlePaymentRequested(ctx context.Context, body []byte) error {
 	var msg PaymentRequested
 	if err := json.Unmarshal(body, &msg); err != nil {
-		log.Printf("[payment] invalid message: %v", err)
+		slog.WarnContext(ctx, "mensaje inválido", "rk", "payment.charge.requested", "err", err)
 		return nil // NACK infinito no sirve; descartamos.
 	}
+	method, ok := commonpb.PaymentMethod_value[msg.Method]
+	if msg.Method != "" && !ok {
+		slog.WarnContext(ctx, "medio de pago desconocido", "order_id", msg.OrderID, "method", msg.Method)
+		s.publishJSON(ctx, "payment.failed", PaymentFailed{OrderID: msg.OrderID, Reason: "unsupported_method"})
+		metrics.PaymentDeclined("unsupported_method")
+		return nil
+	}
+	req := Payment{
+		OrderID:      msg.OrderID,
+		UserID:       msg.UserID,
+		AmountCents:  msg.AmountCents,
+		Method:       PaymentMethod(method),
+		InstrumentID: msg.InstrumentID,
+		WalletCents:  msg.WalletCents,
+		State:        PaymentStatePending,
+	}
+	if req.Method == MethodStoreCredit && req.WalletCents == 0 {
+		req.WalletCents = req.AmountCents
+	}
+	if req.WalletCents < 0 || req.WalletCents > req.AmountCents || (req.WalletCents > 0 && req.UserID == 0) {
+		slog.WarnContext(ctx, "saldo a favor inválido", "order_id", msg.OrderID, "wallet_cents", req.WalletCents, "amount_cents", req.AmountCents, "user_id", req.UserID)
+		s.publishJSON(ctx, "payment.failed", PaymentFailed{OrderID: msg.OrderID, Reason: "invalid_wallet_amount"})
+		metrics.PaymentDeclined("invalid_wallet_amount")
+		return nil
+	}
 
-	if err := s.repo.UpsertPending(ctx, Payment{
-		OrderID:     msg.OrderID,
-		AmountCents: msg.AmountCents,
-		State:       PaymentStatePending,
-	}); err != nil {
+	p, err := s.repo.GetByOrderID(ctx, msg.OrderID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case p == nil || p.State == PaymentStateFailed:
+		if err := s.repo.UpsertPending(ctx, req); err != nil {
+			return err
+		}
+	case p.State == PaymentStateAwaitingDelivery:
+		s.publishJSON(ctx, "payment.deferred", PaymentDeferred{OrderID: p.OrderID, ProviderRef: p.ProviderRef})
+		return nil
+	case p.State == PaymentStateUnderReview:
+		return nil // lo cobra ApproveReview
+	case p.State != PaymentStatePending:
+		// Ya cobrado (redelivery o petición repetida): se repite el evento sin cobrar otra vez
+		s.publishJSON(ctx, "payment.succeeded", PaymentSucceeded{OrderID: p.OrderID, ProviderRef: p.ProviderRef})
+		return nil
+	}
+	if ok, err := s.screen(ctx, &req); err != nil || !ok {
 		return err
 	}
+	return s.charge(ctx, &req)
+}
 
-	// Ejecutar cobro con la pasarela fake 
-	ok, providerRef, failReason := s.provider.Charge(ctx, msg.OrderID, msg.AmountCents)
+// Publicado por Order cuando la transportadora entrega la orden.
+type OrderDelivered struct {
+	OrderID int64 `json:"order_id"`
+}
+
+// handleOrderDelivered captura los pagos contra entrega; los demás ya estaban cobrados.
+func (s *service) handleOrderDelivered(ctx context.Context, body []byte) error {
+	var msg OrderDelivered
+	if err := json.Unmarshal(body, &msg); err != nil {
+		slog.WarnContext(ctx, "mensaje inválido", "rk", "order.delivered", "err", err)
+		return nil
+	}
+	p, err := s.repo.GetByOrderID(ctx, msg.OrderID)
+	if err != nil {
+		return err
+	}
+	if p == nil || p.State != PaymentStateAwaitingDelivery {
+		return nil
+	}
+	return s.settle(ctx, p.OrderID, ChargeResult{Status: ChargeApproved, ProviderRef: p.ProviderRef}, "delivery")
+}
 
-	if ok {
-		if err := s.repo.SetResult(ctx, msg.OrderID, PaymentStateSucceeded, providerRef); err != nil {
+// Publicado por Order cuando un paso de la saga no respondió a tiempo.
+type OrderExpired struct {
+	OrderID int64  `json:"order_id"`
+	Reason  string `json:"reason"`
+}
+
+// handleOrderExpired anula el pago de una orden vencida: si no se alcanzó a
+// cobrar falla (y devuelve el saldo a favor debitado); si ya se cobró, se
+// reembolsa completo. Order repite order.expired cuando el cobro llega tarde.
+//
+// Si la pasarela no responde el mensaje se reencola (el reintento usa la misma
+// clave); si rechaza el reembolso queda un REFUND_FAILED en el reporte de
+// conciliación para que lo devuelva una persona.
+func (s *service) handleOrderExpired(ctx context.Context, body []byte) error {
+	var msg OrderExpired
+	if err := json.Unmarshal(body, &msg); err != nil {
+		slog.WarnContext(ctx, "mensaje inválido", "rk", "order.expired", "err", err)
+		return nil
+	}
+	p, err := s.repo.GetByOrderID(ctx, msg.OrderID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return nil // el cobro nunca se pidió
+	}
+	switch p.State {
+	case PaymentStateUnderReview:
+		if _, err := s.repo.ResolveReview(ctx, p.OrderID, false, "system", msg.Reason); err != nil {
 			return err
 		}
-		ev := PaymentSucceeded{OrderID: msg.OrderID, ProviderRef: providerRef}
-		s.publishJSON("payment.succeeded", ev)
-		log.Printf("[payment] SUCCEEDED order=%d ref=%s", msg.OrderID, providerRef)
-	} else {
-		if err := s.repo.SetResult(ctx, msg.OrderID, PaymentStateFailed, providerRef); err != nil {
-			return err
+		fallthrough
+	case PaymentStatePending, PaymentStateAwaitingDelivery:
+		return s.settle(ctx, p.OrderID, ChargeResult{Status: ChargeDeclined, FailReason: "order_expired", ProviderRef: p.ProviderRef}, "expiry")
+	case PaymentStateSucceeded, PaymentStatePartiallyRefunded:
+		out, err := s.refund(ctx, p.OrderID, 0, "order_expired", fmt.Sprintf("expired:%d", p.OrderID), false)
+		if err != nil {
+			reason := refundFailReason(err)
+			if reason == "" {
+				return err
+			}
+			slog.WarnContext(ctx, "orden vencida y cobrada; el reembolso falló", "order_id", p.OrderID, "err", err)
+			captured, refunded, err := s.repo.Balance(ctx, p.OrderID)
+			if err != nil {
+				return err
+			}
+			return s.flagRefundFailed(ctx, p, p.ProviderRef, captured-refunded, "order_expired: "+reason)
 		}
-		ev := PaymentFailed{OrderID: msg.OrderID, Reason: failReason, ProviderRef: providerRef}
-		s.publishJSON("payment.failed", ev)
-		log.Printf("[payment] FAILED order=%d reason=%s ref=%s", msg.OrderID, failReason, providerRef)
+		slog.InfoContext(ctx, "REFUNDED por vencimiento", "order_id", p.OrderID, "amount_cents", out.amount(), "reason", msg.Reason)
+	}
+	return nil
+}
+
+// Publicado por Order al recibir una devolución → consumido por Payment.
+type RefundRequested struct {
+	OrderID     int64  `json:"order_id"`
+	ReturnID    int64  `json:"return_id"`
+	AmountCents int64  `json:"amount_cents"`
+	Reason      string `json:"reason"`
+}
+
+// payment.refunded / payment.refund_failed → consumido por Order (allá es
+// RefundResult; aquí ese nombre es la respuesta de la pasarela).
+type RefundResponse struct {
+	OrderID          int64  `json:"order_id"`
+	ReturnID         int64  `json:"return_id"`
+	AmountCents      int64  `json:"amount_cents"`
+	ProviderRef      string `json:"provider_ref"`
+	StoreCreditCents int64  `json:"store_credit_cents,omitempty"` // parte devuelta como saldo a favor
+	Reason           string `json:"reason,omitempty"`
+}
+
+func (s *service) handleRefundRequested(ctx context.Context, body []byte) error {
+	var msg RefundRequested
+	if err := json.Unmarshal(body, &msg); err != nil {
+		slog.WarnContext(ctx, "mensaje inválido", "rk", "payment.refund.requested", "err", err)
+		return nil
+	}
+
+	res := RefundResponse{OrderID: msg.OrderID, ReturnID: msg.ReturnID, AmountCents: msg.AmountCents}
+	if msg.AmountCents <= 0 {
+		res.Reason = "invalid_amount"
+		s.publishJSON(ctx, "payment.refund_failed", res)
+		return nil
+	}
+	// La clave por devolución hace que un redelivery repita el resultado sin reembolsar de nuevo
+	out, err := s.refund(ctx, msg.OrderID, msg.AmountCents, msg.Reason, fmt.Sprintf("return:%d", msg.ReturnID), false)
+	if err != nil {
+		if res.Reason = refundFailReason(err); res.Reason == "" {
+			return err // error de infraestructura: se reencola
+		}
+		s.publishJSON(ctx, "payment.refund_failed", res)
+		slog.WarnContext(ctx, "REFUND FAILED", "order_id", msg.OrderID, "return_id", msg.ReturnID, "amount_cents", msg.AmountCents, "err", err)
+		return nil
+	}
+	res.AmountCents = out.amount()
+	res.ProviderRef = out.providerRef()
+	if out.Credit != nil {
+		res.StoreCreditCents = out.Credit.AmountCents
 	}
+	s.publishJSON(ctx, "payment.refunded", res)
+	slog.InfoContext(ctx, "REFUNDED", "order_id", msg.OrderID, "return_id", msg.ReturnID,
+		"amount_cents", res.AmountCents, "store_credit_cents", res.StoreCreditCents, "ref", res.ProviderRef)
 	return nil
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Tarjetas guardadas. El número lo tokeniza la pasarela antes de llegar aquí:
+// Payment guarda el token (para cobrar) y la marca y los últimos 4 dígitos
+// (para mostrar). Un token con forma de número de tarjeta se rechaza.
+
+func (s *service) SaveInstrument(ctx context.Context, req *paymentpb.SaveInstrumentRequest) (*paymentpb.Instrument, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id requerido")
+	}
+	if err := auth.CheckUser(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	in := &Instrument{
+		UserID:   req.UserId,
+		Token:    strings.TrimSpace(req.Token),
+		Brand:    strings.ToLower(strings.TrimSpace(req.Brand)),
+		Last4:    strings.TrimSpace(req.Last4),
+		ExpMonth: req.ExpMonth,
+		ExpYear:  req.ExpYear,
+		Label:    strings.TrimSpace(req.Label),
+	}
+	switch {
+	case in.Token == "":
+		return nil, status.Error(codes.InvalidArgument, "token requerido")
+	case looksLikePAN(in.Token):
+		return nil, status.Error(codes.InvalidArgument, "token parece un número de tarjeta; envía el token de la pasarela")
+	case in.Brand == "":
+		return nil, status.Error(codes.InvalidArgument, "brand requerido")
+	case len(in.Last4) != 4 || strings.Trim(in.Last4, "0123456789") != "":
+		return nil, status.Error(codes.InvalidArgument, "last4 debe tener 4 dígitos")
+	case len(in.Label) > 40:
+		return nil, status.Error(codes.InvalidArgument, "label admite hasta 40 caracteres")
+	}
+	if in.ExpMonth != 0 || in.ExpYear != 0 {
+		if in.ExpMonth < 1 || in.ExpMonth > 12 || in.ExpYear < 2000 {
+			return nil, status.Error(codes.InvalidArgument, "vencimiento inválido (exp_month 1-12, exp_year AAAA)")
+		}
+		now := time.Now()
+		if in.ExpYear < int32(now.Year()) || (in.ExpYear == int32(now.Year()) && in.ExpMonth < int32(now.Month())) {
+			return nil, status.Error(codes.InvalidArgument, "la tarjeta está vencida")
+		}
+	}
+
+	saved, err := s.repo.SaveInstrument(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return toPBInstrument(saved), nil
+}
+
+func (s *service) ListInstruments(ctx context.Context, req *commonpb.UserRef) (*paymentpb.ListInstrumentsResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id requerido")
+	}
+	if err := auth.CheckUser(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	ins, err := s.repo.ListInstruments(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &paymentpb.ListInstrumentsResponse{Instruments: make([]*paymentpb.Instrument, 0, len(ins))}
+	for i := range ins {
+		resp.Instruments = append(resp.Instruments, toPBInstrument(&ins[i]))
+	}
+	return resp, nil
+}
+
+func (s *service) DeleteInstrument(ctx context.Context, req *paymentpb.InstrumentRef) (*commonpb.Ack, error) {
+	if req.UserId == 0 || req.InstrumentId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id e instrument_id requeridos")
+	}
+	if err := auth.CheckUser(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	in, err := s.repo.GetInstrument(ctx, req.InstrumentId)
+	if err != nil {
+		return nil, err
+	}
+	if in == nil || in.UserID != req.UserId {
+		return nil, status.Error(codes.NotFound, "tarjeta no encontrada")
+	}
+	// Borrar dos veces no es error: el reintento del cliente ve el mismo resultado
+	if _, err := s.repo.DeleteInstrument(ctx, req.UserId, req.InstrumentId); err != nil {
+		return nil, err
+	}
+	return &commonpb.Ack{Ok: true}, nil
+}
+
+// looksLikePAN detecta un número de tarjeta (13 a 19 dígitos, con espacios o
+// guiones) enviado por error en lugar del token.
+func looksLikePAN(token string) bool {
+	digits := 0
+	for _, r := range token {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case r == ' ' || r == '-':
+		default:
+			return false
+		}
+	}
+	return digits >= 13 && digits <= 19
+}
+
+func toPBInstrument(in *Instrument) *paymentpb.Instrument {
+	return &paymentpb.Instrument{
+		InstrumentId: in.ID,
+		UserId:       in.UserID,
+		Brand:        in.Brand,
+		Last4:        in.Last4,
+		ExpMonth:     in.ExpMonth,
+		ExpYear:      in.ExpYear,
+		Label:        in.Label,
+		CreatedUnix:  in.CreatedAt.Unix(),
+	}
+}
@@ -2,13 +2,34 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"time"
 
 	"google.golang.org/grpc"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/mtls"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
 func main() {
+	// Subcomandos administrativos (ver cli.go)
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	logging.Setup("payment")
+	flushTraces := must(tracing.Setup("payment"))
+	defer flushTraces(context.Background())
+	// /metrics en PAYMENT_METRICS_ADDR
+	must(struct{}{}, metrics.Setup("payment"))
 	cfg := loadConfig()
 	ctx := context.Background()
 
@@ -20,23 +41,58 @@ func main() {
 	defer br.close()
 
 	svc := &service{
-		cfg:      cfg,
-		repo:     repo,
-		provider: newFakeProvider(),
-		br:       br,
+		cfg:       cfg,
+		repo:      repo,
+		providers: must(newProviders(cfg)),
+		risk:      must(newRiskEvaluator(cfg, repo)),
+		br:        br,
 	}
 
 	// Worker de pagos (consume peticiones de cobro)
 	must(struct{}{}, br.consumePaymentRequested(ctx, svc.handlePaymentRequested, cfg.ConsumerTag, cfg.PrefetchCount))
+	// Worker de reembolsos (devoluciones aprobadas y recibidas en bodega)
+	must(struct{}{}, br.consumeRefundRequested(ctx, svc.handleRefundRequested, cfg.ConsumerTag, cfg.PrefetchCount))
+	// Entregas: captura de los pagos contra entrega
+	must(struct{}{}, br.consumeOrderDelivered(ctx, svc.handleOrderDelivered, cfg.ConsumerTag, cfg.PrefetchCount))
+	// Órdenes vencidas: se anula o reembolsa el cobro
+	must(struct{}{}, br.consumeOrderExpired(ctx, svc.handleOrderExpired, cfg.ConsumerTag, cfg.PrefetchCount))
+	metrics.QueueDepth(br.queueDepth, cfg.RequestQueue, cfg.RefundQueue, cfg.DeliveryQueue, cfg.ExpiryQueue)
+
+	// Webhooks de la pasarela y reconciliador de pagos PENDING
+	if cfg.WebhookSecret == "" {
+		slog.Warn("PAYMENT_WEBHOOK_SECRET vacío: los webhooks se rechazan y solo confirma el reconciliador")
+	}
+	httpServer := &http.Server{Addr: cfg.HTTPAddr, Handler: svc.httpRoutes(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logging.Fatal("http", err)
+		}
+	}()
+	go svc.runReconciler(ctx)
+	if cfg.SettlementDir != "" {
+		go svc.runSettlementImporter(ctx, cfg.SettlementDir, cfg.SettlementScan)
+		slog.Info("archivos de liquidación", "dir", cfg.SettlementDir, "every", cfg.SettlementScan.String())
+	}
 
 	// gRPC server
 	lis := must(net.Listen("tcp", ":"+cfg.ServicePort))
-	grpcServer := grpc.NewServer()
+	authority := must(auth.NewFromEnv())
+	tlsOpts := must(mtls.Setup("payment", paymentPeers()))
+	opts := grpcclient.ServerOptions()
+	opts = append(opts, tracing.ServerOptions()...)
+	opts = append(opts, metrics.ServerOptions()...)
+	opts = append(opts, logging.ServerOptions()...)
+	opts = append(opts, tlsOpts...)
+	grpcServer := grpc.NewServer(append(opts, authority.ServerOptions(adminRules)...)...)
 	svc.register(grpcServer)
 
-	log.Printf("[payment] gRPC listening on :%s", cfg.ServicePort)
-	log.Printf("[payment] DB at %s", cfg.DBPath)
-	log.Printf("[payment] consuming queue: %s (exchange=%s)", cfg.RequestQueue, cfg.ExchangeName)
+	slog.Info("gRPC escuchando", "port", cfg.ServicePort, "db", cfg.DBPath)
+	slog.Info("pasarelas", "provider", cfg.Provider, "method_providers", fmt.Sprint(cfg.MethodProviders),
+		"risk_engine", cfg.RiskEngine, "risk_rules", cfg.RiskRulesPath)
+	slog.Info("webhooks y reconciliador", "http", cfg.HTTPAddr, "reconcile_every", cfg.ReconcileInterval.String(),
+		"pending_timeout", cfg.PendingTimeout.String(), "pending_expiry", cfg.PendingExpiry.String())
+	slog.Info("consumiendo colas", "queues", []string{cfg.RequestQueue, cfg.RefundQueue, cfg.DeliveryQueue, cfg.ExpiryQueue},
+		"exchange", cfg.ExchangeName)
 
 	must(struct{}{}, grpcServer.Serve(lis))
 }
@@ -9,6 +9,10 @@ const (
 	PaymentStatePending
 	PaymentStateSucceeded
 	PaymentStateFailed
+	PaymentStateRefunded
+	PaymentStatePartiallyRefunded
+	PaymentStateAwaitingDelivery // contra entrega: se cobra al entregar
+	PaymentStateUnderReview      // retenido por riesgo hasta la revisión manual
 )
 
 func (s PaymentState) String() string {
@@ -19,15 +23,242 @@ func (s PaymentState) String() string {
 		return "SUCCEEDED"
 	case PaymentStateFailed:
 		return "FAILED"
+	case PaymentStateRefunded:
+		return "REFUNDED"
+	case PaymentStatePartiallyRefunded:
+		return "PARTIALLY_REFUNDED"
+	case PaymentStateAwaitingDelivery:
+		return "AWAITING_DELIVERY"
+	case PaymentStateUnderReview:
+		return "UNDER_REVIEW"
 	default:
 		return "UNSPECIFIED"
 	}
 }
 
-type Payment struct {
+// Tipos de movimiento con la firma de TransactionKind en payment.proto
+type TransactionKind int
+
+const (
+	TxCharge        TransactionKind = 1
+	TxRefund        TransactionKind = 2 // devuelve todo el saldo restante
+	TxPartialRefund TransactionKind = 3
+)
+
+// Transaction es un movimiento de dinero de un pago. IdemKey evita registrar
+// dos veces la misma operación ("charge:<order>", "return:<id>" o la clave del cliente).
+type Transaction struct {
+	ID          int64
+	OrderID     int64
+	Kind        TransactionKind
+	AmountCents int64
+	ProviderRef string
+	Reason      string
+	IdemKey     string
+	StoreCredit bool // cobrado del monedero o devuelto como saldo a favor
+	CreatedAt   time.Time
+}
+
+type AttemptState int
+
+const (
+	AttemptOpen     AttemptState = 1 // enviado (o por enviar) sin respuesta de la pasarela
+	AttemptPending  AttemptState = 2 // la pasarela lo aceptó y resolverá después
+	AttemptApproved AttemptState = 3
+	AttemptDeclined AttemptState = 4
+)
+
+// ChargeAttempt es un intento de cobro de una orden. Todos los reintentos del
+// mismo intento (redeliveries, reconciliador) reutilizan IdemKey; un intento
+// nuevo solo se abre cuando el anterior terminó rechazado.
+type ChargeAttempt struct {
+	ID          int64
 	OrderID     int64
+	Attempt     int
+	IdemKey     string
 	AmountCents int64
-	State       PaymentState
+	State       AttemptState
 	ProviderRef string
+	FailReason  string
+	Retries     int // errores reintentables de la pasarela en este intento
+	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
+
+// Medios de pago con la firma de PaymentMethod en common.proto
+type PaymentMethod int
+
+const (
+	MethodUnspecified    PaymentMethod = 0 // pagos anteriores a los medios de pago: PAYMENT_PROVIDER
+	MethodPSE            PaymentMethod = 1
+	MethodCard           PaymentMethod = 2
+	MethodCashOnDelivery PaymentMethod = 3
+	MethodStoreCredit    PaymentMethod = 4
+)
+
+// methodNames son los nombres de PAYMENT_METHOD_PROVIDERS.
+var methodNames = map[string]PaymentMethod{
+	"pse":          MethodPSE,
+	"card":         MethodCard,
+	"cod":          MethodCashOnDelivery,
+	"store_credit": MethodStoreCredit,
+}
+
+type Payment struct {
+	OrderID      int64
+	UserID       int64
+	AmountCents  int64
+	Method       PaymentMethod
+	InstrumentID int64 // tarjeta guardada (MethodCard)
+	WalletCents  int64 // parte cobrada del monedero; el resto va por la pasarela
+	State        PaymentState
+	ProviderRef  string
+	RedirectURL  string // página del banco mientras está PENDING
+	UpdatedAt    time.Time
+}
+
+// Instrument es una tarjeta tokenizada de un usuario. Token lo emite el
+// tokenizador de la pasarela; el número completo nunca pasa por aquí.
+type Instrument struct {
+	ID        int64
+	UserID    int64
+	Token     string
+	Brand     string
+	Last4     string
+	ExpMonth  int32
+	ExpYear   int32
+	Label     string
+	Deleted   bool
+	CreatedAt time.Time
+}
+
+// Monedero: saldo a favor por usuario con un libro de movimientos.
+
+// Tipos de movimiento con la firma de WalletTransactionKind en payment.proto
+type WalletKind int
+
+const (
+	WalletGiftCard WalletKind = 1 // crédito: tarjeta de regalo redimida
+	WalletRefund   WalletKind = 2 // crédito: reembolso como saldo a favor
+	WalletPayment  WalletKind = 3 // débito: pago de una orden
+	WalletRelease  WalletKind = 4 // crédito: el pago que usó el saldo falló
+)
+
+// WalletEntry es un movimiento del monedero. AmountCents es positivo para
+// créditos y negativo para débitos; IdemKey evita aplicarlo dos veces.
+type WalletEntry struct {
+	ID           int64
+	UserID       int64
+	Kind         WalletKind
+	AmountCents  int64
+	BalanceAfter int64
+	OrderID      int64
+	Reference    string
+	IdemKey      string
+	CreatedAt    time.Time
+}
+
+type GiftCard struct {
+	Code         string
+	InitialCents int64
+	BalanceCents int64
+	RedeemedBy   int64
+	ExpiresAt    time.Time // cero = no vence
+	CreatedAt    time.Time
+}
+
+// Análisis de riesgo antes del cobro (ver risk.go).
+
+// Decisiones con la firma de RiskDecision en payment.proto
+type RiskDecision int
+
+const (
+	RiskApprove RiskDecision = 1
+	RiskReview  RiskDecision = 2
+	RiskDecline RiskDecision = 3
+)
+
+func (d RiskDecision) String() string {
+	switch d {
+	case RiskApprove:
+		return "approve"
+	case RiskReview:
+		return "review"
+	case RiskDecline:
+		return "decline"
+	default:
+		return "unspecified"
+	}
+}
+
+// Estados de la revisión manual con la firma de ReviewState en payment.proto
+type ReviewState int
+
+const (
+	ReviewNone     ReviewState = 0 // la decisión no pedía revisión
+	ReviewPending  ReviewState = 1
+	ReviewApproved ReviewState = 2
+	ReviewRejected ReviewState = 3
+)
+
+// RiskAssessment es la evaluación de un pago: el puntaje, las reglas que lo
+// sumaron y, si quedó en revisión, quién la resolvió.
+type RiskAssessment struct {
+	ID          int64
+	OrderID     int64
+	UserID      int64
+	AmountCents int64
+	Score       int
+	Decision    RiskDecision
+	Reasons     []string
+	ReviewState ReviewState
+	Reviewer    string
+	ReviewNote  string
+	CreatedAt   time.Time
+	ReviewedAt  time.Time // cero mientras no se revise
+}
+
+// Conciliación contra la liquidación de la pasarela (ver settlement.go).
+
+type DiscrepancyKind int
+
+const (
+	DiscrepancyMissing        DiscrepancyKind = 1 // registrado aquí, no liquidado
+	DiscrepancyExtra          DiscrepancyKind = 2 // liquidado, sin registro aquí
+	DiscrepancyAmountMismatch DiscrepancyKind = 3
+	DiscrepancyRefundFailed   DiscrepancyKind = 4 // cobrado y sin poder devolverse (ver flagRefundFailed)
+)
+
+// SettlementLine es una fila del archivo de liquidación.
+type SettlementLine struct {
+	Date        string // YYYY-MM-DD
+	ProviderRef string
+	Refund      bool
+	AmountCents int64
+}
+
+type SettlementRun struct {
+	ID            int64
+	Source        string
+	Checksum      string
+	PeriodFrom    string
+	PeriodTo      string
+	Lines         int
+	Matched       int
+	Discrepancies int
+	CreatedAt     time.Time
+}
+
+type Discrepancy struct {
+	ID            int64
+	RunID         int64
+	Kind          DiscrepancyKind
+	Date          string
+	ProviderRef   string
+	OrderID       int64
+	TxKind        TransactionKind
+	RecordedCents int64
+	SettledCents  int64
+	Note          string
+	Resolved      bool
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PaymentProvider es la pasarela que ejecuta cobros y reembolsos.
+//
+// Charge y Refund devuelven error solo si no hubo respuesta válida de la
+// pasarela (un rechazo del banco no es error). En ese caso no se sabe si el
+// cobro o el reembolso se hizo: repetirlo con la misma IdempotencyKey nunca lo
+// hace dos veces.
+type PaymentProvider interface {
+	Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error)
+	Refund(ctx context.Context, req RefundRequest) (RefundResult, error)
+}
+
+type ChargeRequest struct {
+	OrderID         int64
+	UserID          int64
+	AmountCents     int64
+	IdempotencyKey  string // ver chargeKey
+	Method          PaymentMethod
+	InstrumentToken string // token de la tarjeta guardada (MethodCard)
+}
+
+type RefundRequest struct {
+	OrderID        int64
+	ChargeRef      string // ProviderRef del cobro que se reembolsa
+	AmountCents    int64
+	Reason         string
+	IdempotencyKey string // ver refundKey
+}
+
+// RefundResult es la respuesta de la pasarela a un reembolso; FailReason
+// explica el rechazo cuando Approved es false.
+type RefundResult struct {
+	Approved    bool
+	ProviderRef string
+	FailReason  string
+}
+
+// ProviderError es una falla de comunicación con la pasarela. Retryable indica
+// que el resultado es incierto (timeout, 5xx, conexión) y se puede reintentar
+// con la misma clave; si es false la pasarela rechazó la petición en sí (4xx)
+// y reintentarla no cambia nada.
+type ProviderError struct {
+	Retryable bool
+	Err       error
+}
+
+func (e *ProviderError) Error() string {
+	if e.Retryable {
+		return "provider error (retryable): " + e.Err.Error()
+	}
+	return "provider error: " + e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error { return e.Err }
+
+// isRetryable clasifica el error de Charge o Refund. Ante la duda se
+// reintenta: con la clave de idempotencia repetir es seguro y rendirse puede
+// dejar un cobro o un reembolso hecho sin registrar.
+func isRetryable(err error) bool {
+	var pe *ProviderError
+	if errors.As(err, &pe) {
+		return pe.Retryable
+	}
+	return true
+}
+
+// ChargeQuerier lo implementan las pasarelas asíncronas; el reconciliador lo usa
+// para preguntar por los cobros que siguen PENDING.
+type ChargeQuerier interface {
+	QueryCharge(ctx context.Context, orderID int64, providerRef string) (ChargeResult, error)
+}
+
+type ChargeStatus int
+
+const (
+	ChargePending ChargeStatus = iota // el banco responde después (webhook o consulta)
+	ChargeApproved
+	ChargeDeclined
+	ChargeDeferred // contra entrega: se captura cuando se entrega la orden
+)
+
+// ChargeResult es la respuesta de la pasarela a un cobro. RedirectURL es la
+// página del banco donde el cliente autoriza un cobro pendiente.
+type ChargeResult struct {
+	Status      ChargeStatus
+	ProviderRef string
+	FailReason  string
+	RedirectURL string
+}
+
+// ProviderFactory crea una pasarela a partir de la configuración.
+type ProviderFactory func(cfg Config) (PaymentProvider, error)
+
+// providers se llena desde el init() de cada implementación.
+var providers = map[string]ProviderFactory{}
+
+func registerProvider(name string, f ProviderFactory) {
+	if _, dup := providers[name]; dup {
+		panic("payment provider registrado dos veces: " + name)
+	}
+	providers[name] = f
+}
+
+// newProvider crea la pasarela registrada con ese nombre.
+func newProvider(cfg Config, name string) (PaymentProvider, error) {
+	f, ok := providers[name]
+	if !ok {
+		names := make([]string, 0, len(providers))
+		for n := range providers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("payment provider %q desconocido (disponibles: %s)", name, strings.Join(names, ", "))
+	}
+	return f(cfg)
+}
+
+// ErrMethodUnavailable indica que ninguna pasarela atiende el medio de pago.
+var ErrMethodUnavailable = errors.New("payment method unavailable")
+
+// providerRoutes asigna la pasarela de cada medio de pago.
+type providerRoutes map[PaymentMethod]PaymentProvider
+
+func (r providerRoutes) forMethod(m PaymentMethod) (PaymentProvider, error) {
+	if p, ok := r[m]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrMethodUnavailable, m)
+}
+
+// newProviders arma las rutas por medio de pago: PSE, tarjeta y los pagos sin
+// medio usan PAYMENT_PROVIDER y contra entrega usa "cod";
+// PAYMENT_METHOD_PROVIDERS cambia cualquiera. Store credit no usa pasarela: lo
+// cobra el monedero (ver charge). Cada pasarela se crea una sola vez aunque
+// atienda varios medios.
+func newProviders(cfg Config) (providerRoutes, error) {
+	names := map[PaymentMethod]string{
+		MethodUnspecified:    cfg.Provider,
+		MethodPSE:            cfg.Provider,
+		MethodCard:           cfg.Provider,
+		MethodCashOnDelivery: "cod",
+	}
+	for m, name := range cfg.MethodProviders {
+		names[m] = name
+	}
+	built := map[string]PaymentProvider{}
+	routes := providerRoutes{}
+	for m, name := range names {
+		if name == "" {
+			continue // medio deshabilitado
+		}
+		p, ok := built[name]
+		if !ok {
+			var err error
+			if p, err = newProvider(cfg, name); err != nil {
+				return nil, err
+			}
+			built[name] = p
+		}
+		routes[m] = p
+	}
+	return routes, nil
+}
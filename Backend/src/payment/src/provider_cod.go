@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+func init() {
+	registerProvider("cod", func(Config) (PaymentProvider, error) { return codProvider{}, nil })
+}
+
+// codProvider atiende el pago contra entrega: en el checkout no se cobra nada y
+// el pago queda AWAITING_DELIVERY hasta que Order publica order.delivered.
+type codProvider struct{}
+
+func (codProvider) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	return ChargeResult{Status: ChargeDeferred, ProviderRef: fmt.Sprintf("COD-%d", req.OrderID)}, nil
+}
+
+// refund devuelve los pagos contra entrega como saldo a favor; si el pago no
+// tiene usuario llega aquí y se rechaza para que quede como REFUND_FAILED y lo
+// devuelva una persona.
+func (codProvider) Refund(ctx context.Context, req RefundRequest) (RefundResult, error) {
+	return RefundResult{FailReason: "cod_manual_refund"}, nil
+}
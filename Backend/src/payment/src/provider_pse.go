@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	registerProvider("pse", func(cfg Config) (PaymentProvider, error) { return newPSEProvider(cfg) })
+}
+
+// pseProvider habla con una pasarela estilo PSE (o con el simulador psesim):
+// se crea la transacción, el cliente aprueba en la página del banco
+// (redirect_url) y el resultado llega de forma asíncrona.
+type pseProvider struct {
+	baseURL   string
+	returnURL string // admite {order_id}
+	http      *http.Client
+}
+
+// Contrato HTTP de la pasarela.
+type pseCreateRequest struct {
+	Reference      string `json:"reference"`
+	AmountCents    int64  `json:"amount_cents"`
+	Description    string `json:"description"`
+	ReturnURL      string `json:"return_url,omitempty"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+type pseTransaction struct {
+	ID            string `json:"id"`
+	Reference     string `json:"reference"`
+	AmountCents   int64  `json:"amount_cents"`
+	Status        string `json:"status"` // PENDING, APPROVED, REJECTED
+	Reason        string `json:"reason,omitempty"`
+	RedirectURL   string `json:"redirect_url,omitempty"`
+	RefundedCents int64  `json:"refunded_cents"`
+}
+
+type pseRefundRequest struct {
+	TransactionID  string `json:"transaction_id"`
+	Reference      string `json:"reference"`
+	AmountCents    int64  `json:"amount_cents"`
+	Reason         string `json:"reason,omitempty"`
+	IdempotencyKey string `json:"idempotency_key"`
+}
+
+type pseRefund struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // APPROVED, REJECTED
+	Reason string `json:"reason,omitempty"`
+}
+
+func newPSEProvider(cfg Config) (*pseProvider, error) {
+	if _, err := url.ParseRequestURI(cfg.PSEBaseURL); err != nil {
+		return nil, fmt.Errorf("PSE_BASE_URL inválida: %w", err)
+	}
+	return &pseProvider{
+		baseURL:   strings.TrimRight(cfg.PSEBaseURL, "/"),
+		returnURL: cfg.PSEReturnURL,
+		http:      &http.Client{Timeout: cfg.PSEHTTPTimeout},
+	}, nil
+}
+
+// pseReference identifica la orden ante el banco. Una orden puede tener varios
+// intentos con la misma referencia, así que los reembolsos nombran la
+// transacción capturada y mandan la referencia solo como control.
+func pseReference(orderID int64) string { return "order-" + strconv.FormatInt(orderID, 10) }
+
+// Charge crea la transacción y vuelve enseguida: el resultado llega después por
+// webhook (ver webhook.go) o lo consulta el reconciliador con QueryCharge.
+// La pasarela devuelve la misma transacción si recibe otra vez la misma
+// idempotency_key, así un reintento tras un timeout no crea un segundo cobro.
+func (p *pseProvider) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	var tx pseTransaction
+	err := p.do(ctx, http.MethodPost, "/api/transactions", pseCreateRequest{
+		Reference:      pseReference(req.OrderID),
+		AmountCents:    req.AmountCents,
+		Description:    fmt.Sprintf("MyBookStore orden #%d", req.OrderID),
+		ReturnURL:      strings.ReplaceAll(p.returnURL, "{order_id}", strconv.FormatInt(req.OrderID, 10)),
+		IdempotencyKey: req.IdempotencyKey,
+	}, &tx)
+	if err != nil {
+		return ChargeResult{}, err
+	}
+	slog.InfoContext(ctx, "PSE", "order_id", req.OrderID, "key", req.IdempotencyKey, "tx", tx.ID, "status", tx.Status, "redirect", tx.RedirectURL)
+	return tx.result(), nil
+}
+
+func (p *pseProvider) QueryCharge(ctx context.Context, orderID int64, providerRef string) (ChargeResult, error) {
+	var tx pseTransaction
+	if err := p.do(ctx, http.MethodGet, "/api/transactions/"+url.PathEscape(providerRef), nil, &tx); err != nil {
+		return ChargeResult{}, err
+	}
+	if tx.Reference != pseReference(orderID) {
+		return ChargeResult{}, fmt.Errorf("tx %s pertenece a %q, no a la orden %d", providerRef, tx.Reference, orderID)
+	}
+	return tx.result(), nil
+}
+
+func (tx pseTransaction) result() ChargeResult {
+	res := ChargeResult{ProviderRef: tx.ID, RedirectURL: tx.RedirectURL}
+	switch tx.Status {
+	case "APPROVED":
+		res.Status = ChargeApproved
+	case "REJECTED":
+		res.Status, res.FailReason = ChargeDeclined, tx.Reason
+		if res.FailReason == "" {
+			res.FailReason = "rejected_by_bank"
+		}
+	default:
+		res.Status = ChargePending
+	}
+	return res
+}
+
+// parsePSEReference es la inversa de pseReference.
+func parsePSEReference(ref string) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(ref, "order-"), 10, 64)
+	return id, err == nil && strings.HasPrefix(ref, "order-") && id > 0
+}
+
+// Refund reembolsa la transacción capturada. Igual que en Charge, la pasarela
+// devuelve el mismo reembolso aprobado si recibe otra vez la misma
+// idempotency_key, así un reintento tras un timeout no reembolsa dos veces.
+func (p *pseProvider) Refund(ctx context.Context, req RefundRequest) (RefundResult, error) {
+	if req.ChargeRef == "" {
+		return RefundResult{FailReason: "missing_charge_ref"}, nil
+	}
+	var rf pseRefund
+	err := p.do(ctx, http.MethodPost, "/api/refunds", pseRefundRequest{
+		TransactionID:  req.ChargeRef,
+		Reference:      pseReference(req.OrderID),
+		AmountCents:    req.AmountCents,
+		Reason:         req.Reason,
+		IdempotencyKey: req.IdempotencyKey,
+	}, &rf)
+	if err != nil {
+		return RefundResult{}, err
+	}
+	slog.InfoContext(ctx, "PSE reembolso", "order_id", req.OrderID, "key", req.IdempotencyKey, "refund", rf.ID, "status", rf.Status)
+	if rf.Status != "APPROVED" {
+		reason := rf.Reason
+		if reason == "" {
+			reason = "rejected_by_bank"
+		}
+		return RefundResult{ProviderRef: rf.ID, FailReason: reason}, nil
+	}
+	return RefundResult{Approved: true, ProviderRef: rf.ID}, nil
+}
+
+// do hace la llamada HTTP y devuelve los fallos como *ProviderError: sin
+// respuesta, timeouts, 408, 429 y 5xx son reintentables; el resto de 4xx no.
+func (p *pseProvider) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	var key string
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return &ProviderError{Err: err}
+		}
+		body = bytes.NewReader(b)
+		switch c := in.(type) {
+		case pseCreateRequest:
+			key = c.IdempotencyKey
+		case pseRefundRequest:
+			key = c.IdempotencyKey
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, body)
+	if err != nil {
+		return &ProviderError{Err: err}
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return &ProviderError{Retryable: true, Err: err}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &ProviderError{
+			Retryable: resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests,
+			Err:       fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg))),
+		}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		// La respuesta se cortó: no se sabe qué hizo la pasarela
+		return &ProviderError{Retryable: true, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestPSERefundNamesCapturedTransaction: la orden puede tener varios intentos
+// con la misma referencia, así que el reembolso tiene que nombrar el capturado.
+func TestPSERefundNamesCapturedTransaction(t *testing.T) {
+	var got pseRefundRequest
+	var gotKey string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/refunds" {
+			http.NotFound(w, r)
+			return
+		}
+		gotKey = r.Header.Get("Idempotency-Key")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(pseRefund{ID: "PSE-RFD-000001", Status: "APPROVED"})
+	}))
+	defer srv.Close()
+
+	p, err := newPSEProvider(Config{PSEBaseURL: srv.URL, PSEHTTPTimeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := RefundRequest{OrderID: 7, ChargeRef: "PSE-000002", AmountCents: 900, Reason: "devolución", IdempotencyKey: "return:3"}
+	res, err := p.Refund(context.Background(), req)
+	if err != nil || !res.Approved || res.ProviderRef != "PSE-RFD-000001" {
+		t.Fatalf("reembolso = %+v (%v)", res, err)
+	}
+	if got.TransactionID != "PSE-000002" || got.Reference != pseReference(7) || got.AmountCents != 900 {
+		t.Fatalf("petición = %+v", got)
+	}
+	if gotKey != "return:3" || got.IdempotencyKey != "return:3" {
+		t.Fatalf("Idempotency-Key = %q / %q", gotKey, got.IdempotencyKey)
+	}
+
+	// Sin cobro capturado no hay a qué reembolsar
+	req.ChargeRef = ""
+	if res, err := p.Refund(context.Background(), req); err != nil || res.Approved || res.FailReason != "missing_charge_ref" {
+		t.Fatalf("sin referencia: %+v (%v)", res, err)
+	}
+}
+
+// TestPSERefundErrors: sin respuesta el reembolso es incierto y se reintenta;
+// un 4xx es definitivo. Ninguno de los dos es un rechazo del banco.
+func TestPSERefundErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		handler   http.HandlerFunc
+		retryable bool
+	}{
+		{"503", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "down", http.StatusServiceUnavailable) }, true},
+		{"422", func(w http.ResponseWriter, r *http.Request) { http.Error(w, "reused", http.StatusUnprocessableEntity) }, false},
+		{"timeout", func(w http.ResponseWriter, r *http.Request) { time.Sleep(200 * time.Millisecond) }, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.handler)
+			defer srv.Close()
+			p, err := newPSEProvider(Config{PSEBaseURL: srv.URL, PSEHTTPTimeout: 50 * time.Millisecond})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = p.Refund(context.Background(), RefundRequest{OrderID: 7, ChargeRef: "PSE-000001", AmountCents: 100, IdempotencyKey: "return:1"})
+			if err == nil {
+				t.Fatal("se esperaba error")
+			}
+			if got := isRetryable(err); got != tc.retryable {
+				t.Fatalf("isRetryable = %v, se esperaba %v (%v)", got, tc.retryable, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+func init() {
+	registerProvider("scripted", func(Config) (PaymentProvider, error) { return &scriptedProvider{}, nil })
+}
+
+// scriptedProvider decide solo por el monto, sin azar ni red, para pruebas
+// reproducibles. Según los centavos del monto (amount % 100):
+//
+//	01 → insufficient_funds
+//	02 → card_declined
+//	03 → bank_unavailable
+//	cualquier otro → aprobado
+//
+// Los reembolsos cuyo monto termina en 99 se rechazan con refund_rejected.
+type scriptedProvider struct {
+	refunds atomic.Int64
+}
+
+var scriptedDeclines = map[int64]string{
+	1: "insufficient_funds",
+	2: "card_declined",
+	3: "bank_unavailable",
+}
+
+func (p *scriptedProvider) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	ref := "SCRIPTED-" + req.IdempotencyKey
+	if reason, ok := scriptedDeclines[req.AmountCents%100]; ok {
+		return ChargeResult{Status: ChargeDeclined, ProviderRef: ref, FailReason: reason}, nil
+	}
+	return ChargeResult{Status: ChargeApproved, ProviderRef: ref}, nil
+}
+
+func (p *scriptedProvider) Refund(ctx context.Context, req RefundRequest) (RefundResult, error) {
+	res := RefundResult{ProviderRef: fmt.Sprintf("SCRIPTED-RFD-%d-%d", req.OrderID, p.refunds.Add(1))}
+	switch {
+	case req.AmountCents <= 0:
+		res.FailReason = "invalid_amount"
+	case req.AmountCents%100 == 99:
+		res.FailReason = "refund_rejected"
+	default:
+		res.Approved = true
+	}
+	return res, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewProvider(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{"fake", Config{}, ""},
+		{"scripted", Config{}, ""},
+		{"cod", Config{}, ""},
+		{"pse", Config{PSEBaseURL: "http://psesim:8099"}, ""},
+		{"pse", Config{}, "PSE_BASE_URL"},
+		{"wallet", Config{}, `"wallet" desconocido (disponibles: cod, fake, pse, scripted)`},
+		{"", Config{}, "desconocido"},
+	}
+	for _, tc := range cases {
+		p, err := newProvider(tc.cfg, tc.name)
+		if tc.wantErr == "" {
+			if err != nil || p == nil {
+				t.Errorf("newProvider(%q) = %v, %v", tc.name, p, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("newProvider(%q) error = %v, se esperaba %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+// TestNewProviders: qué pasarela atiende cada medio según PAYMENT_PROVIDER y
+// PAYMENT_METHOD_PROVIDERS ("" = medio deshabilitado).
+func TestNewProviders(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  Config
+		want map[PaymentMethod]string
+	}{
+		{"por defecto", Config{Provider: "fake"}, map[PaymentMethod]string{
+			MethodUnspecified: "fake", MethodPSE: "fake", MethodCard: "fake", MethodCashOnDelivery: "cod", MethodStoreCredit: "",
+		}},
+		{"tarjeta aparte", Config{Provider: "fake", MethodProviders: map[PaymentMethod]string{MethodCard: "scripted"}}, map[PaymentMethod]string{
+			MethodPSE: "fake", MethodCard: "scripted", MethodCashOnDelivery: "cod",
+		}},
+		{"contra entrega deshabilitada", Config{Provider: "scripted", MethodProviders: map[PaymentMethod]string{MethodCashOnDelivery: ""}}, map[PaymentMethod]string{
+			MethodPSE: "scripted", MethodCashOnDelivery: "",
+		}},
+		{"sin PAYMENT_PROVIDER", Config{MethodProviders: map[PaymentMethod]string{MethodPSE: "fake"}}, map[PaymentMethod]string{
+			MethodUnspecified: "", MethodPSE: "fake", MethodCard: "", MethodCashOnDelivery: "cod",
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			routes, err := newProviders(tc.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for m, want := range tc.want {
+				p, err := routes.forMethod(m)
+				if want == "" {
+					if !errors.Is(err, ErrMethodUnavailable) {
+						t.Errorf("medio %d: %T, %v; se esperaba ErrMethodUnavailable", m, p, err)
+					}
+					continue
+				}
+				if err != nil || providerName(p) != want {
+					t.Errorf("medio %d = %s (%v), se esperaba %s", m, providerName(p), err, want)
+				}
+			}
+		})
+	}
+
+	// Una pasarela que atiende varios medios se crea una sola vez
+	routes, err := newProviders(Config{Provider: "scripted"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes[MethodPSE] != routes[MethodCard] {
+		t.Fatal("PSE y tarjeta usan instancias distintas de la misma pasarela")
+	}
+
+	if _, err := newProviders(Config{Provider: "fake", MethodProviders: map[PaymentMethod]string{MethodCard: "wallet"}}); err == nil {
+		t.Fatal("una pasarela desconocida en PAYMENT_METHOD_PROVIDERS no falló")
+	}
+}
+
+// providerName devuelve el nombre registrado de la pasarela.
+func providerName(p PaymentProvider) string {
+	switch p.(type) {
+	case *fakeProvider:
+		return "fake"
+	case *scriptedProvider:
+		return "scripted"
+	case codProvider:
+		return "cod"
+	case *pseProvider:
+		return "pse"
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+func TestGetMethodProviders(t *testing.T) {
+	t.Setenv("TEST_METHOD_PROVIDERS", " card=scripted, cod= ,wallet=fake,,pse=pse")
+	got := getMethodProviders("TEST_METHOD_PROVIDERS")
+	want := map[PaymentMethod]string{MethodCard: "scripted", MethodCashOnDelivery: "", MethodPSE: "pse"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("getMethodProviders = %v, se esperaba %v", got, want)
+	}
+}
+
+func TestScriptedProvider(t *testing.T) {
+	p := &scriptedProvider{}
+	charges := []struct {
+		amount int64
+		status ChargeStatus
+		reason string
+	}{
+		{1500, ChargeApproved, ""},
+		{1501, ChargeDeclined, "insufficient_funds"},
+		{1502, ChargeDeclined, "card_declined"},
+		{1503, ChargeDeclined, "bank_unavailable"},
+		{1504, ChargeApproved, ""},
+	}
+	for _, tc := range charges {
+		res, err := p.Charge(context.Background(), ChargeRequest{OrderID: 7, AmountCents: tc.amount, IdempotencyKey: chargeKey(7, 1)})
+		if err != nil || res.Status != tc.status || res.FailReason != tc.reason || res.ProviderRef != "SCRIPTED-"+chargeKey(7, 1) {
+			t.Errorf("cobro de %d = %+v (%v), se esperaba %d %q", tc.amount, res, err, tc.status, tc.reason)
+		}
+	}
+
+	refunds := []struct {
+		amount int64
+		reason string
+	}{
+		{500, ""},
+		{599, "refund_rejected"},
+		{0, "invalid_amount"},
+	}
+	for _, tc := range refunds {
+		res, err := p.Refund(context.Background(), RefundRequest{OrderID: 7, AmountCents: tc.amount, IdempotencyKey: "return:1"})
+		if err != nil || res.Approved != (tc.reason == "") || res.FailReason != tc.reason {
+			t.Errorf("reembolso de %d = %+v (%v), se esperaba %q", tc.amount, res, err, tc.reason)
+		}
+	}
+}
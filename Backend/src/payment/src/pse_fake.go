@@ -2,13 +2,12 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"hash/fnv"
 	"math/rand"
-	"time"
 )
 
-type PaymentProvider interface {
-	Charge(ctx context.Context, orderID, amountCents int64) (ok bool, providerRef, failReason string)
+func init() {
+	registerProvider("fake", func(Config) (PaymentProvider, error) { return newFakeProvider(), nil })
 }
 
 type fakeProvider struct{}
@@ -18,14 +17,32 @@ func newFakeProvider() PaymentProvider { return &fakeProvider{} }
 // Regla determinística simple para pruebas (evita flakes):
 // - Si orderID es PAR ⇒ éxito.
 // - Si es IMPAR ⇒ 80% éxito y 20% falla por fondos insuficientes.
-func (f *fakeProvider) Charge(ctx context.Context, orderID, amountCents int64) (bool, string, string) {
-	ref := fmt.Sprintf("FAKE-%d-%d", orderID, time.Now().UnixNano())
-	if orderID%2 == 0 {
-		return true, ref, ""
+//
+// El azar sale de la clave de idempotencia: el mismo intento siempre obtiene la
+// misma respuesta y la misma referencia.
+func (f *fakeProvider) Charge(ctx context.Context, req ChargeRequest) (ChargeResult, error) {
+	res := ChargeResult{Status: ChargeApproved, ProviderRef: "FAKE-" + req.IdempotencyKey}
+	if req.OrderID%2 == 0 {
+		return res, nil
 	}
-	r := rand.New(rand.NewSource(orderID + time.Now().UnixNano()))
+	h := fnv.New64a()
+	h.Write([]byte(req.IdempotencyKey))
+	r := rand.New(rand.NewSource(int64(h.Sum64())))
 	if r.Intn(10) < 8 {
-		return true, ref, ""
+		return res, nil
 	}
-	return false, ref, "insufficient_funds"
+	res.Status, res.FailReason = ChargeDeclined, "insufficient_funds"
+	return res, nil
+}
+
+// Los reembolsos de la pasarela fake siempre se aprueban; el tope contra lo
+// cobrado lo valida el servicio. La referencia sale de la clave, como en Charge.
+func (f *fakeProvider) Refund(ctx context.Context, req RefundRequest) (RefundResult, error) {
+	res := RefundResult{ProviderRef: "FAKE-REFUND-" + req.IdempotencyKey}
+	if req.AmountCents <= 0 {
+		res.FailReason = "invalid_amount"
+		return res, nil
+	}
+	res.Approved = true
+	return res, nil
 }
@@ -3,9 +3,14 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
 type broker struct {
@@ -41,12 +46,31 @@ func (b *broker) close() {
 }
 
 func (b *broker) consumePaymentRequested(ctx context.Context, handler func(context.Context, []byte) error, consumerTag string, prefetch int) error {
-	q, err := b.ch.QueueDeclare(b.cfg.RequestQueue, true, false, false, false, nil)
+	// Bind a topic key; puedes publicar con routing key "payment.charge.requested"
+	return b.consume(ctx, b.cfg.RequestQueue, "payment.charge.requested", handler, consumerTag, prefetch)
+}
+
+// consumeRefundRequested atiende las solicitudes de reembolso de Order (devoluciones).
+func (b *broker) consumeRefundRequested(ctx context.Context, handler func(context.Context, []byte) error, consumerTag string, prefetch int) error {
+	return b.consume(ctx, b.cfg.RefundQueue, "payment.refund.requested", handler, consumerTag+"-refunds", prefetch)
+}
+
+// consumeOrderDelivered recibe las entregas de Order para capturar los pagos contra entrega.
+func (b *broker) consumeOrderDelivered(ctx context.Context, handler func(context.Context, []byte) error, consumerTag string, prefetch int) error {
+	return b.consume(ctx, b.cfg.DeliveryQueue, "order.delivered", handler, consumerTag+"-deliveries", prefetch)
+}
+
+// consumeOrderExpired recibe las órdenes vencidas para anular o reembolsar el cobro.
+func (b *broker) consumeOrderExpired(ctx context.Context, handler func(context.Context, []byte) error, consumerTag string, prefetch int) error {
+	return b.consume(ctx, b.cfg.ExpiryQueue, "order.expired", handler, consumerTag+"-expiries", prefetch)
+}
+
+func (b *broker) consume(ctx context.Context, queue, routingKey string, handler func(context.Context, []byte) error, consumerTag string, prefetch int) error {
+	q, err := b.ch.QueueDeclare(queue, true, false, false, false, nil)
 	if err != nil {
 		return err
 	}
-	// Bind a topic key; puedes publicar con routing key "payment.charge.requested"
-	if err := b.ch.QueueBind(q.Name, "payment.charge.requested", b.cfg.ExchangeName, false, nil); err != nil {
+	if err := b.ch.QueueBind(q.Name, routingKey, b.cfg.ExchangeName, false, nil); err != nil {
 		return err
 	}
 	if err := b.ch.Qos(prefetch, 0, false); err != nil {
@@ -59,25 +83,52 @@ func (b *broker) consumePaymentRequested(ctx context.Context, handler func(conte
 
 	go func() {
 		for d := range deliveries {
-			if err := handler(ctx, d.Body); err != nil {
-				log.Printf("[payment] handler error: %v", err)
+			ctx := logging.FromHeaders(ctx, d.Headers)
+			ctx, span := tracing.StartConsume(ctx, d.Headers, queue, d.RoutingKey)
+			metrics.Received(queue, d.RoutingKey, d.Timestamp)
+			err := handler(ctx, d.Body)
+			tracing.End(span, err)
+			metrics.Consumed(queue, d.RoutingKey, err)
+			if err != nil {
+				slog.ErrorContext(ctx, "rabbit: handler", "queue", queue, "err", err)
 				_ = d.Nack(false, true) // lo encola de nuevo
 				continue
 			}
 			_ = d.Ack(false)
 		}
-		log.Printf("[payment] deliveries channel closed")
+		slog.Warn("rabbit: consumidor detenido", "queue", queue)
 	}()
 	return nil
 }
 
-func (b *broker) publishJSON(exchangeKey string, v any) {
+// queueDepth lee del broker los mensajes en espera de queue (pkg/metrics). Usa
+// un canal propio: la declaración pasiva de una cola inexistente cierra el canal.
+func (b *broker) queueDepth(queue string) (int, error) {
+	ch, err := b.conn.Channel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return 0, err
+	}
+	return q.Messages, nil
+}
+
+// publishJSON publica v con el correlation_id y el contexto de traza de ctx en los headers.
+func (b *broker) publishJSON(ctx context.Context, exchangeKey string, v any) {
 	body, _ := json.Marshal(v)
-	err := b.ch.Publish(b.cfg.ExchangeName, exchangeKey, false, false, amqp.Publishing{
+	ctx, span := tracing.StartPublish(ctx, b.cfg.ExchangeName, exchangeKey)
+	err := b.ch.PublishWithContext(ctx, b.cfg.ExchangeName, exchangeKey, false, false, amqp.Publishing{
 		ContentType: "application/json",
+		Headers:     tracing.Inject(ctx, logging.Headers(ctx)),
 		Body:        body,
+		Timestamp:   time.Now(),
 	})
+	tracing.End(span, err)
+	metrics.Published(exchangeKey, err)
 	if err != nil {
-		log.Printf("[payment] publish error (%s): %v", exchangeKey, err)
+		slog.WarnContext(ctx, "publish", "rk", exchangeKey, "err", err)
 	}
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+)
+
+// declineLabels son los motivos de rechazo que se usan como etiqueta de la
+// métrica. El motivo lo puede escribir el banco (o ser el texto de un error), y
+// cada valor distinto crearía una serie nueva en Prometheus.
+var declineLabels = map[string]bool{
+	"insufficient_funds":        true,
+	"rejected_by_bank":          true,
+	"bank_timeout":              true,
+	"order_expired":             true,
+	"method_unavailable":        true,
+	"invalid_instrument":        true,
+	"invalid_amount":            true,
+	"insufficient_store_credit": true,
+	"provider_rejected_request": true,
+	"risk_declined":             true,
+	"risk_rejected":             true,
+}
+
+// declineLabel reduce el motivo de rechazo a una de las declineLabels, o a
+// "other" si no es ninguna.
+func declineLabel(reason string) string {
+	switch {
+	case reason == "":
+		return "unknown"
+	case reason == "declined_by_bank": // psesim
+		return "rejected_by_bank"
+	case declineLabels[reason]:
+		return reason
+	}
+	return "other"
+}
+
+// settle aplica el resultado final de un cobro PENDING y publica
+// payment.succeeded o payment.failed. Es idempotente: si el cobro síncrono, un
+// webhook o el reconciliador ya lo resolvieron, no hace nada.
+func (s *service) settle(ctx context.Context, orderID int64, res ChargeResult, source string) error {
+	state := PaymentStateFailed
+	if res.Status == ChargeApproved {
+		state = PaymentStateSucceeded
+	}
+	changed, err := s.repo.SetResult(ctx, orderID, state, res.ProviderRef, res.FailReason)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		if state != PaymentStateSucceeded {
+			return nil
+		}
+		p, err := s.repo.GetByOrderID(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if p != nil && p.State == PaymentStateFailed {
+			// El banco aprobó después de darse por vencido: el dinero quedó capturado
+			slog.WarnContext(ctx, "aprobado tras marcarse FAILED; se reembolsa", "order_id", orderID, "source", source, "ref", res.ProviderRef)
+			return s.refundLateApproval(ctx, p, res.ProviderRef)
+		}
+		return nil
+	}
+
+	if state == PaymentStateSucceeded {
+		s.publishJSON(ctx, "payment.succeeded", PaymentSucceeded{OrderID: orderID, ProviderRef: res.ProviderRef})
+		slog.InfoContext(ctx, "SUCCEEDED", "order_id", orderID, "ref", res.ProviderRef, "source", source)
+		return nil
+	}
+	s.publishJSON(ctx, "payment.failed", PaymentFailed{OrderID: orderID, Reason: res.FailReason, ProviderRef: res.ProviderRef})
+	metrics.PaymentDeclined(declineLabel(res.FailReason))
+	slog.InfoContext(ctx, "FAILED", "order_id", orderID, "reason", res.FailReason, "ref", res.ProviderRef, "source", source)
+	return nil
+}
+
+// refundLateApproval devuelve un cobro que el banco aprobó cuando el pago ya
+// estaba FAILED (por ejemplo tras bank_timeout). El pago sigue FAILED y el
+// libro no registra nada: no hubo cobro para la orden. La clave sale de la
+// transacción, así un webhook repetido no reembolsa dos veces; si la pasarela
+// no responde se devuelve el error para que el aviso se reintente, y si
+// rechaza el reembolso queda como REFUND_FAILED en el reporte de conciliación.
+func (s *service) refundLateApproval(ctx context.Context, p *Payment, chargeRef string) error {
+	amount := p.AmountCents - p.WalletCents // el monedero ya se devolvió al fallar
+	provider, err := s.providers.forMethod(p.Method)
+	if err != nil {
+		return s.flagRefundFailed(ctx, p, chargeRef, amount, "late_approval: method_unavailable")
+	}
+	rctx, cancel := context.WithTimeout(ctx, s.cfg.ChargeTimeout)
+	res, err := provider.Refund(rctx, RefundRequest{
+		OrderID:        p.OrderID,
+		ChargeRef:      chargeRef,
+		AmountCents:    amount,
+		Reason:         "late_approval",
+		IdempotencyKey: "late:" + chargeRef,
+	})
+	cancel()
+	switch {
+	case err != nil && isRetryable(err):
+		return err
+	case err != nil:
+		slog.WarnContext(ctx, "refund", "order_id", p.OrderID, "ref", chargeRef, "err", err)
+		return s.flagRefundFailed(ctx, p, chargeRef, amount, "late_approval: provider_rejected_request")
+	case !res.Approved:
+		return s.flagRefundFailed(ctx, p, chargeRef, amount, "late_approval: "+res.FailReason)
+	}
+	slog.InfoContext(ctx, "REFUNDED aprobación tardía", "order_id", p.OrderID, "ref", chargeRef, "refund", res.ProviderRef, "amount_cents", amount)
+	return nil
+}
+
+// runReconciler revisa periódicamente los pagos PENDING por si el webhook no
+// llegó o la pasarela nunca respondió al cobro: pregunta a la pasarela o repite
+// el cobro con la misma clave y, pasado PendingExpiry sin respuesta, los da por
+// fallidos.
+func (s *service) runReconciler(ctx context.Context) {
+	t := time.NewTicker(s.cfg.ReconcileInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			s.reconcilePending(ctx, time.Now())
+		}
+	}
+}
+
+func (s *service) reconcilePending(ctx context.Context, now time.Time) {
+	ps, err := s.repo.ListPendingBefore(ctx, now.Add(-s.cfg.PendingTimeout))
+	if err != nil {
+		slog.ErrorContext(ctx, "reconcile", "err", err)
+		return
+	}
+	for _, p := range ps {
+		// Cada pago revisado es una operación propia con su correlation_id
+		ctx := logging.NewContext(ctx, logging.NewID())
+		if p.ProviderRef == "" && now.Sub(p.UpdatedAt) < s.cfg.PendingExpiry {
+			// El cobro no tuvo respuesta: repetirlo con la misma clave no cobra dos veces.
+			// screen reutiliza la evaluación guardada (o evalúa si no alcanzó a guardarse).
+			ok, err := s.screen(ctx, &p)
+			if err == nil && ok {
+				err = s.charge(ctx, &p)
+			}
+			if err != nil {
+				slog.WarnContext(ctx, "reconcile", "order_id", p.OrderID, "err", err)
+			}
+			continue
+		}
+		res := ChargeResult{Status: ChargePending, ProviderRef: p.ProviderRef}
+		provider, _ := s.providers.forMethod(p.Method)
+		querier, canQuery := provider.(ChargeQuerier)
+		if canQuery && p.ProviderRef != "" {
+			if res, err = querier.QueryCharge(ctx, p.OrderID, p.ProviderRef); err != nil {
+				// Sin respuesta no se decide nada: el banco pudo haber aprobado
+				slog.WarnContext(ctx, "reconcile", "order_id", p.OrderID, "ref", p.ProviderRef, "err", err)
+				continue
+			}
+		}
+		if res.Status == ChargePending {
+			if now.Sub(p.UpdatedAt) < s.cfg.PendingExpiry {
+				continue
+			}
+			res = ChargeResult{Status: ChargeDeclined, ProviderRef: p.ProviderRef, FailReason: "bank_timeout"}
+		}
+		if err := s.settle(ctx, p.OrderID, res, "reconciler"); err != nil {
+			slog.WarnContext(ctx, "reconcile", "order_id", p.OrderID, "err", err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RefundDeclinedError es un rechazo de la pasarela al reembolso.
+type RefundDeclinedError struct{ Reason string }
+
+func (e *RefundDeclinedError) Error() string { return "refund declined: " + e.Reason }
+
+// refundOutcome es el resultado de un reembolso: la parte devuelta por la
+// pasarela, la devuelta como saldo a favor, o ambas.
+type refundOutcome struct {
+	Refund *Transaction // por la pasarela
+	Credit *Transaction // al monedero
+	State  PaymentState
+}
+
+func (o *refundOutcome) amount() int64 {
+	var n int64
+	if o.Refund != nil {
+		n += o.Refund.AmountCents
+	}
+	if o.Credit != nil {
+		n += o.Credit.AmountCents
+	}
+	return n
+}
+
+func (o *refundOutcome) providerRef() string {
+	if o.Refund != nil {
+		return o.Refund.ProviderRef
+	}
+	if o.Credit != nil {
+		return o.Credit.ProviderRef
+	}
+	return ""
+}
+
+// refund devuelve dinero de un pago cobrado y lo registra en el libro de
+// transacciones. amountCents 0 reembolsa todo el saldo. Con key != "" un
+// reintento devuelve las transacciones originales sin volver a la pasarela.
+// Si la pasarela no responde se devuelve su error (reintentable) y no se
+// registra nada; el reintento manda la misma clave (ver refundKey).
+//
+// Va como saldo a favor si toCredit, si el pago fue contra entrega (no hay a
+// dónde devolverlo) o si lo que queda por devolver se pagó con el monedero; si
+// no, por la pasarela que cobró. Un reembolso que cruza ambas partes se parte
+// en dos: primero el saldo a favor (clave key+"/credit") y luego la pasarela
+// (clave key), así un reintento tras una falla de la pasarela no acredita dos veces.
+func (s *service) refund(ctx context.Context, orderID, amountCents int64, reason, key string, toCredit bool) (*refundOutcome, error) {
+	// Serializa validación → pasarela → registro para no reembolsar dos veces el mismo saldo
+	s.refundMu.Lock()
+	defer s.refundMu.Unlock()
+
+	p, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrNotCaptured
+	}
+	out := &refundOutcome{State: p.State}
+	creditKey := ""
+	if key != "" {
+		creditKey = key + "/credit"
+		prev, err := s.keyedTransaction(ctx, key, orderID)
+		if err != nil {
+			return nil, err
+		}
+		if out.Credit, err = s.keyedTransaction(ctx, creditKey, orderID); err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			if prev.StoreCredit {
+				out.Credit = prev
+			} else {
+				out.Refund = prev
+			}
+			return out, nil
+		}
+	}
+	if p.State != PaymentStateSucceeded && p.State != PaymentStatePartiallyRefunded {
+		return nil, ErrNotCaptured
+	}
+
+	captured, refunded, err := s.repo.Balance(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	walletCaptured, walletRefunded, err := s.repo.StoreCreditBalance(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := captured - refunded
+	if amountCents == 0 {
+		amountCents = remaining
+	} else if out.Credit != nil {
+		amountCents -= out.Credit.AmountCents // la parte de saldo a favor ya se hizo
+	}
+	if amountCents <= 0 || amountCents > remaining {
+		return nil, ErrRefundExceedsCapture
+	}
+
+	// Lo reembolsable por la pasarela es lo que cobró menos lo que ya devolvió
+	// (los reembolsos a saldo a favor consumen primero la parte del monedero)
+	external := captured - walletCaptured - (refunded - walletRefunded)
+	if extra := walletRefunded - walletCaptured; extra > 0 {
+		external -= extra
+	}
+	external = min(external, remaining)
+
+	credit := int64(0)
+	switch {
+	case out.Credit != nil:
+	case toCredit || (p.Method == MethodCashOnDelivery && p.UserID != 0) || external <= 0:
+		credit = amountCents
+	case amountCents > external:
+		credit = amountCents - external
+	}
+	if credit > 0 {
+		if p.UserID == 0 {
+			return nil, &RefundDeclinedError{Reason: "store_credit_unavailable"}
+		}
+		k := key
+		if credit < amountCents {
+			k = creditKey
+		}
+		t := &Transaction{
+			OrderID:     orderID,
+			AmountCents: credit,
+			ProviderRef: walletRef(orderID),
+			Reason:      reason,
+			IdemKey:     k,
+		}
+		if out.State, err = s.repo.AddStoreCreditRefund(ctx, t, p.UserID); err != nil {
+			return nil, err
+		}
+		out.Credit = t
+		if credit == amountCents {
+			return out, nil
+		}
+	}
+
+	// El resto vuelve por la pasarela que cobró
+	amountCents -= credit
+	provider, err := s.providers.forMethod(p.Method)
+	if err != nil {
+		return nil, &RefundDeclinedError{Reason: "method_unavailable"}
+	}
+	rkey := refundKey(key, orderID, refunded, amountCents)
+	rctx, cancel := context.WithTimeout(ctx, s.cfg.ChargeTimeout)
+	res, err := provider.Refund(rctx, RefundRequest{
+		OrderID:        orderID,
+		ChargeRef:      p.ProviderRef,
+		AmountCents:    amountCents,
+		Reason:         reason,
+		IdempotencyKey: rkey,
+	})
+	cancel()
+	if err != nil {
+		if isRetryable(err) {
+			// No se sabe si la pasarela reembolsó: el reintento repite la clave
+			return nil, err
+		}
+		slog.WarnContext(ctx, "refund", "order_id", orderID, "key", rkey, "err", err)
+		return nil, &RefundDeclinedError{Reason: "provider_rejected_request"}
+	}
+	if !res.Approved {
+		return nil, &RefundDeclinedError{Reason: res.FailReason}
+	}
+	t := &Transaction{
+		OrderID:     orderID,
+		AmountCents: amountCents,
+		ProviderRef: res.ProviderRef,
+		Reason:      reason,
+		IdemKey:     key,
+	}
+	if out.State, err = s.repo.AddRefund(ctx, t); err != nil {
+		return nil, err
+	}
+	out.Refund = t
+	return out, nil
+}
+
+// flagRefundFailed deja a la vista de los operadores (reporte de conciliación,
+// tipo REFUND_FAILED) un cobro chargeRef que debía devolverse y la pasarela
+// rechazó. Se resuelve solo si un reembolso posterior devuelve todo el saldo
+// de la orden; si no, sigue abierto en el reporte.
+func (s *service) flagRefundFailed(ctx context.Context, p *Payment, chargeRef string, amountCents int64, note string) error {
+	return s.repo.RecordDiscrepancy(ctx, &Discrepancy{
+		Kind:          DiscrepancyRefundFailed,
+		Date:          time.Now().UTC().Format(dateLayout),
+		ProviderRef:   chargeRef,
+		OrderID:       p.OrderID,
+		TxKind:        TxRefund,
+		RecordedCents: amountCents,
+		Note:          note,
+	})
+}
+
+// refundKey es la clave de idempotencia del reembolso en la pasarela: la del
+// reembolso o, si no se dio, una derivada de lo ya reembolsado. Un reintento
+// tras un timeout no cambia ese saldo, así que repite la clave y la pasarela
+// no reembolsa dos veces.
+func refundKey(key string, orderID, refunded, amountCents int64) string {
+	if key != "" {
+		return key
+	}
+	return fmt.Sprintf("refund:%d:%d:%d", orderID, refunded, amountCents)
+}
+
+// keyedTransaction busca la transacción de una clave de idempotencia y
+// verifica que sea de la orden.
+func (s *service) keyedTransaction(ctx context.Context, key string, orderID int64) (*Transaction, error) {
+	t, err := s.repo.GetTransactionByKey(ctx, key)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	if t.OrderID != orderID {
+		return nil, fmt.Errorf("idempotency key %q belongs to order %d", key, t.OrderID)
+	}
+	return t, nil
+}
+
+// refundFailReason traduce el error de refund al motivo publicado en payment.refund_failed.
+func refundFailReason(err error) string {
+	var declined *RefundDeclinedError
+	switch {
+	case errors.As(err, &declined):
+		return declined.Reason
+	case errors.Is(err, ErrRefundExceedsCapture):
+		return "exceeds_captured_amount"
+	case errors.Is(err, ErrNotCaptured):
+		return "payment_not_captured"
+	default:
+		return ""
+	}
+}
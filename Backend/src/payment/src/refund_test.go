@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requestRefund entrega payment.refund.requested como RabbitMQ: se reencola
+// mientras el handler devuelva error. Devuelve cuántas entregas hicieron falta.
+func (h *harness) requestRefund(t *testing.T, orderID, returnID, amountCents int64) int {
+	t.Helper()
+	body := []byte(fmt.Sprintf(`{"order_id":%d,"return_id":%d,"amount_cents":%d,"reason":"devolución"}`, orderID, returnID, amountCents))
+	for n := 1; n <= 10; n++ {
+		if err := h.svc.handleRefundRequested(context.Background(), body); err == nil {
+			return n
+		}
+	}
+	t.Fatalf("order %d: el reembolso sigue fallando tras 10 entregas", orderID)
+	return 0
+}
+
+func (h *harness) refunded(t *testing.T, orderID int64) int64 {
+	t.Helper()
+	_, refunded, err := h.repo.Balance(context.Background(), orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return refunded
+}
+
+// TestRefundTimeoutRetriesWithSameKey: la pasarela reembolsó pero la respuesta
+// no llegó. El mensaje se reencola y el reintento manda la misma clave, así
+// que el banco reembolsa una sola vez.
+func TestRefundTimeoutRetriesWithSameKey(t *testing.T) {
+	for name, f := range map[string]fault{"request perdido": lostRequest, "respuesta perdida": lostResponse} {
+		t.Run(name, func(t *testing.T) {
+			h := newHarness(t)
+			h.deliver(t, 42, 1500)
+			h.bank.refundFaults = []fault{f}
+			if n := h.requestRefund(t, 42, 5, 500); n != 2 {
+				t.Fatalf("entregas = %d, se esperaban 2", n)
+			}
+			if len(h.bank.refunds) != 1 || fmt.Sprint(h.bank.refundKeys) != "[return:5 return:5]" {
+				t.Fatalf("reembolsos = %v, claves = %v", h.bank.refunds, h.bank.refundKeys)
+			}
+			if got := h.refunded(t, 42); got != 500 {
+				t.Fatalf("reembolsado = %d, se esperaba 500", got)
+			}
+			if h.pub.count("payment.refunded") != 1 || h.pub.count("payment.refund_failed") != 0 {
+				t.Fatalf("eventos publicados: %v", h.pub.keys)
+			}
+		})
+	}
+}
+
+// TestRefundPaymentWithoutKey: sin idempotency_key el RPC deriva la clave de
+// lo ya reembolsado, así que repetirlo tras un timeout tampoco reembolsa dos veces.
+func TestRefundPaymentWithoutKey(t *testing.T) {
+	h := newHarness(t)
+	h.deliver(t, 42, 1500)
+	req := &paymentpb.RefundPaymentRequest{OrderId: 42, Amount: &commonpb.Money{Cents: 500}, Reason: "soporte"}
+
+	h.bank.refundFaults = []fault{lostResponse}
+	if _, err := h.svc.RefundPayment(context.Background(), req); status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, se esperaba Unavailable", err)
+	}
+	resp, err := h.svc.RefundPayment(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetRefundedTotal().GetCents() != 500 || len(h.bank.refunds) != 1 {
+		t.Fatalf("reembolsado = %d, reembolsos en el banco = %d", resp.GetRefundedTotal().GetCents(), len(h.bank.refunds))
+	}
+	if h.bank.refundKeys[0] != h.bank.refundKeys[1] {
+		t.Fatalf("claves = %v, se esperaba la misma en el reintento", h.bank.refundKeys)
+	}
+
+	// Un segundo reembolso del mismo monto es otro reembolso: otra clave
+	if _, err := h.svc.RefundPayment(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.refunded(t, 42); got != 1000 || len(h.bank.refunds) != 2 {
+		t.Fatalf("reembolsado = %d, reembolsos en el banco = %d", got, len(h.bank.refunds))
+	}
+}
+
+// TestRefundExceedsCapture: no se devuelve más de lo cobrado, ni de una vez ni
+// sumando reembolsos parciales, y el banco no llega a enterarse.
+func TestRefundExceedsCapture(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(t)
+	h.deliver(t, 42, 1500)
+
+	if _, err := h.svc.refund(ctx, 42, 1501, "devolución", "rfd-1", false); !errors.Is(err, ErrRefundExceedsCapture) {
+		t.Fatalf("err = %v, se esperaba ErrRefundExceedsCapture", err)
+	}
+	if _, err := h.svc.refund(ctx, 42, 1000, "devolución", "rfd-2", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.svc.refund(ctx, 42, 600, "devolución", "rfd-3", false); !errors.Is(err, ErrRefundExceedsCapture) {
+		t.Fatalf("err = %v, se esperaba ErrRefundExceedsCapture", err)
+	}
+
+	_, err := h.svc.RefundPayment(ctx, &paymentpb.RefundPaymentRequest{OrderId: 42, Amount: &commonpb.Money{Cents: 501}, IdempotencyKey: "rfd-4"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("RefundPayment: err = %v, se esperaba FailedPrecondition", err)
+	}
+	if got := h.refunded(t, 42); got != 1000 || len(h.bank.refundKeys) != 1 {
+		t.Fatalf("reembolsado = %d, llamadas al banco = %v", got, h.bank.refundKeys)
+	}
+
+	// El resto sí cabe
+	resp, err := h.svc.RefundPayment(ctx, &paymentpb.RefundPaymentRequest{OrderId: 42, IdempotencyKey: "rfd-5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.State != paymentpb.PaymentState_PAYMENT_STATE_REFUNDED || resp.GetTransaction().GetAmount().GetCents() != 500 {
+		t.Fatalf("respuesta = %v", resp)
+	}
+}
+
+// TestRefundRejectedRequest: un 4xx de la pasarela es definitivo: se publica
+// refund_failed en vez de reencolar para siempre.
+func TestRefundRejectedRequest(t *testing.T) {
+	h := newHarness(t)
+	h.deliver(t, 42, 1500)
+	h.bank.refundFaults = []fault{badRequest}
+	if n := h.requestRefund(t, 42, 5, 500); n != 1 {
+		t.Fatalf("entregas = %d, un error final no debe reencolar", n)
+	}
+	if h.pub.count("payment.refund_failed") != 1 || h.refunded(t, 42) != 0 {
+		t.Fatalf("eventos publicados: %v", h.pub.keys)
+	}
+}
+
+// TestOrderExpiredRefundFailures: una orden vencida ya cobrada se reembolsa. Si
+// la pasarela no responde el mensaje se reencola; si rechaza, el cobro queda
+// como REFUND_FAILED en el reporte hasta que un reembolso posterior lo devuelve.
+func TestOrderExpiredRefundFailures(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(t)
+	expire := func(orderID int64) error {
+		return h.svc.handleOrderExpired(ctx, []byte(fmt.Sprintf(`{"order_id":%d,"reason":"payment_timeout"}`, orderID)))
+	}
+	report := func() *paymentpb.ReconciliationReport {
+		t.Helper()
+		rep, err := h.svc.GetReconciliationReport(ctx, &paymentpb.ReconciliationReportRequest{IncludeResolved: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rep
+	}
+
+	// Sin respuesta: se reencola y el reintento reembolsa una vez
+	h.deliver(t, 42, 1500)
+	h.bank.refundFaults = []fault{lostResponse}
+	if err := expire(42); err == nil {
+		t.Fatal("un timeout de la pasarela debe reencolar el mensaje")
+	}
+	if err := expire(42); err != nil {
+		t.Fatal(err)
+	}
+	if p := h.payment(t, 42); p.State != PaymentStateRefunded || len(h.bank.refunds) != 1 {
+		t.Fatalf("estado = %s, reembolsos en el banco = %d", p.State, len(h.bank.refunds))
+	}
+
+	// Rechazo: se confirma el mensaje y queda a la vista, una sola vez
+	h.deliver(t, 43, 2000)
+	h.bank.refundDecline = "transaction_not_approved"
+	for range 2 {
+		if err := expire(43); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rep := report()
+	if rep.RefundFailed != 1 || len(rep.Discrepancies) != 1 {
+		t.Fatalf("reporte = %v", rep)
+	}
+	d := rep.Discrepancies[0]
+	if d.Kind != paymentpb.DiscrepancyKind_DISCREPANCY_KIND_REFUND_FAILED || d.OrderId != 43 ||
+		d.Recorded.GetCents() != 2000 || d.Note != "order_expired: transaction_not_approved" || d.Resolved {
+		t.Fatalf("diferencia = %v", d)
+	}
+
+	// Un operador reembolsa a mano y la diferencia se resuelve
+	h.bank.refundDecline = ""
+	if _, err := h.svc.RefundPayment(ctx, &paymentpb.RefundPaymentRequest{OrderId: 43, IdempotencyKey: "manual-43"}); err != nil {
+		t.Fatal(err)
+	}
+	if rep := report(); rep.RefundFailed != 0 || !rep.Discrepancies[0].Resolved {
+		t.Fatalf("reporte tras el reembolso = %v", rep)
+	}
+}
@@ -3,23 +3,122 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
 )
 
 type Repository interface {
 	Init(ctx context.Context) error
 	UpsertPending(ctx context.Context, p Payment) error
-	SetResult(ctx context.Context, orderID int64, state PaymentState, providerRef string) error
+	// SetPending guarda la transacción que la pasarela dejó pendiente.
+	SetPending(ctx context.Context, orderID int64, providerRef, redirectURL string) error
+	// SetDeferred deja un pago PENDING esperando la entrega (contra entrega).
+	SetDeferred(ctx context.Context, orderID int64, providerRef string) error
+	// SetResult resuelve un pago PENDING, AWAITING_DELIVERY o UNDER_REVIEW y cierra su intento
+	// abierto; devuelve false si ya estaba resuelto.
+	SetResult(ctx context.Context, orderID int64, state PaymentState, providerRef, failReason string) (bool, error)
+	// OpenAttempt devuelve el intento de cobro en curso de la orden o abre uno nuevo.
+	OpenAttempt(ctx context.Context, orderID, amountCents int64) (*ChargeAttempt, error)
+	UpdateAttempt(ctx context.Context, a *ChargeAttempt) error
 	GetByOrderID(ctx context.Context, orderID int64) (*Payment, error)
+	// ListPendingBefore devuelve los pagos PENDING sin cambios desde before.
+	ListPendingBefore(ctx context.Context, before time.Time) ([]Payment, error)
+	// RecordWebhook registra una notificación; devuelve false si ya se había recibido.
+	RecordWebhook(ctx context.Context, eventID string, orderID int64) (bool, error)
+	ForgetWebhook(ctx context.Context, eventID string) error
+	// Balance devuelve lo cobrado y lo reembolsado según el libro de transacciones.
+	Balance(ctx context.Context, orderID int64) (captured, refunded int64, err error)
+	// AddRefund registra el reembolso si no supera el saldo, fija t.Kind (total o
+	// parcial) y actualiza el estado del pago, que devuelve. El reembolso total
+	// resuelve los REFUND_FAILED abiertos de la orden.
+	AddRefund(ctx context.Context, t *Transaction) (PaymentState, error)
+	// GetTransactionByKey devuelve (nil, nil) si no hay transacción con esa clave.
+	GetTransactionByKey(ctx context.Context, key string) (*Transaction, error)
+	ListTransactions(ctx context.Context, orderID int64) ([]Transaction, error)
+
+	// Conciliación
+	FindSettlementRun(ctx context.Context, checksum string) (*SettlementRun, error)
+	// TransactionsByRef devuelve los movimientos del libro con esa referencia de la pasarela.
+	TransactionsByRef(ctx context.Context, providerRef string) ([]Transaction, error)
+	// TransactionsBetween devuelve los movimientos creados en [from, to), sin
+	// los de contra entrega (la pasarela no los liquida).
+	TransactionsBetween(ctx context.Context, from, to time.Time) ([]Transaction, error)
+	// IsSettled indica si alguna liquidación anterior incluyó el movimiento.
+	IsSettled(ctx context.Context, providerRef string, refund bool) (bool, error)
+	// SaveSettlementRun guarda la corrida con sus líneas y diferencias; las
+	// líneas liquidadas resuelven los MISSING abiertos de corridas anteriores.
+	SaveSettlementRun(ctx context.Context, run *SettlementRun, lines []SettlementLine, found []Discrepancy) error
+	ListDiscrepancies(ctx context.Context, from, to string, includeResolved bool) ([]Discrepancy, error)
+	// RecordDiscrepancy guarda una diferencia que no sale de una liquidación
+	// (RunID 0). Si ya hay una abierta del mismo tipo para la orden no hace nada.
+	RecordDiscrepancy(ctx context.Context, d *Discrepancy) error
+	ListSettlementRuns(ctx context.Context, from, to string) ([]SettlementRun, error)
+
+	// Tarjetas guardadas
+	// SaveInstrument guarda la tarjeta; si el usuario ya tenía ese token
+	// devuelve la existente (y la restaura si estaba eliminada).
+	SaveInstrument(ctx context.Context, in *Instrument) (*Instrument, error)
+	// GetInstrument devuelve (nil, nil) si no existe; incluye las eliminadas.
+	GetInstrument(ctx context.Context, id int64) (*Instrument, error)
+	ListInstruments(ctx context.Context, userID int64) ([]Instrument, error)
+	// DeleteInstrument devuelve false si la tarjeta no es del usuario o ya estaba eliminada.
+	DeleteInstrument(ctx context.Context, userID, id int64) (bool, error)
+
+	// Monedero
+	WalletBalance(ctx context.Context, userID int64) (int64, error)
+	// ApplyWallet registra el movimiento y ajusta el saldo en una sola
+	// transacción; un débito mayor al saldo devuelve ErrInsufficientFunds. Si
+	// IdemKey ya se usó devuelve el movimiento original sin aplicarlo otra vez.
+	ApplyWallet(ctx context.Context, e *WalletEntry) (*WalletEntry, error)
+	ListWalletEntries(ctx context.Context, userID int64, limit int) ([]WalletEntry, error)
+	// StoreCreditBalance es como Balance pero solo con los movimientos del monedero.
+	StoreCreditBalance(ctx context.Context, orderID int64) (captured, refunded int64, err error)
+	// AddStoreCreditRefund registra el reembolso (t.StoreCredit) y acredita el
+	// monto en el monedero del usuario en la misma transacción.
+	AddStoreCreditRefund(ctx context.Context, t *Transaction, userID int64) (PaymentState, error)
+	CreateGiftCard(ctx context.Context, g *GiftCard) error
+	// RedeemGiftCard pasa el saldo de la tarjeta al monedero del usuario. Si el
+	// mismo usuario ya la había redimido devuelve ese movimiento.
+	RedeemGiftCard(ctx context.Context, userID int64, code string) (*WalletEntry, error)
+
+	// Análisis de riesgo
+	// SaveRiskAssessment guarda la evaluación del pago PENDING de la orden (y lo
+	// pasa a UNDER_REVIEW si la decisión es revisión). Devuelve false si el pago
+	// ya tenía evaluación o dejó de estar PENDING; en ese caso no guarda nada.
+	SaveRiskAssessment(ctx context.Context, a *RiskAssessment) (bool, error)
+	// GetRiskAssessment devuelve la evaluación vigente del pago de la orden o
+	// (nil, nil) si aún no se evaluó.
+	GetRiskAssessment(ctx context.Context, orderID int64) (*RiskAssessment, error)
+	// ResolveReview cierra la revisión pendiente de la orden; al aprobar, el
+	// pago vuelve a PENDING en la misma transacción. Devuelve false si no había
+	// revisión pendiente.
+	ResolveReview(ctx context.Context, orderID int64, approve bool, reviewer, note string) (bool, error)
+	ListRiskReviews(ctx context.Context, includeResolved bool, limit int) ([]RiskAssessment, error)
+	RiskHistory
 }
 
+var (
+	// ErrRefundExceedsCapture indica que el reembolso supera el saldo capturado del pago.
+	ErrRefundExceedsCapture = errors.New("refund exceeds captured amount")
+	// ErrNotCaptured indica que el pago no existe o no está cobrado.
+	ErrNotCaptured = errors.New("payment not captured")
+	// ErrInsufficientFunds indica que el débito dejaría el monedero en negativo.
+	ErrInsufficientFunds = errors.New("insufficient store credit")
+	ErrGiftCardNotFound  = errors.New("gift card not found")
+	ErrGiftCardRedeemed  = errors.New("gift card already redeemed")
+	ErrGiftCardExpired   = errors.New("gift card expired")
+)
+
 type sqliteRepo struct{ db *sql.DB }
 
 func newSQLiteRepo(path string) (Repository, error) {
-	db, err := sql.Open("sqlite3", path)
+	db, err := tracing.OpenSQLite("sqlite3", path)
 	if err != nil {
 		return nil, err
 	}
@@ -37,44 +136,1168 @@ CREATE TABLE IF NOT EXISTS payments(
   updated_unix INTEGER NOT NULL
 );
 CREATE INDEX IF NOT EXISTS idx_payments_state ON payments(state);
+CREATE TABLE IF NOT EXISTS payment_transactions(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  order_id INTEGER NOT NULL,
+  kind INTEGER NOT NULL,
+  amount_cents INTEGER NOT NULL,
+  provider_ref TEXT NOT NULL DEFAULT '',
+  reason TEXT NOT NULL DEFAULT '',
+  idem_key TEXT UNIQUE,
+  created_unix INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_ptx_order ON payment_transactions(order_id);
+CREATE TABLE IF NOT EXISTS payment_attempts(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  order_id INTEGER NOT NULL,
+  attempt INTEGER NOT NULL,
+  idem_key TEXT NOT NULL UNIQUE,
+  amount_cents INTEGER NOT NULL,
+  state INTEGER NOT NULL,
+  provider_ref TEXT NOT NULL DEFAULT '',
+  fail_reason TEXT NOT NULL DEFAULT '',
+  retries INTEGER NOT NULL DEFAULT 0,
+  created_unix INTEGER NOT NULL,
+  updated_unix INTEGER NOT NULL,
+  UNIQUE(order_id, attempt)
+);
+CREATE TABLE IF NOT EXISTS settlement_runs(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  source TEXT NOT NULL,
+  checksum TEXT NOT NULL UNIQUE,
+  period_from TEXT NOT NULL,
+  period_to TEXT NOT NULL,
+  lines INTEGER NOT NULL,
+  matched INTEGER NOT NULL,
+  discrepancies INTEGER NOT NULL,
+  created_unix INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS settlement_lines(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id INTEGER NOT NULL REFERENCES settlement_runs(id),
+  settled_date TEXT NOT NULL,
+  provider_ref TEXT NOT NULL,
+  refund INTEGER NOT NULL,
+  amount_cents INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sl_ref ON settlement_lines(provider_ref, refund);
+CREATE TABLE IF NOT EXISTS settlement_discrepancies(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  run_id INTEGER NOT NULL REFERENCES settlement_runs(id),
+  kind INTEGER NOT NULL,
+  date TEXT NOT NULL,
+  provider_ref TEXT NOT NULL,
+  order_id INTEGER NOT NULL DEFAULT 0,
+  tx_kind INTEGER NOT NULL DEFAULT 0,
+  recorded_cents INTEGER NOT NULL DEFAULT 0,
+  settled_cents INTEGER NOT NULL DEFAULT 0,
+  note TEXT NOT NULL DEFAULT '',
+  resolved_unix INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_sd_date ON settlement_discrepancies(date);
+CREATE INDEX IF NOT EXISTS idx_ptx_ref ON payment_transactions(provider_ref);
+CREATE TABLE IF NOT EXISTS payment_instruments(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL,
+  token TEXT NOT NULL,
+  brand TEXT NOT NULL,
+  last4 TEXT NOT NULL,
+  exp_month INTEGER NOT NULL DEFAULT 0,
+  exp_year INTEGER NOT NULL DEFAULT 0,
+  label TEXT NOT NULL DEFAULT '',
+  deleted_unix INTEGER NOT NULL DEFAULT 0,
+  created_unix INTEGER NOT NULL,
+  UNIQUE(user_id, token)
+);
+CREATE TABLE IF NOT EXISTS wallet_accounts(
+  user_id INTEGER PRIMARY KEY,
+  balance_cents INTEGER NOT NULL DEFAULT 0 CHECK(balance_cents >= 0),
+  updated_unix INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS wallet_transactions(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL,
+  kind INTEGER NOT NULL,
+  amount_cents INTEGER NOT NULL,
+  balance_after INTEGER NOT NULL,
+  order_id INTEGER NOT NULL DEFAULT 0,
+  reference TEXT NOT NULL DEFAULT '',
+  idem_key TEXT UNIQUE,
+  created_unix INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_wtx_user ON wallet_transactions(user_id);
+CREATE INDEX IF NOT EXISTS idx_wtx_order ON wallet_transactions(order_id);
+CREATE TABLE IF NOT EXISTS gift_cards(
+  code TEXT PRIMARY KEY,
+  initial_cents INTEGER NOT NULL,
+  balance_cents INTEGER NOT NULL CHECK(balance_cents >= 0),
+  redeemed_by INTEGER NOT NULL DEFAULT 0,
+  redeemed_unix INTEGER NOT NULL DEFAULT 0,
+  expires_unix INTEGER NOT NULL DEFAULT 0,
+  created_unix INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS risk_assessments(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  order_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  amount_cents INTEGER NOT NULL,
+  score INTEGER NOT NULL,
+  decision INTEGER NOT NULL,
+  reasons TEXT NOT NULL DEFAULT '[]',
+  review_state INTEGER NOT NULL DEFAULT 0,
+  reviewer TEXT NOT NULL DEFAULT '',
+  review_note TEXT NOT NULL DEFAULT '',
+  created_unix INTEGER NOT NULL,
+  reviewed_unix INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_risk_user ON risk_assessments(user_id, created_unix);
+CREATE INDEX IF NOT EXISTS idx_risk_review ON risk_assessments(review_state);
+CREATE TABLE IF NOT EXISTS webhook_events(
+  event_id TEXT PRIMARY KEY,
+  order_id INTEGER NOT NULL,
+  received_unix INTEGER NOT NULL
+);
 `
-	_, err := r.db.ExecContext(ctx, ddl)
+	if _, err := r.db.ExecContext(ctx, ddl); err != nil {
+		return err
+	}
+	// Columnas agregadas después de la primera versión del esquema
+	for _, c := range []struct{ table, column, def string }{
+		{"payments", "redirect_url", "TEXT NOT NULL DEFAULT ''"},
+		{"payments", "user_id", "INTEGER NOT NULL DEFAULT 0"},
+		{"payments", "method", "INTEGER NOT NULL DEFAULT 0"},
+		{"payments", "instrument_id", "INTEGER NOT NULL DEFAULT 0"},
+		{"payments", "wallet_cents", "INTEGER NOT NULL DEFAULT 0"},
+		{"payments", "risk_id", "INTEGER NOT NULL DEFAULT 0"},
+		{"payment_transactions", "store_credit", "INTEGER NOT NULL DEFAULT 0"},
+	} {
+		if err := ensureColumn(ctx, r.db, c.table, c.column, c.def); err != nil {
+			return err
+		}
+	}
+	return r.migrateLedger(ctx)
+}
+
+// ensureColumn agrega la columna si la tabla fue creada con un esquema anterior.
+func ensureColumn(ctx context.Context, db *sql.DB, table, column, def string) error {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, typ string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, def))
+	return err
+}
+
+// migrateLedger lleva al libro de transacciones los cobros existentes y los
+// reembolsos de la tabla refunds (versión anterior), que luego se elimina.
+func (r *sqliteRepo) migrateLedger(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `
+INSERT OR IGNORE INTO payment_transactions(order_id, kind, amount_cents, provider_ref, reason, idem_key, created_unix)
+SELECT order_id, ?, amount_cents, COALESCE(provider_ref,''), '', 'charge:' || order_id, updated_unix
+FROM payments p WHERE state=?
+  AND NOT EXISTS (SELECT 1 FROM payment_transactions t WHERE t.order_id=p.order_id AND t.kind=?);
+`, TxCharge, PaymentStateSucceeded, TxCharge); err != nil {
+		return err
+	}
+	var n int
+	if err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name='refunds'`).Scan(&n); err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `
+INSERT OR IGNORE INTO payment_transactions(order_id, kind, amount_cents, provider_ref, reason, idem_key, created_unix)
+SELECT order_id, ?, amount_cents, provider_ref, 'return:' || return_id, 'return:' || return_id, created_unix FROM refunds;
+UPDATE payments SET state = CASE
+    WHEN (SELECT SUM(amount_cents) FROM payment_transactions t WHERE t.order_id=payments.order_id AND t.kind<>?) >= amount_cents THEN ?
+    ELSE ? END
+WHERE state=? AND order_id IN (SELECT order_id FROM refunds);
+DROP TABLE refunds;
+`, TxPartialRefund, TxCharge, PaymentStateRefunded, PaymentStatePartiallyRefunded, PaymentStateSucceeded)
 	return err
 }
 
 func (r *sqliteRepo) UpsertPending(ctx context.Context, p Payment) error {
 	_, err := r.db.ExecContext(ctx, `
-INSERT INTO payments(order_id, amount_cents, state, provider_ref, updated_unix)
-VALUES(?,?,?,?,?)
+INSERT INTO payments(order_id, user_id, amount_cents, method, instrument_id, wallet_cents, state, provider_ref, updated_unix)
+VALUES(?,?,?,?,?,?,?,?,?)
 ON CONFLICT(order_id) DO UPDATE SET
+  user_id=excluded.user_id,
   amount_cents=excluded.amount_cents,
+  method=excluded.method,
+  instrument_id=excluded.instrument_id,
+  wallet_cents=excluded.wallet_cents,
+  risk_id=0,
   state=?,
   updated_unix=?;
-`, p.OrderID, p.AmountCents, PaymentStatePending, "", time.Now().Unix(),
+`, p.OrderID, p.UserID, p.AmountCents, p.Method, p.InstrumentID, p.WalletCents, PaymentStatePending, "", time.Now().Unix(),
 		PaymentStatePending, time.Now().Unix())
 	return err
 }
 
-func (r *sqliteRepo) SetResult(ctx context.Context, orderID int64, state PaymentState, providerRef string) error {
+func (r *sqliteRepo) SetPending(ctx context.Context, orderID int64, providerRef, redirectURL string) error {
 	_, err := r.db.ExecContext(ctx, `
-UPDATE payments SET state=?, provider_ref=?, updated_unix=? WHERE order_id=?;
-`, state, providerRef, time.Now().Unix(), orderID)
+UPDATE payments SET provider_ref=?, redirect_url=?, updated_unix=? WHERE order_id=? AND state=?;
+`, providerRef, redirectURL, time.Now().Unix(), orderID, PaymentStatePending)
 	return err
 }
 
-func (r *sqliteRepo) GetByOrderID(ctx context.Context, orderID int64) (*Payment, error) {
-	row := r.db.QueryRowContext(ctx, `
-SELECT order_id, amount_cents, state, provider_ref, updated_unix FROM payments WHERE order_id=?;
-`, orderID)
+func (r *sqliteRepo) SetDeferred(ctx context.Context, orderID int64, providerRef string) error {
+	_, err := r.db.ExecContext(ctx, `
+UPDATE payments SET state=?, provider_ref=?, updated_unix=? WHERE order_id=? AND state=?;
+`, PaymentStateAwaitingDelivery, providerRef, time.Now().Unix(), orderID, PaymentStatePending)
+	return err
+}
+
+// SetResult fija el resultado de un cobro PENDING (contra entrega o en
+// revisión); si fue exitoso registra la transacción CHARGE (una por la pasarela
+// y otra por la parte del monedero) y si falló devuelve al monedero lo que se
+// había descontado. Webhook, reconciliador y cobro síncrono pueden llegar a la vez:
+// solo el primero cambia el estado.
+func (r *sqliteRepo) SetResult(ctx context.Context, orderID int64, state PaymentState, providerRef, failReason string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	res, err := tx.ExecContext(ctx, `
+UPDATE payments SET state=?, provider_ref=?, redirect_url='', updated_unix=? WHERE order_id=? AND state IN (?,?,?);
+`, state, providerRef, now, orderID, PaymentStatePending, PaymentStateAwaitingDelivery, PaymentStateUnderReview)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	attempt := AttemptDeclined
+	if state == PaymentStateSucceeded {
+		attempt = AttemptApproved
+	}
+	if _, err := tx.ExecContext(ctx, `
+UPDATE payment_attempts SET state=?, provider_ref=?, fail_reason=?, updated_unix=? WHERE order_id=? AND state IN (?,?);
+`, attempt, providerRef, failReason, now, orderID, AttemptOpen, AttemptPending); err != nil {
+		return false, err
+	}
+	if state == PaymentStateSucceeded {
+		if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO payment_transactions(order_id, kind, amount_cents, provider_ref, reason, idem_key, created_unix)
+SELECT order_id, ?, amount_cents-wallet_cents, ?, '', 'charge:' || order_id, ? FROM payments
+WHERE order_id=? AND amount_cents>wallet_cents;
+INSERT OR IGNORE INTO payment_transactions(order_id, kind, amount_cents, provider_ref, reason, idem_key, store_credit, created_unix)
+SELECT order_id, ?, wallet_cents, ?, '', 'charge-wallet:' || order_id, 1, ? FROM payments
+WHERE order_id=? AND wallet_cents>0;
+`, TxCharge, providerRef, now, orderID, TxCharge, walletRef(orderID), now, orderID); err != nil {
+			return false, err
+		}
+	} else if err := releaseWallet(ctx, tx, orderID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// walletRef es la referencia de los movimientos del pago hechos con el monedero.
+func walletRef(orderID int64) string { return fmt.Sprintf("WALLET-%d", orderID) }
+
+// releaseWallet devuelve al monedero lo descontado para la orden que aún no se
+// había devuelto. Se calcula sobre el libro, así que repetirlo no acredita dos veces.
+func releaseWallet(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	var held, userID int64
+	if err := tx.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(amount_cents),0), COALESCE(MAX(user_id),0) FROM wallet_transactions WHERE order_id=? AND kind IN (?,?);
+`, orderID, WalletPayment, WalletRelease).Scan(&held, &userID); err != nil {
+		return err
+	}
+	if held >= 0 {
+		return nil
+	}
+	return applyWallet(ctx, tx, &WalletEntry{
+		UserID:      userID,
+		Kind:        WalletRelease,
+		AmountCents: -held,
+		OrderID:     orderID,
+		Reference:   fmt.Sprintf("orden #%d", orderID),
+	})
+}
+
+// chargeKey es la clave de idempotencia del intento n de la orden.
+func chargeKey(orderID int64, attempt int) string {
+	return fmt.Sprintf("charge-%d-%d", orderID, attempt)
+}
+
+const attemptColumns = `id, order_id, attempt, idem_key, amount_cents, state, provider_ref, fail_reason, retries, created_unix, updated_unix`
+
+func scanAttempt(row interface{ Scan(...any) error }) (*ChargeAttempt, error) {
+	var a ChargeAttempt
+	var created, updated int64
+	if err := row.Scan(&a.ID, &a.OrderID, &a.Attempt, &a.IdemKey, &a.AmountCents, &a.State,
+		&a.ProviderRef, &a.FailReason, &a.Retries, &created, &updated); err != nil {
+		return nil, err
+	}
+	a.CreatedAt, a.UpdatedAt = time.Unix(created, 0), time.Unix(updated, 0)
+	return &a, nil
+}
+
+func (r *sqliteRepo) OpenAttempt(ctx context.Context, orderID, amountCents int64) (*ChargeAttempt, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	last, err := scanAttempt(tx.QueryRowContext(ctx,
+		`SELECT `+attemptColumns+` FROM payment_attempts WHERE order_id=? ORDER BY attempt DESC LIMIT 1;`, orderID))
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		last = &ChargeAttempt{}
+	case err != nil:
+		return nil, err
+	case last.State == AttemptOpen || last.State == AttemptPending:
+		return last, nil
+	}
+
+	now := time.Now()
+	a := &ChargeAttempt{
+		OrderID:     orderID,
+		Attempt:     last.Attempt + 1,
+		AmountCents: amountCents,
+		State:       AttemptOpen,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	a.IdemKey = chargeKey(orderID, a.Attempt)
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO payment_attempts(order_id, attempt, idem_key, amount_cents, state, created_unix, updated_unix)
+VALUES(?,?,?,?,?,?,?);
+`, a.OrderID, a.Attempt, a.IdemKey, a.AmountCents, a.State, now.Unix(), now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	if a.ID, err = res.LastInsertId(); err != nil {
+		return nil, err
+	}
+	return a, tx.Commit()
+}
+
+func (r *sqliteRepo) UpdateAttempt(ctx context.Context, a *ChargeAttempt) error {
+	a.UpdatedAt = time.Now()
+	_, err := r.db.ExecContext(ctx, `
+UPDATE payment_attempts SET state=?, provider_ref=?, fail_reason=?, retries=?, updated_unix=? WHERE id=?;
+`, a.State, a.ProviderRef, a.FailReason, a.Retries, a.UpdatedAt.Unix(), a.ID)
+	return err
+}
+
+const paymentColumns = `order_id, user_id, amount_cents, method, instrument_id, wallet_cents, state, COALESCE(provider_ref,''), redirect_url, updated_unix`
+
+func scanPayment(row interface{ Scan(...any) error }) (*Payment, error) {
 	var p Payment
 	var updated int64
-	if err := row.Scan(&p.OrderID, &p.AmountCents, &p.State, &p.ProviderRef, &updated); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
+	if err := row.Scan(&p.OrderID, &p.UserID, &p.AmountCents, &p.Method, &p.InstrumentID, &p.WalletCents, &p.State,
+		&p.ProviderRef, &p.RedirectURL, &updated); err != nil {
 		return nil, err
 	}
 	p.UpdatedAt = time.Unix(updated, 0)
 	return &p, nil
 }
 
+func (r *sqliteRepo) GetByOrderID(ctx context.Context, orderID int64) (*Payment, error) {
+	p, err := scanPayment(r.db.QueryRowContext(ctx,
+		`SELECT `+paymentColumns+` FROM payments WHERE order_id=?;`, orderID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return p, err
+}
+
+func (r *sqliteRepo) ListPendingBefore(ctx context.Context, before time.Time) ([]Payment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+paymentColumns+` FROM payments WHERE state=? AND updated_unix<=? ORDER BY updated_unix;`,
+		PaymentStatePending, before.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Payment
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *p)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteRepo) RecordWebhook(ctx context.Context, eventID string, orderID int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+INSERT OR IGNORE INTO webhook_events(event_id, order_id, received_unix) VALUES(?,?,?);
+`, eventID, orderID, time.Now().Unix())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// ForgetWebhook borra la notificación para que un reintento de la pasarela se procese.
+func (r *sqliteRepo) ForgetWebhook(ctx context.Context, eventID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM webhook_events WHERE event_id=?;`, eventID)
+	return err
+}
+
+func (r *sqliteRepo) Balance(ctx context.Context, orderID int64) (int64, int64, error) {
+	return balance(ctx, r.db, orderID)
+}
+
+func (r *sqliteRepo) StoreCreditBalance(ctx context.Context, orderID int64) (captured, refunded int64, err error) {
+	err = r.db.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(CASE WHEN kind=? THEN amount_cents ELSE 0 END),0),
+       COALESCE(SUM(CASE WHEN kind<>? THEN amount_cents ELSE 0 END),0)
+FROM payment_transactions WHERE order_id=? AND store_credit=1;
+`, TxCharge, TxCharge, orderID).Scan(&captured, &refunded)
+	return captured, refunded, err
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func balance(ctx context.Context, q queryer, orderID int64) (captured, refunded int64, err error) {
+	err = q.QueryRowContext(ctx, `
+SELECT COALESCE(SUM(CASE WHEN kind=? THEN amount_cents ELSE 0 END),0),
+       COALESCE(SUM(CASE WHEN kind<>? THEN amount_cents ELSE 0 END),0)
+FROM payment_transactions WHERE order_id=?;
+`, TxCharge, TxCharge, orderID).Scan(&captured, &refunded)
+	return captured, refunded, err
+}
+
+func (r *sqliteRepo) AddRefund(ctx context.Context, t *Transaction) (PaymentState, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return PaymentStateUnspecified, err
+	}
+	defer tx.Rollback()
+
+	st, err := addRefund(ctx, tx, t)
+	if err != nil {
+		return st, err
+	}
+	return st, tx.Commit()
+}
+
+func (r *sqliteRepo) AddStoreCreditRefund(ctx context.Context, t *Transaction, userID int64) (PaymentState, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return PaymentStateUnspecified, err
+	}
+	defer tx.Rollback()
+
+	t.StoreCredit = true
+	st, err := addRefund(ctx, tx, t)
+	if err != nil {
+		return st, err
+	}
+	if err := applyWallet(ctx, tx, &WalletEntry{
+		UserID:      userID,
+		Kind:        WalletRefund,
+		AmountCents: t.AmountCents,
+		OrderID:     t.OrderID,
+		Reference:   fmt.Sprintf("orden #%d", t.OrderID),
+		IdemKey:     fmt.Sprintf("refund:%d", t.ID),
+	}); err != nil {
+		return st, err
+	}
+	return st, tx.Commit()
+}
+
+// addRefund valida el saldo del pago, inserta el reembolso y actualiza el estado.
+func addRefund(ctx context.Context, tx *sql.Tx, t *Transaction) (PaymentState, error) {
+	var st PaymentState
+	if err := tx.QueryRowContext(ctx, `SELECT state FROM payments WHERE order_id=?;`, t.OrderID).Scan(&st); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PaymentStateUnspecified, ErrNotCaptured
+		}
+		return PaymentStateUnspecified, err
+	}
+	if st != PaymentStateSucceeded && st != PaymentStatePartiallyRefunded {
+		return st, ErrNotCaptured
+	}
+	captured, refunded, err := balance(ctx, tx, t.OrderID)
+	if err != nil {
+		return st, err
+	}
+	remaining := captured - refunded
+	if t.AmountCents <= 0 || t.AmountCents > remaining {
+		return st, ErrRefundExceedsCapture
+	}
+
+	t.Kind, st = TxPartialRefund, PaymentStatePartiallyRefunded
+	if t.AmountCents == remaining {
+		t.Kind, st = TxRefund, PaymentStateRefunded
+	}
+	t.CreatedAt = time.Now()
+	var key any
+	if t.IdemKey != "" {
+		key = t.IdemKey
+	}
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO payment_transactions(order_id, kind, amount_cents, provider_ref, reason, idem_key, store_credit, created_unix)
+VALUES(?,?,?,?,?,?,?,?);
+`, t.OrderID, t.Kind, t.AmountCents, t.ProviderRef, t.Reason, key, t.StoreCredit, t.CreatedAt.Unix())
+	if err != nil {
+		return st, err
+	}
+	if t.ID, err = res.LastInsertId(); err != nil {
+		return st, err
+	}
+	if _, err = tx.ExecContext(ctx, `
+UPDATE payments SET state=?, updated_unix=? WHERE order_id=?;
+`, st, t.CreatedAt.Unix(), t.OrderID); err != nil {
+		return st, err
+	}
+	if st == PaymentStateRefunded {
+		_, err = tx.ExecContext(ctx, `
+UPDATE settlement_discrepancies SET resolved_unix=? WHERE kind=? AND order_id=? AND resolved_unix=0;
+`, t.CreatedAt.Unix(), DiscrepancyRefundFailed, t.OrderID)
+	}
+	return st, err
+}
+
+const txColumns = `id, order_id, kind, amount_cents, provider_ref, reason, COALESCE(idem_key,''), store_credit, created_unix`
+
+func scanTransaction(row interface{ Scan(...any) error }) (*Transaction, error) {
+	var t Transaction
+	var created int64
+	if err := row.Scan(&t.ID, &t.OrderID, &t.Kind, &t.AmountCents, &t.ProviderRef, &t.Reason, &t.IdemKey, &t.StoreCredit, &created); err != nil {
+		return nil, err
+	}
+	t.CreatedAt = time.Unix(created, 0)
+	return &t, nil
+}
+
+func (r *sqliteRepo) GetTransactionByKey(ctx context.Context, key string) (*Transaction, error) {
+	t, err := scanTransaction(r.db.QueryRowContext(ctx,
+		`SELECT `+txColumns+` FROM payment_transactions WHERE idem_key=?;`, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *sqliteRepo) ListTransactions(ctx context.Context, orderID int64) ([]Transaction, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+txColumns+` FROM payment_transactions WHERE order_id=? ORDER BY id;`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteRepo) queryTransactions(ctx context.Context, where string, args ...any) ([]Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+txColumns+` FROM payment_transactions WHERE `+where+` ORDER BY id;`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Transaction
+	for rows.Next() {
+		t, err := scanTransaction(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *t)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteRepo) TransactionsByRef(ctx context.Context, providerRef string) ([]Transaction, error) {
+	return r.queryTransactions(ctx, `provider_ref=?`, providerRef)
+}
+
+func (r *sqliteRepo) TransactionsBetween(ctx context.Context, from, to time.Time) ([]Transaction, error) {
+	return r.queryTransactions(ctx, `created_unix>=? AND created_unix<? AND store_credit=0
+  AND order_id NOT IN (SELECT order_id FROM payments WHERE method=?)`, from.Unix(), to.Unix(), MethodCashOnDelivery)
+}
+
+func (r *sqliteRepo) FindSettlementRun(ctx context.Context, checksum string) (*SettlementRun, error) {
+	run, err := scanSettlementRun(r.db.QueryRowContext(ctx,
+		`SELECT `+runColumns+` FROM settlement_runs WHERE checksum=?;`, checksum))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return run, err
+}
+
+func (r *sqliteRepo) IsSettled(ctx context.Context, providerRef string, refund bool) (bool, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM settlement_lines WHERE provider_ref=? AND refund=?;`, providerRef, refund).Scan(&n)
+	return n > 0, err
+}
+
+func (r *sqliteRepo) SaveSettlementRun(ctx context.Context, run *SettlementRun, lines []SettlementLine, found []Discrepancy) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	run.CreatedAt = time.Now()
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO settlement_runs(source, checksum, period_from, period_to, lines, matched, discrepancies, created_unix)
+VALUES(?,?,?,?,?,?,?,?);
+`, run.Source, run.Checksum, run.PeriodFrom, run.PeriodTo, run.Lines, run.Matched, run.Discrepancies, run.CreatedAt.Unix())
+	if err != nil {
+		return err
+	}
+	if run.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	for _, l := range lines {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO settlement_lines(run_id, settled_date, provider_ref, refund, amount_cents) VALUES(?,?,?,?,?);
+`, run.ID, l.Date, l.ProviderRef, l.Refund, l.AmountCents); err != nil {
+			return err
+		}
+		// Liquidado con retraso: resuelve el MISSING que dejó una corrida anterior
+		kindFilter := `tx_kind=?`
+		if l.Refund {
+			kindFilter = `tx_kind<>?`
+		}
+		if _, err := tx.ExecContext(ctx, `
+UPDATE settlement_discrepancies SET resolved_unix=?
+WHERE kind=? AND provider_ref=? AND `+kindFilter+` AND resolved_unix=0;
+`, run.CreatedAt.Unix(), DiscrepancyMissing, l.ProviderRef, TxCharge); err != nil {
+			return err
+		}
+	}
+	for _, d := range found {
+		if d.Kind == DiscrepancyMissing {
+			// Si un periodo se concilia otra vez, el MISSING que sigue abierto no se repite
+			var open int
+			if err := tx.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM settlement_discrepancies WHERE kind=? AND provider_ref=? AND tx_kind=? AND resolved_unix=0;
+`, DiscrepancyMissing, d.ProviderRef, d.TxKind).Scan(&open); err != nil {
+				return err
+			}
+			if open > 0 {
+				continue
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO settlement_discrepancies(run_id, kind, date, provider_ref, order_id, tx_kind, recorded_cents, settled_cents, note)
+VALUES(?,?,?,?,?,?,?,?,?);
+`, run.ID, d.Kind, d.Date, d.ProviderRef, d.OrderID, d.TxKind, d.RecordedCents, d.SettledCents, d.Note); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteRepo) ListDiscrepancies(ctx context.Context, from, to string, includeResolved bool) ([]Discrepancy, error) {
+	rows, err := r.db.QueryContext(ctx, `
+SELECT id, run_id, kind, date, provider_ref, order_id, tx_kind, recorded_cents, settled_cents, note, resolved_unix
+FROM settlement_discrepancies
+WHERE date>=? AND date<=? AND (? OR resolved_unix=0)
+ORDER BY date, id;
+`, from, to, includeResolved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Discrepancy
+	for rows.Next() {
+		var d Discrepancy
+		var resolved int64
+		if err := rows.Scan(&d.ID, &d.RunID, &d.Kind, &d.Date, &d.ProviderRef, &d.OrderID, &d.TxKind,
+			&d.RecordedCents, &d.SettledCents, &d.Note, &resolved); err != nil {
+			return nil, err
+		}
+		d.Resolved = resolved != 0
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteRepo) RecordDiscrepancy(ctx context.Context, d *Discrepancy) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `
+SELECT id FROM settlement_discrepancies WHERE kind=? AND order_id=? AND resolved_unix=0;
+`, d.Kind, d.OrderID).Scan(&d.ID)
+	if err == nil {
+		return nil // ya está a la vista de los operadores
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO settlement_discrepancies(run_id, kind, date, provider_ref, order_id, tx_kind, recorded_cents, settled_cents, note)
+VALUES(0,?,?,?,?,?,?,?,?);
+`, d.Kind, d.Date, d.ProviderRef, d.OrderID, d.TxKind, d.RecordedCents, d.SettledCents, d.Note)
+	if err != nil {
+		return err
+	}
+	if d.ID, err = res.LastInsertId(); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const runColumns = `id, source, checksum, period_from, period_to, lines, matched, discrepancies, created_unix`
+
+func scanSettlementRun(row interface{ Scan(...any) error }) (*SettlementRun, error) {
+	var run SettlementRun
+	var created int64
+	if err := row.Scan(&run.ID, &run.Source, &run.Checksum, &run.PeriodFrom, &run.PeriodTo,
+		&run.Lines, &run.Matched, &run.Discrepancies, &created); err != nil {
+		return nil, err
+	}
+	run.CreatedAt = time.Unix(created, 0)
+	return &run, nil
+}
+
+// ListSettlementRuns devuelve las corridas cuyo periodo se cruza con [from, to].
+func (r *sqliteRepo) ListSettlementRuns(ctx context.Context, from, to string) ([]SettlementRun, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+runColumns+` FROM settlement_runs WHERE period_to>=? AND period_from<=? ORDER BY id;`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []SettlementRun
+	for rows.Next() {
+		run, err := scanSettlementRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *run)
+	}
+	return out, rows.Err()
+}
+
+const instrumentColumns = `id, user_id, token, brand, last4, exp_month, exp_year, label, deleted_unix, created_unix`
+
+func scanInstrument(row interface{ Scan(...any) error }) (*Instrument, error) {
+	var in Instrument
+	var deleted, created int64
+	if err := row.Scan(&in.ID, &in.UserID, &in.Token, &in.Brand, &in.Last4, &in.ExpMonth, &in.ExpYear,
+		&in.Label, &deleted, &created); err != nil {
+		return nil, err
+	}
+	in.Deleted = deleted != 0
+	in.CreatedAt = time.Unix(created, 0)
+	return &in, nil
+}
+
+func (r *sqliteRepo) SaveInstrument(ctx context.Context, in *Instrument) (*Instrument, error) {
+	// Volver a guardar el token restaura la tarjeta y actualiza sus datos visibles
+	if _, err := r.db.ExecContext(ctx, `
+INSERT INTO payment_instruments(user_id, token, brand, last4, exp_month, exp_year, label, created_unix)
+VALUES(?,?,?,?,?,?,?,?)
+ON CONFLICT(user_id, token) DO UPDATE SET
+  brand=excluded.brand,
+  last4=excluded.last4,
+  exp_month=excluded.exp_month,
+  exp_year=excluded.exp_year,
+  label=excluded.label,
+  deleted_unix=0;
+`, in.UserID, in.Token, in.Brand, in.Last4, in.ExpMonth, in.ExpYear, in.Label, time.Now().Unix()); err != nil {
+		return nil, err
+	}
+	return scanInstrument(r.db.QueryRowContext(ctx,
+		`SELECT `+instrumentColumns+` FROM payment_instruments WHERE user_id=? AND token=?;`, in.UserID, in.Token))
+}
+
+func (r *sqliteRepo) GetInstrument(ctx context.Context, id int64) (*Instrument, error) {
+	in, err := scanInstrument(r.db.QueryRowContext(ctx,
+		`SELECT `+instrumentColumns+` FROM payment_instruments WHERE id=?;`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return in, err
+}
+
+func (r *sqliteRepo) ListInstruments(ctx context.Context, userID int64) ([]Instrument, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+instrumentColumns+` FROM payment_instruments WHERE user_id=? AND deleted_unix=0 ORDER BY id DESC;`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Instrument
+	for rows.Next() {
+		in, err := scanInstrument(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *in)
+	}
+	return out, rows.Err()
+}
+
+// DeleteInstrument solo marca la tarjeta: un cobro en curso que la usa puede
+// necesitar el token para reintentar con la misma clave.
+func (r *sqliteRepo) DeleteInstrument(ctx context.Context, userID, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE payment_instruments SET deleted_unix=? WHERE id=? AND user_id=? AND deleted_unix=0;`,
+		time.Now().Unix(), id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *sqliteRepo) WalletBalance(ctx context.Context, userID int64) (int64, error) {
+	var bal int64
+	err := r.db.QueryRowContext(ctx, `SELECT balance_cents FROM wallet_accounts WHERE user_id=?;`, userID).Scan(&bal)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return bal, err
+}
+
+const walletColumns = `id, user_id, kind, amount_cents, balance_after, order_id, reference, COALESCE(idem_key,''), created_unix`
+
+func scanWalletEntry(row interface{ Scan(...any) error }) (*WalletEntry, error) {
+	var e WalletEntry
+	var created int64
+	if err := row.Scan(&e.ID, &e.UserID, &e.Kind, &e.AmountCents, &e.BalanceAfter, &e.OrderID,
+		&e.Reference, &e.IdemKey, &created); err != nil {
+		return nil, err
+	}
+	e.CreatedAt = time.Unix(created, 0)
+	return &e, nil
+}
+
+// walletEntryByKey devuelve (nil, nil) si la clave no se ha usado.
+func walletEntryByKey(ctx context.Context, q queryer, key string) (*WalletEntry, error) {
+	e, err := scanWalletEntry(q.QueryRowContext(ctx,
+		`SELECT `+walletColumns+` FROM wallet_transactions WHERE idem_key=?;`, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return e, err
+}
+
+// applyWallet ajusta el saldo y registra el movimiento dentro de tx. El débito
+// lleva la condición en el mismo UPDATE: dos débitos concurrentes nunca dejan
+// el saldo en negativo (y el CHECK de la tabla lo respalda).
+func applyWallet(ctx context.Context, tx *sql.Tx, e *WalletEntry) error {
+	e.CreatedAt = time.Now()
+	now := e.CreatedAt.Unix()
+	if _, err := tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO wallet_accounts(user_id, balance_cents, updated_unix) VALUES(?,0,?);
+`, e.UserID, now); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+UPDATE wallet_accounts SET balance_cents=balance_cents+?, updated_unix=? WHERE user_id=? AND balance_cents+?>=0;
+`, e.AmountCents, now, e.UserID, e.AmountCents)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrInsufficientFunds
+	}
+	if err := tx.QueryRowContext(ctx,
+		`SELECT balance_cents FROM wallet_accounts WHERE user_id=?;`, e.UserID).Scan(&e.BalanceAfter); err != nil {
+		return err
+	}
+	var key any
+	if e.IdemKey != "" {
+		key = e.IdemKey
+	}
+	res, err = tx.ExecContext(ctx, `
+INSERT INTO wallet_transactions(user_id, kind, amount_cents, balance_after, order_id, reference, idem_key, created_unix)
+VALUES(?,?,?,?,?,?,?,?);
+`, e.UserID, e.Kind, e.AmountCents, e.BalanceAfter, e.OrderID, e.Reference, key, now)
+	if err != nil {
+		return err
+	}
+	e.ID, err = res.LastInsertId()
+	return err
+}
+
+func (r *sqliteRepo) ApplyWallet(ctx context.Context, e *WalletEntry) (*WalletEntry, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if e.IdemKey != "" {
+		prev, err := walletEntryByKey(ctx, tx, e.IdemKey)
+		if err != nil || prev != nil {
+			return prev, err
+		}
+	}
+	if err := applyWallet(ctx, tx, e); err != nil {
+		return nil, err
+	}
+	return e, tx.Commit()
+}
+
+func (r *sqliteRepo) ListWalletEntries(ctx context.Context, userID int64, limit int) ([]WalletEntry, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+walletColumns+` FROM wallet_transactions WHERE user_id=? ORDER BY id DESC LIMIT ?;`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []WalletEntry
+	for rows.Next() {
+		e, err := scanWalletEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *e)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteRepo) CreateGiftCard(ctx context.Context, g *GiftCard) error {
+	g.CreatedAt = time.Now()
+	var expires int64
+	if !g.ExpiresAt.IsZero() {
+		expires = g.ExpiresAt.Unix()
+	}
+	g.BalanceCents = g.InitialCents
+	_, err := r.db.ExecContext(ctx, `
+INSERT INTO gift_cards(code, initial_cents, balance_cents, expires_unix, created_unix) VALUES(?,?,?,?,?);
+`, g.Code, g.InitialCents, g.BalanceCents, expires, g.CreatedAt.Unix())
+	return err
+}
+
+func (r *sqliteRepo) RedeemGiftCard(ctx context.Context, userID int64, code string) (*WalletEntry, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var balance, redeemedBy, expires int64
+	err = tx.QueryRowContext(ctx,
+		`SELECT balance_cents, redeemed_by, expires_unix FROM gift_cards WHERE code=?;`, code).Scan(&balance, &redeemedBy, &expires)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrGiftCardNotFound
+	case err != nil:
+		return nil, err
+	case redeemedBy == userID:
+		return walletEntryByKey(ctx, tx, "giftcard:"+code)
+	case redeemedBy != 0:
+		return nil, ErrGiftCardRedeemed
+	case expires != 0 && expires <= time.Now().Unix():
+		return nil, ErrGiftCardExpired
+	}
+
+	// Solo una redención gana aunque lleguen dos a la vez: la otra no encuentra redeemed_by=0
+	res, err := tx.ExecContext(ctx, `
+UPDATE gift_cards SET balance_cents=0, redeemed_by=?, redeemed_unix=? WHERE code=? AND redeemed_by=0;
+`, userID, time.Now().Unix(), code)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrGiftCardRedeemed
+	}
+	e := &WalletEntry{
+		UserID:      userID,
+		Kind:        WalletGiftCard,
+		AmountCents: balance,
+		Reference:   maskGiftCard(code),
+		IdemKey:     "giftcard:" + code,
+	}
+	if err := applyWallet(ctx, tx, e); err != nil {
+		return nil, err
+	}
+	return e, tx.Commit()
+}
+
+const riskColumns = `id, order_id, user_id, amount_cents, score, decision, reasons, review_state, reviewer, review_note, created_unix, reviewed_unix`
+
+func scanRiskAssessment(row interface{ Scan(...any) error }) (*RiskAssessment, error) {
+	var a RiskAssessment
+	var reasons string
+	var created, reviewed int64
+	if err := row.Scan(&a.ID, &a.OrderID, &a.UserID, &a.AmountCents, &a.Score, &a.Decision, &reasons,
+		&a.ReviewState, &a.Reviewer, &a.ReviewNote, &created, &reviewed); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(reasons), &a.Reasons); err != nil {
+		return nil, fmt.Errorf("risk assessment %d: %w", a.ID, err)
+	}
+	a.CreatedAt = time.Unix(created, 0)
+	if reviewed != 0 {
+		a.ReviewedAt = time.Unix(reviewed, 0)
+	}
+	return &a, nil
+}
+
+func (r *sqliteRepo) SaveRiskAssessment(ctx context.Context, a *RiskAssessment) (bool, error) {
+	reasons, err := json.Marshal(a.Reasons)
+	if err != nil {
+		return false, err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	a.CreatedAt = time.Now()
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO risk_assessments(order_id, user_id, amount_cents, score, decision, reasons, review_state, created_unix)
+VALUES(?,?,?,?,?,?,?,?);
+`, a.OrderID, a.UserID, a.AmountCents, a.Score, a.Decision, string(reasons), a.ReviewState, a.CreatedAt.Unix())
+	if err != nil {
+		return false, err
+	}
+	if a.ID, err = res.LastInsertId(); err != nil {
+		return false, err
+	}
+	state := PaymentStatePending
+	if a.Decision == RiskReview {
+		state = PaymentStateUnderReview
+	}
+	res, err = tx.ExecContext(ctx, `
+UPDATE payments SET risk_id=?, state=?, updated_unix=? WHERE order_id=? AND state=? AND risk_id=0;
+`, a.ID, state, a.CreatedAt.Unix(), a.OrderID, PaymentStatePending)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *sqliteRepo) GetRiskAssessment(ctx context.Context, orderID int64) (*RiskAssessment, error) {
+	a, err := scanRiskAssessment(r.db.QueryRowContext(ctx, `
+SELECT `+riskColumns+` FROM risk_assessments WHERE id=(SELECT risk_id FROM payments WHERE order_id=?);
+`, orderID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return a, err
+}
+
+func (r *sqliteRepo) ResolveReview(ctx context.Context, orderID int64, approve bool, reviewer, note string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	state := ReviewRejected
+	if approve {
+		state = ReviewApproved
+	}
+	now := time.Now().Unix()
+	res, err := tx.ExecContext(ctx, `
+UPDATE risk_assessments SET review_state=?, reviewer=?, review_note=?, reviewed_unix=?
+WHERE id=(SELECT risk_id FROM payments WHERE order_id=?) AND review_state=?;
+`, state, reviewer, note, now, orderID, ReviewPending)
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+	// Al rechazar el pago sigue UNDER_REVIEW hasta que settle lo marque FAILED
+	if approve {
+		if _, err := tx.ExecContext(ctx, `
+UPDATE payments SET state=?, updated_unix=? WHERE order_id=? AND state=?;
+`, PaymentStatePending, now, orderID, PaymentStateUnderReview); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+func (r *sqliteRepo) ListRiskReviews(ctx context.Context, includeResolved bool, limit int) ([]RiskAssessment, error) {
+	q := `SELECT ` + riskColumns + ` FROM risk_assessments WHERE decision=?`
+	args := []any{RiskReview}
+	if !includeResolved {
+		q += ` AND review_state=?`
+		args = append(args, ReviewPending)
+	}
+	rows, err := r.db.QueryContext(ctx, q+` ORDER BY id LIMIT ?;`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []RiskAssessment
+	for rows.Next() {
+		a, err := scanRiskAssessment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *a)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteRepo) CountRiskAssessmentsSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM risk_assessments WHERE user_id=? AND created_unix>=?;`, userID, since.Unix()).Scan(&n)
+	return n, err
+}
+
+func (r *sqliteRepo) CountDeclinedSince(ctx context.Context, userID int64, since time.Time) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+SELECT
+  (SELECT COUNT(*) FROM payment_attempts a JOIN payments p ON p.order_id=a.order_id
+   WHERE p.user_id=? AND a.state=? AND a.updated_unix>=?)
++ (SELECT COUNT(*) FROM risk_assessments
+   WHERE user_id=? AND created_unix>=? AND (decision=? OR review_state=?));
+`, userID, AttemptDeclined, since.Unix(), userID, since.Unix(), RiskDecline, ReviewRejected).Scan(&n)
+	return n, err
+}
+
+func (r *sqliteRepo) HasPaidOrder(ctx context.Context, userID, exceptOrderID int64) (bool, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM payments WHERE user_id=? AND order_id<>? AND state IN (?,?,?);
+`, userID, exceptOrderID, PaymentStateSucceeded, PaymentStateRefunded, PaymentStatePartiallyRefunded).Scan(&n)
+	return n > 0, err
+}
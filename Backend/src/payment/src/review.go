@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Revisión manual de los pagos que el análisis de riesgo dejó UNDER_REVIEW.
+
+func (s *service) ListRiskReviews(ctx context.Context, req *paymentpb.ListRiskReviewsRequest) (*paymentpb.ListRiskReviewsResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	as, err := s.repo.ListRiskReviews(ctx, req.IncludeResolved, limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &paymentpb.ListRiskReviewsResponse{Reviews: make([]*paymentpb.RiskAssessment, 0, len(as))}
+	for i := range as {
+		resp.Reviews = append(resp.Reviews, toPBRiskAssessment(&as[i]))
+	}
+	return resp, nil
+}
+
+func (s *service) ApproveReview(ctx context.Context, req *paymentpb.ReviewDecisionRequest) (*paymentpb.RiskAssessment, error) {
+	return s.resolveReview(ctx, req, true)
+}
+
+func (s *service) RejectReview(ctx context.Context, req *paymentpb.ReviewDecisionRequest) (*paymentpb.RiskAssessment, error) {
+	return s.resolveReview(ctx, req, false)
+}
+
+// resolveReview registra la decisión y la aplica: al aprobar se cobra, al
+// rechazar el pago falla. Repetir la misma decisión completa lo que haya
+// quedado a medias (p.ej. el cobro no alcanzó a salir) sin cobrar dos veces.
+func (s *service) resolveReview(ctx context.Context, req *paymentpb.ReviewDecisionRequest, approve bool) (*paymentpb.RiskAssessment, error) {
+	reviewer := strings.TrimSpace(req.Reviewer)
+	if req.OrderId == 0 || reviewer == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_id y reviewer requeridos")
+	}
+	a, err := s.repo.GetRiskAssessment(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil || a.Decision != RiskReview {
+		return nil, status.Error(codes.NotFound, "la orden no tiene un pago en revisión")
+	}
+	want := ReviewRejected
+	if approve {
+		want = ReviewApproved
+	}
+	if a.ReviewState != ReviewPending && a.ReviewState != want {
+		return nil, status.Errorf(codes.FailedPrecondition, "la revisión ya se resolvió (%s)",
+			paymentpb.ReviewState(a.ReviewState).String())
+	}
+	if a.ReviewState == ReviewPending {
+		if _, err := s.repo.ResolveReview(ctx, req.OrderId, approve, reviewer, strings.TrimSpace(req.Note)); err != nil {
+			return nil, err
+		}
+		slog.InfoContext(ctx, "REVIEW", "order_id", req.OrderId, "approve", approve, "reviewer", reviewer)
+	}
+
+	p, err := s.repo.GetByOrderID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p == nil:
+	case approve && p.State == PaymentStatePending && p.ProviderRef == "":
+		// Si la pasarela no responde el pago queda PENDING y lo retoma el reconciliador
+		if err := s.charge(ctx, p); err != nil {
+			slog.WarnContext(ctx, "review", "order_id", p.OrderID, "err", err)
+		}
+	case !approve && p.State == PaymentStateUnderReview:
+		if err := s.settle(ctx, p.OrderID, ChargeResult{Status: ChargeDeclined, FailReason: "risk_rejected"}, "review"); err != nil {
+			return nil, err
+		}
+	}
+
+	if a, err = s.repo.GetRiskAssessment(ctx, req.OrderId); err != nil {
+		return nil, err
+	}
+	return toPBRiskAssessment(a), nil
+}
+
+func toPBRiskAssessment(a *RiskAssessment) *paymentpb.RiskAssessment {
+	out := &paymentpb.RiskAssessment{
+		AssessmentId: a.ID,
+		OrderId:      a.OrderID,
+		UserId:       a.UserID,
+		Amount:       &commonpb.Money{Cents: a.AmountCents},
+		Score:        int32(a.Score),
+		Decision:     paymentpb.RiskDecision(a.Decision),
+		Reasons:      a.Reasons,
+		ReviewState:  paymentpb.ReviewState(a.ReviewState),
+		Reviewer:     a.Reviewer,
+		ReviewNote:   a.ReviewNote,
+		CreatedUnix:  a.CreatedAt.Unix(),
+	}
+	if !a.ReviewedAt.IsZero() {
+		out.ReviewedUnix = a.ReviewedAt.Unix()
+	}
+	return out
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Análisis de riesgo: antes de ir a la pasarela cada pago se evalúa y queda
+// aprobado, en revisión manual (UNDER_REVIEW hasta ApproveReview/RejectReview)
+// o rechazado. La evaluación se guarda con sus motivos y se reutiliza en los
+// reintentos del mismo pago.
+
+// RiskEvaluator decide qué hacer con un pago. Devuelve error solo si no pudo
+// evaluarlo (p.ej. la base no respondió); el mensaje se reencola.
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, p *Payment) (*RiskAssessment, error)
+}
+
+// RiskHistory es lo que las reglas consultan del historial del usuario.
+type RiskHistory interface {
+	// CountRiskAssessmentsSince cuenta los pagos evaluados del usuario desde since.
+	CountRiskAssessmentsSince(ctx context.Context, userID int64, since time.Time) (int, error)
+	// CountDeclinedSince cuenta los cobros rechazados por la pasarela y los
+	// pagos rechazados por riesgo del usuario desde since.
+	CountDeclinedSince(ctx context.Context, userID int64, since time.Time) (int, error)
+	// HasPaidOrder indica si el usuario ya pagó alguna otra orden.
+	HasPaidOrder(ctx context.Context, userID, exceptOrderID int64) (bool, error)
+}
+
+// RiskEvaluatorFactory crea un evaluador a partir de la configuración.
+type RiskEvaluatorFactory func(cfg Config, hist RiskHistory) (RiskEvaluator, error)
+
+// riskEvaluators se llena desde el init() de cada implementación.
+var riskEvaluators = map[string]RiskEvaluatorFactory{}
+
+func registerRiskEvaluator(name string, f RiskEvaluatorFactory) {
+	if _, dup := riskEvaluators[name]; dup {
+		panic("risk evaluator registrado dos veces: " + name)
+	}
+	riskEvaluators[name] = f
+}
+
+// newRiskEvaluator crea el evaluador de PAYMENT_RISK_ENGINE; "off" devuelve nil
+// y los pagos van directo a la pasarela.
+func newRiskEvaluator(cfg Config, hist RiskHistory) (RiskEvaluator, error) {
+	if cfg.RiskEngine == "off" {
+		return nil, nil
+	}
+	f, ok := riskEvaluators[cfg.RiskEngine]
+	if !ok {
+		names := make([]string, 0, len(riskEvaluators))
+		for n := range riskEvaluators {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("risk engine %q desconocido (disponibles: off, %s)", cfg.RiskEngine, strings.Join(names, ", "))
+	}
+	return f(cfg, hist)
+}
+
+func init() {
+	registerRiskEvaluator("rules", func(cfg Config, hist RiskHistory) (RiskEvaluator, error) {
+		rules, err := loadRiskRules(cfg.RiskRulesPath)
+		if err != nil {
+			return nil, err
+		}
+		return &ruleEvaluator{rules: rules, hist: hist, now: time.Now}, nil
+	})
+}
+
+// RiskRules son las reglas del evaluador "rules" (PAYMENT_RISK_RULES_PATH,
+// JSON). Cada regla que se cumple suma su puntaje; con ReviewAt o más el pago
+// va a revisión y con DeclineAt o más se rechaza.
+type RiskRules struct {
+	ReviewAt      int            `json:"review_at"`
+	DeclineAt     int            `json:"decline_at"`
+	ExemptMethods []string       `json:"exempt_methods"` // nombres de PAYMENT_METHOD_PROVIDERS
+	Velocity      []VelocityRule `json:"velocity"`
+	Amount        []AmountRule   `json:"amount"`
+	FirstOrder    *AmountRule    `json:"first_order"` // el usuario no ha pagado otra orden
+	Failures      []FailureRule  `json:"failures"`
+}
+
+// VelocityRule: más de MaxPayments pagos del usuario en la ventana.
+type VelocityRule struct {
+	WindowMinutes int `json:"window_minutes"`
+	MaxPayments   int `json:"max_payments"`
+	Score         int `json:"score"`
+}
+
+// AmountRule: el total del pago supera OverCents.
+type AmountRule struct {
+	OverCents int64 `json:"over_cents"`
+	Score     int   `json:"score"`
+}
+
+// FailureRule: al menos MinFailures pagos rechazados del usuario en la ventana.
+type FailureRule struct {
+	WindowMinutes int `json:"window_minutes"`
+	MinFailures   int `json:"min_failures"`
+	Score         int `json:"score"`
+}
+
+// defaultRiskRules: revisión para compras grandes, primeras compras altas y
+// ráfagas de pagos; rechazo cuando se acumulan varias señales. El saldo a
+// favor no se evalúa (ya es dinero de la tienda).
+func defaultRiskRules() *RiskRules {
+	return &RiskRules{
+		ReviewAt:      50,
+		DeclineAt:     100,
+		ExemptMethods: []string{"store_credit"},
+		Velocity: []VelocityRule{
+			{WindowMinutes: 10, MaxPayments: 3, Score: 50},
+			{WindowMinutes: 24 * 60, MaxPayments: 10, Score: 50},
+		},
+		Amount: []AmountRule{
+			{OverCents: 1000000, Score: 50},
+			{OverCents: 3000000, Score: 50},
+		},
+		FirstOrder: &AmountRule{OverCents: 500000, Score: 30},
+		Failures: []FailureRule{
+			{WindowMinutes: 24 * 60, MinFailures: 3, Score: 30},
+			{WindowMinutes: 24 * 60, MinFailures: 5, Score: 70},
+		},
+	}
+}
+
+// loadRiskRules lee las reglas de un archivo JSON con la forma de RiskRules;
+// path vacío devuelve defaultRiskRules().
+func loadRiskRules(path string) (*RiskRules, error) {
+	if path == "" {
+		return defaultRiskRules(), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("risk rules: %w", err)
+	}
+	var r RiskRules
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("risk rules: %s: %w", path, err)
+	}
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("risk rules: %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+func (r *RiskRules) validate() error {
+	if r.ReviewAt <= 0 || r.DeclineAt < r.ReviewAt {
+		return fmt.Errorf("se requiere 0 < review_at <= decline_at (review_at=%d, decline_at=%d)", r.ReviewAt, r.DeclineAt)
+	}
+	for _, m := range r.ExemptMethods {
+		if _, ok := methodNames[m]; !ok {
+			return fmt.Errorf("exempt_methods: medio de pago %q desconocido", m)
+		}
+	}
+	for _, v := range r.Velocity {
+		if v.WindowMinutes <= 0 {
+			return fmt.Errorf("velocity: window_minutes debe ser mayor que cero")
+		}
+	}
+	for _, f := range r.Failures {
+		if f.WindowMinutes <= 0 || f.MinFailures <= 0 {
+			return fmt.Errorf("failures: window_minutes y min_failures deben ser mayores que cero")
+		}
+	}
+	return nil
+}
+
+type ruleEvaluator struct {
+	rules *RiskRules
+	hist  RiskHistory
+	now   func() time.Time
+}
+
+func (e *ruleEvaluator) Evaluate(ctx context.Context, p *Payment) (*RiskAssessment, error) {
+	a := &RiskAssessment{OrderID: p.OrderID, UserID: p.UserID, AmountCents: p.AmountCents, Reasons: []string{}}
+	add := func(score int, format string, args ...any) {
+		a.Score += score
+		a.Reasons = append(a.Reasons, fmt.Sprintf(format, args...)+fmt.Sprintf(" (+%d)", score))
+	}
+
+	for _, m := range e.rules.ExemptMethods {
+		if methodNames[m] == p.Method {
+			a.Decision = RiskApprove
+			a.Reasons = append(a.Reasons, "medio exento: "+m)
+			return a, nil
+		}
+	}
+	for _, r := range e.rules.Amount {
+		if p.AmountCents > r.OverCents {
+			add(r.Score, "monto mayor a %d", r.OverCents)
+		}
+	}
+	// Las reglas por usuario no aplican a los pagos anteriores a user_id
+	if p.UserID != 0 {
+		now := e.now()
+		for _, r := range e.rules.Velocity {
+			n, err := e.hist.CountRiskAssessmentsSince(ctx, p.UserID, now.Add(-time.Duration(r.WindowMinutes)*time.Minute))
+			if err != nil {
+				return nil, err
+			}
+			if n+1 > r.MaxPayments {
+				add(r.Score, "%d pagos en %s", n+1, window(r.WindowMinutes))
+			}
+		}
+		for _, r := range e.rules.Failures {
+			n, err := e.hist.CountDeclinedSince(ctx, p.UserID, now.Add(-time.Duration(r.WindowMinutes)*time.Minute))
+			if err != nil {
+				return nil, err
+			}
+			if n >= r.MinFailures {
+				add(r.Score, "%d pagos rechazados en %s", n, window(r.WindowMinutes))
+			}
+		}
+		if r := e.rules.FirstOrder; r != nil && p.AmountCents > r.OverCents {
+			paid, err := e.hist.HasPaidOrder(ctx, p.UserID, p.OrderID)
+			if err != nil {
+				return nil, err
+			}
+			if !paid {
+				add(r.Score, "primera compra mayor a %d", r.OverCents)
+			}
+		}
+	}
+
+	switch {
+	case a.Score >= e.rules.DeclineAt:
+		a.Decision = RiskDecline
+	case a.Score >= e.rules.ReviewAt:
+		a.Decision = RiskReview
+		a.ReviewState = ReviewPending
+	default:
+		a.Decision = RiskApprove
+	}
+	return a, nil
+}
+
+// window escribe la ventana como la leería una persona: 10m, 24h.
+func window(minutes int) string {
+	d := time.Duration(minutes) * time.Minute
+	if d%time.Hour == 0 {
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+// screen evalúa el pago antes del cobro y devuelve true si puede seguir a la
+// pasarela; si no, el pago quedó rechazado o esperando la revisión manual. Los
+// redeliveries y el reconciliador reutilizan la evaluación guardada.
+func (s *service) screen(ctx context.Context, p *Payment) (bool, error) {
+	if s.risk == nil {
+		return true, nil
+	}
+	a, err := s.repo.GetRiskAssessment(ctx, p.OrderID)
+	if err != nil {
+		return false, err
+	}
+	if a == nil {
+		if a, err = s.risk.Evaluate(ctx, p); err != nil {
+			return false, err
+		}
+		saved, err := s.repo.SaveRiskAssessment(ctx, a)
+		if err != nil {
+			return false, err
+		}
+		if !saved {
+			// Otra entrega lo evaluó primero (o el pago ya no está PENDING)
+			if a, err = s.repo.GetRiskAssessment(ctx, p.OrderID); err != nil || a == nil {
+				return false, err
+			}
+		} else if a.Decision != RiskApprove {
+			slog.InfoContext(ctx, "RISK "+strings.ToUpper(a.Decision.String()), "order_id", a.OrderID, "user_id", a.UserID,
+				"score", a.Score, "reasons", strings.Join(a.Reasons, "; "))
+		}
+	}
+
+	switch {
+	case a.Decision == RiskDecline:
+		return false, s.settle(ctx, p.OrderID, ChargeResult{Status: ChargeDeclined, FailReason: "risk_declined"}, "risk")
+	case a.Decision == RiskReview && a.ReviewState == ReviewRejected:
+		return false, s.settle(ctx, p.OrderID, ChargeResult{Status: ChargeDeclined, FailReason: "risk_rejected"}, "review")
+	case a.Decision == RiskReview && a.ReviewState == ReviewPending:
+		return false, nil // lo decide ApproveReview / RejectReview
+	}
+	return true, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Pruebas del análisis de riesgo: las reglas por defecto deciden aprobar,
+// revisar o rechazar, y un pago en revisión no llega a la pasarela hasta que
+// alguien lo aprueba.
+
+func (h *harness) withRisk(rules *RiskRules) {
+	h.svc.risk = &ruleEvaluator{rules: rules, hist: h.repo, now: time.Now}
+}
+
+func TestRiskDecisions(t *testing.T) {
+	h := newHarness(t)
+	h.withRisk(defaultRiskRules())
+
+	h.deliver(t, 1, 1500000) // monto alto: revisión
+	h.deliver(t, 2, 3500000) // monto muy alto: rechazo
+	h.deliver(t, 3, 600000)  // primera compra de 600000: suma 30, se aprueba
+
+	for _, c := range []struct {
+		order    int64
+		decision RiskDecision
+		state    PaymentState
+	}{
+		{1, RiskReview, PaymentStateUnderReview},
+		{2, RiskDecline, PaymentStateFailed},
+		{3, RiskApprove, PaymentStateSucceeded},
+	} {
+		a, err := h.repo.GetRiskAssessment(context.Background(), c.order)
+		if err != nil || a == nil {
+			t.Fatalf("order %d: evaluación = %v, %v", c.order, a, err)
+		}
+		if a.Decision != c.decision || len(a.Reasons) == 0 {
+			t.Errorf("order %d: decisión = %s %v, se esperaba %s", c.order, a.Decision, a.Reasons, c.decision)
+		}
+		if p := h.payment(t, c.order); p.State != c.state {
+			t.Errorf("order %d: estado = %s, se esperaba %s", c.order, p.State, c.state)
+		}
+	}
+	if len(h.bank.keys) != 1 {
+		t.Fatalf("cobros en el banco: %v; solo la orden aprobada debía llegar", h.bank.keys)
+	}
+
+	// Cuarto pago del usuario en 10 minutos: velocidad
+	h.deliver(t, 4, 1000)
+	if a, _ := h.repo.GetRiskAssessment(context.Background(), 4); a == nil || a.Decision != RiskReview {
+		t.Fatalf("evaluación por velocidad = %+v", a)
+	}
+}
+
+func TestReviewApproveCharges(t *testing.T) {
+	h := newHarness(t)
+	h.withRisk(defaultRiskRules())
+	h.deliver(t, 5, 1500000)
+
+	// El redelivery no cobra ni evalúa de nuevo
+	h.deliver(t, 5, 1500000)
+	if len(h.bank.keys) != 0 {
+		t.Fatalf("se llamó a la pasarela antes de la revisión: %v", h.bank.keys)
+	}
+	reviews, err := h.svc.ListRiskReviews(context.Background(), &paymentpb.ListRiskReviewsRequest{})
+	if err != nil || len(reviews.Reviews) != 1 || reviews.Reviews[0].OrderId != 5 {
+		t.Fatalf("revisiones pendientes = %v, %v", reviews, err)
+	}
+
+	req := &paymentpb.ReviewDecisionRequest{OrderId: 5, Reviewer: "ana", Note: "cliente verificado"}
+	for i := 0; i < 2; i++ { // aprobar dos veces no cobra dos veces
+		a, err := h.svc.ApproveReview(context.Background(), req)
+		if err != nil || a.ReviewState != paymentpb.ReviewState_REVIEW_STATE_APPROVED || a.Reviewer != "ana" {
+			t.Fatalf("ApproveReview = %+v, %v", a, err)
+		}
+	}
+	if p := h.payment(t, 5); p.State != PaymentStateSucceeded {
+		t.Fatalf("estado = %s, se esperaba SUCCEEDED", p.State)
+	}
+	if h.bank.charges != 1 || h.pub.count("payment.succeeded") != 1 {
+		t.Fatalf("cobros = %d, payment.succeeded = %d", h.bank.charges, h.pub.count("payment.succeeded"))
+	}
+
+	_, err = h.svc.RejectReview(context.Background(), req)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("RejectReview tras aprobar = %v, se esperaba FailedPrecondition", err)
+	}
+}
+
+func TestReviewRejectFailsPayment(t *testing.T) {
+	h := newHarness(t)
+	h.withRisk(defaultRiskRules())
+	h.deliver(t, 6, 1500000)
+
+	a, err := h.svc.RejectReview(context.Background(), &paymentpb.ReviewDecisionRequest{OrderId: 6, Reviewer: "ana"})
+	if err != nil || a.ReviewState != paymentpb.ReviewState_REVIEW_STATE_REJECTED {
+		t.Fatalf("RejectReview = %+v, %v", a, err)
+	}
+	if p := h.payment(t, 6); p.State != PaymentStateFailed {
+		t.Fatalf("estado = %s, se esperaba FAILED", p.State)
+	}
+	if len(h.bank.keys) != 0 || h.pub.count("payment.failed") != 1 {
+		t.Fatalf("cobros = %v, payment.failed = %d", h.bank.keys, h.pub.count("payment.failed"))
+	}
+
+	// Si la orden vuelve a pedir el cobro se evalúa otra vez desde cero
+	h.deliver(t, 6, 1500000)
+	if a, _ := h.repo.GetRiskAssessment(context.Background(), 6); a == nil || a.ReviewState != ReviewPending {
+		t.Fatalf("evaluación del reintento = %+v", a)
+	}
+}
@@ -2,39 +2,168 @@ package main
 
 import (
 	"context"
+	"errors"
+	"sync"
 
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/mtls"
 	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
 type service struct {
 	paymentpb.UnimplementedPaymentServer
-	cfg      Config
-	repo     Repository
-	provider PaymentProvider
-	br       *broker
+	cfg       Config
+	repo      Repository
+	providers providerRoutes
+	risk      RiskEvaluator // nil = sin análisis de riesgo
+	br        publisher
+	refundMu  sync.Mutex
+}
+
+// publisher publica los eventos de Payment; en producción es *broker.
+type publisher interface {
+	publishJSON(ctx context.Context, key string, v any)
+}
+
+// adminRules: los RPCs de gestión exigen rol admin (ver cli.go); los de cada
+// usuario validan la propiedad en el handler con auth.CheckUser.
+var adminRules = auth.Rules{Admin: []string{
+	"/payment.Payment/RefundPayment",
+	"/payment.Payment/ImportSettlement",
+	"/payment.Payment/GetReconciliationReport",
+	"/payment.Payment/IssueGiftCard",
+	"/payment.Payment/ListRiskReviews",
+	"/payment.Payment/ApproveReview",
+	"/payment.Payment/RejectReview",
+}}
+
+// paymentPeers: con mTLS, el storefront llama a los RPCs de cada usuario y los
+// de gestión quedan para el propio binario (cli.go) y el certificado admin.
+func paymentPeers() mtls.Peers {
+	peers := mtls.Peers{"/payment.Payment/": {"storefront"}}
+	for _, m := range adminRules.Admin {
+		peers[m] = []string{"payment", "admin"}
+	}
+	return peers
 }
 
 func (s *service) register(grpcServer *grpc.Server) {
 	paymentpb.RegisterPaymentServer(grpcServer, s)
 	reflection.Register(grpcServer)
+	grpcclient.RegisterHealth(grpcServer)
 }
 
 func (s *service) GetPaymentStatus(ctx context.Context, req *paymentpb.GetPaymentStatusRequest) (*paymentpb.GetPaymentStatusResponse, error) {
 	p, err := s.repo.GetByOrderID(ctx, req.OrderId)
-	if err != nil || p == nil {
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
 		return &paymentpb.GetPaymentStatusResponse{
 			OrderId: req.OrderId,
 			State:   paymentpb.PaymentState_PAYMENT_STATE_UNSPECIFIED,
 		}, nil
 	}
-	return &paymentpb.GetPaymentStatusResponse{
+	if err := auth.CheckUser(ctx, p.UserID); err != nil {
+		return nil, err
+	}
+	resp := &paymentpb.GetPaymentStatusResponse{
 		OrderId:     p.OrderID,
 		State:       toPBState(p.State),
 		ProviderRef: p.ProviderRef,
 		UpdatedUnix: p.UpdatedAt.Unix(),
-	}, nil
+		RedirectUrl: p.RedirectURL,
+		Method:      commonpb.PaymentMethod(p.Method),
+	}
+	if p.WalletCents > 0 {
+		resp.WalletAmount = &commonpb.Money{Cents: p.WalletCents}
+	}
+	if p.InstrumentID != 0 {
+		if in, err := s.repo.GetInstrument(ctx, p.InstrumentID); err == nil && in != nil {
+			resp.Instrument = toPBInstrument(in)
+		}
+	}
+	return resp, nil
+}
+
+func (s *service) RefundPayment(ctx context.Context, req *paymentpb.RefundPaymentRequest) (*paymentpb.RefundPaymentResponse, error) {
+	if req.OrderId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order_id requerido")
+	}
+	if req.GetAmount().GetCents() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount no puede ser negativo")
+	}
+	out, err := s.refund(ctx, req.OrderId, req.GetAmount().GetCents(), req.Reason, req.IdempotencyKey, req.ToStoreCredit)
+	var declined *RefundDeclinedError
+	var perr *ProviderError
+	switch {
+	case errors.Is(err, ErrNotCaptured):
+		return nil, status.Error(codes.FailedPrecondition, "el pago no está cobrado")
+	case errors.Is(err, ErrRefundExceedsCapture):
+		return nil, status.Error(codes.FailedPrecondition, "el reembolso supera el saldo cobrado")
+	case errors.As(err, &declined):
+		return nil, status.Errorf(codes.Aborted, "la pasarela rechazó el reembolso: %s", declined.Reason)
+	case errors.As(err, &perr):
+		// Reintentar es seguro: va con la misma clave de idempotencia (ver refundKey)
+		return nil, status.Error(codes.Unavailable, "la pasarela no respondió; reintente el reembolso")
+	case err != nil:
+		return nil, err
+	}
+	_, refunded, err := s.repo.Balance(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &paymentpb.RefundPaymentResponse{
+		State:         toPBState(out.State),
+		RefundedTotal: &commonpb.Money{Cents: refunded},
+	}
+	if out.Refund != nil {
+		resp.Transaction = toPBTransaction(out.Refund)
+	}
+	if out.Credit != nil {
+		resp.StoreCredit = toPBTransaction(out.Credit)
+	}
+	return resp, nil
+}
+
+func (s *service) ListTransactions(ctx context.Context, req *paymentpb.ListTransactionsRequest) (*paymentpb.ListTransactionsResponse, error) {
+	p, err := s.repo.GetByOrderID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		if err := auth.CheckUser(ctx, p.UserID); err != nil {
+			return nil, err
+		}
+	}
+	txs, err := s.repo.ListTransactions(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &paymentpb.ListTransactionsResponse{Transactions: make([]*paymentpb.Transaction, 0, len(txs))}
+	for i := range txs {
+		resp.Transactions = append(resp.Transactions, toPBTransaction(&txs[i]))
+	}
+	return resp, nil
+}
+
+func toPBTransaction(t *Transaction) *paymentpb.Transaction {
+	return &paymentpb.Transaction{
+		TransactionId: t.ID,
+		OrderId:       t.OrderID,
+		Kind:          paymentpb.TransactionKind(t.Kind),
+		Amount:        &commonpb.Money{Cents: t.AmountCents},
+		ProviderRef:   t.ProviderRef,
+		Reason:        t.Reason,
+		CreatedUnix:   t.CreatedAt.Unix(),
+		StoreCredit:   t.StoreCredit,
+	}
 }
 
 func toPBState(s PaymentState) paymentpb.PaymentState {
@@ -45,11 +174,19 @@ func toPBState(s PaymentState) paymentpb.PaymentState {
 		return paymentpb.PaymentState_PAYMENT_STATE_SUCCEEDED
 	case PaymentStateFailed:
 		return paymentpb.PaymentState_PAYMENT_STATE_FAILED
+	case PaymentStateRefunded:
+		return paymentpb.PaymentState_PAYMENT_STATE_REFUNDED
+	case PaymentStatePartiallyRefunded:
+		return paymentpb.PaymentState_PAYMENT_STATE_PARTIALLY_REFUNDED
+	case PaymentStateAwaitingDelivery:
+		return paymentpb.PaymentState_PAYMENT_STATE_AWAITING_DELIVERY
+	case PaymentStateUnderReview:
+		return paymentpb.PaymentState_PAYMENT_STATE_UNDER_REVIEW
 	default:
 		return paymentpb.PaymentState_PAYMENT_STATE_UNSPECIFIED
 	}
 }
 
 // helper para publicar desde service
-func (s *service) publishJSON(key string, v any) { s.br.publishJSON(key, v) }
+func (s *service) publishJSON(ctx context.Context, key string, v any) { s.br.publishJSON(ctx, key, v) }
 
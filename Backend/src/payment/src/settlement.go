@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Conciliación contra la liquidación de la pasarela. El archivo es un CSV con
+// cabecera; el orden de las columnas no importa y las demás se ignoran:
+//
+//	date,provider_ref,type,amount_cents
+//	2026-10-18,PSE-000001,CHARGE,150000
+//	2026-10-18,PSE-RFD-000001,REFUND,30000
+//
+// type es CHARGE, REFUND o PARTIAL_REFUND. Cada línea se cruza con el libro de
+// transacciones por provider_ref y monto. Los movimientos del periodo que
+// ninguna liquidación incluyó quedan como MISSING hasta que una posterior los
+// traiga. Las fechas son UTC.
+
+const dateLayout = "2006-01-02"
+
+// ErrInvalidSettlement indica un archivo de liquidación mal formado.
+var ErrInvalidSettlement = errors.New("invalid settlement file")
+
+func parseSettlementCSV(r io.Reader) ([]SettlementLine, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: sin cabecera: %v", ErrInvalidSettlement, err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, c := range []string{"date", "provider_ref", "type", "amount_cents"} {
+		if _, ok := col[c]; !ok {
+			return nil, fmt.Errorf("%w: falta la columna %q", ErrInvalidSettlement, c)
+		}
+	}
+	cr.FieldsPerRecord = len(header)
+
+	var out []SettlementLine
+	for n := 2; ; n++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSettlement, err)
+		}
+		l := SettlementLine{Date: rec[col["date"]], ProviderRef: strings.TrimSpace(rec[col["provider_ref"]])}
+		if _, err := time.Parse(dateLayout, l.Date); err != nil {
+			return nil, fmt.Errorf("%w: línea %d: fecha %q", ErrInvalidSettlement, n, l.Date)
+		}
+		if l.ProviderRef == "" {
+			return nil, fmt.Errorf("%w: línea %d: provider_ref vacío", ErrInvalidSettlement, n)
+		}
+		switch strings.ToUpper(strings.TrimSpace(rec[col["type"]])) {
+		case "CHARGE":
+		case "REFUND", "PARTIAL_REFUND":
+			l.Refund = true
+		default:
+			return nil, fmt.Errorf("%w: línea %d: type %q", ErrInvalidSettlement, n, rec[col["type"]])
+		}
+		if l.AmountCents, err = strconv.ParseInt(strings.TrimSpace(rec[col["amount_cents"]]), 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: línea %d: amount_cents %q", ErrInvalidSettlement, n, rec[col["amount_cents"]])
+		}
+		if l.AmountCents < 0 {
+			l.AmountCents = -l.AmountCents // algunas pasarelas envían los reembolsos en negativo
+		}
+		out = append(out, l)
+	}
+}
+
+// reconcileSettlement concilia un archivo de liquidación. from y to vacíos
+// toman las fechas extremas del archivo. Si el archivo ya se importó devuelve
+// la corrida original y duplicate=true.
+func (s *service) reconcileSettlement(ctx context.Context, source string, data []byte, from, to string) (run *SettlementRun, duplicate bool, err error) {
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if prev, err := s.repo.FindSettlementRun(ctx, checksum); err != nil || prev != nil {
+		return prev, prev != nil, err
+	}
+
+	lines, err := parseSettlementCSV(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, err
+	}
+	if len(lines) == 0 {
+		return nil, false, fmt.Errorf("%w: el archivo no tiene líneas", ErrInvalidSettlement)
+	}
+	for _, l := range lines {
+		if from == "" || l.Date < from {
+			from = l.Date
+		}
+		if to == "" || l.Date > to {
+			to = l.Date
+		}
+	}
+	fromT, toT, err := parseDateRange(from, to)
+	if err != nil {
+		return nil, false, err
+	}
+
+	run = &SettlementRun{Source: source, Checksum: checksum, PeriodFrom: from, PeriodTo: to, Lines: len(lines)}
+	var found []Discrepancy
+	matched := map[int64]bool{} // transacciones del libro ya cruzadas en este archivo
+	for _, l := range lines {
+		txs, err := s.repo.TransactionsByRef(ctx, l.ProviderRef)
+		if err != nil {
+			return nil, false, err
+		}
+		var hit *Transaction
+		var orderID int64
+		repeated := false
+		for i := range txs {
+			if (txs[i].Kind != TxCharge) != l.Refund {
+				continue
+			}
+			if matched[txs[i].ID] {
+				repeated = true
+				orderID = txs[i].OrderID
+				continue
+			}
+			hit = &txs[i]
+			break
+		}
+
+		extra := Discrepancy{Kind: DiscrepancyExtra, Date: l.Date, ProviderRef: l.ProviderRef, OrderID: orderID, TxKind: TxCharge, SettledCents: l.AmountCents}
+		if l.Refund {
+			extra.TxKind = TxRefund
+		}
+		if hit != nil {
+			extra.OrderID = hit.OrderID
+			if settled, err := s.repo.IsSettled(ctx, hit.ProviderRef, l.Refund); err != nil {
+				return nil, false, err
+			} else if settled {
+				extra.Note = "ya estaba en una liquidación anterior"
+				found = append(found, extra)
+				continue
+			}
+		}
+		switch {
+		case hit == nil && repeated:
+			extra.Note = "línea repetida en el archivo"
+			found = append(found, extra)
+		case hit == nil:
+			extra.Note = "sin registro en el libro"
+			found = append(found, extra)
+		case hit.AmountCents != l.AmountCents:
+			matched[hit.ID] = true
+			found = append(found, Discrepancy{
+				Kind:          DiscrepancyAmountMismatch,
+				Date:          l.Date,
+				ProviderRef:   l.ProviderRef,
+				OrderID:       hit.OrderID,
+				TxKind:        hit.Kind,
+				RecordedCents: hit.AmountCents,
+				SettledCents:  l.AmountCents,
+			})
+		default:
+			matched[hit.ID] = true
+			run.Matched++
+		}
+	}
+
+	// Lo registrado en el periodo que no trajo este archivo ni uno anterior
+	txs, err := s.repo.TransactionsBetween(ctx, fromT, toT)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, t := range txs {
+		if matched[t.ID] {
+			continue
+		}
+		if settled, err := s.repo.IsSettled(ctx, t.ProviderRef, t.Kind != TxCharge); err != nil {
+			return nil, false, err
+		} else if settled {
+			continue
+		}
+		found = append(found, Discrepancy{
+			Kind:          DiscrepancyMissing,
+			Date:          t.CreatedAt.UTC().Format(dateLayout),
+			ProviderRef:   t.ProviderRef,
+			OrderID:       t.OrderID,
+			TxKind:        t.Kind,
+			RecordedCents: t.AmountCents,
+			Note:          "no aparece en la liquidación",
+		})
+	}
+
+	run.Discrepancies = len(found)
+	if err := s.repo.SaveSettlementRun(ctx, run, lines, found); err != nil {
+		return nil, false, err
+	}
+	slog.InfoContext(ctx, "settlement", "source", source, "from", from, "to", to,
+		"lines", run.Lines, "matched", run.Matched, "discrepancies", run.Discrepancies)
+	return run, false, nil
+}
+
+// parseDateRange valida [from, to] y devuelve los instantes [from, to+1 día).
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	fromT, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: fecha inicial %q", ErrInvalidSettlement, from)
+	}
+	toT, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: fecha final %q", ErrInvalidSettlement, to)
+	}
+	if toT.Before(fromT) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %s es anterior a %s", ErrInvalidSettlement, to, from)
+	}
+	return fromT, toT.AddDate(0, 0, 1), nil
+}
+
+func (s *service) ImportSettlement(ctx context.Context, req *paymentpb.ImportSettlementRequest) (*paymentpb.SettlementRun, error) {
+	if len(req.Csv) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "csv vacío")
+	}
+	source := req.Source
+	if source == "" {
+		source = "grpc"
+	}
+	run, dup, err := s.reconcileSettlement(ctx, source, req.Csv, req.PeriodFrom, req.PeriodTo)
+	if errors.Is(err, ErrInvalidSettlement) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := toPBSettlementRun(run)
+	out.Duplicate = dup
+	return out, nil
+}
+
+func (s *service) GetReconciliationReport(ctx context.Context, req *paymentpb.ReconciliationReportRequest) (*paymentpb.ReconciliationReport, error) {
+	from, to := req.FromDate, req.ToDate
+	if to == "" {
+		to = time.Now().UTC().Format(dateLayout)
+	}
+	if from == "" {
+		from = to
+	}
+	if _, _, err := parseDateRange(from, to); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ds, err := s.repo.ListDiscrepancies(ctx, from, to, req.IncludeResolved)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := s.repo.ListSettlementRuns(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := &paymentpb.ReconciliationReport{}
+	for _, d := range ds {
+		// Los totales cuentan solo lo que sigue abierto
+		switch {
+		case d.Resolved:
+		case d.Kind == DiscrepancyMissing:
+			out.Missing++
+		case d.Kind == DiscrepancyExtra:
+			out.Extra++
+		case d.Kind == DiscrepancyAmountMismatch:
+			out.AmountMismatch++
+		case d.Kind == DiscrepancyRefundFailed:
+			out.RefundFailed++
+		}
+		out.Discrepancies = append(out.Discrepancies, &paymentpb.Discrepancy{
+			DiscrepancyId:   d.ID,
+			RunId:           d.RunID,
+			Kind:            paymentpb.DiscrepancyKind(d.Kind),
+			Date:            d.Date,
+			ProviderRef:     d.ProviderRef,
+			OrderId:         d.OrderID,
+			TransactionKind: paymentpb.TransactionKind(d.TxKind),
+			Recorded:        &commonpb.Money{Cents: d.RecordedCents},
+			Settled:         &commonpb.Money{Cents: d.SettledCents},
+			Note:            d.Note,
+			Resolved:        d.Resolved,
+		})
+	}
+	for i := range runs {
+		out.Runs = append(out.Runs, toPBSettlementRun(&runs[i]))
+	}
+	return out, nil
+}
+
+func toPBSettlementRun(r *SettlementRun) *paymentpb.SettlementRun {
+	return &paymentpb.SettlementRun{
+		RunId:         r.ID,
+		Source:        r.Source,
+		PeriodFrom:    r.PeriodFrom,
+		PeriodTo:      r.PeriodTo,
+		Lines:         int32(r.Lines),
+		Matched:       int32(r.Matched),
+		Discrepancies: int32(r.Discrepancies),
+		CreatedUnix:   r.CreatedAt.Unix(),
+	}
+}
+
+// runSettlementImporter concilia los CSV que la pasarela deja en dir. Cada
+// archivo procesado se renombra a .done (o .failed si es inválido); como la
+// importación se deduplica por checksum, un archivo que no se pudo renombrar no
+// se concilia dos veces.
+func (s *service) runSettlementImporter(ctx context.Context, dir string, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		s.importSettlementDir(ctx, dir)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *service) importSettlementDir(ctx context.Context, dir string) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	if err != nil {
+		slog.ErrorContext(ctx, "settlement", "dir", dir, "err", err)
+		return
+	}
+	for _, f := range files {
+		ctx := logging.NewContext(ctx, logging.NewID())
+		data, err := os.ReadFile(f)
+		if err != nil {
+			slog.ErrorContext(ctx, "settlement", "file", f, "err", err)
+			continue
+		}
+		suffix := ".done"
+		if _, _, err := s.reconcileSettlement(ctx, filepath.Base(f), data, "", ""); err != nil {
+			slog.ErrorContext(ctx, "settlement", "file", f, "err", err)
+			if !errors.Is(err, ErrInvalidSettlement) {
+				continue // error de infraestructura: se reintenta en la próxima pasada
+			}
+			suffix = ".failed"
+		}
+		if err := os.Rename(f, f+suffix); err != nil {
+			slog.ErrorContext(ctx, "settlement", "file", f, "err", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+)
+
+func TestParseSettlementCSV(t *testing.T) {
+	lines, err := parseSettlementCSV(strings.NewReader(
+		"amount_cents,provider_ref,extra,type,date\n" +
+			"150000, PSE-1 ,x,charge,2026-10-18\n" +
+			"-30000,PSE-RFD-1,x,PARTIAL_REFUND,2026-10-18\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SettlementLine{
+		{Date: "2026-10-18", ProviderRef: "PSE-1", AmountCents: 150000},
+		{Date: "2026-10-18", ProviderRef: "PSE-RFD-1", AmountCents: 30000, Refund: true},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("líneas = %+v", lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Fatalf("línea %d = %+v, se esperaba %+v", i, lines[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{
+		"",
+		"date,provider_ref,type\n2026-10-18,PSE-1,CHARGE\n",
+		"date,provider_ref,type,amount_cents\n18/10/2026,PSE-1,CHARGE,1\n",
+		"date,provider_ref,type,amount_cents\n2026-10-18,,CHARGE,1\n",
+		"date,provider_ref,type,amount_cents\n2026-10-18,PSE-1,CHARGEBACK,1\n",
+		"date,provider_ref,type,amount_cents\n2026-10-18,PSE-1,CHARGE,uno\n",
+	} {
+		if _, err := parseSettlementCSV(strings.NewReader(bad)); !errors.Is(err, ErrInvalidSettlement) {
+			t.Errorf("%q: err = %v, se esperaba ErrInvalidSettlement", bad, err)
+		}
+	}
+}
+
+// TestReconcileSettlement cobra tres órdenes y liquida una bien, otra con otro
+// monto y omite la tercera; la pasarela además trae una referencia desconocida.
+func TestReconcileSettlement(t *testing.T) {
+	ctx := context.Background()
+	h := newHarness(t)
+	for id := int64(1); id <= 3; id++ {
+		h.deliver(t, id, 10000*id)
+	}
+	today := time.Now().UTC().Format(dateLayout)
+	csv := []byte(fmt.Sprintf("date,provider_ref,type,amount_cents\n"+
+		"%[1]s,%[2]s,CHARGE,10000\n"+
+		"%[1]s,%[3]s,CHARGE,19900\n"+
+		"%[1]s,PSE-OTRA,CHARGE,5000\n",
+		today, h.payment(t, 1).ProviderRef, h.payment(t, 2).ProviderRef))
+
+	run, err := h.svc.ImportSettlement(ctx, &paymentpb.ImportSettlementRequest{Csv: csv, Source: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Lines != 3 || run.Matched != 1 || run.Discrepancies != 3 || run.Duplicate {
+		t.Fatalf("corrida = %+v", run)
+	}
+
+	rep, err := h.svc.GetReconciliationReport(ctx, &paymentpb.ReconciliationReportRequest{FromDate: today, ToDate: today})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Missing != 1 || rep.AmountMismatch != 1 || rep.Extra != 1 || len(rep.Runs) != 1 {
+		t.Fatalf("reporte = %+v", rep)
+	}
+	for _, d := range rep.Discrepancies {
+		switch d.Kind {
+		case paymentpb.DiscrepancyKind_DISCREPANCY_KIND_MISSING:
+			if d.OrderId != 3 || d.Recorded.GetCents() != 30000 {
+				t.Errorf("faltante = %+v", d)
+			}
+		case paymentpb.DiscrepancyKind_DISCREPANCY_KIND_AMOUNT_MISMATCH:
+			if d.OrderId != 2 || d.Recorded.GetCents() != 20000 || d.Settled.GetCents() != 19900 {
+				t.Errorf("diferencia de monto = %+v", d)
+			}
+		case paymentpb.DiscrepancyKind_DISCREPANCY_KIND_EXTRA:
+			if d.ProviderRef != "PSE-OTRA" || d.Settled.GetCents() != 5000 {
+				t.Errorf("sobrante = %+v", d)
+			}
+		}
+	}
+
+	// El mismo archivo no se concilia dos veces
+	again, err := h.svc.ImportSettlement(ctx, &paymentpb.ImportSettlementRequest{Csv: csv, Source: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.Duplicate || again.RunId != run.RunId {
+		t.Fatalf("reimportación = %+v", again)
+	}
+}
+
+func TestGetPaymentStatus(t *testing.T) {
+	h := newHarness(t)
+	h.deliver(t, 1, 10000)
+	ctx := auth.NewContext(context.Background(), auth.User(1))
+
+	resp, err := h.svc.GetPaymentStatus(ctx, &paymentpb.GetPaymentStatusRequest{OrderId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.State != paymentpb.PaymentState_PAYMENT_STATE_SUCCEEDED {
+		t.Fatalf("estado = %s", resp.State)
+	}
+	// Sin pago todavía: UNSPECIFIED, no un error
+	resp, err = h.svc.GetPaymentStatus(ctx, &paymentpb.GetPaymentStatusRequest{OrderId: 2})
+	if err != nil || resp.State != paymentpb.PaymentState_PAYMENT_STATE_UNSPECIFIED {
+		t.Fatalf("sin pago: %v, %v", resp, err)
+	}
+	// Un fallo de la base no se disfraza de "sin pago"
+	h.repo.fail["GetByOrderID"] = 1
+	if _, err := h.svc.GetPaymentStatus(ctx, &paymentpb.GetPaymentStatusRequest{OrderId: 1}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, se esperaba el error del repositorio", err)
+	}
+}
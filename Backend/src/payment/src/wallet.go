@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Monedero: saldo a favor de cada usuario. Se acredita al redimir tarjetas de
+// regalo y con reembolsos como saldo a favor, y se debita al pagar una orden
+// (total o parcialmente, ver charge). El saldo nunca queda en negativo: el
+// débito es condicional en la base (ver applyWallet).
+
+func (s *service) GetBalance(ctx context.Context, req *commonpb.UserRef) (*paymentpb.WalletBalance, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id requerido")
+	}
+	if err := auth.CheckUser(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	bal, err := s.repo.WalletBalance(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentpb.WalletBalance{UserId: req.UserId, Balance: &commonpb.Money{Cents: bal}}, nil
+}
+
+func (s *service) RedeemGiftCard(ctx context.Context, req *paymentpb.RedeemGiftCardRequest) (*paymentpb.RedeemGiftCardResponse, error) {
+	code := normalizeGiftCard(req.Code)
+	if req.UserId == 0 || code == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id y code requeridos")
+	}
+	if err := auth.CheckUser(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	e, err := s.repo.RedeemGiftCard(ctx, req.UserId, code)
+	switch {
+	case errors.Is(err, ErrGiftCardNotFound):
+		return nil, status.Error(codes.NotFound, "tarjeta de regalo no encontrada")
+	case errors.Is(err, ErrGiftCardRedeemed):
+		return nil, status.Error(codes.FailedPrecondition, "la tarjeta de regalo ya fue redimida")
+	case errors.Is(err, ErrGiftCardExpired):
+		return nil, status.Error(codes.FailedPrecondition, "la tarjeta de regalo está vencida")
+	case err != nil:
+		return nil, err
+	}
+	bal, err := s.repo.WalletBalance(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &paymentpb.RedeemGiftCardResponse{
+		Transaction: toPBWalletEntry(e),
+		Balance:     &commonpb.Money{Cents: bal},
+	}, nil
+}
+
+func (s *service) ListWalletTransactions(ctx context.Context, req *paymentpb.ListWalletTransactionsRequest) (*paymentpb.ListWalletTransactionsResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id requerido")
+	}
+	if err := auth.CheckUser(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	limit := int(req.Limit)
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	entries, err := s.repo.ListWalletEntries(ctx, req.UserId, limit)
+	if err != nil {
+		return nil, err
+	}
+	bal, err := s.repo.WalletBalance(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	resp := &paymentpb.ListWalletTransactionsResponse{
+		Transactions: make([]*paymentpb.WalletTransaction, 0, len(entries)),
+		Balance:      &commonpb.Money{Cents: bal},
+	}
+	for i := range entries {
+		resp.Transactions = append(resp.Transactions, toPBWalletEntry(&entries[i]))
+	}
+	return resp, nil
+}
+
+func (s *service) IssueGiftCard(ctx context.Context, req *paymentpb.IssueGiftCardRequest) (*paymentpb.GiftCard, error) {
+	amount := req.GetAmount().GetCents()
+	if amount <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount debe ser mayor que cero")
+	}
+	if req.ExpiresUnix != 0 && req.ExpiresUnix <= time.Now().Unix() {
+		return nil, status.Error(codes.InvalidArgument, "expires_unix ya pasó")
+	}
+	g := &GiftCard{InitialCents: amount}
+	if req.ExpiresUnix != 0 {
+		g.ExpiresAt = time.Unix(req.ExpiresUnix, 0)
+	}
+	code, err := newGiftCardCode()
+	if err != nil {
+		return nil, err
+	}
+	g.Code = code
+	if err := s.repo.CreateGiftCard(ctx, g); err != nil {
+		return nil, err
+	}
+	return &paymentpb.GiftCard{
+		Code:        g.Code,
+		Amount:      &commonpb.Money{Cents: g.InitialCents},
+		ExpiresUnix: req.ExpiresUnix,
+		CreatedUnix: g.CreatedAt.Unix(),
+	}, nil
+}
+
+// Sin 0/O ni 1/I para que el código se pueda dictar.
+const giftCardAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// newGiftCardCode genera un código GC-XXXX-XXXX-XXXX (60 bits aleatorios).
+func newGiftCardCode() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("GC")
+	for i, c := range b {
+		if i%4 == 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(giftCardAlphabet[int(c)%len(giftCardAlphabet)])
+	}
+	return sb.String(), nil
+}
+
+// normalizeGiftCard acepta el código en minúsculas, con espacios o sin guiones.
+func normalizeGiftCard(code string) string {
+	code = strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+	if len(code) != 14 || !strings.HasPrefix(code, "GC") {
+		return code
+	}
+	return "GC-" + code[2:6] + "-" + code[6:10] + "-" + code[10:]
+}
+
+// maskGiftCard deja visibles solo los últimos 4 caracteres del código.
+func maskGiftCard(code string) string {
+	if len(code) < 4 {
+		return code
+	}
+	return "GC-****-****-" + code[len(code)-4:]
+}
+
+func toPBWalletEntry(e *WalletEntry) *paymentpb.WalletTransaction {
+	return &paymentpb.WalletTransaction{
+		TransactionId: e.ID,
+		UserId:        e.UserID,
+		Kind:          paymentpb.WalletTransactionKind(e.Kind),
+		Amount:        &commonpb.Money{Cents: e.AmountCents},
+		BalanceAfter:  &commonpb.Money{Cents: e.BalanceAfter},
+		OrderId:       e.OrderID,
+		Reference:     e.Reference,
+		CreatedUnix:   e.CreatedAt.Unix(),
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Pruebas del monedero: el saldo nunca queda negativo aunque lleguen débitos y
+// redenciones a la vez, y el pago dividido devuelve o reembolsa cada parte por
+// donde corresponde.
+
+func (h *harness) credit(t *testing.T, userID, cents int64) {
+	t.Helper()
+	g := &GiftCard{Code: fmt.Sprintf("GC-TEST-%04d-%04d", userID, cents), InitialCents: cents}
+	if err := h.repo.CreateGiftCard(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.repo.RedeemGiftCard(context.Background(), userID, g.Code); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (h *harness) balance(t *testing.T, userID int64) int64 {
+	t.Helper()
+	bal, err := h.repo.WalletBalance(context.Background(), userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bal
+}
+
+func TestWalletConcurrentDebitsNeverOverdraw(t *testing.T) {
+	h := newHarness(t)
+	h.credit(t, 1, 1000)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ok, insufficient := 0, 0
+	for i := 0; i < 25; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := h.repo.ApplyWallet(context.Background(), &WalletEntry{
+				UserID: 1, Kind: WalletPayment, AmountCents: -100, IdemKey: fmt.Sprintf("debit:%d", i),
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, ErrInsufficientFunds):
+				insufficient++
+			default:
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if ok != 10 || insufficient != 15 {
+		t.Fatalf("débitos aplicados = %d, rechazados = %d; se esperaban 10 y 15", ok, insufficient)
+	}
+	if bal := h.balance(t, 1); bal != 0 {
+		t.Fatalf("saldo = %d, se esperaba 0", bal)
+	}
+}
+
+func TestGiftCardRedeemedOnce(t *testing.T) {
+	h := newHarness(t)
+	g := &GiftCard{Code: "GC-AAAA-BBBB-CCCC", InitialCents: 5000}
+	if err := h.repo.CreateGiftCard(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for u := range errs {
+		wg.Add(1)
+		go func(u int) {
+			defer wg.Done()
+			_, errs[u] = h.repo.RedeemGiftCard(context.Background(), int64(u+1), g.Code)
+		}(u)
+	}
+	wg.Wait()
+
+	var winner int64
+	total := int64(0)
+	for u, err := range errs {
+		switch {
+		case err == nil:
+			if winner != 0 {
+				t.Fatalf("la tarjeta se redimió para los usuarios %d y %d", winner, u+1)
+			}
+			winner = int64(u + 1)
+		case !errors.Is(err, ErrGiftCardRedeemed):
+			t.Fatalf("usuario %d: %v", u+1, err)
+		}
+		total += h.balance(t, int64(u+1))
+	}
+	if winner == 0 || total != 5000 {
+		t.Fatalf("ganador = %d, saldo acreditado = %d; se esperaba uno solo con 5000", winner, total)
+	}
+
+	// Repetir la redención del ganador devuelve el mismo movimiento
+	e, err := h.repo.RedeemGiftCard(context.Background(), winner, g.Code)
+	if err != nil || e == nil || e.AmountCents != 5000 {
+		t.Fatalf("re-redención = %+v, %v", e, err)
+	}
+	if bal := h.balance(t, winner); bal != 5000 {
+		t.Fatalf("saldo tras re-redención = %d", bal)
+	}
+}
+
+func (h *harness) deliverSplit(t *testing.T, orderID, amountCents, walletCents int64) {
+	t.Helper()
+	body := []byte(fmt.Sprintf(`{"order_id":%d,"user_id":1,"amount_cents":%d,"wallet_cents":%d}`, orderID, amountCents, walletCents))
+	if err := h.svc.handlePaymentRequested(context.Background(), body); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplitPaymentDeclinedReleasesWallet(t *testing.T) {
+	h := newHarness(t)
+	h.credit(t, 1, 1000)
+	h.bank.decline[7] = "insufficient_funds"
+
+	h.deliverSplit(t, 7, 1500, 600)
+	if p := h.payment(t, 7); p.State != PaymentStateFailed {
+		t.Fatalf("estado = %s, se esperaba FAILED", p.State)
+	}
+	if bal := h.balance(t, 1); bal != 1000 {
+		t.Fatalf("saldo = %d, el débito no se devolvió", bal)
+	}
+
+	// Reintento con otro intento: esta vez el banco aprueba la parte restante
+	delete(h.bank.decline, 7)
+	h.deliverSplit(t, 7, 1500, 600)
+	if p := h.payment(t, 7); p.State != PaymentStateSucceeded {
+		t.Fatalf("estado = %s, se esperaba SUCCEEDED", p.State)
+	}
+	if bal := h.balance(t, 1); bal != 400 {
+		t.Fatalf("saldo = %d, se esperaba 400", bal)
+	}
+	captured, _, err := h.repo.Balance(context.Background(), 7)
+	if err != nil || captured != 1500 {
+		t.Fatalf("capturado = %d (%v), se esperaba 1500", captured, err)
+	}
+	if len(h.bank.keys) != 2 || h.bank.byKey[chargeKey(7, 2)].Status != ChargeApproved {
+		t.Fatalf("cobros en el banco: %v", h.bank.keys)
+	}
+}
+
+func TestStoreCreditOnlyPaymentSkipsProvider(t *testing.T) {
+	h := newHarness(t)
+	h.credit(t, 1, 2000)
+
+	body := []byte(`{"order_id":8,"user_id":1,"amount_cents":1200,"method":"PAYMENT_METHOD_STORE_CREDIT"}`)
+	if err := h.svc.handlePaymentRequested(context.Background(), body); err != nil {
+		t.Fatal(err)
+	}
+	if p := h.payment(t, 8); p.State != PaymentStateSucceeded || p.ProviderRef != walletRef(8) {
+		t.Fatalf("pago = %+v", p)
+	}
+	if len(h.bank.keys) != 0 {
+		t.Fatalf("se llamó a la pasarela: %v", h.bank.keys)
+	}
+	if bal := h.balance(t, 1); bal != 800 {
+		t.Fatalf("saldo = %d, se esperaba 800", bal)
+	}
+
+	// Sin saldo suficiente el pago falla sin tocar el monedero
+	body = []byte(`{"order_id":9,"user_id":1,"amount_cents":1200,"method":"PAYMENT_METHOD_STORE_CREDIT"}`)
+	if err := h.svc.handlePaymentRequested(context.Background(), body); err != nil {
+		t.Fatal(err)
+	}
+	if p := h.payment(t, 9); p.State != PaymentStateFailed {
+		t.Fatalf("estado = %s, se esperaba FAILED", p.State)
+	}
+	if bal := h.balance(t, 1); bal != 800 {
+		t.Fatalf("saldo = %d, se esperaba 800", bal)
+	}
+}
+
+func TestSplitRefundCreditsWalletThenProvider(t *testing.T) {
+	h := newHarness(t)
+	h.credit(t, 1, 1000)
+	h.deliverSplit(t, 11, 1500, 600)
+
+	// 1000 de 1500: la pasarela devuelve lo que cobró (900) y el resto va al monedero
+	out, err := h.svc.refund(context.Background(), 11, 1000, "devolución", "rfd-1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Refund == nil || out.Refund.AmountCents != 900 || out.Credit == nil || out.Credit.AmountCents != 100 {
+		t.Fatalf("reembolso = %+v / %+v", out.Refund, out.Credit)
+	}
+	if bal := h.balance(t, 1); bal != 500 {
+		t.Fatalf("saldo = %d, se esperaba 500", bal)
+	}
+
+	// El reintento con la misma clave no devuelve nada más
+	if _, err := h.svc.refund(context.Background(), 11, 1000, "devolución", "rfd-1", false); err != nil {
+		t.Fatal(err)
+	}
+	if bal := h.balance(t, 1); bal != 500 {
+		t.Fatalf("saldo tras reintento = %d, se esperaba 500", bal)
+	}
+
+	// Lo que queda solo puede ir al monedero
+	out, err = h.svc.refund(context.Background(), 11, 0, "devolución", "rfd-2", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Refund != nil || out.Credit == nil || out.Credit.AmountCents != 500 || out.State != PaymentStateRefunded {
+		t.Fatalf("reembolso = %+v / %+v (%s)", out.Refund, out.Credit, out.State)
+	}
+	if bal := h.balance(t, 1); bal != 1000 {
+		t.Fatalf("saldo = %d, se esperaba 1000", bal)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
+)
+
+// Cabeceras de las notificaciones firmadas. La firma es
+// "sha256=" + hex(HMAC-SHA256(secreto, timestamp + "." + cuerpo)).
+const (
+	headerWebhookTimestamp = "X-Webhook-Timestamp"
+	headerWebhookSignature = "X-Webhook-Signature"
+)
+
+// pseWebhook es la notificación que envía la pasarela cuando una transacción
+// cambia de estado. EventID es estable entre reintentos del mismo aviso.
+type pseWebhook struct {
+	EventID     string         `json:"event_id"`
+	Type        string         `json:"type"`
+	Transaction pseTransaction `json:"transaction"`
+}
+
+var (
+	errWebhookDisabled  = errors.New("webhooks deshabilitados (PAYMENT_WEBHOOK_SECRET vacío)")
+	errWebhookSignature = errors.New("firma inválida")
+)
+
+func (s *service) httpRoutes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST /webhooks/pse", tracing.Middleware(metrics.Middleware(logging.Middleware(http.HandlerFunc(s.handlePSEWebhook)))))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+	return mux
+}
+
+func (s *service) handlePSEWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 64<<10))
+	if err != nil {
+		http.Error(w, "cuerpo ilegible", http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+	if err := verifyWebhook(s.cfg.WebhookSecret, s.cfg.WebhookTolerance, r.Header, body, time.Now()); err != nil {
+		slog.WarnContext(ctx, "webhook rechazado", "remote", r.RemoteAddr, "err", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var ev pseWebhook
+	if err := json.Unmarshal(body, &ev); err != nil || ev.EventID == "" {
+		http.Error(w, "notificación inválida", http.StatusBadRequest)
+		return
+	}
+	orderID, ok := parsePSEReference(ev.Transaction.Reference)
+	if !ok {
+		http.Error(w, "referencia desconocida", http.StatusUnprocessableEntity)
+		return
+	}
+	res := ev.Transaction.result()
+	if res.Status == ChargePending {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	p, err := s.repo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		http.Error(w, "error interno", http.StatusInternalServerError)
+		return
+	}
+	if p == nil {
+		http.Error(w, "pago no encontrado", http.StatusNotFound)
+		return
+	}
+	// La notificación tiene que corresponder al cobro que se inició; la parte
+	// del monedero no pasa por la pasarela
+	external := p.AmountCents - p.WalletCents
+	if (p.ProviderRef != "" && p.ProviderRef != ev.Transaction.ID) || ev.Transaction.AmountCents != external {
+		slog.WarnContext(ctx, "webhook no coincide con el pago", "event_id", ev.EventID, "order_id", orderID,
+			"tx", ev.Transaction.ID, "ref", p.ProviderRef, "tx_cents", ev.Transaction.AmountCents, "external_cents", external)
+		http.Error(w, "la transacción no coincide con el pago", http.StatusConflict)
+		return
+	}
+
+	fresh, err := s.repo.RecordWebhook(ctx, ev.EventID, orderID)
+	if err != nil {
+		http.Error(w, "error interno", http.StatusInternalServerError)
+		return
+	}
+	if !fresh {
+		slog.InfoContext(ctx, "webhook duplicado; se ignora", "event_id", ev.EventID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err := s.settle(ctx, orderID, res, "webhook"); err != nil {
+		// Se olvida el evento para que el reintento de la pasarela lo procese
+		if ferr := s.repo.ForgetWebhook(ctx, ev.EventID); ferr != nil {
+			slog.ErrorContext(ctx, "webhook", "event_id", ev.EventID, "err", ferr)
+		}
+		slog.ErrorContext(ctx, "webhook", "event_id", ev.EventID, "order_id", orderID, "err", err)
+		http.Error(w, "error interno", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verifyWebhook comprueba la firma HMAC y que el timestamp firmado esté dentro
+// de la tolerancia, para que no se puedan reenviar notificaciones viejas.
+func verifyWebhook(secret string, tolerance time.Duration, h http.Header, body []byte, now time.Time) error {
+	if secret == "" {
+		return errWebhookDisabled
+	}
+	ts := h.Get(headerWebhookTimestamp)
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s inválido", headerWebhookTimestamp)
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("timestamp fuera de tolerancia (%s)", d.Round(time.Second))
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(h.Get(headerWebhookSignature), "sha256="))
+	if err != nil || !hmac.Equal(got, webhookMAC(secret, ts, body)) {
+		return errWebhookSignature
+	}
+	return nil
+}
+
+func webhookMAC(secret, ts string, body []byte) []byte {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(body)
+	return m.Sum(nil)
+}
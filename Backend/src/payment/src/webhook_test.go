@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+)
+
+// postWebhook firma con el secreto del servicio y entrega una notificación de la pasarela.
+func (h *harness) postWebhook(t *testing.T, ev pseWebhook) int {
+	t.Helper()
+	return h.postWebhookSigned(t, ev, h.svc.cfg.WebhookSecret)
+}
+
+func (h *harness) postWebhookSigned(t *testing.T, ev pseWebhook, secret string) int {
+	t.Helper()
+	body, err := json.Marshal(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/pse", bytes.NewReader(body))
+	req.Header.Set(headerWebhookTimestamp, ts)
+	req.Header.Set(headerWebhookSignature, "sha256="+hex.EncodeToString(webhookMAC(secret, ts, body)))
+	rec := httptest.NewRecorder()
+	h.svc.handlePSEWebhook(rec, req)
+	return rec.Code
+}
+
+// TestWebhookSplitPayment: en un pago dividido la pasarela solo ve la parte
+// externa, así que el webhook trae ese monto y no el total de la orden.
+func TestWebhookSplitPayment(t *testing.T) {
+	h := newHarness(t)
+	h.svc.cfg.WebhookSecret = "secreto"
+	h.svc.cfg.WebhookTolerance = time.Minute
+	h.bank.async = true
+	h.credit(t, 1, 1000)
+	h.deliverSplit(t, 7, 1500, 600)
+
+	p := h.payment(t, 7)
+	if p.State != PaymentStatePending || p.ProviderRef == "" {
+		t.Fatalf("pago = %+v, se esperaba PENDING con referencia", p)
+	}
+	ev := pseWebhook{EventID: "ev-1", Type: "transaction.updated", Transaction: pseTransaction{
+		ID: p.ProviderRef, Reference: pseReference(7), AmountCents: 1500, Status: "APPROVED",
+	}}
+	if code := h.postWebhook(t, ev); code != http.StatusConflict {
+		t.Fatalf("con el total de la orden: status %d, se esperaba 409", code)
+	}
+
+	ev.Transaction.AmountCents = 900
+	if code := h.postWebhook(t, ev); code != http.StatusNoContent {
+		t.Fatalf("con la parte externa: status %d, se esperaba 204", code)
+	}
+	if p := h.payment(t, 7); p.State != PaymentStateSucceeded {
+		t.Fatalf("estado = %s, se esperaba SUCCEEDED", p.State)
+	}
+	if n := h.pub.count("payment.succeeded"); n != 1 {
+		t.Fatalf("payment.succeeded publicado %d veces", n)
+	}
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"event_id":"ev-1"}`)
+	signed := func(secret string, at time.Time) http.Header {
+		ts := strconv.FormatInt(at.Unix(), 10)
+		h := http.Header{}
+		h.Set(headerWebhookTimestamp, ts)
+		h.Set(headerWebhookSignature, "sha256="+hex.EncodeToString(webhookMAC(secret, ts, body)))
+		return h
+	}
+	cases := []struct {
+		name    string
+		secret  string
+		header  http.Header
+		body    []byte
+		wantErr error // nil con ok = false: cualquier error
+		ok      bool
+	}{
+		{name: "válida", secret: "secreto", header: signed("secreto", now), body: body, ok: true},
+		{name: "dentro de la tolerancia", secret: "secreto", header: signed("secreto", now.Add(-4*time.Minute)), body: body, ok: true},
+		{name: "otro secreto", secret: "secreto", header: signed("otro", now), body: body, wantErr: errWebhookSignature},
+		{name: "cuerpo alterado", secret: "secreto", header: signed("secreto", now), body: []byte(`{"event_id":"ev-2"}`), wantErr: errWebhookSignature},
+		{name: "firma que no es hex", secret: "secreto", header: http.Header{
+			headerWebhookTimestamp: {strconv.FormatInt(now.Unix(), 10)}, headerWebhookSignature: {"sha256=zz"},
+		}, body: body, wantErr: errWebhookSignature},
+		{name: "timestamp viejo", secret: "secreto", header: signed("secreto", now.Add(-6*time.Minute)), body: body},
+		{name: "timestamp futuro", secret: "secreto", header: signed("secreto", now.Add(6*time.Minute)), body: body},
+		{name: "sin timestamp", secret: "secreto", header: http.Header{}, body: body},
+		{name: "sin secreto configurado", secret: "", header: signed("", now), body: body, wantErr: errWebhookDisabled},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyWebhook(tc.secret, 5*time.Minute, tc.header, tc.body, now)
+			switch {
+			case tc.ok && err != nil:
+				t.Fatalf("err = %v", err)
+			case !tc.ok && err == nil:
+				t.Fatal("se esperaba error")
+			case tc.wantErr != nil && !errors.Is(err, tc.wantErr):
+				t.Fatalf("err = %v, se esperaba %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestWebhookRejectedAndDuplicate: sin firma válida el aviso no toca el pago, y
+// la pasarela que repite el mismo event_id no publica dos veces.
+func TestWebhookRejectedAndDuplicate(t *testing.T) {
+	h := newHarness(t)
+	h.svc.cfg.WebhookSecret = "secreto"
+	h.svc.cfg.WebhookTolerance = time.Minute
+	h.bank.async = true
+	h.deliver(t, 7, 1500)
+	p := h.payment(t, 7)
+	ev := pseWebhook{EventID: "ev-1", Type: "transaction.updated", Transaction: pseTransaction{
+		ID: p.ProviderRef, Reference: pseReference(7), AmountCents: 1500, Status: "APPROVED",
+	}}
+
+	if code := h.postWebhookSigned(t, ev, "otro"); code != http.StatusUnauthorized {
+		t.Fatalf("firma inválida: status %d, se esperaba 401", code)
+	}
+	if p := h.payment(t, 7); p.State != PaymentStatePending {
+		t.Fatalf("estado = %s tras un aviso sin firma válida", p.State)
+	}
+
+	for range 2 {
+		if code := h.postWebhook(t, ev); code != http.StatusNoContent {
+			t.Fatalf("status %d, se esperaba 204", code)
+		}
+	}
+	if n := h.pub.count("payment.succeeded"); n != 1 {
+		t.Fatalf("payment.succeeded publicado %d veces", n)
+	}
+
+	// Sin secreto los webhooks están deshabilitados
+	h.svc.cfg.WebhookSecret = ""
+	if code := h.postWebhook(t, ev); code != http.StatusUnauthorized {
+		t.Fatalf("sin secreto: status %d, se esperaba 401", code)
+	}
+}
+
+// TestLateApprovalRefunded: el banco aprueba después de que el pago se dio por
+// vencido (bank_timeout). El cobro se devuelve una sola vez aunque el aviso se
+// repita; si la pasarela no responde el aviso se reintenta y, si rechaza el
+// reembolso, queda como REFUND_FAILED en el reporte.
+func TestLateApprovalRefunded(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*harness, pseWebhook) {
+		h := newHarness(t)
+		h.svc.cfg.WebhookSecret = "secreto"
+		h.svc.cfg.WebhookTolerance = time.Minute
+		h.bank.async = true
+		h.deliver(t, 7, 1500)
+		h.svc.reconcilePending(ctx, time.Now().Add(2*time.Hour))
+		p := h.payment(t, 7)
+		if p.State != PaymentStateFailed {
+			t.Fatalf("estado = %s, se esperaba FAILED por bank_timeout", p.State)
+		}
+		return h, pseWebhook{EventID: "ev-1", Type: "transaction.updated", Transaction: pseTransaction{
+			ID: p.ProviderRef, Reference: pseReference(7), AmountCents: 1500, Status: "APPROVED",
+		}}
+	}
+
+	t.Run("reembolsado", func(t *testing.T) {
+		h, ev := setup(t)
+		h.bank.refundFaults = []fault{lostRequest}
+		if code := h.postWebhook(t, ev); code != http.StatusInternalServerError {
+			t.Fatalf("sin respuesta de la pasarela: status %d, se esperaba 500 para que reintente", code)
+		}
+		for _, id := range []string{"ev-1", "ev-2"} {
+			ev.EventID = id
+			if code := h.postWebhook(t, ev); code != http.StatusNoContent {
+				t.Fatalf("%s: status %d", id, code)
+			}
+		}
+		want := "late:" + ev.Transaction.ID
+		if len(h.bank.refunds) != 1 || h.bank.lastRefund.IdempotencyKey != want ||
+			h.bank.lastRefund.ChargeRef != ev.Transaction.ID || h.bank.lastRefund.AmountCents != 1500 {
+			t.Fatalf("reembolsos = %v, último = %+v", h.bank.refunds, h.bank.lastRefund)
+		}
+		if p := h.payment(t, 7); p.State != PaymentStateFailed || h.pub.count("payment.succeeded") != 0 {
+			t.Fatalf("estado = %s, eventos = %v", p.State, h.pub.keys)
+		}
+	})
+
+	t.Run("rechazado", func(t *testing.T) {
+		h, ev := setup(t)
+		h.bank.refundDecline = "refund_rejected"
+		if code := h.postWebhook(t, ev); code != http.StatusNoContent {
+			t.Fatalf("status %d", code)
+		}
+		rep, err := h.svc.GetReconciliationReport(ctx, &paymentpb.ReconciliationReportRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rep.RefundFailed != 1 || rep.Discrepancies[0].ProviderRef != ev.Transaction.ID ||
+			rep.Discrepancies[0].Recorded.GetCents() != 1500 || rep.Discrepancies[0].Note != "late_approval: refund_rejected" {
+			t.Fatalf("reporte = %v", rep)
+		}
+	})
+}
@@ -0,0 +1,521 @@
+// psesim simula un banco estilo PSE para probar localmente el flujo asíncrono
+// de pagos por redirección: el servicio Payment crea la transacción, el cliente
+// la aprueba o rechaza en la página del banco (o se resuelve sola tras
+// PSESIM_AUTO_RESOLVE) y el banco avisa el resultado con un webhook firmado;
+// Payment también puede consultarlo. GET /api/settlements entrega la
+// liquidación diaria para la conciliación.
+//
+// Variables de entorno:
+//
+//	PSESIM_ADDR              dirección HTTP (":8099")
+//	PSESIM_PUBLIC_URL        URL base para armar redirect_url ("http://localhost:8099")
+//	PSESIM_LATENCY           demora de cada llamada al API ("200ms")
+//	PSESIM_DECLINE_RATE      probabilidad de rechazo al resolver solo (0.2)
+//	PSESIM_TIMEOUT_RATE      probabilidad de que una llamada no responda a tiempo (0)
+//	PSESIM_HANG              cuánto se cuelga una llamada que "no responde" ("30s")
+//	PSESIM_AUTO_RESOLVE      plazo para resolver sin intervención; 0 = solo manual ("3s")
+//	PSESIM_SEED              semilla del azar; 0 = según la hora
+//	PSESIM_WEBHOOK_URL       a dónde avisar los resultados; vacío = sin webhooks
+//	PSESIM_WEBHOOK_SECRET    secreto HMAC compartido con Payment
+//	PSESIM_WEBHOOK_DUP_RATE  probabilidad de entregar un aviso dos veces (0)
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type config struct {
+	Addr        string
+	PublicURL   string
+	Latency     time.Duration
+	DeclineRate float64
+	TimeoutRate float64
+	Hang        time.Duration
+	AutoResolve time.Duration
+	Seed        int64
+
+	WebhookURL     string
+	WebhookSecret  string
+	WebhookDupRate float64
+}
+
+func loadConfig() config {
+	return config{
+		Addr:        getEnv("PSESIM_ADDR", ":8099"),
+		PublicURL:   strings.TrimRight(getEnv("PSESIM_PUBLIC_URL", "http://localhost:8099"), "/"),
+		Latency:     getDuration("PSESIM_LATENCY", 200*time.Millisecond),
+		DeclineRate: getFloat("PSESIM_DECLINE_RATE", 0.2),
+		TimeoutRate: getFloat("PSESIM_TIMEOUT_RATE", 0),
+		Hang:        getDuration("PSESIM_HANG", 30*time.Second),
+		AutoResolve: getDuration("PSESIM_AUTO_RESOLVE", 3*time.Second),
+		Seed:        int64(getFloat("PSESIM_SEED", 0)),
+
+		WebhookURL:     getEnv("PSESIM_WEBHOOK_URL", ""),
+		WebhookSecret:  getEnv("PSESIM_WEBHOOK_SECRET", ""),
+		WebhookDupRate: getFloat("PSESIM_WEBHOOK_DUP_RATE", 0),
+	}
+}
+
+// Mismo contrato que pseProvider en el servicio Payment.
+type transaction struct {
+	ID            string `json:"id"`
+	Reference     string `json:"reference"`
+	AmountCents   int64  `json:"amount_cents"`
+	Description   string `json:"description"`
+	Status        string `json:"status"` // PENDING, APPROVED, REJECTED
+	Reason        string `json:"reason,omitempty"`
+	RedirectURL   string `json:"redirect_url,omitempty"`
+	RefundedCents int64  `json:"refunded_cents"`
+	ReturnURL     string `json:"-"`
+}
+
+type refundRequest struct {
+	TransactionID string `json:"transaction_id"` // sin él se usa el último intento de reference
+	Reference     string `json:"reference"`
+	AmountCents   int64  `json:"amount_cents"`
+	Reason        string `json:"reason"`
+	Key           string `json:"idempotency_key"`
+}
+
+type refund struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"` // APPROVED, REJECTED
+	Reason      string `json:"reason,omitempty"`
+	AmountCents int64  `json:"-"`
+}
+
+type bank struct {
+	cfg config
+
+	mu      sync.Mutex
+	rnd     *rand.Rand
+	seq     int
+	txs     map[string]*transaction
+	byRef   map[string]string // reference → id del último intento
+	byKey   map[string]string // idempotency key → id
+	refunds int
+	rfByKey map[string]refund // idempotency key → reembolso aprobado
+	settled []settlement
+}
+
+// settlement es una fila del archivo de liquidación diario (ver handleSettlements).
+type settlement struct {
+	Date        string
+	ProviderRef string
+	Type        string // CHARGE, REFUND
+	AmountCents int64
+}
+
+func newBank(cfg config) *bank {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &bank{
+		cfg:     cfg,
+		rnd:     rand.New(rand.NewSource(seed)),
+		txs:     map[string]*transaction{},
+		byRef:   map[string]string{},
+		byKey:   map[string]string{},
+		rfByKey: map[string]refund{},
+	}
+}
+
+func (b *bank) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/transactions", b.api(b.handleCreate))
+	mux.HandleFunc("GET /api/transactions/{id}", b.api(b.handleGet))
+	mux.HandleFunc("POST /api/refunds", b.api(b.handleRefund))
+	mux.HandleFunc("GET /api/settlements", b.handleSettlements)
+	mux.HandleFunc("GET /pse/{id}", b.handlePage)
+	mux.HandleFunc("POST /pse/{id}", b.handleDecision)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+	return mux
+}
+
+// api aplica la latencia y los timeouts simulados a las llamadas del API.
+func (b *bank) api(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		hang := b.rnd.Float64() < b.cfg.TimeoutRate
+		b.mu.Unlock()
+		if hang {
+			slog.Info("simulando timeout", "method", r.Method, "path", r.URL.Path, "hang", b.cfg.Hang.String())
+			select {
+			case <-time.After(b.cfg.Hang):
+				http.Error(w, "gateway timeout", http.StatusGatewayTimeout)
+			case <-r.Context().Done():
+			}
+			return
+		}
+		time.Sleep(b.cfg.Latency)
+		h(w, r)
+	}
+}
+
+func (b *bank) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reference   string `json:"reference"`
+		AmountCents int64  `json:"amount_cents"`
+		Description string `json:"description"`
+		ReturnURL   string `json:"return_url"`
+		Key         string `json:"idempotency_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Reference == "" || req.AmountCents <= 0 {
+		http.Error(w, "reference y amount_cents son obligatorios", http.StatusBadRequest)
+		return
+	}
+	if req.Key == "" {
+		req.Key = r.Header.Get("Idempotency-Key")
+	}
+
+	b.mu.Lock()
+	// Una clave ya vista devuelve la misma transacción, sea cual sea su estado
+	if id, ok := b.byKey[req.Key]; ok && req.Key != "" {
+		tx := *b.txs[id]
+		b.mu.Unlock()
+		if tx.AmountCents != req.AmountCents {
+			http.Error(w, "idempotency_key reutilizada con otro monto", http.StatusUnprocessableEntity)
+			return
+		}
+		writeJSON(w, http.StatusOK, tx)
+		return
+	}
+	// Igual que un banco real: la misma referencia pendiente o aprobada no se cobra dos veces
+	if id, ok := b.byRef[req.Reference]; ok && b.txs[id].Status != "REJECTED" {
+		tx := *b.txs[id]
+		b.mu.Unlock()
+		writeJSON(w, http.StatusOK, tx)
+		return
+	}
+	b.seq++
+	id := fmt.Sprintf("PSE-%06d", b.seq)
+	tx := &transaction{
+		ID:          id,
+		Reference:   req.Reference,
+		AmountCents: req.AmountCents,
+		Description: req.Description,
+		Status:      "PENDING",
+		RedirectURL: b.cfg.PublicURL + "/pse/" + id,
+		ReturnURL:   req.ReturnURL,
+	}
+	b.txs[id] = tx
+	b.byRef[req.Reference] = id
+	if req.Key != "" {
+		b.byKey[req.Key] = id
+	}
+	out := *tx
+	b.mu.Unlock()
+
+	if b.cfg.AutoResolve > 0 {
+		time.AfterFunc(b.cfg.AutoResolve, func() { b.autoResolve(id) })
+	}
+	slog.Info("tx creada", "tx", id, "ref", req.Reference, "key", req.Key, "amount_cents", req.AmountCents)
+	writeJSON(w, http.StatusCreated, out)
+}
+
+func (b *bank) handleGet(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	tx, ok := b.txs[r.PathValue("id")]
+	var out transaction
+	if ok {
+		out = *tx
+	}
+	b.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (b *bank) handleRefund(w http.ResponseWriter, r *http.Request) {
+	var req refundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || (req.TransactionID == "" && req.Reference == "") {
+		http.Error(w, "transaction_id o reference es obligatorio", http.StatusBadRequest)
+		return
+	}
+	if req.Key == "" {
+		req.Key = r.Header.Get("Idempotency-Key")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// Una clave ya aprobada devuelve el mismo reembolso; un rechazo no se
+	// guarda, así la misma clave puede intentarlo otra vez
+	if rf, ok := b.rfByKey[req.Key]; ok && req.Key != "" {
+		if rf.AmountCents != req.AmountCents {
+			http.Error(w, "idempotency_key reutilizada con otro monto", http.StatusUnprocessableEntity)
+			return
+		}
+		writeJSON(w, http.StatusOK, rf)
+		return
+	}
+	b.refunds++
+	rf := refund{ID: fmt.Sprintf("PSE-RFD-%06d", b.refunds), Status: "REJECTED"}
+	id := req.TransactionID
+	if id == "" {
+		id = b.byRef[req.Reference]
+	}
+	tx := b.txs[id]
+	switch {
+	case tx != nil && req.Reference != "" && tx.Reference != req.Reference:
+		rf.Reason = "reference_mismatch"
+	case tx == nil || tx.Status != "APPROVED":
+		rf.Reason = "transaction_not_approved"
+	case req.AmountCents <= 0 || tx.RefundedCents+req.AmountCents > tx.AmountCents:
+		rf.Reason = "amount_exceeds_balance"
+	default:
+		tx.RefundedCents += req.AmountCents
+		rf.Status = "APPROVED"
+		rf.AmountCents = req.AmountCents
+		b.settle(rf.ID, "REFUND", req.AmountCents)
+		if req.Key != "" {
+			b.rfByKey[req.Key] = rf
+		}
+	}
+	slog.Info("reembolso", "refund", rf.ID, "tx", id, "ref", req.Reference, "key", req.Key, "amount_cents", req.AmountCents, "status", rf.Status, "reason", rf.Reason)
+	writeJSON(w, http.StatusOK, rf)
+}
+
+// settle anota un movimiento aprobado en la liquidación del día; se llama con b.mu tomado.
+func (b *bank) settle(ref, typ string, amountCents int64) {
+	b.settled = append(b.settled, settlement{
+		Date:        time.Now().UTC().Format("2006-01-02"),
+		ProviderRef: ref,
+		Type:        typ,
+		AmountCents: amountCents,
+	})
+}
+
+// handleSettlements entrega la liquidación de un día (?date=AAAA-MM-DD, por
+// defecto hoy) en el CSV que concilia el servicio Payment.
+func (b *bank) handleSettlements(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = time.Now().UTC().Format("2006-01-02")
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		http.Error(w, "date debe ser AAAA-MM-DD", http.StatusBadRequest)
+		return
+	}
+	b.mu.Lock()
+	var rows []settlement
+	for _, st := range b.settled {
+		if st.Date == date {
+			rows = append(rows, st)
+		}
+	}
+	b.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="pse-settlement-%s.csv"`, date))
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"date", "provider_ref", "type", "amount_cents"})
+	for _, st := range rows {
+		cw.Write([]string{st.Date, st.ProviderRef, st.Type, strconv.FormatInt(st.AmountCents, 10)})
+	}
+	cw.Flush()
+}
+
+var pageTpl = template.Must(template.New("pse").Parse(`<!doctype html>
+<html lang="es"><head><meta charset="utf-8"><title>Banco simulado · PSE</title>
+<style>body{font-family:sans-serif;max-width:480px;margin:3rem auto}button{padding:.6rem 1.2rem;margin-right:.5rem}</style>
+</head><body>
+<h2>🏦 Banco simulado (PSE)</h2>
+<p><strong>{{.Description}}</strong></p>
+<p>Referencia: {{.Reference}}<br>Monto: ${{.Pesos}}<br>Estado: <strong>{{.Status}}</strong> {{.Reason}}</p>
+{{if eq .Status "PENDING"}}
+<form method="post">
+  <button name="decision" value="approve">Aprobar pago</button>
+  <button name="decision" value="reject">Rechazar</button>
+</form>
+{{else if .ReturnURL}}
+<p><a href="{{.ReturnURL}}">Volver al comercio</a></p>
+{{end}}
+</body></html>`))
+
+func (b *bank) handlePage(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	tx, ok := b.txs[r.PathValue("id")]
+	var out transaction
+	if ok {
+		out = *tx
+	}
+	b.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	pageTpl.Execute(w, struct {
+		transaction
+		Pesos string
+	}{out, fmt.Sprintf("%d.%02d", out.AmountCents/100, out.AmountCents%100)})
+}
+
+func (b *bank) handleDecision(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	approve := r.FormValue("decision") == "approve"
+	reason := ""
+	if !approve {
+		reason = "rejected_by_customer"
+	}
+	tx, ok := b.resolve(id, approve, reason)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if tx.ReturnURL != "" {
+		http.Redirect(w, r, tx.ReturnURL, http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/pse/"+id, http.StatusSeeOther)
+}
+
+func (b *bank) autoResolve(id string) {
+	b.mu.Lock()
+	declined := b.rnd.Float64() < b.cfg.DeclineRate
+	b.mu.Unlock()
+	if declined {
+		b.resolve(id, false, "declined_by_bank")
+		return
+	}
+	b.resolve(id, true, "")
+}
+
+// resolve fija el resultado de una transacción pendiente; las ya resueltas no cambian.
+func (b *bank) resolve(id string, approve bool, reason string) (transaction, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tx, ok := b.txs[id]
+	if !ok {
+		return transaction{}, false
+	}
+	if tx.Status == "PENDING" {
+		tx.Status, tx.Reason = "REJECTED", reason
+		if approve {
+			tx.Status, tx.Reason = "APPROVED", ""
+			b.settle(tx.ID, "CHARGE", tx.AmountCents)
+		}
+		slog.Info("tx resuelta", "tx", id, "status", tx.Status, "reason", tx.Reason)
+		go b.notify(*tx)
+	}
+	return *tx, true
+}
+
+// notify avisa el resultado al comercio con reintentos y backoff. El event_id
+// es el mismo en cada reintento para que el comercio pueda deduplicar.
+func (b *bank) notify(tx transaction) {
+	if b.cfg.WebhookURL == "" {
+		return
+	}
+	body, _ := json.Marshal(map[string]any{
+		"event_id":    fmt.Sprintf("evt-%s-%s", tx.ID, strings.ToLower(tx.Status)),
+		"type":        "transaction.updated",
+		"transaction": tx,
+	})
+	b.mu.Lock()
+	deliveries := 1
+	if b.rnd.Float64() < b.cfg.WebhookDupRate {
+		deliveries = 2
+	}
+	b.mu.Unlock()
+
+	for n := 0; n < deliveries; n++ {
+		backoff := time.Second
+		for attempt := 1; attempt <= 5; attempt++ {
+			err := b.postWebhook(body)
+			if err == nil {
+				break
+			}
+			slog.Warn("webhook", "tx", tx.ID, "attempt", attempt, "err", err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+}
+
+func (b *bank) postWebhook(body []byte) error {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(b.cfg.WebhookSecret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+
+	req, err := http.NewRequest(http.MethodPost, b.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Timestamp", ts)
+	req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("respuesta %s", resp.Status)
+	}
+	return nil
+}
+
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
+
+func getDuration(k string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(k)); err == nil {
+		return d
+	}
+	return def
+}
+
+func getFloat(k string, def float64) float64 {
+	if f, err := strconv.ParseFloat(os.Getenv(k), 64); err == nil {
+		return f
+	}
+	return def
+}
+
+func main() {
+	// Mismo formato JSON que los servicios (sin correlation_id: es el banco)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)).With("service", "psesim"))
+	cfg := loadConfig()
+	srv := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           newBank(cfg).routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	slog.Info("psesim escuchando", "http", cfg.Addr, "latency", cfg.Latency.String(), "decline", cfg.DeclineRate,
+		"timeout", cfg.TimeoutRate, "auto_resolve", cfg.AutoResolve.String())
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		slog.Error("http", "err", err)
+		os.Exit(1)
+	}
+}
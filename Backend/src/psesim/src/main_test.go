@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// newTestBank levanta el simulador sin latencia, timeouts ni resolución automática.
+func newTestBank(t *testing.T) (*bank, *httptest.Server) {
+	t.Helper()
+	b := newBank(config{PublicURL: "http://psesim", Seed: 1})
+	srv := httptest.NewServer(b.routes())
+	t.Cleanup(srv.Close)
+	return b, srv
+}
+
+// post manda body como JSON y decodifica la respuesta en out si es 2xx.
+func post(t *testing.T, srv *httptest.Server, path, key string, body, out any) int {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+path, bytes.NewReader(raw))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 && out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func create(t *testing.T, srv *httptest.Server, ref, key string, amount int64) transaction {
+	t.Helper()
+	var tx transaction
+	if code := post(t, srv, "/api/transactions", key, map[string]any{"reference": ref, "amount_cents": amount}, &tx); code >= 300 {
+		t.Fatalf("crear %s = %d", ref, code)
+	}
+	return tx
+}
+
+// decide aprueba o rechaza la transacción desde la página del banco.
+func decide(t *testing.T, srv *httptest.Server, id, decision string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.PostForm(srv.URL+"/pse/"+id, url.Values{"decision": {decision}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("decisión sobre %s = %d", id, resp.StatusCode)
+	}
+}
+
+func TestCreateIdempotency(t *testing.T) {
+	_, srv := newTestBank(t)
+
+	tx := create(t, srv, "order-1", "order:1:attempt:1", 1500)
+	if tx.Status != "PENDING" || tx.RedirectURL != "http://psesim/pse/"+tx.ID {
+		t.Fatalf("transacción = %+v", tx)
+	}
+	if again := create(t, srv, "order-1", "order:1:attempt:1", 1500); again.ID != tx.ID {
+		t.Fatalf("la misma clave creó otra transacción: %s", again.ID)
+	}
+	if code := post(t, srv, "/api/transactions", "order:1:attempt:1", map[string]any{"reference": "order-1", "amount_cents": 900}, nil); code != http.StatusUnprocessableEntity {
+		t.Fatalf("clave reutilizada con otro monto = %d", code)
+	}
+	if code := post(t, srv, "/api/transactions", "", map[string]any{"reference": "order-2"}, nil); code != http.StatusBadRequest {
+		t.Fatalf("sin monto = %d", code)
+	}
+
+	// La referencia pendiente no se cobra dos veces; rechazada sí admite otro intento
+	if other := create(t, srv, "order-1", "order:1:attempt:2", 1500); other.ID != tx.ID {
+		t.Fatalf("la referencia pendiente abrió otra transacción: %s", other.ID)
+	}
+	decide(t, srv, tx.ID, "reject")
+	retry := create(t, srv, "order-1", "order:1:attempt:3", 1500)
+	if retry.ID == tx.ID {
+		t.Fatal("un intento nuevo tras el rechazo devolvió la transacción rechazada")
+	}
+	var got transaction
+	resp, err := http.Get(srv.URL + "/api/transactions/" + tx.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	json.NewDecoder(resp.Body).Decode(&got)
+	if got.Status != "REJECTED" || got.Reason != "rejected_by_customer" {
+		t.Fatalf("transacción rechazada = %+v", got)
+	}
+}
+
+func TestRefunds(t *testing.T) {
+	b, srv := newTestBank(t)
+	tx := create(t, srv, "order-7", "order:7:attempt:1", 1000)
+
+	requestRefund := func(key string, body map[string]any) (refund, int) {
+		t.Helper()
+		var rf refund
+		code := post(t, srv, "/api/refunds", key, body, &rf)
+		return rf, code
+	}
+
+	if rf, _ := requestRefund("return:1", map[string]any{"transaction_id": tx.ID, "amount_cents": 400}); rf.Status != "REJECTED" || rf.Reason != "transaction_not_approved" {
+		t.Fatalf("reembolso de una transacción pendiente = %+v", rf)
+	}
+	decide(t, srv, tx.ID, "approve")
+
+	cases := []struct {
+		name   string
+		key    string
+		body   map[string]any
+		status string
+		reason string
+	}{
+		{"aprobado", "return:1", map[string]any{"transaction_id": tx.ID, "reference": "order-7", "amount_cents": 400}, "APPROVED", ""},
+		{"referencia ajena", "return:2", map[string]any{"transaction_id": tx.ID, "reference": "order-8", "amount_cents": 100}, "REJECTED", "reference_mismatch"},
+		{"excede el saldo", "return:3", map[string]any{"transaction_id": tx.ID, "amount_cents": 700}, "REJECTED", "amount_exceeds_balance"},
+		{"por referencia", "return:4", map[string]any{"reference": "order-7", "amount_cents": 600}, "APPROVED", ""},
+	}
+	ids := map[string]string{}
+	for _, tc := range cases {
+		rf, code := requestRefund(tc.key, tc.body)
+		if code != http.StatusOK || rf.Status != tc.status || rf.Reason != tc.reason {
+			t.Fatalf("%s: %d %+v, se esperaba %s %q", tc.name, code, rf, tc.status, tc.reason)
+		}
+		ids[tc.key] = rf.ID
+	}
+
+	// Repetir una clave aprobada devuelve el mismo reembolso sin volver a descontar
+	rf, _ := requestRefund("return:1", map[string]any{"transaction_id": tx.ID, "amount_cents": 400})
+	if rf.ID != ids["return:1"] || rf.Status != "APPROVED" {
+		t.Fatalf("reintento con la misma clave = %+v", rf)
+	}
+	if _, code := requestRefund("return:1", map[string]any{"transaction_id": tx.ID, "amount_cents": 300}); code != http.StatusUnprocessableEntity {
+		t.Fatalf("clave reutilizada con otro monto = %d", code)
+	}
+	b.mu.Lock()
+	refunded := b.txs[tx.ID].RefundedCents
+	b.mu.Unlock()
+	if refunded != 1000 {
+		t.Fatalf("reembolsado = %d, se esperaba 1000", refunded)
+	}
+
+	// La liquidación tiene el cobro y los dos reembolsos aprobados
+	resp, err := http.Get(srv.URL + "/api/settlements")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(resp.Body)
+	for _, want := range []string{tx.ID + ",CHARGE,1000", ids["return:1"] + ",REFUND,400", ids["return:4"] + ",REFUND,600"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("liquidación sin %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,177 @@
+"""Autenticación de los RPC, con el mismo esquema que pkg/auth en Go.
+
+Cada llamada trae en el metadata "authorization: Bearer <token>", un JWT HS256
+firmado con GRPC_AUTH_SECRET (o con una de GRPC_AUTH_PREVIOUS_SECRETS mientras
+se rota la clave). El header lleva kid = hex(sha256("mybookstore-grpc:" +
+secreto)[:4]) para elegir la clave sin revelarla.
+
+El interceptor valida firma y vigencia y deja el Principal en un ContextVar.
+Sin token solo pasan los métodos de PUBLIC. Un usuario solo puede pedir por su
+propio user_id; servicios internos y admins, por cualquiera (CheckUser en Go).
+"""
+import base64
+import binascii
+import contextvars
+import hashlib
+import hmac
+import json
+import os
+import time
+from dataclasses import dataclass, field
+from typing import List, Optional
+
+import grpc
+
+ROLE_ADMIN = "admin"
+MIN_SECRET_LEN = 32
+MAX_SKEW = 60  # segundos de desfase tolerados en iat, como maxSkew en Go
+
+# Alta y login (todavía no hay sesión), health check y reflexión de grpcurl
+PUBLIC = (
+    "/user.User/Register",
+    "/user.User/Authenticate",
+    "/grpc.health.v1.Health/",
+    "/grpc.reflection.",
+)
+
+
+@dataclass
+class Principal:
+    user_id: int = 0
+    service: str = ""
+    roles: List[str] = field(default_factory=list)
+
+    def is_admin(self) -> bool:
+        return ROLE_ADMIN in self.roles
+
+
+_principal: contextvars.ContextVar[Optional[Principal]] = contextvars.ContextVar("principal", default=None)
+
+
+def principal() -> Optional[Principal]:
+    """El Principal autenticado de la llamada en curso, o None."""
+    return _principal.get()
+
+
+class TokenError(Exception):
+    """Token mal formado, con firma inválida o vencido; el motivo no se expone."""
+
+
+def _key_id(purpose: str, secret: str) -> str:
+    return hashlib.sha256(f"mybookstore-{purpose}:{secret}".encode()).hexdigest()[:8]
+
+
+def _b64decode(part: str) -> bytes:
+    return base64.b64decode(part + "=" * (-len(part) % 4), altchars=b"-_", validate=True)
+
+
+class Authority:
+    """Verifica los tokens de gRPC. No los emite: User no llama a otros servicios."""
+
+    def __init__(self, secret: str, previous: List[str] = ()):
+        secrets = [s for s in [secret, *previous] if s]
+        if not secrets or any(len(s) < MIN_SECRET_LEN for s in secrets):
+            raise ValueError(f"GRPC_AUTH_SECRET y GRPC_AUTH_PREVIOUS_SECRETS necesitan al menos {MIN_SECRET_LEN} caracteres")
+        self.keys = {_key_id("grpc", s): s.encode() for s in secrets}
+
+    def verify(self, token: str, now: Optional[float] = None) -> Principal:
+        parts = token.split(".")
+        if len(parts) != 3:
+            raise TokenError()
+        try:
+            header = json.loads(_b64decode(parts[0]))
+            key = self.keys.get(header.get("kid")) if header.get("alg") == "HS256" else None
+            if key is None:
+                raise TokenError()
+            mac = hmac.new(key, f"{parts[0]}.{parts[1]}".encode(), hashlib.sha256).digest()
+            if not hmac.compare_digest(mac, _b64decode(parts[2])):
+                raise TokenError()
+            claims = json.loads(_b64decode(parts[1]))
+            now = int(time.time() if now is None else now)
+            if now >= int(claims["exp"]) or int(claims["iat"]) > now + MAX_SKEW:
+                raise TokenError()
+            p = Principal(service=claims.get("svc") or "", roles=list(claims.get("roles") or []))
+            if claims.get("sub"):
+                p.user_id = int(claims["sub"])
+                if p.user_id <= 0:
+                    raise TokenError()
+        except (ValueError, KeyError, TypeError, AttributeError, binascii.Error):
+            raise TokenError()
+        if not p.user_id and not p.service:
+            raise TokenError()
+        return p
+
+
+def from_env() -> Authority:
+    previous = [s.strip() for s in os.getenv("GRPC_AUTH_PREVIOUS_SECRETS", "").split(",") if s.strip()]
+    return Authority(os.getenv("GRPC_AUTH_SECRET", ""), previous)
+
+
+def _match(method: str) -> bool:
+    return any(method == m or (m[-1] in "/." and method.startswith(m)) for m in PUBLIC)
+
+
+def _deny(handler, code: grpc.StatusCode, details: str):
+    """Un handler del mismo tipo que handler que responde con el error."""
+    def abort(request_or_iterator, context):
+        context.abort(code, details)
+
+    if handler.unary_unary is not None:
+        make = grpc.unary_unary_rpc_method_handler
+    elif handler.unary_stream is not None:
+        make = grpc.unary_stream_rpc_method_handler
+    elif handler.stream_unary is not None:
+        make = grpc.stream_unary_rpc_method_handler
+    else:
+        make = grpc.stream_stream_rpc_method_handler
+    return make(abort, request_deserializer=handler.request_deserializer,
+                response_serializer=handler.response_serializer)
+
+
+class AuthInterceptor(grpc.ServerInterceptor):
+    """Valida el token de cada llamada y que un usuario solo pida por su user_id.
+
+    Un token presente pero inválido se rechaza incluso en los métodos públicos.
+    """
+
+    def __init__(self, authority: Authority):
+        self.authority = authority
+
+    def intercept_service(self, continuation, handler_call_details):
+        handler = continuation(handler_call_details)
+        if handler is None:
+            return handler
+        method = handler_call_details.method
+        value = dict(handler_call_details.invocation_metadata or ()).get("authorization")
+        p = None
+        if value is not None:
+            if not value.startswith("Bearer "):
+                return _deny(handler, grpc.StatusCode.UNAUTHENTICATED, "se esperaba authorization: Bearer <token>")
+            try:
+                p = self.authority.verify(value[len("Bearer "):])
+            except TokenError:
+                return _deny(handler, grpc.StatusCode.UNAUTHENTICATED, "token inválido o vencido")
+        if p is None and not _match(method):
+            return _deny(handler, grpc.StatusCode.UNAUTHENTICATED, "falta el token de autenticación")
+        # Los únicos streams son los de reflexión, que son públicos
+        if handler.unary_unary is None:
+            return handler
+        behavior = handler.unary_unary
+
+        # El handler corre en un hilo del pool: el ContextVar se fija ahí
+        def with_principal(request, context):
+            if p is not None and not p.service and not p.is_admin():
+                fields = request.DESCRIPTOR.fields_by_name
+                if "user_id" in fields and request.user_id != p.user_id:
+                    context.abort(grpc.StatusCode.PERMISSION_DENIED, "el recurso pertenece a otro usuario")
+            token = _principal.set(p)
+            try:
+                return behavior(request, context)
+            finally:
+                _principal.reset(token)
+
+        return grpc.unary_unary_rpc_method_handler(
+            with_principal,
+            request_deserializer=handler.request_deserializer,
+            response_serializer=handler.response_serializer,
+        )
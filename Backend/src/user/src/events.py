@@ -1,10 +1,14 @@
 import json
 import logging
+import time
 from datetime import datetime
 from typing import Any, Dict, Optional
 import pika
 from pika.exceptions import AMQPConnectionError, AMQPChannelError
 
+from . import logs
+from . import metrics
+
 
 class UserCreated:
     def __init__(self, user_id: int, name: str, email: str):
@@ -42,9 +46,9 @@ class EventPublisher:
             try:
                 self._connect()
                 self._setup_exchange()
-                logging.info("[user] RabbitMQ event publisher initialized")
+                logging.info("RabbitMQ event publisher initialized")
             except Exception as e:
-                logging.warning(f"[user] WARN: RabbitMQ not available ({e}). Continuing without events.")
+                logging.warning(f"RabbitMQ not available ({e}). Continuing without events.")
                 self.connection = None
                 self.channel = None
     
@@ -57,7 +61,7 @@ class EventPublisher:
             self.connection = pika.BlockingConnection(parameters)
             self.channel = self.connection.channel()
         except AMQPConnectionError as e:
-            logging.error(f"[user] Failed to connect to RabbitMQ: {e}")
+            logging.error(f"Failed to connect to RabbitMQ: {e}")
             raise
     
     def _setup_exchange(self):
@@ -69,12 +73,12 @@ class EventPublisher:
                     durable=True
                 )
             except AMQPChannelError as e:
-                logging.error(f"[user] Failed to declare exchange: {e}")
+                logging.error(f"Failed to declare exchange: {e}")
                 raise
     
     def publish(self, event_type: str, payload: Any) -> bool:
         if not self.channel:
-            logging.debug(f"[user] No RabbitMQ connection, skipping event: {event_type}")
+            logging.debug(f"No RabbitMQ connection, skipping event: {event_type}")
             return False
         
         try:
@@ -97,15 +101,19 @@ class EventPublisher:
                 body=json.dumps(message),
                 properties=pika.BasicProperties(
                     content_type='application/json',
-                    delivery_mode=2
+                    delivery_mode=2,
+                    headers=logs.headers(),
+                    timestamp=int(time.time())
                 )
             )
             
-            logging.info(f"[user] publish event {event_type}")
+            logging.info(f"publish event {event_type}")
+            metrics.published(event_type, True)
             return True
             
         except Exception as e:
-            logging.error(f"[user] Failed to publish event {event_type}: {e}")
+            logging.error(f"Failed to publish event {event_type}: {e}")
+            metrics.published(event_type, False)
             return False
     
     def close(self):
@@ -114,9 +122,9 @@ class EventPublisher:
                 self.channel.close()
             if self.connection and not self.connection.is_closed:
                 self.connection.close()
-            logging.info("[user] RabbitMQ connection closed")
+            logging.info("RabbitMQ connection closed")
         except Exception as e:
-            logging.warning(f"[user] Error closing RabbitMQ connection: {e}")
+            logging.warning(f"Error closing RabbitMQ connection: {e}")
 
 
 def new_event_publisher(rabbit_url: str) -> EventPublisher:
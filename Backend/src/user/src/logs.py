@@ -0,0 +1,97 @@
+"""Logs JSON con correlation_id, en el mismo formato que pkg/logging en Go.
+
+El nivel sale de USER_LOG_LEVEL (debug, info, warn, error). El correlation_id
+llega en el metadata x-correlation-id de cada RPC; el interceptor lo deja en un
+ContextVar del hilo que atiende la llamada, cada línea de log lo incluye y los
+eventos publicados lo llevan en sus headers AMQP.
+"""
+import contextvars
+import json
+import logging
+import os
+import re
+import secrets
+import sys
+from datetime import datetime, timezone
+from typing import Dict, Optional
+
+import grpc
+
+HEADER = "x-correlation-id"
+
+_correlation_id: contextvars.ContextVar[str] = contextvars.ContextVar("correlation_id", default="")
+_valid_id = re.compile(r"^[A-Za-z0-9_-]{1,64}$")
+
+# Nombres de nivel de slog
+_LEVELS = {"debug": logging.DEBUG, "info": logging.INFO, "warn": logging.WARNING,
+           "warning": logging.WARNING, "error": logging.ERROR}
+_NAMES = {logging.DEBUG: "DEBUG", logging.INFO: "INFO", logging.WARNING: "WARN",
+          logging.ERROR: "ERROR", logging.CRITICAL: "ERROR"}
+
+
+def correlation_id() -> str:
+    return _correlation_id.get()
+
+
+def headers() -> Optional[Dict[str, str]]:
+    """Headers AMQP que propagan el correlation_id actual (None si no hay)."""
+    cid = _correlation_id.get()
+    return {HEADER: cid} if cid else None
+
+
+class JsonFormatter(logging.Formatter):
+    def __init__(self, service: str):
+        super().__init__()
+        self.service = service
+
+    def format(self, record: logging.LogRecord) -> str:
+        out = {
+            "time": datetime.fromtimestamp(record.created, timezone.utc).isoformat(timespec="milliseconds"),
+            "level": _NAMES.get(record.levelno, record.levelname),
+            "msg": record.getMessage(),
+            "service": self.service,
+        }
+        cid = _correlation_id.get()
+        if cid:
+            out["correlation_id"] = cid
+        if record.exc_info:
+            out["err"] = self.formatException(record.exc_info)
+        return json.dumps(out, ensure_ascii=False)
+
+
+def setup(service: str) -> None:
+    key = f"{service.upper()}_LOG_LEVEL"
+    value = os.getenv(key, "").strip().lower()
+    handler = logging.StreamHandler(sys.stdout)
+    handler.setFormatter(JsonFormatter(service))
+    root = logging.getLogger()
+    root.handlers = [handler]
+    root.setLevel(_LEVELS.get(value, logging.INFO))
+    if value and value not in _LEVELS:
+        logging.warning(f"nivel de log inválido en {key}={value!r}; uso info")
+
+
+class CorrelationInterceptor(grpc.ServerInterceptor):
+    """Toma el correlation_id del metadata (o genera uno) para toda la llamada."""
+
+    def intercept_service(self, continuation, handler_call_details):
+        handler = continuation(handler_call_details)
+        if handler is None or handler.unary_unary is None:
+            return handler
+        incoming = dict(handler_call_details.invocation_metadata or ()).get(HEADER, "")
+        cid = incoming if _valid_id.match(incoming) else secrets.token_hex(8)
+        behavior = handler.unary_unary
+
+        # El handler corre en un hilo del pool: el ContextVar se fija ahí
+        def with_correlation(request, context):
+            token = _correlation_id.set(cid)
+            try:
+                return behavior(request, context)
+            finally:
+                _correlation_id.reset(token)
+
+        return grpc.unary_unary_rpc_method_handler(
+            with_correlation,
+            request_deserializer=handler.request_deserializer,
+            response_serializer=handler.response_serializer,
+        )
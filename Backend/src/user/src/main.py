@@ -23,45 +23,52 @@ from .config import load_config
 from .repository import new_user_repository
 from .events import new_event_publisher
 from .server import new_user_service
-
-
-def setup_logging():
-    logging.basicConfig(
-        level=logging.INFO,
-        format='%(asctime)s - %(name)s - %(levelname)s - %(message)s',
-        handlers=[
-            logging.StreamHandler(sys.stdout)
-        ]
-    )
+from .mtls import server_credentials
+from . import auth
+from . import logs
+from . import metrics
 
 
 def signal_handler(signum, frame):
-    logging.info("[user] Received shutdown signal, stopping server...")
+    logging.info("Received shutdown signal, stopping server...")
     sys.exit(0)
 
 
 def main():
-    setup_logging()
-    logging.info("[user] Starting User service...")
+    # JSON con correlation_id (USER_LOG_LEVEL), igual que los servicios Go
+    logs.setup("user")
+    # /metrics en USER_METRICS_ADDR, con los nombres de pkg/metrics
+    metrics.setup("user")
+    logging.info("Starting User service...")
     
     signal.signal(signal.SIGINT, signal_handler)
     signal.signal(signal.SIGTERM, signal_handler)
     
     try:
         cfg = load_config()
+        # Mismos tokens que los servicios Go (pkg/auth)
+        authority = auth.from_env()
         
         repo = new_user_repository(cfg.db_path)
-        logging.info(f"[user] Database initialized: {cfg.db_path}")
+        logging.info(f"Database initialized: {cfg.db_path}")
         
         pub = new_event_publisher(cfg.rabbit_url)
         if pub.connection:
-            logging.info("[user] RabbitMQ connection established")
+            logging.info("RabbitMQ connection established")
         else:
-            logging.warning("[user] RabbitMQ not available, continuing without events")
+            logging.warning("RabbitMQ not available, continuing without events")
         
         svc = new_user_service(repo, pub)
         
-        server = grpc.server(futures.ThreadPoolExecutor(max_workers=10))
+        # Acepta los pings de keepalive de los clientes Go (pkg/grpcclient)
+        server = grpc.server(
+            futures.ThreadPoolExecutor(max_workers=10),
+            interceptors=[logs.CorrelationInterceptor(), metrics.MetricsInterceptor(), auth.AuthInterceptor(authority)],
+            options=[
+                ("grpc.keepalive_permit_without_calls", 1),
+                ("grpc.http2.min_ping_interval_without_data_ms", 20000),
+            ],
+        )
         
         user_pb2_grpc.add_UserServicer_to_server(svc, server)
         
@@ -71,22 +78,26 @@ def main():
         reflection.enable_server_reflection(service_names, server)
         
         listen_addr = f"[::]:{cfg.grpc_port}"
-        server.add_insecure_port(listen_addr)
+        creds = server_credentials(cfg)
+        if creds is not None:
+            server.add_secure_port(listen_addr, creds)
+        else:
+            server.add_insecure_port(listen_addr)
         server.start()
         
-        logging.info(f"[user] gRPC listening on :{cfg.grpc_port}")
+        logging.info(f"gRPC listening on :{cfg.grpc_port}")
         
         try:
             server.wait_for_termination()
         except KeyboardInterrupt:
-            logging.info("[user] Received interrupt signal")
+            logging.info("Received interrupt signal")
         finally:
             pub.close()
             server.stop(grace=5)
-            logging.info("[user] Server stopped")
+            logging.info("Server stopped")
     
     except Exception as e:
-        logging.error(f"[user] Fatal error: {e}")
+        logging.error(f"Fatal error: {e}")
         sys.exit(1)
 
 
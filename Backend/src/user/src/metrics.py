@@ -0,0 +1,86 @@
+"""Métricas Prometheus con los mismos nombres y etiquetas que pkg/metrics en Go.
+
+Se sirven en GET /metrics en USER_METRICS_ADDR (":9090" por defecto; "off" no
+abre el puerto). Todas las series llevan la etiqueta service="user".
+"""
+import logging
+import os
+import time
+
+import grpc
+from prometheus_client import CollectorRegistry, Counter, Histogram, start_http_server
+from prometheus_client import GC_COLLECTOR, PLATFORM_COLLECTOR, PROCESS_COLLECTOR
+
+REGISTRY = CollectorRegistry()
+for _collector in (GC_COLLECTOR, PLATFORM_COLLECTOR, PROCESS_COLLECTOR):
+    REGISTRY.register(_collector)
+
+_SERVICE = "user"
+
+RPC_SERVER = Histogram(
+    "mybookstore_grpc_server_handling_seconds",
+    "Duración de los RPC atendidos, por método y código de respuesta.",
+    ["service", "method", "code"], registry=REGISTRY,
+)
+PUBLISHED = Counter(
+    "mybookstore_messages_published_total",
+    "Mensajes publicados, por routing key y resultado (ok | error).",
+    ["service", "routing_key", "result"], registry=REGISTRY,
+)
+
+
+def setup(service: str) -> None:
+    global _SERVICE
+    _SERVICE = service
+    addr = os.getenv(f"{service.upper()}_METRICS_ADDR", ":9090").strip() or ":9090"
+    if addr == "off":
+        return
+    host, _, port = addr.rpartition(":")
+    start_http_server(int(port), addr=host or "0.0.0.0", registry=REGISTRY)
+    logging.info(f"metrics listening on {addr}")
+
+
+def published(routing_key: str, ok: bool) -> None:
+    PUBLISHED.labels(_SERVICE, routing_key, "ok" if ok else "error").inc()
+
+
+def _code(context) -> str:
+    # Mismo texto que codes.Code.String() de grpc-go (OK, NotFound, ...)
+    code = context.code()
+    if code is None or code == grpc.StatusCode.OK:
+        return "OK"
+    return "".join(part.capitalize() for part in code.name.split("_"))
+
+
+class MetricsInterceptor(grpc.ServerInterceptor):
+    """Mide cada RPC unario; el code sale del que fijó el handler o de la excepción."""
+
+    def intercept_service(self, continuation, handler_call_details):
+        handler = continuation(handler_call_details)
+        if handler is None or handler.unary_unary is None:
+            return handler
+        method = handler_call_details.method
+        if method.startswith("/grpc.health.v1.Health/"):
+            return handler
+        behavior = handler.unary_unary
+
+        def measured(request, context):
+            start = time.perf_counter()
+            failed = False
+            try:
+                return behavior(request, context)
+            except Exception:
+                failed = True
+                raise
+            finally:
+                code = _code(context)
+                if failed and code == "OK":
+                    code = "Unknown"  # excepción sin context.abort: grpc responde UNKNOWN
+                RPC_SERVER.labels(_SERVICE, method, code).observe(time.perf_counter() - start)
+
+        return grpc.unary_unary_rpc_method_handler(
+            measured,
+            request_deserializer=handler.request_deserializer,
+            response_serializer=handler.response_serializer,
+        )
+
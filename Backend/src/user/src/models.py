@@ -1,6 +1,6 @@
 from datetime import datetime
 from typing import Optional
-from sqlalchemy import Column, Integer, String, DateTime, create_engine
+from sqlalchemy import Column, Integer, String, DateTime, Boolean, ForeignKey, create_engine
 from sqlalchemy.ext.declarative import declarative_base
 from sqlalchemy.orm import sessionmaker
 
@@ -29,4 +29,43 @@ class User(Base):
             'password_hash': self.password_hash,
             'created_at': self.created_at,
             'updated_at': self.updated_at
-        }
\ No newline at end of file
+        }
+
+
+class Address(Base):
+    __tablename__ = "addresses"
+    
+    id = Column(Integer, primary_key=True, autoincrement=True)
+    user_id = Column(Integer, ForeignKey("users.id", ondelete="CASCADE"), nullable=False, index=True)
+    label = Column(String, nullable=False, default="")
+    recipient = Column(String, nullable=False)
+    line1 = Column(String, nullable=False)
+    line2 = Column(String, nullable=False, default="")
+    city = Column(String, nullable=False)
+    region = Column(String, nullable=False, default="")
+    postal_code = Column(String, nullable=False, default="")
+    country = Column(String, nullable=False)
+    phone = Column(String, nullable=False, default="")
+    is_default = Column(Boolean, nullable=False, default=False)
+    created_at = Column(DateTime, nullable=False, default=datetime.utcnow)
+    
+    def __repr__(self):
+        return f"<Address(id={self.id}, user_id={self.user_id}, label='{self.label}')>"
+
+
+class WebSession(Base):
+    """Sesión web de los frontends. El id viaja dentro del token firmado."""
+    __tablename__ = "sessions"
+    
+    id = Column(String, primary_key=True)
+    user_id = Column(Integer, ForeignKey("users.id", ondelete="CASCADE"), nullable=False, index=True)
+    created_at = Column(DateTime, nullable=False, default=datetime.utcnow)
+    expires_at = Column(DateTime, nullable=False)
+    revoked_at = Column(DateTime, nullable=True)
+    replaced_by = Column(String, nullable=False, default="")   # sesión nueva tras una rotación
+    
+    def is_active(self, now: datetime) -> bool:
+        return self.revoked_at is None and self.expires_at > now
+    
+    def __repr__(self):
+        return f"<WebSession(id='{self.id[:6]}…', user_id={self.user_id})>"
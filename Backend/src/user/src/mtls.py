@@ -0,0 +1,49 @@
+"""mTLS del servidor gRPC, con las mismas variables que pkg/mtls en Go.
+
+Con GRPC_TLS_MODE=mtls el servicio presenta GRPC_TLS_DIR/user.crt (y user.key)
+y exige un certificado de cliente firmado por GRPC_TLS_DIR/ca.crt. Los archivos
+se releen cuando cambian, como mucho cada GRPC_TLS_RELOAD_INTERVAL.
+La restricción de peers por RPC (mtls.Peers en Go) no está implementada aquí.
+"""
+import logging
+import os
+import time
+from typing import Optional
+
+import grpc
+
+
+def server_credentials(cfg) -> Optional[grpc.ServerCredentials]:
+    if cfg.tls_mode == "off":
+        return None
+    if cfg.tls_mode != "mtls":
+        raise ValueError(f"GRPC_TLS_MODE={cfg.tls_mode!r} (off | mtls)")
+
+    paths = [os.path.join(cfg.tls_dir, name) for name in ("user.crt", "user.key", "ca.crt")]
+    state = {"mtime": 0.0, "checked": time.monotonic()}
+
+    def load():
+        crt, key, ca = (open(p, "rb").read() for p in paths)
+        state["mtime"] = max(os.path.getmtime(p) for p in paths)
+        return grpc.ssl_server_certificate_configuration([(key, crt)], root_certificates=ca)
+
+    initial = load()
+
+    def fetch():
+        # gRPC lo llama en cada handshake; None conserva la configuración actual
+        now = time.monotonic()
+        if now - state["checked"] < cfg.tls_reload_interval:
+            return None
+        state["checked"] = now
+        try:
+            if max(os.path.getmtime(p) for p in paths) <= state["mtime"]:
+                return None
+            conf = load()
+            logging.info("certificado recargado")
+            return conf
+        except (OSError, ValueError) as e:
+            logging.warning(f"no se pudo recargar el certificado: {e}")
+            return None
+
+    logging.info(f"mTLS con {paths[0]} (CA {paths[2]})")
+    return grpc.dynamic_ssl_server_credentials(initial, fetch, require_client_authentication=True)
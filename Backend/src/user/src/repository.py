@@ -1,11 +1,12 @@
 import logging
 import os
-from datetime import datetime
-from typing import Optional
+import secrets
+from datetime import datetime, timedelta
+from typing import List, Optional
 from sqlalchemy import create_engine
 from sqlalchemy.orm import sessionmaker, Session
 from sqlalchemy.exc import IntegrityError, NoResultFound
-from .models import Base, User
+from .models import Base, User, Address, WebSession
 
 
 class UserRepository:
@@ -27,9 +28,9 @@ class UserRepository:
     def _migrate(self):
         try:
             Base.metadata.create_all(bind=self.engine)
-            logging.info("[user] Database migration completed")
+            logging.info("Database migration completed")
         except Exception as e:
-            logging.error(f"[user] Database migration failed: {e}")
+            logging.error(f"Database migration failed: {e}")
             raise
     
     def _get_db(self) -> Session:
@@ -47,12 +48,12 @@ class UserRepository:
             db.commit()
             db.refresh(user)
             
-            logging.info(f"[user] Created user with ID: {user.id}")
+            logging.info(f"Created user with ID: {user.id}")
             return user.id
             
         except IntegrityError as e:
             db.rollback()
-            logging.error(f"[user] Failed to create user: {e}")
+            logging.error(f"Failed to create user: {e}")
             raise
         finally:
             db.close()
@@ -62,9 +63,9 @@ class UserRepository:
         try:
             user = db.query(User).filter(User.id == user_id).first()
             if user:
-                logging.debug(f"[user] Found user by ID: {user_id}")
+                logging.debug(f"Found user by ID: {user_id}")
             else:
-                logging.debug(f"[user] User not found by ID: {user_id}")
+                logging.debug(f"User not found by ID: {user_id}")
             return user
         finally:
             db.close()
@@ -74,9 +75,9 @@ class UserRepository:
         try:
             user = db.query(User).filter(User.email == email).first()
             if user:
-                logging.debug(f"[user] Found user by email: {email}")
+                logging.debug(f"Found user by email: {email}")
             else:
-                logging.debug(f"[user] User not found by email: {email}")
+                logging.debug(f"User not found by email: {email}")
             return user
         finally:
             db.close()
@@ -86,23 +87,197 @@ class UserRepository:
         try:
             user = db.query(User).filter(User.id == user_id).first()
             if not user:
-                logging.warning(f"[user] User not found for update: {user_id}")
+                logging.warning(f"User not found for update: {user_id}")
                 return False
             
             user.name = name
             user.updated_at = datetime.utcnow()
             
             db.commit()
-            logging.info(f"[user] Updated name for user ID: {user_id}")
+            logging.info(f"Updated name for user ID: {user_id}")
             return True
             
         except Exception as e:
             db.rollback()
-            logging.error(f"[user] Failed to update user name: {e}")
+            logging.error(f"Failed to update user name: {e}")
             return False
         finally:
             db.close()
 
+    
+    # --- Libreta de direcciones ---
+    
+    def add_address(self, addr: Address, make_default: bool) -> Address:
+        db = self._get_db()
+        try:
+            has_any = db.query(Address).filter(Address.user_id == addr.user_id).count() > 0
+            if make_default or not has_any:
+                db.query(Address).filter(Address.user_id == addr.user_id).update({Address.is_default: False})
+                addr.is_default = True
+            addr.created_at = datetime.utcnow()
+            
+            db.add(addr)
+            db.commit()
+            db.refresh(addr)
+            
+            logging.info(f"Added address {addr.id} for user ID: {addr.user_id}")
+            return addr
+            
+        except Exception as e:
+            db.rollback()
+            logging.error(f"Failed to add address: {e}")
+            raise
+        finally:
+            db.close()
+    
+    def list_addresses(self, user_id: int) -> List[Address]:
+        db = self._get_db()
+        try:
+            return (db.query(Address)
+                    .filter(Address.user_id == user_id)
+                    .order_by(Address.is_default.desc(), Address.id)
+                    .all())
+        finally:
+            db.close()
+    
+    def get_address(self, user_id: int, address_id: int) -> Optional[Address]:
+        db = self._get_db()
+        try:
+            return (db.query(Address)
+                    .filter(Address.id == address_id, Address.user_id == user_id)
+                    .first())
+        finally:
+            db.close()
+    
+    def delete_address(self, user_id: int, address_id: int) -> bool:
+        db = self._get_db()
+        try:
+            addr = (db.query(Address)
+                    .filter(Address.id == address_id, Address.user_id == user_id)
+                    .first())
+            if not addr:
+                return False
+            was_default = addr.is_default
+            db.delete(addr)
+            db.flush()
+            
+            # Si se borró la dirección por defecto, la más antigua pasa a serlo
+            if was_default:
+                nxt = (db.query(Address)
+                       .filter(Address.user_id == user_id)
+                       .order_by(Address.id)
+                       .first())
+                if nxt:
+                    nxt.is_default = True
+            
+            db.commit()
+            logging.info(f"Deleted address {address_id} for user ID: {user_id}")
+            return True
+            
+        except Exception as e:
+            db.rollback()
+            logging.error(f"Failed to delete address: {e}")
+            raise
+        finally:
+            db.close()
+
+    
+    # --- Sesiones web ---
+    
+    def create_session(self, user_id: int, ttl: timedelta) -> WebSession:
+        db = self._get_db()
+        try:
+            now = datetime.utcnow()
+            s = WebSession(id=secrets.token_urlsafe(32), user_id=user_id,
+                           created_at=now, expires_at=now + ttl, replaced_by="")
+            db.add(s)
+            db.commit()
+            db.refresh(s)
+            return s
+        except Exception as e:
+            db.rollback()
+            logging.error(f"Failed to create session: {e}")
+            raise
+        finally:
+            db.close()
+    
+    def get_active_session(self, session_id: str, grace: timedelta = timedelta(0)) -> Optional[WebSession]:
+        """Devuelve la sesión si sigue activa. Si otra petición la rotó hace menos
+        de grace devuelve el reemplazo, para que la cookie anterior que todavía
+        viaja en peticiones paralelas no cierre la sesión."""
+        db = self._get_db()
+        try:
+            now = datetime.utcnow()
+            s = db.query(WebSession).filter(WebSession.id == session_id).first()
+            if s and s.replaced_by and s.revoked_at and now - s.revoked_at <= grace:
+                s = db.query(WebSession).filter(WebSession.id == s.replaced_by).first()
+            if s and s.is_active(now):
+                return s
+            return None
+        finally:
+            db.close()
+    
+    def rotate_session(self, session_id: str, ttl: timedelta, grace: timedelta) -> Optional[WebSession]:
+        """Revoca la sesión y crea su reemplazo en la misma transacción. Si ya se
+        rotó hace menos de grace (otra petición en paralelo con la misma cookie)
+        devuelve el reemplazo existente."""
+        db = self._get_db()
+        try:
+            now = datetime.utcnow()
+            old = db.query(WebSession).filter(WebSession.id == session_id).first()
+            if not old:
+                return None
+            if old.replaced_by and old.revoked_at and now - old.revoked_at <= grace:
+                new = db.query(WebSession).filter(WebSession.id == old.replaced_by).first()
+                return new if new and new.is_active(now) else None
+            if not old.is_active(now):
+                return None
+            new = WebSession(id=secrets.token_urlsafe(32), user_id=old.user_id,
+                             created_at=now, expires_at=now + ttl, replaced_by="")
+            old.revoked_at = now
+            old.replaced_by = new.id
+            db.add(new)
+            db.commit()
+            db.refresh(new)
+            return new
+        except Exception as e:
+            db.rollback()
+            logging.error(f"Failed to rotate session: {e}")
+            raise
+        finally:
+            db.close()
+    
+    def revoke_session(self, session_id: str) -> bool:
+        db = self._get_db()
+        try:
+            n = (db.query(WebSession)
+                 .filter(WebSession.id == session_id, WebSession.revoked_at.is_(None))
+                 .update({WebSession.revoked_at: datetime.utcnow()}))
+            db.commit()
+            return n > 0
+        except Exception as e:
+            db.rollback()
+            logging.error(f"Failed to revoke session: {e}")
+            raise
+        finally:
+            db.close()
+    
+    def revoke_user_sessions(self, user_id: int) -> int:
+        db = self._get_db()
+        try:
+            n = (db.query(WebSession)
+                 .filter(WebSession.user_id == user_id, WebSession.revoked_at.is_(None))
+                 .update({WebSession.revoked_at: datetime.utcnow()}))
+            db.commit()
+            logging.info(f"Revoked {n} sessions for user ID: {user_id}")
+            return n
+        except Exception as e:
+            db.rollback()
+            logging.error(f"Failed to revoke sessions: {e}")
+            raise
+        finally:
+            db.close()
+
 
 def new_user_repository(dsn: str) -> UserRepository:
     return UserRepository(dsn)
\ No newline at end of file
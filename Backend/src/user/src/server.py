@@ -1,6 +1,7 @@
 import logging
 import bcrypt
 from concurrent import futures
+from datetime import timedelta, timezone
 import grpc
 from grpc_reflection.v1alpha import reflection
 
@@ -20,7 +21,13 @@ import user_pb2, user_pb2_grpc
 import common_pb2
 from .repository import UserRepository
 from .events import EventPublisher, UserCreated, UserUpdated
-from .models import User
+from .models import User, Address, WebSession
+
+
+# Sesiones web: vigencia máxima que puede pedir un frontend y ventana en la que
+# rotar dos veces la misma sesión devuelve el mismo reemplazo.
+MAX_SESSION_TTL = timedelta(days=30)
+ROTATE_GRACE = timedelta(seconds=30)
 
 
 class UserService(user_pb2_grpc.UserServicer):
@@ -47,7 +54,7 @@ class UserService(user_pb2_grpc.UserServicer):
                 bcrypt.gensalt()
             ).decode('utf-8')
         except Exception as e:
-            logging.error(f"[user] Password hashing failed: {e}")
+            logging.error(f"Password hashing failed: {e}")
             context.set_code(grpc.StatusCode.INTERNAL)
             context.set_details("password processing failed")
             return user_pb2.RegisterResponse()
@@ -64,11 +71,11 @@ class UserService(user_pb2_grpc.UserServicer):
             
             self.pub.publish("user.created", UserCreated(user_id, user.name, user.email))
             
-            logging.info(f"[user] User registered successfully: {user_id}")
+            logging.info(f"User registered successfully: {user_id}")
             return user_pb2.RegisterResponse(user_id=user_id)
             
         except Exception as e:
-            logging.error(f"[user] User registration failed: {e}")
+            logging.error(f"User registration failed: {e}")
             context.set_code(grpc.StatusCode.INTERNAL)
             context.set_details("user creation failed")
             return user_pb2.RegisterResponse()
@@ -91,11 +98,11 @@ class UserService(user_pb2_grpc.UserServicer):
                 context.set_details("invalid credentials")
                 return user_pb2.AuthenticateResponse()
             
-            logging.info(f"[user] User authenticated successfully: {user.id}")
+            logging.info(f"User authenticated successfully: {user.id}")
             return user_pb2.AuthenticateResponse(ok=True, user_id=user.id)
             
         except Exception as e:
-            logging.error(f"[user] Authentication error: {e}")
+            logging.error(f"Authentication error: {e}")
             context.set_code(grpc.StatusCode.INTERNAL)
             context.set_details("authentication failed")
             return user_pb2.AuthenticateResponse()
@@ -107,7 +114,7 @@ class UserService(user_pb2_grpc.UserServicer):
             context.set_details("user not found")
             return user_pb2.UserProfile()
         
-        logging.debug(f"[user] Profile retrieved for user: {user.id}")
+        logging.debug(f"Profile retrieved for user: {user.id}")
         return user_pb2.UserProfile(
             user_id=user.id,
             name=user.name,
@@ -134,13 +141,172 @@ class UserService(user_pb2_grpc.UserServicer):
             context.set_details("failed to retrieve updated user")
             return user_pb2.UserProfile()
         
-        logging.info(f"[user] Name updated for user: {user.id}")
+        logging.info(f"Name updated for user: {user.id}")
         return user_pb2.UserProfile(
             user_id=user.id,
             name=user.name,
             email=user.email
         )
 
+    
+    # --- Libreta de direcciones ---
+    
+    def ListAddresses(self, request, context):
+        addrs = self.repo.list_addresses(request.user_id)
+        return user_pb2.ListAddressesResponse(addresses=[_address_to_pb(a) for a in addrs])
+    
+    def GetAddress(self, request, context):
+        addr = self.repo.get_address(request.user_id, request.address_id)
+        if not addr:
+            context.set_code(grpc.StatusCode.NOT_FOUND)
+            context.set_details("address not found")
+            return user_pb2.SavedAddress()
+        return _address_to_pb(addr)
+    
+    def AddAddress(self, request, context):
+        a = request.address
+        if not request.user_id or not a.recipient or not a.line1 or not a.city or not a.country:
+            context.set_code(grpc.StatusCode.INVALID_ARGUMENT)
+            context.set_details("user_id, recipient, line1, city and country are required")
+            return user_pb2.SavedAddress()
+        
+        if not self.repo.get_by_id(request.user_id):
+            context.set_code(grpc.StatusCode.NOT_FOUND)
+            context.set_details("user not found")
+            return user_pb2.SavedAddress()
+        
+        addr = Address(
+            user_id=request.user_id,
+            label=request.label,
+            recipient=a.recipient,
+            line1=a.line1,
+            line2=a.line2,
+            city=a.city,
+            region=a.region,
+            postal_code=a.postal_code,
+            country=a.country.upper(),
+            phone=a.phone,
+        )
+        try:
+            addr = self.repo.add_address(addr, request.make_default)
+            return _address_to_pb(addr)
+        except Exception as e:
+            logging.error(f"Add address failed: {e}")
+            context.set_code(grpc.StatusCode.INTERNAL)
+            context.set_details("address creation failed")
+            return user_pb2.SavedAddress()
+    
+    def DeleteAddress(self, request, context):
+        try:
+            ok = self.repo.delete_address(request.user_id, request.address_id)
+        except Exception:
+            context.set_code(grpc.StatusCode.INTERNAL)
+            context.set_details("address deletion failed")
+            return common_pb2.Ack()
+        if not ok:
+            return common_pb2.Ack(ok=False, message="address not found")
+        return common_pb2.Ack(ok=True)
+
+    
+    # --- Sesiones web ---
+    
+    def CreateSession(self, request, context):
+        if not request.user_id or request.ttl_seconds <= 0:
+            context.set_code(grpc.StatusCode.INVALID_ARGUMENT)
+            context.set_details("user_id and ttl_seconds are required")
+            return user_pb2.Session()
+        user = self.repo.get_by_id(request.user_id)
+        if not user:
+            context.set_code(grpc.StatusCode.NOT_FOUND)
+            context.set_details("user not found")
+            return user_pb2.Session()
+        try:
+            s = self.repo.create_session(user.id, _session_ttl(request.ttl_seconds))
+        except Exception:
+            context.set_code(grpc.StatusCode.INTERNAL)
+            context.set_details("session creation failed")
+            return user_pb2.Session()
+        logging.info(f"Session opened for user: {user.id}")
+        return _session_to_pb(s, user.name)
+    
+    def CheckSession(self, request, context):
+        s = self.repo.get_active_session(request.session_id, ROTATE_GRACE) if request.session_id else None
+        user = self.repo.get_by_id(s.user_id) if s else None
+        if not user:
+            context.set_code(grpc.StatusCode.NOT_FOUND)
+            context.set_details("session not found")
+            return user_pb2.Session()
+        return _session_to_pb(s, user.name)
+    
+    def RotateSession(self, request, context):
+        if not request.session_id or request.ttl_seconds <= 0:
+            context.set_code(grpc.StatusCode.INVALID_ARGUMENT)
+            context.set_details("session_id and ttl_seconds are required")
+            return user_pb2.Session()
+        try:
+            s = self.repo.rotate_session(request.session_id, _session_ttl(request.ttl_seconds), ROTATE_GRACE)
+        except Exception:
+            context.set_code(grpc.StatusCode.INTERNAL)
+            context.set_details("session rotation failed")
+            return user_pb2.Session()
+        user = self.repo.get_by_id(s.user_id) if s else None
+        if not user:
+            context.set_code(grpc.StatusCode.NOT_FOUND)
+            context.set_details("session not found")
+            return user_pb2.Session()
+        return _session_to_pb(s, user.name)
+    
+    def RevokeSession(self, request, context):
+        try:
+            ok = self.repo.revoke_session(request.session_id)
+        except Exception:
+            context.set_code(grpc.StatusCode.INTERNAL)
+            context.set_details("session revocation failed")
+            return common_pb2.Ack()
+        return common_pb2.Ack(ok=True, message="" if ok else "session already closed")
+    
+    def RevokeUserSessions(self, request, context):
+        try:
+            n = self.repo.revoke_user_sessions(request.user_id)
+        except Exception:
+            context.set_code(grpc.StatusCode.INTERNAL)
+            context.set_details("session revocation failed")
+            return common_pb2.Ack()
+        return common_pb2.Ack(ok=True, message=f"{n} sessions revoked")
+
+
+def _session_ttl(seconds: int) -> timedelta:
+    return min(timedelta(seconds=seconds), MAX_SESSION_TTL)
+
+
+def _session_to_pb(s: WebSession, name: str):
+    return user_pb2.Session(
+        session_id=s.id,
+        user_id=s.user_id,
+        name=name,
+        created_unix=int(s.created_at.replace(tzinfo=timezone.utc).timestamp()),
+        expires_unix=int(s.expires_at.replace(tzinfo=timezone.utc).timestamp()),
+    )
+
+
+def _address_to_pb(a: Address):
+    return user_pb2.SavedAddress(
+        address_id=a.id,
+        user_id=a.user_id,
+        label=a.label,
+        is_default=a.is_default,
+        address=common_pb2.Address(
+            recipient=a.recipient,
+            line1=a.line1,
+            line2=a.line2,
+            city=a.city,
+            region=a.region,
+            postal_code=a.postal_code,
+            country=a.country,
+            phone=a.phone,
+        ),
+    )
+
 
 def new_user_service(repo: UserRepository, pub: EventPublisher) -> UserService:
     return UserService(repo, pub)
\ No newline at end of file
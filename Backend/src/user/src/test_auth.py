@@ -0,0 +1,135 @@
+"""Pruebas de auth.py contra los tokens que emite pkg/auth en Go.
+
+Los vectores están en pkg/auth/testdata/tokens.json y los genera Authority.Issue
+(TestTokenVectors); si el formato cambia en Go, esta prueba falla también.
+
+    cd Backend/src/user && python -m unittest src.test_auth
+"""
+import base64
+import json
+import os
+import unittest
+from types import SimpleNamespace
+from unittest import mock
+
+import grpc
+
+from . import auth
+
+VECTORS = os.path.join(os.path.dirname(__file__), "..", "..", "..", "..", "pkg", "auth", "testdata", "tokens.json")
+
+
+def _load_vectors():
+    with open(VECTORS, encoding="utf-8") as f:
+        return json.load(f)
+
+
+def _b64(data: bytes) -> str:
+    return base64.urlsafe_b64encode(data).rstrip(b"=").decode()
+
+
+class _Aborted(Exception):
+    def __init__(self, code, details):
+        super().__init__(details)
+        self.code = code
+
+
+class _Context:
+    """Lo mínimo de grpc.ServicerContext: abort corta el handler con una excepción."""
+
+    def abort(self, code, details):
+        raise _Aborted(code, details)
+
+
+def _request(user_id: int):
+    """Un mensaje con campo user_id, como los de user_pb2."""
+    return SimpleNamespace(user_id=user_id, DESCRIPTOR=SimpleNamespace(fields_by_name={"user_id": None}))
+
+
+class GoVectorsTest(unittest.TestCase):
+    def setUp(self):
+        self.v = _load_vectors()
+        self.now = self.v["now"]
+        self.tokens = {t["name"]: t for t in self.v["tokens"]}
+        self.authority = auth.Authority(self.v["secret"], [self.v["previous_secret"]])
+
+    def test_verifies_go_tokens(self):
+        for t in self.v["tokens"]:
+            with self.subTest(t["name"]):
+                p = self.authority.verify(t["token"], now=self.now)
+                self.assertEqual((p.user_id, p.service, p.roles), (t["user_id"], t["service"], t["roles"]))
+        self.assertTrue(self.authority.verify(self.tokens["admin"]["token"], now=self.now).is_admin())
+
+    def test_previous_key_only_while_rotating(self):
+        only_current = auth.Authority(self.v["secret"])
+        with self.assertRaises(auth.TokenError):
+            only_current.verify(self.tokens["clave anterior"]["token"], now=self.now)
+
+    def test_rejects(self):
+        token = self.tokens["usuario"]["token"]
+        header, claims, sig = token.split(".")
+        forged = json.loads(base64.urlsafe_b64decode(claims + "=="))
+        forged["sub"] = "43"
+        unsigned = _b64(json.dumps({"alg": "none", "typ": "JWT"}).encode())
+        cases = {
+            "vencido": (token, self.now + self.v["ttl_seconds"]),
+            "emitido en el futuro": (token, self.now - auth.MAX_SKEW - 1),
+            "claims alterados": (f"{header}.{_b64(json.dumps(forged).encode())}.{sig}", self.now),
+            "firma alterada": (f"{header}.{claims}.{_b64(b'x' * 32)}", self.now),
+            "sin firma": (f"{unsigned}.{claims}.", self.now),
+            "mal formado": ("abc", self.now),
+        }
+        for name, (tok, now) in cases.items():
+            with self.subTest(name), self.assertRaises(auth.TokenError):
+                self.authority.verify(tok, now=now)
+        with self.assertRaises(auth.TokenError):
+            auth.Authority("z" * 32).verify(token, now=self.now)
+
+
+class InterceptorTest(unittest.TestCase):
+    def setUp(self):
+        self.v = _load_vectors()
+        self.tokens = {t["name"]: t["token"] for t in self.v["tokens"]}
+        self.interceptor = auth.AuthInterceptor(auth.Authority(self.v["secret"]))
+        self.seen = []
+
+    def call(self, method: str, token, request):
+        def behavior(req, context):
+            self.seen.append(auth.principal())
+            return "ok"
+
+        metadata = (("authorization", f"Bearer {token}"),) if token else ()
+        details = SimpleNamespace(method=method, invocation_metadata=metadata)
+        # El interceptor verifica con el reloj real: se fija en el de los vectores
+        with mock.patch.object(auth.time, "time", return_value=self.v["now"]):
+            handler = self.interceptor.intercept_service(
+                lambda _: grpc.unary_unary_rpc_method_handler(behavior), details)
+            return handler.unary_unary(request, _Context())
+
+    def test_foreign_user_id(self):
+        # Un usuario solo puede pedir por su propio user_id
+        with self.assertRaises(_Aborted) as cm:
+            self.call("/user.User/GetProfile", self.tokens["usuario"], _request(43))
+        self.assertEqual(cm.exception.code, grpc.StatusCode.PERMISSION_DENIED)
+        self.assertEqual(self.seen, [])
+
+        self.assertEqual(self.call("/user.User/GetProfile", self.tokens["usuario"], _request(42)), "ok")
+        self.assertEqual(self.seen[-1].user_id, 42)
+
+        # Servicios internos y admins piden por cualquiera
+        for name in ("servicio", "admin"):
+            self.assertEqual(self.call("/user.User/GetProfile", self.tokens[name], _request(43)), "ok")
+        self.assertIsNone(auth.principal())
+
+    def test_missing_or_invalid_token(self):
+        for token in (None, self.tokens["clave anterior"]):
+            with self.assertRaises(_Aborted) as cm:
+                self.call("/user.User/GetProfile", token, _request(42))
+            self.assertEqual(cm.exception.code, grpc.StatusCode.UNAUTHENTICATED)
+        # Los métodos públicos pasan sin token
+        self.assertEqual(self.call("/user.User/Authenticate", None, _request(0)), "ok")
+        self.assertIsNone(self.seen[-1])
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ahinestrog/mybookstore/pkg/session"
+)
+
+// errorBody es el cuerpo de todos los errores de /api/v1, p. ej.
+// {"error": {"code": 404, "status": "NOT_FOUND", "message": "libro no encontrado"}}.
+// status es el código de gRPC y code su equivalente HTTP (httpStatus).
+type errorBody struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// httpStatus traduce el código de gRPC al HTTP que recibe el cliente. Es la
+// tabla de grpc-gateway, salvo FAILED_PRECONDITION: los servicios la usan para
+// reglas de negocio sobre el estado del recurso (una orden que ya no admite
+// devolución, una tarjeta de regalo ya redimida), que en HTTP son un conflicto.
+func httpStatus(c codes.Code) int {
+	if c == codes.FailedPrecondition {
+		return http.StatusConflict
+	}
+	return runtime.HTTPStatusFromCode(c)
+}
+
+// writeError responde err como errorBody. Los errores internos se registran y
+// al cliente solo le llega un mensaje genérico.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(err)
+	msg := st.Message()
+	switch st.Code() {
+	case codes.Unknown, codes.Internal, codes.DataLoss:
+		slog.ErrorContext(r.Context(), "api", "method", r.Method, "path", r.URL.Path, "err", err)
+		msg = "error interno"
+	case codes.Unavailable, codes.DeadlineExceeded:
+		slog.WarnContext(r.Context(), "api", "method", r.Method, "path", r.URL.Path, "err", err)
+		msg = "servicio no disponible, intenta de nuevo"
+	case codes.Unauthenticated:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mybookstore"`)
+	}
+	writeStatus(w, httpStatus(st.Code()), st.Code(), msg)
+}
+
+func writeStatus(w http.ResponseWriter, httpCode int, c codes.Code, msg string) {
+	var body errorBody
+	body.Error.Code = httpCode
+	body.Error.Status = code.Code(c).String()
+	body.Error.Message = msg
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// grpcError es el runtime.ErrorHandlerFunc del gateway: errores de los
+// servicios y de la traducción de la petición (JSON o parámetros inválidos).
+func grpcError(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, r, err)
+}
+
+// routingError responde las rutas que no existen y los métodos no soportados.
+func routingError(ctx context.Context, _ *runtime.ServeMux, _ runtime.Marshaler, w http.ResponseWriter, r *http.Request, httpCode int) {
+	switch httpCode {
+	case http.StatusNotFound:
+		writeStatus(w, httpCode, codes.NotFound, "ruta no encontrada: "+r.URL.Path)
+	case http.StatusMethodNotAllowed:
+		writeStatus(w, httpCode, codes.Unimplemented, "método "+r.Method+" no permitido en "+r.URL.Path)
+	default:
+		writeStatus(w, httpCode, codes.InvalidArgument, http.StatusText(httpCode))
+	}
+}
+
+// ownUser rechaza antes de llamar al servicio las rutas /users/{user_id}/...
+// de otro usuario. Los servicios lo validan además: los Go con auth.CheckUser y
+// User (Python) en su interceptor de auth.
+func ownUser(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		if id, ok := params["user_id"]; ok {
+			uid := session.UserID(r)
+			switch {
+			case uid == 0:
+				writeError(w, r, status.Error(codes.Unauthenticated, "inicia sesión para usar esta ruta"))
+				return
+			case id != strconv.FormatInt(uid, 10):
+				writeError(w, r, status.Error(codes.PermissionDenied, "el recurso pertenece a otro usuario"))
+				return
+			}
+		}
+		next(w, r, params)
+	}
+}
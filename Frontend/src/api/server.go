@@ -0,0 +1,115 @@
+// Package api es la API REST/JSON pública del storefront (/api/v1/...). Las
+// rutas salen de las anotaciones google.api.http de los .proto: el gateway que
+// genera protoc-gen-grpc-gateway (proto/gen/*/*.pb.gw.go) traduce cada una al
+// RPC del servicio, y /api/v1/openapi.json sirve el documento que
+// protoc-gen-openapiv2 genera de los mismos .proto.
+//
+// La sesión es la del storefront: la cookie desde el navegador o el token de
+// POST /api/v1/sessions en "Authorization: Bearer" (ver pkg/session). Las
+// llamadas gRPC llevan al usuario de la sesión igual que las de las páginas.
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/ahinestrog/mybookstore/Frontend/src/web"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/session"
+	cartpb "github.com/ahinestrog/mybookstore/proto/gen/cart"
+	catalogpb "github.com/ahinestrog/mybookstore/proto/gen/catalog"
+	inventorypb "github.com/ahinestrog/mybookstore/proto/gen/inventory"
+	"github.com/ahinestrog/mybookstore/proto/gen/openapi"
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	userpb "github.com/ahinestrog/mybookstore/proto/gen/user"
+)
+
+// callTimeout acota cada llamada al servicio detrás de una ruta.
+const callTimeout = 10 * time.Second
+
+type Server struct {
+	gw       *runtime.ServeMux
+	users    userpb.UserClient
+	sessions *session.Manager
+}
+
+// New conecta con los seis servicios, con las mismas variables que los demás
+// módulos, y registra las rutas generadas.
+func New(deps web.Deps) (*Server, error) {
+	userAddr := web.Getenv("USER_GRPC_ADDR", "localhost:50055")
+	s := &Server{sessions: deps.Sessions, gw: newGatewayMux()}
+	for _, svc := range []struct {
+		addr     string
+		register func(context.Context, *runtime.ServeMux, *grpc.ClientConn) error
+	}{
+		{web.Getenv("CATALOG_GRPC_ADDR", "localhost:50051"), catalogpb.RegisterCatalogHandler},
+		{web.Getenv("CART_GRPC_TARGET", "localhost:50050"), cartpb.RegisterCartHandler},
+		{web.Getenv("ORDER_SVC_ADDR", "localhost:50054"), orderpb.RegisterOrderHandler},
+		{web.Getenv("PAYMENT_GRPC_ADDR", "localhost:50053"), paymentpb.RegisterPaymentHandler},
+		{web.Getenv("INVENTORY_SERVICE_ADDR", web.Getenv("INVENTORY_GRPC_ADDR", "localhost:50052")), inventorypb.RegisterInventoryHandler},
+		{userAddr, userpb.RegisterUserHandler},
+	} {
+		cc, err := grpcclient.Conn(svc.addr)
+		if err != nil {
+			return nil, err
+		}
+		if err := svc.register(context.Background(), s.gw, cc); err != nil {
+			return nil, err
+		}
+	}
+	cc, err := grpcclient.Conn(userAddr)
+	if err != nil {
+		return nil, err
+	}
+	s.users = userpb.NewUserClient(cc)
+	return s, nil
+}
+
+func newGatewayMux() *runtime.ServeMux {
+	return runtime.NewServeMux(
+		// snake_case como en los .proto; los campos en cero también se envían
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		// Ningún header HTTP pasa al metadata: la cookie y el token de sesión se
+		// quedan aquí y pkg/auth firma el token de gRPC del usuario
+		runtime.WithIncomingHeaderMatcher(func(string) (string, bool) { return "", false }),
+		runtime.WithErrorHandler(grpcError),
+		runtime.WithRoutingErrorHandler(routingError),
+		runtime.WithMiddlewares(ownUser),
+	)
+}
+
+func (s *Server) Name() string { return "api" }
+
+func (s *Server) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v1/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openapi.JSON)
+	})
+	mux.HandleFunc("POST /v1/sessions", s.handleLogin)
+	mux.HandleFunc("DELETE /v1/sessions/current", s.handleLogout)
+	mux.HandleFunc("/v1/", s.serveGateway)
+}
+
+// serveGateway pasa la petición al gateway generado. El storefront quita el
+// prefijo /api y las rutas de los .proto lo llevan completo: se restituye.
+func (s *Server) serveGateway(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), callTimeout)
+	defer cancel()
+	u := *r.URL
+	u.Path = "/api" + u.Path
+	if u.RawPath != "" {
+		u.RawPath = "/api" + u.RawPath
+	}
+	r = r.WithContext(ctx)
+	r.URL = &u
+	s.gw.ServeHTTP(w, r)
+}
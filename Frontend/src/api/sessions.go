@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ahinestrog/mybookstore/pkg/session"
+	userpb "github.com/ahinestrog/mybookstore/proto/gen/user"
+)
+
+// maxLoginBody acota el cuerpo de POST /api/v1/sessions.
+const maxLoginBody = 4 << 10
+
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// loginResponse usa los mismos tipos JSON que las rutas generadas (int64 como string).
+type loginResponse struct {
+	Token       string `json:"token"`
+	UserID      int64  `json:"user_id,string"`
+	ExpiresUnix int64  `json:"expires_unix,string"`
+}
+
+// handleLogin autentica con email y contraseña y abre una sesión para clientes
+// sin cookies: el token va en "Authorization: Bearer" hasta que venza o se
+// cierre con DELETE /api/v1/sessions/current.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLoginBody)).Decode(&req); err != nil {
+		writeError(w, r, status.Error(codes.InvalidArgument, "se esperaba {\"email\", \"password\"} en JSON"))
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" || req.Password == "" {
+		writeError(w, r, status.Error(codes.InvalidArgument, "email y password son obligatorios"))
+		return
+	}
+	resp, err := s.users.Authenticate(r.Context(), &userpb.AuthenticateRequest{Email: req.Email, Password: req.Password})
+	if err != nil && status.Code(err) != codes.Unauthenticated && status.Code(err) != codes.NotFound {
+		writeError(w, r, err)
+		return
+	}
+	if err != nil || !resp.GetOk() {
+		writeError(w, r, status.Error(codes.Unauthenticated, "credenciales inválidas"))
+		return
+	}
+	ses, token, err := s.sessions.IssueToken(r.Context(), resp.GetUserId())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "api: login", "err", err)
+		writeError(w, r, status.Error(codes.Unavailable, "no se pudo abrir la sesión"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(loginResponse{Token: token, UserID: ses.UserID, ExpiresUnix: ses.ExpiresAt.Unix()})
+}
+
+// handleLogout revoca la sesión con la que llega la petición.
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	ses := session.FromContext(r.Context())
+	if ses == nil {
+		writeError(w, r, status.Error(codes.Unauthenticated, "no hay una sesión abierta"))
+		return
+	}
+	if err := s.sessions.Revoke(r.Context(), ses); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
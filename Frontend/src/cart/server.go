@@ -1,120 +1,110 @@
-package main
+// Package cart es el módulo del carrito y del checkout.
+package cart
 
 import (
 	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
 	"fmt"
-	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
 	neturl "net/url"
-	"os"
 	"strconv"
 	"time"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/ahinestrog/mybookstore/Frontend/src/web"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/session"
+	"github.com/ahinestrog/mybookstore/pkg/webform"
 	cartpb "github.com/ahinestrog/mybookstore/proto/gen/cart"
 	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 	inventorypb "github.com/ahinestrog/mybookstore/proto/gen/inventory"
 	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
+	userpb "github.com/ahinestrog/mybookstore/proto/gen/user"
 )
 
+//go:embed templates/*.html
+var templatesFS embed.FS
+
 type Server struct {
-	tpl         *template.Template
+	pages       *web.Pages
 	client      cartpb.CartClient
 	orderClient orderpb.OrderClient
-	orderAddr   string
 	invClient   inventorypb.InventoryClient
-	invAddr     string
+	userClient  userpb.UserClient
+	payClient   paymentpb.PaymentClient
 }
 
-func mustEnv(key, def string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return def
-}
-
-func main() {
-	httpAddr := mustEnv("GATEWAY_HTTP_ADDR", ":8080")
-	grpcTarget := mustEnv("CART_GRPC_TARGET", "localhost:50051")
-	orderAddr := mustEnv("ORDER_GRPC_TARGET", "localhost:50054")
-
+// New conecta con Cart, Order, Inventory, User (libreta de direcciones) y
+// Payment (tarjetas guardadas y saldo a favor).
+func New(web.Deps) (*Server, error) {
 	// Prefer INVENTORY_SERVICE_ADDR, fallback to INVENTORY_GRPC_TARGET
-	invAddr := os.Getenv("INVENTORY_SERVICE_ADDR")
-	if invAddr == "" {
-		invAddr = mustEnv("INVENTORY_GRPC_TARGET", "inventory:50052")
-	}
-
-	cc, err := grpc.Dial(grpcTarget, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("dial grpc %s: %v", grpcTarget, err)
-	}
-	defer cc.Close()
-
-	orderCC, err := grpc.Dial(orderAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("dial order grpc %s: %v", orderAddr, err)
-	}
-	defer orderCC.Close()
-
-	invCC, err := grpc.Dial(invAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("dial inventory grpc %s: %v", invAddr, err)
-	}
-	defer invCC.Close()
-
-	tpl := template.Must(template.ParseGlob("./templates/*.html"))
-	s := &Server{
-		tpl:         tpl,
-		client:      cartpb.NewCartClient(cc),
-		orderClient: orderpb.NewOrderClient(orderCC),
-		orderAddr:   orderAddr,
-		invClient:   inventorypb.NewInventoryClient(invCC),
-		invAddr:     invAddr,
-	}
+	targets := []string{
+		web.Getenv("CART_GRPC_TARGET", "localhost:50051"),
+		web.Getenv("ORDER_GRPC_TARGET", web.Getenv("ORDER_SVC_ADDR", "localhost:50054")),
+		web.Getenv("INVENTORY_SERVICE_ADDR", web.Getenv("INVENTORY_GRPC_TARGET", "inventory:50052")),
+		web.Getenv("USER_GRPC_ADDR", "user:50055"),
+		web.Getenv("PAYMENT_GRPC_ADDR", "payment:50053"),
+	}
+	conns := make([]*grpc.ClientConn, len(targets))
+	for i, t := range targets {
+		cc, err := grpcclient.Conn(t)
+		if err != nil {
+			return nil, fmt.Errorf("dial grpc %s: %w", t, err)
+		}
+		conns[i] = cc
+	}
+	return &Server{
+		pages:       web.MustParsePages("cart", templatesFS, "templates/*.html", nil),
+		client:      cartpb.NewCartClient(conns[0]),
+		orderClient: orderpb.NewOrderClient(conns[1]),
+		invClient:   inventorypb.NewInventoryClient(conns[2]),
+		userClient:  userpb.NewUserClient(conns[3]),
+		payClient:   paymentpb.NewPaymentClient(conns[4]),
+	}, nil
+}
 
-	mux := http.NewServeMux()
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static"))))
-	mux.HandleFunc("/", s.handleIndex)
-	mux.HandleFunc("/cart", s.handleCart)
-	mux.HandleFunc("/add", s.handleAdd)
-	mux.HandleFunc("/remove", s.handleRemove)
-	mux.HandleFunc("/remove_line", s.handleRemoveLine)
-	mux.HandleFunc("/clear", s.handleClear)
-	mux.HandleFunc("/checkout", s.handleCheckout)
-
-	log.Printf("[gateway] HTTP %s -> gRPC %s, Order %s, Inventory %s", httpAddr, grpcTarget, orderAddr, invAddr)
-	if err := http.ListenAndServe(httpAddr, mux); err != nil {
-		log.Fatal(err)
-	}
+func (s *Server) Name() string { return "cart" }
+
+// Las vistas son GET; lo que cambia el carrito solo por POST y con token CSRF
+// (lo exige el middleware de sesiones del storefront)
+func (s *Server) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("/", webform.Methods(s.handleIndex, http.MethodGet))
+	mux.HandleFunc("/cart", webform.Methods(s.handleCart, http.MethodGet))
+	mux.HandleFunc("/add", webform.Methods(s.handleAdd, http.MethodPost))
+	mux.HandleFunc("/remove", webform.Methods(s.handleRemove, http.MethodPost))
+	mux.HandleFunc("/remove_line", webform.Methods(s.handleRemoveLine, http.MethodPost))
+	mux.HandleFunc("/clear", webform.Methods(s.handleClear, http.MethodPost))
+	mux.HandleFunc("/checkout", webform.Methods(s.handleCheckout, http.MethodPost))
 }
 
-func (s *Server) ctx() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), 5*time.Second)
+// ctx deriva de la petición: lleva el Principal de la sesión a las llamadas gRPC.
+func (s *Server) ctx(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), 5*time.Second)
 }
 
-// Para demo, fijamos el usuario. En producción, vendría de sesión/autenticación.
+// El usuario sale de la sesión verificada por el middleware (pkg/session).
 func (s *Server) userID(r *http.Request) int64 {
-	if c, err := r.Cookie("uid"); err == nil {
-		if id, err2 := strconv.ParseInt(c.Value, 10, 64); err2 == nil {
-			return id
-		}
-	}
-	return 0
+	return session.UserID(r)
 }
 
-func (s *Server) userName(r *http.Request) string {
-	if c, err := r.Cookie("uname"); err == nil {
-		return c.Value
+// newIdempotencyKey genera una clave aleatoria para el formulario de checkout.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
 	}
-	return ""
+	return hex.EncodeToString(b)
 }
 
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
-	// Serve the cart view at the root so that accessing /cart/ (rewritten to /)
-	// shows the current cart. This is necessary when the Ingress rewrites
-	// prefixed paths (e.g. /cart/) to / on the backend.
+	// La raíz del módulo (/cart/) muestra el carrito actual
 	s.handleCart(w, r)
 }
 
@@ -133,10 +123,16 @@ type ItemVM struct {
 	Line   MoneyView
 }
 
+// EstimateVM es el desglose estimado (IVA y envío a la región por defecto).
+type EstimateVM struct {
+	Subtotal, Discount, Tax, Shipping, GrandTotal MoneyView
+}
+
 type CartVM struct {
-	Items []ItemVM
-	Total MoneyView
-	Msg   string
+	Items    []ItemVM
+	Total    MoneyView
+	Estimate *EstimateVM
+	Msg      string
 }
 
 func toVM(cv *cartpb.CartView, msg string) CartVM {
@@ -151,38 +147,68 @@ func toVM(cv *cartpb.CartView, msg string) CartVM {
 		})
 	}
 	vm.Total = MoneyView{Cents: cv.GetTotal().GetCents()}
+	if e := cv.GetEstimate(); e != nil {
+		vm.Estimate = &EstimateVM{
+			Subtotal:   MoneyView{Cents: e.GetSubtotal().GetCents()},
+			Discount:   MoneyView{Cents: e.GetDiscount().GetCents()},
+			Tax:        MoneyView{Cents: e.GetTax().GetCents()},
+			Shipping:   MoneyView{Cents: e.GetShipping().GetCents()},
+			GrandTotal: MoneyView{Cents: e.GetGrandTotal().GetCents()},
+		}
+	}
 	return vm
 }
 
 func (s *Server) handleCart(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(r)
 	defer cancel()
-	log.Printf("handleCart: user=%d", s.userID(r))
+	slog.DebugContext(ctx, "GetCart", "user_id", s.userID(r))
 	resp, err := s.client.GetCart(ctx, &commonpb.UserRef{UserId: s.userID(r)})
 	if err != nil {
 		http.Error(w, err.Error(), 500)
 		return
 	}
-	log.Printf("handleCart: got %d items", len(resp.GetItems()))
+	slog.DebugContext(ctx, "GetCart", "items", len(resp.GetItems()))
 	msg := r.URL.Query().Get("msg")
 	logged := s.userID(r) != 0
-	s.renderCart(w, resp, msg, logged, s.userName(r))
+	var addrs []*userpb.SavedAddress
+	var cards []*paymentpb.Instrument
+	var wallet int64
+	if logged {
+		ar, err := s.userClient.ListAddresses(ctx, &commonpb.UserRef{UserId: s.userID(r)})
+		if err != nil {
+			slog.WarnContext(ctx, "ListAddresses falló", "err", err)
+		} else {
+			addrs = ar.GetAddresses()
+		}
+		// Sin Payment se puede pagar igual con PSE o contra entrega
+		ir, err := s.payClient.ListInstruments(ctx, &commonpb.UserRef{UserId: s.userID(r)})
+		if err != nil {
+			slog.WarnContext(ctx, "ListInstruments falló", "err", err)
+		} else {
+			cards = ir.GetInstruments()
+		}
+		if br, err := s.payClient.GetBalance(ctx, &commonpb.UserRef{UserId: s.userID(r)}); err != nil {
+			slog.WarnContext(ctx, "GetBalance falló", "err", err)
+		} else {
+			wallet = br.GetBalance().GetCents()
+		}
+	}
+	s.renderCart(w, r, resp, msg, addrs, cards, wallet)
 }
 
 func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, err.Error(), 400)
+	f := webform.Parse(r)
+	bookID := f.ID("book_id", "Libro")
+	qty64 := f.OptionalInt("qty", "Cantidad", 1, maxQty, 1)
+	if !f.Valid() {
+		s.redirectMsg(w, r, f.Message())
 		return
 	}
-	bookID, _ := strconv.ParseInt(r.Form.Get("book_id"), 10, 64)
-	qty64, _ := strconv.ParseInt(r.Form.Get("qty"), 10, 32)
-	if qty64 <= 0 {
-		qty64 = 1
-	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(r)
 	defer cancel()
-	log.Printf("handleAdd: user=%d book=%d qty=%d", s.userID(r), bookID, qty64)
+	slog.DebugContext(ctx, "AddItem", "user_id", s.userID(r), "book_id", bookID, "qty", qty64)
 	cv, err := s.client.AddItem(ctx, &cartpb.AddItemRequest{
 		UserId: s.userID(r),
 		BookId: bookID,
@@ -192,22 +218,20 @@ func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), 500)
 		return
 	}
-	log.Printf("handleAdd: cart now %d items", len(cv.GetItems()))
+	slog.DebugContext(ctx, "AddItem", "items", len(cv.GetItems()))
 	http.Redirect(w, r, "/cart/?msg=Item%20agregado", http.StatusSeeOther)
 }
 
 func (s *Server) handleRemove(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, err.Error(), 400)
+	f := webform.Parse(r)
+	bookID := f.ID("book_id", "Libro")
+	qty64 := f.OptionalInt("qty", "Cantidad", 1, maxQty, 1) // por defecto, decrementa 1
+	if !f.Valid() {
+		s.redirectMsg(w, r, f.Message())
 		return
 	}
-	bookID, _ := strconv.ParseInt(r.Form.Get("book_id"), 10, 64)
-	qty64, _ := strconv.ParseInt(r.Form.Get("qty"), 10, 32)
-	if qty64 <= 0 {
-		qty64 = 1 // por defecto, decrementa 1
-	}
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(r)
 	defer cancel()
 	cv, err := s.client.RemoveItem(ctx, &cartpb.RemoveItemRequest{
 		UserId: s.userID(r),
@@ -223,13 +247,14 @@ func (s *Server) handleRemove(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleRemoveLine(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, err.Error(), 400)
+	f := webform.Parse(r)
+	bookID := f.ID("book_id", "Libro")
+	if !f.Valid() {
+		s.redirectMsg(w, r, f.Message())
 		return
 	}
-	bookID, _ := strconv.ParseInt(r.Form.Get("book_id"), 10, 64)
 
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(r)
 	defer cancel()
 	cv, err := s.client.RemoveItem(ctx, &cartpb.RemoveItemRequest{
 		UserId: s.userID(r),
@@ -245,7 +270,7 @@ func (s *Server) handleRemoveLine(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleClear(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := s.ctx()
+	ctx, cancel := s.ctx(r)
 	defer cancel()
 	cv, err := s.client.ClearCart(ctx, &commonpb.UserRef{UserId: s.userID(r)})
 	if err != nil {
@@ -257,129 +282,158 @@ func (s *Server) handleClear(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleCheckout(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Redirect(w, r, "/cart/", http.StatusSeeOther)
-		return
-	}
-
 	// Require login
 	uid := s.userID(r)
 	if uid == 0 {
-		http.Redirect(w, r, "/user/login?from=/cart/", http.StatusSeeOther)
+		http.Redirect(w, r, web.LoginURL("/cart/"), http.StatusSeeOther)
 		return
 	}
 
-	ctx, cancel := s.ctx()
+	// La orden se despacha a una dirección de la libreta del usuario; la
+	// tarjeta solo viaja si se eligió pagar con tarjeta
+	f := webform.Parse(r)
+	addressID := f.ID("address_id", "Dirección de envío")
+	method := checkoutMethods[f.OneOf("payment_method", "Medio de pago", "pse", "card", "cod")]
+	var instrumentID int64
+	if method == commonpb.PaymentMethod_PAYMENT_METHOD_CARD {
+		instrumentID = f.ID("instrument_id", "Tarjeta guardada")
+	}
+	useWallet := f.Bool("use_wallet")
+	idemKey := f.Text("idempotency_key", "Clave de idempotencia", 0, 64)
+	if !f.Valid() {
+		s.redirectMsg(w, r, f.Message())
+		return
+	}
+
+	ctx, cancel := s.ctx(r)
 	defer cancel()
 
+	// Saldo a favor: se ofrece todo el saldo y Order lo recorta al total de la orden
+	var wallet *commonpb.Money
+	if useWallet {
+		br, err := s.payClient.GetBalance(ctx, &commonpb.UserRef{UserId: uid})
+		if err != nil {
+			slog.WarnContext(ctx, "checkout: GetBalance falló", "err", err)
+			http.Redirect(w, r, "/cart/?msg="+neturl.QueryEscape("No se pudo consultar tu saldo a favor"), http.StatusSeeOther)
+			return
+		}
+		wallet = br.GetBalance()
+	}
+
 	// Validate inventory before creating order
 	// Fetch current cart to get items and quantities
 	cv, err := s.client.GetCart(ctx, &commonpb.UserRef{UserId: uid})
 	if err != nil {
-		log.Printf("[checkout] GetCart failed: %v", err)
+		slog.WarnContext(ctx, "checkout: GetCart falló", "err", err)
 		http.Redirect(w, r, "/cart/?msg=No%20se%20pudo%20obtener%20carrito", http.StatusSeeOther)
 		return
 	}
-	if len(cv.GetItems()) == 0 {
+	// Un doble clic o reintento llega con el carrito ya congelado por el primer
+	// envío: sin líneas no hay nada que validar y, con la misma clave, Order
+	// devuelve la orden original
+	if len(cv.GetItems()) == 0 && idemKey == "" {
+		http.Redirect(w, r, "/cart/?msg=Carrito%20vac%C3%ADo", http.StatusSeeOther)
+		return
+	}
+	if len(cv.GetItems()) > 0 {
+		if msg := s.checkStock(ctx, cv); msg != "" {
+			http.Redirect(w, r, "/cart/?msg="+neturl.QueryEscape(msg), http.StatusSeeOther)
+			return
+		}
+	}
+
+	// 1. Create order via order service (inventory prevalidated)
+	// La clave viene del formulario renderizado: un doble clic o reintento reusa la misma orden
+	resp, err := s.orderClient.CreateOrder(ctx, &orderpb.CreateOrderRequest{
+		UserId:         uid,
+		IdempotencyKey: idemKey,
+		AddressId:      addressID,
+		PaymentMethod:  method,
+		InstrumentId:   instrumentID,
+		WalletAmount:   wallet,
+	})
+	if len(cv.GetItems()) == 0 && status.Code(err) == codes.FailedPrecondition {
+		// La clave no tenía orden: el carrito de verdad está vacío
 		http.Redirect(w, r, "/cart/?msg=Carrito%20vac%C3%ADo", http.StatusSeeOther)
 		return
 	}
-	// Build list of IDs
+	if err != nil {
+		slog.WarnContext(ctx, "checkout: CreateOrder falló", "err", err)
+		http.Redirect(w, r, "/cart/?msg=Error%20al%20crear%20orden", http.StatusSeeOther)
+		return
+	}
+
+	// 2. El carrito ya quedó congelado por Order (CheckoutSnapshot); se vacía o se
+	// restaura cuando la saga publica su resultado final, no aquí.
+
+	// 3. Redirect to order status page (PRG pattern)
+	orderID := resp.GetOrderId()
+	slog.InfoContext(ctx, "checkout: orden creada", "order_id", orderID, "user_id", uid, "total_cents", resp.GetTotal().GetCents())
+	http.Redirect(w, r, fmt.Sprintf("/order/status?id=%d", orderID), http.StatusSeeOther)
+}
+
+// checkStock compara el carrito con la disponibilidad de Inventory y devuelve el
+// mensaje para el usuario, o "" si alcanza para todo.
+func (s *Server) checkStock(ctx context.Context, cv *cartpb.CartView) string {
 	ids := make([]int64, 0, len(cv.GetItems()))
-	qtyByID := make(map[int64]int32, len(cv.GetItems()))
-	titleByID := make(map[int64]string, len(cv.GetItems()))
 	for _, it := range cv.GetItems() {
 		ids = append(ids, it.GetBookId())
-		qtyByID[it.GetBookId()] = it.GetQty()
-		titleByID[it.GetBookId()] = it.GetTitle()
 	}
-	// Query inventory availability
 	invResp, err := s.invClient.GetAvailability(ctx, &inventorypb.GetAvailabilityRequest{BookIds: ids})
 	if err != nil {
-		log.Printf("[checkout] inventory GetAvailability failed: %v", err)
-		http.Redirect(w, r, "/cart/?msg=No%20se%20pudo%20validar%20inventario", http.StatusSeeOther)
-		return
+		slog.WarnContext(ctx, "checkout: GetAvailability falló", "err", err)
+		return "No se pudo validar inventario"
 	}
 	avail := map[int64]int32{}
 	for _, it := range invResp.GetItems() {
 		avail[it.GetBookId()] = it.GetAvailableQty()
 	}
-	// Check each item
-	for id, need := range qtyByID {
-		have := avail[id]
-		if have < need {
-			title := titleByID[id]
-			msg := fmt.Sprintf("Sin%20stock:%20%s%20(id:%d)%20req:%d%20disp:%d", neturl.QueryEscape(title), id, need, have)
-			http.Redirect(w, r, "/cart/?msg="+msg, http.StatusSeeOther)
-			return
+	for _, it := range cv.GetItems() {
+		if have := avail[it.GetBookId()]; have < it.GetQty() {
+			return fmt.Sprintf("Sin stock: %s (id:%d) req:%d disp:%d", it.GetTitle(), it.GetBookId(), it.GetQty(), have)
 		}
 	}
+	return ""
+}
 
-	// 1. Create order via order service (inventory prevalidated)
-	resp, err := s.orderClient.CreateOrder(ctx, &orderpb.CreateOrderRequest{UserId: uid})
-	if err != nil {
-		log.Printf("[checkout] CreateOrder failed: %v", err)
-		http.Redirect(w, r, "/cart/?msg=Error%20al%20crear%20orden", http.StatusSeeOther)
-		return
-	}
+// maxQty es la cantidad máxima que acepta un formulario del carrito.
+const maxQty = 99
 
-	// 2. Clear cart after successful order creation
-	if _, err := s.client.ClearCart(ctx, &commonpb.UserRef{UserId: uid}); err != nil {
-		log.Printf("[checkout] ClearCart failed (order already created): %v", err)
-		// Don't fail checkout if cart clear fails
-	}
+// redirectMsg vuelve al carrito mostrando msg (p. ej. los errores de validación).
+func (s *Server) redirectMsg(w http.ResponseWriter, r *http.Request, msg string) {
+	http.Redirect(w, r, "/cart/?msg="+neturl.QueryEscape(msg), http.StatusSeeOther)
+}
 
-	// 3. Redirect to order status page (PRG pattern)
-	orderID := resp.GetOrderId()
-	log.Printf("[checkout] Order created successfully: order_id=%d, user_id=%d, total=%d",
-		orderID, uid, resp.GetTotal().GetCents())
-	http.Redirect(w, r, fmt.Sprintf("/order/status?id=%d", orderID), http.StatusSeeOther)
+// checkoutMethods son los valores de payment_method en el formulario de compra.
+var checkoutMethods = map[string]commonpb.PaymentMethod{
+	"pse":  commonpb.PaymentMethod_PAYMENT_METHOD_PSE,
+	"card": commonpb.PaymentMethod_PAYMENT_METHOD_CARD,
+	"cod":  commonpb.PaymentMethod_PAYMENT_METHOD_CASH_ON_DELIVERY,
 }
 
-// renderCart ejecuta el layout principal para que los bloques definidos en cart.html se inserten
-// y adapta los datos al shape que esperan las plantillas (Cart, Msg y helper FormatCOP).
-func (s *Server) renderCart(w http.ResponseWriter, cv *cartpb.CartView, msg string, loggedIn bool, userName string) {
+// renderCart adapta el carrito al shape que espera cart.html.
+func (s *Server) renderCart(w http.ResponseWriter, r *http.Request, cv *cartpb.CartView, msg string, addrs []*userpb.SavedAddress, cards []*paymentpb.Instrument, walletCents int64) {
 	vm := toVM(cv, msg)
 	data := struct {
-		Items     []ItemVM
-		Total     MoneyView
-		Msg       string
-		FormatCOP func(int64) string
-		Query     string
-		Year      int
-		LoggedIn  bool
-		UserName  string
+		Items    []ItemVM
+		Total    MoneyView
+		Estimate *EstimateVM
+		Msg      string
+		// Nueva en cada render del formulario de compra
+		IdempotencyKey string
+		Addresses      []*userpb.SavedAddress
+		Cards          []*paymentpb.Instrument
+		WalletCents    int64 // saldo a favor disponible
 	}{
-		Items: vm.Items,
-		Total: vm.Total,
-		Msg:   vm.Msg,
-		FormatCOP: func(cents int64) string {
-			pesos := cents / 100
-			// formato sencillo con separadores de miles
-			s := strconv.FormatInt(pesos, 10)
-			out := ""
-			for i, r := range s {
-				if i != 0 && (len(s)-i)%3 == 0 {
-					out += "."
-				}
-				out += string(r)
-			}
-			return fmt.Sprintf("$ %s", out)
-		},
-		Query:    "",
-		Year:     time.Now().Year(),
-		LoggedIn: loggedIn,
-		UserName: userName,
-	}
-	// Parse only layout and cart templates to ensure the cart content block is the one used
-	tpl, err := template.ParseFiles("./templates/layout.html", "./templates/cart.html")
-	if err != nil {
-		log.Printf("template parse error: %v", err)
-		http.Error(w, "template error", 500)
-		return
-	}
-	if err := tpl.ExecuteTemplate(w, "layout.html", data); err != nil {
-		log.Printf("template render error: %v", err)
-		http.Error(w, "template error", 500)
+		Items:    vm.Items,
+		Total:    vm.Total,
+		Estimate: vm.Estimate,
+		Msg:      vm.Msg,
+
+		IdempotencyKey: newIdempotencyKey(),
+		Addresses:      addrs,
+		Cards:          cards,
+		WalletCents:    walletCents,
 	}
+	s.pages.Render(w, r, "cart.html", data)
 }
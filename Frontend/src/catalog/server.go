@@ -1,93 +1,67 @@
-package main
+// Package catalog es el módulo del catálogo: listado con búsqueda y detalle
+// del libro con su disponibilidad.
+package catalog
 
 import (
 	"context"
 	"embed"
 	"html/template"
-	"log"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ahinestrog/mybookstore/Frontend/src/web"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/webform"
 	catalogpb "github.com/ahinestrog/mybookstore/proto/gen/catalog"
 	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 	inventorypb "github.com/ahinestrog/mybookstore/proto/gen/inventory"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 //go:embed templates/*.html
 var templatesFS embed.FS
 
-//go:embed static/*
+//go:embed static
 var staticFS embed.FS
 
 type Server struct {
-	tplList *template.Template
-	tplBook *template.Template
-	client  catalogpb.CatalogClient
-	invCli  inventorypb.InventoryClient
+	pages  *web.Pages
+	client catalogpb.CatalogClient
+	invCli inventorypb.InventoryClient
 }
 
-func main() {
-	addr := getenv("FRONTEND_CATALOG_ADDR", ":8081")
-	grpcAddr := getenv("CATALOG_GRPC_ADDR", "localhost:50051")
-
-	// gRPC client
-	conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// New conecta con Catalog (CATALOG_GRPC_ADDR) e Inventory (INVENTORY_SERVICE_ADDR
+// o INVENTORY_GRPC_ADDR).
+func New(web.Deps) (*Server, error) {
+	grpcAddr := web.Getenv("CATALOG_GRPC_ADDR", "localhost:50051")
+	conn, err := grpcclient.Conn(grpcAddr)
 	if err != nil {
-		log.Fatalf("dial catalog grpc (%s): %v", grpcAddr, err)
+		return nil, err
 	}
-	defer conn.Close()
-	client := catalogpb.NewCatalogClient(conn)
-
 	// Inventory gRPC (for availability on book page)
-	// Prefer INVENTORY_SERVICE_ADDR over INVENTORY_GRPC_ADDR
-	invAddr := os.Getenv("INVENTORY_SERVICE_ADDR")
-	if invAddr == "" {
-		invAddr = getenv("INVENTORY_GRPC_ADDR", "inventory:50052")
-	}
-	invConn, err := grpc.Dial(invAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	invAddr := web.Getenv("INVENTORY_SERVICE_ADDR", web.Getenv("INVENTORY_GRPC_ADDR", "inventory:50052"))
+	invConn, err := grpcclient.Conn(invAddr)
 	if err != nil {
-		log.Fatalf("dial inventory grpc (%s): %v", invAddr, err)
+		return nil, err
 	}
-	defer invConn.Close()
-	invClient := inventorypb.NewInventoryClient(invConn)
-
-	// Parse templates
 	funcs := template.FuncMap{
 		"add":   func(a, b int32) int32 { return a + b },
 		"split": func(s, sep string) []string { return strings.Split(s, sep) },
-		"year":  func() int { return time.Now().Year() },
-	}
-
-	tplLayout := template.Must(template.New("layout.html").Funcs(funcs).ParseFS(templatesFS, "templates/layout.html"))
-	tplList := template.Must(template.Must(tplLayout.Clone()).ParseFS(templatesFS, "templates/index.html"))
-	tplBook := template.Must(template.Must(tplLayout.Clone()).ParseFS(templatesFS, "templates/book.html"))
-
-	s := &Server{tplList: tplList, tplBook: tplBook, client: client, invCli: invClient}
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", s.handleIndex)
-	mux.HandleFunc("/book", s.handleBook)
-
-	// static
-	mux.Handle("/static/", http.FileServer(http.FS(staticFS)))
-
-	log.Printf("Catalog Frontend listening on %s (Catalog gRPC → %s, Inventory gRPC → %s)", addr, grpcAddr, invAddr)
-	if err := http.ListenAndServe(addr, withLog(mux)); err != nil {
-		log.Fatal(err)
 	}
+	return &Server{
+		pages:  web.MustParsePages("catalog", templatesFS, "templates/*.html", funcs),
+		client: catalogpb.NewCatalogClient(conn),
+		invCli: inventorypb.NewInventoryClient(invConn),
+	}, nil
 }
 
-func withLog(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		h.ServeHTTP(w, r)
-		log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
-	})
+func (s *Server) Name() string { return "catalog" }
+
+func (s *Server) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("/", webform.Methods(s.handleIndex, http.MethodGet))
+	mux.HandleFunc("/book", webform.Methods(s.handleBook, http.MethodGet))
+	mux.Handle("/static/", web.Static(staticFS))
 }
 
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
@@ -112,7 +86,7 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 	if err != nil {
-		httpError(w, "No se pudo obtener el catálogo: "+err.Error(), http.StatusBadGateway)
+		http.Error(w, "No se pudo obtener el catálogo: "+err.Error(), http.StatusBadGateway)
 		return
 	}
 
@@ -152,17 +126,15 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 
 		items = append(items, listItem{
 			Id: it.GetId(), Title: it.GetTitle(), Author: it.GetAuthor(),
-			CoverUrl: finalCover, PriceStr: "$ " + formatThousands(priceCents/100),
+			CoverUrl: finalCover, PriceStr: web.COP(priceCents),
 		})
 	}
 
 	data := struct {
-		Query    string
-		Items    []listItem
-		Page     *commonpb.PageResponse
-		PageURL  func(int) string
-		LoggedIn bool
-		UserName string
+		Query   string
+		Items   []listItem
+		Page    *commonpb.PageResponse
+		PageURL func(int) string
 	}{
 		Query: q,
 		Items: items,
@@ -174,14 +146,8 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 			// Use relative URL to keep current path prefix (e.g., /catalog)
 			return "?" + qs.Encode()
 		},
-		LoggedIn: cookieUID(r) != 0,
-		UserName: cookieUName(r),
-	}
-
-	if err := s.tplList.Execute(w, data); err != nil {
-		log.Printf("template execute list error: %v", err)
-		httpError(w, "Error renderizando página", http.StatusInternalServerError)
 	}
+	s.pages.Render(w, r, "index.html", data)
 }
 
 func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
@@ -190,12 +156,12 @@ func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
 
 	id := atoi64Default(r.URL.Query().Get("id"), 0)
 	if id <= 0 {
-		httpError(w, "id inválido", http.StatusBadRequest)
+		http.Error(w, "id inválido", http.StatusBadRequest)
 		return
 	}
 	b, err := s.client.GetBook(ctx, &catalogpb.GetBookRequest{Id: id})
 	if err != nil {
-		httpError(w, "No se pudo obtener el libro: "+err.Error(), http.StatusBadGateway)
+		http.Error(w, "No se pudo obtener el libro: "+err.Error(), http.StatusBadGateway)
 		return
 	}
 
@@ -206,7 +172,7 @@ func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
 		tryPath := "static" + finalCover
 		if f, err := staticFS.Open(tryPath); err == nil {
 			f.Close()
-			finalCover = "/static" + finalCover
+			finalCover = "static" + finalCover
 		} else {
 			finalCover = "https://via.placeholder.com/200x300?text=Cover"
 		}
@@ -226,41 +192,17 @@ func (s *Server) handleBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data := struct {
-		Query string
-		Book  *catalogpb.Book
-		// helper para formatear dinero: cents → "12.345,67" o "12,345.67" según preferencia
-		FormatCOP func(int64) string
-		LoggedIn  bool
-		UserName  string
+		Book      *catalogpb.Book
 		Available int32
 	}{
-		Query: r.URL.Query().Get("q"),
-		Book:  b,
-		FormatCOP: func(cents int64) string {
-			// muy simple: pesos enteros
-			pesos := cents / 100
-			return "$ " + formatThousands(pesos)
-		},
-		LoggedIn:  cookieUID(r) != 0,
-		UserName:  cookieUName(r),
+		Book:      b,
 		Available: avail,
 	}
-
-	if err := s.tplBook.Execute(w, data); err != nil {
-		log.Printf("template execute book error: %v", err)
-		httpError(w, "Error renderizando página", http.StatusInternalServerError)
-	}
+	s.pages.Render(w, r, "book.html", data)
 }
 
 // --- utils ---
 
-func getenv(k, d string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
-	}
-	return d
-}
-
 func atoiDefault(s string, d int) int {
 	if s == "" {
 		return d
@@ -282,44 +224,3 @@ func atoi64Default(s string, d int64) int64 {
 	}
 	return i
 }
-
-func httpError(w http.ResponseWriter, msg string, code int) {
-	w.WriteHeader(code)
-	_, _ = w.Write([]byte("<pre>" + template.HTMLEscapeString(msg) + "</pre>"))
-}
-
-func formatThousands(n int64) string {
-	s := strconv.FormatInt(n, 10)
-	neg := false
-	if n < 0 {
-		neg = true
-		s = s[1:]
-	}
-	out := ""
-	for i, r := range s {
-		if i != 0 && (len(s)-i)%3 == 0 {
-			out += "."
-		}
-		out += string(r)
-	}
-	if neg {
-		out = "-" + out
-	}
-	return out
-}
-
-// --- login helpers ---
-func cookieUID(r *http.Request) int64 {
-	if c, err := r.Cookie("uid"); err == nil {
-		if id, err2 := strconv.ParseInt(c.Value, 10, 64); err2 == nil {
-			return id
-		}
-	}
-	return 0
-}
-func cookieUName(r *http.Request) string {
-	if c, err := r.Cookie("uname"); err == nil {
-		return c.Value
-	}
-	return ""
-}
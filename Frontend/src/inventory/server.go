@@ -1,82 +1,61 @@
-package main
+// Package inventory es el módulo del panel de inventario: una página que
+// consulta la disponibilidad con /api/inventory.
+package inventory
 
 import (
 	"context"
+	"embed"
 	"encoding/json"
-	"fmt"
-	"html/template"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ahinestrog/mybookstore/Frontend/src/web"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/webform"
 	inventorypb "github.com/ahinestrog/mybookstore/proto/gen/inventory"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
+//go:embed templates/*.html
+var templatesFS embed.FS
+
 type StockItem struct {
 	BookID       int64 `json:"book_id"`
 	AvailableQty int32 `json:"available_qty"`
 }
 
-func main() {
-	mux := http.NewServeMux()
-
-	// estáticos con y sin prefijo /inventory
-	fs := http.FileServer(http.Dir("static"))
-	mux.Handle("/static/", http.StripPrefix("/static/", fs))
-	mux.Handle("/inventory/static/", http.StripPrefix("/inventory/static/", fs))
-
-	// API endpoint (server-side calls inventory gRPC)
-	mux.HandleFunc("/api/inventory", handleAPIInventory)
-
-	// Frontend
-	mux.HandleFunc("/", handleInventoryPage)
-	mux.HandleFunc("/inventory", handleInventoryPage)
-	mux.HandleFunc("/inventory/", handleInventoryPage)
-
-	addr := getenv("FRONTEND_INVENTORY_ADDR", ":8082")
-	fmt.Printf("🌐 Inventory Frontend escuchando en %s\n", addr)
-	http.ListenAndServe(addr, mux)
+type Server struct {
+	pages  *web.Pages
+	client inventorypb.InventoryClient
 }
 
-func getenv(k, d string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
+// New conecta con Inventory (INVENTORY_SERVICE_ADDR o INVENTORY_GRPC_ADDR).
+func New(web.Deps) (*Server, error) {
+	cc, err := grpcclient.Conn(web.Getenv("INVENTORY_SERVICE_ADDR", web.Getenv("INVENTORY_GRPC_ADDR", "inventory:50052")))
+	if err != nil {
+		return nil, err
 	}
-	return d
+	return &Server{
+		pages:  web.MustParsePages("inventory", templatesFS, "templates/*.html", nil),
+		client: inventorypb.NewInventoryClient(cc),
+	}, nil
 }
 
-func invClient(ctx context.Context) (inventorypb.InventoryClient, *grpc.ClientConn, error) {
-	// Prefer INVENTORY_SERVICE_ADDR over INVENTORY_GRPC_ADDR
-	target := os.Getenv("INVENTORY_SERVICE_ADDR")
-	if target == "" {
-		target = getenv("INVENTORY_GRPC_ADDR", "inventory:50052")
-	}
-	cc, err := grpc.DialContext(ctx, target, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return nil, nil, err
-	}
-	return inventorypb.NewInventoryClient(cc), cc, nil
+func (s *Server) Name() string { return "inventory" }
+
+func (s *Server) Routes(mux *http.ServeMux) {
+	// API endpoint (server-side calls inventory gRPC)
+	mux.HandleFunc("/api/inventory", webform.Methods(s.handleAPIInventory, http.MethodGet))
+	// Frontend (solo lectura)
+	mux.HandleFunc("/", webform.Methods(s.handleInventoryPage, http.MethodGet))
 }
 
-func handleInventoryPage(w http.ResponseWriter, r *http.Request) {
-	tpl, err := template.ParseFiles("templates/index.html")
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	data := map[string]any{
-		"LoggedIn": cookieUID(r) != 0,
-		"UserName": cookieUName(r),
-		"Prefill":  r.URL.Query().Get("ids"),
-	}
-	_ = tpl.Execute(w, data)
+func (s *Server) handleInventoryPage(w http.ResponseWriter, r *http.Request) {
+	s.pages.Render(w, r, "index.html", map[string]any{"Prefill": r.URL.Query().Get("ids")})
 }
 
-func handleAPIInventory(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleAPIInventory(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	raw := strings.TrimSpace(r.URL.Query().Get("ids"))
@@ -102,13 +81,7 @@ func handleAPIInventory(w http.ResponseWriter, r *http.Request) {
 
 	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
 	defer cancel()
-	cli, cc, err := invClient(ctx)
-	if err != nil {
-		http.Error(w, "inventory grpc: "+err.Error(), http.StatusBadGateway)
-		return
-	}
-	defer cc.Close()
-	resp, err := cli.GetAvailability(ctx, &inventorypb.GetAvailabilityRequest{BookIds: ids})
+	resp, err := s.client.GetAvailability(ctx, &inventorypb.GetAvailabilityRequest{BookIds: ids})
 	if err != nil {
 		http.Error(w, "inventory grpc: "+err.Error(), http.StatusBadGateway)
 		return
@@ -119,19 +92,3 @@ func handleAPIInventory(w http.ResponseWriter, r *http.Request) {
 	}
 	_ = json.NewEncoder(w).Encode(map[string]any{"items": items})
 }
-
-// --- login helpers ---
-func cookieUID(r *http.Request) int64 {
-	if c, err := r.Cookie("uid"); err == nil {
-		if id, err2 := strconv.ParseInt(c.Value, 10, 64); err2 == nil {
-			return id
-		}
-	}
-	return 0
-}
-func cookieUName(r *http.Request) string {
-	if c, err := r.Cookie("uname"); err == nil {
-		return c.Value
-	}
-	return ""
-}
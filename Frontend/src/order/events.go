@@ -0,0 +1,150 @@
+package order
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
+)
+
+const (
+	// sseRetry es lo que espera el navegador antes de reconectar.
+	sseRetry = 3 * time.Second
+	// sseHeartbeat mantiene abierta la conexión a través del ingress mientras la orden no cambia.
+	sseHeartbeat = 15 * time.Second
+)
+
+// eventView es el data: de cada evento "status", con los mismos textos que status.html.
+type eventView struct {
+	Status      string `json:"status"`
+	Reason      string `json:"reason,omitempty"`
+	Fulfillment string `json:"fulfillment,omitempty"`
+	Final       bool   `json:"final"`
+}
+
+type watchResult struct {
+	ev  *orderpb.OrderEvent
+	err error
+}
+
+// GET /events?id=N
+//
+// Server-Sent Events con los cambios de la orden (WatchOrder). Cada evento lleva
+// id: con su event_id; al reconectar, EventSource lo devuelve en Last-Event-ID y
+// el stream sigue desde ahí sin repetir ni perder transiciones. Si el servicio
+// no está disponible la respuesta termina y el navegador reintenta tras retry:;
+// si la orden no existe o es de otro usuario se envía "gone" y el cliente cierra.
+func (a *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	oid, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil || oid <= 0 {
+		http.Error(w, "order_id inválido", http.StatusBadRequest)
+		return
+	}
+	var after int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		if after, err = strconv.ParseInt(v, 10, 64); err != nil || after < 0 {
+			http.Error(w, "Last-Event-ID inválido", http.StatusBadRequest)
+			return
+		}
+	}
+	client, err := a.dialOrder()
+	if err != nil {
+		http.Error(w, "No se pudo conectar al servicio de órdenes", http.StatusBadGateway)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-store")
+	h.Set("X-Accel-Buffering", "no") // ingress-nginx: no acumular la respuesta
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if err := rc.Flush(); err != nil {
+		slog.WarnContext(r.Context(), "events: el servidor no admite streaming", "err", err)
+		return
+	}
+
+	stream, err := client.WatchOrder(ctx, &orderpb.WatchOrderRequest{OrderId: oid, AfterEventId: after})
+	if err != nil {
+		watchFailed(ctx, w, oid, err)
+		return
+	}
+	events := make(chan watchResult)
+	go func() {
+		for {
+			ev, err := stream.Recv()
+			select {
+			case events <- watchResult{ev, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+		case res := <-events:
+			if res.err == io.EOF {
+				return
+			}
+			if res.err != nil {
+				watchFailed(ctx, w, oid, res.err)
+				return
+			}
+			writeEvent(w, res.ev)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w io.Writer, ev *orderpb.OrderEvent) {
+	data, _ := json.Marshal(eventView{
+		Status:      orderStatusToText(ev.GetStatus()),
+		Reason:      statusReasonToText(ev.GetStatusReason()),
+		Fulfillment: fulfillmentStateToText(ev.GetFulfillmentState()),
+		Final:       ev.GetFinal(),
+	})
+	// Sin historial (event_id 0) no hay desde dónde reanudar: se omite id:
+	if id := ev.GetEventId(); id > 0 {
+		fmt.Fprintf(w, "id: %d\n", id)
+	}
+	fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+}
+
+// watchFailed termina el stream. Los errores transitorios solo cierran la
+// respuesta (el navegador reconecta); los demás no se arreglan reintentando.
+func watchFailed(ctx context.Context, w http.ResponseWriter, oid int64, err error) {
+	switch status.Code(err) {
+	case codes.Canceled:
+		return
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		slog.WarnContext(ctx, "WatchOrder", "order_id", oid, "err", err)
+		return
+	}
+	msg, _ := json.Marshal(status.Convert(err).Message())
+	fmt.Fprintf(w, "event: gone\ndata: %s\n\n", msg)
+	http.NewResponseController(w).Flush()
+}
@@ -1,19 +1,27 @@
-package main
+// Package order es el módulo de órdenes: creación, estado y devoluciones.
+package order
 
 import (
 	"context"
+	"crypto/rand"
 	"embed"
+	"encoding/hex"
 	"fmt"
-	"html/template"
-	"log"
+	"log/slog"
+	"math"
 	"net/http"
-	"os"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 
+	"github.com/ahinestrog/mybookstore/Frontend/src/web"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/session"
+	"github.com/ahinestrog/mybookstore/pkg/webform"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 	orderpb "github.com/ahinestrog/mybookstore/proto/gen/order"
 	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
 )
@@ -21,125 +29,90 @@ import (
 //go:embed templates/*.html
 var tplFS embed.FS
 
-//go:embed static/*
-var staticFS embed.FS
-
-type app struct {
-	addr        string
+type Server struct {
 	orderAddr   string
 	paymentAddr string
-	tpls        *template.Template
-	httpServer  *http.Server
+	pages       *web.Pages
 }
 
-func main() {
-	a := &app{
-		addr:        getEnv("FRONTEND_ADDR", ":8083"),
-		orderAddr:   getEnv("ORDER_SVC_ADDR", "localhost:50056"),
-		paymentAddr: getEnv("PAYMENT_SVC_ADDR", "localhost:50053"),
-	}
-	a.loadTemplates()
-	mux := http.NewServeMux()
-
-	// static
-	mux.Handle("/static/", http.FileServer(http.FS(staticFS)))
-
-	// routes
-	mux.HandleFunc("/", a.handleIndex)
-	mux.HandleFunc("/create", a.handleCreate)
-	mux.HandleFunc("/status", a.handleStatus)
-
-	// enlaces rápidos a otras vistas del frontend como el catalogo o el carrito de compras
-	mux.HandleFunc("/catalog", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/catalog/", http.StatusFound) })
-	mux.HandleFunc("/cart", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/cart/", http.StatusFound) })
-	mux.HandleFunc("/inventory", func(w http.ResponseWriter, r *http.Request) { http.Redirect(w, r, "/inventory/", http.StatusFound) })
-
-	a.httpServer = &http.Server{
-		Addr:              a.addr,
-		Handler:           mux,
-		ReadHeaderTimeout: 5 * time.Second,
-	}
-	log.Printf("[order-frontend] http listening on %s (order svc: %s, payment svc: %s)", a.addr, a.orderAddr, a.paymentAddr)
-	if err := a.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatal(err)
-	}
+// New prepara el módulo; las conexiones a Order y Payment se piden al pool
+// de grpcclient en cada petición.
+func New(web.Deps) (*Server, error) {
+	return &Server{
+		orderAddr:   web.Getenv("ORDER_SVC_ADDR", "localhost:50056"),
+		paymentAddr: web.Getenv("PAYMENT_SVC_ADDR", web.Getenv("PAYMENT_GRPC_ADDR", "localhost:50053")),
+		pages:       web.MustParsePages("order", tplFS, "templates/*.html", nil),
+	}, nil
 }
 
-func (a *app) loadTemplates() {
-	tpls := template.Must(template.ParseFS(tplFS, "templates/*.html"))
-	a.tpls = tpls
-}
+func (a *Server) Name() string { return "order" }
 
-func (a *app) dialOrder() (*grpc.ClientConn, orderpb.OrderClient, error) {
-	cc, err := grpc.Dial(a.orderAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return nil, nil, err
-	}
-	return cc, orderpb.NewOrderClient(cc), nil
+func (a *Server) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("/", webform.Methods(a.handleIndex, http.MethodGet))
+	mux.HandleFunc("/create", webform.Methods(a.handleCreate, http.MethodPost))
+	mux.HandleFunc("/status", webform.Methods(a.handleStatus, http.MethodGet))
+	mux.HandleFunc("/events", webform.Methods(a.handleEvents, http.MethodGet))
+	mux.HandleFunc("/returns", webform.Methods(a.handleRequestReturn, http.MethodPost))
 }
 
-func (a *app) dialPayment() (*grpc.ClientConn, paymentpb.PaymentClient, error) {
-	cc, err := grpc.Dial(a.paymentAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// Las conexiones salen del pool de grpcclient; no se cierran por petición.
+func (a *Server) dialOrder() (orderpb.OrderClient, error) {
+	cc, err := grpcclient.Conn(a.orderAddr)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return cc, paymentpb.NewPaymentClient(cc), nil
+	return orderpb.NewOrderClient(cc), nil
 }
 
-// Función render que ejecuta el layout y las plantillas
-func render(w http.ResponseWriter, tpls *template.Template, layout, name string, data any) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	// Execute the layout template so that defined blocks (e.g. content/title)
-	// inside other files (index.html/status.html) are rendered within the layout.
-	// Previously this executed the inner 'name' template which only contained
-	// definitions and produced an empty response.
-	if err := tpls.ExecuteTemplate(w, layout, data); err != nil {
-		// Log the error server-side and return an informative 500 body for quicker debugging
-		log.Printf("template execute error: %v (layout=%s name=%s)", err, layout, name)
-		http.Error(w, fmt.Sprintf("error renderizando %s: %v", name, err), http.StatusInternalServerError)
+func (a *Server) dialPayment() (paymentpb.PaymentClient, error) {
+	cc, err := grpcclient.Conn(a.paymentAddr)
+	if err != nil {
+		return nil, err
 	}
+	return paymentpb.NewPaymentClient(cc), nil
 }
 
 // GET /
-func (a *app) handleIndex(w http.ResponseWriter, r *http.Request) {
+func (a *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
 		return
 	}
-	data := map[string]any{
-		"LoggedIn": cookieUID(r) != 0,
-		"UserName": cookieUName(r),
-	}
-	render(w, a.tpls, "layout.html", "index.html", data)
+	data := map[string]any{"IdempotencyKey": newIdempotencyKey()}
+	a.pages.Render(w, r, "index.html", data)
 }
 
 // POST /create
-func (a *app) handleCreate(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+func (a *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	// La orden siempre es del usuario de la sesión, nunca de un campo del formulario
+	uid := session.UserID(r)
+	if uid == 0 {
+		http.Redirect(w, r, web.LoginURL("/order/"), http.StatusSeeOther)
 		return
 	}
-	r.ParseForm()
-	uidStr := r.Form.Get("user_id")
-	uid, err := strconv.ParseInt(uidStr, 10, 64)
-	if err != nil || uid <= 0 {
-		render(w, a.tpls, "layout.html", "index.html", map[string]any{"Error": "user_id inválido"})
+
+	f := webform.Parse(r)
+	idemKey := f.Text("idempotency_key", "Clave de idempotencia", 0, 64)
+	if !f.Valid() {
+		a.pages.Render(w, r, "index.html", map[string]any{"Error": f.Message()})
 		return
 	}
 
-	cc, client, err := a.dialOrder()
+	client, err := a.dialOrder()
 	if err != nil {
-		render(w, a.tpls, "layout.html", "index.html", map[string]any{"Error": "No se pudo conectar al servicio de órdenes"})
+		a.pages.Render(w, r, "index.html", map[string]any{"Error": "No se pudo conectar al servicio de órdenes"})
 		return
 	}
-	defer cc.Close()
 
 	ctx, cancel := timeoutCtx(r.Context(), 5*time.Second)
 	defer cancel()
 
-	resp, err := client.CreateOrder(ctx, &orderpb.CreateOrderRequest{UserId: uid})
+	resp, err := client.CreateOrder(ctx, &orderpb.CreateOrderRequest{
+		UserId:         uid,
+		IdempotencyKey: idemKey,
+	})
 	if err != nil {
-		render(w, a.tpls, "layout.html", "index.html", map[string]any{"Error": fmt.Sprintf("CreateOrder falló: %v", err)})
+		a.pages.Render(w, r, "index.html", map[string]any{"Error": fmt.Sprintf("CreateOrder falló: %v", err)})
 		return
 	}
 
@@ -154,43 +127,43 @@ func (a *app) handleCreate(w http.ResponseWriter, r *http.Request) {
 		rows = append(rows, row{
 			Title: it.Title,
 			Qty:   it.Qty,
-			Unit:  centsToStr(it.UnitPrice.GetCents()),
-			Line:  centsToStr(it.LineTotal.GetCents()),
+			Unit:  web.COP(it.UnitPrice.GetCents()),
+			Line:  web.COP(it.LineTotal.GetCents()),
 		})
 	}
 
 	data := map[string]any{
-		"Created": true,
-		"OrderID": resp.GetOrderId(),
-		"Status":  orderStatusToText(resp.GetStatus()),
-		"Total":   centsToStr(resp.GetTotal().GetCents()),
-		"Items":   rows,
+		"Created":   true,
+		"OrderID":   resp.GetOrderId(),
+		"Status":    orderStatusToText(resp.GetStatus()),
+		"Total":     web.COP(resp.GetTotal().GetCents()),
+		"Items":     rows,
+		"Breakdown": breakdownView(resp.GetBreakdown()),
 	}
-	render(w, a.tpls, "layout.html", "index.html", data)
+	a.pages.Render(w, r, "index.html", data)
 }
 
 // GET /status
-func (a *app) handleStatus(w http.ResponseWriter, r *http.Request) {
+func (a *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("id")
-	data := map[string]any{"QueryOrderID": q, "LoggedIn": cookieUID(r) != 0, "UserName": cookieUName(r)}
+	data := map[string]any{"QueryOrderID": q}
 	if q == "" {
-		render(w, a.tpls, "layout.html", "status.html", data)
+		a.pages.Render(w, r, "status.html", data)
 		return
 	}
 	oid, err := strconv.ParseInt(q, 10, 64)
 	if err != nil || oid <= 0 {
 		data["Error"] = "order_id inválido"
-		render(w, a.tpls, "layout.html", "status.html", data)
+		a.pages.Render(w, r, "status.html", data)
 		return
 	}
 
-	cc, client, err := a.dialOrder()
+	client, err := a.dialOrder()
 	if err != nil {
 		data["Error"] = "No se pudo conectar al servicio de órdenes"
-		render(w, a.tpls, "layout.html", "status.html", data)
+		a.pages.Render(w, r, "status.html", data)
 		return
 	}
-	defer cc.Close()
 
 	ctx, cancel := timeoutCtx(r.Context(), 4*time.Second)
 	defer cancel()
@@ -198,15 +171,39 @@ func (a *app) handleStatus(w http.ResponseWriter, r *http.Request) {
 	resp, err := client.GetOrderStatus(ctx, &orderpb.GetOrderStatusRequest{OrderId: oid})
 	if err != nil {
 		data["Error"] = fmt.Sprintf("GetOrderStatus falló: %v", err)
-		render(w, a.tpls, "layout.html", "status.html", data)
+		a.pages.Render(w, r, "status.html", data)
 		return
 	}
 
 	data["Found"] = true
 	data["OrderID"] = resp.GetOrderId()
 	data["Status"] = orderStatusToText(resp.GetStatus())
-	data["Total"] = centsToStr(resp.GetTotal().GetCents())
+	data["Total"] = web.COP(resp.GetTotal().GetCents())
+	data["Breakdown"] = breakdownView(resp.GetBreakdown())
 	data["UpdatedUnix"] = resp.GetUpdatedUnix()
+	data["ShipTo"] = resp.GetShippingAddress()
+	data["PaymentMethod"] = paymentMethodToText(resp.GetPaymentMethod())
+	data["StatusReason"] = statusReasonToText(resp.GetStatusReason())
+	if f := resp.GetFulfillment(); f != nil {
+		data["Fulfillment"] = fulfillmentStateToText(f.GetState())
+		data["Carrier"] = f.GetCarrier()
+		data["Tracking"] = f.GetTrackingNumber()
+	}
+
+	// Devoluciones: historial y, si la orden ya se entregó, las líneas que aún se pueden devolver
+	rctx, rcancel := timeoutCtx(r.Context(), 3*time.Second)
+	defer rcancel()
+	rets, err := client.ListReturns(rctx, &commonpb.OrderRef{OrderId: oid})
+	if err != nil {
+		slog.WarnContext(r.Context(), "ListReturns falló", "order_id", oid, "err", err)
+	} else {
+		data["Returns"] = returnsView(rets.GetReturns())
+		if resp.GetFulfillment().GetState() == orderpb.FulfillmentState_FULFILLMENT_STATE_DELIVERED && session.UserID(r) != 0 {
+			data["Returnable"] = returnableLines(resp.GetItems(), rets.GetReturns())
+			data["ReturnReasons"] = returnReasonOptions
+		}
+	}
+	data["ReturnMsg"] = r.URL.Query().Get("return_msg")
 
 	// Check if this is a newly created order (coming from checkout)
 	// If the order status is CREATED and there's no 'from' query param, assume it's just created
@@ -215,18 +212,17 @@ func (a *app) handleStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get payment status
-	pcc, pclient, err := a.dialPayment()
+	pclient, err := a.dialPayment()
 	if err != nil {
-		log.Printf("[order] failed to dial payment service: %v", err)
+		slog.WarnContext(r.Context(), "sin conexión con payment", "err", err)
 		// Continue without payment info
 	} else {
-		defer pcc.Close()
 		pctx, pcancel := timeoutCtx(r.Context(), 3*time.Second)
 		defer pcancel()
 
 		presp, err := pclient.GetPaymentStatus(pctx, &paymentpb.GetPaymentStatusRequest{OrderId: oid})
 		if err != nil {
-			log.Printf("[order] GetPaymentStatus failed: %v", err)
+			slog.WarnContext(r.Context(), "GetPaymentStatus falló", "order_id", oid, "err", err)
 		} else {
 			data["PaymentState"] = paymentStateToText(presp.GetState())
 			data["ProviderRef"] = presp.GetProviderRef()
@@ -234,7 +230,167 @@ func (a *app) handleStatus(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	render(w, a.tpls, "layout.html", "status.html", data)
+	a.pages.Render(w, r, "status.html", data)
+}
+
+// POST /returns
+func (a *Server) handleRequestReturn(w http.ResponseWriter, r *http.Request) {
+	f := webform.Parse(r)
+	oid := f.ID("order_id", "Orden")
+	if !f.Valid() {
+		http.Redirect(w, r, "/order/status", http.StatusSeeOther)
+		return
+	}
+	back := func(msg string) {
+		http.Redirect(w, r, fmt.Sprintf("/order/status?id=%d&from=returns&return_msg=%s", oid, url.QueryEscape(msg)), http.StatusSeeOther)
+	}
+	uid := session.UserID(r)
+	if uid == 0 {
+		back("Inicia sesión para solicitar una devolución")
+		return
+	}
+
+	// Campos qty_<book_id> y reason_<book_id>; las líneas en 0 no se devuelven
+	req := &orderpb.RequestReturnRequest{OrderId: oid, UserId: uid, Note: f.Text("note", "Comentario", 0, 300)}
+	for k := range r.PostForm {
+		if !strings.HasPrefix(k, "qty_") {
+			continue
+		}
+		bookID, err := strconv.ParseInt(strings.TrimPrefix(k, "qty_"), 10, 64)
+		if err != nil || bookID <= 0 {
+			continue
+		}
+		qty := f.OptionalInt(k, "Cantidad", 0, maxReturnQty, 0)
+		reason := f.OptionalInt(fmt.Sprintf("reason_%d", bookID), "Motivo", 0, math.MaxInt32, 0)
+		if qty == 0 {
+			continue
+		}
+		req.Lines = append(req.Lines, &orderpb.ReturnLine{
+			BookId: bookID,
+			Qty:    int32(qty),
+			Reason: orderpb.ReturnReason(reason),
+		})
+	}
+	if !f.Valid() {
+		back(f.Message())
+		return
+	}
+	if len(req.Lines) == 0 {
+		back("Elige al menos un libro para devolver")
+		return
+	}
+
+	client, err := a.dialOrder()
+	if err != nil {
+		back("No se pudo conectar al servicio de órdenes")
+		return
+	}
+	ctx, cancel := timeoutCtx(r.Context(), 5*time.Second)
+	defer cancel()
+	rt, err := client.RequestReturn(ctx, req)
+	if err != nil {
+		back("No se pudo solicitar la devolución: " + status.Convert(err).Message())
+		return
+	}
+	back(fmt.Sprintf("Devolución #%d solicitada; te avisaremos cuando sea revisada", rt.GetReturnId()))
+}
+
+// maxReturnQty acota la cantidad por línea del formulario; Order valida contra lo comprado.
+const maxReturnQty = 999
+
+// ReturnVM es una devolución lista para la plantilla.
+type ReturnVM struct {
+	ID        int64
+	State     string
+	Refund    string
+	Restocked bool
+	Review    string
+	Lines     []string
+}
+
+func returnsView(rets []*orderpb.Return) []ReturnVM {
+	out := make([]ReturnVM, 0, len(rets))
+	for _, rt := range rets {
+		vm := ReturnVM{
+			ID:        rt.GetReturnId(),
+			State:     returnStateToText(rt.GetState()),
+			Refund:    web.COP(rt.GetRefundTotal().GetCents()),
+			Restocked: rt.GetRestocked(),
+			Review:    rt.GetReviewNote(),
+		}
+		for _, l := range rt.GetLines() {
+			vm.Lines = append(vm.Lines, fmt.Sprintf("%d × %s (%s)", l.GetQty(), l.GetTitle(), returnReasonToText(l.GetReason())))
+		}
+		out = append(out, vm)
+	}
+	return out
+}
+
+// ReturnableLine es una línea de la orden con las unidades que aún se pueden devolver.
+type ReturnableLine struct {
+	BookID int64
+	Title  string
+	Max    int32
+}
+
+func returnableLines(items []*orderpb.OrderItem, rets []*orderpb.Return) []ReturnableLine {
+	used := map[int64]int32{}
+	for _, rt := range rets {
+		if rt.GetState() == orderpb.ReturnState_RETURN_STATE_REJECTED {
+			continue
+		}
+		for _, l := range rt.GetLines() {
+			used[l.GetBookId()] += l.GetQty()
+		}
+	}
+	var out []ReturnableLine
+	for _, it := range items {
+		if left := it.GetQty() - used[it.GetBookId()]; left > 0 {
+			out = append(out, ReturnableLine{BookID: it.GetBookId(), Title: it.GetTitle(), Max: left})
+		}
+	}
+	return out
+}
+
+type reasonOption struct {
+	Value int32
+	Label string
+}
+
+var returnReasonOptions = []reasonOption{
+	{int32(orderpb.ReturnReason_RETURN_REASON_DAMAGED), "Llegó dañado"},
+	{int32(orderpb.ReturnReason_RETURN_REASON_WRONG_ITEM), "No es el libro que pedí"},
+	{int32(orderpb.ReturnReason_RETURN_REASON_NOT_AS_DESCRIBED), "No coincide con la descripción"},
+	{int32(orderpb.ReturnReason_RETURN_REASON_NO_LONGER_NEEDED), "Ya no lo necesito"},
+	{int32(orderpb.ReturnReason_RETURN_REASON_OTHER), "Otro"},
+}
+
+func returnReasonToText(r orderpb.ReturnReason) string {
+	for _, o := range returnReasonOptions {
+		if o.Value == int32(r) {
+			return o.Label
+		}
+	}
+	return "Sin motivo"
+}
+
+func returnStateToText(s orderpb.ReturnState) string {
+	switch s {
+	case orderpb.ReturnState_RETURN_STATE_REQUESTED:
+		return "Solicitada"
+	case orderpb.ReturnState_RETURN_STATE_APPROVED:
+		return "Aprobada: envía los libros"
+	case orderpb.ReturnState_RETURN_STATE_REJECTED:
+		return "Rechazada"
+	case orderpb.ReturnState_RETURN_STATE_RECEIVED:
+		return "Recibida, reembolso en curso"
+	case orderpb.ReturnState_RETURN_STATE_REFUNDED:
+		return "Reembolsada"
+	case orderpb.ReturnState_RETURN_STATE_REFUND_FAILED:
+		return "Reembolso fallido"
+	default:
+		return ""
+	}
 }
 
 func orderStatusToText(s orderpb.OrderStatus) string {
@@ -243,6 +399,8 @@ func orderStatusToText(s orderpb.OrderStatus) string {
 		return "CREATED"
 	case orderpb.OrderStatus_ORDER_STATUS_PAID:
 		return "PAID"
+	case orderpb.OrderStatus_ORDER_STATUS_CONFIRMED:
+		return "CONFIRMED"
 	case orderpb.OrderStatus_ORDER_STATUS_CANCELLED:
 		return "CANCELLED"
 	case orderpb.OrderStatus_ORDER_STATUS_FAILED:
@@ -252,6 +410,38 @@ func orderStatusToText(s orderpb.OrderStatus) string {
 	}
 }
 
+// statusReasonToText explica por qué se canceló la orden; los motivos que no
+// conoce se muestran tal cual.
+func statusReasonToText(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case "inventory_timeout":
+		return "No recibimos confirmación de inventario a tiempo. La reserva se liberó y tu carrito quedó como estaba."
+	case "payment_timeout":
+		return "El pago no se confirmó a tiempo. Si alcanzó a cobrarse, se reembolsa automáticamente."
+	default:
+		return reason
+	}
+}
+
+func fulfillmentStateToText(s orderpb.FulfillmentState) string {
+	switch s {
+	case orderpb.FulfillmentState_FULFILLMENT_STATE_PENDING:
+		return "Pendiente de despacho"
+	case orderpb.FulfillmentState_FULFILLMENT_STATE_PICKING:
+		return "Alistando"
+	case orderpb.FulfillmentState_FULFILLMENT_STATE_PACKED:
+		return "Empacado"
+	case orderpb.FulfillmentState_FULFILLMENT_STATE_SHIPPED:
+		return "Enviado"
+	case orderpb.FulfillmentState_FULFILLMENT_STATE_DELIVERED:
+		return "Entregado"
+	default:
+		return ""
+	}
+}
+
 func paymentStateToText(s paymentpb.PaymentState) string {
 	switch s {
 	case paymentpb.PaymentState_PAYMENT_STATE_PENDING:
@@ -260,48 +450,60 @@ func paymentStateToText(s paymentpb.PaymentState) string {
 		return "SUCCEEDED"
 	case paymentpb.PaymentState_PAYMENT_STATE_FAILED:
 		return "FAILED"
+	case paymentpb.PaymentState_PAYMENT_STATE_REFUNDED:
+		return "REFUNDED"
+	case paymentpb.PaymentState_PAYMENT_STATE_PARTIALLY_REFUNDED:
+		return "PARTIALLY_REFUNDED"
+	case paymentpb.PaymentState_PAYMENT_STATE_AWAITING_DELIVERY:
+		return "AWAITING_DELIVERY"
+	case paymentpb.PaymentState_PAYMENT_STATE_UNDER_REVIEW:
+		return "UNDER_REVIEW"
 	default:
 		return "UNSPECIFIED"
 	}
 }
 
-func centsToStr(c int64) string {
-	sign := ""
-	if c < 0 {
-		sign = "-"
-		c = -c
+func paymentMethodToText(m commonpb.PaymentMethod) string {
+	switch m {
+	case commonpb.PaymentMethod_PAYMENT_METHOD_PSE:
+		return "PSE"
+	case commonpb.PaymentMethod_PAYMENT_METHOD_CARD:
+		return "Tarjeta"
+	case commonpb.PaymentMethod_PAYMENT_METHOD_CASH_ON_DELIVERY:
+		return "Contra entrega"
+	case commonpb.PaymentMethod_PAYMENT_METHOD_STORE_CREDIT:
+		return "Saldo a favor"
+	default:
+		return ""
 	}
-	d := c / 100
-	ct := c % 100
-	return fmt.Sprintf("%s$%d.%02d", sign, d, ct)
 }
 
-func timeoutCtx(parent context.Context, d time.Duration) (context.Context, func()) {
-	if parent == nil {
-		parent = context.Background()
+// breakdownView formatea el desglose para las plantillas; nil si no viene.
+func breakdownView(b *commonpb.PriceBreakdown) map[string]string {
+	if b == nil {
+		return nil
 	}
-	return context.WithTimeout(parent, d)
-}
-
-func getEnv(k, def string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
+	return map[string]string{
+		"Subtotal": web.COP(b.GetSubtotal().GetCents()),
+		"Discount": web.COP(b.GetDiscount().GetCents()),
+		"Tax":      web.COP(b.GetTax().GetCents()),
+		"Shipping": web.COP(b.GetShipping().GetCents()),
+		"Total":    web.COP(b.GetGrandTotal().GetCents()),
 	}
-	return def
 }
 
-// --- login helpers ---
-func cookieUID(r *http.Request) int64 {
-	if c, err := r.Cookie("uid"); err == nil {
-		if id, err2 := strconv.ParseInt(c.Value, 10, 64); err2 == nil {
-			return id
-		}
+// newIdempotencyKey genera una clave aleatoria por cada render del formulario de creación.
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
 	}
-	return 0
+	return hex.EncodeToString(b)
 }
-func cookieUName(r *http.Request) string {
-	if c, err := r.Cookie("uname"); err == nil {
-		return c.Value
+
+func timeoutCtx(parent context.Context, d time.Duration) (context.Context, func()) {
+	if parent == nil {
+		parent = context.Background()
 	}
-	return ""
+	return context.WithTimeout(parent, d)
 }
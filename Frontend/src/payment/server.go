@@ -1,65 +1,59 @@
-package main
+// Package payment es el módulo de pagos: estado de un pago, tarjetas guardadas
+// y saldo a favor.
+package payment
 
 import (
 	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
-	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ahinestrog/mybookstore/Frontend/src/web"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/session"
+	"github.com/ahinestrog/mybookstore/pkg/webform"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 	paymentpb "github.com/ahinestrog/mybookstore/proto/gen/payment"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
-// Config
+//go:embed templates/*.html
+var templatesFS embed.FS
 
-type Config struct {
-	Port            string // HTTP del frontend de payment
-	PaymentGRPCAddr string // host:puerto del microservicio payment
-}
-
-func getenv(k, def string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
-	}
-	return def
-}
-
-func loadConfig() Config {
-	return Config{
-		Port:            getenv("FRONTEND_PAYMENT_PORT", "8084"),
-		PaymentGRPCAddr: getenv("PAYMENT_GRPC_ADDR", "payment:50053"),
-	}
-}
+//go:embed static
+var staticFS embed.FS
 
 // Templates
 
-func parseTemplates() (*template.Template, error) {
-	t := template.New("base.html").Funcs(template.FuncMap{
-		"since": func(unix int64) string {
-			if unix == 0 {
-				return "-"
-			}
-			return time.Unix(unix, 0).Format("2006-01-02 15:04:05")
-		},
-		"badgeClass": func(state string) string {
-			switch state {
-			case "PENDING":
-				return "badge badge-pending"
-			case "SUCCEEDED":
-				return "badge badge-ok"
-			case "FAILED":
-				return "badge badge-fail"
-			default:
-				return "badge"
-			}
-		},
-	})
-	return t.ParseGlob("templates/*.html")
+var funcs = template.FuncMap{
+	"since": func(unix int64) string {
+		if unix == 0 {
+			return "-"
+		}
+		return time.Unix(unix, 0).Format("2006-01-02 15:04:05")
+	},
+	"walletKind": walletKindLabel,
+	"badgeClass": func(state string) string {
+		switch state {
+		case "PENDING", "AWAITING_DELIVERY", "UNDER_REVIEW":
+			return "badge badge-pending"
+		case "SUCCEEDED":
+			return "badge badge-ok"
+		case "FAILED":
+			return "badge badge-fail"
+		case "REFUNDED", "PARTIALLY_REFUNDED":
+			return "badge badge-refund"
+		default:
+			return "badge"
+		}
+	},
 }
 
 // Cliente gRPC
@@ -71,15 +65,93 @@ type PaymentClient struct {
 func NewPaymentClient(addr string) *PaymentClient { return &PaymentClient{addr: addr} }
 
 func (c *PaymentClient) GetStatus(ctx context.Context, orderID int64) (*paymentpb.GetPaymentStatusResponse, error) {
-	conn, err := grpc.DialContext(ctx, c.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpcclient.Conn(c.addr)
 	if err != nil {
 		return nil, fmt.Errorf("dial payment: %w", err)
 	}
-	defer conn.Close()
 	cl := paymentpb.NewPaymentClient(conn)
 	return cl.GetPaymentStatus(ctx, &paymentpb.GetPaymentStatusRequest{OrderId: orderID})
 }
 
+func (c *PaymentClient) ListTransactions(ctx context.Context, orderID int64) ([]*paymentpb.Transaction, error) {
+	conn, err := grpcclient.Conn(c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial payment: %w", err)
+	}
+	resp, err := paymentpb.NewPaymentClient(conn).ListTransactions(ctx, &paymentpb.ListTransactionsRequest{OrderId: orderID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetTransactions(), nil
+}
+
+func (c *PaymentClient) client() (paymentpb.PaymentClient, error) {
+	conn, err := grpcclient.Conn(c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial payment: %w", err)
+	}
+	return paymentpb.NewPaymentClient(conn), nil
+}
+
+func (c *PaymentClient) ListInstruments(ctx context.Context, userID int64) ([]*paymentpb.Instrument, error) {
+	cl, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := cl.ListInstruments(ctx, &commonpb.UserRef{UserId: userID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetInstruments(), nil
+}
+
+func (c *PaymentClient) SaveInstrument(ctx context.Context, req *paymentpb.SaveInstrumentRequest) error {
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	_, err = cl.SaveInstrument(ctx, req)
+	return err
+}
+
+func (c *PaymentClient) DeleteInstrument(ctx context.Context, userID, id int64) error {
+	cl, err := c.client()
+	if err != nil {
+		return err
+	}
+	_, err = cl.DeleteInstrument(ctx, &paymentpb.InstrumentRef{UserId: userID, InstrumentId: id})
+	return err
+}
+
+func (c *PaymentClient) Wallet(ctx context.Context, userID int64) (*paymentpb.ListWalletTransactionsResponse, error) {
+	cl, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	return cl.ListWalletTransactions(ctx, &paymentpb.ListWalletTransactionsRequest{UserId: userID})
+}
+
+func (c *PaymentClient) RedeemGiftCard(ctx context.Context, userID int64, code string) (*paymentpb.RedeemGiftCardResponse, error) {
+	cl, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	return cl.RedeemGiftCard(ctx, &paymentpb.RedeemGiftCardRequest{UserId: userID, Code: code})
+}
+
+func mapKindToString(k paymentpb.TransactionKind) string {
+	switch k {
+	case paymentpb.TransactionKind_TRANSACTION_KIND_CHARGE:
+		return "Cobro"
+	case paymentpb.TransactionKind_TRANSACTION_KIND_REFUND:
+		return "Reembolso"
+	case paymentpb.TransactionKind_TRANSACTION_KIND_PARTIAL_REFUND:
+		return "Reembolso parcial"
+	default:
+		return "-"
+	}
+}
+
 func mapStateToString(s paymentpb.PaymentState) string {
 	switch s {
 	case paymentpb.PaymentState_PAYMENT_STATE_PENDING:
@@ -88,6 +160,14 @@ func mapStateToString(s paymentpb.PaymentState) string {
 		return "SUCCEEDED"
 	case paymentpb.PaymentState_PAYMENT_STATE_FAILED:
 		return "FAILED"
+	case paymentpb.PaymentState_PAYMENT_STATE_REFUNDED:
+		return "REFUNDED"
+	case paymentpb.PaymentState_PAYMENT_STATE_PARTIALLY_REFUNDED:
+		return "PARTIALLY_REFUNDED"
+	case paymentpb.PaymentState_PAYMENT_STATE_AWAITING_DELIVERY:
+		return "AWAITING_DELIVERY"
+	case paymentpb.PaymentState_PAYMENT_STATE_UNDER_REVIEW:
+		return "UNDER_REVIEW"
 	default:
 		return "UNSPECIFIED"
 	}
@@ -96,65 +176,67 @@ func mapStateToString(s paymentpb.PaymentState) string {
 // HTTP server
 
 type Server struct {
-	cfg   Config
-	tpl   *template.Template
+	pages *web.Pages
 	pgCli *PaymentClient
 }
 
-func NewServer(cfg Config) (*Server, error) {
-	tpl, err := parseTemplates()
+// New usa PAYMENT_GRPC_ADDR para el microservicio payment.
+func New(web.Deps) (*Server, error) {
+	pages, err := web.ParsePages("payment", templatesFS, "templates/*.html", funcs)
 	if err != nil {
 		return nil, err
 	}
 	return &Server{
-		cfg:   cfg,
-		tpl:   tpl,
-		pgCli: NewPaymentClient(cfg.PaymentGRPCAddr),
+		pages: pages,
+		pgCli: NewPaymentClient(web.Getenv("PAYMENT_GRPC_ADDR", "payment:50053")),
 	}, nil
 }
 
-func (s *Server) routes() http.Handler {
-	mux := http.NewServeMux()
-
-	// estáticos en /static/... y compatibilidad con /payment/static/... (para subdominio y prefijo)
-	fs := http.FileServer(http.Dir("static"))
-	mux.Handle("/static/", http.StripPrefix("/static/", fs))
-	mux.Handle("/payment/static/", http.StripPrefix("/payment/static/", fs))
+func (s *Server) Name() string { return "payment" }
 
-	mux.HandleFunc("/", s.handleHome)
-	// Soporta ambos paths: con prefijo (cuando se accede vía mybookstore.local/payment/...) y sin prefijo (cuando se usa subdominio)
-	mux.HandleFunc("/payment/status", s.handlePaymentStatus)
-	mux.HandleFunc("/status", s.handlePaymentStatus)
-
-	return s.logRequests(mux)
+func (s *Server) Routes(mux *http.ServeMux) {
+	mux.Handle("/static/", web.Static(staticFS))
+	mux.HandleFunc("/", webform.Methods(s.handleHome, http.MethodGet))
+	mux.HandleFunc("/status", webform.Methods(s.handlePaymentStatus, http.MethodGet))
+	mux.HandleFunc("/methods", webform.Methods(s.handleMethods, http.MethodGet, http.MethodPost))
+	mux.HandleFunc("/methods/delete", webform.Methods(s.handleDeleteMethod, http.MethodPost))
+	mux.HandleFunc("/wallet", webform.Methods(s.handleWallet, http.MethodGet, http.MethodPost))
 }
 
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
-	// Redirigimos al path con prefijo para que el Ingress pueda enrutar correctamente bajo mybookstore.local/payment
 	http.Redirect(w, r, "/payment/status", http.StatusFound)
 }
 
 func (s *Server) handlePaymentStatus(w http.ResponseWriter, r *http.Request) {
+	type txView struct {
+		Kind        string
+		AmountCents int64
+		ProviderRef string
+		Reason      string
+		CreatedUnix int64
+	}
 	type viewData struct {
 		Title       string
 		QueryOrder  string
 		HasResult   bool
 		OrderID     int64
 		StateStr    string
+		Method      string
+		WalletCents int64
 		ProviderRef string
+		RedirectURL string
 		UpdatedUnix int64
+		Txs         []txView
 		ErrorMsg    string
-		LoggedIn    bool
-		UserName    string
 	}
-	data := viewData{Title: "Payment Status", LoggedIn: cookieUID(r) != 0, UserName: cookieUName(r)}
+	data := viewData{Title: "Payment Status"}
 
 	if q := r.URL.Query().Get("order_id"); q != "" {
 		data.QueryOrder = q
 		var orderID int64
 		if _, err := fmt.Sscan(q, &orderID); err != nil || orderID <= 0 {
 			data.ErrorMsg = "order_id inválido (debe ser entero > 0)"
-			s.render(w, "status.html", data)
+			s.pages.Render(w, r, "status.html", data)
 			return
 		}
 		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
@@ -166,55 +248,213 @@ func (s *Server) handlePaymentStatus(w http.ResponseWriter, r *http.Request) {
 			data.HasResult = true
 			data.OrderID = resp.GetOrderId()
 			data.StateStr = mapStateToString(resp.GetState())
+			data.Method = methodLabel(resp.GetMethod(), resp.GetInstrument())
+			data.WalletCents = resp.GetWalletAmount().GetCents()
 			data.ProviderRef = resp.GetProviderRef()
+			data.RedirectURL = resp.GetRedirectUrl()
 			data.UpdatedUnix = resp.GetUpdatedUnix()
+
+			txs, err := s.pgCli.ListTransactions(ctx, orderID)
+			if err != nil {
+				slog.WarnContext(ctx, "ListTransactions falló", "order_id", orderID, "err", err)
+			}
+			for _, t := range txs {
+				kind := mapKindToString(t.GetKind())
+				if t.GetStoreCredit() {
+					kind += " (saldo a favor)"
+				}
+				data.Txs = append(data.Txs, txView{
+					Kind:        kind,
+					AmountCents: t.GetAmount().GetCents(),
+					ProviderRef: t.GetProviderRef(),
+					Reason:      t.GetReason(),
+					CreatedUnix: t.GetCreatedUnix(),
+				})
+			}
 		}
 	}
 
-	s.render(w, "status.html", data)
+	s.pages.Render(w, r, "status.html", data)
 }
 
-func (s *Server) render(w http.ResponseWriter, name string, data any) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.tpl.ExecuteTemplate(w, name, data); err != nil {
-		log.Printf("render error: %v", err)
-		http.Error(w, "template error", http.StatusInternalServerError)
+// methodLabel describe el medio de pago para mostrarlo.
+func methodLabel(m commonpb.PaymentMethod, in *paymentpb.Instrument) string {
+	switch m {
+	case commonpb.PaymentMethod_PAYMENT_METHOD_PSE:
+		return "PSE"
+	case commonpb.PaymentMethod_PAYMENT_METHOD_CARD:
+		if in != nil {
+			return fmt.Sprintf("Tarjeta %s •••• %s", in.GetBrand(), in.GetLast4())
+		}
+		return "Tarjeta"
+	case commonpb.PaymentMethod_PAYMENT_METHOD_CASH_ON_DELIVERY:
+		return "Contra entrega"
+	case commonpb.PaymentMethod_PAYMENT_METHOD_STORE_CREDIT:
+		return "Saldo a favor"
+	default:
+		return "-"
 	}
 }
 
-func (s *Server) logRequests(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s (%s) from %s", r.Method, r.URL.Path, time.Since(start), r.RemoteAddr)
-	})
+// handleMethods lista y agrega las tarjetas guardadas del usuario logueado.
+func (s *Server) handleMethods(w http.ResponseWriter, r *http.Request) {
+	uid := session.UserID(r)
+	if uid == 0 {
+		http.Redirect(w, r, web.LoginURL("/payment/methods"), http.StatusSeeOther)
+		return
+	}
+	from := web.SafeFrom(r.FormValue("from"))
+	data := map[string]any{"From": from}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if r.Method == http.MethodPost {
+		err := s.saveCard(ctx, uid, r)
+		if err == nil && from != "" {
+			http.Redirect(w, r, from, http.StatusSeeOther)
+			return
+		}
+		if err != nil {
+			data["Error"] = "No se pudo guardar la tarjeta: " + err.Error()
+		}
+	}
+	cards, err := s.pgCli.ListInstruments(ctx, uid)
+	if err != nil {
+		data["Error"] = "No se pudieron obtener las tarjetas: " + err.Error()
+	}
+	data["Cards"] = cards
+	s.pages.Render(w, r, "methods.html", data)
 }
 
-func main() {
-	cfg := loadConfig()
-	srv, err := NewServer(cfg)
+func (s *Server) saveCard(ctx context.Context, uid int64, r *http.Request) error {
+	f := webform.Parse(r)
+	thisYear := int64(time.Now().Year())
+	number := f.Text("number", "Número", 13, 23)
+	month := f.Int("exp_month", "Mes", 1, 12)
+	year := f.Int("exp_year", "Año", thisYear, thisYear+20)
+	label := f.Text("label", "Etiqueta", 0, 40)
+	if !f.Valid() {
+		return errors.New(f.Message())
+	}
+	token, brand, last4, err := tokenizeCard(number)
 	if err != nil {
-		log.Fatalf("init: %v", err)
+		return err
+	}
+	return s.pgCli.SaveInstrument(ctx, &paymentpb.SaveInstrumentRequest{
+		UserId:   uid,
+		Token:    token,
+		Brand:    brand,
+		Last4:    last4,
+		ExpMonth: int32(month),
+		ExpYear:  int32(year),
+		Label:    label,
+	})
+}
+
+func (s *Server) handleDeleteMethod(w http.ResponseWriter, r *http.Request) {
+	uid := session.UserID(r)
+	f := webform.Parse(r)
+	id := f.ID("instrument_id", "Tarjeta")
+	if uid == 0 || !f.Valid() {
+		http.Redirect(w, r, "/payment/methods", http.StatusSeeOther)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+	if err := s.pgCli.DeleteInstrument(ctx, uid, id); err != nil {
+		slog.WarnContext(ctx, "DeleteInstrument falló", "user_id", uid, "instrument_id", id, "err", err)
+	}
+	http.Redirect(w, r, "/payment/methods", http.StatusSeeOther)
+}
+
+// tokenizeCard simula el tokenizador de la pasarela: el número se descarta
+// aquí y a Payment solo llegan el token, la marca y los últimos 4 dígitos.
+func tokenizeCard(number string) (token, brand, last4 string, err error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, number)
+	if len(digits) < 13 || len(digits) > 19 || strings.Trim(digits, "0123456789") != "" || !luhn(digits) {
+		return "", "", "", fmt.Errorf("número de tarjeta inválido")
+	}
+	switch {
+	case digits[0] == '4':
+		brand = "visa"
+	case digits[:2] >= "51" && digits[:2] <= "55", digits[:4] >= "2221" && digits[:4] <= "2720":
+		brand = "mastercard"
+	case digits[:2] == "34" || digits[:2] == "37":
+		brand = "amex"
+	case digits[:2] == "36" || digits[:2] == "38" || digits[:2] == "30":
+		brand = "diners"
+	default:
+		brand = "card"
 	}
-	addr := ":" + cfg.Port
-	log.Printf("[payment-frontend] http=%s payment_grpc=%s", addr, cfg.PaymentGRPCAddr)
-	if err := http.ListenAndServe(addr, srv.routes()); err != nil {
-		log.Fatal(err)
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
 	}
+	return "tok_" + hex.EncodeToString(b), brand, digits[len(digits)-4:], nil
 }
 
-// --- login helpers ---
-func cookieUID(r *http.Request) int64 {
-	if c, err := r.Cookie("uid"); err == nil {
-		if id, err2 := strconv.ParseInt(c.Value, 10, 64); err2 == nil {
-			return id
+func luhn(digits string) bool {
+	sum := 0
+	for i := range digits {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// handleWallet muestra el saldo a favor y sus movimientos; POST redime una tarjeta de regalo.
+func (s *Server) handleWallet(w http.ResponseWriter, r *http.Request) {
+	uid := session.UserID(r)
+	if uid == 0 {
+		http.Redirect(w, r, web.LoginURL("/payment/wallet"), http.StatusSeeOther)
+		return
+	}
+	data := map[string]any{"Balance": int64(0)}
+	ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+	defer cancel()
+
+	if r.Method == http.MethodPost {
+		f := webform.Parse(r)
+		code := f.Text("code", "Código", 1, 40)
+		if !f.Valid() {
+			data["Error"] = f.Message()
+		} else if res, err := s.pgCli.RedeemGiftCard(ctx, uid, code); err != nil {
+			data["Error"] = "No se pudo redimir la tarjeta: " + status.Convert(err).Message()
+		} else {
+			data["Info"] = fmt.Sprintf("Se agregaron %s a tu saldo", web.COP(res.GetTransaction().GetAmount().GetCents()))
 		}
 	}
-	return 0
+	resp, err := s.pgCli.Wallet(ctx, uid)
+	if err != nil {
+		data["Error"] = "No se pudo obtener el saldo: " + status.Convert(err).Message()
+	} else {
+		data["Balance"] = resp.GetBalance().GetCents()
+		data["Txs"] = resp.GetTransactions()
+	}
+	s.pages.Render(w, r, "wallet.html", data)
 }
-func cookieUName(r *http.Request) string {
-	if c, err := r.Cookie("uname"); err == nil {
-		return c.Value
+
+func walletKindLabel(k paymentpb.WalletTransactionKind) string {
+	switch k {
+	case paymentpb.WalletTransactionKind_WALLET_TRANSACTION_KIND_GIFT_CARD:
+		return "Tarjeta de regalo"
+	case paymentpb.WalletTransactionKind_WALLET_TRANSACTION_KIND_REFUND:
+		return "Reembolso"
+	case paymentpb.WalletTransactionKind_WALLET_TRANSACTION_KIND_PAYMENT:
+		return "Pago"
+	case paymentpb.WalletTransactionKind_WALLET_TRANSACTION_KIND_RELEASE:
+		return "Pago no realizado"
+	default:
+		return "-"
 	}
-	return ""
 }
@@ -0,0 +1,118 @@
+// Storefront es el único binario HTTP de la tienda: monta catálogo, carrito,
+// órdenes, pagos, inventario y usuario como módulos de un mismo gateway, con
+// layout, sesión y páginas de error compartidos. El módulo api sirve la API
+// REST/JSON pública (/api/v1/...) con la misma sesión.
+//
+// Las URLs de siempre siguen funcionando (/cart/add, /user/login?from=...) y
+// también los subdominios cart.<dominio>, user.<dominio>, etc. Para desarrollo
+// local basta un proceso: go run ./Frontend/src/storefront.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/Frontend/src/api"
+	"github.com/ahinestrog/mybookstore/Frontend/src/cart"
+	"github.com/ahinestrog/mybookstore/Frontend/src/catalog"
+	"github.com/ahinestrog/mybookstore/Frontend/src/inventory"
+	"github.com/ahinestrog/mybookstore/Frontend/src/order"
+	"github.com/ahinestrog/mybookstore/Frontend/src/payment"
+	"github.com/ahinestrog/mybookstore/Frontend/src/user"
+	"github.com/ahinestrog/mybookstore/Frontend/src/web"
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+	"github.com/ahinestrog/mybookstore/pkg/mtls"
+	"github.com/ahinestrog/mybookstore/pkg/session"
+	"github.com/ahinestrog/mybookstore/pkg/tracing"
+)
+
+// modules son las áreas disponibles, en el orden en que se montan.
+var modules = []struct {
+	name string
+	new  func(web.Deps) (web.Module, error)
+}{
+	{"catalog", func(d web.Deps) (web.Module, error) { return catalog.New(d) }},
+	{"cart", func(d web.Deps) (web.Module, error) { return cart.New(d) }},
+	{"order", func(d web.Deps) (web.Module, error) { return order.New(d) }},
+	{"payment", func(d web.Deps) (web.Module, error) { return payment.New(d) }},
+	{"inventory", func(d web.Deps) (web.Module, error) { return inventory.New(d) }},
+	{"user", func(d web.Deps) (web.Module, error) { return user.New(d) }},
+	{"api", func(d web.Deps) (web.Module, error) { return api.New(d) }},
+}
+
+func main() {
+	addr := web.Getenv("STOREFRONT_ADDR", ":8080")
+	userAddr := web.Getenv("USER_GRPC_ADDR", "user:50055")
+
+	// Logs JSON (STOREFRONT_LOG_LEVEL); cada petición abre un correlation_id que
+	// siguen las llamadas gRPC y los eventos que dispare, y una traza
+	// (OTEL_TRACES_EXPORTER) con un span por llamada
+	logging.Setup("storefront")
+	flushTraces, err := tracing.Setup("storefront")
+	if err != nil {
+		logging.Fatal("tracing", err)
+	}
+	defer flushTraces(context.Background())
+	// Métricas de las peticiones y de las llamadas gRPC en STOREFRONT_METRICS_ADDR/metrics
+	if err := metrics.Setup("storefront"); err != nil {
+		logging.Fatal("metrics", err)
+	}
+
+	// mTLS (GRPC_TLS_MODE) y tokens de gRPC (pkg/auth): cada llamada lleva al
+	// usuario de la sesión
+	if _, err := mtls.Setup("storefront", nil); err != nil {
+		logging.Fatal("mtls", err)
+	}
+	if _, err := auth.SetupClients(nil); err != nil {
+		logging.Fatal("auth", err)
+	}
+	defer grpcclient.CloseAll()
+
+	// Sesiones firmadas (pkg/session), verificadas contra el servicio User
+	sessions, err := session.NewFromEnv(userAddr)
+	if err != nil {
+		logging.Fatal("sesiones", err)
+	}
+	deps := web.Deps{Sessions: sessions}
+
+	// STOREFRONT_MODULES (p. ej. "catalog,cart") limita las áreas montadas
+	enabled := map[string]bool{}
+	for _, name := range strings.Split(web.Getenv("STOREFRONT_MODULES", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			enabled[name] = true
+		}
+	}
+	var mods []web.Module
+	var names []string
+	for _, m := range modules {
+		if len(enabled) > 0 && !enabled[m.name] {
+			continue
+		}
+		mod, err := m.new(deps)
+		if err != nil {
+			logging.Fatal("módulo "+m.name, err)
+		}
+		mods = append(mods, mod)
+		names = append(names, m.name)
+	}
+	if len(mods) == 0 {
+		logging.Fatal("STOREFRONT_MODULES no coincide con ningún módulo", nil)
+	}
+
+	home := "/" + names[0] + "/"
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           tracing.Middleware(metrics.Middleware(logging.Middleware(web.ErrorPages(sessions.Middleware(web.NewGateway(home, mods...)))))),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	slog.Info("storefront escuchando", "addr", addr, "modules", strings.Join(names, ","))
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logging.Fatal("http", err)
+	}
+}
@@ -1,183 +1,204 @@
-package main
+// Package user es el módulo de cuentas: registro, login, perfil y libreta de
+// direcciones.
+package user
 
 import (
-	"html/template"
-	"log"
+	"embed"
+	"log/slog"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strconv"
+	"strings"
 
+	"github.com/ahinestrog/mybookstore/Frontend/src/web"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	"github.com/ahinestrog/mybookstore/pkg/session"
+	"github.com/ahinestrog/mybookstore/pkg/webform"
 	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
 	userpb "github.com/ahinestrog/mybookstore/proto/gen/user"
-	"google.golang.org/grpc"
 )
 
-var (
-	tpl *template.Template
-)
-
-func main() {
-	// Parse templates with a container path fallback to local dev path
-	if t, err := template.ParseGlob("/srv/templates/*.html"); err == nil {
-		tpl = t
-	} else {
-		// local dev fallback
-		if tt, err2 := template.ParseGlob(filepath.FromSlash("./templates/*.html")); err2 == nil {
-			tpl = tt
-		} else {
-			log.Fatalf("template parse failed: %v / %v", err, err2)
-		}
-	}
+//go:embed templates/*.html
+var templatesFS embed.FS
 
-	// allow overriding when running locally: prefer localhost by default
-	grpcAddr := env("USER_GRPC_ADDR", "localhost:50055")
+type Server struct {
+	pages    *web.Pages
+	client   userpb.UserClient
+	sessions *session.Manager
+}
 
-	conn, err := grpc.Dial(grpcAddr, grpc.WithInsecure())
+// New conecta con el servicio User; login y logout usan las sesiones del
+// storefront (deps.Sessions).
+func New(deps web.Deps) (*Server, error) {
+	conn, err := grpcclient.Conn(web.Getenv("USER_GRPC_ADDR", "localhost:50055"))
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
-	defer conn.Close()
-	client := userpb.NewUserClient(conn)
+	return &Server{
+		pages:    web.MustParsePages("user", templatesFS, "templates/*.html", nil),
+		client:   userpb.NewUserClient(conn),
+		sessions: deps.Sessions,
+	}, nil
+}
 
-	// Serve static assets from container path or fallback to local path in dev
-	if _, err := os.Stat("/srv/static"); err == nil {
-		http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("/srv/static"))))
-	} else {
-		http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("./static"))))
-	}
+func (s *Server) Name() string { return "user" }
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		data := map[string]any{
-			"LoggedIn": cookieUID(r) != 0,
-			"UserName": cookieUName(r),
-		}
-		_ = tpl.ExecuteTemplate(w, "home.html", data)
-	})
+func (s *Server) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("/", webform.Methods(func(w http.ResponseWriter, r *http.Request) {
+		s.pages.Render(w, r, "home.html", nil)
+	}, http.MethodGet))
 
-	// Support trailing slash paths from ingress rewrites
-	http.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/register", webform.Methods(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			_ = tpl.ExecuteTemplate(w, "register.html", nil)
+			s.pages.Render(w, r, "register.html", map[string]any{})
 		case http.MethodPost:
-			name := r.FormValue("name")
-			email := r.FormValue("email")
-			pass := r.FormValue("password")
-			resp, err := client.Register(r.Context(), &userpb.RegisterRequest{
+			f := webform.Parse(r)
+			name := f.Text("name", "Nombre", 1, 80)
+			email := f.Email("email", "Email")
+			pass := f.Password("password", "Contraseña", 8, 72)
+			// El formulario se devuelve con lo escrito (menos la contraseña)
+			data := map[string]any{"Name": name, "Email": email}
+			if !f.Valid() {
+				data["Error"] = f.Message()
+				s.pages.RenderStatus(w, r, http.StatusUnprocessableEntity, "register.html", data)
+				return
+			}
+			resp, err := s.client.Register(r.Context(), &userpb.RegisterRequest{
 				Name:     name,
 				Email:    email,
 				Password: pass,
 			})
 			if err != nil {
-				_ = tpl.ExecuteTemplate(w, "register.html", map[string]any{"Error": err.Error()})
+				data["Error"] = err.Error()
+				s.pages.Render(w, r, "register.html", data)
+				return
+			}
+			// Sesión firmada (path=/ para todo el storefront)
+			if _, err := s.sessions.Login(r.Context(), w, resp.GetUserId()); err != nil {
+				slog.WarnContext(r.Context(), "login tras registro", "err", err)
+				http.Redirect(w, r, "/user/login", http.StatusSeeOther)
 				return
 			}
-			// Set simple session cookies (demo): uid and uname (path=/ so all apps can read)
-			http.SetCookie(w, &http.Cookie{Name: "uid", Value: strconv.FormatInt(resp.GetUserId(), 10), Path: "/", MaxAge: 7 * 24 * 3600, HttpOnly: false})
-			http.SetCookie(w, &http.Cookie{Name: "uname", Value: name, Path: "/", MaxAge: 7 * 24 * 3600, HttpOnly: false})
-			http.Redirect(w, r, "/user/profile?user_id="+strconv.FormatInt(resp.GetUserId(), 10), http.StatusSeeOther)
+			http.Redirect(w, r, "/user/profile", http.StatusSeeOther)
 		}
-	})
-	http.HandleFunc("/register/", func(w http.ResponseWriter, r *http.Request) {
-		r.URL.Path = "/register"
-		http.DefaultServeMux.ServeHTTP(w, r)
-	})
+	}, http.MethodGet, http.MethodPost))
 
-	http.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/login", webform.Methods(func(w http.ResponseWriter, r *http.Request) {
+		// El login vuelve a from; el formulario lo conserva en su action
+		from := web.SafeFrom(r.URL.Query().Get("from"))
 		switch r.Method {
 		case http.MethodGet:
-			_ = tpl.ExecuteTemplate(w, "login.html", nil)
+			s.pages.Render(w, r, "login.html", map[string]any{"From": from})
 		case http.MethodPost:
-			email := r.FormValue("email")
-			pass := r.FormValue("password")
-			resp, err := client.Authenticate(r.Context(), &userpb.AuthenticateRequest{
+			// Sin mínimo de contraseña: las cuentas anteriores a la regla de 8 caracteres siguen entrando
+			f := webform.Parse(r)
+			email := f.Email("email", "Email")
+			pass := f.Password("password", "Contraseña", 1, 72)
+			data := map[string]any{"Email": email, "From": from}
+			if !f.Valid() {
+				data["Error"] = f.Message()
+				s.pages.RenderStatus(w, r, http.StatusUnprocessableEntity, "login.html", data)
+				return
+			}
+			resp, err := s.client.Authenticate(r.Context(), &userpb.AuthenticateRequest{
 				Email:    email,
 				Password: pass,
 			})
 			if err != nil || !resp.GetOk() {
-				_ = tpl.ExecuteTemplate(w, "login.html", map[string]any{"Error": "Credenciales inválidas"})
+				data["Error"] = "Credenciales inválidas"
+				s.pages.Render(w, r, "login.html", data)
 				return
 			}
-			// Fetch profile to get display name
-			prof, perr := client.GetProfile(r.Context(), &commonpb.UserRef{UserId: resp.GetUserId()})
-			if perr != nil {
-				prof = &userpb.UserProfile{}
+			if _, err := s.sessions.Login(r.Context(), w, resp.GetUserId()); err != nil {
+				slog.WarnContext(r.Context(), "login", "err", err)
+				data["Error"] = "No se pudo iniciar sesión, intenta de nuevo"
+				s.pages.Render(w, r, "login.html", data)
+				return
 			}
-			// Set cookies for session
-			http.SetCookie(w, &http.Cookie{Name: "uid", Value: strconv.FormatInt(resp.GetUserId(), 10), Path: "/", MaxAge: 7 * 24 * 3600, HttpOnly: false})
-			http.SetCookie(w, &http.Cookie{Name: "uname", Value: prof.GetName(), Path: "/", MaxAge: 7 * 24 * 3600, HttpOnly: false})
 			// Support redirect back if provided
-			next := r.URL.Query().Get("from")
+			next := from
 			if next == "" {
-				next = "/user/profile?user_id=" + strconv.FormatInt(resp.GetUserId(), 10)
+				next = "/user/profile"
 			}
 			http.Redirect(w, r, next, http.StatusSeeOther)
 		}
-	})
-	http.HandleFunc("/login/", func(w http.ResponseWriter, r *http.Request) {
-		r.URL.Path = "/login"
-		http.DefaultServeMux.ServeHTTP(w, r)
-	})
+	}, http.MethodGet, http.MethodPost))
 
-	http.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
-		idStr := r.URL.Query().Get("user_id")
-		id, _ := strconv.ParseInt(idStr, 10, 64)
-		prof, err := client.GetProfile(r.Context(), &commonpb.UserRef{UserId: id})
+	mux.HandleFunc("/profile", webform.Methods(func(w http.ResponseWriter, r *http.Request) {
+		id := session.UserID(r)
+		if id == 0 {
+			http.Redirect(w, r, web.LoginURL("/user/profile"), http.StatusSeeOther)
+			return
+		}
+		prof, err := s.client.GetProfile(r.Context(), &commonpb.UserRef{UserId: id})
 		if err != nil {
 			http.Error(w, "no se pudo obtener perfil: "+err.Error(), 500)
 			return
 		}
-		_ = tpl.ExecuteTemplate(w, "profile.html", prof)
-	})
-	http.HandleFunc("/profile/", func(w http.ResponseWriter, r *http.Request) {
-		r.URL.Path = "/profile"
-		http.DefaultServeMux.ServeHTTP(w, r)
-	})
+		s.pages.Render(w, r, "profile.html", prof)
+	}, http.MethodGet))
 
-	// Simple logout: clear cookies and redirect to user home
-	http.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
-		http.SetCookie(w, &http.Cookie{Name: "uid", Value: "", Path: "/", MaxAge: -1})
-		http.SetCookie(w, &http.Cookie{Name: "uname", Value: "", Path: "/", MaxAge: -1})
-		http.Redirect(w, r, "/user/", http.StatusSeeOther)
-	})
-	http.HandleFunc("/logout/", func(w http.ResponseWriter, r *http.Request) {
-		r.URL.Path = "/logout"
-		http.DefaultServeMux.ServeHTTP(w, r)
-	})
-
-	// HTTP_ADDR is expected in the form ":8080"; for convenience also allow PORT
-	addr := env("HTTP_ADDR", "")
-	if addr == "" {
-		if p := os.Getenv("PORT"); p != "" {
-			addr = ":" + p
-		} else {
-			addr = ":8080"
+	// Libreta de direcciones del usuario logueado (se usan en el checkout del carrito)
+	mux.HandleFunc("/addresses", webform.Methods(func(w http.ResponseWriter, r *http.Request) {
+		uid := session.UserID(r)
+		if uid == 0 {
+			http.Redirect(w, r, web.LoginURL("/user/addresses"), http.StatusSeeOther)
+			return
 		}
-	}
-	log.Printf("[frontend-user] listening on %s (gRPC at %s)", addr, grpcAddr)
-	log.Fatal(http.ListenAndServe(addr, nil))
-}
-
-func env(k, def string) string {
-	if v := os.Getenv(k); v != "" {
-		return v
-	}
-	return def
-}
+		from := web.SafeFrom(r.FormValue("from"))
+		data := map[string]any{"From": from}
+		if r.Method == http.MethodPost {
+			f := webform.Parse(r)
+			req := &userpb.AddAddressRequest{
+				UserId: uid,
+				Label:  f.Text("label", "Etiqueta", 0, 40),
+				Address: &commonpb.Address{
+					Recipient:  f.Text("recipient", "Destinatario", 1, 120),
+					Line1:      f.Text("line1", "Dirección", 1, 200),
+					Line2:      f.Text("line2", "Complemento", 0, 200),
+					City:       f.Text("city", "Ciudad", 1, 80),
+					Region:     f.Text("region", "Departamento", 0, 80),
+					PostalCode: f.Text("postal_code", "Código postal", 0, 12),
+					Country:    strings.ToUpper(f.Text("country", "País", 2, 2)),
+					Phone:      f.Text("phone", "Teléfono", 0, 20),
+				},
+				MakeDefault: f.Bool("make_default"),
+			}
+			if !f.Valid() {
+				data["Error"] = f.Message()
+			} else if _, err := s.client.AddAddress(r.Context(), req); err != nil {
+				data["Error"] = "No se pudo guardar la dirección: " + err.Error()
+			} else if from != "" {
+				http.Redirect(w, r, from, http.StatusSeeOther)
+				return
+			}
+		}
+		resp, err := s.client.ListAddresses(r.Context(), &commonpb.UserRef{UserId: uid})
+		if err != nil {
+			http.Error(w, "no se pudieron obtener direcciones: "+err.Error(), 500)
+			return
+		}
+		data["Addresses"] = resp.GetAddresses()
+		s.pages.Render(w, r, "addresses.html", data)
+	}, http.MethodGet, http.MethodPost))
+	mux.HandleFunc("/addresses/delete", webform.Methods(func(w http.ResponseWriter, r *http.Request) {
+		uid := session.UserID(r)
+		f := webform.Parse(r)
+		id := f.ID("address_id", "Dirección")
+		if uid == 0 || !f.Valid() {
+			http.Redirect(w, r, "/user/addresses", http.StatusSeeOther)
+			return
+		}
+		if _, err := s.client.DeleteAddress(r.Context(), &userpb.AddressRef{UserId: uid, AddressId: id}); err != nil {
+			slog.WarnContext(r.Context(), "DeleteAddress", "err", err)
+		}
+		http.Redirect(w, r, "/user/addresses", http.StatusSeeOther)
+	}, http.MethodPost))
 
-// --- login helpers ---
-func cookieUID(r *http.Request) int64 {
-	if c, err := r.Cookie("uid"); err == nil {
-		if id, err2 := strconv.ParseInt(c.Value, 10, 64); err2 == nil {
-			return id
+	// Logout: revoca la sesión en el servicio User
+	mux.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		if err := s.sessions.Logout(w, r); err != nil {
+			slog.WarnContext(r.Context(), "logout", "err", err)
 		}
-	}
-	return 0
-}
-func cookieUName(r *http.Request) string {
-	if c, err := r.Cookie("uname"); err == nil {
-		return c.Value
-	}
-	return ""
+		http.Redirect(w, r, "/user/", http.StatusSeeOther)
+	})
 }
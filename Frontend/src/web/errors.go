@@ -0,0 +1,113 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+)
+
+// errorPages renderiza error.html dentro del layout; el módulo "" deja sin
+// marcar la navegación.
+var errorPages = MustParsePages("", layoutFS, "templates/error.html", nil)
+
+// maxErrorBody acota el mensaje de texto que se copia a la página de error.
+const maxErrorBody = 2048
+
+type requestSlot struct{ r *http.Request }
+
+type requestSlotKey struct{}
+
+// noteRequest guarda la petición que llega al gateway (ya con la sesión en el
+// contexto) para que la página de error muestre al usuario en el layout.
+func noteRequest(r *http.Request) {
+	if s, ok := r.Context().Value(requestSlotKey{}).(*requestSlot); ok {
+		s.r = r
+	}
+}
+
+// ErrorPages convierte las respuestas de error en texto plano (http.Error, de
+// los handlers, de webform o del CSRF de pkg/session) en la página de error
+// común. Las respuestas JSON o HTML pasan sin cambios.
+func ErrorPages(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slot := &requestSlot{r: r}
+		ew := &errorWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r.WithContext(context.WithValue(r.Context(), requestSlotKey{}, slot)))
+		if ew.code == 0 {
+			return
+		}
+		h := w.Header()
+		h.Del("Content-Length")
+		h.Del("X-Content-Type-Options")
+		errorPages.RenderStatus(w, slot.r, ew.code, "error.html", errorData{
+			Title:   statusTitle(ew.code),
+			Message: strings.TrimSpace(ew.body.String()),
+		})
+	})
+}
+
+type errorData struct {
+	Title   string
+	Message string
+}
+
+func statusTitle(code int) string {
+	switch code {
+	case http.StatusNotFound:
+		return "Página no encontrada"
+	case http.StatusForbidden:
+		return "Acceso denegado"
+	case http.StatusMethodNotAllowed:
+		return "Método no permitido"
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return "Solicitud inválida"
+	}
+	if code >= 500 {
+		return "Algo salió mal"
+	}
+	return http.StatusText(code)
+}
+
+// errorWriter retiene el cuerpo de un error en texto plano en vez de enviarlo.
+type errorWriter struct {
+	http.ResponseWriter
+	code int // != 0: error interceptado
+	body bytes.Buffer
+	sent bool
+}
+
+func (e *errorWriter) WriteHeader(code int) {
+	if e.sent || e.code != 0 {
+		return
+	}
+	if code >= 400 && strings.HasPrefix(e.Header().Get("Content-Type"), "text/plain") {
+		e.code = code
+		return
+	}
+	e.sent = true
+	e.ResponseWriter.WriteHeader(code)
+}
+
+func (e *errorWriter) Write(b []byte) (int, error) {
+	if !e.sent && e.code == 0 {
+		e.WriteHeader(http.StatusOK)
+	}
+	if e.code != 0 {
+		if room := maxErrorBody - e.body.Len(); room > 0 {
+			e.body.Write(b[:min(len(b), room)])
+		}
+		return len(b), nil
+	}
+	return e.ResponseWriter.Write(b)
+}
+
+// Flush atraviesa los demás envoltorios (p. ej. el de Logging) hasta el
+// http.ResponseWriter del servidor; las respuestas SSE dependen de él.
+func (e *errorWriter) Flush() {
+	if e.code == 0 {
+		_ = http.NewResponseController(e.ResponseWriter).Flush()
+	}
+}
+
+func (e *errorWriter) Unwrap() http.ResponseWriter { return e.ResponseWriter }
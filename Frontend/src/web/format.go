@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// COP formatea centavos como pesos enteros con separador de miles: "$ 12.345".
+func COP(cents int64) string {
+	pesos := cents / 100
+	sign := ""
+	if pesos < 0 {
+		sign, pesos = "-", -pesos
+	}
+	s := strconv.FormatInt(pesos, 10)
+	out := make([]byte, 0, len(s)+len(s)/3)
+	for i := range s {
+		if i != 0 && (len(s)-i)%3 == 0 {
+			out = append(out, '.')
+		}
+		out = append(out, s[i])
+	}
+	return "$ " + sign + string(out)
+}
+
+// LoginURL es la página de login que, al entrar, vuelve a from (una ruta local).
+func LoginURL(from string) string {
+	if from == "" {
+		return "/user/login"
+	}
+	return "/user/login?from=" + url.QueryEscape(from)
+}
+
+// SafeFrom solo acepta rutas locales para evitar redirecciones abiertas.
+func SafeFrom(from string) string {
+	if len(from) > 0 && from[0] == '/' && (len(from) == 1 || (from[1] != '/' && from[1] != '\\')) {
+		return from
+	}
+	return ""
+}
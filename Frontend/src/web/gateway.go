@@ -0,0 +1,92 @@
+package web
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+)
+
+// Gateway monta cada módulo bajo /<nombre>/ y atiende también los subdominios
+// <nombre>.<dominio>, que antes apuntaban a un frontend propio. Reemplaza el
+// rewrite-target del ingress: los módulos ven las rutas sin el prefijo.
+type Gateway struct {
+	mux     *http.ServeMux
+	modules map[string]http.Handler
+}
+
+// NewGateway arma el router; "/" redirige a home (p. ej. "/catalog/").
+func NewGateway(home string, modules ...Module) *Gateway {
+	g := &Gateway{mux: http.NewServeMux(), modules: map[string]http.Handler{}}
+
+	static, _ := fs.Sub(staticFS, "static")
+	g.mux.Handle("GET /assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(static))))
+	g.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	g.mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, home, http.StatusFound)
+	})
+
+	for _, m := range modules {
+		sub := http.NewServeMux()
+		m.Routes(sub)
+		prefix := "/" + m.Name()
+		h := noteRoute(prefix, sub)
+		g.modules[m.Name()] = h
+		g.mux.Handle(prefix+"/", http.StripPrefix(prefix, h))
+		// /cart → /cart/ conservando la query
+		g.mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+			u := *r.URL
+			u.Path = prefix + "/"
+			http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+		})
+	}
+	return g
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	noteRequest(r)
+	// Por subdominio (cart.mybookstore.local/add) la ruta no trae el prefijo;
+	// los assets y los enlaces a otras áreas (/user/login) siguen por el mux
+	if h, ok := g.modules[subdomain(r.Host)]; ok && !g.routed(r.URL.Path) {
+		h.ServeHTTP(w, r)
+		return
+	}
+	g.mux.ServeHTTP(w, r)
+	metrics.NoteRoute(r.Context(), metrics.PatternPath(r.Pattern))
+}
+
+// noteRoute informa a pkg/metrics la ruta del módulo que atendió la petición
+// ("/cart/add"). StripPrefix le pasa al módulo una copia de la petición, así
+// que el patrón solo se ve aquí.
+func noteRoute(prefix string, sub *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub.ServeHTTP(w, r)
+		if r.Pattern != "" {
+			metrics.NoteRoute(r.Context(), prefix+metrics.PatternPath(r.Pattern))
+		}
+	})
+}
+
+// routed indica si la ruta ya apunta a un área, a los assets o al health check.
+func (g *Gateway) routed(p string) bool {
+	if strings.HasPrefix(p, "/assets/") || p == "/healthz" {
+		return true
+	}
+	name, _, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+	_, ok := g.modules[name]
+	return ok
+}
+
+func subdomain(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	label, rest, ok := strings.Cut(host, ".")
+	if !ok || rest == "" {
+		return ""
+	}
+	return label
+}
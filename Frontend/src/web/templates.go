@@ -0,0 +1,134 @@
+package web
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/session"
+)
+
+//go:embed templates/*.html
+var layoutFS embed.FS
+
+//go:embed static
+var staticFS embed.FS
+
+// Pages son las páginas de un módulo. Cada archivo se parsea junto al layout
+// compartido y define los bloques "title", "content" y, si hace falta, "head".
+//
+// Además de las funciones propias del módulo, las plantillas disponen de:
+//
+//	{{csrfField}}           input oculto con el token CSRF de la sesión
+//	{{loggedIn}} {{userName}} usuario de la sesión
+//	{{cop .Cents}}          dinero en pesos ("$ 12.345")
+//	{{loginURL "/cart/"}}   login que vuelve a la ruta dada
+//	{{module}} {{query}} {{year}}
+type Pages struct {
+	module string
+	byName map[string]*template.Template
+}
+
+// ParsePages parsea los archivos de fsys que coinciden con pattern.
+func ParsePages(module string, fsys fs.FS, pattern string, funcs template.FuncMap) (*Pages, error) {
+	base, err := template.New("layout.html").
+		Funcs(baseFuncs(module, nil)).
+		Funcs(funcs).
+		ParseFS(layoutFS, "templates/layout.html")
+	if err != nil {
+		return nil, err
+	}
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	p := &Pages{module: module, byName: map[string]*template.Template{}}
+	for _, f := range files {
+		t, err := template.Must(base.Clone()).ParseFS(fsys, f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		p.byName[path.Base(f)] = t
+	}
+	return p, nil
+}
+
+// MustParsePages es ParsePages para las plantillas embebidas de cada módulo.
+func MustParsePages(module string, fsys fs.FS, pattern string, funcs template.FuncMap) *Pages {
+	p, err := ParsePages(module, fsys, pattern, funcs)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// baseFuncs son las funciones comunes; con r nil solo sirven para parsear y
+// Render las ata a cada petición.
+func baseFuncs(module string, r *http.Request) template.FuncMap {
+	funcs := template.FuncMap{
+		"module":   func() string { return module },
+		"year":     func() int { return time.Now().Year() },
+		"cop":      COP,
+		"loginURL": LoginURL,
+		"loggedIn": func() bool { return r != nil && session.UserID(r) != 0 },
+		"userName": func() string {
+			if r == nil {
+				return ""
+			}
+			return session.UserName(r)
+		},
+		"query": func() string {
+			if r == nil {
+				return ""
+			}
+			return r.URL.Query().Get("q")
+		},
+	}
+	for k, v := range session.TemplateFuncs(r) {
+		funcs[k] = v
+	}
+	return funcs
+}
+
+// Render responde 200 con la página name.
+func (p *Pages) Render(w http.ResponseWriter, r *http.Request, name string, data any) {
+	p.RenderStatus(w, r, http.StatusOK, name, data)
+}
+
+// RenderStatus ejecuta la página dentro del layout. La salida se arma en memoria
+// para que un error de plantilla responda 500 y no una página a medias.
+func (p *Pages) RenderStatus(w http.ResponseWriter, r *http.Request, code int, name string, data any) {
+	t, ok := p.byName[name]
+	if !ok {
+		slog.ErrorContext(r.Context(), "plantilla no existe", "module", p.module, "template", name)
+		http.Error(w, "Error renderizando la página", http.StatusInternalServerError)
+		return
+	}
+	t, err := t.Clone()
+	if err == nil {
+		var buf bytes.Buffer
+		if err = t.Funcs(baseFuncs(p.module, r)).ExecuteTemplate(&buf, "layout.html", data); err == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(code)
+			_, _ = buf.WriteTo(w)
+			return
+		}
+	}
+	slog.ErrorContext(r.Context(), "render", "module", p.module, "template", name, "err", err)
+	http.Error(w, "Error renderizando la página", http.StatusInternalServerError)
+}
+
+// Static sirve la carpeta static de un módulo (embebida) bajo /static/.
+func Static(fsys fs.FS) http.Handler {
+	sub, err := fs.Sub(fsys, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/static/", http.FileServer(http.FS(sub)))
+}
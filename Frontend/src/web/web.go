@@ -0,0 +1,36 @@
+// Package web es la base común del storefront: el gateway que monta cada área
+// (catálogo, carrito, órdenes, pagos, inventario, usuario) como un módulo, el
+// layout y las páginas de error compartidas y los helpers de las plantillas.
+//
+// Cada módulo registra sus rutas relativas a su prefijo, igual que cuando era
+// un frontend aparte detrás del rewrite del ingress: el gateway sirve
+// /cart/add quitando /cart y también cart.<dominio>/add por subdominio.
+package web
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/ahinestrog/mybookstore/pkg/session"
+)
+
+// Module es un área del storefront.
+type Module interface {
+	// Name es el prefijo (/cart/) y el subdominio (cart.) del módulo.
+	Name() string
+	// Routes registra las rutas relativas al prefijo ("/", "/add"...).
+	Routes(mux *http.ServeMux)
+}
+
+// Deps son las dependencias compartidas que el storefront entrega a los módulos.
+type Deps struct {
+	Sessions *session.Manager
+}
+
+// Getenv devuelve la variable de entorno k o def si está vacía.
+func Getenv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ahinestrog/mybookstore/pkg/logging"
+)
+
+// echo responde con su nombre y la ruta que ve, para comprobar el prefijo.
+type echo string
+
+func (e echo) Name() string { return string(e) }
+
+func (e echo) Routes(mux *http.ServeMux) {
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(string(e) + " " + r.URL.Path))
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "sin <stock>", http.StatusBadGateway)
+	})
+}
+
+func TestGateway(t *testing.T) {
+	h := ErrorPages(NewGateway("/catalog/", echo("catalog"), echo("cart")))
+	for _, tc := range []struct {
+		host, path string
+		code       int
+		want       string // cuerpo o Location
+	}{
+		{"localhost", "/cart/add", 200, "cart /add"},
+		{"localhost", "/catalog/", 200, "catalog /"},
+		{"localhost", "/cart?msg=x", 301, "/cart/?msg=x"},
+		{"localhost", "/", 302, "/catalog/"},
+		{"cart.mybookstore.local", "/add", 200, "cart /add"},
+		{"cart.mybookstore.local:8080", "/", 200, "cart /"},
+		{"cart.mybookstore.local", "/catalog/book", 200, "catalog /book"},
+		{"mybookstore.local", "/healthz", 200, "ok"},
+		{"localhost", "/nada/", 404, "Página no encontrada"},
+	} {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		r.Host = tc.host
+		h.ServeHTTP(rec, r)
+		got := rec.Body.String()
+		if loc := rec.Header().Get("Location"); loc != "" {
+			got = loc
+		}
+		if rec.Code != tc.code || !strings.Contains(got, tc.want) {
+			t.Errorf("%s%s: %d %q, se esperaba %d %q", tc.host, tc.path, rec.Code, got, tc.code, tc.want)
+		}
+	}
+}
+
+func TestErrorPage(t *testing.T) {
+	h := ErrorPages(NewGateway("/cart/", echo("cart")))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cart/fail", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusBadGateway || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("status %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{"Algo salió mal", "sin &lt;stock&gt;", `href="/assets/style.css`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("la página de error no incluye %q", want)
+		}
+	}
+}
+
+func TestCOP(t *testing.T) {
+	for cents, want := range map[int64]string{0: "$ 0", 99: "$ 0", 1234500: "$ 12.345", -100000000: "$ -1.000.000"} {
+		if got := COP(cents); got != want {
+			t.Errorf("COP(%d) = %q, se esperaba %q", cents, got, want)
+		}
+	}
+}
+
+func TestFlushThroughWrappers(t *testing.T) {
+	h := logging.Middleware(ErrorPages(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: x\n\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("Flush: %v", err)
+		}
+	})))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/order/events", nil))
+	if !rec.Flushed {
+		t.Error("el Flush no llegó al ResponseWriter del servidor")
+	}
+}
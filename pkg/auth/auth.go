@@ -0,0 +1,199 @@
+// Package auth autentica las llamadas gRPC entre frontends y servicios.
+//
+// Cada RPC lleva en el metadata "authorization: Bearer <token>", un JWT HS256 de
+// vida corta (GRPC_AUTH_TOKEN_TTL) firmado con GRPC_AUTH_SECRET, que todos los
+// procesos comparten. El token describe al Principal: un usuario (lo emite el
+// frontend a partir de la sesión verificada por pkg/session), un servicio que
+// actúa por su cuenta (consumidores de eventos, tareas periódicas) o una
+// herramienta de operación con rol admin.
+//
+// Los interceptores de servidor (ServerOptions) validan el token y dejan el
+// Principal en el contexto; Rules define qué métodos son públicos y cuáles
+// exigen admin. La propiedad de los recursos la decide cada handler con
+// CheckUser. Los interceptores de cliente (DialOptions) reenvían el Principal
+// del contexto, así una llamada encadenada (order → cart) conserva al usuario
+// que la originó.
+//
+// El servicio User (Python) valida los mismos tokens en
+// Backend/src/user/src/auth.py: si cambia el formato, hay que cambiarlo también ahí.
+// Ambos lados se prueban con los mismos tokens de testdata/tokens.json.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+)
+
+// RoleAdmin habilita los RPCs de gestión (OrderAdmin, conciliación, revisiones...).
+const RoleAdmin = "admin"
+
+// Principal es quién hace la llamada.
+type Principal struct {
+	UserID  int64    // 0 si no actúa un usuario
+	Service string   // servicio o herramienta que llama en nombre propio
+	Roles   []string // p. ej. RoleAdmin
+}
+
+// User es el Principal de un usuario final.
+func User(id int64) *Principal { return &Principal{UserID: id} }
+
+// Service es el Principal de un servicio interno.
+func Service(name string) *Principal { return &Principal{Service: name} }
+
+func (p *Principal) HasRole(role string) bool { return slices.Contains(p.Roles, role) }
+
+func (p *Principal) IsAdmin() bool { return p.HasRole(RoleAdmin) }
+
+func (p *Principal) String() string {
+	switch {
+	case p.UserID != 0:
+		return "user:" + strconv.FormatInt(p.UserID, 10)
+	case p.Service != "":
+		return "service:" + p.Service
+	}
+	return "anonymous"
+}
+
+type ctxKey struct{}
+
+// NewContext devuelve ctx con el Principal que usarán los interceptores de cliente.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext devuelve el Principal autenticado, o nil.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(ctxKey{}).(*Principal)
+	return p
+}
+
+type Config struct {
+	Secret          string        // GRPC_AUTH_SECRET (mínimo 32 caracteres)
+	PreviousSecrets []string      // GRPC_AUTH_PREVIOUS_SECRETS: solo verifican, para rotar la clave
+	TokenTTL        time.Duration // GRPC_AUTH_TOKEN_TTL: vigencia de cada token emitido
+}
+
+const minSecretLen = 32
+
+// ConfigFromEnv lee la configuración común a frontends y servicios.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Secret: os.Getenv("GRPC_AUTH_SECRET"), TokenTTL: 5 * time.Minute}
+	for _, s := range strings.Split(os.Getenv("GRPC_AUTH_PREVIOUS_SECRETS"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			cfg.PreviousSecrets = append(cfg.PreviousSecrets, s)
+		}
+	}
+	if v := os.Getenv("GRPC_AUTH_TOKEN_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("auth: GRPC_AUTH_TOKEN_TTL=%q no es una duración válida", v)
+		}
+		cfg.TokenTTL = d
+	}
+	return cfg, cfg.validate()
+}
+
+func (c Config) validate() error {
+	for _, s := range append([]string{c.Secret}, c.PreviousSecrets...) {
+		if len(s) < minSecretLen {
+			return fmt.Errorf("auth: GRPC_AUTH_SECRET y GRPC_AUTH_PREVIOUS_SECRETS necesitan al menos %d caracteres", minSecretLen)
+		}
+	}
+	return nil
+}
+
+// Authority emite y verifica los tokens de gRPC.
+type Authority struct {
+	keys Keyring
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+func New(cfg Config) (*Authority, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &Authority{
+		keys: NewKeyring("grpc", cfg.Secret, cfg.PreviousSecrets...),
+		ttl:  cfg.TokenTTL,
+		now:  time.Now,
+	}, nil
+}
+
+func NewFromEnv() (*Authority, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg)
+}
+
+// SetupClients crea la Authority desde el entorno y registra sus interceptores
+// en todas las conexiones de grpcclient. self es el Principal del proceso cuando
+// el contexto no trae uno (nil en los frontends: sin sesión la llamada es anónima).
+// Se llama al inicio de main, antes de abrir conexiones.
+func SetupClients(self *Principal) (*Authority, error) {
+	a, err := NewFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	grpcclient.AddDialOptions(a.DialOptions(self)...)
+	return a, nil
+}
+
+// maxSkew tolera relojes desfasados entre contenedores al validar iat.
+const maxSkew = time.Minute
+
+type claims struct {
+	Sub   string   `json:"sub,omitempty"` // user_id
+	Svc   string   `json:"svc,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+	Iat   int64    `json:"iat"`
+	Exp   int64    `json:"exp"`
+}
+
+// Issue firma un token para p válido por GRPC_AUTH_TOKEN_TTL.
+func (a *Authority) Issue(p *Principal) (string, error) {
+	return a.IssueFor(p, a.ttl)
+}
+
+// IssueFor es Issue con una vigencia explícita (tokens de operación).
+func (a *Authority) IssueFor(p *Principal, ttl time.Duration) (string, error) {
+	now := a.now()
+	c := claims{Svc: p.Service, Roles: p.Roles, Iat: now.Unix(), Exp: now.Add(ttl).Unix()}
+	if p.UserID != 0 {
+		c.Sub = strconv.FormatInt(p.UserID, 10)
+	}
+	return a.keys.Sign(c)
+}
+
+// Verify valida firma y vigencia y devuelve el Principal del token.
+func (a *Authority) Verify(token string) (*Principal, error) {
+	var c claims
+	if err := a.keys.Verify(token, &c); err != nil {
+		return nil, err
+	}
+	now := a.now()
+	if now.Unix() >= c.Exp || c.Iat > now.Add(maxSkew).Unix() {
+		return nil, ErrToken
+	}
+	p := &Principal{Service: c.Svc, Roles: c.Roles}
+	if c.Sub != "" {
+		uid, err := strconv.ParseInt(c.Sub, 10, 64)
+		if err != nil || uid <= 0 {
+			return nil, ErrToken
+		}
+		p.UserID = uid
+	}
+	if p.UserID == 0 && p.Service == "" {
+		return nil, ErrToken
+	}
+	return p, nil
+}
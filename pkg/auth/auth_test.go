@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestAuthority(t *testing.T, secret string) *Authority {
+	t.Helper()
+	a, err := New(Config{Secret: secret, TokenTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// call simula el viaje cliente → servidor: el interceptor de cliente firma con
+// el Principal de ctx (o self) y el de servidor valida el metadata resultante.
+func call(t *testing.T, client, server *Authority, ctx context.Context, self *Principal, method string, rules Rules) (context.Context, error) {
+	t.Helper()
+	out, err := client.outgoing(ctx, self)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, _ := metadata.FromOutgoingContext(out)
+	return server.authorize(metadata.NewIncomingContext(context.Background(), md), method, rules)
+}
+
+func TestPrincipalTravelsAndOwnership(t *testing.T) {
+	a := newTestAuthority(t, testSecret)
+	ctx, err := call(t, a, a, NewContext(context.Background(), User(5)), Service("order"), "/cart.Cart/GetCart", Rules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// El usuario del contexto tiene prioridad sobre el Principal del proceso
+	if p := FromContext(ctx); p == nil || p.UserID != 5 || p.Service != "" {
+		t.Fatalf("principal = %v, se esperaba user:5", p)
+	}
+	if err := CheckUser(ctx, 5); err != nil {
+		t.Fatalf("el dueño fue rechazado: %v", err)
+	}
+	if err := CheckUser(ctx, 6); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("carrito ajeno = %v, se esperaba PermissionDenied", err)
+	}
+
+	// Sin usuario en el contexto (consumidor de eventos) llama el servicio
+	ctx, err = call(t, a, a, context.Background(), Service("order"), "/cart.Cart/CheckoutSnapshot", Rules{})
+	if err != nil || CheckUser(ctx, 6) != nil {
+		t.Fatalf("llamada de servicio rechazada: %v / %v", err, CheckUser(ctx, 6))
+	}
+	if err := CheckUser(context.Background(), 6); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("sin principal = %v, se esperaba Unauthenticated", err)
+	}
+}
+
+func TestRules(t *testing.T) {
+	a := newTestAuthority(t, testSecret)
+	rules := Rules{Public: []string{"/catalog.Catalog/"}, Admin: []string{"/order.OrderAdmin/"}}
+	admin := &Principal{Service: "cli", Roles: []string{RoleAdmin}}
+	expired := newTestAuthority(t, testSecret)
+	expired.now = func() time.Time { return time.Now().Add(-time.Hour) }
+
+	for _, c := range []struct {
+		name   string
+		client *Authority
+		who    *Principal
+		method string
+		want   codes.Code
+	}{
+		{"anónimo en método público", a, nil, "/catalog.Catalog/ListBooks", codes.OK},
+		{"health check", a, nil, "/grpc.health.v1.Health/Check", codes.OK},
+		{"anónimo en método privado", a, nil, "/order.Order/GetOrderStatus", codes.Unauthenticated},
+		{"usuario en método de gestión", a, User(5), "/order.OrderAdmin/UpdateFulfillment", codes.PermissionDenied},
+		{"admin en método de gestión", a, admin, "/order.OrderAdmin/UpdateFulfillment", codes.OK},
+		{"otra clave, aunque sea público", newTestAuthority(t, strings.Repeat("x", 32)), User(5), "/catalog.Catalog/ListBooks", codes.Unauthenticated},
+		{"token vencido", expired, User(5), "/order.Order/GetOrderStatus", codes.Unauthenticated},
+	} {
+		_, err := call(t, c.client, a, context.Background(), c.who, c.method, rules)
+		if status.Code(err) != c.want {
+			t.Errorf("%s: %v, se esperaba %s", c.name, err, c.want)
+		}
+	}
+}
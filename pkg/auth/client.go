@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DialOptions instala los interceptores de cliente: cada llamada lleva un token
+// del Principal del contexto o, si no hay, de self. Sin ninguno de los dos la
+// llamada sale sin token (solo sirve para métodos públicos).
+func (a *Authority) DialOptions(self *Principal) []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			ctx, err := a.outgoing(ctx, self)
+			if err != nil {
+				return err
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			ctx, err := a.outgoing(ctx, self)
+			if err != nil {
+				return nil, err
+			}
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	}
+}
+
+func (a *Authority) outgoing(ctx context.Context, self *Principal) (context.Context, error) {
+	// Un token puesto a mano (p. ej. por una herramienta) no se reemplaza
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get("authorization")) > 0 {
+		return ctx, nil
+	}
+	p := FromContext(ctx)
+	if p == nil {
+		p = self
+	}
+	if p == nil {
+		return ctx, nil
+	}
+	token, err := a.Issue(p)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), nil
+}
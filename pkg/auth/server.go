@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Rules dice qué métodos no exigen token y cuáles exigen rol admin. Cada
+// entrada es un método completo ("/cart.Cart/GetCart") o un servicio entero
+// ("/order.OrderAdmin/"). El resto de métodos exige un Principal cualquiera.
+type Rules struct {
+	Public []string
+	Admin  []string
+}
+
+// alwaysPublic: el health check del balanceador y la reflexión de grpcurl.
+var alwaysPublic = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+func match(list []string, method string) bool {
+	for _, m := range list {
+		if method == m || ((strings.HasSuffix(m, "/") || strings.HasSuffix(m, ".")) && strings.HasPrefix(method, m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize valida el token del metadata y aplica rules. Un token presente pero
+// inválido se rechaza incluso en métodos públicos.
+func (a *Authority) authorize(ctx context.Context, method string, rules Rules) (context.Context, error) {
+	p, err := a.fromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p == nil && (match(alwaysPublic, method) || match(rules.Public, method)):
+		return ctx, nil
+	case p == nil:
+		return nil, status.Error(codes.Unauthenticated, "falta el token de autenticación")
+	case match(rules.Admin, method) && !p.IsAdmin():
+		return nil, status.Errorf(codes.PermissionDenied, "%s requiere rol %s", method, RoleAdmin)
+	}
+	return NewContext(ctx, p), nil
+}
+
+func (a *Authority) fromMetadata(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	vals := md.Get("authorization")
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	token, ok := strings.CutPrefix(vals[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "se esperaba authorization: Bearer <token>")
+	}
+	p, err := a.Verify(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "token inválido o vencido")
+	}
+	return p, nil
+}
+
+// ServerOptions instala los interceptores unary y stream con rules; se suman a
+// grpcclient.ServerOptions().
+func (a *Authority) ServerOptions(rules Rules) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := a.authorize(ctx, info.FullMethod, rules)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := a.authorize(ss.Context(), info.FullMethod, rules)
+			if err != nil {
+				return err
+			}
+			return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+		}),
+	}
+}
+
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context { return s.ctx }
+
+// CheckUser permite la llamada si el Principal es el usuario userID, un admin o
+// un servicio interno. Los handlers la usan para que nadie lea ni modifique
+// carritos, órdenes o pagos ajenos.
+func CheckUser(ctx context.Context, userID int64) error {
+	p := FromContext(ctx)
+	switch {
+	case p == nil:
+		return status.Error(codes.Unauthenticated, "falta el token de autenticación")
+	case p.Service != "" || p.IsAdmin():
+		return nil
+	case userID != 0 && p.UserID == userID:
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, "el recurso pertenece a otro usuario")
+}
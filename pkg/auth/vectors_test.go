@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+	"time"
+)
+
+var updateVectors = flag.Bool("update", false, "reescribe testdata/tokens.json")
+
+// tokenVectors son tokens emitidos por Issue con reloj y claves fijos. Los
+// verifica también Backend/src/user/src/test_auth.py, para que un cambio de
+// formato rompa las pruebas de ambos lados y no solo las de Go.
+type tokenVectors struct {
+	Secret         string        `json:"secret"`
+	PreviousSecret string        `json:"previous_secret"`
+	Now            int64         `json:"now"`
+	TTLSeconds     int64         `json:"ttl_seconds"`
+	Tokens         []tokenVector `json:"tokens"`
+}
+
+type tokenVector struct {
+	Name     string   `json:"name"`
+	Previous bool     `json:"previous"` // firmado con previous_secret
+	UserID   int64    `json:"user_id"`
+	Service  string   `json:"service"`
+	Roles    []string `json:"roles"`
+	Token    string   `json:"token"`
+}
+
+const vectorsPath = "testdata/tokens.json"
+
+func TestTokenVectors(t *testing.T) {
+	want := tokenVectors{
+		Secret:         testSecret,
+		PreviousSecret: "fedcba9876543210fedcba9876543210",
+		Now:            time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC).Unix(),
+		TTLSeconds:     300,
+		Tokens: []tokenVector{
+			{Name: "usuario", UserID: 42, Roles: []string{}},
+			{Name: "servicio", Service: "session", Roles: []string{}},
+			{Name: "admin", Service: "ops", Roles: []string{RoleAdmin}},
+			{Name: "clave anterior", Previous: true, UserID: 7, Roles: []string{}},
+		},
+	}
+	for i, v := range want.Tokens {
+		secret := want.Secret
+		if v.Previous {
+			secret = want.PreviousSecret
+		}
+		a, err := New(Config{Secret: secret, TokenTTL: time.Duration(want.TTLSeconds) * time.Second})
+		if err != nil {
+			t.Fatal(err)
+		}
+		a.now = func() time.Time { return time.Unix(want.Now, 0) }
+		p := &Principal{UserID: v.UserID, Service: v.Service}
+		if len(v.Roles) > 0 {
+			p.Roles = v.Roles
+		}
+		if want.Tokens[i].Token, err = a.Issue(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if *updateVectors {
+		b, err := json.MarshalIndent(want, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(vectorsPath, append(b, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	raw, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got tokenVectors
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tokens) != len(want.Tokens) {
+		t.Fatalf("%s tiene %d tokens, se esperaban %d (go test -update)", vectorsPath, len(got.Tokens), len(want.Tokens))
+	}
+	for i, v := range want.Tokens {
+		if got.Tokens[i].Token != v.Token {
+			t.Errorf("%s: el token de %s cambió; si el formato cambió a propósito, regenerar con go test -update y ajustar auth.py", vectorsPath, v.Name)
+		}
+	}
+
+	// Go también acepta sus propios vectores, con la clave anterior en rotación
+	a, err := New(Config{Secret: want.Secret, PreviousSecrets: []string{want.PreviousSecret}, TokenTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.now = func() time.Time { return time.Unix(want.Now, 0) }
+	for _, v := range want.Tokens {
+		p, err := a.Verify(v.Token)
+		if err != nil || p.UserID != v.UserID || p.Service != v.Service {
+			t.Errorf("%s: Verify = %+v, %v", v.Name, p, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package grpcclient centraliza las conexiones gRPC entre servicios y frontends.
+//
+// Cada destino usa una única conexión persistente (grpc.ClientConn es seguro
+// para uso concurrente), con keepalive, balanceo round_robin con health checking
+// y el service config de policy.go (deadlines por método y reintentos solo para
+// RPCs idempotentes).
+//
+// El destino puede ser una dirección ("cart:50050"), un nombre DNS con varios
+// registros (servicio headless en k8s) o una lista separada por comas
+// ("cart-0:50050,cart-1:50050").
+package grpcclient
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health" // habilita healthCheckConfig en el cliente
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+var (
+	mu    sync.Mutex
+	conns = map[string]*grpc.ClientConn{}
+
+	extraMu   sync.RWMutex
+	extraOpts []grpc.DialOption
+	creds     = insecure.NewCredentials()
+)
+
+// SetCredentials reemplaza el transporte sin cifrar de las conexiones que se
+// creen después (mTLS, ver pkg/mtls). Se llama al inicio de main.
+func SetCredentials(c credentials.TransportCredentials) {
+	extraMu.Lock()
+	defer extraMu.Unlock()
+	creds = c
+}
+
+// AddDialOptions suma opciones a todas las conexiones que se creen después (p. ej.
+// los interceptores de pkg/auth). Se llama al inicio de main.
+func AddDialOptions(opts ...grpc.DialOption) {
+	extraMu.Lock()
+	defer extraMu.Unlock()
+	extraOpts = append(extraOpts, opts...)
+}
+
+// Conn devuelve la conexión compartida para target, creándola la primera vez.
+func Conn(target string) (*grpc.ClientConn, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if cc, ok := conns[target]; ok {
+		return cc, nil
+	}
+	cc, err := Dial(target)
+	if err != nil {
+		return nil, err
+	}
+	conns[target] = cc
+	return cc, nil
+}
+
+// MustConn es Conn para main(): la conexión es perezosa, así que solo falla con
+// un destino mal formado.
+func MustConn(target string) *grpc.ClientConn {
+	cc, err := Conn(target)
+	if err != nil {
+		panic(err)
+	}
+	return cc
+}
+
+// CloseAll cierra todas las conexiones del pool.
+func CloseAll() {
+	mu.Lock()
+	defer mu.Unlock()
+	for t, cc := range conns {
+		cc.Close()
+		delete(conns, t)
+	}
+}
+
+// Dial crea una conexión nueva (fuera del pool) con las opciones por defecto.
+func Dial(target string, extra ...grpc.DialOption) (*grpc.ClientConn, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("grpcclient: destino vacío")
+	}
+	opts := append(DialOptions(), extra...)
+	if strings.Contains(target, ",") {
+		r := manual.NewBuilderWithScheme("static")
+		var addrs []resolver.Address
+		for _, a := range strings.Split(target, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				addrs = append(addrs, resolver.Address{Addr: a})
+			}
+		}
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("grpcclient: destino inválido %q", target)
+		}
+		r.InitialState(resolver.State{Addresses: addrs})
+		opts = append(opts, grpc.WithResolvers(r))
+		target = r.Scheme() + ":///" + addrs[0].Addr
+	}
+	cc, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("grpcclient: %s: %w", target, err)
+	}
+	return cc, nil
+}
+
+// DialOptions son las opciones comunes de todas las conexiones.
+func DialOptions() []grpc.DialOption {
+	extraMu.RLock()
+	defer extraMu.RUnlock()
+	return append([]grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(ServiceConfig(Methods)),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                30 * time.Second,
+			Timeout:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	}, extraOpts...)
+}
@@ -0,0 +1,169 @@
+package grpcclient
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cartpb "github.com/ahinestrog/mybookstore/proto/gen/cart"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+)
+
+func TestServiceConfig(t *testing.T) {
+	raw := ServiceConfig([]Method{
+		{Service: "cart.Cart", Timeout: 5 * time.Second},
+		{Service: "cart.Cart", Name: "GetCart", Timeout: 1500 * time.Millisecond, Idempotent: true},
+		{Service: "order.Order", Name: "WatchOrder", Idempotent: true},
+	})
+	var sc serviceConfig
+	if err := json.Unmarshal([]byte(raw), &sc); err != nil {
+		t.Fatalf("service config inválido: %v\n%s", err, raw)
+	}
+	if _, ok := sc.LoadBalancingConfig[0]["round_robin"]; !ok || len(sc.LoadBalancingConfig) != 1 {
+		t.Fatalf("loadBalancingConfig = %v", sc.LoadBalancingConfig)
+	}
+	want := []struct {
+		service, method, timeout string
+		retry                    bool
+	}{
+		{"cart.Cart", "", "5s", false},
+		{"cart.Cart", "GetCart", "1.5s", true},
+		{"order.Order", "WatchOrder", "", true},
+	}
+	if len(sc.MethodConfig) != len(want) {
+		t.Fatalf("methodConfig = %d entradas, se esperaban %d", len(sc.MethodConfig), len(want))
+	}
+	for i, w := range want {
+		mc := sc.MethodConfig[i]
+		if mc.Name[0].Service != w.service || mc.Name[0].Method != w.method || mc.Timeout != w.timeout || (mc.RetryPolicy != nil) != w.retry {
+			t.Errorf("methodConfig[%d] = %+v, se esperaba %+v", i, mc, w)
+		}
+	}
+	if rp := sc.MethodConfig[1].RetryPolicy; rp.MaxAttempts != 4 || len(rp.RetryableStatusCodes) != 1 || rp.RetryableStatusCodes[0] != "UNAVAILABLE" {
+		t.Fatalf("retryPolicy = %+v", rp)
+	}
+}
+
+// TestMethodsTable: gRPC rechaza el service config entero si un método se repite,
+// y entonces ninguna conexión tendría deadlines ni reintentos.
+func TestMethodsTable(t *testing.T) {
+	seen := map[methodName]bool{}
+	for _, m := range Methods {
+		k := methodName{Service: m.Service, Method: m.Name}
+		if seen[k] {
+			t.Errorf("método repetido en Methods: %s/%s", m.Service, m.Name)
+		}
+		seen[k] = true
+	}
+	cc, err := grpc.NewClient("localhost:1", DialOptions()...)
+	if err != nil {
+		t.Fatalf("gRPC no acepta el service config de Methods: %v", err)
+	}
+	cc.Close()
+}
+
+// cartServer responde UNAVAILABLE y anota cuántas veces se llamó a cada RPC y
+// el deadline con que llegó.
+type cartServer struct {
+	cartpb.UnimplementedCartServer
+	getCart, addItem atomic.Int32
+	deadline         atomic.Int64 // tiempo restante del último RPC, en ns
+}
+
+func (s *cartServer) note(ctx context.Context) {
+	if d, ok := ctx.Deadline(); ok {
+		s.deadline.Store(int64(time.Until(d)))
+	}
+}
+
+func (s *cartServer) GetCart(ctx context.Context, _ *commonpb.UserRef) (*cartpb.CartView, error) {
+	s.getCart.Add(1)
+	s.note(ctx)
+	return nil, status.Error(codes.Unavailable, "réplica caída")
+}
+
+func (s *cartServer) AddItem(ctx context.Context, _ *cartpb.AddItemRequest) (*cartpb.CartView, error) {
+	s.addItem.Add(1)
+	s.note(ctx)
+	return nil, status.Error(codes.Unavailable, "réplica caída")
+}
+
+func startCart(t *testing.T) (*cartServer, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(ServerOptions()...)
+	cart := &cartServer{}
+	cartpb.RegisterCartServer(srv, cart)
+	RegisterHealth(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return cart, lis.Addr().String()
+}
+
+// TestMethodPolicy: el cliente aplica el deadline y los reintentos del método
+// (GetCart) y, si no tiene entrada propia, los del servicio (AddItem).
+func TestMethodPolicy(t *testing.T) {
+	cart, addr := startCart(t)
+	cc, err := Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Close()
+	client := cartpb.NewCartClient(cc)
+	ctx := context.Background()
+
+	if _, err := client.GetCart(ctx, &commonpb.UserRef{UserId: 1}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("GetCart = %v", err)
+	}
+	if n := cart.getCart.Load(); n != 4 {
+		t.Fatalf("GetCart (idempotente) se intentó %d veces, se esperaban 4", n)
+	}
+	if d := time.Duration(cart.deadline.Load()); d <= 0 || d > readTimeout {
+		t.Fatalf("deadline de GetCart = %s, se esperaba a lo sumo %s", d, readTimeout)
+	}
+
+	if _, err := client.AddItem(ctx, &cartpb.AddItemRequest{UserId: 1, BookId: 2, Qty: 1}); status.Code(err) != codes.Unavailable {
+		t.Fatalf("AddItem = %v", err)
+	}
+	if n := cart.addItem.Load(); n != 1 {
+		t.Fatalf("AddItem (no idempotente) se intentó %d veces, se esperaba 1", n)
+	}
+	if d := time.Duration(cart.deadline.Load()); d <= readTimeout || d > writeTimeout {
+		t.Fatalf("deadline de AddItem = %s, se esperaba el del servicio (%s)", d, writeTimeout)
+	}
+}
+
+func TestConnReuse(t *testing.T) {
+	t.Cleanup(CloseAll)
+	a, err := Conn("cart:50050")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := MustConn("cart:50050"); b != a {
+		t.Fatal("el mismo destino abrió otra conexión")
+	}
+	if c := MustConn("cart-0:50050,cart-1:50050"); c == a {
+		t.Fatal("destinos distintos comparten la conexión")
+	}
+	if _, err := Conn(" "); err == nil {
+		t.Fatal("un destino vacío no falló")
+	}
+	if _, err := Conn(" , "); err == nil {
+		t.Fatal("una lista sin direcciones no falló")
+	}
+
+	CloseAll()
+	if b := MustConn("cart:50050"); b == a {
+		t.Fatal("CloseAll no vació el pool")
+	}
+}
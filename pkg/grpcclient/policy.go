@@ -0,0 +1,135 @@
+package grpcclient
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Method describe la política de un RPC: deadline por defecto y si es seguro
+// reintentarlo (idempotente).
+type Method struct {
+	Service    string // nombre completo del servicio, p. ej. "cart.Cart"
+	Name       string // vacío aplica a todo el servicio
+	Timeout    time.Duration
+	Idempotent bool
+}
+
+const (
+	readTimeout  = 2 * time.Second
+	writeTimeout = 5 * time.Second
+)
+
+// Methods es la tabla de políticas de todos los servicios del proyecto. Solo los
+// RPCs marcados como idempotentes se reintentan ante UNAVAILABLE.
+var Methods = []Method{
+	{Service: "cart.Cart", Timeout: writeTimeout},
+	{Service: "cart.Cart", Name: "GetCart", Timeout: readTimeout, Idempotent: true},
+	{Service: "cart.Cart", Name: "ClearCart", Timeout: writeTimeout, Idempotent: true},
+	// Reintentar con el mismo checkout_id devuelve el mismo snapshot.
+	{Service: "cart.Cart", Name: "CheckoutSnapshot", Timeout: writeTimeout, Idempotent: true},
+	// Un checkout ya restaurado no se toca de nuevo.
+	{Service: "cart.Cart", Name: "RestoreCheckout", Timeout: writeTimeout, Idempotent: true},
+
+	{Service: "catalog.Catalog", Timeout: readTimeout, Idempotent: true},
+	{Service: "inventory.Inventory", Timeout: readTimeout, Idempotent: true},
+
+	// CreateOrder llama al carrito y a la base; no se reintenta aquí porque la
+	// idempotencia depende de que el cliente envíe idempotency_key.
+	{Service: "order.Order", Name: "CreateOrder", Timeout: 10 * time.Second},
+	{Service: "order.Order", Name: "GetOrderStatus", Timeout: readTimeout, Idempotent: true},
+	{Service: "order.Order", Name: "RequestReturn", Timeout: writeTimeout},
+	{Service: "order.Order", Name: "ListReturns", Timeout: readTimeout, Idempotent: true},
+	// Stream abierto mientras el cliente mire la orden: sin deadline. Solo lee.
+	{Service: "order.Order", Name: "WatchOrder", Idempotent: true},
+	// Repetir el estado actual del despacho o la misma decisión sobre una
+	// devolución no tiene efecto.
+	{Service: "order.OrderAdmin", Timeout: writeTimeout, Idempotent: true},
+
+	{Service: "payment.Payment", Timeout: readTimeout, Idempotent: true},
+	// Solo es seguro reintentarlo si el cliente envía idempotency_key.
+	{Service: "payment.Payment", Name: "RefundPayment", Timeout: writeTimeout},
+	// El archivo se deduplica por checksum, así que reintentar no duplica la corrida.
+	{Service: "payment.Payment", Name: "ImportSettlement", Timeout: 30 * time.Second, Idempotent: true},
+	// Guardar el mismo token devuelve el instrumento existente; borrar dos veces no falla.
+	{Service: "payment.Payment", Name: "SaveInstrument", Timeout: writeTimeout, Idempotent: true},
+	{Service: "payment.Payment", Name: "DeleteInstrument", Timeout: writeTimeout, Idempotent: true},
+	// Redimir de nuevo la misma tarjeta (mismo usuario) devuelve el movimiento original.
+	{Service: "payment.Payment", Name: "RedeemGiftCard", Timeout: writeTimeout, Idempotent: true},
+	// Cada llamada emite un código nuevo.
+	{Service: "payment.Payment", Name: "IssueGiftCard", Timeout: writeTimeout},
+	// Repetir la misma decisión completa lo pendiente sin cobrar dos veces; aprobar
+	// cobra en la pasarela, así que se le da el tiempo de un cobro.
+	{Service: "payment.Payment", Name: "ApproveReview", Timeout: 15 * time.Second, Idempotent: true},
+	{Service: "payment.Payment", Name: "RejectReview", Timeout: writeTimeout, Idempotent: true},
+
+	{Service: "user.User", Timeout: writeTimeout},
+	{Service: "user.User", Name: "Authenticate", Timeout: writeTimeout, Idempotent: true},
+	{Service: "user.User", Name: "GetProfile", Timeout: readTimeout, Idempotent: true},
+	{Service: "user.User", Name: "UpdateName", Timeout: writeTimeout, Idempotent: true},
+	{Service: "user.User", Name: "ListAddresses", Timeout: readTimeout, Idempotent: true},
+	{Service: "user.User", Name: "GetAddress", Timeout: readTimeout, Idempotent: true},
+	{Service: "user.User", Name: "DeleteAddress", Timeout: writeTimeout, Idempotent: true},
+	{Service: "user.User", Name: "CheckSession", Timeout: readTimeout, Idempotent: true},
+	{Service: "user.User", Name: "RevokeSession", Timeout: writeTimeout, Idempotent: true},
+	{Service: "user.User", Name: "RevokeUserSessions", Timeout: writeTimeout, Idempotent: true},
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method,omitempty"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	Timeout     string       `json:"timeout,omitempty"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+	HealthCheckConfig   struct {
+		ServiceName string `json:"serviceName"`
+	} `json:"healthCheckConfig"`
+	MethodConfig []methodConfig `json:"methodConfig"`
+}
+
+var defaultRetry = &retryPolicy{
+	MaxAttempts:          4,
+	InitialBackoff:       "0.1s",
+	MaxBackoff:           "1s",
+	BackoffMultiplier:    2,
+	RetryableStatusCodes: []string{"UNAVAILABLE"},
+}
+
+// ServiceConfig arma el service config JSON (round_robin + health checking +
+// methodConfig) a partir de la tabla de métodos.
+func ServiceConfig(methods []Method) string {
+	sc := serviceConfig{
+		LoadBalancingConfig: []map[string]struct{}{{"round_robin": {}}},
+	}
+	for _, m := range methods {
+		mc := methodConfig{Name: []methodName{{Service: m.Service, Method: m.Name}}}
+		if m.Timeout > 0 {
+			mc.Timeout = durationString(m.Timeout)
+		}
+		if m.Idempotent {
+			mc.RetryPolicy = defaultRetry
+		}
+		sc.MethodConfig = append(sc.MethodConfig, mc)
+	}
+	b, _ := json.Marshal(sc)
+	return string(b)
+}
+
+func durationString(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
@@ -0,0 +1,29 @@
+package grpcclient
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ServerOptions acepta los pings de keepalive que envían los clientes de este
+// paquete; con la política por defecto el servidor cerraría la conexión.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             20 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	}
+}
+
+// RegisterHealth registra grpc.health.v1.Health en estado SERVING, que es lo que
+// consulta el balanceador del cliente antes de enviar RPCs a una réplica.
+func RegisterHealth(s *grpc.Server) *health.Server {
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(s, hs)
+	return hs
+}
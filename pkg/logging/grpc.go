@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DialOptions instala los interceptores de cliente: cada llamada lleva el
+// correlation_id del contexto en el metadata. Setup ya los registra en grpcclient.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			return streamer(outgoing(ctx), desc, cc, method, opts...)
+		}),
+	}
+}
+
+func outgoing(ctx context.Context) context.Context {
+	if id := ID(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, Header, id)
+	}
+	return ctx
+}
+
+// ServerOptions instala los interceptores de servidor: el correlation_id del
+// metadata (o uno nuevo) queda en el contexto del handler y cada RPC se
+// registra al terminar, en debug si salió bien y en error si falló por dentro.
+// Van antes que los de pkg/auth, así los rechazos también se registran.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx = incoming(ctx)
+			start := time.Now()
+			resp, err := handler(ctx, req)
+			logRPC(ctx, info.FullMethod, start, err)
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx := incoming(ss.Context())
+			start := time.Now()
+			err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+			logRPC(ctx, info.FullMethod, start, err)
+			return err
+		}),
+	}
+}
+
+func incoming(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(Header); len(v) > 0 && validID(v[0]) {
+		return NewContext(ctx, v[0])
+	}
+	return NewContext(ctx, NewID())
+}
+
+func logRPC(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelDebug
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss:
+		level = slog.LevelError
+	}
+	args := []any{"method", method, "code", code.String(), "duration", time.Since(start)}
+	if err != nil {
+		args = append(args, "err", err)
+	}
+	slog.Log(ctx, level, "rpc", args...)
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,150 @@
+// Package logging configura log/slog igual en todos los procesos Go: JSON en
+// stdout, nivel desde <SERVICIO>_LOG_LEVEL y, en cada línea, el correlation_id
+// del contexto.
+//
+// El correlation_id nace en el storefront con cada petición HTTP (Middleware),
+// viaja en el metadata de gRPC y en los headers de los mensajes AMQP bajo
+// Header, y cada servicio lo devuelve al contexto al recibir la llamada
+// (ServerOptions) o el mensaje (FromHeaders). Así un checkout se puede seguir
+// desde la petición del navegador hasta el cobro y el despacho. Para que llegue
+// al log hay que usar las variantes con contexto: slog.InfoContext(ctx, ...).
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+)
+
+// Header es el nombre del correlation_id en el metadata gRPC, en los headers
+// AMQP y en la respuesta HTTP del storefront.
+const Header = "x-correlation-id"
+
+// Setup instala el logger de service como slog.Default (también recibe lo que
+// se escriba con el paquete log) y registra los interceptores de cliente en
+// grpcclient. Se llama al inicio de main, antes de abrir conexiones.
+func Setup(service string) *slog.Logger {
+	key := strings.ToUpper(service) + "_LOG_LEVEL"
+	level, err := ParseLevel(os.Getenv(key))
+	l := slog.New(NewHandler(os.Stdout, level)).With("service", service)
+	slog.SetDefault(l)
+	if err != nil {
+		l.Warn("nivel de log inválido; uso info", "key", key, "err", err)
+	}
+	grpcclient.AddDialOptions(DialOptions()...)
+	return l
+}
+
+// ParseLevel acepta debug, info, warn y error (sin distinguir mayúsculas). Vacío es info.
+func ParseLevel(s string) (slog.Level, error) {
+	var l slog.Level
+	if strings.TrimSpace(s) == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("%q: %w", s, err)
+	}
+	return l, nil
+}
+
+// NewHandler escribe JSON con el correlation_id del contexto de cada registro
+// y, si hay un span activo (pkg/tracing), con su trace_id y span_id.
+func NewHandler(w io.Writer, level slog.Leveler) slog.Handler {
+	return correlationHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})}
+}
+
+type correlationHandler struct{ slog.Handler }
+
+func (h correlationHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := ID(ctx); id != "" {
+		r.AddAttrs(slog.String("correlation_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h correlationHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return correlationHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h correlationHandler) WithGroup(name string) slog.Handler {
+	return correlationHandler{h.Handler.WithGroup(name)}
+}
+
+// Fatal registra el error y termina el proceso; reemplaza a log.Fatalf en main.
+func Fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+type ctxKey struct{}
+
+// NewID genera un correlation_id (16 caracteres hexadecimales).
+func NewID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NewContext devuelve ctx con el correlation_id id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// ID devuelve el correlation_id de ctx, o "".
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// Ensure devuelve ctx con un correlation_id: el que ya tenga o uno nuevo. Lo
+// usan las tareas que no nacen de una petición (plazos, conciliación).
+func Ensure(ctx context.Context) context.Context {
+	if ID(ctx) != "" {
+		return ctx
+	}
+	return NewContext(ctx, NewID())
+}
+
+// Headers son los headers AMQP que propagan el correlation_id de ctx (nil si
+// no hay). Sirven como amqp.Table en cualquiera de los clientes AMQP.
+func Headers(ctx context.Context) map[string]any {
+	if id := ID(ctx); id != "" {
+		return map[string]any{Header: id}
+	}
+	return nil
+}
+
+// FromHeaders devuelve ctx con el correlation_id de los headers de un mensaje
+// AMQP, o con uno nuevo si el publicador no lo envió.
+func FromHeaders(ctx context.Context, headers map[string]any) context.Context {
+	if id, ok := headers[Header].(string); ok && validID(id) {
+		return NewContext(ctx, id)
+	}
+	return NewContext(ctx, NewID())
+}
+
+// validID limita lo que se acepta de otro proceso a algo que quepa en una
+// línea de log sin sorpresas.
+func validID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
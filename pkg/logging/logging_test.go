@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// TestCorrelationTravels sigue un id desde la petición HTTP hasta el log del
+// consumidor: Middleware → metadata gRPC → headers AMQP → FromHeaders.
+func TestCorrelationTravels(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(NewHandler(&buf, slog.LevelInfo))
+
+	var id string
+	rec := httptest.NewRecorder()
+	Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = ID(r.Context())
+		w.WriteHeader(http.StatusAccepted)
+	})).ServeHTTP(rec, httptest.NewRequest("POST", "/checkout", nil))
+	if id == "" || rec.Header().Get(Header) != id {
+		t.Fatalf("id en el handler = %q, en la respuesta = %q", id, rec.Header().Get(Header))
+	}
+
+	// Cliente gRPC → servidor
+	md, _ := metadata.FromOutgoingContext(outgoing(NewContext(context.Background(), id)))
+	ctx := incoming(metadata.NewIncomingContext(context.Background(), md))
+	if ID(ctx) != id {
+		t.Fatalf("id en el servidor gRPC = %q, se esperaba %q", ID(ctx), id)
+	}
+
+	// Publicador AMQP → consumidor
+	ctx = FromHeaders(context.Background(), Headers(ctx))
+	log.InfoContext(ctx, "order.created", "order_id", 7)
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("la línea no es JSON: %v (%s)", err, buf.String())
+	}
+	if line["correlation_id"] != id || line["msg"] != "order.created" || line["order_id"] != float64(7) {
+		t.Fatalf("línea = %v", line)
+	}
+}
+
+func TestMissingOrForgedID(t *testing.T) {
+	for _, h := range []map[string]any{nil, {Header: ""}, {Header: "a b\n{}"}, {Header: strings.Repeat("x", 65)}, {Header: 42}} {
+		id := ID(FromHeaders(context.Background(), h))
+		if !validID(id) || len(id) != 16 {
+			t.Fatalf("headers %v → id %q, se esperaba uno nuevo", h, id)
+		}
+	}
+	ctx := incoming(metadata.NewIncomingContext(context.Background(), metadata.Pairs(Header, "../../etc")))
+	if id := ID(ctx); id == "../../etc" || !validID(id) {
+		t.Fatalf("metadata inválido aceptado: %q", id)
+	}
+	if Headers(context.Background()) != nil {
+		t.Fatal("sin correlation_id no se envían headers")
+	}
+	if ctx := Ensure(NewContext(context.Background(), "abc")); ID(ctx) != "abc" {
+		t.Fatalf("Ensure reemplazó el id: %q", ID(ctx))
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"": slog.LevelInfo, "debug": slog.LevelDebug, " WARN ": slog.LevelWarn, "error": slog.LevelError} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v; se esperaba %v", in, got, err, want)
+		}
+	}
+	if l, err := ParseLevel("verbose"); err == nil || l != slog.LevelInfo {
+		t.Fatalf("ParseLevel(verbose) = %v, %v; se esperaba error e info", l, err)
+	}
+
+	var buf bytes.Buffer
+	log := slog.New(NewHandler(&buf, slog.LevelWarn))
+	log.Info("no")
+	log.Warn("sí")
+	if strings.Count(buf.String(), "\n") != 1 || !strings.Contains(buf.String(), `"msg":"sí"`) {
+		t.Fatalf("salida con nivel warn = %q", buf.String())
+	}
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	consumed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "messages_consumed_total",
+		Help: "Mensajes procesados por los consumidores, por cola, routing key y resultado (ok | error).",
+	}, []string{"queue", "routing_key", "result"})
+	lag = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Name: "message_lag_seconds",
+		Help:    "Tiempo entre la publicación de un mensaje y el inicio de su proceso.",
+		Buckets: []float64{.005, .01, .05, .1, .5, 1, 5, 15, 60, 300},
+	}, []string{"queue", "routing_key"})
+	published = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "messages_published_total",
+		Help: "Mensajes publicados, por routing key y resultado (ok | error).",
+	}, []string{"routing_key", "result"})
+
+	queueMessages = prometheus.NewDesc(namespace+"_queue_messages",
+		"Mensajes en espera en la cola (ready), leídos del broker en cada scrape.",
+		[]string{"queue"}, nil)
+)
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// Received mide el retraso de un mensaje al llegar al consumidor. sent es el
+// Timestamp de la publicación; los mensajes sin él (publicadores viejos) no se miden.
+func Received(queue, routingKey string, sent time.Time) {
+	if sent.IsZero() {
+		return
+	}
+	lag.WithLabelValues(queue, routingKey).Observe(time.Since(sent).Seconds())
+}
+
+// Consumed cuenta un mensaje procesado; err distinto de nil cuenta como fallo.
+func Consumed(queue, routingKey string, err error) {
+	consumed.WithLabelValues(queue, routingKey, result(err)).Inc()
+}
+
+// Published cuenta una publicación; err distinto de nil es que el broker no la aceptó.
+func Published(routingKey string, err error) {
+	published.WithLabelValues(routingKey, result(err)).Inc()
+}
+
+// QueueDepth exporta mybookstore_queue_messages de cada cola de queues; depth
+// se consulta en cada scrape (una declaración pasiva en el broker). Cada
+// servicio registra las colas que consume.
+func QueueDepth(depth func(queue string) (int, error), queues ...string) {
+	reg.MustRegister(queueCollector{depth: depth, queues: queues})
+}
+
+type queueCollector struct {
+	depth  func(string) (int, error)
+	queues []string
+}
+
+func (c queueCollector) Describe(ch chan<- *prometheus.Desc) { ch <- queueMessages }
+
+func (c queueCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, q := range c.queues {
+		n, err := c.depth(q)
+		if err != nil {
+			slog.Warn("metrics: profundidad de cola", "queue", q, "err", err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(queueMessages, prometheus.GaugeValue, float64(n), q)
+	}
+}
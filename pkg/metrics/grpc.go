@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	rpcServer = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Name: "grpc_server_handling_seconds",
+		Help:    "Duración de los RPC atendidos, por método y código de respuesta.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+	rpcClient = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Name: "grpc_client_handling_seconds",
+		Help:    "Duración de los RPC hechos a otros servicios, por método y código de respuesta.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+)
+
+// Los health checks no se miden: serían la mayoría de las llamadas.
+func skip(method string) bool { return strings.HasPrefix(method, "/grpc.health.v1.Health/") }
+
+func observe(h *prometheus.HistogramVec, method string, start time.Time, err error) {
+	if skip(method) {
+		return
+	}
+	h.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+}
+
+// DialOptions mide cada llamada a otro servicio. Setup ya las registra en grpcclient.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(clientUnary)}
+}
+
+// ServerOptions mide cada RPC atendido. En los streams (WatchOrder) la duración
+// es lo que estuvo abierto.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(serverUnary),
+		grpc.ChainStreamInterceptor(serverStream),
+	}
+}
+
+func clientUnary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	observe(rpcClient, method, start, err)
+	return err
+}
+
+func serverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observe(rpcServer, info.FullMethod, start, err)
+	return resp, err
+}
+
+func serverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observe(rpcServer, info.FullMethod, start, err)
+	return err
+}
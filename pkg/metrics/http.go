@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var httpRequests = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace, Name: "http_request_duration_seconds",
+	Help:    "Duración de las peticiones HTTP, por método, ruta y status.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "route", "code"})
+
+type routeSlotKey struct{}
+
+type routeSlot struct{ route string }
+
+// NoteRoute informa la ruta que atendió la petición, como el patrón del mux
+// ("/cart/add", "/order/{id}") y no la URL, para que la métrica no tenga una
+// serie por libro u orden. Vale la primera: el mux más interno la informa antes.
+func NoteRoute(ctx context.Context, route string) {
+	if s, ok := ctx.Value(routeSlotKey{}).(*routeSlot); ok && s.route == "" {
+		s.route = route
+	}
+}
+
+// Route prepara la petición para que los handlers informen la ruta con
+// NoteRoute. La función devuelta, llamada al terminar la petición, da la ruta
+// informada, o el patrón del mux que recibió la petición, o "" si no hay
+// ninguno. Si un middleware de afuera ya la preparó, ambos ven la misma ruta.
+func Route(r *http.Request) (*http.Request, func() string) {
+	slot, ok := r.Context().Value(routeSlotKey{}).(*routeSlot)
+	if !ok {
+		slot = &routeSlot{}
+		r = r.WithContext(context.WithValue(r.Context(), routeSlotKey{}, slot))
+	}
+	return r, func() string {
+		if slot.route != "" {
+			return slot.route
+		}
+		return PatternPath(r.Pattern)
+	}
+}
+
+// PatternPath quita el método y el host de un patrón de http.ServeMux
+// ("GET /add" → "/add").
+func PatternPath(pattern string) string {
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		return pattern[i:]
+	}
+	return pattern
+}
+
+// Middleware mide cada petición. La ruta la informa el handler con NoteRoute;
+// si nadie la informa queda el patrón del mux externo o "unmatched". /healthz
+// no se mide.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, code: http.StatusOK}
+		r, noted := Route(r)
+		next.ServeHTTP(sw, r)
+		route := noted()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequests.WithLabelValues(method(r.Method), route, strconv.Itoa(sw.code)).Observe(time.Since(start).Seconds())
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (s *statusWriter) WriteHeader(code int) {
+	s.code = code
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusWriter) Unwrap() http.ResponseWriter { return s.ResponseWriter }
+
+// method acota la etiqueta a los métodos conocidos; cualquier otro cuenta como "other".
+func method(m string) string {
+	switch m {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+		return m
+	}
+	return "other"
+}
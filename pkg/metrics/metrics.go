@@ -0,0 +1,123 @@
+// Package metrics expone métricas Prometheus iguales en todos los procesos Go
+// en GET /metrics, en <SERVICIO>_METRICS_ADDR (":9090" por defecto; "off" no
+// abre el puerto). Todas las series llevan la etiqueta service y, según la
+// métrica, las mismas etiquetas en todos los servicios:
+//
+//	mybookstore_grpc_server_handling_seconds   method, code
+//	mybookstore_grpc_client_handling_seconds   method, code
+//	mybookstore_http_request_duration_seconds  method, route, code
+//	mybookstore_messages_consumed_total        queue, routing_key, result
+//	mybookstore_message_lag_seconds            queue, routing_key
+//	mybookstore_messages_published_total       routing_key, result
+//	mybookstore_queue_messages                 queue
+//	mybookstore_orders_finished_total          status
+//	mybookstore_payment_declines_total         reason
+//	mybookstore_reservations_rejected_total    reason
+//	mybookstore_cart_adds_total
+//
+// Los errores de un RPC salen del histograma por code (p. ej.
+// code!="OK"); los del consumidor, de result="error".
+package metrics
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+)
+
+const namespace = "mybookstore"
+
+var (
+	registry = prometheus.NewRegistry()
+	// reg agrega la etiqueta service; Setup la fija
+	reg prometheus.Registerer = registry
+)
+
+// Métricas de negocio; cada servicio usa las suyas.
+var (
+	ordersFinished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "orders_finished_total",
+		Help: "Órdenes cuya saga terminó, por estado final.",
+	}, []string{"status"})
+	paymentDeclines = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "payment_declines_total",
+		Help: "Pagos rechazados, por motivo.",
+	}, []string{"reason"})
+	reservationsRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "reservations_rejected_total",
+		Help: "Reservas de stock rechazadas, por motivo.",
+	}, []string{"reason"})
+	// Sin etiquetas propias, pero como vector para no exportar un 0 en los demás servicios
+	cartAdds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "cart_adds_total",
+		Help: "Libros agregados al carrito.",
+	}, nil)
+)
+
+// Setup registra las métricas de service, instala los interceptores de cliente
+// en grpcclient y abre el endpoint /metrics. Se llama al inicio de main,
+// después de logging.Setup; falla si el puerto no está libre.
+func Setup(service string) error {
+	reg = prometheus.WrapRegistererWith(prometheus.Labels{"service": service}, registry)
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcServer, rpcClient, httpRequests,
+		consumed, lag, published,
+		ordersFinished, paymentDeclines, reservationsRejected, cartAdds,
+	)
+	grpcclient.AddDialOptions(DialOptions()...)
+
+	addr := os.Getenv(strings.ToUpper(service) + "_METRICS_ADDR")
+	if addr == "" {
+		addr = ":9090"
+	}
+	if addr == "off" {
+		return nil
+	}
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics: servidor detenido", "err", err)
+		}
+	}()
+	return nil
+}
+
+// Handler sirve las métricas registradas en formato Prometheus.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// OrderFinished cuenta una orden que terminó la saga con status ("paid", "failed"...).
+func OrderFinished(status string) { ordersFinished.WithLabelValues(status).Inc() }
+
+// PaymentDeclined cuenta un pago rechazado por reason.
+func PaymentDeclined(reason string) {
+	if reason == "" {
+		reason = "unknown"
+	}
+	paymentDeclines.WithLabelValues(reason).Inc()
+}
+
+// ReservationRejected cuenta una reserva de stock rechazada por reason.
+func ReservationRejected(reason string) { reservationsRejected.WithLabelValues(reason).Inc() }
+
+// CartAdd cuenta un libro agregado al carrito.
+func CartAdd() { cartAdds.WithLabelValues().Inc() }
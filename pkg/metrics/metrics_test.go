@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// samples devuelve cuántas observaciones tiene la serie de h con labels.
+func samples(t *testing.T, h *prometheus.HistogramVec, labels ...string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := h.WithLabelValues(labels...).(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+// TestMiddlewareRoute: la ruta es el patrón del mux más interno, no la URL, como
+// en el gateway del storefront (StripPrefix + un mux por módulo).
+func TestMiddlewareRoute(t *testing.T) {
+	sub := http.NewServeMux()
+	sub.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	mod := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub.ServeHTTP(w, r)
+		NoteRoute(r.Context(), "/cart"+PatternPath(r.Pattern))
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/cart/", http.StripPrefix("/cart", mod))
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {})
+	h := Middleware(mux)
+
+	for _, c := range []struct{ method, path string }{
+		{"GET", "/cart/items/42"},
+		{"GET", "/cart/items/43"},
+		{"GET", "/nope"},
+		{"BREW", "/nope"},
+		{"GET", "/healthz"},
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(c.method, c.path, nil))
+	}
+
+	if n := samples(t, httpRequests, "GET", "/cart/items/{id}", "201"); n != 2 {
+		t.Fatalf("/cart/items/{id} = %d, se esperaban 2", n)
+	}
+	if n := samples(t, httpRequests, "GET", "unmatched", "404"); n != 1 {
+		t.Fatalf("unmatched = %d, se esperaba 1", n)
+	}
+	if n := samples(t, httpRequests, "other", "unmatched", "404"); n != 1 {
+		t.Fatalf("método desconocido = %d, se esperaba 1", n)
+	}
+	if n := samples(t, httpRequests, "GET", "/healthz", "200"); n != 0 {
+		t.Fatalf("/healthz se midió %d veces", n)
+	}
+}
+
+func TestServerCodes(t *testing.T) {
+	unary := func(ctx context.Context, method string, err error) {
+		_, _ = serverUnary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, any) (any, error) {
+			return nil, err
+		})
+	}
+	unary(context.Background(), "/order.Order/GetOrder", nil)
+	unary(context.Background(), "/order.Order/GetOrder", status.Error(codes.NotFound, "no existe"))
+	unary(context.Background(), "/grpc.health.v1.Health/Check", nil)
+
+	if n := samples(t, rpcServer, "/order.Order/GetOrder", "OK"); n != 1 {
+		t.Fatalf("OK = %d, se esperaba 1", n)
+	}
+	if n := samples(t, rpcServer, "/order.Order/GetOrder", "NotFound"); n != 1 {
+		t.Fatalf("NotFound = %d, se esperaba 1", n)
+	}
+	if n := samples(t, rpcServer, "/grpc.health.v1.Health/Check", "OK"); n != 0 {
+		t.Fatalf("el health check se midió %d veces", n)
+	}
+}
+
+// TestExposition: lo que ve Prometheus lleva service en todas las series.
+func TestExposition(t *testing.T) {
+	t.Setenv("TEST_METRICS_ADDR", "off")
+	if err := Setup("test"); err != nil {
+		t.Fatal(err)
+	}
+	QueueDepth(func(q string) (int, error) {
+		if q == "missing" {
+			return 0, errors.New("NOT_FOUND")
+		}
+		return 3, nil
+	}, "order-service", "missing")
+	Received("order-service", "payment.failed", time.Now().Add(-2*time.Second))
+	Consumed("order-service", "payment.failed", errors.New("boom"))
+	Published("order.paid", nil)
+	OrderFinished("paid")
+	PaymentDeclined("")
+
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	out := string(body)
+
+	for _, want := range []string{
+		`mybookstore_queue_messages{queue="order-service",service="test"} 3`,
+		`mybookstore_messages_consumed_total{queue="order-service",result="error",routing_key="payment.failed",service="test"} 1`,
+		`mybookstore_message_lag_seconds_count{queue="order-service",routing_key="payment.failed",service="test"} 1`,
+		`mybookstore_messages_published_total{result="ok",routing_key="order.paid",service="test"} 1`,
+		`mybookstore_orders_finished_total{service="test",status="paid"} 1`,
+		`mybookstore_payment_declines_total{reason="unknown",service="test"} 1`,
+		`go_goroutines{service="test"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("falta %s", want)
+		}
+	}
+	if strings.Contains(out, `queue="missing"`) || strings.Contains(out, "mybookstore_cart_adds_total") {
+		t.Errorf("series que no deberían estar:\n%s", out)
+	}
+}
@@ -0,0 +1,128 @@
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteDevCerts genera en dir una CA local (ca.crt/ca.key, se reutiliza si ya
+// existe) y un certificado por nombre, válido como servidor y como cliente, con
+// el nombre en el CN y en los SAN DNS junto a localhost. Es solo para
+// desarrollo: en producción los emite la PKI del entorno.
+func WriteDevCerts(dir string, names []string, validity time.Duration) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	ca, caKey, err := loadOrCreateCA(dir, validity)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template(name, validity)
+		if err != nil {
+			return err
+		}
+		tmpl.DNSNames = []string{name, "localhost"}
+		tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+		if err != nil {
+			return err
+		}
+		if err := writePair(dir, name, der, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadOrCreateCA(dir string, validity time.Duration) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPEM, errCert := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	keyPEM, errKey := os.ReadFile(filepath.Join(dir, "ca.key"))
+	if errCert == nil && errKey == nil {
+		cb, _ := pem.Decode(certPEM)
+		kb, _ := pem.Decode(keyPEM)
+		if cb == nil || kb == nil {
+			return nil, nil, errors.New("mtls: ca.crt o ca.key no son PEM")
+		}
+		ca, err := x509.ParseCertificate(cb.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := x509.ParseECPrivateKey(kb.Bytes)
+		return ca, key, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl, err := template("mybookstore-dev-ca", validity)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writePair(dir, "ca", der, key); err != nil {
+		return nil, nil, err
+	}
+	ca, err := x509.ParseCertificate(der)
+	return ca, key, err
+}
+
+func template(cn string, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 120))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"MyBookStore dev"}},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+func writePair(dir, name string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	// Se escribe a un temporal y se renombra: un proceso que recarga nunca ve
+	// un archivo a medias. 0644 también en las claves porque los contenedores
+	// corren sin root y con otro uid que el dueño de ./certs (solo desarrollo).
+	for file, block := range map[string]*pem.Block{
+		name + ".crt": {Type: "CERTIFICATE", Bytes: der},
+		name + ".key": {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	} {
+		tmp := filepath.Join(dir, "."+file+".tmp")
+		if err := os.WriteFile(tmp, pem.EncodeToMemory(block), 0o644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, filepath.Join(dir, file)); err != nil {
+			return fmt.Errorf("mtls: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Package mtls configura TLS mutuo en las conexiones gRPC entre procesos.
+//
+// Con GRPC_TLS_MODE=mtls cada proceso se identifica con el certificado
+// GRPC_TLS_DIR/<nombre>.crt (y su .key), firmado por GRPC_TLS_DIR/ca.crt; el
+// nombre ("cart", "storefront"...) va en el CN y, para los servidores, en
+// los SAN DNS. Servidores y clientes exigen el certificado del otro extremo.
+// Los archivos se releen cuando cambian (como mucho cada
+// GRPC_TLS_RELOAD_INTERVAL), así una rotación no requiere reiniciar.
+//
+// Peers restringe qué identidades pueden llamar a cada RPC. Para desarrollo,
+// scripts/devcerts genera una CA local y los certificados de cada proceso.
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+)
+
+type Config struct {
+	Mode           string        // GRPC_TLS_MODE: off | mtls
+	CertFile       string        // GRPC_TLS_DIR/<nombre>.crt
+	KeyFile        string        // GRPC_TLS_DIR/<nombre>.key
+	CAFile         string        // GRPC_TLS_DIR/ca.crt
+	ReloadInterval time.Duration // GRPC_TLS_RELOAD_INTERVAL
+}
+
+// ConfigFromEnv lee la configuración del proceso name.
+func ConfigFromEnv(name string) (Config, error) {
+	dir := os.Getenv("GRPC_TLS_DIR")
+	if dir == "" {
+		dir = "/certs"
+	}
+	cfg := Config{
+		Mode:           os.Getenv("GRPC_TLS_MODE"),
+		CertFile:       filepath.Join(dir, name+".crt"),
+		KeyFile:        filepath.Join(dir, name+".key"),
+		CAFile:         filepath.Join(dir, "ca.crt"),
+		ReloadInterval: time.Minute,
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = "off"
+	}
+	if cfg.Mode != "off" && cfg.Mode != "mtls" {
+		return cfg, fmt.Errorf("mtls: GRPC_TLS_MODE=%q (off | mtls)", cfg.Mode)
+	}
+	if v := os.Getenv("GRPC_TLS_RELOAD_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("mtls: GRPC_TLS_RELOAD_INTERVAL=%q no es una duración válida", v)
+		}
+		cfg.ReloadInterval = d
+	}
+	return cfg, nil
+}
+
+// Setup lee GRPC_TLS_* para el proceso name. Con mTLS activo registra las
+// credenciales de cliente en grpcclient y devuelve las opciones de servidor
+// (credenciales e interceptores de peers); los procesos que solo son clientes
+// las ignoran. Con GRPC_TLS_MODE=off no hace nada. Se llama al inicio de main,
+// antes de abrir conexiones.
+func Setup(name string, peers Peers) ([]grpc.ServerOption, error) {
+	cfg, err := ConfigFromEnv(name)
+	if err != nil || cfg.Mode == "off" {
+		return nil, err
+	}
+	l, err := NewLoader(cfg)
+	if err != nil {
+		return nil, err
+	}
+	grpcclient.SetCredentials(l.ClientCredentials())
+	slog.Info("mtls activo", "name", name, "cert", cfg.CertFile, "ca", cfg.CAFile)
+	return append([]grpc.ServerOption{grpc.Creds(l.ServerCredentials())}, peers.ServerOptions()...), nil
+}
+
+// Loader mantiene el certificado y la CA vigentes y los relee al cambiar.
+type Loader struct {
+	cfg Config
+	now func() time.Time
+
+	mu      sync.Mutex
+	checked time.Time // última revisión de los archivos
+	modTime time.Time // el más reciente de los tres archivos al cargar
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+}
+
+func NewLoader(cfg Config) (*Loader, error) {
+	l := &Loader{cfg: cfg, now: time.Now}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *Loader) load() error {
+	mod, err := l.latestModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(l.cfg.CertFile, l.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("mtls: %w", err)
+	}
+	caPEM, err := os.ReadFile(l.cfg.CAFile)
+	if err != nil {
+		return fmt.Errorf("mtls: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("mtls: %s no contiene certificados", l.cfg.CAFile)
+	}
+	l.cert, l.pool, l.modTime, l.checked = &cert, pool, mod, l.now()
+	return nil
+}
+
+func (l *Loader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range []string{l.cfg.CertFile, l.cfg.KeyFile, l.cfg.CAFile} {
+		st, err := os.Stat(f)
+		if err != nil {
+			return latest, fmt.Errorf("mtls: %w", err)
+		}
+		if st.ModTime().After(latest) {
+			latest = st.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// current devuelve el par vigente; si pasó ReloadInterval revisa los archivos.
+// Una rotación a medio copiar (par inválido) se ignora hasta la siguiente revisión.
+func (l *Loader) current() (*tls.Certificate, *x509.CertPool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.now().Sub(l.checked) >= l.cfg.ReloadInterval {
+		l.checked = l.now()
+		if mod, err := l.latestModTime(); err == nil && mod.After(l.modTime) {
+			if err := l.load(); err != nil {
+				slog.Warn("mtls: no se pudo recargar el certificado", "err", err)
+			} else {
+				slog.Info("mtls: certificado recargado", "cert", l.cfg.CertFile)
+			}
+		}
+	}
+	return l.cert, l.pool
+}
+
+// ServerTLSConfig exige y verifica el certificado del cliente con la CA vigente.
+func (l *Loader) ServerTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := l.current()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    pool,
+			}, nil
+		},
+	}
+}
+
+// ClientTLSConfig presenta el certificado propio y verifica el del servidor
+// contra la CA vigente. La verificación estándar se reemplaza por
+// VerifyConnection porque RootCAs no se puede cambiar después de crear el
+// config; el resultado es el mismo (cadena y nombre del servidor).
+func (l *Loader) ClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, // ver VerifyConnection
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := l.current()
+			return cert, nil
+		},
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("mtls: el servidor no presentó certificado")
+			}
+			_, pool := l.current()
+			opts := x509.VerifyOptions{
+				Roots:         pool,
+				DNSName:       cs.ServerName,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, c := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(c)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}
+}
+
+func (l *Loader) ServerCredentials() credentials.TransportCredentials {
+	return credentials.NewTLS(l.ServerTLSConfig())
+}
+
+func (l *Loader) ClientCredentials() credentials.TransportCredentials {
+	return credentials.NewTLS(l.ClientTLSConfig())
+}
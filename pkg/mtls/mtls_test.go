@@ -0,0 +1,109 @@
+package mtls
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func loader(t *testing.T, dir, name string) *Loader {
+	t.Helper()
+	l, err := NewLoader(Config{
+		Mode:           "mtls",
+		CertFile:       filepath.Join(dir, name+".crt"),
+		KeyFile:        filepath.Join(dir, name+".key"),
+		CAFile:         filepath.Join(dir, "ca.crt"),
+		ReloadInterval: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// serve levanta el health check de gRPC con mTLS como "cart" y devuelve una
+// función que lo consulta con el certificado de name.
+func serve(t *testing.T, dir string, peers Peers) func(name string) error {
+	t.Helper()
+	lis := bufconn.Listen(1 << 16)
+	srv := grpc.NewServer(append([]grpc.ServerOption{grpc.Creds(loader(t, dir, "cart").ServerCredentials())}, peers.ServerOptions()...)...)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return func(name string) error {
+		cc, err := grpc.NewClient("passthrough:///cart",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(loader(t, dir, name).ClientCredentials()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cc.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(cc).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+}
+
+func TestPeersByIdentity(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteDevCerts(dir, []string{"cart", "order", "frontend-cart"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	check := serve(t, dir, Peers{"/grpc.health.v1.Health/": {"order"}})
+
+	if err := check("order"); err != nil {
+		t.Fatalf("order rechazado: %v", err)
+	}
+	if err := check("frontend-cart"); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("frontend-cart = %v, se esperaba PermissionDenied", err)
+	}
+
+	// Un certificado de otra CA no pasa el handshake
+	other := t.TempDir()
+	if err := WriteDevCerts(other, []string{"order"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"order.crt", "order.key"} {
+		b, _ := os.ReadFile(filepath.Join(other, f))
+		os.WriteFile(filepath.Join(dir, f), b, 0o600)
+	}
+	if err := check("order"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("certificado de otra CA = %v, se esperaba Unavailable", err)
+	}
+}
+
+func TestReloadAfterRotation(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteDevCerts(dir, []string{"cart"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	l := loader(t, dir, "cart")
+	before, _ := l.current()
+
+	if err := WriteDevCerts(dir, []string{"cart"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Second)
+	for _, f := range []string{"cart.crt", "cart.key"} {
+		os.Chtimes(filepath.Join(dir, f), future, future)
+	}
+	if c, _ := l.current(); c != before {
+		t.Fatal("se recargó antes de GRPC_TLS_RELOAD_INTERVAL")
+	}
+	l.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if c, _ := l.current(); c == before {
+		t.Fatal("el certificado rotado no se recargó")
+	}
+}
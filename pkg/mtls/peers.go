@@ -0,0 +1,76 @@
+package mtls
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Peers dice qué identidades (CN del certificado) pueden llamar a cada método.
+// La clave es un método completo ("/cart.Cart/CheckoutSnapshot") o un servicio
+// ("/cart.Cart/"); manda la regla del método sobre la del servicio. Un método
+// sin regla acepta a cualquier peer con certificado de la CA, igual que el
+// health check.
+type Peers map[string][]string
+
+func (p Peers) allowed(method string) ([]string, bool) {
+	if ids, ok := p[method]; ok {
+		return ids, true
+	}
+	if i := strings.LastIndex(method, "/"); i > 0 {
+		ids, ok := p[method[:i+1]]
+		return ids, ok
+	}
+	return nil, false
+}
+
+// PeerIdentity devuelve el CN del certificado verificado del cliente, o "".
+func PeerIdentity(ctx context.Context) string {
+	pr, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := pr.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return info.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+func (p Peers) check(ctx context.Context, method string) error {
+	ids, ok := p.allowed(method)
+	if !ok {
+		return nil
+	}
+	if id := PeerIdentity(ctx); !slices.Contains(ids, id) {
+		return status.Errorf(codes.PermissionDenied, "%s no puede llamar a %s", id, method)
+	}
+	return nil
+}
+
+// ServerOptions instala la verificación de peers en RPCs unary y stream.
+func (p Peers) ServerOptions() []grpc.ServerOption {
+	if len(p) == 0 {
+		return nil
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := p.check(ctx, info.FullMethod); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := p.check(ss.Context(), info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+}
@@ -0,0 +1,174 @@
+// Package pricing calcula el desglose de una compra: subtotal, descuentos, IVA
+// por categoría y envío por peso o cantidad según la región de destino.
+//
+// Lo usan Cart (estimado del carrito) y Order (desglose definitivo que queda
+// guardado en la orden), así que ambos deben cargar las mismas reglas.
+package pricing
+
+import (
+	"strings"
+)
+
+// Line es una línea a cotizar. Los precios no incluyen IVA.
+type Line struct {
+	Category    string
+	UnitCents   int64
+	Qty         int32
+	WeightGrams int32 // 0 = usar DefaultItemWeightGrams
+}
+
+// Destination es el destino del envío; nil cotiza con la región por defecto.
+type Destination struct {
+	Region  string
+	Country string
+}
+
+// Breakdown es el resultado de la cotización.
+type Breakdown struct {
+	SubtotalCents   int64
+	DiscountCents   int64
+	TaxCents        int64
+	ShippingCents   int64
+	GrandTotalCents int64
+	LineTaxCents    []int64 // IVA de cada línea, en el mismo orden de entrada
+}
+
+// Quote aplica el pipeline: subtotal → descuento → IVA (sobre la base con
+// descuento) → envío → total.
+func (r *Rules) Quote(lines []Line, dest *Destination) Breakdown {
+	var b Breakdown
+	bases := make([]int64, len(lines))
+	for i, l := range lines {
+		bases[i] = l.UnitCents * int64(l.Qty)
+		b.SubtotalCents += bases[i]
+	}
+
+	b.DiscountCents = r.discount(b.SubtotalCents)
+	allocate(bases, b.DiscountCents)
+
+	b.LineTaxCents = make([]int64, len(lines))
+	for i, l := range lines {
+		b.LineTaxCents[i] = applyBP(bases[i], r.Tax.rateBP(l.Category))
+		b.TaxCents += b.LineTaxCents[i]
+	}
+
+	if len(lines) > 0 {
+		b.ShippingCents = r.Shipping.cost(lines, b.SubtotalCents-b.DiscountCents, dest)
+	}
+	b.GrandTotalCents = b.SubtotalCents - b.DiscountCents + b.TaxCents + b.ShippingCents
+	return b
+}
+
+// discount devuelve el mayor descuento por volumen que aplique al subtotal.
+func (r *Rules) discount(subtotal int64) int64 {
+	var best int64
+	for _, d := range r.Discounts {
+		if subtotal < d.MinSubtotalCents {
+			continue
+		}
+		if v := applyBP(subtotal, d.PercentBP); v > best {
+			best = v
+		}
+	}
+	return best
+}
+
+// NetLines devuelve la base de cada línea después de repartir discount tal como
+// lo hace Quote. Sirve para calcular reembolsos sobre lo que realmente se cobró.
+func NetLines(lineCents []int64, discount int64) []int64 {
+	bases := append([]int64(nil), lineCents...)
+	allocate(bases, discount)
+	return bases
+}
+
+// allocate reparte el descuento entre las líneas en proporción a su base; el
+// residuo de redondeo va a la última línea con base.
+func allocate(bases []int64, discount int64) {
+	if discount <= 0 {
+		return
+	}
+	var total int64
+	last := -1
+	for i, v := range bases {
+		total += v
+		if v > 0 {
+			last = i
+		}
+	}
+	if total == 0 {
+		return
+	}
+	var given int64
+	for i, v := range bases {
+		if i == last {
+			bases[i] -= discount - given
+			break
+		}
+		part := discount * v / total
+		bases[i] -= part
+		given += part
+	}
+}
+
+func (t TaxRules) rateBP(category string) int {
+	c := normalize(category)
+	for _, e := range t.ExemptCategories {
+		if normalize(e) == c {
+			return 0
+		}
+	}
+	for k, bp := range t.CategoryRateBP {
+		if normalize(k) == c {
+			return bp
+		}
+	}
+	return t.DefaultRateBP
+}
+
+func (s ShippingRules) cost(lines []Line, net int64, dest *Destination) int64 {
+	if s.FreeOverCents > 0 && net >= s.FreeOverCents {
+		return 0
+	}
+	rate, ok := s.Regions[normalize(s.DefaultRegion)]
+	if dest != nil {
+		if rr, found := s.Regions[normalize(dest.Region)]; found {
+			rate, ok = rr, true
+		}
+	}
+	if !ok {
+		return 0
+	}
+	cost := rate.BaseCents
+	switch s.Mode {
+	case ModeItems:
+		var items int64
+		for _, l := range lines {
+			items += int64(l.Qty)
+		}
+		cost += rate.PerItemCents * items
+	default:
+		var grams int64
+		for _, l := range lines {
+			w := int64(l.WeightGrams)
+			if w <= 0 {
+				w = int64(s.DefaultItemWeightGrams)
+			}
+			grams += w * int64(l.Qty)
+		}
+		kg := (grams + 999) / 1000 // se cobra por kilo o fracción
+		cost += rate.PerKgCents * kg
+	}
+	return cost
+}
+
+// applyBP aplica basis points (1900 = 19%) redondeando al centavo más cercano.
+func applyBP(cents int64, bp int) int64 {
+	return (cents*int64(bp) + 5000) / 10000
+}
+
+var accents = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u")
+
+// normalize permite escribir "Bogotá" o " bogota " en direcciones y reglas.
+func normalize(s string) string {
+	return accents.Replace(strings.ToLower(strings.TrimSpace(s)))
+}
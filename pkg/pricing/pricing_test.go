@@ -0,0 +1,209 @@
+package pricing
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// withDiscounts son las reglas por defecto más dos tramos de descuento por volumen.
+func withDiscounts() *Rules {
+	r := Default()
+	r.Discounts = []DiscountRule{
+		{MinSubtotalCents: 100000, PercentBP: 500},
+		{MinSubtotalCents: 200000, PercentBP: 1000},
+	}
+	return r
+}
+
+func TestApplyBP(t *testing.T) {
+	for _, tc := range []struct {
+		cents int64
+		bp    int
+		want  int64
+	}{
+		{1000, 1900, 190},
+		{333, 1900, 63}, // 63,27
+		{105, 500, 5},   // 5,25
+		{110, 500, 6},   // 5,5 sube
+		{1, 5000, 1},    // 0,5 sube
+		{1, 4999, 0},
+		{0, 1900, 0},
+		{12345, 0, 0},
+	} {
+		if got := applyBP(tc.cents, tc.bp); got != tc.want {
+			t.Errorf("applyBP(%d, %d) = %d, se esperaba %d", tc.cents, tc.bp, got, tc.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		bases    []int64
+		discount int64
+		want     []int64
+	}{
+		{"sin descuento", []int64{100, 200}, 0, []int64{100, 200}},
+		{"proporcional exacto", []int64{1000, 3000}, 400, []int64{900, 2700}},
+		{"residuo a la última", []int64{100, 100, 100}, 100, []int64{67, 67, 66}},
+		{"última con base cero", []int64{500, 500, 0}, 101, []int64{450, 449, 0}},
+		{"precio cero en medio", []int64{600, 0, 400}, 50, []int64{570, 0, 380}},
+		{"todo en cero", []int64{0, 0}, 50, []int64{0, 0}},
+		{"sin líneas", nil, 50, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			bases := slices.Clone(tc.bases)
+			allocate(bases, tc.discount)
+			if !slices.Equal(bases, tc.want) {
+				t.Fatalf("allocate(%v, %d) = %v, se esperaba %v", tc.bases, tc.discount, bases, tc.want)
+			}
+		})
+	}
+}
+
+func TestNetLines(t *testing.T) {
+	lines := []int64{70000, 33333, 10001}
+	net := NetLines(lines, 5667)
+	if want := []int64{66500, 31667, 9500}; !slices.Equal(net, want) {
+		t.Fatalf("NetLines = %v, se esperaba %v", net, want)
+	}
+	if lines[0] != 70000 {
+		t.Fatalf("NetLines modificó la entrada: %v", lines)
+	}
+	var sum int64
+	for _, v := range net {
+		sum += v
+	}
+	if sum != 70000+33333+10001-5667 {
+		t.Fatalf("las bases netas suman %d", sum)
+	}
+}
+
+func TestRateBP(t *testing.T) {
+	tax := Default().Tax
+	for category, want := range map[string]int{
+		"libros":       0,
+		" Literatura ": 0,
+		"Biografía":    0,
+		"revistas":     500,
+		"REVISTAS":     500,
+		"papeleria":    1900,
+		"":             1900,
+	} {
+		if got := tax.rateBP(category); got != want {
+			t.Errorf("rateBP(%q) = %d, se esperaba %d", category, got, want)
+		}
+	}
+}
+
+func TestShippingCost(t *testing.T) {
+	byItems := ShippingRules{
+		Mode:          ModeItems,
+		DefaultRegion: "local",
+		Regions:       map[string]RegionRate{"local": {BaseCents: 1000, PerItemCents: 200}},
+	}
+	for _, tc := range []struct {
+		name  string
+		rules ShippingRules
+		lines []Line
+		net   int64
+		dest  *Destination
+		want  int64
+	}{
+		{"sin destino usa la región por defecto", Default().Shipping, []Line{{Qty: 2, WeightGrams: 300}}, 50000, nil, 15000},
+		{"región con tilde", Default().Shipping, []Line{{Qty: 2, WeightGrams: 300}}, 50000, &Destination{Region: "Bogotá"}, 7500},
+		{"región desconocida", Default().Shipping, []Line{{Qty: 2, WeightGrams: 300}}, 50000, &Destination{Region: "Amazonas"}, 15000},
+		{"kilo exacto", Default().Shipping, []Line{{Qty: 2, WeightGrams: 500}}, 50000, &Destination{Region: "bogota"}, 7500},
+		{"fracción de kilo", Default().Shipping, []Line{{Qty: 1, WeightGrams: 1001}}, 50000, &Destination{Region: "bogota"}, 9000},
+		{"peso por defecto", Default().Shipping, []Line{{Qty: 3}}, 50000, &Destination{Region: "bogota"}, 9000},
+		{"gratis desde el umbral", Default().Shipping, []Line{{Qty: 1}}, 300000, nil, 0},
+		{"por cantidad", byItems, []Line{{Qty: 3}, {Qty: 2}}, 50000, nil, 2000},
+		{"sin tarifa", ShippingRules{DefaultRegion: "x"}, []Line{{Qty: 1}}, 50000, nil, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rules.cost(tc.lines, tc.net, tc.dest); got != tc.want {
+				t.Fatalf("envío = %d, se esperaba %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		lines []Line
+		dest  *Destination
+		want  Breakdown
+	}{
+		{
+			name: "sin líneas",
+			want: Breakdown{},
+		},
+		{
+			name:  "libros exentos",
+			lines: []Line{{Category: "libros", UnitCents: 25000, Qty: 2, WeightGrams: 400}},
+			dest:  &Destination{Region: "Bogotá"},
+			want:  Breakdown{SubtotalCents: 50000, ShippingCents: 7500, GrandTotalCents: 57500, LineTaxCents: []int64{0}},
+		},
+		{
+			name: "IVA por categoría",
+			lines: []Line{
+				{Category: "libros", UnitCents: 30000, Qty: 2, WeightGrams: 500},
+				{Category: "papeleria", UnitCents: 10000, Qty: 1, WeightGrams: 200},
+				{Category: "revistas", UnitCents: 15000, Qty: 1, WeightGrams: 300},
+			},
+			want: Breakdown{SubtotalCents: 85000, TaxCents: 2650, ShippingCents: 18000, GrandTotalCents: 105650,
+				LineTaxCents: []int64{0, 1900, 750}},
+		},
+		{
+			// 5% de 113334 = 5667: 3500 y 1666 por proporción, 501 de residuo a la última
+			name: "descuento repartido entre líneas",
+			lines: []Line{
+				{Category: "libros", UnitCents: 70000, Qty: 1, WeightGrams: 500},
+				{Category: "papeleria", UnitCents: 33333, Qty: 1, WeightGrams: 500},
+				{Category: "revistas", UnitCents: 10001, Qty: 1},
+			},
+			dest: &Destination{Region: "Cundinamarca"},
+			want: Breakdown{SubtotalCents: 113334, DiscountCents: 5667, TaxCents: 6492, ShippingCents: 12000, GrandTotalCents: 126159,
+				LineTaxCents: []int64{0, 6017, 475}},
+		},
+		{
+			name:  "gana el mayor descuento",
+			lines: []Line{{Category: "libros", UnitCents: 250000, Qty: 1}},
+			want:  Breakdown{SubtotalCents: 250000, DiscountCents: 25000, ShippingCents: 15000, GrandTotalCents: 240000, LineTaxCents: []int64{0}},
+		},
+		{
+			// El umbral de envío gratis se mide después del descuento
+			name:  "envío sobre la base con descuento",
+			lines: []Line{{Category: "libros", UnitCents: 150000, Qty: 2}},
+			want:  Breakdown{SubtotalCents: 300000, DiscountCents: 30000, ShippingCents: 15000, GrandTotalCents: 285000, LineTaxCents: []int64{0}},
+		},
+		{
+			name:  "envío gratis",
+			lines: []Line{{Category: "libros", UnitCents: 400000, Qty: 1}},
+			want:  Breakdown{SubtotalCents: 400000, DiscountCents: 40000, GrandTotalCents: 360000, LineTaxCents: []int64{0}},
+		},
+		{
+			name: "cantidad y precio en cero",
+			lines: []Line{
+				{Category: "libros", UnitCents: 20000, Qty: 0, WeightGrams: 400},
+				{Category: "papeleria", UnitCents: 0, Qty: 3, WeightGrams: 100},
+				{Category: "papeleria", UnitCents: 10000, Qty: 1, WeightGrams: 100},
+			},
+			want: Breakdown{SubtotalCents: 10000, TaxCents: 1900, ShippingCents: 15000, GrandTotalCents: 26900,
+				LineTaxCents: []int64{0, 0, 1900}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := withDiscounts().Quote(tc.lines, tc.dest)
+			if !slices.Equal(got.LineTaxCents, tc.want.LineTaxCents) {
+				t.Fatalf("IVA por línea = %v, se esperaba %v", got.LineTaxCents, tc.want.LineTaxCents)
+			}
+			got.LineTaxCents, tc.want.LineTaxCents = nil, nil
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("Quote = %+v\nse esperaba %+v", got, tc.want)
+			}
+		})
+	}
+}
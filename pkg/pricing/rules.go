@@ -0,0 +1,95 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Modos de cálculo del envío
+const (
+	ModeWeight = "weight"
+	ModeItems  = "items"
+)
+
+// Rules son las reglas configurables de precios (ver Load para el formato JSON).
+type Rules struct {
+	Tax       TaxRules       `json:"tax"`
+	Shipping  ShippingRules  `json:"shipping"`
+	Discounts []DiscountRule `json:"discounts"`
+}
+
+// TaxRules: IVA en basis points (1900 = 19%) por categoría del libro.
+type TaxRules struct {
+	DefaultRateBP    int            `json:"default_rate_bp"`
+	CategoryRateBP   map[string]int `json:"category_rate_bp"`
+	ExemptCategories []string       `json:"exempt_categories"`
+}
+
+type ShippingRules struct {
+	Mode                   string                `json:"mode"` // "weight" | "items"
+	FreeOverCents          int64                 `json:"free_over_cents"`
+	DefaultRegion          string                `json:"default_region"`
+	DefaultItemWeightGrams int32                 `json:"default_item_weight_grams"`
+	Regions                map[string]RegionRate `json:"regions"`
+}
+
+type RegionRate struct {
+	BaseCents    int64 `json:"base_cents"`
+	PerKgCents   int64 `json:"per_kg_cents"`
+	PerItemCents int64 `json:"per_item_cents"`
+}
+
+// DiscountRule: descuento por volumen sobre el subtotal.
+type DiscountRule struct {
+	MinSubtotalCents int64 `json:"min_subtotal_cents"`
+	PercentBP        int   `json:"percent_bp"`
+}
+
+// Default son las reglas de Colombia: IVA general del 19%, libros exentos y
+// envío por peso con tarifa menor dentro de Bogotá.
+func Default() *Rules {
+	return &Rules{
+		Tax: TaxRules{
+			DefaultRateBP:    1900,
+			CategoryRateBP:   map[string]int{"revistas": 500},
+			ExemptCategories: []string{"libros", "literatura", "biografia", "tecnologia", "ciencia", "infantil", "academico", "desarrollo-personal"},
+		},
+		Shipping: ShippingRules{
+			Mode:                   ModeWeight,
+			FreeOverCents:          300000,
+			DefaultRegion:          "nacional",
+			DefaultItemWeightGrams: 500,
+			Regions: map[string]RegionRate{
+				"bogota":       {BaseCents: 6000, PerKgCents: 1500},
+				"cundinamarca": {BaseCents: 8000, PerKgCents: 2000},
+				"nacional":     {BaseCents: 12000, PerKgCents: 3000},
+			},
+		},
+	}
+}
+
+// Load lee las reglas de un archivo JSON con la forma de Rules; path vacío
+// devuelve Default().
+func Load(path string) (*Rules, error) {
+	if path == "" {
+		return Default(), nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("pricing: %w", err)
+	}
+	var r Rules
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("pricing: %s: %w", path, err)
+	}
+	regions := make(map[string]RegionRate, len(r.Shipping.Regions))
+	for k, v := range r.Shipping.Regions {
+		regions[normalize(k)] = v
+	}
+	r.Shipping.Regions = regions
+	if r.Shipping.Mode == "" {
+		r.Shipping.Mode = ModeWeight
+	}
+	return &r, nil
+}
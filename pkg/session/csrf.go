@@ -0,0 +1,167 @@
+package session
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"mime"
+	"net/http"
+)
+
+// Protección CSRF con double submit firmado: cada navegador recibe una cookie
+// aleatoria (<SESSION_COOKIE>_csrf) y los formularios envían csrf_token =
+// HMAC(clave derivada de SESSION_SECRET, cookie|user_id). El token queda atado
+// a la sesión: la cookie se renueva al iniciar y al cerrar sesión, y un token
+// de otro navegador o de otro usuario no sirve. La clave es común a todos los
+// frontends, así un formulario del catálogo puede enviar a /cart/add.
+//
+// Las peticiones con cuerpo JSON no llevan token: un sitio ajeno solo puede
+// enviarlas con CORS, que el storefront no habilita.
+
+const (
+	csrfFormField = "csrf_token"
+	csrfHeader    = "X-CSRF-Token" // para peticiones hechas con fetch
+
+	// maxFormBytes limita el cuerpo de los formularios; ninguno pasa de unos KB
+	maxFormBytes = 1 << 20
+)
+
+type csrfCtxKey struct{}
+
+func csrfKeys(current string, previous []string) [][]byte {
+	var keys [][]byte
+	for _, secret := range append([]string{current}, previous...) {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("mybookstore-csrf"))
+		keys = append(keys, mac.Sum(nil))
+	}
+	return keys
+}
+
+func csrfToken(key []byte, nonce string, userID int64) string {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s|%d", nonce, userID)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func newNonce() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand no falla en las plataformas soportadas
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// safeMethod indica los métodos que no cambian estado y no llevan token.
+func safeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// jsonBody indica si la petición declara un cuerpo application/json.
+func jsonBody(r *http.Request) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mt == "application/json"
+}
+
+// csrf asegura la cookie del navegador, deja el token de la petición en el
+// contexto y, si el método cambia estado, exige que el token venga en el
+// formulario o en el header. Devuelve false si ya respondió 403.
+func (m *Manager) csrf(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	nonce := ""
+	if c, err := r.Cookie(m.csrfCookieName()); err == nil && len(c.Value) >= 32 {
+		nonce = c.Value
+	}
+	fresh := nonce == ""
+	if fresh {
+		nonce = newNonce()
+		m.setCSRFCookie(w, nonce)
+	}
+	uid := UserID(r)
+	r = r.WithContext(context.WithValue(r.Context(), csrfCtxKey{}, csrfToken(m.csrfKeys[0], nonce, uid)))
+	if safeMethod(r.Method) || jsonBody(r) {
+		return r, true
+	}
+	if !fresh && m.validCSRF(w, r, nonce, uid) {
+		return r, true
+	}
+	slog.WarnContext(r.Context(), "session: token CSRF inválido", "method", r.Method, "path", r.URL.Path)
+	http.Error(w, "El formulario venció o no viene de este sitio; recarga la página e intenta de nuevo", http.StatusForbidden)
+	return r, false
+}
+
+func (m *Manager) validCSRF(w http.ResponseWriter, r *http.Request, nonce string, uid int64) bool {
+	got := r.Header.Get(csrfHeader)
+	if got == "" {
+		r.Body = http.MaxBytesReader(w, r.Body, maxFormBytes)
+		got = r.PostFormValue(csrfFormField)
+	}
+	if got == "" {
+		return false
+	}
+	// Las claves anteriores cubren los formularios abiertos durante una rotación de SESSION_SECRET
+	for _, key := range m.csrfKeys {
+		if hmac.Equal([]byte(got), []byte(csrfToken(key, nonce, uid))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) csrfCookieName() string { return m.cfg.CookieName + "_csrf" }
+
+// setCSRFCookie entrega una cookie de navegador (sin Expires): dura lo que la
+// sesión del navegador y se renueva en Login y Logout.
+func (m *Manager) setCSRFCookie(w http.ResponseWriter, nonce string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.csrfCookieName(),
+		Value:    nonce,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   m.cfg.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// CSRFToken devuelve el token que los formularios de r deben enviar en
+// csrf_token (o en el header X-CSRF-Token).
+func CSRFToken(r *http.Request) string {
+	t, _ := r.Context().Value(csrfCtxKey{}).(string)
+	return t
+}
+
+// CSRFField devuelve el input oculto con el token de r.
+func CSRFField(r *http.Request) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + csrfFormField + `" value="` +
+		template.HTMLEscapeString(CSRFToken(r)) + `">`)
+}
+
+// TemplateFuncs registra {{csrfField}} en las plantillas. Con r nil sirve para
+// parsearlas al arrancar; Bind lo ata a cada petición.
+func TemplateFuncs(r *http.Request) template.FuncMap {
+	return template.FuncMap{"csrfField": func() template.HTML {
+		if r == nil {
+			return ""
+		}
+		return CSRFField(r)
+	}}
+}
+
+// Bind devuelve una copia de t cuyo {{csrfField}} escribe el token de r. t debe
+// parsearse con TemplateFuncs(nil) y ejecutarse siempre a través de Bind
+// (html/template no clona plantillas ya ejecutadas).
+func Bind(t *template.Template, r *http.Request) (*template.Template, error) {
+	c, err := t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return c.Funcs(TemplateFuncs(r)), nil
+}
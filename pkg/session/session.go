@@ -0,0 +1,421 @@
+// Package session reemplaza la cookie uid de los frontends por sesiones firmadas.
+//
+// Al iniciar sesión el frontend de usuario abre una sesión en el servicio User
+// (Manager.Login) y entrega un token firmado (JWT HS256, ver token.go) en una
+// cookie HttpOnly, Secure y SameSite=Lax. Todos los frontends lo verifican con
+// Manager.Middleware: firma y vencimiento se validan localmente y la sesión se
+// consulta al servicio User, con una caché de SESSION_CHECK_INTERVAL, para que
+// un logout o una revocación se respeten en todos. Los handlers leen al usuario
+// con UserID(r) y UserName(r); nunca de la cookie ni de un campo del formulario.
+// El middleware también deja el auth.Principal del usuario en el contexto, con
+// el que pkg/auth firma las llamadas gRPC a los servicios.
+//
+// El middleware también protege los formularios contra CSRF: emite un token
+// por sesión (CSRFToken, {{csrfField}} en las plantillas) y lo exige en los
+// métodos que cambian estado.
+//
+// Los clientes sin cookies (la API /api/v1, apps móviles) reciben el mismo token
+// con IssueToken y lo envían en "Authorization: Bearer <token>". Esas peticiones
+// no pasan por el CSRF ni se rotan: el token vale hasta SESSION_TTL.
+//
+// Cada SESSION_ROTATE_AFTER el middleware rota la sesión (id y token nuevos).
+// Las claves de firma se rotan moviendo SESSION_SECRET a SESSION_PREVIOUS_SECRETS.
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+	userpb "github.com/ahinestrog/mybookstore/proto/gen/user"
+)
+
+// Session es la sesión verificada de la petición.
+type Session struct {
+	ID        string
+	UserID    int64
+	Name      string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+type Config struct {
+	Secret          string        // SESSION_SECRET: firma los tokens nuevos (mínimo 32 caracteres)
+	PreviousSecrets []string      // SESSION_PREVIOUS_SECRETS: solo verifican, para rotar la clave
+	TTL             time.Duration // SESSION_TTL: vigencia de la sesión
+	RotateAfter     time.Duration // SESSION_ROTATE_AFTER: antigüedad a partir de la cual se rota
+	CheckInterval   time.Duration // SESSION_CHECK_INTERVAL: caché de la consulta al servicio User
+	CookieName      string        // SESSION_COOKIE
+	Secure          bool          // SESSION_COOKIE_SECURE; false solo para desarrollo sin HTTPS
+}
+
+const minSecretLen = 32
+
+// ConfigFromEnv lee la configuración común a todos los frontends.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{
+		Secret:        os.Getenv("SESSION_SECRET"),
+		TTL:           7 * 24 * time.Hour,
+		RotateAfter:   time.Hour,
+		CheckInterval: 30 * time.Second,
+		CookieName:    "mbs_session",
+		Secure:        os.Getenv("SESSION_COOKIE_SECURE") != "false",
+	}
+	for _, s := range strings.Split(os.Getenv("SESSION_PREVIOUS_SECRETS"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			cfg.PreviousSecrets = append(cfg.PreviousSecrets, s)
+		}
+	}
+	if v := os.Getenv("SESSION_COOKIE"); v != "" {
+		cfg.CookieName = v
+	}
+	for _, d := range []struct {
+		env string
+		dst *time.Duration
+	}{
+		{"SESSION_TTL", &cfg.TTL},
+		{"SESSION_ROTATE_AFTER", &cfg.RotateAfter},
+		{"SESSION_CHECK_INTERVAL", &cfg.CheckInterval},
+	} {
+		v := os.Getenv(d.env)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed <= 0 {
+			return cfg, fmt.Errorf("session: %s=%q no es una duración válida", d.env, v)
+		}
+		*d.dst = parsed
+	}
+	return cfg, cfg.validate()
+}
+
+func (c Config) validate() error {
+	if len(c.Secret) < minSecretLen {
+		return fmt.Errorf("session: SESSION_SECRET requerido (mínimo %d caracteres)", minSecretLen)
+	}
+	for _, s := range c.PreviousSecrets {
+		if len(s) < minSecretLen {
+			return fmt.Errorf("session: SESSION_PREVIOUS_SECRETS: cada clave necesita al menos %d caracteres", minSecretLen)
+		}
+	}
+	if c.RotateAfter >= c.TTL {
+		return fmt.Errorf("session: SESSION_ROTATE_AFTER (%s) debe ser menor que SESSION_TTL (%s)", c.RotateAfter, c.TTL)
+	}
+	return nil
+}
+
+// Manager emite y verifica las sesiones de un frontend.
+type Manager struct {
+	cfg      Config
+	keys     keyring
+	csrfKeys [][]byte // la primera firma; todas verifican
+	store    Store
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cached // sid → resultado de la última consulta al Store
+}
+
+type cached struct {
+	s     *Session // nil = revocada
+	until time.Time
+}
+
+func New(cfg Config, store Store) (*Manager, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &Manager{
+		cfg:      cfg,
+		keys:     newKeyring(cfg.Secret, cfg.PreviousSecrets),
+		csrfKeys: csrfKeys(cfg.Secret, cfg.PreviousSecrets),
+		store:    store,
+		now:      time.Now,
+		cache:    map[string]cached{},
+	}, nil
+}
+
+// NewFromEnv es New con ConfigFromEnv y las sesiones en el servicio User de userAddr.
+func NewFromEnv(userAddr string) (*Manager, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	cc, err := grpcclient.Conn(userAddr)
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg, UserStore{Client: userpb.NewUserClient(cc)})
+}
+
+type ctxKey struct{}
+
+// FromContext devuelve la sesión verificada por Middleware, o nil.
+func FromContext(ctx context.Context) *Session {
+	s, _ := ctx.Value(ctxKey{}).(*Session)
+	return s
+}
+
+// UserID devuelve el usuario de la sesión, o 0 si la petición es anónima.
+func UserID(r *http.Request) int64 {
+	if s := FromContext(r.Context()); s != nil {
+		return s.UserID
+	}
+	return 0
+}
+
+// UserName devuelve el nombre mostrado del usuario de la sesión.
+func UserName(r *http.Request) string {
+	if s := FromContext(r.Context()); s != nil {
+		return s.Name
+	}
+	return ""
+}
+
+// Middleware verifica la cookie de sesión y deja la sesión en el contexto. Una
+// cookie inválida o revocada se borra y la petición sigue como anónima. Las
+// peticiones que cambian estado (POST, PUT, PATCH, DELETE) sin un token CSRF
+// válido se rechazan con 403 (ver csrf.go).
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, viaHeader := bearerToken(r)
+		var s *Session
+		if viaHeader {
+			s = m.headerSession(r, token)
+		} else {
+			s = m.session(w, r)
+		}
+		if s != nil {
+			// El Principal viaja en las llamadas gRPC que el handler haga con r.Context()
+			ctx := auth.NewContext(context.WithValue(r.Context(), ctxKey{}, s), auth.User(s.UserID))
+			r = r.WithContext(ctx)
+		}
+		// Un sitio ajeno no puede agregar el header Authorization a una petición
+		// del navegador: el CSRF solo protege a las sesiones por cookie
+		if !viaHeader {
+			var ok bool
+			if r, ok = m.csrf(w, r); !ok {
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken lee el token de "Authorization: Bearer <token>".
+func bearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token), ok
+}
+
+// headerSession verifica el token del header Authorization, o devuelve nil.
+func (m *Manager) headerSession(r *http.Request, token string) *Session {
+	s, _, err := m.verify(r.Context(), token)
+	if err != nil {
+		if !errors.Is(err, errToken) && !errors.Is(err, ErrRevoked) {
+			slog.WarnContext(r.Context(), "session: verificación falló", "err", err)
+		}
+		return nil
+	}
+	return s
+}
+
+// session devuelve la sesión verificada de la cookie (rotándola si toca), o nil.
+func (m *Manager) session(w http.ResponseWriter, r *http.Request) *Session {
+	c, err := r.Cookie(m.cfg.CookieName)
+	if err != nil {
+		return nil
+	}
+	s, replaced, err := m.verify(r.Context(), c.Value)
+	switch {
+	case errors.Is(err, errToken), errors.Is(err, ErrRevoked):
+		m.clearCookie(w)
+		return nil
+	case err != nil:
+		// El servicio User no respondió: sin sesión esta vez, pero la cookie se conserva
+		slog.WarnContext(r.Context(), "session: verificación falló", "err", err)
+		return nil
+	}
+	if replaced {
+		// Otra petición con la misma cookie ya rotó la sesión: esta también
+		// entrega la cookie nueva en vez de cerrar la sesión
+		if err := m.setCookie(w, s); err != nil {
+			slog.WarnContext(r.Context(), "session: rotación falló", "err", err)
+		}
+		return s
+	}
+	if m.now().Sub(s.IssuedAt) >= m.cfg.RotateAfter {
+		s = m.rotate(r.Context(), w, s)
+	}
+	return s
+}
+
+// verify valida el token y consulta la sesión. replaced indica que la sesión
+// se rotó hace poco en otra petición y s es su reemplazo.
+func (m *Manager) verify(ctx context.Context, token string) (s *Session, replaced bool, err error) {
+	now := m.now()
+	s, err = m.keys.verify(token, now)
+	if err != nil {
+		return nil, false, err
+	}
+	m.mu.Lock()
+	hit, ok := m.cache[s.ID]
+	m.mu.Unlock()
+	if !ok || now.After(hit.until) {
+		stored, err := m.store.Check(ctx, s.ID)
+		if err != nil && !errors.Is(err, ErrRevoked) {
+			return nil, false, err
+		}
+		hit = cached{s: stored, until: now.Add(m.cfg.CheckInterval)}
+		m.remember(s.ID, hit)
+	}
+	if hit.s == nil || hit.s.UserID != s.UserID {
+		return nil, false, ErrRevoked
+	}
+	if hit.s.ID != s.ID {
+		next := *hit.s
+		return &next, true, nil
+	}
+	// El nombre viene del servicio User por si cambió después de firmar el token
+	s.Name = hit.s.Name
+	return s, false, nil
+}
+
+// rotate reemplaza la sesión; si el servicio User falla se sigue con la actual
+// y se reintenta en la próxima petición.
+func (m *Manager) rotate(ctx context.Context, w http.ResponseWriter, s *Session) *Session {
+	next, err := m.store.Rotate(ctx, s.ID, m.cfg.TTL)
+	if err != nil {
+		slog.WarnContext(ctx, "session: rotación falló", "err", err)
+		return s
+	}
+	if err := m.setCookie(w, next); err != nil {
+		slog.WarnContext(ctx, "session: rotación falló", "err", err)
+		return s
+	}
+	m.forget(s.ID)
+	m.remember(next.ID, cached{s: next, until: m.now().Add(m.cfg.CheckInterval)})
+	return next
+}
+
+// Login abre una sesión para el usuario ya autenticado y entrega la cookie.
+func (m *Manager) Login(ctx context.Context, w http.ResponseWriter, userID int64) (*Session, error) {
+	s, err := m.store.Create(ctx, userID, m.cfg.TTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.setCookie(w, s); err != nil {
+		return nil, err
+	}
+	// Cookie CSRF nueva: los tokens anónimos de antes del login dejan de servir
+	m.setCSRFCookie(w, newNonce())
+	clearLegacyCookies(w)
+	return s, nil
+}
+
+// IssueToken abre una sesión para el usuario ya autenticado y devuelve el token
+// que el cliente enviará en "Authorization: Bearer".
+func (m *Manager) IssueToken(ctx context.Context, userID int64) (*Session, string, error) {
+	s, err := m.store.Create(ctx, userID, m.cfg.TTL)
+	if err != nil {
+		return nil, "", err
+	}
+	token, err := m.keys.sign(s)
+	if err != nil {
+		return nil, "", err
+	}
+	return s, token, nil
+}
+
+// Revoke cierra la sesión s en todos los frontends.
+func (m *Manager) Revoke(ctx context.Context, s *Session) error {
+	m.forget(s.ID)
+	return m.store.Revoke(ctx, s.ID)
+}
+
+// Logout revoca la sesión de la petición (si la hay) y borra la cookie.
+func (m *Manager) Logout(w http.ResponseWriter, r *http.Request) error {
+	m.clearCookie(w)
+	m.setCSRFCookie(w, newNonce())
+	clearLegacyCookies(w)
+	s := FromContext(r.Context())
+	if s == nil {
+		if c, err := r.Cookie(m.cfg.CookieName); err == nil {
+			s, _ = m.keys.verify(c.Value, m.now())
+		}
+	}
+	if s == nil {
+		return nil
+	}
+	return m.Revoke(r.Context(), s)
+}
+
+func (m *Manager) setCookie(w http.ResponseWriter, s *Session) error {
+	token, err := m.keys.sign(s)
+	if err != nil {
+		return err
+	}
+	// Lax y no Strict: al volver del banco (PSE) la navegación viene de otro sitio
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  s.ExpiresAt,
+		MaxAge:   int(s.ExpiresAt.Sub(m.now()) / time.Second),
+		HttpOnly: true,
+		Secure:   m.cfg.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func (m *Manager) clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.cfg.CookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   m.cfg.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearLegacyCookies borra las cookies uid/uname de la versión anterior.
+func clearLegacyCookies(w http.ResponseWriter) {
+	for _, name := range []string{"uid", "uname"} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1})
+	}
+}
+
+// maxCached limita la caché; al llenarse se descartan las entradas vencidas.
+const maxCached = 10000
+
+func (m *Manager) remember(sid string, c cached) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.cache) >= maxCached {
+		now := m.now()
+		for k, v := range m.cache {
+			if now.After(v.until) {
+				delete(m.cache, k)
+			}
+		}
+	}
+	m.cache[sid] = c
+}
+
+func (m *Manager) forget(sid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.cache, sid)
+}
@@ -0,0 +1,359 @@
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore es el servicio User en memoria. replaced guarda las sesiones rotadas
+// que siguen dentro de la ventana de gracia (sid anterior → reemplazo).
+type memStore struct {
+	mu       sync.Mutex
+	next     int
+	active   map[string]*Session
+	replaced map[string]string
+	checks   int
+}
+
+func newMemStore() *memStore {
+	return &memStore{active: map[string]*Session{}, replaced: map[string]string{}}
+}
+
+func (s *memStore) Create(ctx context.Context, userID int64, ttl time.Duration) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	now := time.Now()
+	ses := &Session{ID: fmt.Sprintf("sid-%d", s.next), UserID: userID, Name: "Ana", IssuedAt: now, ExpiresAt: now.Add(ttl)}
+	s.active[ses.ID] = ses
+	return ses, nil
+}
+
+func (s *memStore) Check(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checks++
+	if next, ok := s.replaced[id]; ok {
+		id = next
+	}
+	if ses, ok := s.active[id]; ok {
+		return ses, nil
+	}
+	return nil, ErrRevoked
+}
+
+func (s *memStore) Rotate(ctx context.Context, id string, ttl time.Duration) (*Session, error) {
+	s.mu.Lock()
+	if next, ok := s.replaced[id]; ok {
+		defer s.mu.Unlock()
+		if ses, ok := s.active[next]; ok {
+			return ses, nil
+		}
+		return nil, ErrRevoked
+	}
+	old, ok := s.active[id]
+	delete(s.active, id)
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrRevoked
+	}
+	next, err := s.Create(ctx, old.UserID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.replaced[id] = next.ID
+	s.mu.Unlock()
+	return next, nil
+}
+
+// expireGrace cierra la ventana de gracia de las sesiones rotadas.
+func (s *memStore) expireGrace() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.replaced)
+}
+
+func (s *memStore) Revoke(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, id)
+	delete(s.replaced, id)
+	return nil
+}
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestManager(t *testing.T, store Store, secret string, previous ...string) *Manager {
+	t.Helper()
+	m, err := New(Config{
+		Secret:          secret,
+		PreviousSecrets: previous,
+		TTL:             24 * time.Hour,
+		RotateAfter:     time.Hour,
+		CheckInterval:   time.Minute,
+		CookieName:      "mbs_session",
+		Secure:          true,
+	}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// serve pasa la petición por el middleware y devuelve el usuario que vio el handler.
+func serve(m *Manager, cookie *http.Cookie) (int64, *httptest.ResponseRecorder) {
+	var uid int64
+	h := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { uid = UserID(r) }))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return uid, rec
+}
+
+func sessionCookie(t *testing.T, rec *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "mbs_session" && c.Value != "" {
+			return c
+		}
+	}
+	t.Fatal("no se entregó la cookie de sesión")
+	return nil
+}
+
+func TestLoginCookieAndForgedTokens(t *testing.T) {
+	m := newTestManager(t, newMemStore(), testSecret)
+	rec := httptest.NewRecorder()
+	if _, err := m.Login(context.Background(), rec, 5); err != nil {
+		t.Fatal(err)
+	}
+	c := sessionCookie(t, rec)
+	if !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("cookie sin HttpOnly/Secure/SameSite: %+v", c)
+	}
+	if uid, _ := serve(m, c); uid != 5 {
+		t.Fatalf("usuario = %d, se esperaba 5", uid)
+	}
+
+	// Cambiar el usuario en los claims invalida la firma
+	parts := strings.Split(c.Value, ".")
+	forged := *c
+	forged.Value = parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"6","sid":"sid-1","iat":1,"exp":9999999999}`)) + "." + parts[2]
+	for name, bad := range map[string]*http.Cookie{
+		"claims alterados": &forged,
+		"cookie uid":       {Name: "uid", Value: "5"},
+		"otra clave":       sessionCookie(t, loginWith(t, newTestManager(t, newMemStore(), strings.Repeat("x", 32)))),
+	} {
+		if uid, _ := serve(m, bad); uid != 0 {
+			t.Errorf("%s: se aceptó al usuario %d", name, uid)
+		}
+	}
+}
+
+func loginWith(t *testing.T, m *Manager) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	if _, err := m.Login(context.Background(), rec, 5); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestLogoutRevokesEverywhere(t *testing.T) {
+	store := newMemStore()
+	login := newTestManager(t, store, testSecret)
+	other := newTestManager(t, store, testSecret) // otro frontend
+	c := sessionCookie(t, loginWith(t, login))
+	if uid, _ := serve(other, c); uid != 5 {
+		t.Fatalf("usuario = %d en el otro frontend", uid)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(c)
+	if err := login.Logout(httptest.NewRecorder(), req); err != nil {
+		t.Fatal(err)
+	}
+	// El otro frontend lo nota cuando vence su caché
+	other.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if uid, rec := serve(other, c); uid != 0 || rec.Result().Cookies()[0].MaxAge >= 0 {
+		t.Fatalf("sesión revocada aceptada (usuario %d) o cookie no borrada", uid)
+	}
+}
+
+func TestRotationAndKeyRotation(t *testing.T) {
+	store := newMemStore()
+	m := newTestManager(t, store, testSecret)
+	c := sessionCookie(t, loginWith(t, m))
+
+	// Pasada una hora la sesión se rota: cookie nueva y la anterior queda revocada
+	m.now = func() time.Time { return time.Now().Add(61 * time.Minute) }
+	uid, rec := serve(m, c)
+	rotated := sessionCookie(t, rec)
+	if uid != 5 || rotated.Value == c.Value {
+		t.Fatalf("usuario = %d; la cookie no rotó", uid)
+	}
+	store.expireGrace()
+	if _, err := store.Check(context.Background(), "sid-1"); err != ErrRevoked {
+		t.Fatalf("la sesión anterior sigue activa: %v", err)
+	}
+
+	// Nueva clave de firma con la anterior en SESSION_PREVIOUS_SECRETS
+	m2 := newTestManager(t, store, strings.Repeat("n", 32), testSecret)
+	m2.now = m.now
+	if uid, _ := serve(m2, rotated); uid != 5 {
+		t.Fatalf("token firmado con la clave anterior rechazado (usuario %d)", uid)
+	}
+}
+
+// Dos peticiones con la misma cookie durante la rotación: la segunda llega a
+// otro frontend (o cuando la caché ya venció) con la sesión anterior revocada.
+func TestRotationWithParallelRequests(t *testing.T) {
+	store := newMemStore()
+	a := newTestManager(t, store, testSecret)
+	b := newTestManager(t, store, testSecret)
+	c := sessionCookie(t, loginWith(t, a))
+	later := func() time.Time { return time.Now().Add(61 * time.Minute) }
+	a.now, b.now = later, later
+
+	uid, rec := serve(a, c)
+	rotated := sessionCookie(t, rec)
+	if uid != 5 || rotated.Value == c.Value {
+		t.Fatalf("usuario = %d; la cookie no rotó", uid)
+	}
+	for name, m := range map[string]*Manager{"mismo frontend": a, "otro frontend": b} {
+		uid, rec := serve(m, c)
+		if uid != 5 {
+			t.Fatalf("%s: la cookie anterior cerró la sesión (usuario %d)", name, uid)
+		}
+		if got := sessionCookie(t, rec); got.MaxAge <= 0 {
+			t.Fatalf("%s: se borró la cookie en vez de entregar el reemplazo", name)
+		}
+		if got, _ := b.keys.verify(sessionCookie(t, rec).Value, later()); got == nil || got.ID != "sid-2" {
+			t.Fatalf("%s: la cookie entregada no es la del reemplazo: %+v", name, got)
+		}
+	}
+	// Rotar otra vez la misma sesión devuelve el mismo reemplazo
+	if s, err := store.Rotate(context.Background(), "sid-1", time.Hour); err != nil || s.ID != "sid-2" {
+		t.Fatalf("segunda rotación = %+v, %v; quiero sid-2", s, err)
+	}
+
+	// Vencida la gracia la cookie anterior ya no sirve
+	store.expireGrace()
+	fresh := newTestManager(t, store, testSecret) // sin caché
+	fresh.now = later
+	if uid, _ := serve(fresh, c); uid != 0 {
+		t.Fatalf("la cookie anterior sigue valiendo pasada la gracia (usuario %d)", uid)
+	}
+	if uid, _ := serve(fresh, rotated); uid != 5 {
+		t.Fatalf("la cookie rotada no sirve (usuario %d)", uid)
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	m := newTestManager(t, newMemStore(), testSecret)
+	login := loginWith(t, m)
+	c := sessionCookie(t, login)
+	var nonce *http.Cookie
+	for _, ck := range login.Result().Cookies() {
+		if ck.Name == "mbs_session_csrf" {
+			nonce = ck
+		}
+	}
+	if nonce == nil {
+		t.Fatal("Login no renovó la cookie CSRF")
+	}
+
+	// El token se lee en un GET, como al renderizar el formulario
+	var token string
+	h := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { token = CSRFToken(r) }))
+	req := httptest.NewRequest(http.MethodGet, "/cart/", nil)
+	req.AddCookie(c)
+	req.AddCookie(nonce)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	anon := csrfToken(m.csrfKeys[0], nonce.Value, 0)
+	if token == "" || token == anon {
+		t.Fatalf("token = %q; debe existir y depender del usuario", token)
+	}
+
+	post := func(form string, cookies ...*http.Cookie) int {
+		req := httptest.NewRequest(http.MethodPost, "/add", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, ck := range cookies {
+			req.AddCookie(ck)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	for name, tc := range map[string]struct {
+		form    string
+		cookies []*http.Cookie
+		want    int
+	}{
+		"token válido":         {"book_id=1&csrf_token=" + token, []*http.Cookie{c, nonce}, http.StatusOK},
+		"sin token":            {"book_id=1", []*http.Cookie{c, nonce}, http.StatusForbidden},
+		"token de anónimo":     {"book_id=1&csrf_token=" + anon, []*http.Cookie{c, nonce}, http.StatusForbidden},
+		"sin cookie CSRF":      {"book_id=1&csrf_token=" + token, []*http.Cookie{c}, http.StatusForbidden},
+		"otra cookie CSRF":     {"book_id=1&csrf_token=" + token, []*http.Cookie{c, {Name: "mbs_session_csrf", Value: newNonce()}}, http.StatusForbidden},
+		"otra clave de sesión": {"book_id=1&csrf_token=" + csrfToken(csrfKeys(strings.Repeat("x", 32), nil)[0], nonce.Value, 5), []*http.Cookie{c, nonce}, http.StatusForbidden},
+	} {
+		if got := post(tc.form, tc.cookies...); got != tc.want {
+			t.Errorf("%s: status %d, se esperaba %d", name, got, tc.want)
+		}
+	}
+
+	// Un cuerpo JSON no se puede enviar desde otro sitio sin CORS
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/users/5/cart/items", strings.NewReader(`{"book_id":1}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.AddCookie(c)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("POST JSON: status %d", rec.Code)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	m := newTestManager(t, newMemStore(), testSecret)
+	s, token, err := m.IssueToken(context.Background(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var uid int64
+	h := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { uid = UserID(r) }))
+	call := func(method, auth string) *httptest.ResponseRecorder {
+		uid = 0
+		req := httptest.NewRequest(method, "/api/v1/users/5/cart/items", strings.NewReader(`{"book_id":1}`))
+		req.Header.Set("Authorization", auth)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Sin cookies: ni de sesión ni de CSRF, y el POST no exige token CSRF
+	rec := call(http.MethodPost, "Bearer "+token)
+	if rec.Code != http.StatusOK || uid != 5 || len(rec.Result().Cookies()) != 0 {
+		t.Fatalf("status %d, usuario %d, cookies %v", rec.Code, uid, rec.Result().Cookies())
+	}
+	if rec := call(http.MethodPost, "Bearer "+token+"x"); rec.Code != http.StatusOK || uid != 0 {
+		t.Fatalf("token alterado aceptado: status %d, usuario %d", rec.Code, uid)
+	}
+
+	if err := m.Revoke(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+	if call(http.MethodGet, "Bearer "+token); uid != 0 {
+		t.Fatalf("token revocado aceptado (usuario %d)", uid)
+	}
+}
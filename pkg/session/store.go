@@ -0,0 +1,94 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+	commonpb "github.com/ahinestrog/mybookstore/proto/gen/common"
+	userpb "github.com/ahinestrog/mybookstore/proto/gen/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrRevoked: la sesión no existe, venció o fue revocada (logout, rotación).
+var ErrRevoked = errors.New("session: sesión revocada o vencida")
+
+// Store guarda el estado de las sesiones fuera del token para poder revocarlas.
+// Check devuelve ErrRevoked cuando la sesión ya no sirve; cualquier otro error
+// es de infraestructura.
+type Store interface {
+	Create(ctx context.Context, userID int64, ttl time.Duration) (*Session, error)
+	Check(ctx context.Context, id string) (*Session, error)
+	Rotate(ctx context.Context, id string, ttl time.Duration) (*Session, error)
+	Revoke(ctx context.Context, id string) error
+}
+
+// UserStore guarda las sesiones en el servicio User.
+type UserStore struct {
+	Client userpb.UserClient
+}
+
+// storePrincipal firma las llamadas de sesión que no traen usuario: al abrir la
+// sesión (login) y al verificar o rotar la cookie todavía no hay Principal, y
+// User exige token en todos los RPC salvo Register y Authenticate.
+var storePrincipal = auth.Service("session")
+
+func withPrincipal(ctx context.Context) context.Context {
+	if auth.FromContext(ctx) != nil {
+		return ctx
+	}
+	return auth.NewContext(ctx, storePrincipal)
+}
+
+func (s UserStore) Create(ctx context.Context, userID int64, ttl time.Duration) (*Session, error) {
+	resp, err := s.Client.CreateSession(withPrincipal(ctx), &userpb.CreateSessionRequest{UserId: userID, TtlSeconds: int64(ttl / time.Second)})
+	if err != nil {
+		return nil, err
+	}
+	return fromPB(resp), nil
+}
+
+func (s UserStore) Check(ctx context.Context, id string) (*Session, error) {
+	resp, err := s.Client.CheckSession(withPrincipal(ctx), &userpb.SessionRef{SessionId: id})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromPB(resp), nil
+}
+
+func (s UserStore) Rotate(ctx context.Context, id string, ttl time.Duration) (*Session, error) {
+	resp, err := s.Client.RotateSession(withPrincipal(ctx), &userpb.RotateSessionRequest{SessionId: id, TtlSeconds: int64(ttl / time.Second)})
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fromPB(resp), nil
+}
+
+func (s UserStore) Revoke(ctx context.Context, id string) error {
+	_, err := s.Client.RevokeSession(withPrincipal(ctx), &userpb.SessionRef{SessionId: id})
+	return err
+}
+
+// RevokeUser cierra todas las sesiones del usuario.
+func (s UserStore) RevokeUser(ctx context.Context, userID int64) error {
+	_, err := s.Client.RevokeUserSessions(withPrincipal(ctx), &commonpb.UserRef{UserId: userID})
+	return err
+}
+
+func fromPB(p *userpb.Session) *Session {
+	return &Session{
+		ID:        p.GetSessionId(),
+		UserID:    p.GetUserId(),
+		Name:      p.GetName(),
+		IssuedAt:  time.Unix(p.GetCreatedUnix(), 0),
+		ExpiresAt: time.Unix(p.GetExpiresUnix(), 0),
+	}
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/auth"
+)
+
+// Los tokens son JWT HS256 firmados con auth.Keyring (propósito "session"): el
+// kid del header permite rotar SESSION_SECRET sin invalidar las sesiones
+// firmadas con la clave anterior.
+
+// errToken cubre cualquier token mal formado, con firma inválida o vencido; el
+// motivo exacto no se expone.
+var errToken = auth.ErrToken
+
+// maxSkew tolera relojes desfasados entre frontends al validar iat.
+const maxSkew = time.Minute
+
+type claims struct {
+	Sub  string `json:"sub"` // user_id
+	Sid  string `json:"sid"` // sesión en el servicio User
+	Name string `json:"name,omitempty"`
+	Iat  int64  `json:"iat"`
+	Exp  int64  `json:"exp"`
+}
+
+type keyring struct {
+	auth.Keyring
+}
+
+func newKeyring(current string, previous []string) keyring {
+	return keyring{auth.NewKeyring("session", current, previous...)}
+}
+
+func (kr keyring) sign(s *Session) (string, error) {
+	return kr.Sign(claims{
+		Sub:  strconv.FormatInt(s.UserID, 10),
+		Sid:  s.ID,
+		Name: s.Name,
+		Iat:  s.IssuedAt.Unix(),
+		Exp:  s.ExpiresAt.Unix(),
+	})
+}
+
+func (kr keyring) verify(token string, now time.Time) (*Session, error) {
+	var c claims
+	if err := kr.Verify(token, &c); err != nil {
+		return nil, errToken
+	}
+	uid, err := strconv.ParseInt(c.Sub, 10, 64)
+	if err != nil || uid <= 0 || c.Sid == "" {
+		return nil, errToken
+	}
+	if now.Unix() >= c.Exp || c.Iat > now.Add(maxSkew).Unix() {
+		return nil, errToken
+	}
+	return &Session{
+		ID:        c.Sid,
+		UserID:    uid,
+		Name:      c.Name,
+		IssuedAt:  time.Unix(c.Iat, 0),
+		ExpiresAt: time.Unix(c.Exp, 0),
+	}, nil
+}
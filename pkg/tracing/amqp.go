@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier adapta los headers AMQP (amqp.Table en los dos clientes que usa
+// el proyecto) al propagador.
+type headerCarrier map[string]any
+
+func (h headerCarrier) Get(key string) string {
+	v, _ := h[key].(string)
+	return v
+}
+
+func (h headerCarrier) Set(key, value string) { h[key] = value }
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Inject agrega a headers el contexto de traza de ctx y los devuelve (crea el
+// mapa si es nil). Se combina con los de pkg/logging:
+//
+//	Headers: tracing.Inject(ctx, logging.Headers(ctx))
+func Inject(ctx context.Context, headers map[string]any) map[string]any {
+	if headers == nil {
+		headers = map[string]any{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+	if len(headers) == 0 {
+		return nil
+	}
+	return headers
+}
+
+// StartPublish abre el span de productor de un mensaje; se inyecta con Inject
+// usando el ctx devuelto y se cierra con End cuando el broker acepta el mensaje.
+func StartPublish(ctx context.Context, exchange, routingKey string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, routingKey+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(append(messaging(exchange, routingKey),
+			semconv.MessagingOperationTypePublish)...),
+	)
+}
+
+// StartConsume continúa la traza del publicador con el span de consumidor de un
+// mensaje recibido de queue. Se cierra con End al terminar de procesarlo.
+func StartConsume(ctx context.Context, headers map[string]any, queue, routingKey string) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(headers))
+	return tracer().Start(ctx, routingKey+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(append(messaging(queue, routingKey),
+			semconv.MessagingOperationTypeDeliver)...),
+	)
+}
+
+func messaging(destination, routingKey string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingDestinationName(destination),
+		semconv.MessagingRabbitmqDestinationRoutingKey(routingKey),
+	}
+}
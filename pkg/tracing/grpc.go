@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"google.golang.org/grpc"
+)
+
+// Los health checks (sondas de k8s y el health checking del balanceador) no
+// abren spans: serían la mayoría y no dicen nada del checkout.
+var notHealth = otelgrpc.WithFilter(filters.Not(filters.HealthCheck()))
+
+// DialOptions abre un span de cliente por RPC y envía el contexto de traza en el
+// metadata. Setup ya las registra en grpcclient.
+func DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{grpc.WithStatsHandler(otelgrpc.NewClientHandler(notHealth))}
+}
+
+// ServerOptions continúa la traza del llamador con un span de servidor por RPC.
+// El stats handler corre antes que cualquier interceptor, así los logs de
+// pkg/logging ya tienen el trace_id.
+func ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.StatsHandler(otelgrpc.NewServerHandler(notHealth))}
+}
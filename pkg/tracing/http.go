@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+)
+
+// Middleware abre un span de servidor por petición HTTP, continuando la traza
+// si el navegador o el proxy envían traceparent. Va por fuera de
+// logging.Middleware para que la línea de cada petición lleve el trace_id.
+//
+// El span se nombra con la ruta del mux ("GET /book/{id}") y no con la URL,
+// igual que metrics.Middleware: la ruta se conoce recién cuando el mux la
+// informa, así que el nombre se fija al terminar la petición.
+func Middleware(next http.Handler) http.Handler {
+	named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, noted := metrics.Route(r)
+		next.ServeHTTP(w, r)
+		route := noted()
+		if route == "" {
+			route = "unmatched"
+		}
+		span := trace.SpanFromContext(r.Context())
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	})
+	return otelhttp.NewHandler(named, "http",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool { return r.URL.Path != "/healthz" }),
+	)
+}
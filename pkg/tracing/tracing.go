@@ -0,0 +1,107 @@
+// Package tracing configura OpenTelemetry igual en todos los procesos Go: el
+// contexto de traza (W3C traceparent) viaja en el metadata de gRPC, en los
+// headers HTTP y en los headers de los mensajes AMQP, y cada RPC, petición
+// HTTP, publicación, mensaje consumido y consulta SQLite abre un span.
+//
+// El exportador se elige con OTEL_TRACES_EXPORTER:
+//
+//	none    (por defecto) no exporta, pero el contexto se sigue propagando
+//	otlp    OTLP/gRPC; destino y opciones con las variables OTEL_EXPORTER_OTLP_*
+//	stdout  una línea JSON por span en la salida estándar
+//	file    lo mismo en TRACES_FILE ("traces.jsonl"); sirve sin red
+//
+// El muestreo sigue OTEL_TRACES_SAMPLER / OTEL_TRACES_SAMPLER_ARG (por defecto
+// se muestrea todo lo que no venga ya descartado por el llamador).
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ahinestrog/mybookstore/pkg/grpcclient"
+)
+
+const scope = "github.com/ahinestrog/mybookstore/pkg/tracing"
+
+// Setup instala el propagador y el TracerProvider de service y registra los
+// handlers de cliente en grpcclient. Se llama al inicio de main, después de
+// logging.Setup; la función devuelta vacía los spans pendientes al salir.
+func Setup(service string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	grpcclient.AddDialOptions(DialOptions()...)
+
+	ctx := context.Background()
+	opt, err := exporter(ctx, os.Getenv("OTEL_TRACES_EXPORTER"))
+	if err != nil || opt == nil {
+		return func(context.Context) error { return nil }, err
+	}
+	// OTEL_SERVICE_NAME y OTEL_RESOURCE_ATTRIBUTES tienen prioridad
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(service)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(opt, sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// exporter devuelve cómo se entregan los spans, o nil si no se exportan.
+func exporter(ctx context.Context, kind string) (sdktrace.TracerProviderOption, error) {
+	switch kind {
+	case "", "none":
+		return nil, nil
+	case "otlp":
+		// La conexión es perezosa: sin colector los spans se descartan al reintentar
+		exp, err := otlptracegrpc.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return sdktrace.WithBatcher(exp), nil
+	case "stdout", "file":
+		w := os.Stdout
+		if kind == "file" {
+			path := os.Getenv("TRACES_FILE")
+			if path == "" {
+				path = "traces.jsonl"
+			}
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return nil, err
+			}
+			w = f
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, err
+		}
+		// Sin lote: lo escrito no se pierde si el proceso termina de golpe
+		return sdktrace.WithSyncer(exp), nil
+	}
+	return nil, fmt.Errorf("OTEL_TRACES_EXPORTER=%q (none | otlp | stdout | file)", kind)
+}
+
+func tracer() trace.Tracer { return otel.Tracer(scope) }
+
+// End marca el span como fallido si err no es nil y lo cierra.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
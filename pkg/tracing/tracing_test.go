@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	_ "modernc.org/sqlite"
+
+	"github.com/ahinestrog/mybookstore/pkg/metrics"
+)
+
+// memory instala un TracerProvider que guarda los spans en memoria.
+func memory(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+	return exp
+}
+
+// TestAMQPContinuesTrace: el consumidor es hijo del span de publicación aunque
+// solo reciba los headers del mensaje.
+func TestAMQPContinuesTrace(t *testing.T) {
+	exp := memory(t)
+
+	ctx, pub := StartPublish(context.Background(), "mybookstore.events", "order.created")
+	headers := Inject(ctx, map[string]any{"x-correlation-id": "abc"})
+	End(pub, nil)
+	if headers["traceparent"] == nil || headers["x-correlation-id"] != "abc" {
+		t.Fatalf("headers = %v", headers)
+	}
+
+	_, cons := StartConsume(context.Background(), headers, "inventory.order.created", "order.created")
+	End(cons, nil)
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, se esperaban 2", len(spans))
+	}
+	p, c := spans[0], spans[1]
+	if p.SpanKind != trace.SpanKindProducer || c.SpanKind != trace.SpanKindConsumer {
+		t.Fatalf("tipos = %v, %v", p.SpanKind, c.SpanKind)
+	}
+	if c.Parent.SpanID() != p.SpanContext.SpanID() || c.SpanContext.TraceID() != p.SpanContext.TraceID() {
+		t.Fatalf("el consumidor no continúa la traza del publicador")
+	}
+
+	if h := Inject(context.Background(), nil); h != nil {
+		t.Fatalf("sin traza ni headers Inject = %v, se esperaba nil", h)
+	}
+}
+
+// TestHTTPSpanNames: el span lleva la ruta del mux, no la URL, tanto si el mux
+// está por fuera del middleware (webhook de Payment) como por dentro (gateway
+// del storefront, que informa la ruta con metrics.NoteRoute).
+func TestHTTPSpanNames(t *testing.T) {
+	exp := memory(t)
+
+	sub := http.NewServeMux()
+	sub.HandleFunc("GET /book/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	gateway := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub.ServeHTTP(w, r)
+		metrics.NoteRoute(r.Context(), "/catalog"+metrics.PatternPath(r.Pattern))
+	})
+	inner := http.NewServeMux()
+	inner.Handle("/catalog/", http.StripPrefix("/catalog", gateway))
+	outer := http.NewServeMux()
+	outer.Handle("POST /webhooks/pse", Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	for _, c := range []struct {
+		h            http.Handler
+		method, path string
+		want         string
+	}{
+		{Middleware(metrics.Middleware(inner)), "GET", "/catalog/book/42", "GET /catalog/book/{id}"},
+		{Middleware(inner), "GET", "/catalog/book/43", "GET /catalog/book/{id}"},
+		{Middleware(inner), "GET", "/nope/7", "GET unmatched"},
+		{outer, "POST", "/webhooks/pse", "POST /webhooks/pse"},
+	} {
+		exp.Reset()
+		c.h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(c.method, c.path, nil))
+		spans := exp.GetSpans()
+		if len(spans) != 1 || spans[0].Name != c.want {
+			t.Fatalf("%s %s: spans = %v, se esperaba %q", c.method, c.path, spans.Snapshots(), c.want)
+		}
+	}
+}
+
+// TestSQLiteSpans: las sentencias dentro de una traza abren spans hijos; las de
+// tareas de fondo no.
+func TestSQLiteSpans(t *testing.T) {
+	exp := memory(t)
+
+	db, err := OpenSQLite("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(exp.GetSpans()); n != 0 {
+		t.Fatalf("sin traza se abrieron %d spans", n)
+	}
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "GetBook")
+	if _, err := db.ExecContext(ctx, `INSERT INTO books (title) VALUES (?)`, "Cien años"); err != nil {
+		t.Fatal(err)
+	}
+	var title string
+	if err := db.QueryRowContext(ctx, `SELECT title FROM books WHERE id = ?`, 1).Scan(&title); err != nil || title != "Cien años" {
+		t.Fatalf("title = %q, err = %v", title, err)
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO nope VALUES (1)`)
+	if err == nil {
+		t.Fatal("se esperaba error con una tabla inexistente")
+	}
+	root.End()
+
+	want := []string{"sqlite INSERT", "sqlite SELECT", "sqlite INSERT", "GetBook"}
+	spans := exp.GetSpans()
+	if len(spans) != len(want) {
+		t.Fatalf("spans = %d, se esperaban %d", len(spans), len(want))
+	}
+	for i, s := range spans {
+		if s.Name != want[i] {
+			t.Fatalf("span %d = %q, se esperaba %q", i, s.Name, want[i])
+		}
+		if i < 3 && s.Parent.SpanID() != root.SpanContext().SpanID() {
+			t.Fatalf("%q no es hijo del span de la petición", s.Name)
+		}
+	}
+	if spans[2].Status.Code != codes.Error || len(spans[2].Events) == 0 {
+		t.Fatalf("el INSERT fallido no quedó marcado: %+v", spans[2].Status)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	for _, kind := range []string{"", "none"} {
+		if opt, err := exporter(context.Background(), kind); opt != nil || err != nil {
+			t.Fatalf("exporter(%q) = %v, %v; se esperaba sin exportador", kind, opt, err)
+		}
+	}
+	t.Setenv("TRACES_FILE", t.TempDir()+"/traces.jsonl")
+	if opt, err := exporter(context.Background(), "file"); opt == nil || err != nil {
+		t.Fatalf("exporter(file) = %v, %v", opt, err)
+	}
+	if _, err := exporter(context.Background(), "zipkin"); err == nil {
+		t.Fatal("se esperaba error con un exportador desconocido")
+	}
+}
@@ -0,0 +1,175 @@
+// Package webform reúne lo que comparten los handlers de formularios de los
+// frontends: el método HTTP que acepta cada ruta y la validación de campos. El
+// token CSRF lo verifica el middleware de pkg/session antes de llegar aquí.
+//
+//	f := webform.Parse(r)
+//	bookID := f.ID("book_id", "Libro")
+//	qty := f.Int("qty", "Cantidad", 1, 99)
+//	if !f.Valid() { ... f.Message() ... }
+package webform
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Methods envuelve h para que solo atienda los métodos dados; el resto recibe
+// 405 con el header Allow. Aceptar GET implica aceptar HEAD.
+func Methods(h http.HandlerFunc, methods ...string) http.HandlerFunc {
+	if slices.Contains(methods, http.MethodGet) && !slices.Contains(methods, http.MethodHead) {
+		methods = append(methods, http.MethodHead)
+	}
+	allow := strings.Join(methods, ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(methods, r.Method) {
+			w.Header().Set("Allow", allow)
+			http.Error(w, "método no permitido", http.StatusMethodNotAllowed)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// Form lee los campos del cuerpo de un formulario y acumula un error por campo.
+type Form struct {
+	values url.Values
+	errs   map[string]string
+	order  []string // campos con error, en el orden en que se validaron
+}
+
+// Parse lee el cuerpo de r. Un cuerpo ilegible deja el formulario inválido.
+func Parse(r *http.Request) *Form {
+	f := &Form{errs: map[string]string{}}
+	if err := r.ParseForm(); err != nil {
+		f.fail("_form", "no se pudo leer el formulario")
+	}
+	f.values = r.PostForm
+	return f
+}
+
+func (f *Form) fail(field, msg string) {
+	if _, ok := f.errs[field]; ok {
+		return
+	}
+	f.errs[field] = msg
+	f.order = append(f.order, field)
+}
+
+// Valid indica si todos los campos validados pasaron.
+func (f *Form) Valid() bool { return len(f.errs) == 0 }
+
+// Error devuelve el mensaje del campo, o "".
+func (f *Form) Error(field string) string { return f.errs[field] }
+
+// Errors devuelve los mensajes por campo, para mostrarlos junto a cada input.
+func (f *Form) Errors() map[string]string { return f.errs }
+
+// Message junta los errores en una línea para mostrarla arriba del formulario.
+func (f *Form) Message() string {
+	msgs := make([]string, 0, len(f.order))
+	for _, field := range f.order {
+		msgs = append(msgs, f.errs[field])
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Text devuelve el campo sin espacios en los extremos. Con min > 0 es
+// obligatorio; se rechazan caracteres de control y más de max caracteres.
+func (f *Form) Text(field, label string, min, max int) string {
+	v := strings.TrimSpace(f.values.Get(field))
+	n := utf8.RuneCountInString(v)
+	switch {
+	case !utf8.ValidString(v) || strings.ContainsFunc(v, unicode.IsControl):
+		f.fail(field, label+" tiene caracteres no válidos")
+	case n == 0 && min > 0:
+		f.fail(field, label+" es obligatorio")
+	case n < min:
+		f.fail(field, fmt.Sprintf("%s debe tener al menos %d caracteres", label, min))
+	case n > max:
+		f.fail(field, fmt.Sprintf("%s admite como máximo %d caracteres", label, max))
+	}
+	return v
+}
+
+// Email devuelve el correo en minúsculas; es obligatorio.
+func (f *Form) Email(field, label string) string {
+	v := strings.ToLower(f.Text(field, label, 1, 254))
+	if f.errs[field] != "" {
+		return v
+	}
+	if a, err := mail.ParseAddress(v); err != nil || a.Address != v || !strings.Contains(v[strings.LastIndex(v, "@"):], ".") {
+		f.fail(field, label+" no es un correo válido")
+	}
+	return v
+}
+
+// Password devuelve el campo tal cual (sin recortar espacios). El máximo se
+// cuenta en bytes: bcrypt ignora lo que pase de 72.
+func (f *Form) Password(field, label string, min, max int) string {
+	v := f.values.Get(field)
+	switch {
+	case v == "":
+		f.fail(field, label+" es obligatoria")
+	case utf8.RuneCountInString(v) < min:
+		f.fail(field, fmt.Sprintf("%s debe tener al menos %d caracteres", label, min))
+	case len(v) > max:
+		f.fail(field, fmt.Sprintf("%s admite como máximo %d bytes", label, max))
+	}
+	return v
+}
+
+// Int devuelve el campo como entero en [min, max]; es obligatorio.
+func (f *Form) Int(field, label string, min, max int64) int64 {
+	v := strings.TrimSpace(f.values.Get(field))
+	if v == "" {
+		f.fail(field, label+" es obligatorio")
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < min || n > max {
+		f.fail(field, fmt.Sprintf("%s debe ser un número entre %d y %d", label, min, max))
+		return 0
+	}
+	return n
+}
+
+// OptionalInt es Int con def cuando el campo no viene o está vacío.
+func (f *Form) OptionalInt(field, label string, min, max, def int64) int64 {
+	if strings.TrimSpace(f.values.Get(field)) == "" {
+		return def
+	}
+	return f.Int(field, label, min, max)
+}
+
+// ID devuelve un identificador positivo; es obligatorio.
+func (f *Form) ID(field, label string) int64 {
+	return f.Int(field, label, 1, math.MaxInt64)
+}
+
+// OneOf devuelve el campo si es una de las opciones; es obligatorio.
+func (f *Form) OneOf(field, label string, options ...string) string {
+	v := strings.TrimSpace(f.values.Get(field))
+	if !slices.Contains(options, v) {
+		f.fail(field, label+" no es una opción válida")
+	}
+	return v
+}
+
+// Bool indica si la casilla vino marcada (cualquier valor no vacío).
+func (f *Form) Bool(field string) bool {
+	return f.values.Get(field) != ""
+}
+
+// Value devuelve el campo sin validar, para valores que no vienen del usuario
+// (como la clave de idempotencia que el servidor puso en el formulario).
+func (f *Form) Value(field string) string {
+	return f.values.Get(field)
+}
@@ -0,0 +1,78 @@
+package webform
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func form(values url.Values) *Form {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return Parse(r)
+}
+
+func TestMethods(t *testing.T) {
+	h := Methods(func(w http.ResponseWriter, r *http.Request) {}, http.MethodPost)
+	for method, want := range map[string]int{http.MethodPost: 200, http.MethodGet: 405, http.MethodDelete: 405} {
+		rec := httptest.NewRecorder()
+		h(rec, httptest.NewRequest(method, "/clear", nil))
+		if rec.Code != want {
+			t.Errorf("%s: status %d, se esperaba %d", method, rec.Code, want)
+		}
+		if want == 405 && rec.Header().Get("Allow") != "POST" {
+			t.Errorf("%s: Allow = %q", method, rec.Header().Get("Allow"))
+		}
+	}
+}
+
+func TestValidation(t *testing.T) {
+	f := form(url.Values{
+		"name":     {"  Ana  "},
+		"email":    {"Ana@Example.com"},
+		"password": {" secreta123 "},
+		"qty":      {"3"},
+		"book_id":  {"42"},
+		"method":   {"pse"},
+	})
+	name := f.Text("name", "Nombre", 1, 80)
+	email := f.Email("email", "Correo")
+	pass := f.Password("password", "Contraseña", 8, 72)
+	qty := f.Int("qty", "Cantidad", 1, 99)
+	id := f.ID("book_id", "Libro")
+	method := f.OneOf("method", "Medio de pago", "pse", "card")
+	if !f.Valid() {
+		t.Fatalf("formulario válido rechazado: %s", f.Message())
+	}
+	if name != "Ana" || email != "ana@example.com" || pass != " secreta123 " || qty != 3 || id != 42 || method != "pse" {
+		t.Fatalf("valores: %q %q %q %d %d %q", name, email, pass, qty, id, method)
+	}
+
+	f = form(url.Values{
+		"name":     {strings.Repeat("a", 81)},
+		"email":    {"ana@localhost"},
+		"password": {"corta"},
+		"qty":      {"0"},
+		"book_id":  {"-1"},
+		"method":   {"bitcoin"},
+		"label":    {"casa\x00"},
+	})
+	f.Text("name", "Nombre", 1, 80)
+	f.Email("email", "Correo")
+	f.Password("password", "Contraseña", 8, 72)
+	f.Int("qty", "Cantidad", 1, 99)
+	f.ID("book_id", "Libro")
+	f.OneOf("method", "Medio de pago", "pse", "card")
+	f.Text("label", "Etiqueta", 0, 40)
+	f.Text("city", "Ciudad", 1, 80)
+	for _, field := range []string{"name", "email", "password", "qty", "book_id", "method", "label", "city"} {
+		if f.Error(field) == "" {
+			t.Errorf("%s: se esperaba un error", field)
+		}
+	}
+	if got := f.OptionalInt("missing", "Cantidad", 1, 99, 1); got != 1 {
+		t.Errorf("OptionalInt sin valor = %d", got)
+	}
+}
@@ -8,6 +8,7 @@ package cartpb
 
 import (
 	common "github.com/ahinestrog/mybookstore/proto/gen/common"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -149,6 +150,8 @@ type CartItem struct {
 	Qty           int32                  `protobuf:"varint,3,opt,name=qty,proto3" json:"qty,omitempty"`
 	UnitPrice     *common.Money          `protobuf:"bytes,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
 	LineTotal     *common.Money          `protobuf:"bytes,5,opt,name=line_total,json=lineTotal,proto3" json:"line_total,omitempty"`
+	Category      string                 `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	WeightGrams   int32                  `protobuf:"varint,7,opt,name=weight_grams,json=weightGrams,proto3" json:"weight_grams,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -218,10 +221,25 @@ func (x *CartItem) GetLineTotal() *common.Money {
 	return nil
 }
 
+func (x *CartItem) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *CartItem) GetWeightGrams() int32 {
+	if x != nil {
+		return x.WeightGrams
+	}
+	return 0
+}
+
 type CartView struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Items         []*CartItem            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
-	Total         *common.Money          `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+	Total         *common.Money          `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`       // suma de líneas (sin impuestos ni envío)
+	Estimate      *common.PriceBreakdown `protobuf:"bytes,3,opt,name=estimate,proto3" json:"estimate,omitempty"` // estimado con la región de envío por defecto
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -270,12 +288,175 @@ func (x *CartView) GetTotal() *common.Money {
 	return nil
 }
 
+func (x *CartView) GetEstimate() *common.PriceBreakdown {
+	if x != nil {
+		return x.Estimate
+	}
+	return nil
+}
+
+type CheckoutSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CheckoutId    string                 `protobuf:"bytes,2,opt,name=checkout_id,json=checkoutId,proto3" json:"checkout_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckoutSnapshotRequest) Reset() {
+	*x = CheckoutSnapshotRequest{}
+	mi := &file_cart_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckoutSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckoutSnapshotRequest) ProtoMessage() {}
+
+func (x *CheckoutSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckoutSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*CheckoutSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_cart_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CheckoutSnapshotRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CheckoutSnapshotRequest) GetCheckoutId() string {
+	if x != nil {
+		return x.CheckoutId
+	}
+	return ""
+}
+
+type CheckoutSnapshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CheckoutId    string                 `protobuf:"bytes,1,opt,name=checkout_id,json=checkoutId,proto3" json:"checkout_id,omitempty"`
+	Cart          *CartView              `protobuf:"bytes,2,opt,name=cart,proto3" json:"cart,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckoutSnapshotResponse) Reset() {
+	*x = CheckoutSnapshotResponse{}
+	mi := &file_cart_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckoutSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckoutSnapshotResponse) ProtoMessage() {}
+
+func (x *CheckoutSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckoutSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*CheckoutSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_cart_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CheckoutSnapshotResponse) GetCheckoutId() string {
+	if x != nil {
+		return x.CheckoutId
+	}
+	return ""
+}
+
+func (x *CheckoutSnapshotResponse) GetCart() *CartView {
+	if x != nil {
+		return x.Cart
+	}
+	return nil
+}
+
+type RestoreCheckoutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	CheckoutId    string                 `protobuf:"bytes,2,opt,name=checkout_id,json=checkoutId,proto3" json:"checkout_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreCheckoutRequest) Reset() {
+	*x = RestoreCheckoutRequest{}
+	mi := &file_cart_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreCheckoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreCheckoutRequest) ProtoMessage() {}
+
+func (x *RestoreCheckoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cart_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreCheckoutRequest.ProtoReflect.Descriptor instead.
+func (*RestoreCheckoutRequest) Descriptor() ([]byte, []int) {
+	return file_cart_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RestoreCheckoutRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RestoreCheckoutRequest) GetCheckoutId() string {
+	if x != nil {
+		return x.CheckoutId
+	}
+	return ""
+}
+
 var File_cart_proto protoreflect.FileDescriptor
 
 const file_cart_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"cart.proto\x12\x04cart\x1a\fcommon.proto\"T\n" +
+	"cart.proto\x12\x04cart\x1a\fcommon.proto\x1a\x1cgoogle/api/annotations.proto\"T\n" +
 	"\x0eAddItemRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x17\n" +
 	"\abook_id\x18\x02 \x01(\x03R\x06bookId\x12\x10\n" +
@@ -283,7 +464,7 @@ const file_cart_proto_rawDesc = "" +
 	"\x11RemoveItemRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x17\n" +
 	"\abook_id\x18\x02 \x01(\x03R\x06bookId\x12\x10\n" +
-	"\x03qty\x18\x03 \x01(\x05R\x03qty\"\xa7\x01\n" +
+	"\x03qty\x18\x03 \x01(\x05R\x03qty\"\xe6\x01\n" +
 	"\bCartItem\x12\x17\n" +
 	"\abook_id\x18\x01 \x01(\x03R\x06bookId\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x10\n" +
@@ -291,16 +472,33 @@ const file_cart_proto_rawDesc = "" +
 	"\n" +
 	"unit_price\x18\x04 \x01(\v2\r.common.MoneyR\tunitPrice\x12,\n" +
 	"\n" +
-	"line_total\x18\x05 \x01(\v2\r.common.MoneyR\tlineTotal\"U\n" +
+	"line_total\x18\x05 \x01(\v2\r.common.MoneyR\tlineTotal\x12\x1a\n" +
+	"\bcategory\x18\x06 \x01(\tR\bcategory\x12!\n" +
+	"\fweight_grams\x18\a \x01(\x05R\vweightGrams\"\x89\x01\n" +
 	"\bCartView\x12$\n" +
 	"\x05items\x18\x01 \x03(\v2\x0e.cart.CartItemR\x05items\x12#\n" +
-	"\x05total\x18\x02 \x01(\v2\r.common.MoneyR\x05total2\xc8\x01\n" +
-	"\x04Cart\x12*\n" +
-	"\aGetCart\x12\x0f.common.UserRef\x1a\x0e.cart.CartView\x12/\n" +
-	"\aAddItem\x12\x14.cart.AddItemRequest\x1a\x0e.cart.CartView\x125\n" +
+	"\x05total\x18\x02 \x01(\v2\r.common.MoneyR\x05total\x122\n" +
+	"\bestimate\x18\x03 \x01(\v2\x16.common.PriceBreakdownR\bestimate\"S\n" +
+	"\x17CheckoutSnapshotRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1f\n" +
+	"\vcheckout_id\x18\x02 \x01(\tR\n" +
+	"checkoutId\"_\n" +
+	"\x18CheckoutSnapshotResponse\x12\x1f\n" +
+	"\vcheckout_id\x18\x01 \x01(\tR\n" +
+	"checkoutId\x12\"\n" +
+	"\x04cart\x18\x02 \x01(\v2\x0e.cart.CartViewR\x04cart\"R\n" +
+	"\x16RestoreCheckoutRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1f\n" +
+	"\vcheckout_id\x18\x02 \x01(\tR\n" +
+	"checkoutId2\x8a\x04\n" +
+	"\x04Cart\x12P\n" +
+	"\aGetCart\x12\x0f.common.UserRef\x1a\x0e.cart.CartView\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/api/v1/users/{user_id}/cart\x12^\n" +
+	"\aAddItem\x12\x14.cart.AddItemRequest\x1a\x0e.cart.CartView\"-\x82\xd3\xe4\x93\x02':\x01*\"\"/api/v1/users/{user_id}/cart/items\x12k\n" +
 	"\n" +
-	"RemoveItem\x12\x17.cart.RemoveItemRequest\x1a\x0e.cart.CartView\x12,\n" +
-	"\tClearCart\x12\x0f.common.UserRef\x1a\x0e.cart.CartViewB9Z7github.com/ahinestrog/mybookstore/proto/gen/cart;cartpbb\x06proto3"
+	"RemoveItem\x12\x17.cart.RemoveItemRequest\x1a\x0e.cart.CartView\"4\x82\xd3\xe4\x93\x02.*,/api/v1/users/{user_id}/cart/items/{book_id}\x12R\n" +
+	"\tClearCart\x12\x0f.common.UserRef\x1a\x0e.cart.CartView\"$\x82\xd3\xe4\x93\x02\x1e*\x1c/api/v1/users/{user_id}/cart\x12Q\n" +
+	"\x10CheckoutSnapshot\x12\x1d.cart.CheckoutSnapshotRequest\x1a\x1e.cart.CheckoutSnapshotResponse\x12<\n" +
+	"\x0fRestoreCheckout\x12\x1c.cart.RestoreCheckoutRequest\x1a\v.common.AckB9Z7github.com/ahinestrog/mybookstore/proto/gen/cart;cartpbb\x06proto3"
 
 var (
 	file_cart_proto_rawDescOnce sync.Once
@@ -314,33 +512,44 @@ func file_cart_proto_rawDescGZIP() []byte {
 	return file_cart_proto_rawDescData
 }
 
-var file_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_cart_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_cart_proto_goTypes = []any{
-	(*AddItemRequest)(nil),    // 0: cart.AddItemRequest
-	(*RemoveItemRequest)(nil), // 1: cart.RemoveItemRequest
-	(*CartItem)(nil),          // 2: cart.CartItem
-	(*CartView)(nil),          // 3: cart.CartView
-	(*common.Money)(nil),      // 4: common.Money
-	(*common.UserRef)(nil),    // 5: common.UserRef
+	(*AddItemRequest)(nil),           // 0: cart.AddItemRequest
+	(*RemoveItemRequest)(nil),        // 1: cart.RemoveItemRequest
+	(*CartItem)(nil),                 // 2: cart.CartItem
+	(*CartView)(nil),                 // 3: cart.CartView
+	(*CheckoutSnapshotRequest)(nil),  // 4: cart.CheckoutSnapshotRequest
+	(*CheckoutSnapshotResponse)(nil), // 5: cart.CheckoutSnapshotResponse
+	(*RestoreCheckoutRequest)(nil),   // 6: cart.RestoreCheckoutRequest
+	(*common.Money)(nil),             // 7: common.Money
+	(*common.PriceBreakdown)(nil),    // 8: common.PriceBreakdown
+	(*common.UserRef)(nil),           // 9: common.UserRef
+	(*common.Ack)(nil),               // 10: common.Ack
 }
 var file_cart_proto_depIdxs = []int32{
-	4, // 0: cart.CartItem.unit_price:type_name -> common.Money
-	4, // 1: cart.CartItem.line_total:type_name -> common.Money
-	2, // 2: cart.CartView.items:type_name -> cart.CartItem
-	4, // 3: cart.CartView.total:type_name -> common.Money
-	5, // 4: cart.Cart.GetCart:input_type -> common.UserRef
-	0, // 5: cart.Cart.AddItem:input_type -> cart.AddItemRequest
-	1, // 6: cart.Cart.RemoveItem:input_type -> cart.RemoveItemRequest
-	5, // 7: cart.Cart.ClearCart:input_type -> common.UserRef
-	3, // 8: cart.Cart.GetCart:output_type -> cart.CartView
-	3, // 9: cart.Cart.AddItem:output_type -> cart.CartView
-	3, // 10: cart.Cart.RemoveItem:output_type -> cart.CartView
-	3, // 11: cart.Cart.ClearCart:output_type -> cart.CartView
-	8, // [8:12] is the sub-list for method output_type
-	4, // [4:8] is the sub-list for method input_type
-	4, // [4:4] is the sub-list for extension type_name
-	4, // [4:4] is the sub-list for extension extendee
-	0, // [0:4] is the sub-list for field type_name
+	7,  // 0: cart.CartItem.unit_price:type_name -> common.Money
+	7,  // 1: cart.CartItem.line_total:type_name -> common.Money
+	2,  // 2: cart.CartView.items:type_name -> cart.CartItem
+	7,  // 3: cart.CartView.total:type_name -> common.Money
+	8,  // 4: cart.CartView.estimate:type_name -> common.PriceBreakdown
+	3,  // 5: cart.CheckoutSnapshotResponse.cart:type_name -> cart.CartView
+	9,  // 6: cart.Cart.GetCart:input_type -> common.UserRef
+	0,  // 7: cart.Cart.AddItem:input_type -> cart.AddItemRequest
+	1,  // 8: cart.Cart.RemoveItem:input_type -> cart.RemoveItemRequest
+	9,  // 9: cart.Cart.ClearCart:input_type -> common.UserRef
+	4,  // 10: cart.Cart.CheckoutSnapshot:input_type -> cart.CheckoutSnapshotRequest
+	6,  // 11: cart.Cart.RestoreCheckout:input_type -> cart.RestoreCheckoutRequest
+	3,  // 12: cart.Cart.GetCart:output_type -> cart.CartView
+	3,  // 13: cart.Cart.AddItem:output_type -> cart.CartView
+	3,  // 14: cart.Cart.RemoveItem:output_type -> cart.CartView
+	3,  // 15: cart.Cart.ClearCart:output_type -> cart.CartView
+	5,  // 16: cart.Cart.CheckoutSnapshot:output_type -> cart.CheckoutSnapshotResponse
+	10, // 17: cart.Cart.RestoreCheckout:output_type -> common.Ack
+	12, // [12:18] is the sub-list for method output_type
+	6,  // [6:12] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_cart_proto_init() }
@@ -354,7 +563,7 @@ func file_cart_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_cart_proto_rawDesc), len(file_cart_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,10 +20,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Cart_GetCart_FullMethodName    = "/cart.Cart/GetCart"
-	Cart_AddItem_FullMethodName    = "/cart.Cart/AddItem"
-	Cart_RemoveItem_FullMethodName = "/cart.Cart/RemoveItem"
-	Cart_ClearCart_FullMethodName  = "/cart.Cart/ClearCart"
+	Cart_GetCart_FullMethodName          = "/cart.Cart/GetCart"
+	Cart_AddItem_FullMethodName          = "/cart.Cart/AddItem"
+	Cart_RemoveItem_FullMethodName       = "/cart.Cart/RemoveItem"
+	Cart_ClearCart_FullMethodName        = "/cart.Cart/ClearCart"
+	Cart_CheckoutSnapshot_FullMethodName = "/cart.Cart/CheckoutSnapshot"
+	Cart_RestoreCheckout_FullMethodName  = "/cart.Cart/RestoreCheckout"
 )
 
 // CartClient is the client API for Cart service.
@@ -34,6 +36,15 @@ type CartClient interface {
 	AddItem(ctx context.Context, in *AddItemRequest, opts ...grpc.CallOption) (*CartView, error)
 	RemoveItem(ctx context.Context, in *RemoveItemRequest, opts ...grpc.CallOption) (*CartView, error)
 	ClearCart(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*CartView, error)
+	// Congela el carrito bajo checkout_id y devuelve las líneas congeladas.
+	// Las líneas salen del carrito vivo; se descartan o se devuelven al carrito
+	// cuando Order publica el resultado final de la saga (order.paid / order.failed).
+	// Repetir la llamada con el mismo checkout_id devuelve el mismo snapshot.
+	CheckoutSnapshot(ctx context.Context, in *CheckoutSnapshotRequest, opts ...grpc.CallOption) (*CheckoutSnapshotResponse, error)
+	// Devuelve las líneas congeladas al carrito cuando Order no llegó a crear la
+	// orden (falló al guardarla o el checkout quedó huérfano). No hace nada si el
+	// checkout ya se resolvió.
+	RestoreCheckout(ctx context.Context, in *RestoreCheckoutRequest, opts ...grpc.CallOption) (*common.Ack, error)
 }
 
 type cartClient struct {
@@ -84,6 +95,26 @@ func (c *cartClient) ClearCart(ctx context.Context, in *common.UserRef, opts ...
 	return out, nil
 }
 
+func (c *cartClient) CheckoutSnapshot(ctx context.Context, in *CheckoutSnapshotRequest, opts ...grpc.CallOption) (*CheckoutSnapshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckoutSnapshotResponse)
+	err := c.cc.Invoke(ctx, Cart_CheckoutSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cartClient) RestoreCheckout(ctx context.Context, in *RestoreCheckoutRequest, opts ...grpc.CallOption) (*common.Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Ack)
+	err := c.cc.Invoke(ctx, Cart_RestoreCheckout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CartServer is the server API for Cart service.
 // All implementations must embed UnimplementedCartServer
 // for forward compatibility.
@@ -92,6 +123,15 @@ type CartServer interface {
 	AddItem(context.Context, *AddItemRequest) (*CartView, error)
 	RemoveItem(context.Context, *RemoveItemRequest) (*CartView, error)
 	ClearCart(context.Context, *common.UserRef) (*CartView, error)
+	// Congela el carrito bajo checkout_id y devuelve las líneas congeladas.
+	// Las líneas salen del carrito vivo; se descartan o se devuelven al carrito
+	// cuando Order publica el resultado final de la saga (order.paid / order.failed).
+	// Repetir la llamada con el mismo checkout_id devuelve el mismo snapshot.
+	CheckoutSnapshot(context.Context, *CheckoutSnapshotRequest) (*CheckoutSnapshotResponse, error)
+	// Devuelve las líneas congeladas al carrito cuando Order no llegó a crear la
+	// orden (falló al guardarla o el checkout quedó huérfano). No hace nada si el
+	// checkout ya se resolvió.
+	RestoreCheckout(context.Context, *RestoreCheckoutRequest) (*common.Ack, error)
 	mustEmbedUnimplementedCartServer()
 }
 
@@ -114,6 +154,12 @@ func (UnimplementedCartServer) RemoveItem(context.Context, *RemoveItemRequest) (
 func (UnimplementedCartServer) ClearCart(context.Context, *common.UserRef) (*CartView, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ClearCart not implemented")
 }
+func (UnimplementedCartServer) CheckoutSnapshot(context.Context, *CheckoutSnapshotRequest) (*CheckoutSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckoutSnapshot not implemented")
+}
+func (UnimplementedCartServer) RestoreCheckout(context.Context, *RestoreCheckoutRequest) (*common.Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreCheckout not implemented")
+}
 func (UnimplementedCartServer) mustEmbedUnimplementedCartServer() {}
 func (UnimplementedCartServer) testEmbeddedByValue()              {}
 
@@ -207,6 +253,42 @@ func _Cart_ClearCart_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Cart_CheckoutSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckoutSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServer).CheckoutSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cart_CheckoutSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServer).CheckoutSnapshot(ctx, req.(*CheckoutSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cart_RestoreCheckout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreCheckoutRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CartServer).RestoreCheckout(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cart_RestoreCheckout_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CartServer).RestoreCheckout(ctx, req.(*RestoreCheckoutRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Cart_ServiceDesc is the grpc.ServiceDesc for Cart service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -230,6 +312,14 @@ var Cart_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ClearCart",
 			Handler:    _Cart_ClearCart_Handler,
 		},
+		{
+			MethodName: "CheckoutSnapshot",
+			Handler:    _Cart_CheckoutSnapshot_Handler,
+		},
+		{
+			MethodName: "RestoreCheckout",
+			Handler:    _Cart_RestoreCheckout_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "cart.proto",
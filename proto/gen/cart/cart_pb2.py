@@ -12,9 +12,10 @@ _sym_db = _symbol_database.Default()
 
 
 import common_pb2 as common__pb2
+from google.api import annotations_pb2 as google_dot_api_dot_annotations__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\ncart.proto\x12\x04\x63\x61rt\x1a\x0c\x63ommon.proto\"?\n\x0e\x41\x64\x64ItemRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0f\n\x07\x62ook_id\x18\x02 \x01(\x03\x12\x0b\n\x03qty\x18\x03 \x01(\x05\"B\n\x11RemoveItemRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0f\n\x07\x62ook_id\x18\x02 \x01(\x03\x12\x0b\n\x03qty\x18\x03 \x01(\x05\"}\n\x08\x43\x61rtItem\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\x12\r\n\x05title\x18\x02 \x01(\t\x12\x0b\n\x03qty\x18\x03 \x01(\x05\x12!\n\nunit_price\x18\x04 \x01(\x0b\x32\r.common.Money\x12!\n\nline_total\x18\x05 \x01(\x0b\x32\r.common.Money\"G\n\x08\x43\x61rtView\x12\x1d\n\x05items\x18\x01 \x03(\x0b\x32\x0e.cart.CartItem\x12\x1c\n\x05total\x18\x02 \x01(\x0b\x32\r.common.Money2\xc8\x01\n\x04\x43\x61rt\x12*\n\x07GetCart\x12\x0f.common.UserRef\x1a\x0e.cart.CartView\x12/\n\x07\x41\x64\x64Item\x12\x14.cart.AddItemRequest\x1a\x0e.cart.CartView\x12\x35\n\nRemoveItem\x12\x17.cart.RemoveItemRequest\x1a\x0e.cart.CartView\x12,\n\tClearCart\x12\x0f.common.UserRef\x1a\x0e.cart.CartViewB9Z7github.com/ahinestrog/mybookstore/proto/gen/cart;cartpbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\ncart.proto\x12\x04\x63\x61rt\x1a\x0c\x63ommon.proto\x1a\x1cgoogle/api/annotations.proto\"?\n\x0e\x41\x64\x64ItemRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0f\n\x07\x62ook_id\x18\x02 \x01(\x03\x12\x0b\n\x03qty\x18\x03 \x01(\x05\"B\n\x11RemoveItemRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0f\n\x07\x62ook_id\x18\x02 \x01(\x03\x12\x0b\n\x03qty\x18\x03 \x01(\x05\"\xa5\x01\n\x08\x43\x61rtItem\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\x12\r\n\x05title\x18\x02 \x01(\t\x12\x0b\n\x03qty\x18\x03 \x01(\x05\x12!\n\nunit_price\x18\x04 \x01(\x0b\x32\r.common.Money\x12!\n\nline_total\x18\x05 \x01(\x0b\x32\r.common.Money\x12\x10\n\x08\x63\x61tegory\x18\x06 \x01(\t\x12\x14\n\x0cweight_grams\x18\x07 \x01(\x05\"q\n\x08\x43\x61rtView\x12\x1d\n\x05items\x18\x01 \x03(\x0b\x32\x0e.cart.CartItem\x12\x1c\n\x05total\x18\x02 \x01(\x0b\x32\r.common.Money\x12(\n\x08\x65stimate\x18\x03 \x01(\x0b\x32\x16.common.PriceBreakdown\"?\n\x17\x43heckoutSnapshotRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x13\n\x0b\x63heckout_id\x18\x02 \x01(\t\"M\n\x18\x43heckoutSnapshotResponse\x12\x13\n\x0b\x63heckout_id\x18\x01 \x01(\t\x12\x1c\n\x04\x63\x61rt\x18\x02 \x01(\x0b\x32\x0e.cart.CartView\">\n\x16RestoreCheckoutRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x13\n\x0b\x63heckout_id\x18\x02 \x01(\t2\x8a\x04\n\x04\x43\x61rt\x12P\n\x07GetCart\x12\x0f.common.UserRef\x1a\x0e.cart.CartView\"$\x82\xd3\xe4\x93\x02\x1e\x12\x1c/api/v1/users/{user_id}/cart\x12^\n\x07\x41\x64\x64Item\x12\x14.cart.AddItemRequest\x1a\x0e.cart.CartView\"-\x82\xd3\xe4\x93\x02\':\x01*\"\"/api/v1/users/{user_id}/cart/items\x12k\n\nRemoveItem\x12\x17.cart.RemoveItemRequest\x1a\x0e.cart.CartView\"4\x82\xd3\xe4\x93\x02.*,/api/v1/users/{user_id}/cart/items/{book_id}\x12R\n\tClearCart\x12\x0f.common.UserRef\x1a\x0e.cart.CartView\"$\x82\xd3\xe4\x93\x02\x1e*\x1c/api/v1/users/{user_id}/cart\x12Q\n\x10\x43heckoutSnapshot\x12\x1d.cart.CheckoutSnapshotRequest\x1a\x1e.cart.CheckoutSnapshotResponse\x12<\n\x0fRestoreCheckout\x12\x1c.cart.RestoreCheckoutRequest\x1a\x0b.common.AckB9Z7github.com/ahinestrog/mybookstore/proto/gen/cart;cartpbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -22,14 +23,28 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'cart_pb2', _globals)
 if _descriptor._USE_C_DESCRIPTORS == False:
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z7github.com/ahinestrog/mybookstore/proto/gen/cart;cartpb'
-  _globals['_ADDITEMREQUEST']._serialized_start=34
-  _globals['_ADDITEMREQUEST']._serialized_end=97
-  _globals['_REMOVEITEMREQUEST']._serialized_start=99
-  _globals['_REMOVEITEMREQUEST']._serialized_end=165
-  _globals['_CARTITEM']._serialized_start=167
-  _globals['_CARTITEM']._serialized_end=292
-  _globals['_CARTVIEW']._serialized_start=294
-  _globals['_CARTVIEW']._serialized_end=365
-  _globals['_CART']._serialized_start=368
-  _globals['_CART']._serialized_end=568
+  _globals['_CART'].methods_by_name['GetCart']._options = None
+  _globals['_CART'].methods_by_name['GetCart']._serialized_options = b'\x82\xd3\xe4\x93\x02\x1e\x12\x1c/api/v1/users/{user_id}/cart'
+  _globals['_CART'].methods_by_name['AddItem']._options = None
+  _globals['_CART'].methods_by_name['AddItem']._serialized_options = b'\x82\xd3\xe4\x93\x02\':\x01*\"\"/api/v1/users/{user_id}/cart/items'
+  _globals['_CART'].methods_by_name['RemoveItem']._options = None
+  _globals['_CART'].methods_by_name['RemoveItem']._serialized_options = b'\x82\xd3\xe4\x93\x02.*,/api/v1/users/{user_id}/cart/items/{book_id}'
+  _globals['_CART'].methods_by_name['ClearCart']._options = None
+  _globals['_CART'].methods_by_name['ClearCart']._serialized_options = b'\x82\xd3\xe4\x93\x02\x1e*\x1c/api/v1/users/{user_id}/cart'
+  _globals['_ADDITEMREQUEST']._serialized_start=64
+  _globals['_ADDITEMREQUEST']._serialized_end=127
+  _globals['_REMOVEITEMREQUEST']._serialized_start=129
+  _globals['_REMOVEITEMREQUEST']._serialized_end=195
+  _globals['_CARTITEM']._serialized_start=198
+  _globals['_CARTITEM']._serialized_end=363
+  _globals['_CARTVIEW']._serialized_start=365
+  _globals['_CARTVIEW']._serialized_end=478
+  _globals['_CHECKOUTSNAPSHOTREQUEST']._serialized_start=480
+  _globals['_CHECKOUTSNAPSHOTREQUEST']._serialized_end=543
+  _globals['_CHECKOUTSNAPSHOTRESPONSE']._serialized_start=545
+  _globals['_CHECKOUTSNAPSHOTRESPONSE']._serialized_end=622
+  _globals['_RESTORECHECKOUTREQUEST']._serialized_start=624
+  _globals['_RESTORECHECKOUTREQUEST']._serialized_end=686
+  _globals['_CART']._serialized_start=689
+  _globals['_CART']._serialized_end=1211
 # @@protoc_insertion_point(module_scope)
@@ -35,6 +35,16 @@ class CartStub(object):
                 request_serializer=common__pb2.UserRef.SerializeToString,
                 response_deserializer=cart__pb2.CartView.FromString,
                 )
+        self.CheckoutSnapshot = channel.unary_unary(
+                '/cart.Cart/CheckoutSnapshot',
+                request_serializer=cart__pb2.CheckoutSnapshotRequest.SerializeToString,
+                response_deserializer=cart__pb2.CheckoutSnapshotResponse.FromString,
+                )
+        self.RestoreCheckout = channel.unary_unary(
+                '/cart.Cart/RestoreCheckout',
+                request_serializer=cart__pb2.RestoreCheckoutRequest.SerializeToString,
+                response_deserializer=common__pb2.Ack.FromString,
+                )
 
 
 class CartServicer(object):
@@ -64,6 +74,25 @@ class CartServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def CheckoutSnapshot(self, request, context):
+        """Congela el carrito bajo checkout_id y devuelve las líneas congeladas.
+        Las líneas salen del carrito vivo; se descartan o se devuelven al carrito
+        cuando Order publica el resultado final de la saga (order.paid / order.failed).
+        Repetir la llamada con el mismo checkout_id devuelve el mismo snapshot.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RestoreCheckout(self, request, context):
+        """Devuelve las líneas congeladas al carrito cuando Order no llegó a crear la
+        orden (falló al guardarla o el checkout quedó huérfano). No hace nada si el
+        checkout ya se resolvió.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_CartServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -87,6 +116,16 @@ def add_CartServicer_to_server(servicer, server):
                     request_deserializer=common__pb2.UserRef.FromString,
                     response_serializer=cart__pb2.CartView.SerializeToString,
             ),
+            'CheckoutSnapshot': grpc.unary_unary_rpc_method_handler(
+                    servicer.CheckoutSnapshot,
+                    request_deserializer=cart__pb2.CheckoutSnapshotRequest.FromString,
+                    response_serializer=cart__pb2.CheckoutSnapshotResponse.SerializeToString,
+            ),
+            'RestoreCheckout': grpc.unary_unary_rpc_method_handler(
+                    servicer.RestoreCheckout,
+                    request_deserializer=cart__pb2.RestoreCheckoutRequest.FromString,
+                    response_serializer=common__pb2.Ack.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'cart.Cart', rpc_method_handlers)
@@ -164,3 +203,37 @@ class Cart(object):
             cart__pb2.CartView.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CheckoutSnapshot(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/cart.Cart/CheckoutSnapshot',
+            cart__pb2.CheckoutSnapshotRequest.SerializeToString,
+            cart__pb2.CheckoutSnapshotResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RestoreCheckout(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/cart.Cart/RestoreCheckout',
+            cart__pb2.RestoreCheckoutRequest.SerializeToString,
+            common__pb2.Ack.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
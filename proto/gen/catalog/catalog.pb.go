@@ -8,6 +8,7 @@ package catalogpb
 
 import (
 	common "github.com/ahinestrog/mybookstore/proto/gen/common"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -178,6 +179,8 @@ type Book struct {
 	Price         *common.Money          `protobuf:"bytes,4,opt,name=price,proto3" json:"price,omitempty"`
 	CoverUrl      string                 `protobuf:"bytes,5,opt,name=cover_url,json=coverUrl,proto3" json:"cover_url,omitempty"`
 	CreatedUnix   int64                  `protobuf:"varint,6,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"` // timestamp en segundos (unix)
+	Category      string                 `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`                           // define la tarifa de IVA
+	WeightGrams   int32                  `protobuf:"varint,8,opt,name=weight_grams,json=weightGrams,proto3" json:"weight_grams,omitempty"` // para calcular el envío
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -254,11 +257,25 @@ func (x *Book) GetCreatedUnix() int64 {
 	return 0
 }
 
+func (x *Book) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Book) GetWeightGrams() int32 {
+	if x != nil {
+		return x.WeightGrams
+	}
+	return 0
+}
+
 var File_catalog_proto protoreflect.FileDescriptor
 
 const file_catalog_proto_rawDesc = "" +
 	"\n" +
-	"\rcatalog.proto\x12\acatalog\x1a\fcommon.proto\"I\n" +
+	"\rcatalog.proto\x12\acatalog\x1a\fcommon.proto\x1a\x1cgoogle/api/annotations.proto\"I\n" +
 	"\x10ListBooksRequest\x12\f\n" +
 	"\x01q\x18\x01 \x01(\tR\x01q\x12'\n" +
 	"\x04page\x18\x02 \x01(\v2\x13.common.PageRequestR\x04page\"b\n" +
@@ -266,17 +283,19 @@ const file_catalog_proto_rawDesc = "" +
 	"\x05items\x18\x01 \x03(\v2\r.catalog.BookR\x05items\x12(\n" +
 	"\x04page\x18\x02 \x01(\v2\x14.common.PageResponseR\x04page\" \n" +
 	"\x0eGetBookRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\xa9\x01\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xe8\x01\n" +
 	"\x04Book\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
 	"\x06author\x18\x03 \x01(\tR\x06author\x12#\n" +
 	"\x05price\x18\x04 \x01(\v2\r.common.MoneyR\x05price\x12\x1b\n" +
 	"\tcover_url\x18\x05 \x01(\tR\bcoverUrl\x12!\n" +
-	"\fcreated_unix\x18\x06 \x01(\x03R\vcreatedUnix2\x80\x01\n" +
-	"\aCatalog\x12B\n" +
-	"\tListBooks\x12\x19.catalog.ListBooksRequest\x1a\x1a.catalog.ListBooksResponse\x121\n" +
-	"\aGetBook\x12\x17.catalog.GetBookRequest\x1a\r.catalog.BookB?Z=github.com/ahinestrog/mybookstore/proto/gen/catalog;catalogpbb\x06proto3"
+	"\fcreated_unix\x18\x06 \x01(\x03R\vcreatedUnix\x12\x1a\n" +
+	"\bcategory\x18\a \x01(\tR\bcategory\x12!\n" +
+	"\fweight_grams\x18\b \x01(\x05R\vweightGrams2\xb3\x01\n" +
+	"\aCatalog\x12Y\n" +
+	"\tListBooks\x12\x19.catalog.ListBooksRequest\x1a\x1a.catalog.ListBooksResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v1/books\x12M\n" +
+	"\aGetBook\x12\x17.catalog.GetBookRequest\x1a\r.catalog.Book\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v1/books/{id}B?Z=github.com/ahinestrog/mybookstore/proto/gen/catalog;catalogpbb\x06proto3"
 
 var (
 	file_catalog_proto_rawDescOnce sync.Once
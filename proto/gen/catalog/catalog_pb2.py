@@ -12,9 +12,10 @@ _sym_db = _symbol_database.Default()
 
 
 import common_pb2 as common__pb2
+from google.api import annotations_pb2 as google_dot_api_dot_annotations__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\rcatalog.proto\x12\x07\x63\x61talog\x1a\x0c\x63ommon.proto\"@\n\x10ListBooksRequest\x12\t\n\x01q\x18\x01 \x01(\t\x12!\n\x04page\x18\x02 \x01(\x0b\x32\x13.common.PageRequest\"U\n\x11ListBooksResponse\x12\x1c\n\x05items\x18\x01 \x03(\x0b\x32\r.catalog.Book\x12\"\n\x04page\x18\x02 \x01(\x0b\x32\x14.common.PageResponse\"\x1c\n\x0eGetBookRequest\x12\n\n\x02id\x18\x01 \x01(\x03\"x\n\x04\x42ook\x12\n\n\x02id\x18\x01 \x01(\x03\x12\r\n\x05title\x18\x02 \x01(\t\x12\x0e\n\x06\x61uthor\x18\x03 \x01(\t\x12\x1c\n\x05price\x18\x04 \x01(\x0b\x32\r.common.Money\x12\x11\n\tcover_url\x18\x05 \x01(\t\x12\x14\n\x0c\x63reated_unix\x18\x06 \x01(\x03\x32\x80\x01\n\x07\x43\x61talog\x12\x42\n\tListBooks\x12\x19.catalog.ListBooksRequest\x1a\x1a.catalog.ListBooksResponse\x12\x31\n\x07GetBook\x12\x17.catalog.GetBookRequest\x1a\r.catalog.BookB?Z=github.com/ahinestrog/mybookstore/proto/gen/catalog;catalogpbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\rcatalog.proto\x12\x07\x63\x61talog\x1a\x0c\x63ommon.proto\x1a\x1cgoogle/api/annotations.proto\"@\n\x10ListBooksRequest\x12\t\n\x01q\x18\x01 \x01(\t\x12!\n\x04page\x18\x02 \x01(\x0b\x32\x13.common.PageRequest\"U\n\x11ListBooksResponse\x12\x1c\n\x05items\x18\x01 \x03(\x0b\x32\r.catalog.Book\x12\"\n\x04page\x18\x02 \x01(\x0b\x32\x14.common.PageResponse\"\x1c\n\x0eGetBookRequest\x12\n\n\x02id\x18\x01 \x01(\x03\"\xa0\x01\n\x04\x42ook\x12\n\n\x02id\x18\x01 \x01(\x03\x12\r\n\x05title\x18\x02 \x01(\t\x12\x0e\n\x06\x61uthor\x18\x03 \x01(\t\x12\x1c\n\x05price\x18\x04 \x01(\x0b\x32\r.common.Money\x12\x11\n\tcover_url\x18\x05 \x01(\t\x12\x14\n\x0c\x63reated_unix\x18\x06 \x01(\x03\x12\x10\n\x08\x63\x61tegory\x18\x07 \x01(\t\x12\x14\n\x0cweight_grams\x18\x08 \x01(\x05\x32\xb3\x01\n\x07\x43\x61talog\x12Y\n\tListBooks\x12\x19.catalog.ListBooksRequest\x1a\x1a.catalog.ListBooksResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v1/books\x12M\n\x07GetBook\x12\x17.catalog.GetBookRequest\x1a\r.catalog.Book\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v1/books/{id}B?Z=github.com/ahinestrog/mybookstore/proto/gen/catalog;catalogpbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -22,14 +23,18 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'catalog_pb2', _globals)
 if _descriptor._USE_C_DESCRIPTORS == False:
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z=github.com/ahinestrog/mybookstore/proto/gen/catalog;catalogpb'
-  _globals['_LISTBOOKSREQUEST']._serialized_start=40
-  _globals['_LISTBOOKSREQUEST']._serialized_end=104
-  _globals['_LISTBOOKSRESPONSE']._serialized_start=106
-  _globals['_LISTBOOKSRESPONSE']._serialized_end=191
-  _globals['_GETBOOKREQUEST']._serialized_start=193
-  _globals['_GETBOOKREQUEST']._serialized_end=221
-  _globals['_BOOK']._serialized_start=223
-  _globals['_BOOK']._serialized_end=343
-  _globals['_CATALOG']._serialized_start=346
-  _globals['_CATALOG']._serialized_end=474
+  _globals['_CATALOG'].methods_by_name['ListBooks']._options = None
+  _globals['_CATALOG'].methods_by_name['ListBooks']._serialized_options = b'\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v1/books'
+  _globals['_CATALOG'].methods_by_name['GetBook']._options = None
+  _globals['_CATALOG'].methods_by_name['GetBook']._serialized_options = b'\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v1/books/{id}'
+  _globals['_LISTBOOKSREQUEST']._serialized_start=70
+  _globals['_LISTBOOKSREQUEST']._serialized_end=134
+  _globals['_LISTBOOKSRESPONSE']._serialized_start=136
+  _globals['_LISTBOOKSRESPONSE']._serialized_end=221
+  _globals['_GETBOOKREQUEST']._serialized_start=223
+  _globals['_GETBOOKREQUEST']._serialized_end=251
+  _globals['_BOOK']._serialized_start=254
+  _globals['_BOOK']._serialized_end=414
+  _globals['_CATALOG']._serialized_start=417
+  _globals['_CATALOG']._serialized_end=596
 # @@protoc_insertion_point(module_scope)
@@ -21,6 +21,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Medio de pago elegido en el checkout.
+type PaymentMethod int32
+
+const (
+	PaymentMethod_PAYMENT_METHOD_UNSPECIFIED      PaymentMethod = 0 // el que indique PAYMENT_PROVIDER
+	PaymentMethod_PAYMENT_METHOD_PSE              PaymentMethod = 1 // transferencia bancaria
+	PaymentMethod_PAYMENT_METHOD_CARD             PaymentMethod = 2 // tarjeta guardada (instrumento tokenizado)
+	PaymentMethod_PAYMENT_METHOD_CASH_ON_DELIVERY PaymentMethod = 3 // se cobra al entregar
+	PaymentMethod_PAYMENT_METHOD_STORE_CREDIT     PaymentMethod = 4
+)
+
+// Enum value maps for PaymentMethod.
+var (
+	PaymentMethod_name = map[int32]string{
+		0: "PAYMENT_METHOD_UNSPECIFIED",
+		1: "PAYMENT_METHOD_PSE",
+		2: "PAYMENT_METHOD_CARD",
+		3: "PAYMENT_METHOD_CASH_ON_DELIVERY",
+		4: "PAYMENT_METHOD_STORE_CREDIT",
+	}
+	PaymentMethod_value = map[string]int32{
+		"PAYMENT_METHOD_UNSPECIFIED":      0,
+		"PAYMENT_METHOD_PSE":              1,
+		"PAYMENT_METHOD_CARD":             2,
+		"PAYMENT_METHOD_CASH_ON_DELIVERY": 3,
+		"PAYMENT_METHOD_STORE_CREDIT":     4,
+	}
+)
+
+func (x PaymentMethod) Enum() *PaymentMethod {
+	p := new(PaymentMethod)
+	*p = x
+	return p
+}
+
+func (x PaymentMethod) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PaymentMethod) Descriptor() protoreflect.EnumDescriptor {
+	return file_common_proto_enumTypes[0].Descriptor()
+}
+
+func (PaymentMethod) Type() protoreflect.EnumType {
+	return &file_common_proto_enumTypes[0]
+}
+
+func (x PaymentMethod) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PaymentMethod.Descriptor instead.
+func (PaymentMethod) EnumDescriptor() ([]byte, []int) {
+	return file_common_proto_rawDescGZIP(), []int{0}
+}
+
 // Representa dinero en centavos para evitar errores de punto flotante.
 type Money struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -320,6 +376,184 @@ func (x *OrderRef) GetOrderId() int64 {
 	return 0
 }
 
+// Dirección postal de envío (se copia tal cual a la orden al hacer checkout).
+type Address struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Recipient     string                 `protobuf:"bytes,1,opt,name=recipient,proto3" json:"recipient,omitempty"`
+	Line1         string                 `protobuf:"bytes,2,opt,name=line1,proto3" json:"line1,omitempty"`
+	Line2         string                 `protobuf:"bytes,3,opt,name=line2,proto3" json:"line2,omitempty"`
+	City          string                 `protobuf:"bytes,4,opt,name=city,proto3" json:"city,omitempty"`
+	Region        string                 `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"` // departamento / estado
+	PostalCode    string                 `protobuf:"bytes,6,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Country       string                 `protobuf:"bytes,7,opt,name=country,proto3" json:"country,omitempty"` // ISO 3166-1 alfa-2, p.ej. "CO"
+	Phone         string                 `protobuf:"bytes,8,opt,name=phone,proto3" json:"phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	mi := &file_common_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_common_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_common_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Address) GetRecipient() string {
+	if x != nil {
+		return x.Recipient
+	}
+	return ""
+}
+
+func (x *Address) GetLine1() string {
+	if x != nil {
+		return x.Line1
+	}
+	return ""
+}
+
+func (x *Address) GetLine2() string {
+	if x != nil {
+		return x.Line2
+	}
+	return ""
+}
+
+func (x *Address) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Address) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Address) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *Address) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Address) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+// Desglose de precios de una compra. total = subtotal - discount + tax + shipping.
+type PriceBreakdown struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subtotal      *Money                 `protobuf:"bytes,1,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	Discount      *Money                 `protobuf:"bytes,2,opt,name=discount,proto3" json:"discount,omitempty"`
+	Tax           *Money                 `protobuf:"bytes,3,opt,name=tax,proto3" json:"tax,omitempty"` // IVA según la categoría de cada libro
+	Shipping      *Money                 `protobuf:"bytes,4,opt,name=shipping,proto3" json:"shipping,omitempty"`
+	GrandTotal    *Money                 `protobuf:"bytes,5,opt,name=grand_total,json=grandTotal,proto3" json:"grand_total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceBreakdown) Reset() {
+	*x = PriceBreakdown{}
+	mi := &file_common_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceBreakdown) ProtoMessage() {}
+
+func (x *PriceBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_common_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceBreakdown.ProtoReflect.Descriptor instead.
+func (*PriceBreakdown) Descriptor() ([]byte, []int) {
+	return file_common_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PriceBreakdown) GetSubtotal() *Money {
+	if x != nil {
+		return x.Subtotal
+	}
+	return nil
+}
+
+func (x *PriceBreakdown) GetDiscount() *Money {
+	if x != nil {
+		return x.Discount
+	}
+	return nil
+}
+
+func (x *PriceBreakdown) GetTax() *Money {
+	if x != nil {
+		return x.Tax
+	}
+	return nil
+}
+
+func (x *PriceBreakdown) GetShipping() *Money {
+	if x != nil {
+		return x.Shipping
+	}
+	return nil
+}
+
+func (x *PriceBreakdown) GetGrandTotal() *Money {
+	if x != nil {
+		return x.GrandTotal
+	}
+	return nil
+}
+
 // Para respuestas simples de “ok”.
 type Ack struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -331,7 +565,7 @@ type Ack struct {
 
 func (x *Ack) Reset() {
 	*x = Ack{}
-	mi := &file_common_proto_msgTypes[6]
+	mi := &file_common_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -343,7 +577,7 @@ func (x *Ack) String() string {
 func (*Ack) ProtoMessage() {}
 
 func (x *Ack) ProtoReflect() protoreflect.Message {
-	mi := &file_common_proto_msgTypes[6]
+	mi := &file_common_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -356,7 +590,7 @@ func (x *Ack) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Ack.ProtoReflect.Descriptor instead.
 func (*Ack) Descriptor() ([]byte, []int) {
-	return file_common_proto_rawDescGZIP(), []int{6}
+	return file_common_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Ack) GetOk() bool {
@@ -395,10 +629,33 @@ const file_common_proto_rawDesc = "" +
 	"\aBookRef\x12\x17\n" +
 	"\abook_id\x18\x01 \x01(\x03R\x06bookId\"%\n" +
 	"\bOrderRef\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\x03R\aorderId\"/\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"\xd0\x01\n" +
+	"\aAddress\x12\x1c\n" +
+	"\trecipient\x18\x01 \x01(\tR\trecipient\x12\x14\n" +
+	"\x05line1\x18\x02 \x01(\tR\x05line1\x12\x14\n" +
+	"\x05line2\x18\x03 \x01(\tR\x05line2\x12\x12\n" +
+	"\x04city\x18\x04 \x01(\tR\x04city\x12\x16\n" +
+	"\x06region\x18\x05 \x01(\tR\x06region\x12\x1f\n" +
+	"\vpostal_code\x18\x06 \x01(\tR\n" +
+	"postalCode\x12\x18\n" +
+	"\acountry\x18\a \x01(\tR\acountry\x12\x14\n" +
+	"\x05phone\x18\b \x01(\tR\x05phone\"\xe2\x01\n" +
+	"\x0ePriceBreakdown\x12)\n" +
+	"\bsubtotal\x18\x01 \x01(\v2\r.common.MoneyR\bsubtotal\x12)\n" +
+	"\bdiscount\x18\x02 \x01(\v2\r.common.MoneyR\bdiscount\x12\x1f\n" +
+	"\x03tax\x18\x03 \x01(\v2\r.common.MoneyR\x03tax\x12)\n" +
+	"\bshipping\x18\x04 \x01(\v2\r.common.MoneyR\bshipping\x12.\n" +
+	"\vgrand_total\x18\x05 \x01(\v2\r.common.MoneyR\n" +
+	"grandTotal\"/\n" +
 	"\x03Ack\x12\x0e\n" +
 	"\x02ok\x18\x01 \x01(\bR\x02ok\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessageB=Z;github.com/ahinestrog/mybookstore/proto/gen/common;commonpbb\x06proto3"
+	"\amessage\x18\x02 \x01(\tR\amessage*\xa6\x01\n" +
+	"\rPaymentMethod\x12\x1e\n" +
+	"\x1aPAYMENT_METHOD_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12PAYMENT_METHOD_PSE\x10\x01\x12\x17\n" +
+	"\x13PAYMENT_METHOD_CARD\x10\x02\x12#\n" +
+	"\x1fPAYMENT_METHOD_CASH_ON_DELIVERY\x10\x03\x12\x1f\n" +
+	"\x1bPAYMENT_METHOD_STORE_CREDIT\x10\x04B=Z;github.com/ahinestrog/mybookstore/proto/gen/common;commonpbb\x06proto3"
 
 var (
 	file_common_proto_rawDescOnce sync.Once
@@ -412,22 +669,31 @@ func file_common_proto_rawDescGZIP() []byte {
 	return file_common_proto_rawDescData
 }
 
-var file_common_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_common_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_common_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_common_proto_goTypes = []any{
-	(*Money)(nil),        // 0: common.Money
-	(*PageRequest)(nil),  // 1: common.PageRequest
-	(*PageResponse)(nil), // 2: common.PageResponse
-	(*UserRef)(nil),      // 3: common.UserRef
-	(*BookRef)(nil),      // 4: common.BookRef
-	(*OrderRef)(nil),     // 5: common.OrderRef
-	(*Ack)(nil),          // 6: common.Ack
+	(PaymentMethod)(0),     // 0: common.PaymentMethod
+	(*Money)(nil),          // 1: common.Money
+	(*PageRequest)(nil),    // 2: common.PageRequest
+	(*PageResponse)(nil),   // 3: common.PageResponse
+	(*UserRef)(nil),        // 4: common.UserRef
+	(*BookRef)(nil),        // 5: common.BookRef
+	(*OrderRef)(nil),       // 6: common.OrderRef
+	(*Address)(nil),        // 7: common.Address
+	(*PriceBreakdown)(nil), // 8: common.PriceBreakdown
+	(*Ack)(nil),            // 9: common.Ack
 }
 var file_common_proto_depIdxs = []int32{
-	0, // [0:0] is the sub-list for method output_type
-	0, // [0:0] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1, // 0: common.PriceBreakdown.subtotal:type_name -> common.Money
+	1, // 1: common.PriceBreakdown.discount:type_name -> common.Money
+	1, // 2: common.PriceBreakdown.tax:type_name -> common.Money
+	1, // 3: common.PriceBreakdown.shipping:type_name -> common.Money
+	1, // 4: common.PriceBreakdown.grand_total:type_name -> common.Money
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_common_proto_init() }
@@ -440,13 +706,14 @@ func file_common_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_common_proto_rawDesc), len(file_common_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      1,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_common_proto_goTypes,
 		DependencyIndexes: file_common_proto_depIdxs,
+		EnumInfos:         file_common_proto_enumTypes,
 		MessageInfos:      file_common_proto_msgTypes,
 	}.Build()
 	File_common_proto = out.File
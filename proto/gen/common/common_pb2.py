@@ -13,7 +13,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0c\x63ommon.proto\x12\x06\x63ommon\"\x16\n\x05Money\x12\r\n\x05\x63\x65nts\x18\x01 \x01(\x03\".\n\x0bPageRequest\x12\x0c\n\x04page\x18\x01 \x01(\x05\x12\x11\n\tpage_size\x18\x02 \x01(\x05\"Y\n\x0cPageResponse\x12\x0c\n\x04page\x18\x01 \x01(\x05\x12\x11\n\tpage_size\x18\x02 \x01(\x05\x12\x13\n\x0btotal_pages\x18\x03 \x01(\x05\x12\x13\n\x0btotal_items\x18\x04 \x01(\x03\"\x1a\n\x07UserRef\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\"\x1a\n\x07\x42ookRef\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\"\x1c\n\x08OrderRef\x12\x10\n\x08order_id\x18\x01 \x01(\x03\"\"\n\x03\x41\x63k\x12\n\n\x02ok\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\tB=Z;github.com/ahinestrog/mybookstore/proto/gen/common;commonpbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0c\x63ommon.proto\x12\x06\x63ommon\"\x16\n\x05Money\x12\r\n\x05\x63\x65nts\x18\x01 \x01(\x03\".\n\x0bPageRequest\x12\x0c\n\x04page\x18\x01 \x01(\x05\x12\x11\n\tpage_size\x18\x02 \x01(\x05\"Y\n\x0cPageResponse\x12\x0c\n\x04page\x18\x01 \x01(\x05\x12\x11\n\tpage_size\x18\x02 \x01(\x05\x12\x13\n\x0btotal_pages\x18\x03 \x01(\x05\x12\x13\n\x0btotal_items\x18\x04 \x01(\x03\"\x1a\n\x07UserRef\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\"\x1a\n\x07\x42ookRef\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\"\x1c\n\x08OrderRef\x12\x10\n\x08order_id\x18\x01 \x01(\x03\"\x8d\x01\n\x07\x41\x64\x64ress\x12\x11\n\trecipient\x18\x01 \x01(\t\x12\r\n\x05line1\x18\x02 \x01(\t\x12\r\n\x05line2\x18\x03 \x01(\t\x12\x0c\n\x04\x63ity\x18\x04 \x01(\t\x12\x0e\n\x06region\x18\x05 \x01(\t\x12\x13\n\x0bpostal_code\x18\x06 \x01(\t\x12\x0f\n\x07\x63ountry\x18\x07 \x01(\t\x12\r\n\x05phone\x18\x08 \x01(\t\"\xb3\x01\n\x0ePriceBreakdown\x12\x1f\n\x08subtotal\x18\x01 \x01(\x0b\x32\r.common.Money\x12\x1f\n\x08\x64iscount\x18\x02 \x01(\x0b\x32\r.common.Money\x12\x1a\n\x03tax\x18\x03 \x01(\x0b\x32\r.common.Money\x12\x1f\n\x08shipping\x18\x04 \x01(\x0b\x32\r.common.Money\x12\"\n\x0bgrand_total\x18\x05 \x01(\x0b\x32\r.common.Money\"\"\n\x03\x41\x63k\x12\n\n\x02ok\x18\x01 \x01(\x08\x12\x0f\n\x07message\x18\x02 \x01(\t*\xa6\x01\n\rPaymentMethod\x12\x1e\n\x1aPAYMENT_METHOD_UNSPECIFIED\x10\x00\x12\x16\n\x12PAYMENT_METHOD_PSE\x10\x01\x12\x17\n\x13PAYMENT_METHOD_CARD\x10\x02\x12#\n\x1fPAYMENT_METHOD_CASH_ON_DELIVERY\x10\x03\x12\x1f\n\x1bPAYMENT_METHOD_STORE_CREDIT\x10\x04\x42=Z;github.com/ahinestrog/mybookstore/proto/gen/common;commonpbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -21,6 +21,8 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'common_pb2', _globals)
 if _descriptor._USE_C_DESCRIPTORS == False:
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z;github.com/ahinestrog/mybookstore/proto/gen/common;commonpb'
+  _globals['_PAYMENTMETHOD']._serialized_start=636
+  _globals['_PAYMENTMETHOD']._serialized_end=802
   _globals['_MONEY']._serialized_start=24
   _globals['_MONEY']._serialized_end=46
   _globals['_PAGEREQUEST']._serialized_start=48
@@ -33,6 +35,10 @@ if _descriptor._USE_C_DESCRIPTORS == False:
   _globals['_BOOKREF']._serialized_end=241
   _globals['_ORDERREF']._serialized_start=243
   _globals['_ORDERREF']._serialized_end=271
-  _globals['_ACK']._serialized_start=273
-  _globals['_ACK']._serialized_end=307
+  _globals['_ADDRESS']._serialized_start=274
+  _globals['_ADDRESS']._serialized_end=415
+  _globals['_PRICEBREAKDOWN']._serialized_start=418
+  _globals['_PRICEBREAKDOWN']._serialized_end=597
+  _globals['_ACK']._serialized_start=599
+  _globals['_ACK']._serialized_end=633
 # @@protoc_insertion_point(module_scope)
@@ -8,6 +8,7 @@ package inventorypb
 
 import (
 	_ "github.com/ahinestrog/mybookstore/proto/gen/common"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -221,7 +222,7 @@ var File_inventory_proto protoreflect.FileDescriptor
 
 const file_inventory_proto_rawDesc = "" +
 	"\n" +
-	"\x0finventory.proto\x12\tinventory\x1a\fcommon.proto\"3\n" +
+	"\x0finventory.proto\x12\tinventory\x1a\fcommon.proto\x1a\x1cgoogle/api/annotations.proto\"3\n" +
 	"\x16GetAvailabilityRequest\x12\x19\n" +
 	"\bbook_ids\x18\x01 \x03(\x03R\abookIds\"I\n" +
 	"\tStockItem\x12\x17\n" +
@@ -234,9 +235,9 @@ const file_inventory_proto_rawDesc = "" +
 	"\x1aRESERVATION_STATE_RESERVED\x10\x01\x12\x1f\n" +
 	"\x1bRESERVATION_STATE_CONFIRMED\x10\x02\x12\x1e\n" +
 	"\x1aRESERVATION_STATE_RELEASED\x10\x03\x12\x1c\n" +
-	"\x18RESERVATION_STATE_FAILED\x10\x042e\n" +
-	"\tInventory\x12X\n" +
-	"\x0fGetAvailability\x12!.inventory.GetAvailabilityRequest\x1a\".inventory.GetAvailabilityResponseBCZAgithub.com/ahinestrog/mybookstore/proto/gen/inventory;inventorypbb\x06proto3"
+	"\x18RESERVATION_STATE_FAILED\x10\x042\x83\x01\n" +
+	"\tInventory\x12v\n" +
+	"\x0fGetAvailability\x12!.inventory.GetAvailabilityRequest\x1a\".inventory.GetAvailabilityResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/api/v1/availabilityBCZAgithub.com/ahinestrog/mybookstore/proto/gen/inventory;inventorypbb\x06proto3"
 
 var (
 	file_inventory_proto_rawDescOnce sync.Once
@@ -12,9 +12,10 @@ _sym_db = _symbol_database.Default()
 
 
 import common_pb2 as common__pb2
+from google.api import annotations_pb2 as google_dot_api_dot_annotations__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0finventory.proto\x12\tinventory\x1a\x0c\x63ommon.proto\"*\n\x16GetAvailabilityRequest\x12\x10\n\x08\x62ook_ids\x18\x01 \x03(\x03\"3\n\tStockItem\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\x12\x15\n\ravailable_qty\x18\x02 \x01(\x05\">\n\x17GetAvailabilityResponse\x12#\n\x05items\x18\x01 \x03(\x0b\x32\x14.inventory.StockItem*\xb4\x01\n\x10ReservationState\x12!\n\x1dRESERVATION_STATE_UNSPECIFIED\x10\x00\x12\x1e\n\x1aRESERVATION_STATE_RESERVED\x10\x01\x12\x1f\n\x1bRESERVATION_STATE_CONFIRMED\x10\x02\x12\x1e\n\x1aRESERVATION_STATE_RELEASED\x10\x03\x12\x1c\n\x18RESERVATION_STATE_FAILED\x10\x04\x32\x65\n\tInventory\x12X\n\x0fGetAvailability\x12!.inventory.GetAvailabilityRequest\x1a\".inventory.GetAvailabilityResponseBCZAgithub.com/ahinestrog/mybookstore/proto/gen/inventory;inventorypbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0finventory.proto\x12\tinventory\x1a\x0c\x63ommon.proto\x1a\x1cgoogle/api/annotations.proto\"*\n\x16GetAvailabilityRequest\x12\x10\n\x08\x62ook_ids\x18\x01 \x03(\x03\"3\n\tStockItem\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\x12\x15\n\ravailable_qty\x18\x02 \x01(\x05\">\n\x17GetAvailabilityResponse\x12#\n\x05items\x18\x01 \x03(\x0b\x32\x14.inventory.StockItem*\xb4\x01\n\x10ReservationState\x12!\n\x1dRESERVATION_STATE_UNSPECIFIED\x10\x00\x12\x1e\n\x1aRESERVATION_STATE_RESERVED\x10\x01\x12\x1f\n\x1bRESERVATION_STATE_CONFIRMED\x10\x02\x12\x1e\n\x1aRESERVATION_STATE_RELEASED\x10\x03\x12\x1c\n\x18RESERVATION_STATE_FAILED\x10\x04\x32\x83\x01\n\tInventory\x12v\n\x0fGetAvailability\x12!.inventory.GetAvailabilityRequest\x1a\".inventory.GetAvailabilityResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/api/v1/availabilityBCZAgithub.com/ahinestrog/mybookstore/proto/gen/inventory;inventorypbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -22,14 +23,16 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'inventory_pb2', _globals)
 if _descriptor._USE_C_DESCRIPTORS == False:
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'ZAgithub.com/ahinestrog/mybookstore/proto/gen/inventory;inventorypb'
-  _globals['_RESERVATIONSTATE']._serialized_start=206
-  _globals['_RESERVATIONSTATE']._serialized_end=386
-  _globals['_GETAVAILABILITYREQUEST']._serialized_start=44
-  _globals['_GETAVAILABILITYREQUEST']._serialized_end=86
-  _globals['_STOCKITEM']._serialized_start=88
-  _globals['_STOCKITEM']._serialized_end=139
-  _globals['_GETAVAILABILITYRESPONSE']._serialized_start=141
-  _globals['_GETAVAILABILITYRESPONSE']._serialized_end=203
-  _globals['_INVENTORY']._serialized_start=388
-  _globals['_INVENTORY']._serialized_end=489
+  _globals['_INVENTORY'].methods_by_name['GetAvailability']._options = None
+  _globals['_INVENTORY'].methods_by_name['GetAvailability']._serialized_options = b'\x82\xd3\xe4\x93\x02\x16\x12\x14/api/v1/availability'
+  _globals['_RESERVATIONSTATE']._serialized_start=236
+  _globals['_RESERVATIONSTATE']._serialized_end=416
+  _globals['_GETAVAILABILITYREQUEST']._serialized_start=74
+  _globals['_GETAVAILABILITYREQUEST']._serialized_end=116
+  _globals['_STOCKITEM']._serialized_start=118
+  _globals['_STOCKITEM']._serialized_end=169
+  _globals['_GETAVAILABILITYRESPONSE']._serialized_start=171
+  _globals['_GETAVAILABILITYRESPONSE']._serialized_end=233
+  _globals['_INVENTORY']._serialized_start=419
+  _globals['_INVENTORY']._serialized_end=550
 # @@protoc_insertion_point(module_scope)
@@ -0,0 +1,8 @@
+// Package openapi embebe el documento OpenAPI de /api/v1, generado desde las
+// anotaciones google.api.http de los .proto (scripts/gen_stubs.sh).
+package openapi
+
+import _ "embed"
+
+//go:embed mybookstore.swagger.json
+var JSON []byte
@@ -8,6 +8,7 @@ package orderpb
 
 import (
 	common "github.com/ahinestrog/mybookstore/proto/gen/common"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -30,6 +31,7 @@ const (
 	OrderStatus_ORDER_STATUS_PAID        OrderStatus = 2 // cobrada (y stock confirmado)
 	OrderStatus_ORDER_STATUS_CANCELLED   OrderStatus = 3 // cancelada por usuario o fallo
 	OrderStatus_ORDER_STATUS_FAILED      OrderStatus = 4 // falló proceso backend
+	OrderStatus_ORDER_STATUS_CONFIRMED   OrderStatus = 5 // contra entrega: en despacho, se cobra al entregar
 )
 
 // Enum value maps for OrderStatus.
@@ -40,6 +42,7 @@ var (
 		2: "ORDER_STATUS_PAID",
 		3: "ORDER_STATUS_CANCELLED",
 		4: "ORDER_STATUS_FAILED",
+		5: "ORDER_STATUS_CONFIRMED",
 	}
 	OrderStatus_value = map[string]int32{
 		"ORDER_STATUS_UNSPECIFIED": 0,
@@ -47,6 +50,7 @@ var (
 		"ORDER_STATUS_PAID":        2,
 		"ORDER_STATUS_CANCELLED":   3,
 		"ORDER_STATUS_FAILED":      4,
+		"ORDER_STATUS_CONFIRMED":   5,
 	}
 )
 
@@ -77,32 +81,918 @@ func (OrderStatus) EnumDescriptor() ([]byte, []int) {
 	return file_order_proto_rawDescGZIP(), []int{0}
 }
 
+// Estados del despacho; solo avanzan hacia adelante.
+type FulfillmentState int32
+
+const (
+	FulfillmentState_FULFILLMENT_STATE_UNSPECIFIED FulfillmentState = 0
+	FulfillmentState_FULFILLMENT_STATE_PENDING     FulfillmentState = 1 // orden pagada, sin procesar en bodega
+	FulfillmentState_FULFILLMENT_STATE_PICKING     FulfillmentState = 2
+	FulfillmentState_FULFILLMENT_STATE_PACKED      FulfillmentState = 3
+	FulfillmentState_FULFILLMENT_STATE_SHIPPED     FulfillmentState = 4 // entregado a la transportadora
+	FulfillmentState_FULFILLMENT_STATE_DELIVERED   FulfillmentState = 5
+)
+
+// Enum value maps for FulfillmentState.
+var (
+	FulfillmentState_name = map[int32]string{
+		0: "FULFILLMENT_STATE_UNSPECIFIED",
+		1: "FULFILLMENT_STATE_PENDING",
+		2: "FULFILLMENT_STATE_PICKING",
+		3: "FULFILLMENT_STATE_PACKED",
+		4: "FULFILLMENT_STATE_SHIPPED",
+		5: "FULFILLMENT_STATE_DELIVERED",
+	}
+	FulfillmentState_value = map[string]int32{
+		"FULFILLMENT_STATE_UNSPECIFIED": 0,
+		"FULFILLMENT_STATE_PENDING":     1,
+		"FULFILLMENT_STATE_PICKING":     2,
+		"FULFILLMENT_STATE_PACKED":      3,
+		"FULFILLMENT_STATE_SHIPPED":     4,
+		"FULFILLMENT_STATE_DELIVERED":   5,
+	}
+)
+
+func (x FulfillmentState) Enum() *FulfillmentState {
+	p := new(FulfillmentState)
+	*p = x
+	return p
+}
+
+func (x FulfillmentState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FulfillmentState) Descriptor() protoreflect.EnumDescriptor {
+	return file_order_proto_enumTypes[1].Descriptor()
+}
+
+func (FulfillmentState) Type() protoreflect.EnumType {
+	return &file_order_proto_enumTypes[1]
+}
+
+func (x FulfillmentState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FulfillmentState.Descriptor instead.
+func (FulfillmentState) EnumDescriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{1}
+}
+
+// Estados de una devolución (RMA).
+type ReturnState int32
+
+const (
+	ReturnState_RETURN_STATE_UNSPECIFIED   ReturnState = 0
+	ReturnState_RETURN_STATE_REQUESTED     ReturnState = 1 // pendiente de revisión
+	ReturnState_RETURN_STATE_APPROVED      ReturnState = 2 // el cliente puede enviar los libros
+	ReturnState_RETURN_STATE_REJECTED      ReturnState = 3
+	ReturnState_RETURN_STATE_RECEIVED      ReturnState = 4 // recibido en bodega; reembolso en curso
+	ReturnState_RETURN_STATE_REFUNDED      ReturnState = 5
+	ReturnState_RETURN_STATE_REFUND_FAILED ReturnState = 6
+)
+
+// Enum value maps for ReturnState.
+var (
+	ReturnState_name = map[int32]string{
+		0: "RETURN_STATE_UNSPECIFIED",
+		1: "RETURN_STATE_REQUESTED",
+		2: "RETURN_STATE_APPROVED",
+		3: "RETURN_STATE_REJECTED",
+		4: "RETURN_STATE_RECEIVED",
+		5: "RETURN_STATE_REFUNDED",
+		6: "RETURN_STATE_REFUND_FAILED",
+	}
+	ReturnState_value = map[string]int32{
+		"RETURN_STATE_UNSPECIFIED":   0,
+		"RETURN_STATE_REQUESTED":     1,
+		"RETURN_STATE_APPROVED":      2,
+		"RETURN_STATE_REJECTED":      3,
+		"RETURN_STATE_RECEIVED":      4,
+		"RETURN_STATE_REFUNDED":      5,
+		"RETURN_STATE_REFUND_FAILED": 6,
+	}
+)
+
+func (x ReturnState) Enum() *ReturnState {
+	p := new(ReturnState)
+	*p = x
+	return p
+}
+
+func (x ReturnState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReturnState) Descriptor() protoreflect.EnumDescriptor {
+	return file_order_proto_enumTypes[2].Descriptor()
+}
+
+func (ReturnState) Type() protoreflect.EnumType {
+	return &file_order_proto_enumTypes[2]
+}
+
+func (x ReturnState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReturnState.Descriptor instead.
+func (ReturnState) EnumDescriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{2}
+}
+
+type ReturnReason int32
+
+const (
+	ReturnReason_RETURN_REASON_UNSPECIFIED      ReturnReason = 0
+	ReturnReason_RETURN_REASON_DAMAGED          ReturnReason = 1
+	ReturnReason_RETURN_REASON_WRONG_ITEM       ReturnReason = 2
+	ReturnReason_RETURN_REASON_NOT_AS_DESCRIBED ReturnReason = 3
+	ReturnReason_RETURN_REASON_NO_LONGER_NEEDED ReturnReason = 4
+	ReturnReason_RETURN_REASON_OTHER            ReturnReason = 5
+)
+
+// Enum value maps for ReturnReason.
+var (
+	ReturnReason_name = map[int32]string{
+		0: "RETURN_REASON_UNSPECIFIED",
+		1: "RETURN_REASON_DAMAGED",
+		2: "RETURN_REASON_WRONG_ITEM",
+		3: "RETURN_REASON_NOT_AS_DESCRIBED",
+		4: "RETURN_REASON_NO_LONGER_NEEDED",
+		5: "RETURN_REASON_OTHER",
+	}
+	ReturnReason_value = map[string]int32{
+		"RETURN_REASON_UNSPECIFIED":      0,
+		"RETURN_REASON_DAMAGED":          1,
+		"RETURN_REASON_WRONG_ITEM":       2,
+		"RETURN_REASON_NOT_AS_DESCRIBED": 3,
+		"RETURN_REASON_NO_LONGER_NEEDED": 4,
+		"RETURN_REASON_OTHER":            5,
+	}
+)
+
+func (x ReturnReason) Enum() *ReturnReason {
+	p := new(ReturnReason)
+	*p = x
+	return p
+}
+
+func (x ReturnReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReturnReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_order_proto_enumTypes[3].Descriptor()
+}
+
+func (ReturnReason) Type() protoreflect.EnumType {
+	return &file_order_proto_enumTypes[3]
+}
+
+func (x ReturnReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReturnReason.Descriptor instead.
+func (ReturnReason) EnumDescriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{3}
+}
+
+type Fulfillment struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OrderId        int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	State          FulfillmentState       `protobuf:"varint,2,opt,name=state,proto3,enum=order.FulfillmentState" json:"state,omitempty"`
+	Carrier        string                 `protobuf:"bytes,3,opt,name=carrier,proto3" json:"carrier,omitempty"`
+	TrackingNumber string                 `protobuf:"bytes,4,opt,name=tracking_number,json=trackingNumber,proto3" json:"tracking_number,omitempty"`
+	ShippedUnix    int64                  `protobuf:"varint,5,opt,name=shipped_unix,json=shippedUnix,proto3" json:"shipped_unix,omitempty"`
+	DeliveredUnix  int64                  `protobuf:"varint,6,opt,name=delivered_unix,json=deliveredUnix,proto3" json:"delivered_unix,omitempty"`
+	UpdatedUnix    int64                  `protobuf:"varint,7,opt,name=updated_unix,json=updatedUnix,proto3" json:"updated_unix,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Fulfillment) Reset() {
+	*x = Fulfillment{}
+	mi := &file_order_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fulfillment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fulfillment) ProtoMessage() {}
+
+func (x *Fulfillment) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fulfillment.ProtoReflect.Descriptor instead.
+func (*Fulfillment) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Fulfillment) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Fulfillment) GetState() FulfillmentState {
+	if x != nil {
+		return x.State
+	}
+	return FulfillmentState_FULFILLMENT_STATE_UNSPECIFIED
+}
+
+func (x *Fulfillment) GetCarrier() string {
+	if x != nil {
+		return x.Carrier
+	}
+	return ""
+}
+
+func (x *Fulfillment) GetTrackingNumber() string {
+	if x != nil {
+		return x.TrackingNumber
+	}
+	return ""
+}
+
+func (x *Fulfillment) GetShippedUnix() int64 {
+	if x != nil {
+		return x.ShippedUnix
+	}
+	return 0
+}
+
+func (x *Fulfillment) GetDeliveredUnix() int64 {
+	if x != nil {
+		return x.DeliveredUnix
+	}
+	return 0
+}
+
+func (x *Fulfillment) GetUpdatedUnix() int64 {
+	if x != nil {
+		return x.UpdatedUnix
+	}
+	return 0
+}
+
+type ReturnLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BookId        int64                  `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Qty           int32                  `protobuf:"varint,2,opt,name=qty,proto3" json:"qty,omitempty"`
+	Reason        ReturnReason           `protobuf:"varint,3,opt,name=reason,proto3,enum=order.ReturnReason" json:"reason,omitempty"`
+	Title         string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`   // lo completa el servidor
+	Refund        *common.Money          `protobuf:"bytes,5,opt,name=refund,proto3" json:"refund,omitempty"` // lo completa el servidor: base con descuento + IVA
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnLine) Reset() {
+	*x = ReturnLine{}
+	mi := &file_order_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnLine) ProtoMessage() {}
+
+func (x *ReturnLine) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnLine.ProtoReflect.Descriptor instead.
+func (*ReturnLine) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ReturnLine) GetBookId() int64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *ReturnLine) GetQty() int32 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *ReturnLine) GetReason() ReturnReason {
+	if x != nil {
+		return x.Reason
+	}
+	return ReturnReason_RETURN_REASON_UNSPECIFIED
+}
+
+func (x *ReturnLine) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ReturnLine) GetRefund() *common.Money {
+	if x != nil {
+		return x.Refund
+	}
+	return nil
+}
+
+type Return struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReturnId      int64                  `protobuf:"varint,1,opt,name=return_id,json=returnId,proto3" json:"return_id,omitempty"`
+	OrderId       int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	State         ReturnState            `protobuf:"varint,4,opt,name=state,proto3,enum=order.ReturnState" json:"state,omitempty"`
+	Lines         []*ReturnLine          `protobuf:"bytes,5,rep,name=lines,proto3" json:"lines,omitempty"`
+	Note          string                 `protobuf:"bytes,6,opt,name=note,proto3" json:"note,omitempty"`                                  // comentario del cliente
+	ReviewNote    string                 `protobuf:"bytes,7,opt,name=review_note,json=reviewNote,proto3" json:"review_note,omitempty"`    // motivo de aprobación/rechazo
+	RefundTotal   *common.Money          `protobuf:"bytes,8,opt,name=refund_total,json=refundTotal,proto3" json:"refund_total,omitempty"` // líneas + envío si la orden queda devuelta por completo
+	Restocked     bool                   `protobuf:"varint,9,opt,name=restocked,proto3" json:"restocked,omitempty"`                       // inventario ya reingresó las unidades
+	RefundRef     string                 `protobuf:"bytes,10,opt,name=refund_ref,json=refundRef,proto3" json:"refund_ref,omitempty"`      // referencia del reembolso en payment
+	CreatedUnix   int64                  `protobuf:"varint,11,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	UpdatedUnix   int64                  `protobuf:"varint,12,opt,name=updated_unix,json=updatedUnix,proto3" json:"updated_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Return) Reset() {
+	*x = Return{}
+	mi := &file_order_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Return) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Return) ProtoMessage() {}
+
+func (x *Return) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Return.ProtoReflect.Descriptor instead.
+func (*Return) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Return) GetReturnId() int64 {
+	if x != nil {
+		return x.ReturnId
+	}
+	return 0
+}
+
+func (x *Return) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Return) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Return) GetState() ReturnState {
+	if x != nil {
+		return x.State
+	}
+	return ReturnState_RETURN_STATE_UNSPECIFIED
+}
+
+func (x *Return) GetLines() []*ReturnLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *Return) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *Return) GetReviewNote() string {
+	if x != nil {
+		return x.ReviewNote
+	}
+	return ""
+}
+
+func (x *Return) GetRefundTotal() *common.Money {
+	if x != nil {
+		return x.RefundTotal
+	}
+	return nil
+}
+
+func (x *Return) GetRestocked() bool {
+	if x != nil {
+		return x.Restocked
+	}
+	return false
+}
+
+func (x *Return) GetRefundRef() string {
+	if x != nil {
+		return x.RefundRef
+	}
+	return ""
+}
+
+func (x *Return) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *Return) GetUpdatedUnix() int64 {
+	if x != nil {
+		return x.UpdatedUnix
+	}
+	return 0
+}
+
 type OrderItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	BookId        int64                  `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Qty           int32                  `protobuf:"varint,3,opt,name=qty,proto3" json:"qty,omitempty"`
-	UnitPrice     *common.Money          `protobuf:"bytes,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
-	LineTotal     *common.Money          `protobuf:"bytes,5,opt,name=line_total,json=lineTotal,proto3" json:"line_total,omitempty"`
+	BookId        int64                  `protobuf:"varint,1,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Qty           int32                  `protobuf:"varint,3,opt,name=qty,proto3" json:"qty,omitempty"`
+	UnitPrice     *common.Money          `protobuf:"bytes,4,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	LineTotal     *common.Money          `protobuf:"bytes,5,opt,name=line_total,json=lineTotal,proto3" json:"line_total,omitempty"`
+	Tax           *common.Money          `protobuf:"bytes,6,opt,name=tax,proto3" json:"tax,omitempty"` // IVA de la línea
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderItem) Reset() {
+	*x = OrderItem{}
+	mi := &file_order_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderItem) ProtoMessage() {}
+
+func (x *OrderItem) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderItem.ProtoReflect.Descriptor instead.
+func (*OrderItem) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OrderItem) GetBookId() int64 {
+	if x != nil {
+		return x.BookId
+	}
+	return 0
+}
+
+func (x *OrderItem) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *OrderItem) GetQty() int32 {
+	if x != nil {
+		return x.Qty
+	}
+	return 0
+}
+
+func (x *OrderItem) GetUnitPrice() *common.Money {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return nil
+}
+
+func (x *OrderItem) GetLineTotal() *common.Money {
+	if x != nil {
+		return x.LineTotal
+	}
+	return nil
+}
+
+func (x *OrderItem) GetTax() *common.Money {
+	if x != nil {
+		return x.Tax
+	}
+	return nil
+}
+
+type CreateOrderRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Clave generada por el cliente; reintentos con la misma clave devuelven la orden original.
+	IdempotencyKey string `protobuf:"bytes,2,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Dirección de la libreta del usuario; se copia a la orden (cambios posteriores no la afectan).
+	AddressId int64 `protobuf:"varint,3,opt,name=address_id,json=addressId,proto3" json:"address_id,omitempty"`
+	// Vacío = el medio por defecto de Payment.
+	PaymentMethod common.PaymentMethod `protobuf:"varint,4,opt,name=payment_method,json=paymentMethod,proto3,enum=common.PaymentMethod" json:"payment_method,omitempty"`
+	// Tarjeta guardada del usuario; obligatoria con PAYMENT_METHOD_CARD.
+	InstrumentId int64 `protobuf:"varint,5,opt,name=instrument_id,json=instrumentId,proto3" json:"instrument_id,omitempty"`
+	// Parte a pagar con el saldo a favor; el resto va por payment_method. Si
+	// cubre el total (se recorta a él) la orden se paga con PAYMENT_METHOD_STORE_CREDIT.
+	WalletAmount  *common.Money `protobuf:"bytes,6,opt,name=wallet_amount,json=walletAmount,proto3" json:"wallet_amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrderRequest) Reset() {
+	*x = CreateOrderRequest{}
+	mi := &file_order_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderRequest) ProtoMessage() {}
+
+func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateOrderRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetAddressId() int64 {
+	if x != nil {
+		return x.AddressId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetPaymentMethod() common.PaymentMethod {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return common.PaymentMethod(0)
+}
+
+func (x *CreateOrderRequest) GetInstrumentId() int64 {
+	if x != nil {
+		return x.InstrumentId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetWalletAmount() *common.Money {
+	if x != nil {
+		return x.WalletAmount
+	}
+	return nil
+}
+
+type CreateOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status        OrderStatus            `protobuf:"varint,2,opt,name=status,proto3,enum=order.OrderStatus" json:"status,omitempty"` // normalmente CREATED
+	Items         []*OrderItem           `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
+	Total         *common.Money          `protobuf:"bytes,4,opt,name=total,proto3" json:"total,omitempty"` // total a pagar (= breakdown.grand_total)
+	Breakdown     *common.PriceBreakdown `protobuf:"bytes,5,opt,name=breakdown,proto3" json:"breakdown,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrderResponse) Reset() {
+	*x = CreateOrderResponse{}
+	mi := &file_order_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderResponse) ProtoMessage() {}
+
+func (x *CreateOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderResponse.ProtoReflect.Descriptor instead.
+func (*CreateOrderResponse) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateOrderResponse) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *CreateOrderResponse) GetStatus() OrderStatus {
+	if x != nil {
+		return x.Status
+	}
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+}
+
+func (x *CreateOrderResponse) GetItems() []*OrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CreateOrderResponse) GetTotal() *common.Money {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+func (x *CreateOrderResponse) GetBreakdown() *common.PriceBreakdown {
+	if x != nil {
+		return x.Breakdown
+	}
+	return nil
+}
+
+type GetOrderStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderStatusRequest) Reset() {
+	*x = GetOrderStatusRequest{}
+	mi := &file_order_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderStatusRequest) ProtoMessage() {}
+
+func (x *GetOrderStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderStatusRequest) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetOrderStatusRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type GetOrderStatusResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	OrderId         int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status          OrderStatus            `protobuf:"varint,2,opt,name=status,proto3,enum=order.OrderStatus" json:"status,omitempty"`
+	Total           *common.Money          `protobuf:"bytes,3,opt,name=total,proto3" json:"total,omitempty"`
+	UpdatedUnix     int64                  `protobuf:"varint,4,opt,name=updated_unix,json=updatedUnix,proto3" json:"updated_unix,omitempty"`
+	ShippingAddress *common.Address        `protobuf:"bytes,5,opt,name=shipping_address,json=shippingAddress,proto3" json:"shipping_address,omitempty"`
+	Fulfillment     *Fulfillment           `protobuf:"bytes,6,opt,name=fulfillment,proto3" json:"fulfillment,omitempty"` // vacío hasta que la orden se paga
+	Breakdown       *common.PriceBreakdown `protobuf:"bytes,7,opt,name=breakdown,proto3" json:"breakdown,omitempty"`
+	Items           []*OrderItem           `protobuf:"bytes,8,rep,name=items,proto3" json:"items,omitempty"`
+	PaymentMethod   common.PaymentMethod   `protobuf:"varint,9,opt,name=payment_method,json=paymentMethod,proto3,enum=common.PaymentMethod" json:"payment_method,omitempty"`
+	StatusReason    string                 `protobuf:"bytes,10,opt,name=status_reason,json=statusReason,proto3" json:"status_reason,omitempty"` // por qué se canceló la orden (p.ej. payment_timeout)
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetOrderStatusResponse) Reset() {
+	*x = GetOrderStatusResponse{}
+	mi := &file_order_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderStatusResponse) ProtoMessage() {}
+
+func (x *GetOrderStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderStatusResponse) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetOrderStatusResponse) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *GetOrderStatusResponse) GetStatus() OrderStatus {
+	if x != nil {
+		return x.Status
+	}
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+}
+
+func (x *GetOrderStatusResponse) GetTotal() *common.Money {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+func (x *GetOrderStatusResponse) GetUpdatedUnix() int64 {
+	if x != nil {
+		return x.UpdatedUnix
+	}
+	return 0
+}
+
+func (x *GetOrderStatusResponse) GetShippingAddress() *common.Address {
+	if x != nil {
+		return x.ShippingAddress
+	}
+	return nil
+}
+
+func (x *GetOrderStatusResponse) GetFulfillment() *Fulfillment {
+	if x != nil {
+		return x.Fulfillment
+	}
+	return nil
+}
+
+func (x *GetOrderStatusResponse) GetBreakdown() *common.PriceBreakdown {
+	if x != nil {
+		return x.Breakdown
+	}
+	return nil
+}
+
+func (x *GetOrderStatusResponse) GetItems() []*OrderItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *GetOrderStatusResponse) GetPaymentMethod() common.PaymentMethod {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return common.PaymentMethod(0)
+}
+
+func (x *GetOrderStatusResponse) GetStatusReason() string {
+	if x != nil {
+		return x.StatusReason
+	}
+	return ""
+}
+
+type WatchOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	AfterEventId  int64                  `protobuf:"varint,2,opt,name=after_event_id,json=afterEventId,proto3" json:"after_event_id,omitempty"` // último event_id recibido; 0 = empezar por el estado actual
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *OrderItem) Reset() {
-	*x = OrderItem{}
-	mi := &file_order_proto_msgTypes[0]
+func (x *WatchOrderRequest) Reset() {
+	*x = WatchOrderRequest{}
+	mi := &file_order_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *OrderItem) String() string {
+func (x *WatchOrderRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*OrderItem) ProtoMessage() {}
+func (*WatchOrderRequest) ProtoMessage() {}
 
-func (x *OrderItem) ProtoReflect() protoreflect.Message {
-	mi := &file_order_proto_msgTypes[0]
+func (x *WatchOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -113,68 +1003,143 @@ func (x *OrderItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use OrderItem.ProtoReflect.Descriptor instead.
-func (*OrderItem) Descriptor() ([]byte, []int) {
-	return file_order_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use WatchOrderRequest.ProtoReflect.Descriptor instead.
+func (*WatchOrderRequest) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *OrderItem) GetBookId() int64 {
+func (x *WatchOrderRequest) GetOrderId() int64 {
 	if x != nil {
-		return x.BookId
+		return x.OrderId
 	}
 	return 0
 }
 
-func (x *OrderItem) GetTitle() string {
+func (x *WatchOrderRequest) GetAfterEventId() int64 {
 	if x != nil {
-		return x.Title
+		return x.AfterEventId
 	}
-	return ""
+	return 0
 }
 
-func (x *OrderItem) GetQty() int32 {
+// Estado de la orden tras un cambio.
+type OrderEvent struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	EventId          int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"` // creciente; 0 si la orden es anterior al historial
+	OrderId          int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Status           OrderStatus            `protobuf:"varint,3,opt,name=status,proto3,enum=order.OrderStatus" json:"status,omitempty"`
+	StatusReason     string                 `protobuf:"bytes,4,opt,name=status_reason,json=statusReason,proto3" json:"status_reason,omitempty"`
+	FulfillmentState FulfillmentState       `protobuf:"varint,5,opt,name=fulfillment_state,json=fulfillmentState,proto3,enum=order.FulfillmentState" json:"fulfillment_state,omitempty"`
+	CreatedUnix      int64                  `protobuf:"varint,6,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	Final            bool                   `protobuf:"varint,7,opt,name=final,proto3" json:"final,omitempty"` // FAILED, CANCELLED o PAID y entregada: no habrá más eventos
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *OrderEvent) Reset() {
+	*x = OrderEvent{}
+	mi := &file_order_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+func (x *OrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[9]
 	if x != nil {
-		return x.Qty
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEvent.ProtoReflect.Descriptor instead.
+func (*OrderEvent) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OrderEvent) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
 	}
 	return 0
 }
 
-func (x *OrderItem) GetUnitPrice() *common.Money {
+func (x *OrderEvent) GetOrderId() int64 {
 	if x != nil {
-		return x.UnitPrice
+		return x.OrderId
 	}
-	return nil
+	return 0
 }
 
-func (x *OrderItem) GetLineTotal() *common.Money {
+func (x *OrderEvent) GetStatus() OrderStatus {
 	if x != nil {
-		return x.LineTotal
+		return x.Status
 	}
-	return nil
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
 }
 
-type CreateOrderRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *OrderEvent) GetStatusReason() string {
+	if x != nil {
+		return x.StatusReason
+	}
+	return ""
 }
 
-func (x *CreateOrderRequest) Reset() {
-	*x = CreateOrderRequest{}
-	mi := &file_order_proto_msgTypes[1]
+func (x *OrderEvent) GetFulfillmentState() FulfillmentState {
+	if x != nil {
+		return x.FulfillmentState
+	}
+	return FulfillmentState_FULFILLMENT_STATE_UNSPECIFIED
+}
+
+func (x *OrderEvent) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *OrderEvent) GetFinal() bool {
+	if x != nil {
+		return x.Final
+	}
+	return false
+}
+
+type UpdateFulfillmentRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OrderId        int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	State          FulfillmentState       `protobuf:"varint,2,opt,name=state,proto3,enum=order.FulfillmentState" json:"state,omitempty"`
+	Carrier        string                 `protobuf:"bytes,3,opt,name=carrier,proto3" json:"carrier,omitempty"`
+	TrackingNumber string                 `protobuf:"bytes,4,opt,name=tracking_number,json=trackingNumber,proto3" json:"tracking_number,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateFulfillmentRequest) Reset() {
+	*x = UpdateFulfillmentRequest{}
+	mi := &file_order_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateOrderRequest) String() string {
+func (x *UpdateFulfillmentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateOrderRequest) ProtoMessage() {}
+func (*UpdateFulfillmentRequest) ProtoMessage() {}
 
-func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_proto_msgTypes[1]
+func (x *UpdateFulfillmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -185,43 +1150,64 @@ func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
-func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
-	return file_order_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use UpdateFulfillmentRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFulfillmentRequest) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *CreateOrderRequest) GetUserId() int64 {
+func (x *UpdateFulfillmentRequest) GetOrderId() int64 {
 	if x != nil {
-		return x.UserId
+		return x.OrderId
 	}
 	return 0
 }
 
-type CreateOrderResponse struct {
+func (x *UpdateFulfillmentRequest) GetState() FulfillmentState {
+	if x != nil {
+		return x.State
+	}
+	return FulfillmentState_FULFILLMENT_STATE_UNSPECIFIED
+}
+
+func (x *UpdateFulfillmentRequest) GetCarrier() string {
+	if x != nil {
+		return x.Carrier
+	}
+	return ""
+}
+
+func (x *UpdateFulfillmentRequest) GetTrackingNumber() string {
+	if x != nil {
+		return x.TrackingNumber
+	}
+	return ""
+}
+
+type RequestReturnRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Status        OrderStatus            `protobuf:"varint,2,opt,name=status,proto3,enum=order.OrderStatus" json:"status,omitempty"` // normalmente CREATED
-	Items         []*OrderItem           `protobuf:"bytes,3,rep,name=items,proto3" json:"items,omitempty"`
-	Total         *common.Money          `protobuf:"bytes,4,opt,name=total,proto3" json:"total,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // debe ser el dueño de la orden
+	Lines         []*ReturnLine          `protobuf:"bytes,3,rep,name=lines,proto3" json:"lines,omitempty"`                  // book_id, qty y reason por línea
+	Note          string                 `protobuf:"bytes,4,opt,name=note,proto3" json:"note,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateOrderResponse) Reset() {
-	*x = CreateOrderResponse{}
-	mi := &file_order_proto_msgTypes[2]
+func (x *RequestReturnRequest) Reset() {
+	*x = RequestReturnRequest{}
+	mi := &file_order_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateOrderResponse) String() string {
+func (x *RequestReturnRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateOrderResponse) ProtoMessage() {}
+func (*RequestReturnRequest) ProtoMessage() {}
 
-func (x *CreateOrderResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_proto_msgTypes[2]
+func (x *RequestReturnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -232,61 +1218,63 @@ func (x *CreateOrderResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateOrderResponse.ProtoReflect.Descriptor instead.
-func (*CreateOrderResponse) Descriptor() ([]byte, []int) {
-	return file_order_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use RequestReturnRequest.ProtoReflect.Descriptor instead.
+func (*RequestReturnRequest) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *CreateOrderResponse) GetOrderId() int64 {
+func (x *RequestReturnRequest) GetOrderId() int64 {
 	if x != nil {
 		return x.OrderId
 	}
 	return 0
 }
 
-func (x *CreateOrderResponse) GetStatus() OrderStatus {
+func (x *RequestReturnRequest) GetUserId() int64 {
 	if x != nil {
-		return x.Status
+		return x.UserId
 	}
-	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+	return 0
 }
 
-func (x *CreateOrderResponse) GetItems() []*OrderItem {
+func (x *RequestReturnRequest) GetLines() []*ReturnLine {
 	if x != nil {
-		return x.Items
+		return x.Lines
 	}
 	return nil
 }
 
-func (x *CreateOrderResponse) GetTotal() *common.Money {
+func (x *RequestReturnRequest) GetNote() string {
 	if x != nil {
-		return x.Total
+		return x.Note
 	}
-	return nil
+	return ""
 }
 
-type GetOrderStatusRequest struct {
+type ReviewReturnRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	ReturnId      int64                  `protobuf:"varint,1,opt,name=return_id,json=returnId,proto3" json:"return_id,omitempty"`
+	Approve       bool                   `protobuf:"varint,2,opt,name=approve,proto3" json:"approve,omitempty"`
+	Note          string                 `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetOrderStatusRequest) Reset() {
-	*x = GetOrderStatusRequest{}
-	mi := &file_order_proto_msgTypes[3]
+func (x *ReviewReturnRequest) Reset() {
+	*x = ReviewReturnRequest{}
+	mi := &file_order_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetOrderStatusRequest) String() string {
+func (x *ReviewReturnRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetOrderStatusRequest) ProtoMessage() {}
+func (*ReviewReturnRequest) ProtoMessage() {}
 
-func (x *GetOrderStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_order_proto_msgTypes[3]
+func (x *ReviewReturnRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -297,43 +1285,54 @@ func (x *GetOrderStatusRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetOrderStatusRequest.ProtoReflect.Descriptor instead.
-func (*GetOrderStatusRequest) Descriptor() ([]byte, []int) {
-	return file_order_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ReviewReturnRequest.ProtoReflect.Descriptor instead.
+func (*ReviewReturnRequest) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *GetOrderStatusRequest) GetOrderId() int64 {
+func (x *ReviewReturnRequest) GetReturnId() int64 {
 	if x != nil {
-		return x.OrderId
+		return x.ReturnId
 	}
 	return 0
 }
 
-type GetOrderStatusResponse struct {
+func (x *ReviewReturnRequest) GetApprove() bool {
+	if x != nil {
+		return x.Approve
+	}
+	return false
+}
+
+func (x *ReviewReturnRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type ReturnRef struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Status        OrderStatus            `protobuf:"varint,2,opt,name=status,proto3,enum=order.OrderStatus" json:"status,omitempty"`
-	Total         *common.Money          `protobuf:"bytes,3,opt,name=total,proto3" json:"total,omitempty"`
-	UpdatedUnix   int64                  `protobuf:"varint,4,opt,name=updated_unix,json=updatedUnix,proto3" json:"updated_unix,omitempty"`
+	ReturnId      int64                  `protobuf:"varint,1,opt,name=return_id,json=returnId,proto3" json:"return_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetOrderStatusResponse) Reset() {
-	*x = GetOrderStatusResponse{}
-	mi := &file_order_proto_msgTypes[4]
+func (x *ReturnRef) Reset() {
+	*x = ReturnRef{}
+	mi := &file_order_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetOrderStatusResponse) String() string {
+func (x *ReturnRef) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetOrderStatusResponse) ProtoMessage() {}
+func (*ReturnRef) ProtoMessage() {}
 
-func (x *GetOrderStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_order_proto_msgTypes[4]
+func (x *ReturnRef) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -344,44 +1343,98 @@ func (x *GetOrderStatusResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetOrderStatusResponse.ProtoReflect.Descriptor instead.
-func (*GetOrderStatusResponse) Descriptor() ([]byte, []int) {
-	return file_order_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use ReturnRef.ProtoReflect.Descriptor instead.
+func (*ReturnRef) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *GetOrderStatusResponse) GetOrderId() int64 {
+func (x *ReturnRef) GetReturnId() int64 {
 	if x != nil {
-		return x.OrderId
+		return x.ReturnId
 	}
 	return 0
 }
 
-func (x *GetOrderStatusResponse) GetStatus() OrderStatus {
-	if x != nil {
-		return x.Status
-	}
-	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+type ListReturnsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Returns       []*Return              `protobuf:"bytes,1,rep,name=returns,proto3" json:"returns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetOrderStatusResponse) GetTotal() *common.Money {
+func (x *ListReturnsResponse) Reset() {
+	*x = ListReturnsResponse{}
+	mi := &file_order_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListReturnsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListReturnsResponse) ProtoMessage() {}
+
+func (x *ListReturnsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_order_proto_msgTypes[14]
 	if x != nil {
-		return x.Total
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *GetOrderStatusResponse) GetUpdatedUnix() int64 {
+// Deprecated: Use ListReturnsResponse.ProtoReflect.Descriptor instead.
+func (*ListReturnsResponse) Descriptor() ([]byte, []int) {
+	return file_order_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListReturnsResponse) GetReturns() []*Return {
 	if x != nil {
-		return x.UpdatedUnix
+		return x.Returns
 	}
-	return 0
+	return nil
 }
 
 var File_order_proto protoreflect.FileDescriptor
 
 const file_order_proto_rawDesc = "" +
 	"\n" +
-	"\vorder.proto\x12\x05order\x1a\fcommon.proto\"\xa8\x01\n" +
+	"\vorder.proto\x12\x05order\x1a\fcommon.proto\x1a\x1cgoogle/api/annotations.proto\"\x87\x02\n" +
+	"\vFulfillment\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12-\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x17.order.FulfillmentStateR\x05state\x12\x18\n" +
+	"\acarrier\x18\x03 \x01(\tR\acarrier\x12'\n" +
+	"\x0ftracking_number\x18\x04 \x01(\tR\x0etrackingNumber\x12!\n" +
+	"\fshipped_unix\x18\x05 \x01(\x03R\vshippedUnix\x12%\n" +
+	"\x0edelivered_unix\x18\x06 \x01(\x03R\rdeliveredUnix\x12!\n" +
+	"\fupdated_unix\x18\a \x01(\x03R\vupdatedUnix\"\xa1\x01\n" +
+	"\n" +
+	"ReturnLine\x12\x17\n" +
+	"\abook_id\x18\x01 \x01(\x03R\x06bookId\x12\x10\n" +
+	"\x03qty\x18\x02 \x01(\x05R\x03qty\x12+\n" +
+	"\x06reason\x18\x03 \x01(\x0e2\x13.order.ReturnReasonR\x06reason\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12%\n" +
+	"\x06refund\x18\x05 \x01(\v2\r.common.MoneyR\x06refund\"\x96\x03\n" +
+	"\x06Return\x12\x1b\n" +
+	"\treturn_id\x18\x01 \x01(\x03R\breturnId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12(\n" +
+	"\x05state\x18\x04 \x01(\x0e2\x12.order.ReturnStateR\x05state\x12'\n" +
+	"\x05lines\x18\x05 \x03(\v2\x11.order.ReturnLineR\x05lines\x12\x12\n" +
+	"\x04note\x18\x06 \x01(\tR\x04note\x12\x1f\n" +
+	"\vreview_note\x18\a \x01(\tR\n" +
+	"reviewNote\x120\n" +
+	"\frefund_total\x18\b \x01(\v2\r.common.MoneyR\vrefundTotal\x12\x1c\n" +
+	"\trestocked\x18\t \x01(\bR\trestocked\x12\x1d\n" +
+	"\n" +
+	"refund_ref\x18\n" +
+	" \x01(\tR\trefundRef\x12!\n" +
+	"\fcreated_unix\x18\v \x01(\x03R\vcreatedUnix\x12!\n" +
+	"\fupdated_unix\x18\f \x01(\x03R\vupdatedUnix\"\xc9\x01\n" +
 	"\tOrderItem\x12\x17\n" +
 	"\abook_id\x18\x01 \x01(\x03R\x06bookId\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x10\n" +
@@ -389,30 +1442,107 @@ const file_order_proto_rawDesc = "" +
 	"\n" +
 	"unit_price\x18\x04 \x01(\v2\r.common.MoneyR\tunitPrice\x12,\n" +
 	"\n" +
-	"line_total\x18\x05 \x01(\v2\r.common.MoneyR\tlineTotal\"-\n" +
+	"line_total\x18\x05 \x01(\v2\r.common.MoneyR\tlineTotal\x12\x1f\n" +
+	"\x03tax\x18\x06 \x01(\v2\r.common.MoneyR\x03tax\"\x8c\x02\n" +
 	"\x12CreateOrderRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\"\xa9\x01\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12'\n" +
+	"\x0fidempotency_key\x18\x02 \x01(\tR\x0eidempotencyKey\x12\x1d\n" +
+	"\n" +
+	"address_id\x18\x03 \x01(\x03R\taddressId\x12<\n" +
+	"\x0epayment_method\x18\x04 \x01(\x0e2\x15.common.PaymentMethodR\rpaymentMethod\x12#\n" +
+	"\rinstrument_id\x18\x05 \x01(\x03R\finstrumentId\x122\n" +
+	"\rwallet_amount\x18\x06 \x01(\v2\r.common.MoneyR\fwalletAmount\"\xdf\x01\n" +
 	"\x13CreateOrderResponse\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\x03R\aorderId\x12*\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x12.order.OrderStatusR\x06status\x12&\n" +
 	"\x05items\x18\x03 \x03(\v2\x10.order.OrderItemR\x05items\x12#\n" +
-	"\x05total\x18\x04 \x01(\v2\r.common.MoneyR\x05total\"2\n" +
+	"\x05total\x18\x04 \x01(\v2\r.common.MoneyR\x05total\x124\n" +
+	"\tbreakdown\x18\x05 \x01(\v2\x16.common.PriceBreakdownR\tbreakdown\"2\n" +
 	"\x15GetOrderStatusRequest\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\x03R\aorderId\"\xa7\x01\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"\xda\x03\n" +
 	"\x16GetOrderStatusResponse\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\x03R\aorderId\x12*\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x12.order.OrderStatusR\x06status\x12#\n" +
 	"\x05total\x18\x03 \x01(\v2\r.common.MoneyR\x05total\x12!\n" +
-	"\fupdated_unix\x18\x04 \x01(\x03R\vupdatedUnix*\x91\x01\n" +
+	"\fupdated_unix\x18\x04 \x01(\x03R\vupdatedUnix\x12:\n" +
+	"\x10shipping_address\x18\x05 \x01(\v2\x0f.common.AddressR\x0fshippingAddress\x124\n" +
+	"\vfulfillment\x18\x06 \x01(\v2\x12.order.FulfillmentR\vfulfillment\x124\n" +
+	"\tbreakdown\x18\a \x01(\v2\x16.common.PriceBreakdownR\tbreakdown\x12&\n" +
+	"\x05items\x18\b \x03(\v2\x10.order.OrderItemR\x05items\x12<\n" +
+	"\x0epayment_method\x18\t \x01(\x0e2\x15.common.PaymentMethodR\rpaymentMethod\x12#\n" +
+	"\rstatus_reason\x18\n" +
+	" \x01(\tR\fstatusReason\"T\n" +
+	"\x11WatchOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12$\n" +
+	"\x0eafter_event_id\x18\x02 \x01(\x03R\fafterEventId\"\x92\x02\n" +
+	"\n" +
+	"OrderEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12*\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x12.order.OrderStatusR\x06status\x12#\n" +
+	"\rstatus_reason\x18\x04 \x01(\tR\fstatusReason\x12D\n" +
+	"\x11fulfillment_state\x18\x05 \x01(\x0e2\x17.order.FulfillmentStateR\x10fulfillmentState\x12!\n" +
+	"\fcreated_unix\x18\x06 \x01(\x03R\vcreatedUnix\x12\x14\n" +
+	"\x05final\x18\a \x01(\bR\x05final\"\xa7\x01\n" +
+	"\x18UpdateFulfillmentRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12-\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x17.order.FulfillmentStateR\x05state\x12\x18\n" +
+	"\acarrier\x18\x03 \x01(\tR\acarrier\x12'\n" +
+	"\x0ftracking_number\x18\x04 \x01(\tR\x0etrackingNumber\"\x87\x01\n" +
+	"\x14RequestReturnRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12'\n" +
+	"\x05lines\x18\x03 \x03(\v2\x11.order.ReturnLineR\x05lines\x12\x12\n" +
+	"\x04note\x18\x04 \x01(\tR\x04note\"`\n" +
+	"\x13ReviewReturnRequest\x12\x1b\n" +
+	"\treturn_id\x18\x01 \x01(\x03R\breturnId\x12\x18\n" +
+	"\aapprove\x18\x02 \x01(\bR\aapprove\x12\x12\n" +
+	"\x04note\x18\x03 \x01(\tR\x04note\"(\n" +
+	"\tReturnRef\x12\x1b\n" +
+	"\treturn_id\x18\x01 \x01(\x03R\breturnId\">\n" +
+	"\x13ListReturnsResponse\x12'\n" +
+	"\areturns\x18\x01 \x03(\v2\r.order.ReturnR\areturns*\xad\x01\n" +
 	"\vOrderStatus\x12\x1c\n" +
 	"\x18ORDER_STATUS_UNSPECIFIED\x10\x00\x12\x18\n" +
 	"\x14ORDER_STATUS_CREATED\x10\x01\x12\x15\n" +
 	"\x11ORDER_STATUS_PAID\x10\x02\x12\x1a\n" +
 	"\x16ORDER_STATUS_CANCELLED\x10\x03\x12\x17\n" +
-	"\x13ORDER_STATUS_FAILED\x10\x042\x9c\x01\n" +
-	"\x05Order\x12D\n" +
-	"\vCreateOrder\x12\x19.order.CreateOrderRequest\x1a\x1a.order.CreateOrderResponse\x12M\n" +
-	"\x0eGetOrderStatus\x12\x1c.order.GetOrderStatusRequest\x1a\x1d.order.GetOrderStatusResponseB;Z9github.com/ahinestrog/mybookstore/proto/gen/order;orderpbb\x06proto3"
+	"\x13ORDER_STATUS_FAILED\x10\x04\x12\x1a\n" +
+	"\x16ORDER_STATUS_CONFIRMED\x10\x05*\xd1\x01\n" +
+	"\x10FulfillmentState\x12!\n" +
+	"\x1dFULFILLMENT_STATE_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19FULFILLMENT_STATE_PENDING\x10\x01\x12\x1d\n" +
+	"\x19FULFILLMENT_STATE_PICKING\x10\x02\x12\x1c\n" +
+	"\x18FULFILLMENT_STATE_PACKED\x10\x03\x12\x1d\n" +
+	"\x19FULFILLMENT_STATE_SHIPPED\x10\x04\x12\x1f\n" +
+	"\x1bFULFILLMENT_STATE_DELIVERED\x10\x05*\xd3\x01\n" +
+	"\vReturnState\x12\x1c\n" +
+	"\x18RETURN_STATE_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16RETURN_STATE_REQUESTED\x10\x01\x12\x19\n" +
+	"\x15RETURN_STATE_APPROVED\x10\x02\x12\x19\n" +
+	"\x15RETURN_STATE_REJECTED\x10\x03\x12\x19\n" +
+	"\x15RETURN_STATE_RECEIVED\x10\x04\x12\x19\n" +
+	"\x15RETURN_STATE_REFUNDED\x10\x05\x12\x1e\n" +
+	"\x1aRETURN_STATE_REFUND_FAILED\x10\x06*\xc7\x01\n" +
+	"\fReturnReason\x12\x1d\n" +
+	"\x19RETURN_REASON_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15RETURN_REASON_DAMAGED\x10\x01\x12\x1c\n" +
+	"\x18RETURN_REASON_WRONG_ITEM\x10\x02\x12\"\n" +
+	"\x1eRETURN_REASON_NOT_AS_DESCRIBED\x10\x03\x12\"\n" +
+	"\x1eRETURN_REASON_NO_LONGER_NEEDED\x10\x04\x12\x17\n" +
+	"\x13RETURN_REASON_OTHER\x10\x052\x8a\x04\n" +
+	"\x05Order\x12o\n" +
+	"\vCreateOrder\x12\x19.order.CreateOrderRequest\x1a\x1a.order.CreateOrderResponse\")\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/api/v1/users/{user_id}/orders\x12p\n" +
+	"\x0eGetOrderStatus\x12\x1c.order.GetOrderStatusRequest\x1a\x1d.order.GetOrderStatusResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/api/v1/orders/{order_id}\x12y\n" +
+	"\rRequestReturn\x12\x1b.order.RequestReturnRequest\x1a\r.order.Return\"<\x82\xd3\xe4\x93\x026:\x01*\"1/api/v1/users/{user_id}/orders/{order_id}/returns\x12f\n" +
+	"\vListReturns\x12\x10.common.OrderRef\x1a\x1a.order.ListReturnsResponse\")\x82\xd3\xe4\x93\x02#\x12!/api/v1/orders/{order_id}/returns\x12;\n" +
+	"\n" +
+	"WatchOrder\x12\x18.order.WatchOrderRequest\x1a\x11.order.OrderEvent0\x012\xc3\x01\n" +
+	"\n" +
+	"OrderAdmin\x12H\n" +
+	"\x11UpdateFulfillment\x12\x1f.order.UpdateFulfillmentRequest\x1a\x12.order.Fulfillment\x129\n" +
+	"\fReviewReturn\x12\x1a.order.ReviewReturnRequest\x1a\r.order.Return\x120\n" +
+	"\rReceiveReturn\x12\x10.order.ReturnRef\x1a\r.order.ReturnB;Z9github.com/ahinestrog/mybookstore/proto/gen/order;orderpbb\x06proto3"
 
 var (
 	file_order_proto_rawDescOnce sync.Once
@@ -426,34 +1556,83 @@ func file_order_proto_rawDescGZIP() []byte {
 	return file_order_proto_rawDescData
 }
 
-var file_order_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_order_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_order_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_order_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_order_proto_goTypes = []any{
-	(OrderStatus)(0),               // 0: order.OrderStatus
-	(*OrderItem)(nil),              // 1: order.OrderItem
-	(*CreateOrderRequest)(nil),     // 2: order.CreateOrderRequest
-	(*CreateOrderResponse)(nil),    // 3: order.CreateOrderResponse
-	(*GetOrderStatusRequest)(nil),  // 4: order.GetOrderStatusRequest
-	(*GetOrderStatusResponse)(nil), // 5: order.GetOrderStatusResponse
-	(*common.Money)(nil),           // 6: common.Money
+	(OrderStatus)(0),                 // 0: order.OrderStatus
+	(FulfillmentState)(0),            // 1: order.FulfillmentState
+	(ReturnState)(0),                 // 2: order.ReturnState
+	(ReturnReason)(0),                // 3: order.ReturnReason
+	(*Fulfillment)(nil),              // 4: order.Fulfillment
+	(*ReturnLine)(nil),               // 5: order.ReturnLine
+	(*Return)(nil),                   // 6: order.Return
+	(*OrderItem)(nil),                // 7: order.OrderItem
+	(*CreateOrderRequest)(nil),       // 8: order.CreateOrderRequest
+	(*CreateOrderResponse)(nil),      // 9: order.CreateOrderResponse
+	(*GetOrderStatusRequest)(nil),    // 10: order.GetOrderStatusRequest
+	(*GetOrderStatusResponse)(nil),   // 11: order.GetOrderStatusResponse
+	(*WatchOrderRequest)(nil),        // 12: order.WatchOrderRequest
+	(*OrderEvent)(nil),               // 13: order.OrderEvent
+	(*UpdateFulfillmentRequest)(nil), // 14: order.UpdateFulfillmentRequest
+	(*RequestReturnRequest)(nil),     // 15: order.RequestReturnRequest
+	(*ReviewReturnRequest)(nil),      // 16: order.ReviewReturnRequest
+	(*ReturnRef)(nil),                // 17: order.ReturnRef
+	(*ListReturnsResponse)(nil),      // 18: order.ListReturnsResponse
+	(*common.Money)(nil),             // 19: common.Money
+	(common.PaymentMethod)(0),        // 20: common.PaymentMethod
+	(*common.PriceBreakdown)(nil),    // 21: common.PriceBreakdown
+	(*common.Address)(nil),           // 22: common.Address
+	(*common.OrderRef)(nil),          // 23: common.OrderRef
 }
 var file_order_proto_depIdxs = []int32{
-	6, // 0: order.OrderItem.unit_price:type_name -> common.Money
-	6, // 1: order.OrderItem.line_total:type_name -> common.Money
-	0, // 2: order.CreateOrderResponse.status:type_name -> order.OrderStatus
-	1, // 3: order.CreateOrderResponse.items:type_name -> order.OrderItem
-	6, // 4: order.CreateOrderResponse.total:type_name -> common.Money
-	0, // 5: order.GetOrderStatusResponse.status:type_name -> order.OrderStatus
-	6, // 6: order.GetOrderStatusResponse.total:type_name -> common.Money
-	2, // 7: order.Order.CreateOrder:input_type -> order.CreateOrderRequest
-	4, // 8: order.Order.GetOrderStatus:input_type -> order.GetOrderStatusRequest
-	3, // 9: order.Order.CreateOrder:output_type -> order.CreateOrderResponse
-	5, // 10: order.Order.GetOrderStatus:output_type -> order.GetOrderStatusResponse
-	9, // [9:11] is the sub-list for method output_type
-	7, // [7:9] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	1,  // 0: order.Fulfillment.state:type_name -> order.FulfillmentState
+	3,  // 1: order.ReturnLine.reason:type_name -> order.ReturnReason
+	19, // 2: order.ReturnLine.refund:type_name -> common.Money
+	2,  // 3: order.Return.state:type_name -> order.ReturnState
+	5,  // 4: order.Return.lines:type_name -> order.ReturnLine
+	19, // 5: order.Return.refund_total:type_name -> common.Money
+	19, // 6: order.OrderItem.unit_price:type_name -> common.Money
+	19, // 7: order.OrderItem.line_total:type_name -> common.Money
+	19, // 8: order.OrderItem.tax:type_name -> common.Money
+	20, // 9: order.CreateOrderRequest.payment_method:type_name -> common.PaymentMethod
+	19, // 10: order.CreateOrderRequest.wallet_amount:type_name -> common.Money
+	0,  // 11: order.CreateOrderResponse.status:type_name -> order.OrderStatus
+	7,  // 12: order.CreateOrderResponse.items:type_name -> order.OrderItem
+	19, // 13: order.CreateOrderResponse.total:type_name -> common.Money
+	21, // 14: order.CreateOrderResponse.breakdown:type_name -> common.PriceBreakdown
+	0,  // 15: order.GetOrderStatusResponse.status:type_name -> order.OrderStatus
+	19, // 16: order.GetOrderStatusResponse.total:type_name -> common.Money
+	22, // 17: order.GetOrderStatusResponse.shipping_address:type_name -> common.Address
+	4,  // 18: order.GetOrderStatusResponse.fulfillment:type_name -> order.Fulfillment
+	21, // 19: order.GetOrderStatusResponse.breakdown:type_name -> common.PriceBreakdown
+	7,  // 20: order.GetOrderStatusResponse.items:type_name -> order.OrderItem
+	20, // 21: order.GetOrderStatusResponse.payment_method:type_name -> common.PaymentMethod
+	0,  // 22: order.OrderEvent.status:type_name -> order.OrderStatus
+	1,  // 23: order.OrderEvent.fulfillment_state:type_name -> order.FulfillmentState
+	1,  // 24: order.UpdateFulfillmentRequest.state:type_name -> order.FulfillmentState
+	5,  // 25: order.RequestReturnRequest.lines:type_name -> order.ReturnLine
+	6,  // 26: order.ListReturnsResponse.returns:type_name -> order.Return
+	8,  // 27: order.Order.CreateOrder:input_type -> order.CreateOrderRequest
+	10, // 28: order.Order.GetOrderStatus:input_type -> order.GetOrderStatusRequest
+	15, // 29: order.Order.RequestReturn:input_type -> order.RequestReturnRequest
+	23, // 30: order.Order.ListReturns:input_type -> common.OrderRef
+	12, // 31: order.Order.WatchOrder:input_type -> order.WatchOrderRequest
+	14, // 32: order.OrderAdmin.UpdateFulfillment:input_type -> order.UpdateFulfillmentRequest
+	16, // 33: order.OrderAdmin.ReviewReturn:input_type -> order.ReviewReturnRequest
+	17, // 34: order.OrderAdmin.ReceiveReturn:input_type -> order.ReturnRef
+	9,  // 35: order.Order.CreateOrder:output_type -> order.CreateOrderResponse
+	11, // 36: order.Order.GetOrderStatus:output_type -> order.GetOrderStatusResponse
+	6,  // 37: order.Order.RequestReturn:output_type -> order.Return
+	18, // 38: order.Order.ListReturns:output_type -> order.ListReturnsResponse
+	13, // 39: order.Order.WatchOrder:output_type -> order.OrderEvent
+	4,  // 40: order.OrderAdmin.UpdateFulfillment:output_type -> order.Fulfillment
+	6,  // 41: order.OrderAdmin.ReviewReturn:output_type -> order.Return
+	6,  // 42: order.OrderAdmin.ReceiveReturn:output_type -> order.Return
+	35, // [35:43] is the sub-list for method output_type
+	27, // [27:35] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_order_proto_init() }
@@ -466,10 +1645,10 @@ func file_order_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_order_proto_rawDesc), len(file_order_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   5,
+			NumEnums:      4,
+			NumMessages:   15,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_order_proto_goTypes,
 		DependencyIndexes: file_order_proto_depIdxs,
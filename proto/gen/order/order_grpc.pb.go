@@ -8,6 +8,7 @@ package orderpb
 
 import (
 	context "context"
+	common "github.com/ahinestrog/mybookstore/proto/gen/common"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -21,6 +22,9 @@ const _ = grpc.SupportPackageIsVersion9
 const (
 	Order_CreateOrder_FullMethodName    = "/order.Order/CreateOrder"
 	Order_GetOrderStatus_FullMethodName = "/order.Order/GetOrderStatus"
+	Order_RequestReturn_FullMethodName  = "/order.Order/RequestReturn"
+	Order_ListReturns_FullMethodName    = "/order.Order/ListReturns"
+	Order_WatchOrder_FullMethodName     = "/order.Order/WatchOrder"
 )
 
 // OrderClient is the client API for Order service.
@@ -31,6 +35,15 @@ type OrderClient interface {
 	CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*CreateOrderResponse, error)
 	// Consulta estado actual de la orden.
 	GetOrderStatus(ctx context.Context, in *GetOrderStatusRequest, opts ...grpc.CallOption) (*GetOrderStatusResponse, error)
+	// Solicita la devolución de algunas o todas las líneas de una orden entregada.
+	RequestReturn(ctx context.Context, in *RequestReturnRequest, opts ...grpc.CallOption) (*Return, error)
+	// Devoluciones de una orden, de la más reciente a la más antigua.
+	ListReturns(ctx context.Context, in *common.OrderRef, opts ...grpc.CallOption) (*ListReturnsResponse, error)
+	// Sigue los cambios de estado y de despacho de la orden a medida que los
+	// aplica la saga. Con after_event_id = 0 el primer mensaje es el estado actual;
+	// al reconectar, con el último event_id recibido, se reenvían los posteriores.
+	// El stream termina cuando la orden ya no puede cambiar (ver OrderEvent.final).
+	WatchOrder(ctx context.Context, in *WatchOrderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderEvent], error)
 }
 
 type orderClient struct {
@@ -61,6 +74,45 @@ func (c *orderClient) GetOrderStatus(ctx context.Context, in *GetOrderStatusRequ
 	return out, nil
 }
 
+func (c *orderClient) RequestReturn(ctx context.Context, in *RequestReturnRequest, opts ...grpc.CallOption) (*Return, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Return)
+	err := c.cc.Invoke(ctx, Order_RequestReturn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderClient) ListReturns(ctx context.Context, in *common.OrderRef, opts ...grpc.CallOption) (*ListReturnsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReturnsResponse)
+	err := c.cc.Invoke(ctx, Order_ListReturns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderClient) WatchOrder(ctx context.Context, in *WatchOrderRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Order_ServiceDesc.Streams[0], Order_WatchOrder_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchOrderRequest, OrderEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Order_WatchOrderClient = grpc.ServerStreamingClient[OrderEvent]
+
 // OrderServer is the server API for Order service.
 // All implementations must embed UnimplementedOrderServer
 // for forward compatibility.
@@ -69,6 +121,15 @@ type OrderServer interface {
 	CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error)
 	// Consulta estado actual de la orden.
 	GetOrderStatus(context.Context, *GetOrderStatusRequest) (*GetOrderStatusResponse, error)
+	// Solicita la devolución de algunas o todas las líneas de una orden entregada.
+	RequestReturn(context.Context, *RequestReturnRequest) (*Return, error)
+	// Devoluciones de una orden, de la más reciente a la más antigua.
+	ListReturns(context.Context, *common.OrderRef) (*ListReturnsResponse, error)
+	// Sigue los cambios de estado y de despacho de la orden a medida que los
+	// aplica la saga. Con after_event_id = 0 el primer mensaje es el estado actual;
+	// al reconectar, con el último event_id recibido, se reenvían los posteriores.
+	// El stream termina cuando la orden ya no puede cambiar (ver OrderEvent.final).
+	WatchOrder(*WatchOrderRequest, grpc.ServerStreamingServer[OrderEvent]) error
 	mustEmbedUnimplementedOrderServer()
 }
 
@@ -85,6 +146,15 @@ func (UnimplementedOrderServer) CreateOrder(context.Context, *CreateOrderRequest
 func (UnimplementedOrderServer) GetOrderStatus(context.Context, *GetOrderStatusRequest) (*GetOrderStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOrderStatus not implemented")
 }
+func (UnimplementedOrderServer) RequestReturn(context.Context, *RequestReturnRequest) (*Return, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestReturn not implemented")
+}
+func (UnimplementedOrderServer) ListReturns(context.Context, *common.OrderRef) (*ListReturnsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReturns not implemented")
+}
+func (UnimplementedOrderServer) WatchOrder(*WatchOrderRequest, grpc.ServerStreamingServer[OrderEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOrder not implemented")
+}
 func (UnimplementedOrderServer) mustEmbedUnimplementedOrderServer() {}
 func (UnimplementedOrderServer) testEmbeddedByValue()               {}
 
@@ -142,6 +212,53 @@ func _Order_GetOrderStatus_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Order_RequestReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServer).RequestReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Order_RequestReturn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServer).RequestReturn(ctx, req.(*RequestReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Order_ListReturns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.OrderRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServer).ListReturns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Order_ListReturns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServer).ListReturns(ctx, req.(*common.OrderRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Order_WatchOrder_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOrderRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderServer).WatchOrder(m, &grpc.GenericServerStream[WatchOrderRequest, OrderEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Order_WatchOrderServer = grpc.ServerStreamingServer[OrderEvent]
+
 // Order_ServiceDesc is the grpc.ServiceDesc for Order service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,6 +274,212 @@ var Order_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetOrderStatus",
 			Handler:    _Order_GetOrderStatus_Handler,
 		},
+		{
+			MethodName: "RequestReturn",
+			Handler:    _Order_RequestReturn_Handler,
+		},
+		{
+			MethodName: "ListReturns",
+			Handler:    _Order_ListReturns_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOrder",
+			Handler:       _Order_WatchOrder_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "order.proto",
+}
+
+const (
+	OrderAdmin_UpdateFulfillment_FullMethodName = "/order.OrderAdmin/UpdateFulfillment"
+	OrderAdmin_ReviewReturn_FullMethodName      = "/order.OrderAdmin/ReviewReturn"
+	OrderAdmin_ReceiveReturn_FullMethodName     = "/order.OrderAdmin/ReceiveReturn"
+)
+
+// OrderAdminClient is the client API for OrderAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Operaciones de bodega/administración sobre el despacho de órdenes pagadas.
+type OrderAdminClient interface {
+	// Avanza el despacho (PICKING → PACKED → SHIPPED → DELIVERED). SHIPPED exige
+	// carrier y tracking_number. Repetir el estado actual no tiene efecto.
+	UpdateFulfillment(ctx context.Context, in *UpdateFulfillmentRequest, opts ...grpc.CallOption) (*Fulfillment, error)
+	// Aprueba o rechaza una devolución REQUESTED. Repetir la misma decisión no tiene efecto.
+	ReviewReturn(ctx context.Context, in *ReviewReturnRequest, opts ...grpc.CallOption) (*Return, error)
+	// Registra la llegada de los libros a bodega: reingresa el stock y solicita el
+	// reembolso. Sobre una devolución REFUND_FAILED reintenta solo el reembolso.
+	ReceiveReturn(ctx context.Context, in *ReturnRef, opts ...grpc.CallOption) (*Return, error)
+}
+
+type orderAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrderAdminClient(cc grpc.ClientConnInterface) OrderAdminClient {
+	return &orderAdminClient{cc}
+}
+
+func (c *orderAdminClient) UpdateFulfillment(ctx context.Context, in *UpdateFulfillmentRequest, opts ...grpc.CallOption) (*Fulfillment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Fulfillment)
+	err := c.cc.Invoke(ctx, OrderAdmin_UpdateFulfillment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderAdminClient) ReviewReturn(ctx context.Context, in *ReviewReturnRequest, opts ...grpc.CallOption) (*Return, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Return)
+	err := c.cc.Invoke(ctx, OrderAdmin_ReviewReturn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderAdminClient) ReceiveReturn(ctx context.Context, in *ReturnRef, opts ...grpc.CallOption) (*Return, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Return)
+	err := c.cc.Invoke(ctx, OrderAdmin_ReceiveReturn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OrderAdminServer is the server API for OrderAdmin service.
+// All implementations must embed UnimplementedOrderAdminServer
+// for forward compatibility.
+//
+// Operaciones de bodega/administración sobre el despacho de órdenes pagadas.
+type OrderAdminServer interface {
+	// Avanza el despacho (PICKING → PACKED → SHIPPED → DELIVERED). SHIPPED exige
+	// carrier y tracking_number. Repetir el estado actual no tiene efecto.
+	UpdateFulfillment(context.Context, *UpdateFulfillmentRequest) (*Fulfillment, error)
+	// Aprueba o rechaza una devolución REQUESTED. Repetir la misma decisión no tiene efecto.
+	ReviewReturn(context.Context, *ReviewReturnRequest) (*Return, error)
+	// Registra la llegada de los libros a bodega: reingresa el stock y solicita el
+	// reembolso. Sobre una devolución REFUND_FAILED reintenta solo el reembolso.
+	ReceiveReturn(context.Context, *ReturnRef) (*Return, error)
+	mustEmbedUnimplementedOrderAdminServer()
+}
+
+// UnimplementedOrderAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOrderAdminServer struct{}
+
+func (UnimplementedOrderAdminServer) UpdateFulfillment(context.Context, *UpdateFulfillmentRequest) (*Fulfillment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFulfillment not implemented")
+}
+func (UnimplementedOrderAdminServer) ReviewReturn(context.Context, *ReviewReturnRequest) (*Return, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReviewReturn not implemented")
+}
+func (UnimplementedOrderAdminServer) ReceiveReturn(context.Context, *ReturnRef) (*Return, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReceiveReturn not implemented")
+}
+func (UnimplementedOrderAdminServer) mustEmbedUnimplementedOrderAdminServer() {}
+func (UnimplementedOrderAdminServer) testEmbeddedByValue()                    {}
+
+// UnsafeOrderAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrderAdminServer will
+// result in compilation errors.
+type UnsafeOrderAdminServer interface {
+	mustEmbedUnimplementedOrderAdminServer()
+}
+
+func RegisterOrderAdminServer(s grpc.ServiceRegistrar, srv OrderAdminServer) {
+	// If the following call pancis, it indicates UnimplementedOrderAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&OrderAdmin_ServiceDesc, srv)
+}
+
+func _OrderAdmin_UpdateFulfillment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFulfillmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderAdminServer).UpdateFulfillment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderAdmin_UpdateFulfillment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderAdminServer).UpdateFulfillment(ctx, req.(*UpdateFulfillmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderAdmin_ReviewReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderAdminServer).ReviewReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderAdmin_ReviewReturn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderAdminServer).ReviewReturn(ctx, req.(*ReviewReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderAdmin_ReceiveReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReturnRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderAdminServer).ReceiveReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderAdmin_ReceiveReturn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderAdminServer).ReceiveReturn(ctx, req.(*ReturnRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// OrderAdmin_ServiceDesc is the grpc.ServiceDesc for OrderAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OrderAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "order.OrderAdmin",
+	HandlerType: (*OrderAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UpdateFulfillment",
+			Handler:    _OrderAdmin_UpdateFulfillment_Handler,
+		},
+		{
+			MethodName: "ReviewReturn",
+			Handler:    _OrderAdmin_ReviewReturn_Handler,
+		},
+		{
+			MethodName: "ReceiveReturn",
+			Handler:    _OrderAdmin_ReceiveReturn_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "order.proto",
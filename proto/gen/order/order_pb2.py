@@ -12,9 +12,10 @@ _sym_db = _symbol_database.Default()
 
 
 import common_pb2 as common__pb2
+from google.api import annotations_pb2 as google_dot_api_dot_annotations__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0border.proto\x12\x05order\x1a\x0c\x63ommon.proto\"~\n\tOrderItem\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\x12\r\n\x05title\x18\x02 \x01(\t\x12\x0b\n\x03qty\x18\x03 \x01(\x05\x12!\n\nunit_price\x18\x04 \x01(\x0b\x32\r.common.Money\x12!\n\nline_total\x18\x05 \x01(\x0b\x32\r.common.Money\"%\n\x12\x43reateOrderRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\"\x8a\x01\n\x13\x43reateOrderResponse\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12\"\n\x06status\x18\x02 \x01(\x0e\x32\x12.order.OrderStatus\x12\x1f\n\x05items\x18\x03 \x03(\x0b\x32\x10.order.OrderItem\x12\x1c\n\x05total\x18\x04 \x01(\x0b\x32\r.common.Money\")\n\x15GetOrderStatusRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\"\x82\x01\n\x16GetOrderStatusResponse\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12\"\n\x06status\x18\x02 \x01(\x0e\x32\x12.order.OrderStatus\x12\x1c\n\x05total\x18\x03 \x01(\x0b\x32\r.common.Money\x12\x14\n\x0cupdated_unix\x18\x04 \x01(\x03*\x91\x01\n\x0bOrderStatus\x12\x1c\n\x18ORDER_STATUS_UNSPECIFIED\x10\x00\x12\x18\n\x14ORDER_STATUS_CREATED\x10\x01\x12\x15\n\x11ORDER_STATUS_PAID\x10\x02\x12\x1a\n\x16ORDER_STATUS_CANCELLED\x10\x03\x12\x17\n\x13ORDER_STATUS_FAILED\x10\x04\x32\x9c\x01\n\x05Order\x12\x44\n\x0b\x43reateOrder\x12\x19.order.CreateOrderRequest\x1a\x1a.order.CreateOrderResponse\x12M\n\x0eGetOrderStatus\x12\x1c.order.GetOrderStatusRequest\x1a\x1d.order.GetOrderStatusResponseB;Z9github.com/ahinestrog/mybookstore/proto/gen/order;orderpbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\x0border.proto\x12\x05order\x1a\x0c\x63ommon.proto\x1a\x1cgoogle/api/annotations.proto\"\xb5\x01\n\x0b\x46ulfillment\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12&\n\x05state\x18\x02 \x01(\x0e\x32\x17.order.FulfillmentState\x12\x0f\n\x07\x63\x61rrier\x18\x03 \x01(\t\x12\x17\n\x0ftracking_number\x18\x04 \x01(\t\x12\x14\n\x0cshipped_unix\x18\x05 \x01(\x03\x12\x16\n\x0e\x64\x65livered_unix\x18\x06 \x01(\x03\x12\x14\n\x0cupdated_unix\x18\x07 \x01(\x03\"}\n\nReturnLine\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\x12\x0b\n\x03qty\x18\x02 \x01(\x05\x12#\n\x06reason\x18\x03 \x01(\x0e\x32\x13.order.ReturnReason\x12\r\n\x05title\x18\x04 \x01(\t\x12\x1d\n\x06refund\x18\x05 \x01(\x0b\x32\r.common.Money\"\x9e\x02\n\x06Return\x12\x11\n\treturn_id\x18\x01 \x01(\x03\x12\x10\n\x08order_id\x18\x02 \x01(\x03\x12\x0f\n\x07user_id\x18\x03 \x01(\x03\x12!\n\x05state\x18\x04 \x01(\x0e\x32\x12.order.ReturnState\x12 \n\x05lines\x18\x05 \x03(\x0b\x32\x11.order.ReturnLine\x12\x0c\n\x04note\x18\x06 \x01(\t\x12\x13\n\x0breview_note\x18\x07 \x01(\t\x12#\n\x0crefund_total\x18\x08 \x01(\x0b\x32\r.common.Money\x12\x11\n\trestocked\x18\t \x01(\x08\x12\x12\n\nrefund_ref\x18\n \x01(\t\x12\x14\n\x0c\x63reated_unix\x18\x0b \x01(\x03\x12\x14\n\x0cupdated_unix\x18\x0c \x01(\x03\"\x9a\x01\n\tOrderItem\x12\x0f\n\x07\x62ook_id\x18\x01 \x01(\x03\x12\r\n\x05title\x18\x02 \x01(\t\x12\x0b\n\x03qty\x18\x03 \x01(\x05\x12!\n\nunit_price\x18\x04 \x01(\x0b\x32\r.common.Money\x12!\n\nline_total\x18\x05 \x01(\x0b\x32\r.common.Money\x12\x1a\n\x03tax\x18\x06 \x01(\x0b\x32\r.common.Money\"\xbe\x01\n\x12\x43reateOrderRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x17\n\x0fidempotency_key\x18\x02 \x01(\t\x12\x12\n\naddress_id\x18\x03 \x01(\x03\x12-\n\x0epayment_method\x18\x04 \x01(\x0e\x32\x15.common.PaymentMethod\x12\x15\n\rinstrument_id\x18\x05 \x01(\x03\x12$\n\rwallet_amount\x18\x06 \x01(\x0b\x32\r.common.Money\"\xb5\x01\n\x13\x43reateOrderResponse\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12\"\n\x06status\x18\x02 \x01(\x0e\x32\x12.order.OrderStatus\x12\x1f\n\x05items\x18\x03 \x03(\x0b\x32\x10.order.OrderItem\x12\x1c\n\x05total\x18\x04 \x01(\x0b\x32\r.common.Money\x12)\n\tbreakdown\x18\x05 \x01(\x0b\x32\x16.common.PriceBreakdown\")\n\x15GetOrderStatusRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\"\xe8\x02\n\x16GetOrderStatusResponse\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12\"\n\x06status\x18\x02 \x01(\x0e\x32\x12.order.OrderStatus\x12\x1c\n\x05total\x18\x03 \x01(\x0b\x32\r.common.Money\x12\x14\n\x0cupdated_unix\x18\x04 \x01(\x03\x12)\n\x10shipping_address\x18\x05 \x01(\x0b\x32\x0f.common.Address\x12\'\n\x0b\x66ulfillment\x18\x06 \x01(\x0b\x32\x12.order.Fulfillment\x12)\n\tbreakdown\x18\x07 \x01(\x0b\x32\x16.common.PriceBreakdown\x12\x1f\n\x05items\x18\x08 \x03(\x0b\x32\x10.order.OrderItem\x12-\n\x0epayment_method\x18\t \x01(\x0e\x32\x15.common.PaymentMethod\x12\x15\n\rstatus_reason\x18\n \x01(\t\"=\n\x11WatchOrderRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12\x16\n\x0e\x61\x66ter_event_id\x18\x02 \x01(\x03\"\xc4\x01\n\nOrderEvent\x12\x10\n\x08\x65vent_id\x18\x01 \x01(\x03\x12\x10\n\x08order_id\x18\x02 \x01(\x03\x12\"\n\x06status\x18\x03 \x01(\x0e\x32\x12.order.OrderStatus\x12\x15\n\rstatus_reason\x18\x04 \x01(\t\x12\x32\n\x11\x66ulfillment_state\x18\x05 \x01(\x0e\x32\x17.order.FulfillmentState\x12\x14\n\x0c\x63reated_unix\x18\x06 \x01(\x03\x12\r\n\x05\x66inal\x18\x07 \x01(\x08\"~\n\x18UpdateFulfillmentRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12&\n\x05state\x18\x02 \x01(\x0e\x32\x17.order.FulfillmentState\x12\x0f\n\x07\x63\x61rrier\x18\x03 \x01(\t\x12\x17\n\x0ftracking_number\x18\x04 \x01(\t\"i\n\x14RequestReturnRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12\x0f\n\x07user_id\x18\x02 \x01(\x03\x12 \n\x05lines\x18\x03 \x03(\x0b\x32\x11.order.ReturnLine\x12\x0c\n\x04note\x18\x04 \x01(\t\"G\n\x13ReviewReturnRequest\x12\x11\n\treturn_id\x18\x01 \x01(\x03\x12\x0f\n\x07\x61pprove\x18\x02 \x01(\x08\x12\x0c\n\x04note\x18\x03 \x01(\t\"\x1e\n\tReturnRef\x12\x11\n\treturn_id\x18\x01 \x01(\x03\"5\n\x13ListReturnsResponse\x12\x1e\n\x07returns\x18\x01 \x03(\x0b\x32\r.order.Return*\xad\x01\n\x0bOrderStatus\x12\x1c\n\x18ORDER_STATUS_UNSPECIFIED\x10\x00\x12\x18\n\x14ORDER_STATUS_CREATED\x10\x01\x12\x15\n\x11ORDER_STATUS_PAID\x10\x02\x12\x1a\n\x16ORDER_STATUS_CANCELLED\x10\x03\x12\x17\n\x13ORDER_STATUS_FAILED\x10\x04\x12\x1a\n\x16ORDER_STATUS_CONFIRMED\x10\x05*\xd1\x01\n\x10\x46ulfillmentState\x12!\n\x1d\x46ULFILLMENT_STATE_UNSPECIFIED\x10\x00\x12\x1d\n\x19\x46ULFILLMENT_STATE_PENDING\x10\x01\x12\x1d\n\x19\x46ULFILLMENT_STATE_PICKING\x10\x02\x12\x1c\n\x18\x46ULFILLMENT_STATE_PACKED\x10\x03\x12\x1d\n\x19\x46ULFILLMENT_STATE_SHIPPED\x10\x04\x12\x1f\n\x1b\x46ULFILLMENT_STATE_DELIVERED\x10\x05*\xd3\x01\n\x0bReturnState\x12\x1c\n\x18RETURN_STATE_UNSPECIFIED\x10\x00\x12\x1a\n\x16RETURN_STATE_REQUESTED\x10\x01\x12\x19\n\x15RETURN_STATE_APPROVED\x10\x02\x12\x19\n\x15RETURN_STATE_REJECTED\x10\x03\x12\x19\n\x15RETURN_STATE_RECEIVED\x10\x04\x12\x19\n\x15RETURN_STATE_REFUNDED\x10\x05\x12\x1e\n\x1aRETURN_STATE_REFUND_FAILED\x10\x06*\xc7\x01\n\x0cReturnReason\x12\x1d\n\x19RETURN_REASON_UNSPECIFIED\x10\x00\x12\x19\n\x15RETURN_REASON_DAMAGED\x10\x01\x12\x1c\n\x18RETURN_REASON_WRONG_ITEM\x10\x02\x12\"\n\x1eRETURN_REASON_NOT_AS_DESCRIBED\x10\x03\x12\"\n\x1eRETURN_REASON_NO_LONGER_NEEDED\x10\x04\x12\x17\n\x13RETURN_REASON_OTHER\x10\x05\x32\x8a\x04\n\x05Order\x12o\n\x0b\x43reateOrder\x12\x19.order.CreateOrderRequest\x1a\x1a.order.CreateOrderResponse\")\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/api/v1/users/{user_id}/orders\x12p\n\x0eGetOrderStatus\x12\x1c.order.GetOrderStatusRequest\x1a\x1d.order.GetOrderStatusResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/api/v1/orders/{order_id}\x12y\n\rRequestReturn\x12\x1b.order.RequestReturnRequest\x1a\r.order.Return\"<\x82\xd3\xe4\x93\x02\x36:\x01*\"1/api/v1/users/{user_id}/orders/{order_id}/returns\x12\x66\n\x0bListReturns\x12\x10.common.OrderRef\x1a\x1a.order.ListReturnsResponse\")\x82\xd3\xe4\x93\x02#\x12!/api/v1/orders/{order_id}/returns\x12;\n\nWatchOrder\x12\x18.order.WatchOrderRequest\x1a\x11.order.OrderEvent0\x01\x32\xc3\x01\n\nOrderAdmin\x12H\n\x11UpdateFulfillment\x12\x1f.order.UpdateFulfillmentRequest\x1a\x12.order.Fulfillment\x12\x39\n\x0cReviewReturn\x12\x1a.order.ReviewReturnRequest\x1a\r.order.Return\x12\x30\n\rReceiveReturn\x12\x10.order.ReturnRef\x1a\r.order.ReturnB;Z9github.com/ahinestrog/mybookstore/proto/gen/order;orderpbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -22,18 +23,54 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'order_pb2', _globals)
 if _descriptor._USE_C_DESCRIPTORS == False:
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z9github.com/ahinestrog/mybookstore/proto/gen/order;orderpb'
-  _globals['_ORDERSTATUS']._serialized_start=521
-  _globals['_ORDERSTATUS']._serialized_end=666
-  _globals['_ORDERITEM']._serialized_start=36
-  _globals['_ORDERITEM']._serialized_end=162
-  _globals['_CREATEORDERREQUEST']._serialized_start=164
-  _globals['_CREATEORDERREQUEST']._serialized_end=201
-  _globals['_CREATEORDERRESPONSE']._serialized_start=204
-  _globals['_CREATEORDERRESPONSE']._serialized_end=342
-  _globals['_GETORDERSTATUSREQUEST']._serialized_start=344
-  _globals['_GETORDERSTATUSREQUEST']._serialized_end=385
-  _globals['_GETORDERSTATUSRESPONSE']._serialized_start=388
-  _globals['_GETORDERSTATUSRESPONSE']._serialized_end=518
-  _globals['_ORDER']._serialized_start=669
-  _globals['_ORDER']._serialized_end=825
+  _globals['_ORDER'].methods_by_name['CreateOrder']._options = None
+  _globals['_ORDER'].methods_by_name['CreateOrder']._serialized_options = b'\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/api/v1/users/{user_id}/orders'
+  _globals['_ORDER'].methods_by_name['GetOrderStatus']._options = None
+  _globals['_ORDER'].methods_by_name['GetOrderStatus']._serialized_options = b'\x82\xd3\xe4\x93\x02\x1b\x12\x19/api/v1/orders/{order_id}'
+  _globals['_ORDER'].methods_by_name['RequestReturn']._options = None
+  _globals['_ORDER'].methods_by_name['RequestReturn']._serialized_options = b'\x82\xd3\xe4\x93\x02\x36:\x01*\"1/api/v1/users/{user_id}/orders/{order_id}/returns'
+  _globals['_ORDER'].methods_by_name['ListReturns']._options = None
+  _globals['_ORDER'].methods_by_name['ListReturns']._serialized_options = b'\x82\xd3\xe4\x93\x02#\x12!/api/v1/orders/{order_id}/returns'
+  _globals['_ORDERSTATUS']._serialized_start=2264
+  _globals['_ORDERSTATUS']._serialized_end=2437
+  _globals['_FULFILLMENTSTATE']._serialized_start=2440
+  _globals['_FULFILLMENTSTATE']._serialized_end=2649
+  _globals['_RETURNSTATE']._serialized_start=2652
+  _globals['_RETURNSTATE']._serialized_end=2863
+  _globals['_RETURNREASON']._serialized_start=2866
+  _globals['_RETURNREASON']._serialized_end=3065
+  _globals['_FULFILLMENT']._serialized_start=67
+  _globals['_FULFILLMENT']._serialized_end=248
+  _globals['_RETURNLINE']._serialized_start=250
+  _globals['_RETURNLINE']._serialized_end=375
+  _globals['_RETURN']._serialized_start=378
+  _globals['_RETURN']._serialized_end=664
+  _globals['_ORDERITEM']._serialized_start=667
+  _globals['_ORDERITEM']._serialized_end=821
+  _globals['_CREATEORDERREQUEST']._serialized_start=824
+  _globals['_CREATEORDERREQUEST']._serialized_end=1014
+  _globals['_CREATEORDERRESPONSE']._serialized_start=1017
+  _globals['_CREATEORDERRESPONSE']._serialized_end=1198
+  _globals['_GETORDERSTATUSREQUEST']._serialized_start=1200
+  _globals['_GETORDERSTATUSREQUEST']._serialized_end=1241
+  _globals['_GETORDERSTATUSRESPONSE']._serialized_start=1244
+  _globals['_GETORDERSTATUSRESPONSE']._serialized_end=1604
+  _globals['_WATCHORDERREQUEST']._serialized_start=1606
+  _globals['_WATCHORDERREQUEST']._serialized_end=1667
+  _globals['_ORDEREVENT']._serialized_start=1670
+  _globals['_ORDEREVENT']._serialized_end=1866
+  _globals['_UPDATEFULFILLMENTREQUEST']._serialized_start=1868
+  _globals['_UPDATEFULFILLMENTREQUEST']._serialized_end=1994
+  _globals['_REQUESTRETURNREQUEST']._serialized_start=1996
+  _globals['_REQUESTRETURNREQUEST']._serialized_end=2101
+  _globals['_REVIEWRETURNREQUEST']._serialized_start=2103
+  _globals['_REVIEWRETURNREQUEST']._serialized_end=2174
+  _globals['_RETURNREF']._serialized_start=2176
+  _globals['_RETURNREF']._serialized_end=2206
+  _globals['_LISTRETURNSRESPONSE']._serialized_start=2208
+  _globals['_LISTRETURNSRESPONSE']._serialized_end=2261
+  _globals['_ORDER']._serialized_start=3068
+  _globals['_ORDER']._serialized_end=3590
+  _globals['_ORDERADMIN']._serialized_start=3593
+  _globals['_ORDERADMIN']._serialized_end=3788
 # @@protoc_insertion_point(module_scope)
@@ -2,6 +2,7 @@
 """Client and server classes corresponding to protobuf-defined services."""
 import grpc
 
+import common_pb2 as common__pb2
 import order_pb2 as order__pb2
 
 
@@ -24,6 +25,21 @@ class OrderStub(object):
                 request_serializer=order__pb2.GetOrderStatusRequest.SerializeToString,
                 response_deserializer=order__pb2.GetOrderStatusResponse.FromString,
                 )
+        self.RequestReturn = channel.unary_unary(
+                '/order.Order/RequestReturn',
+                request_serializer=order__pb2.RequestReturnRequest.SerializeToString,
+                response_deserializer=order__pb2.Return.FromString,
+                )
+        self.ListReturns = channel.unary_unary(
+                '/order.Order/ListReturns',
+                request_serializer=common__pb2.OrderRef.SerializeToString,
+                response_deserializer=order__pb2.ListReturnsResponse.FromString,
+                )
+        self.WatchOrder = channel.unary_stream(
+                '/order.Order/WatchOrder',
+                request_serializer=order__pb2.WatchOrderRequest.SerializeToString,
+                response_deserializer=order__pb2.OrderEvent.FromString,
+                )
 
 
 class OrderServicer(object):
@@ -43,6 +59,30 @@ class OrderServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def RequestReturn(self, request, context):
+        """Solicita la devolución de algunas o todas las líneas de una orden entregada.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListReturns(self, request, context):
+        """Devoluciones de una orden, de la más reciente a la más antigua.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def WatchOrder(self, request, context):
+        """Sigue los cambios de estado y de despacho de la orden a medida que los
+        aplica la saga. Con after_event_id = 0 el primer mensaje es el estado actual;
+        al reconectar, con el último event_id recibido, se reenvían los posteriores.
+        El stream termina cuando la orden ya no puede cambiar (ver OrderEvent.final).
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_OrderServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -56,6 +96,21 @@ def add_OrderServicer_to_server(servicer, server):
                     request_deserializer=order__pb2.GetOrderStatusRequest.FromString,
                     response_serializer=order__pb2.GetOrderStatusResponse.SerializeToString,
             ),
+            'RequestReturn': grpc.unary_unary_rpc_method_handler(
+                    servicer.RequestReturn,
+                    request_deserializer=order__pb2.RequestReturnRequest.FromString,
+                    response_serializer=order__pb2.Return.SerializeToString,
+            ),
+            'ListReturns': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListReturns,
+                    request_deserializer=common__pb2.OrderRef.FromString,
+                    response_serializer=order__pb2.ListReturnsResponse.SerializeToString,
+            ),
+            'WatchOrder': grpc.unary_stream_rpc_method_handler(
+                    servicer.WatchOrder,
+                    request_deserializer=order__pb2.WatchOrderRequest.FromString,
+                    response_serializer=order__pb2.OrderEvent.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'order.Order', rpc_method_handlers)
@@ -99,3 +154,189 @@ class Order(object):
             order__pb2.GetOrderStatusResponse.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RequestReturn(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/order.Order/RequestReturn',
+            order__pb2.RequestReturnRequest.SerializeToString,
+            order__pb2.Return.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListReturns(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/order.Order/ListReturns',
+            common__pb2.OrderRef.SerializeToString,
+            order__pb2.ListReturnsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def WatchOrder(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_stream(request, target, '/order.Order/WatchOrder',
+            order__pb2.WatchOrderRequest.SerializeToString,
+            order__pb2.OrderEvent.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+
+class OrderAdminStub(object):
+    """Operaciones de bodega/administración sobre el despacho de órdenes pagadas.
+    """
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.UpdateFulfillment = channel.unary_unary(
+                '/order.OrderAdmin/UpdateFulfillment',
+                request_serializer=order__pb2.UpdateFulfillmentRequest.SerializeToString,
+                response_deserializer=order__pb2.Fulfillment.FromString,
+                )
+        self.ReviewReturn = channel.unary_unary(
+                '/order.OrderAdmin/ReviewReturn',
+                request_serializer=order__pb2.ReviewReturnRequest.SerializeToString,
+                response_deserializer=order__pb2.Return.FromString,
+                )
+        self.ReceiveReturn = channel.unary_unary(
+                '/order.OrderAdmin/ReceiveReturn',
+                request_serializer=order__pb2.ReturnRef.SerializeToString,
+                response_deserializer=order__pb2.Return.FromString,
+                )
+
+
+class OrderAdminServicer(object):
+    """Operaciones de bodega/administración sobre el despacho de órdenes pagadas.
+    """
+
+    def UpdateFulfillment(self, request, context):
+        """Avanza el despacho (PICKING → PACKED → SHIPPED → DELIVERED). SHIPPED exige
+        carrier y tracking_number. Repetir el estado actual no tiene efecto.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReviewReturn(self, request, context):
+        """Aprueba o rechaza una devolución REQUESTED. Repetir la misma decisión no tiene efecto.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ReceiveReturn(self, request, context):
+        """Registra la llegada de los libros a bodega: reingresa el stock y solicita el
+        reembolso. Sobre una devolución REFUND_FAILED reintenta solo el reembolso.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_OrderAdminServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+            'UpdateFulfillment': grpc.unary_unary_rpc_method_handler(
+                    servicer.UpdateFulfillment,
+                    request_deserializer=order__pb2.UpdateFulfillmentRequest.FromString,
+                    response_serializer=order__pb2.Fulfillment.SerializeToString,
+            ),
+            'ReviewReturn': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReviewReturn,
+                    request_deserializer=order__pb2.ReviewReturnRequest.FromString,
+                    response_serializer=order__pb2.Return.SerializeToString,
+            ),
+            'ReceiveReturn': grpc.unary_unary_rpc_method_handler(
+                    servicer.ReceiveReturn,
+                    request_deserializer=order__pb2.ReturnRef.FromString,
+                    response_serializer=order__pb2.Return.SerializeToString,
+            ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+            'order.OrderAdmin', rpc_method_handlers)
+    server.add_generic_rpc_handlers((generic_handler,))
+
+
+ # This class is part of an EXPERIMENTAL API.
+class OrderAdmin(object):
+    """Operaciones de bodega/administración sobre el despacho de órdenes pagadas.
+    """
+
+    @staticmethod
+    def UpdateFulfillment(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/order.OrderAdmin/UpdateFulfillment',
+            order__pb2.UpdateFulfillmentRequest.SerializeToString,
+            order__pb2.Fulfillment.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ReviewReturn(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/order.OrderAdmin/ReviewReturn',
+            order__pb2.ReviewReturnRequest.SerializeToString,
+            order__pb2.Return.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ReceiveReturn(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/order.OrderAdmin/ReceiveReturn',
+            order__pb2.ReturnRef.SerializeToString,
+            order__pb2.Return.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
@@ -7,7 +7,8 @@
 package paymentpb
 
 import (
-	_ "github.com/ahinestrog/mybookstore/proto/gen/common"
+	common "github.com/ahinestrog/mybookstore/proto/gen/common"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -25,10 +26,14 @@ const (
 type PaymentState int32
 
 const (
-	PaymentState_PAYMENT_STATE_UNSPECIFIED PaymentState = 0
-	PaymentState_PAYMENT_STATE_PENDING     PaymentState = 1
-	PaymentState_PAYMENT_STATE_SUCCEEDED   PaymentState = 2
-	PaymentState_PAYMENT_STATE_FAILED      PaymentState = 3
+	PaymentState_PAYMENT_STATE_UNSPECIFIED        PaymentState = 0
+	PaymentState_PAYMENT_STATE_PENDING            PaymentState = 1
+	PaymentState_PAYMENT_STATE_SUCCEEDED          PaymentState = 2
+	PaymentState_PAYMENT_STATE_FAILED             PaymentState = 3
+	PaymentState_PAYMENT_STATE_REFUNDED           PaymentState = 4 // se devolvió todo lo cobrado
+	PaymentState_PAYMENT_STATE_PARTIALLY_REFUNDED PaymentState = 5
+	PaymentState_PAYMENT_STATE_AWAITING_DELIVERY  PaymentState = 6 // contra entrega: se cobra al entregar la orden
+	PaymentState_PAYMENT_STATE_UNDER_REVIEW       PaymentState = 7 // retenido por riesgo hasta la revisión manual
 )
 
 // Enum value maps for PaymentState.
@@ -38,12 +43,20 @@ var (
 		1: "PAYMENT_STATE_PENDING",
 		2: "PAYMENT_STATE_SUCCEEDED",
 		3: "PAYMENT_STATE_FAILED",
+		4: "PAYMENT_STATE_REFUNDED",
+		5: "PAYMENT_STATE_PARTIALLY_REFUNDED",
+		6: "PAYMENT_STATE_AWAITING_DELIVERY",
+		7: "PAYMENT_STATE_UNDER_REVIEW",
 	}
 	PaymentState_value = map[string]int32{
-		"PAYMENT_STATE_UNSPECIFIED": 0,
-		"PAYMENT_STATE_PENDING":     1,
-		"PAYMENT_STATE_SUCCEEDED":   2,
-		"PAYMENT_STATE_FAILED":      3,
+		"PAYMENT_STATE_UNSPECIFIED":        0,
+		"PAYMENT_STATE_PENDING":            1,
+		"PAYMENT_STATE_SUCCEEDED":          2,
+		"PAYMENT_STATE_FAILED":             3,
+		"PAYMENT_STATE_REFUNDED":           4,
+		"PAYMENT_STATE_PARTIALLY_REFUNDED": 5,
+		"PAYMENT_STATE_AWAITING_DELIVERY":  6,
+		"PAYMENT_STATE_UNDER_REVIEW":       7,
 	}
 )
 
@@ -74,6 +87,372 @@ func (PaymentState) EnumDescriptor() ([]byte, []int) {
 	return file_payment_proto_rawDescGZIP(), []int{0}
 }
 
+type TransactionKind int32
+
+const (
+	TransactionKind_TRANSACTION_KIND_UNSPECIFIED    TransactionKind = 0
+	TransactionKind_TRANSACTION_KIND_CHARGE         TransactionKind = 1
+	TransactionKind_TRANSACTION_KIND_REFUND         TransactionKind = 2 // devuelve el saldo restante completo
+	TransactionKind_TRANSACTION_KIND_PARTIAL_REFUND TransactionKind = 3
+)
+
+// Enum value maps for TransactionKind.
+var (
+	TransactionKind_name = map[int32]string{
+		0: "TRANSACTION_KIND_UNSPECIFIED",
+		1: "TRANSACTION_KIND_CHARGE",
+		2: "TRANSACTION_KIND_REFUND",
+		3: "TRANSACTION_KIND_PARTIAL_REFUND",
+	}
+	TransactionKind_value = map[string]int32{
+		"TRANSACTION_KIND_UNSPECIFIED":    0,
+		"TRANSACTION_KIND_CHARGE":         1,
+		"TRANSACTION_KIND_REFUND":         2,
+		"TRANSACTION_KIND_PARTIAL_REFUND": 3,
+	}
+)
+
+func (x TransactionKind) Enum() *TransactionKind {
+	p := new(TransactionKind)
+	*p = x
+	return p
+}
+
+func (x TransactionKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TransactionKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_proto_enumTypes[1].Descriptor()
+}
+
+func (TransactionKind) Type() protoreflect.EnumType {
+	return &file_payment_proto_enumTypes[1]
+}
+
+func (x TransactionKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TransactionKind.Descriptor instead.
+func (TransactionKind) EnumDescriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{1}
+}
+
+type DiscrepancyKind int32
+
+const (
+	DiscrepancyKind_DISCREPANCY_KIND_UNSPECIFIED     DiscrepancyKind = 0
+	DiscrepancyKind_DISCREPANCY_KIND_MISSING         DiscrepancyKind = 1 // registrado aquí, la pasarela no lo liquidó
+	DiscrepancyKind_DISCREPANCY_KIND_EXTRA           DiscrepancyKind = 2 // liquidado por la pasarela, sin registro aquí
+	DiscrepancyKind_DISCREPANCY_KIND_AMOUNT_MISMATCH DiscrepancyKind = 3
+	DiscrepancyKind_DISCREPANCY_KIND_REFUND_FAILED   DiscrepancyKind = 4 // cobrado y sin poder devolverse: lo devuelve una persona
+)
+
+// Enum value maps for DiscrepancyKind.
+var (
+	DiscrepancyKind_name = map[int32]string{
+		0: "DISCREPANCY_KIND_UNSPECIFIED",
+		1: "DISCREPANCY_KIND_MISSING",
+		2: "DISCREPANCY_KIND_EXTRA",
+		3: "DISCREPANCY_KIND_AMOUNT_MISMATCH",
+		4: "DISCREPANCY_KIND_REFUND_FAILED",
+	}
+	DiscrepancyKind_value = map[string]int32{
+		"DISCREPANCY_KIND_UNSPECIFIED":     0,
+		"DISCREPANCY_KIND_MISSING":         1,
+		"DISCREPANCY_KIND_EXTRA":           2,
+		"DISCREPANCY_KIND_AMOUNT_MISMATCH": 3,
+		"DISCREPANCY_KIND_REFUND_FAILED":   4,
+	}
+)
+
+func (x DiscrepancyKind) Enum() *DiscrepancyKind {
+	p := new(DiscrepancyKind)
+	*p = x
+	return p
+}
+
+func (x DiscrepancyKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DiscrepancyKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_proto_enumTypes[2].Descriptor()
+}
+
+func (DiscrepancyKind) Type() protoreflect.EnumType {
+	return &file_payment_proto_enumTypes[2]
+}
+
+func (x DiscrepancyKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DiscrepancyKind.Descriptor instead.
+func (DiscrepancyKind) EnumDescriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{2}
+}
+
+type WalletTransactionKind int32
+
+const (
+	WalletTransactionKind_WALLET_TRANSACTION_KIND_UNSPECIFIED WalletTransactionKind = 0
+	WalletTransactionKind_WALLET_TRANSACTION_KIND_GIFT_CARD   WalletTransactionKind = 1 // redención de tarjeta de regalo
+	WalletTransactionKind_WALLET_TRANSACTION_KIND_REFUND      WalletTransactionKind = 2 // reembolso como saldo a favor
+	WalletTransactionKind_WALLET_TRANSACTION_KIND_PAYMENT     WalletTransactionKind = 3 // pago de una orden (débito)
+	WalletTransactionKind_WALLET_TRANSACTION_KIND_RELEASE     WalletTransactionKind = 4 // devolución de un débito cuyo pago falló
+)
+
+// Enum value maps for WalletTransactionKind.
+var (
+	WalletTransactionKind_name = map[int32]string{
+		0: "WALLET_TRANSACTION_KIND_UNSPECIFIED",
+		1: "WALLET_TRANSACTION_KIND_GIFT_CARD",
+		2: "WALLET_TRANSACTION_KIND_REFUND",
+		3: "WALLET_TRANSACTION_KIND_PAYMENT",
+		4: "WALLET_TRANSACTION_KIND_RELEASE",
+	}
+	WalletTransactionKind_value = map[string]int32{
+		"WALLET_TRANSACTION_KIND_UNSPECIFIED": 0,
+		"WALLET_TRANSACTION_KIND_GIFT_CARD":   1,
+		"WALLET_TRANSACTION_KIND_REFUND":      2,
+		"WALLET_TRANSACTION_KIND_PAYMENT":     3,
+		"WALLET_TRANSACTION_KIND_RELEASE":     4,
+	}
+)
+
+func (x WalletTransactionKind) Enum() *WalletTransactionKind {
+	p := new(WalletTransactionKind)
+	*p = x
+	return p
+}
+
+func (x WalletTransactionKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WalletTransactionKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_proto_enumTypes[3].Descriptor()
+}
+
+func (WalletTransactionKind) Type() protoreflect.EnumType {
+	return &file_payment_proto_enumTypes[3]
+}
+
+func (x WalletTransactionKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WalletTransactionKind.Descriptor instead.
+func (WalletTransactionKind) EnumDescriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{3}
+}
+
+type RiskDecision int32
+
+const (
+	RiskDecision_RISK_DECISION_UNSPECIFIED RiskDecision = 0
+	RiskDecision_RISK_DECISION_APPROVE     RiskDecision = 1
+	RiskDecision_RISK_DECISION_REVIEW      RiskDecision = 2
+	RiskDecision_RISK_DECISION_DECLINE     RiskDecision = 3
+)
+
+// Enum value maps for RiskDecision.
+var (
+	RiskDecision_name = map[int32]string{
+		0: "RISK_DECISION_UNSPECIFIED",
+		1: "RISK_DECISION_APPROVE",
+		2: "RISK_DECISION_REVIEW",
+		3: "RISK_DECISION_DECLINE",
+	}
+	RiskDecision_value = map[string]int32{
+		"RISK_DECISION_UNSPECIFIED": 0,
+		"RISK_DECISION_APPROVE":     1,
+		"RISK_DECISION_REVIEW":      2,
+		"RISK_DECISION_DECLINE":     3,
+	}
+)
+
+func (x RiskDecision) Enum() *RiskDecision {
+	p := new(RiskDecision)
+	*p = x
+	return p
+}
+
+func (x RiskDecision) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RiskDecision) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_proto_enumTypes[4].Descriptor()
+}
+
+func (RiskDecision) Type() protoreflect.EnumType {
+	return &file_payment_proto_enumTypes[4]
+}
+
+func (x RiskDecision) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RiskDecision.Descriptor instead.
+func (RiskDecision) EnumDescriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{4}
+}
+
+type ReviewState int32
+
+const (
+	ReviewState_REVIEW_STATE_UNSPECIFIED ReviewState = 0 // la decisión no requería revisión
+	ReviewState_REVIEW_STATE_PENDING     ReviewState = 1
+	ReviewState_REVIEW_STATE_APPROVED    ReviewState = 2
+	ReviewState_REVIEW_STATE_REJECTED    ReviewState = 3
+)
+
+// Enum value maps for ReviewState.
+var (
+	ReviewState_name = map[int32]string{
+		0: "REVIEW_STATE_UNSPECIFIED",
+		1: "REVIEW_STATE_PENDING",
+		2: "REVIEW_STATE_APPROVED",
+		3: "REVIEW_STATE_REJECTED",
+	}
+	ReviewState_value = map[string]int32{
+		"REVIEW_STATE_UNSPECIFIED": 0,
+		"REVIEW_STATE_PENDING":     1,
+		"REVIEW_STATE_APPROVED":    2,
+		"REVIEW_STATE_REJECTED":    3,
+	}
+)
+
+func (x ReviewState) Enum() *ReviewState {
+	p := new(ReviewState)
+	*p = x
+	return p
+}
+
+func (x ReviewState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReviewState) Descriptor() protoreflect.EnumDescriptor {
+	return file_payment_proto_enumTypes[5].Descriptor()
+}
+
+func (ReviewState) Type() protoreflect.EnumType {
+	return &file_payment_proto_enumTypes[5]
+}
+
+func (x ReviewState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReviewState.Descriptor instead.
+func (ReviewState) EnumDescriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{5}
+}
+
+type Transaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId int64                  `protobuf:"varint,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	OrderId       int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Kind          TransactionKind        `protobuf:"varint,3,opt,name=kind,proto3,enum=payment.TransactionKind" json:"kind,omitempty"`
+	Amount        *common.Money          `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	ProviderRef   string                 `protobuf:"bytes,5,opt,name=provider_ref,json=providerRef,proto3" json:"provider_ref,omitempty"`
+	Reason        string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,7,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	StoreCredit   bool                   `protobuf:"varint,8,opt,name=store_credit,json=storeCredit,proto3" json:"store_credit,omitempty"` // cobrado del monedero o devuelto como saldo a favor
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_payment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Transaction) GetTransactionId() int64 {
+	if x != nil {
+		return x.TransactionId
+	}
+	return 0
+}
+
+func (x *Transaction) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Transaction) GetKind() TransactionKind {
+	if x != nil {
+		return x.Kind
+	}
+	return TransactionKind_TRANSACTION_KIND_UNSPECIFIED
+}
+
+func (x *Transaction) GetAmount() *common.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *Transaction) GetProviderRef() string {
+	if x != nil {
+		return x.ProviderRef
+	}
+	return ""
+}
+
+func (x *Transaction) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Transaction) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *Transaction) GetStoreCredit() bool {
+	if x != nil {
+		return x.StoreCredit
+	}
+	return false
+}
+
 type GetPaymentStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
@@ -83,7 +462,7 @@ type GetPaymentStatusRequest struct {
 
 func (x *GetPaymentStatusRequest) Reset() {
 	*x = GetPaymentStatusRequest{}
-	mi := &file_payment_proto_msgTypes[0]
+	mi := &file_payment_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -95,7 +474,7 @@ func (x *GetPaymentStatusRequest) String() string {
 func (*GetPaymentStatusRequest) ProtoMessage() {}
 
 func (x *GetPaymentStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[0]
+	mi := &file_payment_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -108,7 +487,7 @@ func (x *GetPaymentStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPaymentStatusRequest.ProtoReflect.Descriptor instead.
 func (*GetPaymentStatusRequest) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{0}
+	return file_payment_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *GetPaymentStatusRequest) GetOrderId() int64 {
@@ -124,13 +503,17 @@ type GetPaymentStatusResponse struct {
 	State         PaymentState           `protobuf:"varint,2,opt,name=state,proto3,enum=payment.PaymentState" json:"state,omitempty"`
 	ProviderRef   string                 `protobuf:"bytes,3,opt,name=provider_ref,json=providerRef,proto3" json:"provider_ref,omitempty"` // id de pago simulado
 	UpdatedUnix   int64                  `protobuf:"varint,4,opt,name=updated_unix,json=updatedUnix,proto3" json:"updated_unix,omitempty"`
+	RedirectUrl   string                 `protobuf:"bytes,5,opt,name=redirect_url,json=redirectUrl,proto3" json:"redirect_url,omitempty"` // página del banco mientras el pago está PENDING
+	Method        common.PaymentMethod   `protobuf:"varint,6,opt,name=method,proto3,enum=common.PaymentMethod" json:"method,omitempty"`
+	Instrument    *Instrument            `protobuf:"bytes,7,opt,name=instrument,proto3" json:"instrument,omitempty"`                         // tarjeta usada (PAYMENT_METHOD_CARD)
+	WalletAmount  *common.Money          `protobuf:"bytes,8,opt,name=wallet_amount,json=walletAmount,proto3" json:"wallet_amount,omitempty"` // parte cobrada del monedero
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetPaymentStatusResponse) Reset() {
 	*x = GetPaymentStatusResponse{}
-	mi := &file_payment_proto_msgTypes[1]
+	mi := &file_payment_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -142,7 +525,7 @@ func (x *GetPaymentStatusResponse) String() string {
 func (*GetPaymentStatusResponse) ProtoMessage() {}
 
 func (x *GetPaymentStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_payment_proto_msgTypes[1]
+	mi := &file_payment_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -155,7 +538,7 @@ func (x *GetPaymentStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetPaymentStatusResponse.ProtoReflect.Descriptor instead.
 func (*GetPaymentStatusResponse) Descriptor() ([]byte, []int) {
-	return file_payment_proto_rawDescGZIP(), []int{1}
+	return file_payment_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *GetPaymentStatusResponse) GetOrderId() int64 {
@@ -186,25 +569,1984 @@ func (x *GetPaymentStatusResponse) GetUpdatedUnix() int64 {
 	return 0
 }
 
-var File_payment_proto protoreflect.FileDescriptor
+func (x *GetPaymentStatusResponse) GetRedirectUrl() string {
+	if x != nil {
+		return x.RedirectUrl
+	}
+	return ""
+}
 
-const file_payment_proto_rawDesc = "" +
-	"\n" +
-	"\rpayment.proto\x12\apayment\x1a\fcommon.proto\"4\n" +
-	"\x17GetPaymentStatusRequest\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\x03R\aorderId\"\xa8\x01\n" +
-	"\x18GetPaymentStatusResponse\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\x03R\aorderId\x12+\n" +
-	"\x05state\x18\x02 \x01(\x0e2\x15.payment.PaymentStateR\x05state\x12!\n" +
-	"\fprovider_ref\x18\x03 \x01(\tR\vproviderRef\x12!\n" +
-	"\fupdated_unix\x18\x04 \x01(\x03R\vupdatedUnix*\x7f\n" +
-	"\fPaymentState\x12\x1d\n" +
-	"\x19PAYMENT_STATE_UNSPECIFIED\x10\x00\x12\x19\n" +
-	"\x15PAYMENT_STATE_PENDING\x10\x01\x12\x1b\n" +
-	"\x17PAYMENT_STATE_SUCCEEDED\x10\x02\x12\x18\n" +
-	"\x14PAYMENT_STATE_FAILED\x10\x032b\n" +
-	"\aPayment\x12W\n" +
-	"\x10GetPaymentStatus\x12 .payment.GetPaymentStatusRequest\x1a!.payment.GetPaymentStatusResponseB?Z=github.com/ahinestrog/mybookstore/proto/gen/payment;paymentpbb\x06proto3"
+func (x *GetPaymentStatusResponse) GetMethod() common.PaymentMethod {
+	if x != nil {
+		return x.Method
+	}
+	return common.PaymentMethod(0)
+}
+
+func (x *GetPaymentStatusResponse) GetInstrument() *Instrument {
+	if x != nil {
+		return x.Instrument
+	}
+	return nil
+}
+
+func (x *GetPaymentStatusResponse) GetWalletAmount() *common.Money {
+	if x != nil {
+		return x.WalletAmount
+	}
+	return nil
+}
+
+type RefundPaymentRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OrderId        int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Amount         *common.Money          `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"` // vacío o 0 = reembolsar todo el saldo
+	Reason         string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,4,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	ToStoreCredit  bool                   `protobuf:"varint,5,opt,name=to_store_credit,json=toStoreCredit,proto3" json:"to_store_credit,omitempty"` // devolver como saldo a favor en vez de por la pasarela
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RefundPaymentRequest) Reset() {
+	*x = RefundPaymentRequest{}
+	mi := &file_payment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentRequest) ProtoMessage() {}
+
+func (x *RefundPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentRequest.ProtoReflect.Descriptor instead.
+func (*RefundPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RefundPaymentRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *RefundPaymentRequest) GetAmount() *common.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *RefundPaymentRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *RefundPaymentRequest) GetToStoreCredit() bool {
+	if x != nil {
+		return x.ToStoreCredit
+	}
+	return false
+}
+
+type RefundPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *Transaction           `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`                // reembolso por la pasarela (vacío si todo fue a saldo a favor)
+	State         PaymentState           `protobuf:"varint,2,opt,name=state,proto3,enum=payment.PaymentState" json:"state,omitempty"` // estado del pago después del reembolso
+	RefundedTotal *common.Money          `protobuf:"bytes,3,opt,name=refunded_total,json=refundedTotal,proto3" json:"refunded_total,omitempty"`
+	StoreCredit   *Transaction           `protobuf:"bytes,4,opt,name=store_credit,json=storeCredit,proto3" json:"store_credit,omitempty"` // parte devuelta al monedero
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundPaymentResponse) Reset() {
+	*x = RefundPaymentResponse{}
+	mi := &file_payment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundPaymentResponse) ProtoMessage() {}
+
+func (x *RefundPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundPaymentResponse.ProtoReflect.Descriptor instead.
+func (*RefundPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RefundPaymentResponse) GetTransaction() *Transaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *RefundPaymentResponse) GetState() PaymentState {
+	if x != nil {
+		return x.State
+	}
+	return PaymentState_PAYMENT_STATE_UNSPECIFIED
+}
+
+func (x *RefundPaymentResponse) GetRefundedTotal() *common.Money {
+	if x != nil {
+		return x.RefundedTotal
+	}
+	return nil
+}
+
+func (x *RefundPaymentResponse) GetStoreCredit() *Transaction {
+	if x != nil {
+		return x.StoreCredit
+	}
+	return nil
+}
+
+type ListTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_payment_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListTransactionsRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type ListTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsResponse) Reset() {
+	*x = ListTransactionsResponse{}
+	mi := &file_payment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsResponse) ProtoMessage() {}
+
+func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type SettlementRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         int64                  `protobuf:"varint,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Source        string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`                           // nombre del archivo
+	PeriodFrom    string                 `protobuf:"bytes,3,opt,name=period_from,json=periodFrom,proto3" json:"period_from,omitempty"` // YYYY-MM-DD
+	PeriodTo      string                 `protobuf:"bytes,4,opt,name=period_to,json=periodTo,proto3" json:"period_to,omitempty"`
+	Lines         int32                  `protobuf:"varint,5,opt,name=lines,proto3" json:"lines,omitempty"`
+	Matched       int32                  `protobuf:"varint,6,opt,name=matched,proto3" json:"matched,omitempty"`
+	Discrepancies int32                  `protobuf:"varint,7,opt,name=discrepancies,proto3" json:"discrepancies,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,8,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	Duplicate     bool                   `protobuf:"varint,9,opt,name=duplicate,proto3" json:"duplicate,omitempty"` // el archivo ya se había importado
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SettlementRun) Reset() {
+	*x = SettlementRun{}
+	mi := &file_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SettlementRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SettlementRun) ProtoMessage() {}
+
+func (x *SettlementRun) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SettlementRun.ProtoReflect.Descriptor instead.
+func (*SettlementRun) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SettlementRun) GetRunId() int64 {
+	if x != nil {
+		return x.RunId
+	}
+	return 0
+}
+
+func (x *SettlementRun) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SettlementRun) GetPeriodFrom() string {
+	if x != nil {
+		return x.PeriodFrom
+	}
+	return ""
+}
+
+func (x *SettlementRun) GetPeriodTo() string {
+	if x != nil {
+		return x.PeriodTo
+	}
+	return ""
+}
+
+func (x *SettlementRun) GetLines() int32 {
+	if x != nil {
+		return x.Lines
+	}
+	return 0
+}
+
+func (x *SettlementRun) GetMatched() int32 {
+	if x != nil {
+		return x.Matched
+	}
+	return 0
+}
+
+func (x *SettlementRun) GetDiscrepancies() int32 {
+	if x != nil {
+		return x.Discrepancies
+	}
+	return 0
+}
+
+func (x *SettlementRun) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *SettlementRun) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+type ImportSettlementRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Csv           []byte                 `protobuf:"bytes,2,opt,name=csv,proto3" json:"csv,omitempty"`                                 // date,provider_ref,type,amount_cents
+	PeriodFrom    string                 `protobuf:"bytes,3,opt,name=period_from,json=periodFrom,proto3" json:"period_from,omitempty"` // vacío = fecha mínima del archivo
+	PeriodTo      string                 `protobuf:"bytes,4,opt,name=period_to,json=periodTo,proto3" json:"period_to,omitempty"`       // vacío = fecha máxima del archivo
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportSettlementRequest) Reset() {
+	*x = ImportSettlementRequest{}
+	mi := &file_payment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportSettlementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportSettlementRequest) ProtoMessage() {}
+
+func (x *ImportSettlementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportSettlementRequest.ProtoReflect.Descriptor instead.
+func (*ImportSettlementRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ImportSettlementRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ImportSettlementRequest) GetCsv() []byte {
+	if x != nil {
+		return x.Csv
+	}
+	return nil
+}
+
+func (x *ImportSettlementRequest) GetPeriodFrom() string {
+	if x != nil {
+		return x.PeriodFrom
+	}
+	return ""
+}
+
+func (x *ImportSettlementRequest) GetPeriodTo() string {
+	if x != nil {
+		return x.PeriodTo
+	}
+	return ""
+}
+
+type Discrepancy struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	DiscrepancyId   int64                  `protobuf:"varint,1,opt,name=discrepancy_id,json=discrepancyId,proto3" json:"discrepancy_id,omitempty"`
+	RunId           int64                  `protobuf:"varint,2,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"` // 0 en REFUND_FAILED: no sale de una liquidación
+	Kind            DiscrepancyKind        `protobuf:"varint,3,opt,name=kind,proto3,enum=payment.DiscrepancyKind" json:"kind,omitempty"`
+	Date            string                 `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	ProviderRef     string                 `protobuf:"bytes,5,opt,name=provider_ref,json=providerRef,proto3" json:"provider_ref,omitempty"`
+	OrderId         int64                  `protobuf:"varint,6,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	TransactionKind TransactionKind        `protobuf:"varint,7,opt,name=transaction_kind,json=transactionKind,proto3,enum=payment.TransactionKind" json:"transaction_kind,omitempty"`
+	Recorded        *common.Money          `protobuf:"bytes,8,opt,name=recorded,proto3" json:"recorded,omitempty"`
+	Settled         *common.Money          `protobuf:"bytes,9,opt,name=settled,proto3" json:"settled,omitempty"`
+	Note            string                 `protobuf:"bytes,10,opt,name=note,proto3" json:"note,omitempty"`
+	Resolved        bool                   `protobuf:"varint,11,opt,name=resolved,proto3" json:"resolved,omitempty"` // una liquidación o un reembolso posterior lo cubrió
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Discrepancy) Reset() {
+	*x = Discrepancy{}
+	mi := &file_payment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Discrepancy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Discrepancy) ProtoMessage() {}
+
+func (x *Discrepancy) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Discrepancy.ProtoReflect.Descriptor instead.
+func (*Discrepancy) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Discrepancy) GetDiscrepancyId() int64 {
+	if x != nil {
+		return x.DiscrepancyId
+	}
+	return 0
+}
+
+func (x *Discrepancy) GetRunId() int64 {
+	if x != nil {
+		return x.RunId
+	}
+	return 0
+}
+
+func (x *Discrepancy) GetKind() DiscrepancyKind {
+	if x != nil {
+		return x.Kind
+	}
+	return DiscrepancyKind_DISCREPANCY_KIND_UNSPECIFIED
+}
+
+func (x *Discrepancy) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Discrepancy) GetProviderRef() string {
+	if x != nil {
+		return x.ProviderRef
+	}
+	return ""
+}
+
+func (x *Discrepancy) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Discrepancy) GetTransactionKind() TransactionKind {
+	if x != nil {
+		return x.TransactionKind
+	}
+	return TransactionKind_TRANSACTION_KIND_UNSPECIFIED
+}
+
+func (x *Discrepancy) GetRecorded() *common.Money {
+	if x != nil {
+		return x.Recorded
+	}
+	return nil
+}
+
+func (x *Discrepancy) GetSettled() *common.Money {
+	if x != nil {
+		return x.Settled
+	}
+	return nil
+}
+
+func (x *Discrepancy) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *Discrepancy) GetResolved() bool {
+	if x != nil {
+		return x.Resolved
+	}
+	return false
+}
+
+type ReconciliationReportRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FromDate        string                 `protobuf:"bytes,1,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"` // YYYY-MM-DD, inclusive
+	ToDate          string                 `protobuf:"bytes,2,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`
+	IncludeResolved bool                   `protobuf:"varint,3,opt,name=include_resolved,json=includeResolved,proto3" json:"include_resolved,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ReconciliationReportRequest) Reset() {
+	*x = ReconciliationReportRequest{}
+	mi := &file_payment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconciliationReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconciliationReportRequest) ProtoMessage() {}
+
+func (x *ReconciliationReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconciliationReportRequest.ProtoReflect.Descriptor instead.
+func (*ReconciliationReportRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ReconciliationReportRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *ReconciliationReportRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *ReconciliationReportRequest) GetIncludeResolved() bool {
+	if x != nil {
+		return x.IncludeResolved
+	}
+	return false
+}
+
+type ReconciliationReport struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Discrepancies  []*Discrepancy         `protobuf:"bytes,1,rep,name=discrepancies,proto3" json:"discrepancies,omitempty"`
+	Missing        int32                  `protobuf:"varint,2,opt,name=missing,proto3" json:"missing,omitempty"`
+	Extra          int32                  `protobuf:"varint,3,opt,name=extra,proto3" json:"extra,omitempty"`
+	AmountMismatch int32                  `protobuf:"varint,4,opt,name=amount_mismatch,json=amountMismatch,proto3" json:"amount_mismatch,omitempty"`
+	Runs           []*SettlementRun       `protobuf:"bytes,5,rep,name=runs,proto3" json:"runs,omitempty"`
+	RefundFailed   int32                  `protobuf:"varint,6,opt,name=refund_failed,json=refundFailed,proto3" json:"refund_failed,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReconciliationReport) Reset() {
+	*x = ReconciliationReport{}
+	mi := &file_payment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconciliationReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconciliationReport) ProtoMessage() {}
+
+func (x *ReconciliationReport) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconciliationReport.ProtoReflect.Descriptor instead.
+func (*ReconciliationReport) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ReconciliationReport) GetDiscrepancies() []*Discrepancy {
+	if x != nil {
+		return x.Discrepancies
+	}
+	return nil
+}
+
+func (x *ReconciliationReport) GetMissing() int32 {
+	if x != nil {
+		return x.Missing
+	}
+	return 0
+}
+
+func (x *ReconciliationReport) GetExtra() int32 {
+	if x != nil {
+		return x.Extra
+	}
+	return 0
+}
+
+func (x *ReconciliationReport) GetAmountMismatch() int32 {
+	if x != nil {
+		return x.AmountMismatch
+	}
+	return 0
+}
+
+func (x *ReconciliationReport) GetRuns() []*SettlementRun {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+func (x *ReconciliationReport) GetRefundFailed() int32 {
+	if x != nil {
+		return x.RefundFailed
+	}
+	return 0
+}
+
+// Tarjeta tokenizada por la pasarela. El número completo nunca llega a Payment.
+type Instrument struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	InstrumentId  int64                  `protobuf:"varint,1,opt,name=instrument_id,json=instrumentId,proto3" json:"instrument_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Brand         string                 `protobuf:"bytes,3,opt,name=brand,proto3" json:"brand,omitempty"` // visa, mastercard, amex...
+	Last4         string                 `protobuf:"bytes,4,opt,name=last4,proto3" json:"last4,omitempty"`
+	ExpMonth      int32                  `protobuf:"varint,5,opt,name=exp_month,json=expMonth,proto3" json:"exp_month,omitempty"`
+	ExpYear       int32                  `protobuf:"varint,6,opt,name=exp_year,json=expYear,proto3" json:"exp_year,omitempty"`
+	Label         string                 `protobuf:"bytes,7,opt,name=label,proto3" json:"label,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,8,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Instrument) Reset() {
+	*x = Instrument{}
+	mi := &file_payment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Instrument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Instrument) ProtoMessage() {}
+
+func (x *Instrument) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Instrument.ProtoReflect.Descriptor instead.
+func (*Instrument) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Instrument) GetInstrumentId() int64 {
+	if x != nil {
+		return x.InstrumentId
+	}
+	return 0
+}
+
+func (x *Instrument) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Instrument) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *Instrument) GetLast4() string {
+	if x != nil {
+		return x.Last4
+	}
+	return ""
+}
+
+func (x *Instrument) GetExpMonth() int32 {
+	if x != nil {
+		return x.ExpMonth
+	}
+	return 0
+}
+
+func (x *Instrument) GetExpYear() int32 {
+	if x != nil {
+		return x.ExpYear
+	}
+	return 0
+}
+
+func (x *Instrument) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Instrument) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+type SaveInstrumentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"` // emitido por el tokenizador de la pasarela
+	Brand         string                 `protobuf:"bytes,3,opt,name=brand,proto3" json:"brand,omitempty"`
+	Last4         string                 `protobuf:"bytes,4,opt,name=last4,proto3" json:"last4,omitempty"`
+	ExpMonth      int32                  `protobuf:"varint,5,opt,name=exp_month,json=expMonth,proto3" json:"exp_month,omitempty"`
+	ExpYear       int32                  `protobuf:"varint,6,opt,name=exp_year,json=expYear,proto3" json:"exp_year,omitempty"`
+	Label         string                 `protobuf:"bytes,7,opt,name=label,proto3" json:"label,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveInstrumentRequest) Reset() {
+	*x = SaveInstrumentRequest{}
+	mi := &file_payment_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveInstrumentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveInstrumentRequest) ProtoMessage() {}
+
+func (x *SaveInstrumentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveInstrumentRequest.ProtoReflect.Descriptor instead.
+func (*SaveInstrumentRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SaveInstrumentRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SaveInstrumentRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *SaveInstrumentRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *SaveInstrumentRequest) GetLast4() string {
+	if x != nil {
+		return x.Last4
+	}
+	return ""
+}
+
+func (x *SaveInstrumentRequest) GetExpMonth() int32 {
+	if x != nil {
+		return x.ExpMonth
+	}
+	return 0
+}
+
+func (x *SaveInstrumentRequest) GetExpYear() int32 {
+	if x != nil {
+		return x.ExpYear
+	}
+	return 0
+}
+
+func (x *SaveInstrumentRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+type ListInstrumentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Instruments   []*Instrument          `protobuf:"bytes,1,rep,name=instruments,proto3" json:"instruments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInstrumentsResponse) Reset() {
+	*x = ListInstrumentsResponse{}
+	mi := &file_payment_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInstrumentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInstrumentsResponse) ProtoMessage() {}
+
+func (x *ListInstrumentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInstrumentsResponse.ProtoReflect.Descriptor instead.
+func (*ListInstrumentsResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListInstrumentsResponse) GetInstruments() []*Instrument {
+	if x != nil {
+		return x.Instruments
+	}
+	return nil
+}
+
+type InstrumentRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // debe ser el dueño
+	InstrumentId  int64                  `protobuf:"varint,2,opt,name=instrument_id,json=instrumentId,proto3" json:"instrument_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstrumentRef) Reset() {
+	*x = InstrumentRef{}
+	mi := &file_payment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstrumentRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstrumentRef) ProtoMessage() {}
+
+func (x *InstrumentRef) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstrumentRef.ProtoReflect.Descriptor instead.
+func (*InstrumentRef) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *InstrumentRef) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *InstrumentRef) GetInstrumentId() int64 {
+	if x != nil {
+		return x.InstrumentId
+	}
+	return 0
+}
+
+// Movimiento del monedero. amount es positivo para créditos y negativo para débitos.
+type WalletTransaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId int64                  `protobuf:"varint,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Kind          WalletTransactionKind  `protobuf:"varint,3,opt,name=kind,proto3,enum=payment.WalletTransactionKind" json:"kind,omitempty"`
+	Amount        *common.Money          `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	BalanceAfter  *common.Money          `protobuf:"bytes,5,opt,name=balance_after,json=balanceAfter,proto3" json:"balance_after,omitempty"`
+	OrderId       int64                  `protobuf:"varint,6,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Reference     string                 `protobuf:"bytes,7,opt,name=reference,proto3" json:"reference,omitempty"` // código de la tarjeta de regalo (enmascarado) u orden
+	CreatedUnix   int64                  `protobuf:"varint,8,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletTransaction) Reset() {
+	*x = WalletTransaction{}
+	mi := &file_payment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletTransaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletTransaction) ProtoMessage() {}
+
+func (x *WalletTransaction) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletTransaction.ProtoReflect.Descriptor instead.
+func (*WalletTransaction) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WalletTransaction) GetTransactionId() int64 {
+	if x != nil {
+		return x.TransactionId
+	}
+	return 0
+}
+
+func (x *WalletTransaction) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *WalletTransaction) GetKind() WalletTransactionKind {
+	if x != nil {
+		return x.Kind
+	}
+	return WalletTransactionKind_WALLET_TRANSACTION_KIND_UNSPECIFIED
+}
+
+func (x *WalletTransaction) GetAmount() *common.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *WalletTransaction) GetBalanceAfter() *common.Money {
+	if x != nil {
+		return x.BalanceAfter
+	}
+	return nil
+}
+
+func (x *WalletTransaction) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *WalletTransaction) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *WalletTransaction) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+type WalletBalance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Balance       *common.Money          `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalletBalance) Reset() {
+	*x = WalletBalance{}
+	mi := &file_payment_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalletBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalletBalance) ProtoMessage() {}
+
+func (x *WalletBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalletBalance.ProtoReflect.Descriptor instead.
+func (*WalletBalance) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WalletBalance) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *WalletBalance) GetBalance() *common.Money {
+	if x != nil {
+		return x.Balance
+	}
+	return nil
+}
+
+type RedeemGiftCardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeemGiftCardRequest) Reset() {
+	*x = RedeemGiftCardRequest{}
+	mi := &file_payment_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeemGiftCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeemGiftCardRequest) ProtoMessage() {}
+
+func (x *RedeemGiftCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeemGiftCardRequest.ProtoReflect.Descriptor instead.
+func (*RedeemGiftCardRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RedeemGiftCardRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RedeemGiftCardRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type RedeemGiftCardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transaction   *WalletTransaction     `protobuf:"bytes,1,opt,name=transaction,proto3" json:"transaction,omitempty"`
+	Balance       *common.Money          `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedeemGiftCardResponse) Reset() {
+	*x = RedeemGiftCardResponse{}
+	mi := &file_payment_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedeemGiftCardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedeemGiftCardResponse) ProtoMessage() {}
+
+func (x *RedeemGiftCardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedeemGiftCardResponse.ProtoReflect.Descriptor instead.
+func (*RedeemGiftCardResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RedeemGiftCardResponse) GetTransaction() *WalletTransaction {
+	if x != nil {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (x *RedeemGiftCardResponse) GetBalance() *common.Money {
+	if x != nil {
+		return x.Balance
+	}
+	return nil
+}
+
+type ListWalletTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // 0 = 50
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWalletTransactionsRequest) Reset() {
+	*x = ListWalletTransactionsRequest{}
+	mi := &file_payment_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWalletTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWalletTransactionsRequest) ProtoMessage() {}
+
+func (x *ListWalletTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWalletTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListWalletTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListWalletTransactionsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ListWalletTransactionsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListWalletTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*WalletTransaction   `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	Balance       *common.Money          `protobuf:"bytes,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWalletTransactionsResponse) Reset() {
+	*x = ListWalletTransactionsResponse{}
+	mi := &file_payment_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWalletTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWalletTransactionsResponse) ProtoMessage() {}
+
+func (x *ListWalletTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWalletTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListWalletTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListWalletTransactionsResponse) GetTransactions() []*WalletTransaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+func (x *ListWalletTransactionsResponse) GetBalance() *common.Money {
+	if x != nil {
+		return x.Balance
+	}
+	return nil
+}
+
+type IssueGiftCardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        *common.Money          `protobuf:"bytes,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	ExpiresUnix   int64                  `protobuf:"varint,2,opt,name=expires_unix,json=expiresUnix,proto3" json:"expires_unix,omitempty"` // 0 = no vence
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueGiftCardRequest) Reset() {
+	*x = IssueGiftCardRequest{}
+	mi := &file_payment_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueGiftCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueGiftCardRequest) ProtoMessage() {}
+
+func (x *IssueGiftCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueGiftCardRequest.ProtoReflect.Descriptor instead.
+func (*IssueGiftCardRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *IssueGiftCardRequest) GetAmount() *common.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *IssueGiftCardRequest) GetExpiresUnix() int64 {
+	if x != nil {
+		return x.ExpiresUnix
+	}
+	return 0
+}
+
+type GiftCard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Amount        *common.Money          `protobuf:"bytes,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	ExpiresUnix   int64                  `protobuf:"varint,3,opt,name=expires_unix,json=expiresUnix,proto3" json:"expires_unix,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,4,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GiftCard) Reset() {
+	*x = GiftCard{}
+	mi := &file_payment_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GiftCard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GiftCard) ProtoMessage() {}
+
+func (x *GiftCard) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GiftCard.ProtoReflect.Descriptor instead.
+func (*GiftCard) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GiftCard) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *GiftCard) GetAmount() *common.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *GiftCard) GetExpiresUnix() int64 {
+	if x != nil {
+		return x.ExpiresUnix
+	}
+	return 0
+}
+
+func (x *GiftCard) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+// Resultado del análisis de riesgo de un pago antes del cobro.
+type RiskAssessment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AssessmentId  int64                  `protobuf:"varint,1,opt,name=assessment_id,json=assessmentId,proto3" json:"assessment_id,omitempty"`
+	OrderId       int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Amount        *common.Money          `protobuf:"bytes,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	Score         int32                  `protobuf:"varint,5,opt,name=score,proto3" json:"score,omitempty"`
+	Decision      RiskDecision           `protobuf:"varint,6,opt,name=decision,proto3,enum=payment.RiskDecision" json:"decision,omitempty"`
+	Reasons       []string               `protobuf:"bytes,7,rep,name=reasons,proto3" json:"reasons,omitempty"` // reglas que sumaron puntaje
+	ReviewState   ReviewState            `protobuf:"varint,8,opt,name=review_state,json=reviewState,proto3,enum=payment.ReviewState" json:"review_state,omitempty"`
+	Reviewer      string                 `protobuf:"bytes,9,opt,name=reviewer,proto3" json:"reviewer,omitempty"`
+	ReviewNote    string                 `protobuf:"bytes,10,opt,name=review_note,json=reviewNote,proto3" json:"review_note,omitempty"`
+	CreatedUnix   int64                  `protobuf:"varint,11,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	ReviewedUnix  int64                  `protobuf:"varint,12,opt,name=reviewed_unix,json=reviewedUnix,proto3" json:"reviewed_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RiskAssessment) Reset() {
+	*x = RiskAssessment{}
+	mi := &file_payment_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RiskAssessment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RiskAssessment) ProtoMessage() {}
+
+func (x *RiskAssessment) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RiskAssessment.ProtoReflect.Descriptor instead.
+func (*RiskAssessment) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RiskAssessment) GetAssessmentId() int64 {
+	if x != nil {
+		return x.AssessmentId
+	}
+	return 0
+}
+
+func (x *RiskAssessment) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *RiskAssessment) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *RiskAssessment) GetAmount() *common.Money {
+	if x != nil {
+		return x.Amount
+	}
+	return nil
+}
+
+func (x *RiskAssessment) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RiskAssessment) GetDecision() RiskDecision {
+	if x != nil {
+		return x.Decision
+	}
+	return RiskDecision_RISK_DECISION_UNSPECIFIED
+}
+
+func (x *RiskAssessment) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *RiskAssessment) GetReviewState() ReviewState {
+	if x != nil {
+		return x.ReviewState
+	}
+	return ReviewState_REVIEW_STATE_UNSPECIFIED
+}
+
+func (x *RiskAssessment) GetReviewer() string {
+	if x != nil {
+		return x.Reviewer
+	}
+	return ""
+}
+
+func (x *RiskAssessment) GetReviewNote() string {
+	if x != nil {
+		return x.ReviewNote
+	}
+	return ""
+}
+
+func (x *RiskAssessment) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *RiskAssessment) GetReviewedUnix() int64 {
+	if x != nil {
+		return x.ReviewedUnix
+	}
+	return 0
+}
+
+type ListRiskReviewsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	IncludeResolved bool                   `protobuf:"varint,1,opt,name=include_resolved,json=includeResolved,proto3" json:"include_resolved,omitempty"` // incluir las ya aprobadas o rechazadas
+	Limit           int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                            // 0 = 50
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListRiskReviewsRequest) Reset() {
+	*x = ListRiskReviewsRequest{}
+	mi := &file_payment_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRiskReviewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRiskReviewsRequest) ProtoMessage() {}
+
+func (x *ListRiskReviewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRiskReviewsRequest.ProtoReflect.Descriptor instead.
+func (*ListRiskReviewsRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListRiskReviewsRequest) GetIncludeResolved() bool {
+	if x != nil {
+		return x.IncludeResolved
+	}
+	return false
+}
+
+func (x *ListRiskReviewsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListRiskReviewsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reviews       []*RiskAssessment      `protobuf:"bytes,1,rep,name=reviews,proto3" json:"reviews,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRiskReviewsResponse) Reset() {
+	*x = ListRiskReviewsResponse{}
+	mi := &file_payment_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRiskReviewsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRiskReviewsResponse) ProtoMessage() {}
+
+func (x *ListRiskReviewsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRiskReviewsResponse.ProtoReflect.Descriptor instead.
+func (*ListRiskReviewsResponse) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListRiskReviewsResponse) GetReviews() []*RiskAssessment {
+	if x != nil {
+		return x.Reviews
+	}
+	return nil
+}
+
+type ReviewDecisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Reviewer      string                 `protobuf:"bytes,2,opt,name=reviewer,proto3" json:"reviewer,omitempty"`
+	Note          string                 `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewDecisionRequest) Reset() {
+	*x = ReviewDecisionRequest{}
+	mi := &file_payment_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewDecisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewDecisionRequest) ProtoMessage() {}
+
+func (x *ReviewDecisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_payment_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewDecisionRequest.ProtoReflect.Descriptor instead.
+func (*ReviewDecisionRequest) Descriptor() ([]byte, []int) {
+	return file_payment_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ReviewDecisionRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *ReviewDecisionRequest) GetReviewer() string {
+	if x != nil {
+		return x.Reviewer
+	}
+	return ""
+}
+
+func (x *ReviewDecisionRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+var File_payment_proto protoreflect.FileDescriptor
+
+const file_payment_proto_rawDesc = "" +
+	"\n" +
+	"\rpayment.proto\x12\apayment\x1a\fcommon.proto\x1a\x1cgoogle/api/annotations.proto\"\xa5\x02\n" +
+	"\vTransaction\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\x03R\rtransactionId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12,\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x18.payment.TransactionKindR\x04kind\x12%\n" +
+	"\x06amount\x18\x04 \x01(\v2\r.common.MoneyR\x06amount\x12!\n" +
+	"\fprovider_ref\x18\x05 \x01(\tR\vproviderRef\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason\x12!\n" +
+	"\fcreated_unix\x18\a \x01(\x03R\vcreatedUnix\x12!\n" +
+	"\fstore_credit\x18\b \x01(\bR\vstoreCredit\"4\n" +
+	"\x17GetPaymentStatusRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"\xe3\x02\n" +
+	"\x18GetPaymentStatusResponse\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12+\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x15.payment.PaymentStateR\x05state\x12!\n" +
+	"\fprovider_ref\x18\x03 \x01(\tR\vproviderRef\x12!\n" +
+	"\fupdated_unix\x18\x04 \x01(\x03R\vupdatedUnix\x12!\n" +
+	"\fredirect_url\x18\x05 \x01(\tR\vredirectUrl\x12-\n" +
+	"\x06method\x18\x06 \x01(\x0e2\x15.common.PaymentMethodR\x06method\x123\n" +
+	"\n" +
+	"instrument\x18\a \x01(\v2\x13.payment.InstrumentR\n" +
+	"instrument\x122\n" +
+	"\rwallet_amount\x18\b \x01(\v2\r.common.MoneyR\fwalletAmount\"\xc1\x01\n" +
+	"\x14RefundPaymentRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12%\n" +
+	"\x06amount\x18\x02 \x01(\v2\r.common.MoneyR\x06amount\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12'\n" +
+	"\x0fidempotency_key\x18\x04 \x01(\tR\x0eidempotencyKey\x12&\n" +
+	"\x0fto_store_credit\x18\x05 \x01(\bR\rtoStoreCredit\"\xeb\x01\n" +
+	"\x15RefundPaymentResponse\x126\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x14.payment.TransactionR\vtransaction\x12+\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x15.payment.PaymentStateR\x05state\x124\n" +
+	"\x0erefunded_total\x18\x03 \x01(\v2\r.common.MoneyR\rrefundedTotal\x127\n" +
+	"\fstore_credit\x18\x04 \x01(\v2\x14.payment.TransactionR\vstoreCredit\"4\n" +
+	"\x17ListTransactionsRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"T\n" +
+	"\x18ListTransactionsResponse\x128\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x14.payment.TransactionR\ftransactions\"\x93\x02\n" +
+	"\rSettlementRun\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\x03R\x05runId\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x1f\n" +
+	"\vperiod_from\x18\x03 \x01(\tR\n" +
+	"periodFrom\x12\x1b\n" +
+	"\tperiod_to\x18\x04 \x01(\tR\bperiodTo\x12\x14\n" +
+	"\x05lines\x18\x05 \x01(\x05R\x05lines\x12\x18\n" +
+	"\amatched\x18\x06 \x01(\x05R\amatched\x12$\n" +
+	"\rdiscrepancies\x18\a \x01(\x05R\rdiscrepancies\x12!\n" +
+	"\fcreated_unix\x18\b \x01(\x03R\vcreatedUnix\x12\x1c\n" +
+	"\tduplicate\x18\t \x01(\bR\tduplicate\"\x81\x01\n" +
+	"\x17ImportSettlementRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x10\n" +
+	"\x03csv\x18\x02 \x01(\fR\x03csv\x12\x1f\n" +
+	"\vperiod_from\x18\x03 \x01(\tR\n" +
+	"periodFrom\x12\x1b\n" +
+	"\tperiod_to\x18\x04 \x01(\tR\bperiodTo\"\x94\x03\n" +
+	"\vDiscrepancy\x12%\n" +
+	"\x0ediscrepancy_id\x18\x01 \x01(\x03R\rdiscrepancyId\x12\x15\n" +
+	"\x06run_id\x18\x02 \x01(\x03R\x05runId\x12,\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x18.payment.DiscrepancyKindR\x04kind\x12\x12\n" +
+	"\x04date\x18\x04 \x01(\tR\x04date\x12!\n" +
+	"\fprovider_ref\x18\x05 \x01(\tR\vproviderRef\x12\x19\n" +
+	"\border_id\x18\x06 \x01(\x03R\aorderId\x12C\n" +
+	"\x10transaction_kind\x18\a \x01(\x0e2\x18.payment.TransactionKindR\x0ftransactionKind\x12)\n" +
+	"\brecorded\x18\b \x01(\v2\r.common.MoneyR\brecorded\x12'\n" +
+	"\asettled\x18\t \x01(\v2\r.common.MoneyR\asettled\x12\x12\n" +
+	"\x04note\x18\n" +
+	" \x01(\tR\x04note\x12\x1a\n" +
+	"\bresolved\x18\v \x01(\bR\bresolved\"~\n" +
+	"\x1bReconciliationReportRequest\x12\x1b\n" +
+	"\tfrom_date\x18\x01 \x01(\tR\bfromDate\x12\x17\n" +
+	"\ato_date\x18\x02 \x01(\tR\x06toDate\x12)\n" +
+	"\x10include_resolved\x18\x03 \x01(\bR\x0fincludeResolved\"\xfc\x01\n" +
+	"\x14ReconciliationReport\x12:\n" +
+	"\rdiscrepancies\x18\x01 \x03(\v2\x14.payment.DiscrepancyR\rdiscrepancies\x12\x18\n" +
+	"\amissing\x18\x02 \x01(\x05R\amissing\x12\x14\n" +
+	"\x05extra\x18\x03 \x01(\x05R\x05extra\x12'\n" +
+	"\x0famount_mismatch\x18\x04 \x01(\x05R\x0eamountMismatch\x12*\n" +
+	"\x04runs\x18\x05 \x03(\v2\x16.payment.SettlementRunR\x04runs\x12#\n" +
+	"\rrefund_failed\x18\x06 \x01(\x05R\frefundFailed\"\xe7\x01\n" +
+	"\n" +
+	"Instrument\x12#\n" +
+	"\rinstrument_id\x18\x01 \x01(\x03R\finstrumentId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x14\n" +
+	"\x05brand\x18\x03 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05last4\x18\x04 \x01(\tR\x05last4\x12\x1b\n" +
+	"\texp_month\x18\x05 \x01(\x05R\bexpMonth\x12\x19\n" +
+	"\bexp_year\x18\x06 \x01(\x05R\aexpYear\x12\x14\n" +
+	"\x05label\x18\a \x01(\tR\x05label\x12!\n" +
+	"\fcreated_unix\x18\b \x01(\x03R\vcreatedUnix\"\xc0\x01\n" +
+	"\x15SaveInstrumentRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x14\n" +
+	"\x05brand\x18\x03 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05last4\x18\x04 \x01(\tR\x05last4\x12\x1b\n" +
+	"\texp_month\x18\x05 \x01(\x05R\bexpMonth\x12\x19\n" +
+	"\bexp_year\x18\x06 \x01(\x05R\aexpYear\x12\x14\n" +
+	"\x05label\x18\a \x01(\tR\x05label\"P\n" +
+	"\x17ListInstrumentsResponse\x125\n" +
+	"\vinstruments\x18\x01 \x03(\v2\x13.payment.InstrumentR\vinstruments\"M\n" +
+	"\rInstrumentRef\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12#\n" +
+	"\rinstrument_id\x18\x02 \x01(\x03R\finstrumentId\"\xbe\x02\n" +
+	"\x11WalletTransaction\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\x03R\rtransactionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x122\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x1e.payment.WalletTransactionKindR\x04kind\x12%\n" +
+	"\x06amount\x18\x04 \x01(\v2\r.common.MoneyR\x06amount\x122\n" +
+	"\rbalance_after\x18\x05 \x01(\v2\r.common.MoneyR\fbalanceAfter\x12\x19\n" +
+	"\border_id\x18\x06 \x01(\x03R\aorderId\x12\x1c\n" +
+	"\treference\x18\a \x01(\tR\treference\x12!\n" +
+	"\fcreated_unix\x18\b \x01(\x03R\vcreatedUnix\"Q\n" +
+	"\rWalletBalance\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12'\n" +
+	"\abalance\x18\x02 \x01(\v2\r.common.MoneyR\abalance\"D\n" +
+	"\x15RedeemGiftCardRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"\x7f\n" +
+	"\x16RedeemGiftCardResponse\x12<\n" +
+	"\vtransaction\x18\x01 \x01(\v2\x1a.payment.WalletTransactionR\vtransaction\x12'\n" +
+	"\abalance\x18\x02 \x01(\v2\r.common.MoneyR\abalance\"N\n" +
+	"\x1dListWalletTransactionsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"\x89\x01\n" +
+	"\x1eListWalletTransactionsResponse\x12>\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x1a.payment.WalletTransactionR\ftransactions\x12'\n" +
+	"\abalance\x18\x02 \x01(\v2\r.common.MoneyR\abalance\"`\n" +
+	"\x14IssueGiftCardRequest\x12%\n" +
+	"\x06amount\x18\x01 \x01(\v2\r.common.MoneyR\x06amount\x12!\n" +
+	"\fexpires_unix\x18\x02 \x01(\x03R\vexpiresUnix\"\x8b\x01\n" +
+	"\bGiftCard\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12%\n" +
+	"\x06amount\x18\x02 \x01(\v2\r.common.MoneyR\x06amount\x12!\n" +
+	"\fexpires_unix\x18\x03 \x01(\x03R\vexpiresUnix\x12!\n" +
+	"\fcreated_unix\x18\x04 \x01(\x03R\vcreatedUnix\"\xb1\x03\n" +
+	"\x0eRiskAssessment\x12#\n" +
+	"\rassessment_id\x18\x01 \x01(\x03R\fassessmentId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12%\n" +
+	"\x06amount\x18\x04 \x01(\v2\r.common.MoneyR\x06amount\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x05R\x05score\x121\n" +
+	"\bdecision\x18\x06 \x01(\x0e2\x15.payment.RiskDecisionR\bdecision\x12\x18\n" +
+	"\areasons\x18\a \x03(\tR\areasons\x127\n" +
+	"\freview_state\x18\b \x01(\x0e2\x14.payment.ReviewStateR\vreviewState\x12\x1a\n" +
+	"\breviewer\x18\t \x01(\tR\breviewer\x12\x1f\n" +
+	"\vreview_note\x18\n" +
+	" \x01(\tR\n" +
+	"reviewNote\x12!\n" +
+	"\fcreated_unix\x18\v \x01(\x03R\vcreatedUnix\x12#\n" +
+	"\rreviewed_unix\x18\f \x01(\x03R\freviewedUnix\"Y\n" +
+	"\x16ListRiskReviewsRequest\x12)\n" +
+	"\x10include_resolved\x18\x01 \x01(\bR\x0fincludeResolved\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"L\n" +
+	"\x17ListRiskReviewsResponse\x121\n" +
+	"\areviews\x18\x01 \x03(\v2\x17.payment.RiskAssessmentR\areviews\"b\n" +
+	"\x15ReviewDecisionRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x1a\n" +
+	"\breviewer\x18\x02 \x01(\tR\breviewer\x12\x12\n" +
+	"\x04note\x18\x03 \x01(\tR\x04note*\x86\x02\n" +
+	"\fPaymentState\x12\x1d\n" +
+	"\x19PAYMENT_STATE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15PAYMENT_STATE_PENDING\x10\x01\x12\x1b\n" +
+	"\x17PAYMENT_STATE_SUCCEEDED\x10\x02\x12\x18\n" +
+	"\x14PAYMENT_STATE_FAILED\x10\x03\x12\x1a\n" +
+	"\x16PAYMENT_STATE_REFUNDED\x10\x04\x12$\n" +
+	" PAYMENT_STATE_PARTIALLY_REFUNDED\x10\x05\x12#\n" +
+	"\x1fPAYMENT_STATE_AWAITING_DELIVERY\x10\x06\x12\x1e\n" +
+	"\x1aPAYMENT_STATE_UNDER_REVIEW\x10\a*\x92\x01\n" +
+	"\x0fTransactionKind\x12 \n" +
+	"\x1cTRANSACTION_KIND_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17TRANSACTION_KIND_CHARGE\x10\x01\x12\x1b\n" +
+	"\x17TRANSACTION_KIND_REFUND\x10\x02\x12#\n" +
+	"\x1fTRANSACTION_KIND_PARTIAL_REFUND\x10\x03*\xb7\x01\n" +
+	"\x0fDiscrepancyKind\x12 \n" +
+	"\x1cDISCREPANCY_KIND_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18DISCREPANCY_KIND_MISSING\x10\x01\x12\x1a\n" +
+	"\x16DISCREPANCY_KIND_EXTRA\x10\x02\x12$\n" +
+	" DISCREPANCY_KIND_AMOUNT_MISMATCH\x10\x03\x12\"\n" +
+	"\x1eDISCREPANCY_KIND_REFUND_FAILED\x10\x04*\xd5\x01\n" +
+	"\x15WalletTransactionKind\x12'\n" +
+	"#WALLET_TRANSACTION_KIND_UNSPECIFIED\x10\x00\x12%\n" +
+	"!WALLET_TRANSACTION_KIND_GIFT_CARD\x10\x01\x12\"\n" +
+	"\x1eWALLET_TRANSACTION_KIND_REFUND\x10\x02\x12#\n" +
+	"\x1fWALLET_TRANSACTION_KIND_PAYMENT\x10\x03\x12#\n" +
+	"\x1fWALLET_TRANSACTION_KIND_RELEASE\x10\x04*}\n" +
+	"\fRiskDecision\x12\x1d\n" +
+	"\x19RISK_DECISION_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15RISK_DECISION_APPROVE\x10\x01\x12\x18\n" +
+	"\x14RISK_DECISION_REVIEW\x10\x02\x12\x19\n" +
+	"\x15RISK_DECISION_DECLINE\x10\x03*{\n" +
+	"\vReviewState\x12\x1c\n" +
+	"\x18REVIEW_STATE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14REVIEW_STATE_PENDING\x10\x01\x12\x19\n" +
+	"\x15REVIEW_STATE_APPROVED\x10\x02\x12\x19\n" +
+	"\x15REVIEW_STATE_REJECTED\x10\x032\xb4\f\n" +
+	"\aPayment\x12\x82\x01\n" +
+	"\x10GetPaymentStatus\x12 .payment.GetPaymentStatusRequest\x1a!.payment.GetPaymentStatusResponse\")\x82\xd3\xe4\x93\x02#\x12!/api/v1/orders/{order_id}/payment\x12N\n" +
+	"\rRefundPayment\x12\x1d.payment.RefundPaymentRequest\x1a\x1e.payment.RefundPaymentResponse\x12\x8f\x01\n" +
+	"\x10ListTransactions\x12 .payment.ListTransactionsRequest\x1a!.payment.ListTransactionsResponse\"6\x82\xd3\xe4\x93\x020\x12./api/v1/orders/{order_id}/payment/transactions\x12L\n" +
+	"\x10ImportSettlement\x12 .payment.ImportSettlementRequest\x1a\x16.payment.SettlementRun\x12^\n" +
+	"\x17GetReconciliationReport\x12$.payment.ReconciliationReportRequest\x1a\x1d.payment.ReconciliationReport\x12u\n" +
+	"\x0eSaveInstrument\x12\x1e.payment.SaveInstrumentRequest\x1a\x13.payment.Instrument\".\x82\xd3\xe4\x93\x02(:\x01*\"#/api/v1/users/{user_id}/instruments\x12q\n" +
+	"\x0fListInstruments\x12\x0f.common.UserRef\x1a .payment.ListInstrumentsResponse\"+\x82\xd3\xe4\x93\x02%\x12#/api/v1/users/{user_id}/instruments\x12t\n" +
+	"\x10DeleteInstrument\x12\x16.payment.InstrumentRef\x1a\v.common.Ack\";\x82\xd3\xe4\x93\x025*3/api/v1/users/{user_id}/instruments/{instrument_id}\x12]\n" +
+	"\n" +
+	"GetBalance\x12\x0f.common.UserRef\x1a\x16.payment.WalletBalance\"&\x82\xd3\xe4\x93\x02 \x12\x1e/api/v1/users/{user_id}/wallet\x12\x87\x01\n" +
+	"\x0eRedeemGiftCard\x12\x1e.payment.RedeemGiftCardRequest\x1a\x1f.payment.RedeemGiftCardResponse\"4\x82\xd3\xe4\x93\x02.:\x01*\")/api/v1/users/{user_id}/wallet/gift-cards\x12\x9e\x01\n" +
+	"\x16ListWalletTransactions\x12&.payment.ListWalletTransactionsRequest\x1a'.payment.ListWalletTransactionsResponse\"3\x82\xd3\xe4\x93\x02-\x12+/api/v1/users/{user_id}/wallet/transactions\x12A\n" +
+	"\rIssueGiftCard\x12\x1d.payment.IssueGiftCardRequest\x1a\x11.payment.GiftCard\x12T\n" +
+	"\x0fListRiskReviews\x12\x1f.payment.ListRiskReviewsRequest\x1a .payment.ListRiskReviewsResponse\x12H\n" +
+	"\rApproveReview\x12\x1e.payment.ReviewDecisionRequest\x1a\x17.payment.RiskAssessment\x12G\n" +
+	"\fRejectReview\x12\x1e.payment.ReviewDecisionRequest\x1a\x17.payment.RiskAssessmentB?Z=github.com/ahinestrog/mybookstore/proto/gen/payment;paymentpbb\x06proto3"
 
 var (
 	file_payment_proto_rawDescOnce sync.Once
@@ -218,22 +2560,117 @@ func file_payment_proto_rawDescGZIP() []byte {
 	return file_payment_proto_rawDescData
 }
 
-var file_payment_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_payment_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_payment_proto_goTypes = []any{
-	(PaymentState)(0),                // 0: payment.PaymentState
-	(*GetPaymentStatusRequest)(nil),  // 1: payment.GetPaymentStatusRequest
-	(*GetPaymentStatusResponse)(nil), // 2: payment.GetPaymentStatusResponse
+	(PaymentState)(0),                      // 0: payment.PaymentState
+	(TransactionKind)(0),                   // 1: payment.TransactionKind
+	(DiscrepancyKind)(0),                   // 2: payment.DiscrepancyKind
+	(WalletTransactionKind)(0),             // 3: payment.WalletTransactionKind
+	(RiskDecision)(0),                      // 4: payment.RiskDecision
+	(ReviewState)(0),                       // 5: payment.ReviewState
+	(*Transaction)(nil),                    // 6: payment.Transaction
+	(*GetPaymentStatusRequest)(nil),        // 7: payment.GetPaymentStatusRequest
+	(*GetPaymentStatusResponse)(nil),       // 8: payment.GetPaymentStatusResponse
+	(*RefundPaymentRequest)(nil),           // 9: payment.RefundPaymentRequest
+	(*RefundPaymentResponse)(nil),          // 10: payment.RefundPaymentResponse
+	(*ListTransactionsRequest)(nil),        // 11: payment.ListTransactionsRequest
+	(*ListTransactionsResponse)(nil),       // 12: payment.ListTransactionsResponse
+	(*SettlementRun)(nil),                  // 13: payment.SettlementRun
+	(*ImportSettlementRequest)(nil),        // 14: payment.ImportSettlementRequest
+	(*Discrepancy)(nil),                    // 15: payment.Discrepancy
+	(*ReconciliationReportRequest)(nil),    // 16: payment.ReconciliationReportRequest
+	(*ReconciliationReport)(nil),           // 17: payment.ReconciliationReport
+	(*Instrument)(nil),                     // 18: payment.Instrument
+	(*SaveInstrumentRequest)(nil),          // 19: payment.SaveInstrumentRequest
+	(*ListInstrumentsResponse)(nil),        // 20: payment.ListInstrumentsResponse
+	(*InstrumentRef)(nil),                  // 21: payment.InstrumentRef
+	(*WalletTransaction)(nil),              // 22: payment.WalletTransaction
+	(*WalletBalance)(nil),                  // 23: payment.WalletBalance
+	(*RedeemGiftCardRequest)(nil),          // 24: payment.RedeemGiftCardRequest
+	(*RedeemGiftCardResponse)(nil),         // 25: payment.RedeemGiftCardResponse
+	(*ListWalletTransactionsRequest)(nil),  // 26: payment.ListWalletTransactionsRequest
+	(*ListWalletTransactionsResponse)(nil), // 27: payment.ListWalletTransactionsResponse
+	(*IssueGiftCardRequest)(nil),           // 28: payment.IssueGiftCardRequest
+	(*GiftCard)(nil),                       // 29: payment.GiftCard
+	(*RiskAssessment)(nil),                 // 30: payment.RiskAssessment
+	(*ListRiskReviewsRequest)(nil),         // 31: payment.ListRiskReviewsRequest
+	(*ListRiskReviewsResponse)(nil),        // 32: payment.ListRiskReviewsResponse
+	(*ReviewDecisionRequest)(nil),          // 33: payment.ReviewDecisionRequest
+	(*common.Money)(nil),                   // 34: common.Money
+	(common.PaymentMethod)(0),              // 35: common.PaymentMethod
+	(*common.UserRef)(nil),                 // 36: common.UserRef
+	(*common.Ack)(nil),                     // 37: common.Ack
 }
 var file_payment_proto_depIdxs = []int32{
-	0, // 0: payment.GetPaymentStatusResponse.state:type_name -> payment.PaymentState
-	1, // 1: payment.Payment.GetPaymentStatus:input_type -> payment.GetPaymentStatusRequest
-	2, // 2: payment.Payment.GetPaymentStatus:output_type -> payment.GetPaymentStatusResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: payment.Transaction.kind:type_name -> payment.TransactionKind
+	34, // 1: payment.Transaction.amount:type_name -> common.Money
+	0,  // 2: payment.GetPaymentStatusResponse.state:type_name -> payment.PaymentState
+	35, // 3: payment.GetPaymentStatusResponse.method:type_name -> common.PaymentMethod
+	18, // 4: payment.GetPaymentStatusResponse.instrument:type_name -> payment.Instrument
+	34, // 5: payment.GetPaymentStatusResponse.wallet_amount:type_name -> common.Money
+	34, // 6: payment.RefundPaymentRequest.amount:type_name -> common.Money
+	6,  // 7: payment.RefundPaymentResponse.transaction:type_name -> payment.Transaction
+	0,  // 8: payment.RefundPaymentResponse.state:type_name -> payment.PaymentState
+	34, // 9: payment.RefundPaymentResponse.refunded_total:type_name -> common.Money
+	6,  // 10: payment.RefundPaymentResponse.store_credit:type_name -> payment.Transaction
+	6,  // 11: payment.ListTransactionsResponse.transactions:type_name -> payment.Transaction
+	2,  // 12: payment.Discrepancy.kind:type_name -> payment.DiscrepancyKind
+	1,  // 13: payment.Discrepancy.transaction_kind:type_name -> payment.TransactionKind
+	34, // 14: payment.Discrepancy.recorded:type_name -> common.Money
+	34, // 15: payment.Discrepancy.settled:type_name -> common.Money
+	15, // 16: payment.ReconciliationReport.discrepancies:type_name -> payment.Discrepancy
+	13, // 17: payment.ReconciliationReport.runs:type_name -> payment.SettlementRun
+	18, // 18: payment.ListInstrumentsResponse.instruments:type_name -> payment.Instrument
+	3,  // 19: payment.WalletTransaction.kind:type_name -> payment.WalletTransactionKind
+	34, // 20: payment.WalletTransaction.amount:type_name -> common.Money
+	34, // 21: payment.WalletTransaction.balance_after:type_name -> common.Money
+	34, // 22: payment.WalletBalance.balance:type_name -> common.Money
+	22, // 23: payment.RedeemGiftCardResponse.transaction:type_name -> payment.WalletTransaction
+	34, // 24: payment.RedeemGiftCardResponse.balance:type_name -> common.Money
+	22, // 25: payment.ListWalletTransactionsResponse.transactions:type_name -> payment.WalletTransaction
+	34, // 26: payment.ListWalletTransactionsResponse.balance:type_name -> common.Money
+	34, // 27: payment.IssueGiftCardRequest.amount:type_name -> common.Money
+	34, // 28: payment.GiftCard.amount:type_name -> common.Money
+	34, // 29: payment.RiskAssessment.amount:type_name -> common.Money
+	4,  // 30: payment.RiskAssessment.decision:type_name -> payment.RiskDecision
+	5,  // 31: payment.RiskAssessment.review_state:type_name -> payment.ReviewState
+	30, // 32: payment.ListRiskReviewsResponse.reviews:type_name -> payment.RiskAssessment
+	7,  // 33: payment.Payment.GetPaymentStatus:input_type -> payment.GetPaymentStatusRequest
+	9,  // 34: payment.Payment.RefundPayment:input_type -> payment.RefundPaymentRequest
+	11, // 35: payment.Payment.ListTransactions:input_type -> payment.ListTransactionsRequest
+	14, // 36: payment.Payment.ImportSettlement:input_type -> payment.ImportSettlementRequest
+	16, // 37: payment.Payment.GetReconciliationReport:input_type -> payment.ReconciliationReportRequest
+	19, // 38: payment.Payment.SaveInstrument:input_type -> payment.SaveInstrumentRequest
+	36, // 39: payment.Payment.ListInstruments:input_type -> common.UserRef
+	21, // 40: payment.Payment.DeleteInstrument:input_type -> payment.InstrumentRef
+	36, // 41: payment.Payment.GetBalance:input_type -> common.UserRef
+	24, // 42: payment.Payment.RedeemGiftCard:input_type -> payment.RedeemGiftCardRequest
+	26, // 43: payment.Payment.ListWalletTransactions:input_type -> payment.ListWalletTransactionsRequest
+	28, // 44: payment.Payment.IssueGiftCard:input_type -> payment.IssueGiftCardRequest
+	31, // 45: payment.Payment.ListRiskReviews:input_type -> payment.ListRiskReviewsRequest
+	33, // 46: payment.Payment.ApproveReview:input_type -> payment.ReviewDecisionRequest
+	33, // 47: payment.Payment.RejectReview:input_type -> payment.ReviewDecisionRequest
+	8,  // 48: payment.Payment.GetPaymentStatus:output_type -> payment.GetPaymentStatusResponse
+	10, // 49: payment.Payment.RefundPayment:output_type -> payment.RefundPaymentResponse
+	12, // 50: payment.Payment.ListTransactions:output_type -> payment.ListTransactionsResponse
+	13, // 51: payment.Payment.ImportSettlement:output_type -> payment.SettlementRun
+	17, // 52: payment.Payment.GetReconciliationReport:output_type -> payment.ReconciliationReport
+	18, // 53: payment.Payment.SaveInstrument:output_type -> payment.Instrument
+	20, // 54: payment.Payment.ListInstruments:output_type -> payment.ListInstrumentsResponse
+	37, // 55: payment.Payment.DeleteInstrument:output_type -> common.Ack
+	23, // 56: payment.Payment.GetBalance:output_type -> payment.WalletBalance
+	25, // 57: payment.Payment.RedeemGiftCard:output_type -> payment.RedeemGiftCardResponse
+	27, // 58: payment.Payment.ListWalletTransactions:output_type -> payment.ListWalletTransactionsResponse
+	29, // 59: payment.Payment.IssueGiftCard:output_type -> payment.GiftCard
+	32, // 60: payment.Payment.ListRiskReviews:output_type -> payment.ListRiskReviewsResponse
+	30, // 61: payment.Payment.ApproveReview:output_type -> payment.RiskAssessment
+	30, // 62: payment.Payment.RejectReview:output_type -> payment.RiskAssessment
+	48, // [48:63] is the sub-list for method output_type
+	33, // [33:48] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
 }
 
 func init() { file_payment_proto_init() }
@@ -246,8 +2683,8 @@ func file_payment_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_payment_proto_rawDesc), len(file_payment_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   2,
+			NumEnums:      6,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
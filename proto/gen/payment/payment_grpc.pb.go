@@ -8,6 +8,7 @@ package paymentpb
 
 import (
 	context "context"
+	common "github.com/ahinestrog/mybookstore/proto/gen/common"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,7 +20,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Payment_GetPaymentStatus_FullMethodName = "/payment.Payment/GetPaymentStatus"
+	Payment_GetPaymentStatus_FullMethodName        = "/payment.Payment/GetPaymentStatus"
+	Payment_RefundPayment_FullMethodName           = "/payment.Payment/RefundPayment"
+	Payment_ListTransactions_FullMethodName        = "/payment.Payment/ListTransactions"
+	Payment_ImportSettlement_FullMethodName        = "/payment.Payment/ImportSettlement"
+	Payment_GetReconciliationReport_FullMethodName = "/payment.Payment/GetReconciliationReport"
+	Payment_SaveInstrument_FullMethodName          = "/payment.Payment/SaveInstrument"
+	Payment_ListInstruments_FullMethodName         = "/payment.Payment/ListInstruments"
+	Payment_DeleteInstrument_FullMethodName        = "/payment.Payment/DeleteInstrument"
+	Payment_GetBalance_FullMethodName              = "/payment.Payment/GetBalance"
+	Payment_RedeemGiftCard_FullMethodName          = "/payment.Payment/RedeemGiftCard"
+	Payment_ListWalletTransactions_FullMethodName  = "/payment.Payment/ListWalletTransactions"
+	Payment_IssueGiftCard_FullMethodName           = "/payment.Payment/IssueGiftCard"
+	Payment_ListRiskReviews_FullMethodName         = "/payment.Payment/ListRiskReviews"
+	Payment_ApproveReview_FullMethodName           = "/payment.Payment/ApproveReview"
+	Payment_RejectReview_FullMethodName            = "/payment.Payment/RejectReview"
 )
 
 // PaymentClient is the client API for Payment service.
@@ -29,6 +44,40 @@ const (
 // Payment funciona por eventos; exponemos un RPC para consultar estado opcional.
 type PaymentClient interface {
 	GetPaymentStatus(ctx context.Context, in *GetPaymentStatusRequest, opts ...grpc.CallOption) (*GetPaymentStatusResponse, error)
+	// Reembolsa total o parcialmente un pago capturado. La suma de reembolsos
+	// nunca supera lo cobrado. Con idempotency_key los reintentos devuelven la
+	// transacción original.
+	RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*RefundPaymentResponse, error)
+	// Movimientos del pago (cobro y reembolsos) en orden cronológico.
+	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+	// Concilia un archivo de liquidación (CSV) de la pasarela contra el libro de
+	// transacciones. Importar dos veces el mismo archivo devuelve la corrida original.
+	ImportSettlement(ctx context.Context, in *ImportSettlementRequest, opts ...grpc.CallOption) (*SettlementRun, error)
+	// Diferencias encontradas por las conciliaciones en un rango de fechas.
+	GetReconciliationReport(ctx context.Context, in *ReconciliationReportRequest, opts ...grpc.CallOption) (*ReconciliationReport, error)
+	// Guarda una tarjeta tokenizada del usuario. Solo se conservan el token, la
+	// marca y los últimos 4 dígitos; guardar el mismo token devuelve el existente.
+	SaveInstrument(ctx context.Context, in *SaveInstrumentRequest, opts ...grpc.CallOption) (*Instrument, error)
+	// Tarjetas guardadas del usuario, de la más reciente a la más antigua.
+	ListInstruments(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*ListInstrumentsResponse, error)
+	// Elimina una tarjeta guardada; los pagos que ya la usaron no cambian.
+	DeleteInstrument(ctx context.Context, in *InstrumentRef, opts ...grpc.CallOption) (*common.Ack, error)
+	// Saldo a favor del usuario (tarjetas de regalo redimidas y reembolsos).
+	GetBalance(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*WalletBalance, error)
+	// Pasa el saldo de la tarjeta de regalo al monedero del usuario. Una tarjeta
+	// se redime una sola vez; repetir la petición del mismo usuario devuelve el
+	// movimiento original.
+	RedeemGiftCard(ctx context.Context, in *RedeemGiftCardRequest, opts ...grpc.CallOption) (*RedeemGiftCardResponse, error)
+	// Movimientos del monedero, del más reciente al más antiguo.
+	ListWalletTransactions(ctx context.Context, in *ListWalletTransactionsRequest, opts ...grpc.CallOption) (*ListWalletTransactionsResponse, error)
+	// Emite una tarjeta de regalo con código aleatorio (uso administrativo).
+	IssueGiftCard(ctx context.Context, in *IssueGiftCardRequest, opts ...grpc.CallOption) (*GiftCard, error)
+	// Pagos retenidos por el análisis de riesgo, del más antiguo al más reciente.
+	ListRiskReviews(ctx context.Context, in *ListRiskReviewsRequest, opts ...grpc.CallOption) (*ListRiskReviewsResponse, error)
+	// Aprueba un pago en revisión: sigue al cobro normal.
+	ApproveReview(ctx context.Context, in *ReviewDecisionRequest, opts ...grpc.CallOption) (*RiskAssessment, error)
+	// Rechaza un pago en revisión: el pago falla con motivo risk_rejected.
+	RejectReview(ctx context.Context, in *ReviewDecisionRequest, opts ...grpc.CallOption) (*RiskAssessment, error)
 }
 
 type paymentClient struct {
@@ -49,6 +98,146 @@ func (c *paymentClient) GetPaymentStatus(ctx context.Context, in *GetPaymentStat
 	return out, nil
 }
 
+func (c *paymentClient) RefundPayment(ctx context.Context, in *RefundPaymentRequest, opts ...grpc.CallOption) (*RefundPaymentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefundPaymentResponse)
+	err := c.cc.Invoke(ctx, Payment_RefundPayment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTransactionsResponse)
+	err := c.cc.Invoke(ctx, Payment_ListTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) ImportSettlement(ctx context.Context, in *ImportSettlementRequest, opts ...grpc.CallOption) (*SettlementRun, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SettlementRun)
+	err := c.cc.Invoke(ctx, Payment_ImportSettlement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) GetReconciliationReport(ctx context.Context, in *ReconciliationReportRequest, opts ...grpc.CallOption) (*ReconciliationReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReconciliationReport)
+	err := c.cc.Invoke(ctx, Payment_GetReconciliationReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) SaveInstrument(ctx context.Context, in *SaveInstrumentRequest, opts ...grpc.CallOption) (*Instrument, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Instrument)
+	err := c.cc.Invoke(ctx, Payment_SaveInstrument_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) ListInstruments(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*ListInstrumentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInstrumentsResponse)
+	err := c.cc.Invoke(ctx, Payment_ListInstruments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) DeleteInstrument(ctx context.Context, in *InstrumentRef, opts ...grpc.CallOption) (*common.Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Ack)
+	err := c.cc.Invoke(ctx, Payment_DeleteInstrument_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) GetBalance(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*WalletBalance, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WalletBalance)
+	err := c.cc.Invoke(ctx, Payment_GetBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) RedeemGiftCard(ctx context.Context, in *RedeemGiftCardRequest, opts ...grpc.CallOption) (*RedeemGiftCardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RedeemGiftCardResponse)
+	err := c.cc.Invoke(ctx, Payment_RedeemGiftCard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) ListWalletTransactions(ctx context.Context, in *ListWalletTransactionsRequest, opts ...grpc.CallOption) (*ListWalletTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWalletTransactionsResponse)
+	err := c.cc.Invoke(ctx, Payment_ListWalletTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) IssueGiftCard(ctx context.Context, in *IssueGiftCardRequest, opts ...grpc.CallOption) (*GiftCard, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GiftCard)
+	err := c.cc.Invoke(ctx, Payment_IssueGiftCard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) ListRiskReviews(ctx context.Context, in *ListRiskReviewsRequest, opts ...grpc.CallOption) (*ListRiskReviewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRiskReviewsResponse)
+	err := c.cc.Invoke(ctx, Payment_ListRiskReviews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) ApproveReview(ctx context.Context, in *ReviewDecisionRequest, opts ...grpc.CallOption) (*RiskAssessment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RiskAssessment)
+	err := c.cc.Invoke(ctx, Payment_ApproveReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *paymentClient) RejectReview(ctx context.Context, in *ReviewDecisionRequest, opts ...grpc.CallOption) (*RiskAssessment, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RiskAssessment)
+	err := c.cc.Invoke(ctx, Payment_RejectReview_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PaymentServer is the server API for Payment service.
 // All implementations must embed UnimplementedPaymentServer
 // for forward compatibility.
@@ -56,6 +245,40 @@ func (c *paymentClient) GetPaymentStatus(ctx context.Context, in *GetPaymentStat
 // Payment funciona por eventos; exponemos un RPC para consultar estado opcional.
 type PaymentServer interface {
 	GetPaymentStatus(context.Context, *GetPaymentStatusRequest) (*GetPaymentStatusResponse, error)
+	// Reembolsa total o parcialmente un pago capturado. La suma de reembolsos
+	// nunca supera lo cobrado. Con idempotency_key los reintentos devuelven la
+	// transacción original.
+	RefundPayment(context.Context, *RefundPaymentRequest) (*RefundPaymentResponse, error)
+	// Movimientos del pago (cobro y reembolsos) en orden cronológico.
+	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	// Concilia un archivo de liquidación (CSV) de la pasarela contra el libro de
+	// transacciones. Importar dos veces el mismo archivo devuelve la corrida original.
+	ImportSettlement(context.Context, *ImportSettlementRequest) (*SettlementRun, error)
+	// Diferencias encontradas por las conciliaciones en un rango de fechas.
+	GetReconciliationReport(context.Context, *ReconciliationReportRequest) (*ReconciliationReport, error)
+	// Guarda una tarjeta tokenizada del usuario. Solo se conservan el token, la
+	// marca y los últimos 4 dígitos; guardar el mismo token devuelve el existente.
+	SaveInstrument(context.Context, *SaveInstrumentRequest) (*Instrument, error)
+	// Tarjetas guardadas del usuario, de la más reciente a la más antigua.
+	ListInstruments(context.Context, *common.UserRef) (*ListInstrumentsResponse, error)
+	// Elimina una tarjeta guardada; los pagos que ya la usaron no cambian.
+	DeleteInstrument(context.Context, *InstrumentRef) (*common.Ack, error)
+	// Saldo a favor del usuario (tarjetas de regalo redimidas y reembolsos).
+	GetBalance(context.Context, *common.UserRef) (*WalletBalance, error)
+	// Pasa el saldo de la tarjeta de regalo al monedero del usuario. Una tarjeta
+	// se redime una sola vez; repetir la petición del mismo usuario devuelve el
+	// movimiento original.
+	RedeemGiftCard(context.Context, *RedeemGiftCardRequest) (*RedeemGiftCardResponse, error)
+	// Movimientos del monedero, del más reciente al más antiguo.
+	ListWalletTransactions(context.Context, *ListWalletTransactionsRequest) (*ListWalletTransactionsResponse, error)
+	// Emite una tarjeta de regalo con código aleatorio (uso administrativo).
+	IssueGiftCard(context.Context, *IssueGiftCardRequest) (*GiftCard, error)
+	// Pagos retenidos por el análisis de riesgo, del más antiguo al más reciente.
+	ListRiskReviews(context.Context, *ListRiskReviewsRequest) (*ListRiskReviewsResponse, error)
+	// Aprueba un pago en revisión: sigue al cobro normal.
+	ApproveReview(context.Context, *ReviewDecisionRequest) (*RiskAssessment, error)
+	// Rechaza un pago en revisión: el pago falla con motivo risk_rejected.
+	RejectReview(context.Context, *ReviewDecisionRequest) (*RiskAssessment, error)
 	mustEmbedUnimplementedPaymentServer()
 }
 
@@ -69,6 +292,48 @@ type UnimplementedPaymentServer struct{}
 func (UnimplementedPaymentServer) GetPaymentStatus(context.Context, *GetPaymentStatusRequest) (*GetPaymentStatusResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPaymentStatus not implemented")
 }
+func (UnimplementedPaymentServer) RefundPayment(context.Context, *RefundPaymentRequest) (*RefundPaymentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundPayment not implemented")
+}
+func (UnimplementedPaymentServer) ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransactions not implemented")
+}
+func (UnimplementedPaymentServer) ImportSettlement(context.Context, *ImportSettlementRequest) (*SettlementRun, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportSettlement not implemented")
+}
+func (UnimplementedPaymentServer) GetReconciliationReport(context.Context, *ReconciliationReportRequest) (*ReconciliationReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReconciliationReport not implemented")
+}
+func (UnimplementedPaymentServer) SaveInstrument(context.Context, *SaveInstrumentRequest) (*Instrument, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveInstrument not implemented")
+}
+func (UnimplementedPaymentServer) ListInstruments(context.Context, *common.UserRef) (*ListInstrumentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInstruments not implemented")
+}
+func (UnimplementedPaymentServer) DeleteInstrument(context.Context, *InstrumentRef) (*common.Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteInstrument not implemented")
+}
+func (UnimplementedPaymentServer) GetBalance(context.Context, *common.UserRef) (*WalletBalance, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedPaymentServer) RedeemGiftCard(context.Context, *RedeemGiftCardRequest) (*RedeemGiftCardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RedeemGiftCard not implemented")
+}
+func (UnimplementedPaymentServer) ListWalletTransactions(context.Context, *ListWalletTransactionsRequest) (*ListWalletTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWalletTransactions not implemented")
+}
+func (UnimplementedPaymentServer) IssueGiftCard(context.Context, *IssueGiftCardRequest) (*GiftCard, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IssueGiftCard not implemented")
+}
+func (UnimplementedPaymentServer) ListRiskReviews(context.Context, *ListRiskReviewsRequest) (*ListRiskReviewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRiskReviews not implemented")
+}
+func (UnimplementedPaymentServer) ApproveReview(context.Context, *ReviewDecisionRequest) (*RiskAssessment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveReview not implemented")
+}
+func (UnimplementedPaymentServer) RejectReview(context.Context, *ReviewDecisionRequest) (*RiskAssessment, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RejectReview not implemented")
+}
 func (UnimplementedPaymentServer) mustEmbedUnimplementedPaymentServer() {}
 func (UnimplementedPaymentServer) testEmbeddedByValue()                 {}
 
@@ -108,6 +373,258 @@ func _Payment_GetPaymentStatus_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Payment_RefundPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).RefundPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_RefundPayment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).RefundPayment(ctx, req.(*RefundPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_ListTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).ListTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_ListTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).ListTransactions(ctx, req.(*ListTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_ImportSettlement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportSettlementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).ImportSettlement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_ImportSettlement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).ImportSettlement(ctx, req.(*ImportSettlementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_GetReconciliationReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconciliationReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).GetReconciliationReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_GetReconciliationReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).GetReconciliationReport(ctx, req.(*ReconciliationReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_SaveInstrument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveInstrumentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).SaveInstrument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_SaveInstrument_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).SaveInstrument(ctx, req.(*SaveInstrumentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_ListInstruments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.UserRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).ListInstruments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_ListInstruments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).ListInstruments(ctx, req.(*common.UserRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_DeleteInstrument_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstrumentRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).DeleteInstrument(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_DeleteInstrument_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).DeleteInstrument(ctx, req.(*InstrumentRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.UserRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).GetBalance(ctx, req.(*common.UserRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_RedeemGiftCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RedeemGiftCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).RedeemGiftCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_RedeemGiftCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).RedeemGiftCard(ctx, req.(*RedeemGiftCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_ListWalletTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWalletTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).ListWalletTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_ListWalletTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).ListWalletTransactions(ctx, req.(*ListWalletTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_IssueGiftCard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssueGiftCardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).IssueGiftCard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_IssueGiftCard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).IssueGiftCard(ctx, req.(*IssueGiftCardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_ListRiskReviews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRiskReviewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).ListRiskReviews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_ListRiskReviews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).ListRiskReviews(ctx, req.(*ListRiskReviewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_ApproveReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).ApproveReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_ApproveReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).ApproveReview(ctx, req.(*ReviewDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Payment_RejectReview_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PaymentServer).RejectReview(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Payment_RejectReview_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PaymentServer).RejectReview(ctx, req.(*ReviewDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Payment_ServiceDesc is the grpc.ServiceDesc for Payment service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -119,6 +636,62 @@ var Payment_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetPaymentStatus",
 			Handler:    _Payment_GetPaymentStatus_Handler,
 		},
+		{
+			MethodName: "RefundPayment",
+			Handler:    _Payment_RefundPayment_Handler,
+		},
+		{
+			MethodName: "ListTransactions",
+			Handler:    _Payment_ListTransactions_Handler,
+		},
+		{
+			MethodName: "ImportSettlement",
+			Handler:    _Payment_ImportSettlement_Handler,
+		},
+		{
+			MethodName: "GetReconciliationReport",
+			Handler:    _Payment_GetReconciliationReport_Handler,
+		},
+		{
+			MethodName: "SaveInstrument",
+			Handler:    _Payment_SaveInstrument_Handler,
+		},
+		{
+			MethodName: "ListInstruments",
+			Handler:    _Payment_ListInstruments_Handler,
+		},
+		{
+			MethodName: "DeleteInstrument",
+			Handler:    _Payment_DeleteInstrument_Handler,
+		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _Payment_GetBalance_Handler,
+		},
+		{
+			MethodName: "RedeemGiftCard",
+			Handler:    _Payment_RedeemGiftCard_Handler,
+		},
+		{
+			MethodName: "ListWalletTransactions",
+			Handler:    _Payment_ListWalletTransactions_Handler,
+		},
+		{
+			MethodName: "IssueGiftCard",
+			Handler:    _Payment_IssueGiftCard_Handler,
+		},
+		{
+			MethodName: "ListRiskReviews",
+			Handler:    _Payment_ListRiskReviews_Handler,
+		},
+		{
+			MethodName: "ApproveReview",
+			Handler:    _Payment_ApproveReview_Handler,
+		},
+		{
+			MethodName: "RejectReview",
+			Handler:    _Payment_RejectReview_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "payment.proto",
@@ -12,9 +12,10 @@ _sym_db = _symbol_database.Default()
 
 
 import common_pb2 as common__pb2
+from google.api import annotations_pb2 as google_dot_api_dot_annotations__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\rpayment.proto\x12\x07payment\x1a\x0c\x63ommon.proto\"+\n\x17GetPaymentStatusRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\"~\n\x18GetPaymentStatusResponse\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12$\n\x05state\x18\x02 \x01(\x0e\x32\x15.payment.PaymentState\x12\x14\n\x0cprovider_ref\x18\x03 \x01(\t\x12\x14\n\x0cupdated_unix\x18\x04 \x01(\x03*\x7f\n\x0cPaymentState\x12\x1d\n\x19PAYMENT_STATE_UNSPECIFIED\x10\x00\x12\x19\n\x15PAYMENT_STATE_PENDING\x10\x01\x12\x1b\n\x17PAYMENT_STATE_SUCCEEDED\x10\x02\x12\x18\n\x14PAYMENT_STATE_FAILED\x10\x03\x32\x62\n\x07Payment\x12W\n\x10GetPaymentStatus\x12 .payment.GetPaymentStatusRequest\x1a!.payment.GetPaymentStatusResponseB?Z=github.com/ahinestrog/mybookstore/proto/gen/payment;paymentpbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\rpayment.proto\x12\x07payment\x1a\x0c\x63ommon.proto\x1a\x1cgoogle/api/annotations.proto\"\xd0\x01\n\x0bTransaction\x12\x16\n\x0etransaction_id\x18\x01 \x01(\x03\x12\x10\n\x08order_id\x18\x02 \x01(\x03\x12&\n\x04kind\x18\x03 \x01(\x0e\x32\x18.payment.TransactionKind\x12\x1d\n\x06\x61mount\x18\x04 \x01(\x0b\x32\r.common.Money\x12\x14\n\x0cprovider_ref\x18\x05 \x01(\t\x12\x0e\n\x06reason\x18\x06 \x01(\t\x12\x14\n\x0c\x63reated_unix\x18\x07 \x01(\x03\x12\x14\n\x0cstore_credit\x18\x08 \x01(\x08\"+\n\x17GetPaymentStatusRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\"\x8a\x02\n\x18GetPaymentStatusResponse\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12$\n\x05state\x18\x02 \x01(\x0e\x32\x15.payment.PaymentState\x12\x14\n\x0cprovider_ref\x18\x03 \x01(\t\x12\x14\n\x0cupdated_unix\x18\x04 \x01(\x03\x12\x14\n\x0credirect_url\x18\x05 \x01(\t\x12%\n\x06method\x18\x06 \x01(\x0e\x32\x15.common.PaymentMethod\x12\'\n\ninstrument\x18\x07 \x01(\x0b\x32\x13.payment.Instrument\x12$\n\rwallet_amount\x18\x08 \x01(\x0b\x32\r.common.Money\"\x89\x01\n\x14RefundPaymentRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12\x1d\n\x06\x61mount\x18\x02 \x01(\x0b\x32\r.common.Money\x12\x0e\n\x06reason\x18\x03 \x01(\t\x12\x17\n\x0fidempotency_key\x18\x04 \x01(\t\x12\x17\n\x0fto_store_credit\x18\x05 \x01(\x08\"\xbb\x01\n\x15RefundPaymentResponse\x12)\n\x0btransaction\x18\x01 \x01(\x0b\x32\x14.payment.Transaction\x12$\n\x05state\x18\x02 \x01(\x0e\x32\x15.payment.PaymentState\x12%\n\x0erefunded_total\x18\x03 \x01(\x0b\x32\r.common.Money\x12*\n\x0cstore_credit\x18\x04 \x01(\x0b\x32\x14.payment.Transaction\"+\n\x17ListTransactionsRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\"F\n\x18ListTransactionsResponse\x12*\n\x0ctransactions\x18\x01 \x03(\x0b\x32\x14.payment.Transaction\"\xb7\x01\n\rSettlementRun\x12\x0e\n\x06run_id\x18\x01 \x01(\x03\x12\x0e\n\x06source\x18\x02 \x01(\t\x12\x13\n\x0bperiod_from\x18\x03 \x01(\t\x12\x11\n\tperiod_to\x18\x04 \x01(\t\x12\r\n\x05lines\x18\x05 \x01(\x05\x12\x0f\n\x07matched\x18\x06 \x01(\x05\x12\x15\n\rdiscrepancies\x18\x07 \x01(\x05\x12\x14\n\x0c\x63reated_unix\x18\x08 \x01(\x03\x12\x11\n\tduplicate\x18\t \x01(\x08\"^\n\x17ImportSettlementRequest\x12\x0e\n\x06source\x18\x01 \x01(\t\x12\x0b\n\x03\x63sv\x18\x02 \x01(\x0c\x12\x13\n\x0bperiod_from\x18\x03 \x01(\t\x12\x11\n\tperiod_to\x18\x04 \x01(\t\"\xa8\x02\n\x0b\x44iscrepancy\x12\x16\n\x0e\x64iscrepancy_id\x18\x01 \x01(\x03\x12\x0e\n\x06run_id\x18\x02 \x01(\x03\x12&\n\x04kind\x18\x03 \x01(\x0e\x32\x18.payment.DiscrepancyKind\x12\x0c\n\x04\x64\x61te\x18\x04 \x01(\t\x12\x14\n\x0cprovider_ref\x18\x05 \x01(\t\x12\x10\n\x08order_id\x18\x06 \x01(\x03\x12\x32\n\x10transaction_kind\x18\x07 \x01(\x0e\x32\x18.payment.TransactionKind\x12\x1f\n\x08recorded\x18\x08 \x01(\x0b\x32\r.common.Money\x12\x1e\n\x07settled\x18\t \x01(\x0b\x32\r.common.Money\x12\x0c\n\x04note\x18\n \x01(\t\x12\x10\n\x08resolved\x18\x0b \x01(\x08\"[\n\x1bReconciliationReportRequest\x12\x11\n\tfrom_date\x18\x01 \x01(\t\x12\x0f\n\x07to_date\x18\x02 \x01(\t\x12\x18\n\x10include_resolved\x18\x03 \x01(\x08\"\xb9\x01\n\x14ReconciliationReport\x12+\n\rdiscrepancies\x18\x01 \x03(\x0b\x32\x14.payment.Discrepancy\x12\x0f\n\x07missing\x18\x02 \x01(\x05\x12\r\n\x05\x65xtra\x18\x03 \x01(\x05\x12\x17\n\x0f\x61mount_mismatch\x18\x04 \x01(\x05\x12$\n\x04runs\x18\x05 \x03(\x0b\x32\x16.payment.SettlementRun\x12\x15\n\rrefund_failed\x18\x06 \x01(\x05\"\x9c\x01\n\nInstrument\x12\x15\n\rinstrument_id\x18\x01 \x01(\x03\x12\x0f\n\x07user_id\x18\x02 \x01(\x03\x12\r\n\x05\x62rand\x18\x03 \x01(\t\x12\r\n\x05last4\x18\x04 \x01(\t\x12\x11\n\texp_month\x18\x05 \x01(\x05\x12\x10\n\x08\x65xp_year\x18\x06 \x01(\x05\x12\r\n\x05label\x18\x07 \x01(\t\x12\x14\n\x0c\x63reated_unix\x18\x08 \x01(\x03\"\x89\x01\n\x15SaveInstrumentRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\r\n\x05token\x18\x02 \x01(\t\x12\r\n\x05\x62rand\x18\x03 \x01(\t\x12\r\n\x05last4\x18\x04 \x01(\t\x12\x11\n\texp_month\x18\x05 \x01(\x05\x12\x10\n\x08\x65xp_year\x18\x06 \x01(\x05\x12\r\n\x05label\x18\x07 \x01(\t\"C\n\x17ListInstrumentsResponse\x12(\n\x0binstruments\x18\x01 \x03(\x0b\x32\x13.payment.Instrument\"7\n\rInstrumentRef\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x15\n\rinstrument_id\x18\x02 \x01(\x03\"\xea\x01\n\x11WalletTransaction\x12\x16\n\x0etransaction_id\x18\x01 \x01(\x03\x12\x0f\n\x07user_id\x18\x02 \x01(\x03\x12,\n\x04kind\x18\x03 \x01(\x0e\x32\x1e.payment.WalletTransactionKind\x12\x1d\n\x06\x61mount\x18\x04 \x01(\x0b\x32\r.common.Money\x12$\n\rbalance_after\x18\x05 \x01(\x0b\x32\r.common.Money\x12\x10\n\x08order_id\x18\x06 \x01(\x03\x12\x11\n\treference\x18\x07 \x01(\t\x12\x14\n\x0c\x63reated_unix\x18\x08 \x01(\x03\"@\n\rWalletBalance\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x1e\n\x07\x62\x61lance\x18\x02 \x01(\x0b\x32\r.common.Money\"6\n\x15RedeemGiftCardRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0c\n\x04\x63ode\x18\x02 \x01(\t\"i\n\x16RedeemGiftCardResponse\x12/\n\x0btransaction\x18\x01 \x01(\x0b\x32\x1a.payment.WalletTransaction\x12\x1e\n\x07\x62\x61lance\x18\x02 \x01(\x0b\x32\r.common.Money\"?\n\x1dListWalletTransactionsRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\r\n\x05limit\x18\x02 \x01(\x05\"r\n\x1eListWalletTransactionsResponse\x12\x30\n\x0ctransactions\x18\x01 \x03(\x0b\x32\x1a.payment.WalletTransaction\x12\x1e\n\x07\x62\x61lance\x18\x02 \x01(\x0b\x32\r.common.Money\"K\n\x14IssueGiftCardRequest\x12\x1d\n\x06\x61mount\x18\x01 \x01(\x0b\x32\r.common.Money\x12\x14\n\x0c\x65xpires_unix\x18\x02 \x01(\x03\"c\n\x08GiftCard\x12\x0c\n\x04\x63ode\x18\x01 \x01(\t\x12\x1d\n\x06\x61mount\x18\x02 \x01(\x0b\x32\r.common.Money\x12\x14\n\x0c\x65xpires_unix\x18\x03 \x01(\x03\x12\x14\n\x0c\x63reated_unix\x18\x04 \x01(\x03\"\xb2\x02\n\x0eRiskAssessment\x12\x15\n\rassessment_id\x18\x01 \x01(\x03\x12\x10\n\x08order_id\x18\x02 \x01(\x03\x12\x0f\n\x07user_id\x18\x03 \x01(\x03\x12\x1d\n\x06\x61mount\x18\x04 \x01(\x0b\x32\r.common.Money\x12\r\n\x05score\x18\x05 \x01(\x05\x12\'\n\x08\x64\x65\x63ision\x18\x06 \x01(\x0e\x32\x15.payment.RiskDecision\x12\x0f\n\x07reasons\x18\x07 \x03(\t\x12*\n\x0creview_state\x18\x08 \x01(\x0e\x32\x14.payment.ReviewState\x12\x10\n\x08reviewer\x18\t \x01(\t\x12\x13\n\x0breview_note\x18\n \x01(\t\x12\x14\n\x0c\x63reated_unix\x18\x0b \x01(\x03\x12\x15\n\rreviewed_unix\x18\x0c \x01(\x03\"A\n\x16ListRiskReviewsRequest\x12\x18\n\x10include_resolved\x18\x01 \x01(\x08\x12\r\n\x05limit\x18\x02 \x01(\x05\"C\n\x17ListRiskReviewsResponse\x12(\n\x07reviews\x18\x01 \x03(\x0b\x32\x17.payment.RiskAssessment\"I\n\x15ReviewDecisionRequest\x12\x10\n\x08order_id\x18\x01 \x01(\x03\x12\x10\n\x08reviewer\x18\x02 \x01(\t\x12\x0c\n\x04note\x18\x03 \x01(\t*\x86\x02\n\x0cPaymentState\x12\x1d\n\x19PAYMENT_STATE_UNSPECIFIED\x10\x00\x12\x19\n\x15PAYMENT_STATE_PENDING\x10\x01\x12\x1b\n\x17PAYMENT_STATE_SUCCEEDED\x10\x02\x12\x18\n\x14PAYMENT_STATE_FAILED\x10\x03\x12\x1a\n\x16PAYMENT_STATE_REFUNDED\x10\x04\x12$\n PAYMENT_STATE_PARTIALLY_REFUNDED\x10\x05\x12#\n\x1fPAYMENT_STATE_AWAITING_DELIVERY\x10\x06\x12\x1e\n\x1aPAYMENT_STATE_UNDER_REVIEW\x10\x07*\x92\x01\n\x0fTransactionKind\x12 \n\x1cTRANSACTION_KIND_UNSPECIFIED\x10\x00\x12\x1b\n\x17TRANSACTION_KIND_CHARGE\x10\x01\x12\x1b\n\x17TRANSACTION_KIND_REFUND\x10\x02\x12#\n\x1fTRANSACTION_KIND_PARTIAL_REFUND\x10\x03*\xb7\x01\n\x0f\x44iscrepancyKind\x12 \n\x1c\x44ISCREPANCY_KIND_UNSPECIFIED\x10\x00\x12\x1c\n\x18\x44ISCREPANCY_KIND_MISSING\x10\x01\x12\x1a\n\x16\x44ISCREPANCY_KIND_EXTRA\x10\x02\x12$\n DISCREPANCY_KIND_AMOUNT_MISMATCH\x10\x03\x12\"\n\x1e\x44ISCREPANCY_KIND_REFUND_FAILED\x10\x04*\xd5\x01\n\x15WalletTransactionKind\x12\'\n#WALLET_TRANSACTION_KIND_UNSPECIFIED\x10\x00\x12%\n!WALLET_TRANSACTION_KIND_GIFT_CARD\x10\x01\x12\"\n\x1eWALLET_TRANSACTION_KIND_REFUND\x10\x02\x12#\n\x1fWALLET_TRANSACTION_KIND_PAYMENT\x10\x03\x12#\n\x1fWALLET_TRANSACTION_KIND_RELEASE\x10\x04*}\n\x0cRiskDecision\x12\x1d\n\x19RISK_DECISION_UNSPECIFIED\x10\x00\x12\x19\n\x15RISK_DECISION_APPROVE\x10\x01\x12\x18\n\x14RISK_DECISION_REVIEW\x10\x02\x12\x19\n\x15RISK_DECISION_DECLINE\x10\x03*{\n\x0bReviewState\x12\x1c\n\x18REVIEW_STATE_UNSPECIFIED\x10\x00\x12\x18\n\x14REVIEW_STATE_PENDING\x10\x01\x12\x19\n\x15REVIEW_STATE_APPROVED\x10\x02\x12\x19\n\x15REVIEW_STATE_REJECTED\x10\x03\x32\xb4\x0c\n\x07Payment\x12\x82\x01\n\x10GetPaymentStatus\x12 .payment.GetPaymentStatusRequest\x1a!.payment.GetPaymentStatusResponse\")\x82\xd3\xe4\x93\x02#\x12!/api/v1/orders/{order_id}/payment\x12N\n\rRefundPayment\x12\x1d.payment.RefundPaymentRequest\x1a\x1e.payment.RefundPaymentResponse\x12\x8f\x01\n\x10ListTransactions\x12 .payment.ListTransactionsRequest\x1a!.payment.ListTransactionsResponse\"6\x82\xd3\xe4\x93\x02\x30\x12./api/v1/orders/{order_id}/payment/transactions\x12L\n\x10ImportSettlement\x12 .payment.ImportSettlementRequest\x1a\x16.payment.SettlementRun\x12^\n\x17GetReconciliationReport\x12$.payment.ReconciliationReportRequest\x1a\x1d.payment.ReconciliationReport\x12u\n\x0eSaveInstrument\x12\x1e.payment.SaveInstrumentRequest\x1a\x13.payment.Instrument\".\x82\xd3\xe4\x93\x02(:\x01*\"#/api/v1/users/{user_id}/instruments\x12q\n\x0fListInstruments\x12\x0f.common.UserRef\x1a .payment.ListInstrumentsResponse\"+\x82\xd3\xe4\x93\x02%\x12#/api/v1/users/{user_id}/instruments\x12t\n\x10\x44\x65leteInstrument\x12\x16.payment.InstrumentRef\x1a\x0b.common.Ack\";\x82\xd3\xe4\x93\x02\x35*3/api/v1/users/{user_id}/instruments/{instrument_id}\x12]\n\nGetBalance\x12\x0f.common.UserRef\x1a\x16.payment.WalletBalance\"&\x82\xd3\xe4\x93\x02 \x12\x1e/api/v1/users/{user_id}/wallet\x12\x87\x01\n\x0eRedeemGiftCard\x12\x1e.payment.RedeemGiftCardRequest\x1a\x1f.payment.RedeemGiftCardResponse\"4\x82\xd3\xe4\x93\x02.:\x01*\")/api/v1/users/{user_id}/wallet/gift-cards\x12\x9e\x01\n\x16ListWalletTransactions\x12&.payment.ListWalletTransactionsRequest\x1a\'.payment.ListWalletTransactionsResponse\"3\x82\xd3\xe4\x93\x02-\x12+/api/v1/users/{user_id}/wallet/transactions\x12\x41\n\rIssueGiftCard\x12\x1d.payment.IssueGiftCardRequest\x1a\x11.payment.GiftCard\x12T\n\x0fListRiskReviews\x12\x1f.payment.ListRiskReviewsRequest\x1a .payment.ListRiskReviewsResponse\x12H\n\rApproveReview\x12\x1e.payment.ReviewDecisionRequest\x1a\x17.payment.RiskAssessment\x12G\n\x0cRejectReview\x12\x1e.payment.ReviewDecisionRequest\x1a\x17.payment.RiskAssessmentB?Z=github.com/ahinestrog/mybookstore/proto/gen/payment;paymentpbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -22,12 +23,90 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'payment_pb2', _globals)
 if _descriptor._USE_C_DESCRIPTORS == False:
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z=github.com/ahinestrog/mybookstore/proto/gen/payment;paymentpb'
-  _globals['_PAYMENTSTATE']._serialized_start=213
-  _globals['_PAYMENTSTATE']._serialized_end=340
-  _globals['_GETPAYMENTSTATUSREQUEST']._serialized_start=40
-  _globals['_GETPAYMENTSTATUSREQUEST']._serialized_end=83
-  _globals['_GETPAYMENTSTATUSRESPONSE']._serialized_start=85
-  _globals['_GETPAYMENTSTATUSRESPONSE']._serialized_end=211
-  _globals['_PAYMENT']._serialized_start=342
-  _globals['_PAYMENT']._serialized_end=440
+  _globals['_PAYMENT'].methods_by_name['GetPaymentStatus']._options = None
+  _globals['_PAYMENT'].methods_by_name['GetPaymentStatus']._serialized_options = b'\x82\xd3\xe4\x93\x02#\x12!/api/v1/orders/{order_id}/payment'
+  _globals['_PAYMENT'].methods_by_name['ListTransactions']._options = None
+  _globals['_PAYMENT'].methods_by_name['ListTransactions']._serialized_options = b'\x82\xd3\xe4\x93\x02\x30\x12./api/v1/orders/{order_id}/payment/transactions'
+  _globals['_PAYMENT'].methods_by_name['SaveInstrument']._options = None
+  _globals['_PAYMENT'].methods_by_name['SaveInstrument']._serialized_options = b'\x82\xd3\xe4\x93\x02(:\x01*\"#/api/v1/users/{user_id}/instruments'
+  _globals['_PAYMENT'].methods_by_name['ListInstruments']._options = None
+  _globals['_PAYMENT'].methods_by_name['ListInstruments']._serialized_options = b'\x82\xd3\xe4\x93\x02%\x12#/api/v1/users/{user_id}/instruments'
+  _globals['_PAYMENT'].methods_by_name['DeleteInstrument']._options = None
+  _globals['_PAYMENT'].methods_by_name['DeleteInstrument']._serialized_options = b'\x82\xd3\xe4\x93\x02\x35*3/api/v1/users/{user_id}/instruments/{instrument_id}'
+  _globals['_PAYMENT'].methods_by_name['GetBalance']._options = None
+  _globals['_PAYMENT'].methods_by_name['GetBalance']._serialized_options = b'\x82\xd3\xe4\x93\x02 \x12\x1e/api/v1/users/{user_id}/wallet'
+  _globals['_PAYMENT'].methods_by_name['RedeemGiftCard']._options = None
+  _globals['_PAYMENT'].methods_by_name['RedeemGiftCard']._serialized_options = b'\x82\xd3\xe4\x93\x02.:\x01*\")/api/v1/users/{user_id}/wallet/gift-cards'
+  _globals['_PAYMENT'].methods_by_name['ListWalletTransactions']._options = None
+  _globals['_PAYMENT'].methods_by_name['ListWalletTransactions']._serialized_options = b'\x82\xd3\xe4\x93\x02-\x12+/api/v1/users/{user_id}/wallet/transactions'
+  _globals['_PAYMENTSTATE']._serialized_start=3675
+  _globals['_PAYMENTSTATE']._serialized_end=3937
+  _globals['_TRANSACTIONKIND']._serialized_start=3940
+  _globals['_TRANSACTIONKIND']._serialized_end=4086
+  _globals['_DISCREPANCYKIND']._serialized_start=4089
+  _globals['_DISCREPANCYKIND']._serialized_end=4272
+  _globals['_WALLETTRANSACTIONKIND']._serialized_start=4275
+  _globals['_WALLETTRANSACTIONKIND']._serialized_end=4488
+  _globals['_RISKDECISION']._serialized_start=4490
+  _globals['_RISKDECISION']._serialized_end=4615
+  _globals['_REVIEWSTATE']._serialized_start=4617
+  _globals['_REVIEWSTATE']._serialized_end=4740
+  _globals['_TRANSACTION']._serialized_start=71
+  _globals['_TRANSACTION']._serialized_end=279
+  _globals['_GETPAYMENTSTATUSREQUEST']._serialized_start=281
+  _globals['_GETPAYMENTSTATUSREQUEST']._serialized_end=324
+  _globals['_GETPAYMENTSTATUSRESPONSE']._serialized_start=327
+  _globals['_GETPAYMENTSTATUSRESPONSE']._serialized_end=593
+  _globals['_REFUNDPAYMENTREQUEST']._serialized_start=596
+  _globals['_REFUNDPAYMENTREQUEST']._serialized_end=733
+  _globals['_REFUNDPAYMENTRESPONSE']._serialized_start=736
+  _globals['_REFUNDPAYMENTRESPONSE']._serialized_end=923
+  _globals['_LISTTRANSACTIONSREQUEST']._serialized_start=925
+  _globals['_LISTTRANSACTIONSREQUEST']._serialized_end=968
+  _globals['_LISTTRANSACTIONSRESPONSE']._serialized_start=970
+  _globals['_LISTTRANSACTIONSRESPONSE']._serialized_end=1040
+  _globals['_SETTLEMENTRUN']._serialized_start=1043
+  _globals['_SETTLEMENTRUN']._serialized_end=1226
+  _globals['_IMPORTSETTLEMENTREQUEST']._serialized_start=1228
+  _globals['_IMPORTSETTLEMENTREQUEST']._serialized_end=1322
+  _globals['_DISCREPANCY']._serialized_start=1325
+  _globals['_DISCREPANCY']._serialized_end=1621
+  _globals['_RECONCILIATIONREPORTREQUEST']._serialized_start=1623
+  _globals['_RECONCILIATIONREPORTREQUEST']._serialized_end=1714
+  _globals['_RECONCILIATIONREPORT']._serialized_start=1717
+  _globals['_RECONCILIATIONREPORT']._serialized_end=1902
+  _globals['_INSTRUMENT']._serialized_start=1905
+  _globals['_INSTRUMENT']._serialized_end=2061
+  _globals['_SAVEINSTRUMENTREQUEST']._serialized_start=2064
+  _globals['_SAVEINSTRUMENTREQUEST']._serialized_end=2201
+  _globals['_LISTINSTRUMENTSRESPONSE']._serialized_start=2203
+  _globals['_LISTINSTRUMENTSRESPONSE']._serialized_end=2270
+  _globals['_INSTRUMENTREF']._serialized_start=2272
+  _globals['_INSTRUMENTREF']._serialized_end=2327
+  _globals['_WALLETTRANSACTION']._serialized_start=2330
+  _globals['_WALLETTRANSACTION']._serialized_end=2564
+  _globals['_WALLETBALANCE']._serialized_start=2566
+  _globals['_WALLETBALANCE']._serialized_end=2630
+  _globals['_REDEEMGIFTCARDREQUEST']._serialized_start=2632
+  _globals['_REDEEMGIFTCARDREQUEST']._serialized_end=2686
+  _globals['_REDEEMGIFTCARDRESPONSE']._serialized_start=2688
+  _globals['_REDEEMGIFTCARDRESPONSE']._serialized_end=2793
+  _globals['_LISTWALLETTRANSACTIONSREQUEST']._serialized_start=2795
+  _globals['_LISTWALLETTRANSACTIONSREQUEST']._serialized_end=2858
+  _globals['_LISTWALLETTRANSACTIONSRESPONSE']._serialized_start=2860
+  _globals['_LISTWALLETTRANSACTIONSRESPONSE']._serialized_end=2974
+  _globals['_ISSUEGIFTCARDREQUEST']._serialized_start=2976
+  _globals['_ISSUEGIFTCARDREQUEST']._serialized_end=3051
+  _globals['_GIFTCARD']._serialized_start=3053
+  _globals['_GIFTCARD']._serialized_end=3152
+  _globals['_RISKASSESSMENT']._serialized_start=3155
+  _globals['_RISKASSESSMENT']._serialized_end=3461
+  _globals['_LISTRISKREVIEWSREQUEST']._serialized_start=3463
+  _globals['_LISTRISKREVIEWSREQUEST']._serialized_end=3528
+  _globals['_LISTRISKREVIEWSRESPONSE']._serialized_start=3530
+  _globals['_LISTRISKREVIEWSRESPONSE']._serialized_end=3597
+  _globals['_REVIEWDECISIONREQUEST']._serialized_start=3599
+  _globals['_REVIEWDECISIONREQUEST']._serialized_end=3672
+  _globals['_PAYMENT']._serialized_start=4743
+  _globals['_PAYMENT']._serialized_end=6331
 # @@protoc_insertion_point(module_scope)
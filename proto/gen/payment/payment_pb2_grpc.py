@@ -2,6 +2,7 @@
 """Client and server classes corresponding to protobuf-defined services."""
 import grpc
 
+import common_pb2 as common__pb2
 import payment_pb2 as payment__pb2
 
 
@@ -20,6 +21,76 @@ class PaymentStub(object):
                 request_serializer=payment__pb2.GetPaymentStatusRequest.SerializeToString,
                 response_deserializer=payment__pb2.GetPaymentStatusResponse.FromString,
                 )
+        self.RefundPayment = channel.unary_unary(
+                '/payment.Payment/RefundPayment',
+                request_serializer=payment__pb2.RefundPaymentRequest.SerializeToString,
+                response_deserializer=payment__pb2.RefundPaymentResponse.FromString,
+                )
+        self.ListTransactions = channel.unary_unary(
+                '/payment.Payment/ListTransactions',
+                request_serializer=payment__pb2.ListTransactionsRequest.SerializeToString,
+                response_deserializer=payment__pb2.ListTransactionsResponse.FromString,
+                )
+        self.ImportSettlement = channel.unary_unary(
+                '/payment.Payment/ImportSettlement',
+                request_serializer=payment__pb2.ImportSettlementRequest.SerializeToString,
+                response_deserializer=payment__pb2.SettlementRun.FromString,
+                )
+        self.GetReconciliationReport = channel.unary_unary(
+                '/payment.Payment/GetReconciliationReport',
+                request_serializer=payment__pb2.ReconciliationReportRequest.SerializeToString,
+                response_deserializer=payment__pb2.ReconciliationReport.FromString,
+                )
+        self.SaveInstrument = channel.unary_unary(
+                '/payment.Payment/SaveInstrument',
+                request_serializer=payment__pb2.SaveInstrumentRequest.SerializeToString,
+                response_deserializer=payment__pb2.Instrument.FromString,
+                )
+        self.ListInstruments = channel.unary_unary(
+                '/payment.Payment/ListInstruments',
+                request_serializer=common__pb2.UserRef.SerializeToString,
+                response_deserializer=payment__pb2.ListInstrumentsResponse.FromString,
+                )
+        self.DeleteInstrument = channel.unary_unary(
+                '/payment.Payment/DeleteInstrument',
+                request_serializer=payment__pb2.InstrumentRef.SerializeToString,
+                response_deserializer=common__pb2.Ack.FromString,
+                )
+        self.GetBalance = channel.unary_unary(
+                '/payment.Payment/GetBalance',
+                request_serializer=common__pb2.UserRef.SerializeToString,
+                response_deserializer=payment__pb2.WalletBalance.FromString,
+                )
+        self.RedeemGiftCard = channel.unary_unary(
+                '/payment.Payment/RedeemGiftCard',
+                request_serializer=payment__pb2.RedeemGiftCardRequest.SerializeToString,
+                response_deserializer=payment__pb2.RedeemGiftCardResponse.FromString,
+                )
+        self.ListWalletTransactions = channel.unary_unary(
+                '/payment.Payment/ListWalletTransactions',
+                request_serializer=payment__pb2.ListWalletTransactionsRequest.SerializeToString,
+                response_deserializer=payment__pb2.ListWalletTransactionsResponse.FromString,
+                )
+        self.IssueGiftCard = channel.unary_unary(
+                '/payment.Payment/IssueGiftCard',
+                request_serializer=payment__pb2.IssueGiftCardRequest.SerializeToString,
+                response_deserializer=payment__pb2.GiftCard.FromString,
+                )
+        self.ListRiskReviews = channel.unary_unary(
+                '/payment.Payment/ListRiskReviews',
+                request_serializer=payment__pb2.ListRiskReviewsRequest.SerializeToString,
+                response_deserializer=payment__pb2.ListRiskReviewsResponse.FromString,
+                )
+        self.ApproveReview = channel.unary_unary(
+                '/payment.Payment/ApproveReview',
+                request_serializer=payment__pb2.ReviewDecisionRequest.SerializeToString,
+                response_deserializer=payment__pb2.RiskAssessment.FromString,
+                )
+        self.RejectReview = channel.unary_unary(
+                '/payment.Payment/RejectReview',
+                request_serializer=payment__pb2.ReviewDecisionRequest.SerializeToString,
+                response_deserializer=payment__pb2.RiskAssessment.FromString,
+                )
 
 
 class PaymentServicer(object):
@@ -32,6 +103,110 @@ class PaymentServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def RefundPayment(self, request, context):
+        """Reembolsa total o parcialmente un pago capturado. La suma de reembolsos
+        nunca supera lo cobrado. Con idempotency_key los reintentos devuelven la
+        transacción original.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListTransactions(self, request, context):
+        """Movimientos del pago (cobro y reembolsos) en orden cronológico.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ImportSettlement(self, request, context):
+        """Concilia un archivo de liquidación (CSV) de la pasarela contra el libro de
+        transacciones. Importar dos veces el mismo archivo devuelve la corrida original.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetReconciliationReport(self, request, context):
+        """Diferencias encontradas por las conciliaciones en un rango de fechas.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def SaveInstrument(self, request, context):
+        """Guarda una tarjeta tokenizada del usuario. Solo se conservan el token, la
+        marca y los últimos 4 dígitos; guardar el mismo token devuelve el existente.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListInstruments(self, request, context):
+        """Tarjetas guardadas del usuario, de la más reciente a la más antigua.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteInstrument(self, request, context):
+        """Elimina una tarjeta guardada; los pagos que ya la usaron no cambian.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetBalance(self, request, context):
+        """Saldo a favor del usuario (tarjetas de regalo redimidas y reembolsos).
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RedeemGiftCard(self, request, context):
+        """Pasa el saldo de la tarjeta de regalo al monedero del usuario. Una tarjeta
+        se redime una sola vez; repetir la petición del mismo usuario devuelve el
+        movimiento original.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListWalletTransactions(self, request, context):
+        """Movimientos del monedero, del más reciente al más antiguo.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def IssueGiftCard(self, request, context):
+        """Emite una tarjeta de regalo con código aleatorio (uso administrativo).
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ListRiskReviews(self, request, context):
+        """Pagos retenidos por el análisis de riesgo, del más antiguo al más reciente.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def ApproveReview(self, request, context):
+        """Aprueba un pago en revisión: sigue al cobro normal.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RejectReview(self, request, context):
+        """Rechaza un pago en revisión: el pago falla con motivo risk_rejected.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_PaymentServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -40,6 +215,76 @@ def add_PaymentServicer_to_server(servicer, server):
                     request_deserializer=payment__pb2.GetPaymentStatusRequest.FromString,
                     response_serializer=payment__pb2.GetPaymentStatusResponse.SerializeToString,
             ),
+            'RefundPayment': grpc.unary_unary_rpc_method_handler(
+                    servicer.RefundPayment,
+                    request_deserializer=payment__pb2.RefundPaymentRequest.FromString,
+                    response_serializer=payment__pb2.RefundPaymentResponse.SerializeToString,
+            ),
+            'ListTransactions': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListTransactions,
+                    request_deserializer=payment__pb2.ListTransactionsRequest.FromString,
+                    response_serializer=payment__pb2.ListTransactionsResponse.SerializeToString,
+            ),
+            'ImportSettlement': grpc.unary_unary_rpc_method_handler(
+                    servicer.ImportSettlement,
+                    request_deserializer=payment__pb2.ImportSettlementRequest.FromString,
+                    response_serializer=payment__pb2.SettlementRun.SerializeToString,
+            ),
+            'GetReconciliationReport': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetReconciliationReport,
+                    request_deserializer=payment__pb2.ReconciliationReportRequest.FromString,
+                    response_serializer=payment__pb2.ReconciliationReport.SerializeToString,
+            ),
+            'SaveInstrument': grpc.unary_unary_rpc_method_handler(
+                    servicer.SaveInstrument,
+                    request_deserializer=payment__pb2.SaveInstrumentRequest.FromString,
+                    response_serializer=payment__pb2.Instrument.SerializeToString,
+            ),
+            'ListInstruments': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListInstruments,
+                    request_deserializer=common__pb2.UserRef.FromString,
+                    response_serializer=payment__pb2.ListInstrumentsResponse.SerializeToString,
+            ),
+            'DeleteInstrument': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteInstrument,
+                    request_deserializer=payment__pb2.InstrumentRef.FromString,
+                    response_serializer=common__pb2.Ack.SerializeToString,
+            ),
+            'GetBalance': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetBalance,
+                    request_deserializer=common__pb2.UserRef.FromString,
+                    response_serializer=payment__pb2.WalletBalance.SerializeToString,
+            ),
+            'RedeemGiftCard': grpc.unary_unary_rpc_method_handler(
+                    servicer.RedeemGiftCard,
+                    request_deserializer=payment__pb2.RedeemGiftCardRequest.FromString,
+                    response_serializer=payment__pb2.RedeemGiftCardResponse.SerializeToString,
+            ),
+            'ListWalletTransactions': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListWalletTransactions,
+                    request_deserializer=payment__pb2.ListWalletTransactionsRequest.FromString,
+                    response_serializer=payment__pb2.ListWalletTransactionsResponse.SerializeToString,
+            ),
+            'IssueGiftCard': grpc.unary_unary_rpc_method_handler(
+                    servicer.IssueGiftCard,
+                    request_deserializer=payment__pb2.IssueGiftCardRequest.FromString,
+                    response_serializer=payment__pb2.GiftCard.SerializeToString,
+            ),
+            'ListRiskReviews': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListRiskReviews,
+                    request_deserializer=payment__pb2.ListRiskReviewsRequest.FromString,
+                    response_serializer=payment__pb2.ListRiskReviewsResponse.SerializeToString,
+            ),
+            'ApproveReview': grpc.unary_unary_rpc_method_handler(
+                    servicer.ApproveReview,
+                    request_deserializer=payment__pb2.ReviewDecisionRequest.FromString,
+                    response_serializer=payment__pb2.RiskAssessment.SerializeToString,
+            ),
+            'RejectReview': grpc.unary_unary_rpc_method_handler(
+                    servicer.RejectReview,
+                    request_deserializer=payment__pb2.ReviewDecisionRequest.FromString,
+                    response_serializer=payment__pb2.RiskAssessment.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'payment.Payment', rpc_method_handlers)
@@ -67,3 +312,241 @@ class Payment(object):
             payment__pb2.GetPaymentStatusResponse.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RefundPayment(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/RefundPayment',
+            payment__pb2.RefundPaymentRequest.SerializeToString,
+            payment__pb2.RefundPaymentResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListTransactions(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/ListTransactions',
+            payment__pb2.ListTransactionsRequest.SerializeToString,
+            payment__pb2.ListTransactionsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ImportSettlement(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/ImportSettlement',
+            payment__pb2.ImportSettlementRequest.SerializeToString,
+            payment__pb2.SettlementRun.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetReconciliationReport(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/GetReconciliationReport',
+            payment__pb2.ReconciliationReportRequest.SerializeToString,
+            payment__pb2.ReconciliationReport.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def SaveInstrument(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/SaveInstrument',
+            payment__pb2.SaveInstrumentRequest.SerializeToString,
+            payment__pb2.Instrument.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListInstruments(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/ListInstruments',
+            common__pb2.UserRef.SerializeToString,
+            payment__pb2.ListInstrumentsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DeleteInstrument(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/DeleteInstrument',
+            payment__pb2.InstrumentRef.SerializeToString,
+            common__pb2.Ack.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetBalance(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/GetBalance',
+            common__pb2.UserRef.SerializeToString,
+            payment__pb2.WalletBalance.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RedeemGiftCard(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/RedeemGiftCard',
+            payment__pb2.RedeemGiftCardRequest.SerializeToString,
+            payment__pb2.RedeemGiftCardResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListWalletTransactions(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/ListWalletTransactions',
+            payment__pb2.ListWalletTransactionsRequest.SerializeToString,
+            payment__pb2.ListWalletTransactionsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def IssueGiftCard(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/IssueGiftCard',
+            payment__pb2.IssueGiftCardRequest.SerializeToString,
+            payment__pb2.GiftCard.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListRiskReviews(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/ListRiskReviews',
+            payment__pb2.ListRiskReviewsRequest.SerializeToString,
+            payment__pb2.ListRiskReviewsResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ApproveReview(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/ApproveReview',
+            payment__pb2.ReviewDecisionRequest.SerializeToString,
+            payment__pb2.RiskAssessment.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RejectReview(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/payment.Payment/RejectReview',
+            payment__pb2.ReviewDecisionRequest.SerializeToString,
+            payment__pb2.RiskAssessment.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
@@ -8,6 +8,7 @@ package userpb
 
 import (
 	common "github.com/ahinestrog/mybookstore/proto/gen/common"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -342,12 +343,476 @@ func (x *UpdateNameRequest) GetName() string {
 	return ""
 }
 
+type SavedAddress struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AddressId     int64                  `protobuf:"varint,1,opt,name=address_id,json=addressId,proto3" json:"address_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Label         string                 `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"` // p.ej. "Casa", "Oficina"
+	Address       *common.Address        `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	IsDefault     bool                   `protobuf:"varint,5,opt,name=is_default,json=isDefault,proto3" json:"is_default,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SavedAddress) Reset() {
+	*x = SavedAddress{}
+	mi := &file_user_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SavedAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SavedAddress) ProtoMessage() {}
+
+func (x *SavedAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SavedAddress.ProtoReflect.Descriptor instead.
+func (*SavedAddress) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SavedAddress) GetAddressId() int64 {
+	if x != nil {
+		return x.AddressId
+	}
+	return 0
+}
+
+func (x *SavedAddress) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SavedAddress) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *SavedAddress) GetAddress() *common.Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *SavedAddress) GetIsDefault() bool {
+	if x != nil {
+		return x.IsDefault
+	}
+	return false
+}
+
+type AddressRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	AddressId     int64                  `protobuf:"varint,2,opt,name=address_id,json=addressId,proto3" json:"address_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddressRef) Reset() {
+	*x = AddressRef{}
+	mi := &file_user_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddressRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddressRef) ProtoMessage() {}
+
+func (x *AddressRef) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddressRef.ProtoReflect.Descriptor instead.
+func (*AddressRef) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AddressRef) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *AddressRef) GetAddressId() int64 {
+	if x != nil {
+		return x.AddressId
+	}
+	return 0
+}
+
+type AddAddressRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Label         string                 `protobuf:"bytes,2,opt,name=label,proto3" json:"label,omitempty"`
+	Address       *common.Address        `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	MakeDefault   bool                   `protobuf:"varint,4,opt,name=make_default,json=makeDefault,proto3" json:"make_default,omitempty"` // la primera dirección siempre queda por defecto
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddAddressRequest) Reset() {
+	*x = AddAddressRequest{}
+	mi := &file_user_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddAddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddAddressRequest) ProtoMessage() {}
+
+func (x *AddAddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddAddressRequest.ProtoReflect.Descriptor instead.
+func (*AddAddressRequest) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AddAddressRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *AddAddressRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *AddAddressRequest) GetAddress() *common.Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *AddAddressRequest) GetMakeDefault() bool {
+	if x != nil {
+		return x.MakeDefault
+	}
+	return false
+}
+
+type ListAddressesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addresses     []*SavedAddress        `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAddressesResponse) Reset() {
+	*x = ListAddressesResponse{}
+	mi := &file_user_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAddressesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAddressesResponse) ProtoMessage() {}
+
+func (x *ListAddressesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAddressesResponse.ProtoReflect.Descriptor instead.
+func (*ListAddressesResponse) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListAddressesResponse) GetAddresses() []*SavedAddress {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+type CreateSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSessionRequest) Reset() {
+	*x = CreateSessionRequest{}
+	mi := &file_user_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSessionRequest) ProtoMessage() {}
+
+func (x *CreateSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSessionRequest.ProtoReflect.Descriptor instead.
+func (*CreateSessionRequest) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateSessionRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *CreateSessionRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type RotateSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // vigencia de la sesión nueva
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateSessionRequest) Reset() {
+	*x = RotateSessionRequest{}
+	mi := &file_user_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateSessionRequest) ProtoMessage() {}
+
+func (x *RotateSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateSessionRequest.ProtoReflect.Descriptor instead.
+func (*RotateSessionRequest) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RotateSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *RotateSessionRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type SessionRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionRef) Reset() {
+	*x = SessionRef{}
+	mi := &file_user_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionRef) ProtoMessage() {}
+
+func (x *SessionRef) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionRef.ProtoReflect.Descriptor instead.
+func (*SessionRef) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SessionRef) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type Session struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"` // nombre mostrado del usuario
+	CreatedUnix   int64                  `protobuf:"varint,4,opt,name=created_unix,json=createdUnix,proto3" json:"created_unix,omitempty"`
+	ExpiresUnix   int64                  `protobuf:"varint,5,opt,name=expires_unix,json=expiresUnix,proto3" json:"expires_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_user_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_user_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_user_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Session) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *Session) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Session) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Session) GetCreatedUnix() int64 {
+	if x != nil {
+		return x.CreatedUnix
+	}
+	return 0
+}
+
+func (x *Session) GetExpiresUnix() int64 {
+	if x != nil {
+		return x.ExpiresUnix
+	}
+	return 0
+}
+
 var File_user_proto protoreflect.FileDescriptor
 
 const file_user_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"user.proto\x12\x04user\x1a\fcommon.proto\"W\n" +
+	"user.proto\x12\x04user\x1a\fcommon.proto\x1a\x1cgoogle/api/annotations.proto\"W\n" +
 	"\x0fRegisterRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1a\n" +
@@ -366,14 +831,65 @@ const file_user_proto_rawDesc = "" +
 	"\x05email\x18\x03 \x01(\tR\x05email\"@\n" +
 	"\x11UpdateNameRequest\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name2\xf4\x01\n" +
-	"\x04User\x129\n" +
-	"\bRegister\x12\x15.user.RegisterRequest\x1a\x16.user.RegisterResponse\x12E\n" +
-	"\fAuthenticate\x12\x19.user.AuthenticateRequest\x1a\x1a.user.AuthenticateResponse\x120\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\xa6\x01\n" +
+	"\fSavedAddress\x12\x1d\n" +
+	"\n" +
+	"address_id\x18\x01 \x01(\x03R\taddressId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x14\n" +
+	"\x05label\x18\x03 \x01(\tR\x05label\x12)\n" +
+	"\aaddress\x18\x04 \x01(\v2\x0f.common.AddressR\aaddress\x12\x1d\n" +
+	"\n" +
+	"is_default\x18\x05 \x01(\bR\tisDefault\"D\n" +
+	"\n" +
+	"AddressRef\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1d\n" +
+	"\n" +
+	"address_id\x18\x02 \x01(\x03R\taddressId\"\x90\x01\n" +
+	"\x11AddAddressRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x14\n" +
+	"\x05label\x18\x02 \x01(\tR\x05label\x12)\n" +
+	"\aaddress\x18\x03 \x01(\v2\x0f.common.AddressR\aaddress\x12!\n" +
+	"\fmake_default\x18\x04 \x01(\bR\vmakeDefault\"I\n" +
+	"\x15ListAddressesResponse\x120\n" +
+	"\taddresses\x18\x01 \x03(\v2\x12.user.SavedAddressR\taddresses\"P\n" +
+	"\x14CreateSessionRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\"V\n" +
+	"\x14RotateSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\"+\n" +
+	"\n" +
+	"SessionRef\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x9b\x01\n" +
+	"\aSession\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12!\n" +
+	"\fcreated_unix\x18\x04 \x01(\x03R\vcreatedUnix\x12!\n" +
+	"\fexpires_unix\x18\x05 \x01(\x03R\vexpiresUnix2\x87\b\n" +
+	"\x04User\x12S\n" +
+	"\bRegister\x12\x15.user.RegisterRequest\x1a\x16.user.RegisterResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/api/v1/users\x12E\n" +
+	"\fAuthenticate\x12\x19.user.AuthenticateRequest\x1a\x1a.user.AuthenticateResponse\x12Q\n" +
+	"\n" +
+	"GetProfile\x12\x0f.common.UserRef\x1a\x11.user.UserProfile\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/users/{user_id}\x12\\\n" +
+	"\n" +
+	"UpdateName\x12\x17.user.UpdateNameRequest\x1a\x11.user.UserProfile\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*2\x17/api/v1/users/{user_id}\x12h\n" +
+	"\rListAddresses\x12\x0f.common.UserRef\x1a\x1b.user.ListAddressesResponse\")\x82\xd3\xe4\x93\x02#\x12!/api/v1/users/{user_id}/addresses\x12j\n" +
 	"\n" +
-	"GetProfile\x12\x0f.common.UserRef\x1a\x11.user.UserProfile\x128\n" +
+	"GetAddress\x12\x10.user.AddressRef\x1a\x12.user.SavedAddress\"6\x82\xd3\xe4\x93\x020\x12./api/v1/users/{user_id}/addresses/{address_id}\x12g\n" +
 	"\n" +
-	"UpdateName\x12\x17.user.UpdateNameRequest\x1a\x11.user.UserProfileB9Z7github.com/ahinestrog/mybookstore/proto/gen/user;userpbb\x06proto3"
+	"AddAddress\x12\x17.user.AddAddressRequest\x1a\x12.user.SavedAddress\",\x82\xd3\xe4\x93\x02&:\x01*\"!/api/v1/users/{user_id}/addresses\x12f\n" +
+	"\rDeleteAddress\x12\x10.user.AddressRef\x1a\v.common.Ack\"6\x82\xd3\xe4\x93\x020*./api/v1/users/{user_id}/addresses/{address_id}\x12:\n" +
+	"\rCreateSession\x12\x1a.user.CreateSessionRequest\x1a\r.user.Session\x12/\n" +
+	"\fCheckSession\x12\x10.user.SessionRef\x1a\r.user.Session\x12:\n" +
+	"\rRotateSession\x12\x1a.user.RotateSessionRequest\x1a\r.user.Session\x12.\n" +
+	"\rRevokeSession\x12\x10.user.SessionRef\x1a\v.common.Ack\x122\n" +
+	"\x12RevokeUserSessions\x12\x0f.common.UserRef\x1a\v.common.AckB9Z7github.com/ahinestrog/mybookstore/proto/gen/user;userpbb\x06proto3"
 
 var (
 	file_user_proto_rawDescOnce sync.Once
@@ -387,30 +903,61 @@ func file_user_proto_rawDescGZIP() []byte {
 	return file_user_proto_rawDescData
 }
 
-var file_user_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_user_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_user_proto_goTypes = []any{
-	(*RegisterRequest)(nil),      // 0: user.RegisterRequest
-	(*RegisterResponse)(nil),     // 1: user.RegisterResponse
-	(*AuthenticateRequest)(nil),  // 2: user.AuthenticateRequest
-	(*AuthenticateResponse)(nil), // 3: user.AuthenticateResponse
-	(*UserProfile)(nil),          // 4: user.UserProfile
-	(*UpdateNameRequest)(nil),    // 5: user.UpdateNameRequest
-	(*common.UserRef)(nil),       // 6: common.UserRef
+	(*RegisterRequest)(nil),       // 0: user.RegisterRequest
+	(*RegisterResponse)(nil),      // 1: user.RegisterResponse
+	(*AuthenticateRequest)(nil),   // 2: user.AuthenticateRequest
+	(*AuthenticateResponse)(nil),  // 3: user.AuthenticateResponse
+	(*UserProfile)(nil),           // 4: user.UserProfile
+	(*UpdateNameRequest)(nil),     // 5: user.UpdateNameRequest
+	(*SavedAddress)(nil),          // 6: user.SavedAddress
+	(*AddressRef)(nil),            // 7: user.AddressRef
+	(*AddAddressRequest)(nil),     // 8: user.AddAddressRequest
+	(*ListAddressesResponse)(nil), // 9: user.ListAddressesResponse
+	(*CreateSessionRequest)(nil),  // 10: user.CreateSessionRequest
+	(*RotateSessionRequest)(nil),  // 11: user.RotateSessionRequest
+	(*SessionRef)(nil),            // 12: user.SessionRef
+	(*Session)(nil),               // 13: user.Session
+	(*common.Address)(nil),        // 14: common.Address
+	(*common.UserRef)(nil),        // 15: common.UserRef
+	(*common.Ack)(nil),            // 16: common.Ack
 }
 var file_user_proto_depIdxs = []int32{
-	0, // 0: user.User.Register:input_type -> user.RegisterRequest
-	2, // 1: user.User.Authenticate:input_type -> user.AuthenticateRequest
-	6, // 2: user.User.GetProfile:input_type -> common.UserRef
-	5, // 3: user.User.UpdateName:input_type -> user.UpdateNameRequest
-	1, // 4: user.User.Register:output_type -> user.RegisterResponse
-	3, // 5: user.User.Authenticate:output_type -> user.AuthenticateResponse
-	4, // 6: user.User.GetProfile:output_type -> user.UserProfile
-	4, // 7: user.User.UpdateName:output_type -> user.UserProfile
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	14, // 0: user.SavedAddress.address:type_name -> common.Address
+	14, // 1: user.AddAddressRequest.address:type_name -> common.Address
+	6,  // 2: user.ListAddressesResponse.addresses:type_name -> user.SavedAddress
+	0,  // 3: user.User.Register:input_type -> user.RegisterRequest
+	2,  // 4: user.User.Authenticate:input_type -> user.AuthenticateRequest
+	15, // 5: user.User.GetProfile:input_type -> common.UserRef
+	5,  // 6: user.User.UpdateName:input_type -> user.UpdateNameRequest
+	15, // 7: user.User.ListAddresses:input_type -> common.UserRef
+	7,  // 8: user.User.GetAddress:input_type -> user.AddressRef
+	8,  // 9: user.User.AddAddress:input_type -> user.AddAddressRequest
+	7,  // 10: user.User.DeleteAddress:input_type -> user.AddressRef
+	10, // 11: user.User.CreateSession:input_type -> user.CreateSessionRequest
+	12, // 12: user.User.CheckSession:input_type -> user.SessionRef
+	11, // 13: user.User.RotateSession:input_type -> user.RotateSessionRequest
+	12, // 14: user.User.RevokeSession:input_type -> user.SessionRef
+	15, // 15: user.User.RevokeUserSessions:input_type -> common.UserRef
+	1,  // 16: user.User.Register:output_type -> user.RegisterResponse
+	3,  // 17: user.User.Authenticate:output_type -> user.AuthenticateResponse
+	4,  // 18: user.User.GetProfile:output_type -> user.UserProfile
+	4,  // 19: user.User.UpdateName:output_type -> user.UserProfile
+	9,  // 20: user.User.ListAddresses:output_type -> user.ListAddressesResponse
+	6,  // 21: user.User.GetAddress:output_type -> user.SavedAddress
+	6,  // 22: user.User.AddAddress:output_type -> user.SavedAddress
+	16, // 23: user.User.DeleteAddress:output_type -> common.Ack
+	13, // 24: user.User.CreateSession:output_type -> user.Session
+	13, // 25: user.User.CheckSession:output_type -> user.Session
+	13, // 26: user.User.RotateSession:output_type -> user.Session
+	16, // 27: user.User.RevokeSession:output_type -> common.Ack
+	16, // 28: user.User.RevokeUserSessions:output_type -> common.Ack
+	16, // [16:29] is the sub-list for method output_type
+	3,  // [3:16] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_user_proto_init() }
@@ -424,7 +971,7 @@ func file_user_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_user_proto_rawDesc), len(file_user_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -20,10 +20,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	User_Register_FullMethodName     = "/user.User/Register"
-	User_Authenticate_FullMethodName = "/user.User/Authenticate"
-	User_GetProfile_FullMethodName   = "/user.User/GetProfile"
-	User_UpdateName_FullMethodName   = "/user.User/UpdateName"
+	User_Register_FullMethodName           = "/user.User/Register"
+	User_Authenticate_FullMethodName       = "/user.User/Authenticate"
+	User_GetProfile_FullMethodName         = "/user.User/GetProfile"
+	User_UpdateName_FullMethodName         = "/user.User/UpdateName"
+	User_ListAddresses_FullMethodName      = "/user.User/ListAddresses"
+	User_GetAddress_FullMethodName         = "/user.User/GetAddress"
+	User_AddAddress_FullMethodName         = "/user.User/AddAddress"
+	User_DeleteAddress_FullMethodName      = "/user.User/DeleteAddress"
+	User_CreateSession_FullMethodName      = "/user.User/CreateSession"
+	User_CheckSession_FullMethodName       = "/user.User/CheckSession"
+	User_RotateSession_FullMethodName      = "/user.User/RotateSession"
+	User_RevokeSession_FullMethodName      = "/user.User/RevokeSession"
+	User_RevokeUserSessions_FullMethodName = "/user.User/RevokeUserSessions"
 )
 
 // UserClient is the client API for User service.
@@ -34,12 +43,29 @@ const (
 type UserClient interface {
 	// Crea un usuario nuevo. Devuelve el ID asignado.
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
-	// Autentica por email+password. (En esta versión simple no hay JWT).
+	// Autentica por email+password. La sesión se abre aparte con CreateSession.
 	Authenticate(ctx context.Context, in *AuthenticateRequest, opts ...grpc.CallOption) (*AuthenticateResponse, error)
 	// Obtiene el perfil básico.
 	GetProfile(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*UserProfile, error)
 	// Actualiza el nombre mostrado del usuario.
 	UpdateName(ctx context.Context, in *UpdateNameRequest, opts ...grpc.CallOption) (*UserProfile, error)
+	// Libreta de direcciones del usuario (se eligen al hacer checkout).
+	ListAddresses(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*ListAddressesResponse, error)
+	GetAddress(ctx context.Context, in *AddressRef, opts ...grpc.CallOption) (*SavedAddress, error)
+	AddAddress(ctx context.Context, in *AddAddressRequest, opts ...grpc.CallOption) (*SavedAddress, error)
+	DeleteAddress(ctx context.Context, in *AddressRef, opts ...grpc.CallOption) (*common.Ack, error)
+	// Sesiones web (ver pkg/session): el frontend de usuario abre una al iniciar
+	// sesión y los demás frontends la consultan para respetar las revocaciones.
+	CreateSession(ctx context.Context, in *CreateSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	// NOT_FOUND si la sesión no existe, venció o fue revocada. Una sesión rotada
+	// hace poco devuelve su reemplazo (session_id distinto).
+	CheckSession(ctx context.Context, in *SessionRef, opts ...grpc.CallOption) (*Session, error)
+	// Reemplaza la sesión por una nueva y revoca la anterior. Rotar de nuevo la
+	// misma sesión poco después devuelve el mismo reemplazo (peticiones en paralelo).
+	RotateSession(ctx context.Context, in *RotateSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	RevokeSession(ctx context.Context, in *SessionRef, opts ...grpc.CallOption) (*common.Ack, error)
+	// Cierra todas las sesiones del usuario (p.ej. "cerrar sesión en todos los dispositivos").
+	RevokeUserSessions(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*common.Ack, error)
 }
 
 type userClient struct {
@@ -90,6 +116,96 @@ func (c *userClient) UpdateName(ctx context.Context, in *UpdateNameRequest, opts
 	return out, nil
 }
 
+func (c *userClient) ListAddresses(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*ListAddressesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAddressesResponse)
+	err := c.cc.Invoke(ctx, User_ListAddresses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) GetAddress(ctx context.Context, in *AddressRef, opts ...grpc.CallOption) (*SavedAddress, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SavedAddress)
+	err := c.cc.Invoke(ctx, User_GetAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) AddAddress(ctx context.Context, in *AddAddressRequest, opts ...grpc.CallOption) (*SavedAddress, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SavedAddress)
+	err := c.cc.Invoke(ctx, User_AddAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) DeleteAddress(ctx context.Context, in *AddressRef, opts ...grpc.CallOption) (*common.Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Ack)
+	err := c.cc.Invoke(ctx, User_DeleteAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) CreateSession(ctx context.Context, in *CreateSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, User_CreateSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) CheckSession(ctx context.Context, in *SessionRef, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, User_CheckSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RotateSession(ctx context.Context, in *RotateSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, User_RotateSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RevokeSession(ctx context.Context, in *SessionRef, opts ...grpc.CallOption) (*common.Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Ack)
+	err := c.cc.Invoke(ctx, User_RevokeSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userClient) RevokeUserSessions(ctx context.Context, in *common.UserRef, opts ...grpc.CallOption) (*common.Ack, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(common.Ack)
+	err := c.cc.Invoke(ctx, User_RevokeUserSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServer is the server API for User service.
 // All implementations must embed UnimplementedUserServer
 // for forward compatibility.
@@ -98,12 +214,29 @@ func (c *userClient) UpdateName(ctx context.Context, in *UpdateNameRequest, opts
 type UserServer interface {
 	// Crea un usuario nuevo. Devuelve el ID asignado.
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
-	// Autentica por email+password. (En esta versión simple no hay JWT).
+	// Autentica por email+password. La sesión se abre aparte con CreateSession.
 	Authenticate(context.Context, *AuthenticateRequest) (*AuthenticateResponse, error)
 	// Obtiene el perfil básico.
 	GetProfile(context.Context, *common.UserRef) (*UserProfile, error)
 	// Actualiza el nombre mostrado del usuario.
 	UpdateName(context.Context, *UpdateNameRequest) (*UserProfile, error)
+	// Libreta de direcciones del usuario (se eligen al hacer checkout).
+	ListAddresses(context.Context, *common.UserRef) (*ListAddressesResponse, error)
+	GetAddress(context.Context, *AddressRef) (*SavedAddress, error)
+	AddAddress(context.Context, *AddAddressRequest) (*SavedAddress, error)
+	DeleteAddress(context.Context, *AddressRef) (*common.Ack, error)
+	// Sesiones web (ver pkg/session): el frontend de usuario abre una al iniciar
+	// sesión y los demás frontends la consultan para respetar las revocaciones.
+	CreateSession(context.Context, *CreateSessionRequest) (*Session, error)
+	// NOT_FOUND si la sesión no existe, venció o fue revocada. Una sesión rotada
+	// hace poco devuelve su reemplazo (session_id distinto).
+	CheckSession(context.Context, *SessionRef) (*Session, error)
+	// Reemplaza la sesión por una nueva y revoca la anterior. Rotar de nuevo la
+	// misma sesión poco después devuelve el mismo reemplazo (peticiones en paralelo).
+	RotateSession(context.Context, *RotateSessionRequest) (*Session, error)
+	RevokeSession(context.Context, *SessionRef) (*common.Ack, error)
+	// Cierra todas las sesiones del usuario (p.ej. "cerrar sesión en todos los dispositivos").
+	RevokeUserSessions(context.Context, *common.UserRef) (*common.Ack, error)
 	mustEmbedUnimplementedUserServer()
 }
 
@@ -126,6 +259,33 @@ func (UnimplementedUserServer) GetProfile(context.Context, *common.UserRef) (*Us
 func (UnimplementedUserServer) UpdateName(context.Context, *UpdateNameRequest) (*UserProfile, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateName not implemented")
 }
+func (UnimplementedUserServer) ListAddresses(context.Context, *common.UserRef) (*ListAddressesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAddresses not implemented")
+}
+func (UnimplementedUserServer) GetAddress(context.Context, *AddressRef) (*SavedAddress, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAddress not implemented")
+}
+func (UnimplementedUserServer) AddAddress(context.Context, *AddAddressRequest) (*SavedAddress, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddAddress not implemented")
+}
+func (UnimplementedUserServer) DeleteAddress(context.Context, *AddressRef) (*common.Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAddress not implemented")
+}
+func (UnimplementedUserServer) CreateSession(context.Context, *CreateSessionRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSession not implemented")
+}
+func (UnimplementedUserServer) CheckSession(context.Context, *SessionRef) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckSession not implemented")
+}
+func (UnimplementedUserServer) RotateSession(context.Context, *RotateSessionRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateSession not implemented")
+}
+func (UnimplementedUserServer) RevokeSession(context.Context, *SessionRef) (*common.Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedUserServer) RevokeUserSessions(context.Context, *common.UserRef) (*common.Ack, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeUserSessions not implemented")
+}
 func (UnimplementedUserServer) mustEmbedUnimplementedUserServer() {}
 func (UnimplementedUserServer) testEmbeddedByValue()              {}
 
@@ -219,6 +379,168 @@ func _User_UpdateName_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _User_ListAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.UserRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).ListAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_ListAddresses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).ListAddresses(ctx, req.(*common.UserRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_GetAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).GetAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_GetAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).GetAddress(ctx, req.(*AddressRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_AddAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).AddAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_AddAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).AddAddress(ctx, req.(*AddAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_DeleteAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).DeleteAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_DeleteAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).DeleteAddress(ctx, req.(*AddressRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_CreateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).CreateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_CreateSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).CreateSession(ctx, req.(*CreateSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_CheckSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).CheckSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_CheckSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).CheckSession(ctx, req.(*SessionRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RotateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RotateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_RotateSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RotateSession(ctx, req.(*RotateSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RevokeSession(ctx, req.(*SessionRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _User_RevokeUserSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(common.UserRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServer).RevokeUserSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: User_RevokeUserSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServer).RevokeUserSessions(ctx, req.(*common.UserRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // User_ServiceDesc is the grpc.ServiceDesc for User service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -242,6 +564,42 @@ var User_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateName",
 			Handler:    _User_UpdateName_Handler,
 		},
+		{
+			MethodName: "ListAddresses",
+			Handler:    _User_ListAddresses_Handler,
+		},
+		{
+			MethodName: "GetAddress",
+			Handler:    _User_GetAddress_Handler,
+		},
+		{
+			MethodName: "AddAddress",
+			Handler:    _User_AddAddress_Handler,
+		},
+		{
+			MethodName: "DeleteAddress",
+			Handler:    _User_DeleteAddress_Handler,
+		},
+		{
+			MethodName: "CreateSession",
+			Handler:    _User_CreateSession_Handler,
+		},
+		{
+			MethodName: "CheckSession",
+			Handler:    _User_CheckSession_Handler,
+		},
+		{
+			MethodName: "RotateSession",
+			Handler:    _User_RotateSession_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _User_RevokeSession_Handler,
+		},
+		{
+			MethodName: "RevokeUserSessions",
+			Handler:    _User_RevokeUserSessions_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "user.proto",
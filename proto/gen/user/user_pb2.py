@@ -12,9 +12,10 @@ _sym_db = _symbol_database.Default()
 
 
 import common_pb2 as common__pb2
+from google.api import annotations_pb2 as google_dot_api_dot_annotations__pb2
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\nuser.proto\x12\x04user\x1a\x0c\x63ommon.proto\"@\n\x0fRegisterRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05\x65mail\x18\x02 \x01(\t\x12\x10\n\x08password\x18\x03 \x01(\t\"#\n\x10RegisterResponse\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\"6\n\x13\x41uthenticateRequest\x12\r\n\x05\x65mail\x18\x01 \x01(\t\x12\x10\n\x08password\x18\x02 \x01(\t\"3\n\x14\x41uthenticateResponse\x12\n\n\x02ok\x18\x01 \x01(\x08\x12\x0f\n\x07user_id\x18\x02 \x01(\x03\";\n\x0bUserProfile\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\r\n\x05\x65mail\x18\x03 \x01(\t\"2\n\x11UpdateNameRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0c\n\x04name\x18\x02 \x01(\t2\xf4\x01\n\x04User\x12\x39\n\x08Register\x12\x15.user.RegisterRequest\x1a\x16.user.RegisterResponse\x12\x45\n\x0c\x41uthenticate\x12\x19.user.AuthenticateRequest\x1a\x1a.user.AuthenticateResponse\x12\x30\n\nGetProfile\x12\x0f.common.UserRef\x1a\x11.user.UserProfile\x12\x38\n\nUpdateName\x12\x17.user.UpdateNameRequest\x1a\x11.user.UserProfileB9Z7github.com/ahinestrog/mybookstore/proto/gen/user;userpbb\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\nuser.proto\x12\x04user\x1a\x0c\x63ommon.proto\x1a\x1cgoogle/api/annotations.proto\"@\n\x0fRegisterRequest\x12\x0c\n\x04name\x18\x01 \x01(\t\x12\r\n\x05\x65mail\x18\x02 \x01(\t\x12\x10\n\x08password\x18\x03 \x01(\t\"#\n\x10RegisterResponse\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\"6\n\x13\x41uthenticateRequest\x12\r\n\x05\x65mail\x18\x01 \x01(\t\x12\x10\n\x08password\x18\x02 \x01(\t\"3\n\x14\x41uthenticateResponse\x12\n\n\x02ok\x18\x01 \x01(\x08\x12\x0f\n\x07user_id\x18\x02 \x01(\x03\";\n\x0bUserProfile\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0c\n\x04name\x18\x02 \x01(\t\x12\r\n\x05\x65mail\x18\x03 \x01(\t\"2\n\x11UpdateNameRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x0c\n\x04name\x18\x02 \x01(\t\"x\n\x0cSavedAddress\x12\x12\n\naddress_id\x18\x01 \x01(\x03\x12\x0f\n\x07user_id\x18\x02 \x01(\x03\x12\r\n\x05label\x18\x03 \x01(\t\x12 \n\x07\x61\x64\x64ress\x18\x04 \x01(\x0b\x32\x0f.common.Address\x12\x12\n\nis_default\x18\x05 \x01(\x08\"1\n\nAddressRef\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x12\n\naddress_id\x18\x02 \x01(\x03\"k\n\x11\x41\x64\x64\x41\x64\x64ressRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\r\n\x05label\x18\x02 \x01(\t\x12 \n\x07\x61\x64\x64ress\x18\x03 \x01(\x0b\x32\x0f.common.Address\x12\x14\n\x0cmake_default\x18\x04 \x01(\x08\">\n\x15ListAddressesResponse\x12%\n\taddresses\x18\x01 \x03(\x0b\x32\x12.user.SavedAddress\"<\n\x14\x43reateSessionRequest\x12\x0f\n\x07user_id\x18\x01 \x01(\x03\x12\x13\n\x0bttl_seconds\x18\x02 \x01(\x03\"?\n\x14RotateSessionRequest\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x13\n\x0bttl_seconds\x18\x02 \x01(\x03\" \n\nSessionRef\x12\x12\n\nsession_id\x18\x01 \x01(\t\"h\n\x07Session\x12\x12\n\nsession_id\x18\x01 \x01(\t\x12\x0f\n\x07user_id\x18\x02 \x01(\x03\x12\x0c\n\x04name\x18\x03 \x01(\t\x12\x14\n\x0c\x63reated_unix\x18\x04 \x01(\x03\x12\x14\n\x0c\x65xpires_unix\x18\x05 \x01(\x03\x32\x87\x08\n\x04User\x12S\n\x08Register\x12\x15.user.RegisterRequest\x1a\x16.user.RegisterResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/api/v1/users\x12\x45\n\x0c\x41uthenticate\x12\x19.user.AuthenticateRequest\x1a\x1a.user.AuthenticateResponse\x12Q\n\nGetProfile\x12\x0f.common.UserRef\x1a\x11.user.UserProfile\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/users/{user_id}\x12\\\n\nUpdateName\x12\x17.user.UpdateNameRequest\x1a\x11.user.UserProfile\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*2\x17/api/v1/users/{user_id}\x12h\n\rListAddresses\x12\x0f.common.UserRef\x1a\x1b.user.ListAddressesResponse\")\x82\xd3\xe4\x93\x02#\x12!/api/v1/users/{user_id}/addresses\x12j\n\nGetAddress\x12\x10.user.AddressRef\x1a\x12.user.SavedAddress\"6\x82\xd3\xe4\x93\x02\x30\x12./api/v1/users/{user_id}/addresses/{address_id}\x12g\n\nAddAddress\x12\x17.user.AddAddressRequest\x1a\x12.user.SavedAddress\",\x82\xd3\xe4\x93\x02&:\x01*\"!/api/v1/users/{user_id}/addresses\x12\x66\n\rDeleteAddress\x12\x10.user.AddressRef\x1a\x0b.common.Ack\"6\x82\xd3\xe4\x93\x02\x30*./api/v1/users/{user_id}/addresses/{address_id}\x12:\n\rCreateSession\x12\x1a.user.CreateSessionRequest\x1a\r.user.Session\x12/\n\x0c\x43heckSession\x12\x10.user.SessionRef\x1a\r.user.Session\x12:\n\rRotateSession\x12\x1a.user.RotateSessionRequest\x1a\r.user.Session\x12.\n\rRevokeSession\x12\x10.user.SessionRef\x1a\x0b.common.Ack\x12\x32\n\x12RevokeUserSessions\x12\x0f.common.UserRef\x1a\x0b.common.AckB9Z7github.com/ahinestrog/mybookstore/proto/gen/user;userpbb\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -22,18 +23,48 @@ _builder.BuildTopDescriptorsAndMessages(DESCRIPTOR, 'user_pb2', _globals)
 if _descriptor._USE_C_DESCRIPTORS == False:
   DESCRIPTOR._options = None
   DESCRIPTOR._serialized_options = b'Z7github.com/ahinestrog/mybookstore/proto/gen/user;userpb'
-  _globals['_REGISTERREQUEST']._serialized_start=34
-  _globals['_REGISTERREQUEST']._serialized_end=98
-  _globals['_REGISTERRESPONSE']._serialized_start=100
-  _globals['_REGISTERRESPONSE']._serialized_end=135
-  _globals['_AUTHENTICATEREQUEST']._serialized_start=137
-  _globals['_AUTHENTICATEREQUEST']._serialized_end=191
-  _globals['_AUTHENTICATERESPONSE']._serialized_start=193
-  _globals['_AUTHENTICATERESPONSE']._serialized_end=244
-  _globals['_USERPROFILE']._serialized_start=246
-  _globals['_USERPROFILE']._serialized_end=305
-  _globals['_UPDATENAMEREQUEST']._serialized_start=307
-  _globals['_UPDATENAMEREQUEST']._serialized_end=357
-  _globals['_USER']._serialized_start=360
-  _globals['_USER']._serialized_end=604
+  _globals['_USER'].methods_by_name['Register']._options = None
+  _globals['_USER'].methods_by_name['Register']._serialized_options = b'\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/api/v1/users'
+  _globals['_USER'].methods_by_name['GetProfile']._options = None
+  _globals['_USER'].methods_by_name['GetProfile']._serialized_options = b'\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/users/{user_id}'
+  _globals['_USER'].methods_by_name['UpdateName']._options = None
+  _globals['_USER'].methods_by_name['UpdateName']._serialized_options = b'\x82\xd3\xe4\x93\x02\x1c:\x01*2\x17/api/v1/users/{user_id}'
+  _globals['_USER'].methods_by_name['ListAddresses']._options = None
+  _globals['_USER'].methods_by_name['ListAddresses']._serialized_options = b'\x82\xd3\xe4\x93\x02#\x12!/api/v1/users/{user_id}/addresses'
+  _globals['_USER'].methods_by_name['GetAddress']._options = None
+  _globals['_USER'].methods_by_name['GetAddress']._serialized_options = b'\x82\xd3\xe4\x93\x02\x30\x12./api/v1/users/{user_id}/addresses/{address_id}'
+  _globals['_USER'].methods_by_name['AddAddress']._options = None
+  _globals['_USER'].methods_by_name['AddAddress']._serialized_options = b'\x82\xd3\xe4\x93\x02&:\x01*\"!/api/v1/users/{user_id}/addresses'
+  _globals['_USER'].methods_by_name['DeleteAddress']._options = None
+  _globals['_USER'].methods_by_name['DeleteAddress']._serialized_options = b'\x82\xd3\xe4\x93\x02\x30*./api/v1/users/{user_id}/addresses/{address_id}'
+  _globals['_REGISTERREQUEST']._serialized_start=64
+  _globals['_REGISTERREQUEST']._serialized_end=128
+  _globals['_REGISTERRESPONSE']._serialized_start=130
+  _globals['_REGISTERRESPONSE']._serialized_end=165
+  _globals['_AUTHENTICATEREQUEST']._serialized_start=167
+  _globals['_AUTHENTICATEREQUEST']._serialized_end=221
+  _globals['_AUTHENTICATERESPONSE']._serialized_start=223
+  _globals['_AUTHENTICATERESPONSE']._serialized_end=274
+  _globals['_USERPROFILE']._serialized_start=276
+  _globals['_USERPROFILE']._serialized_end=335
+  _globals['_UPDATENAMEREQUEST']._serialized_start=337
+  _globals['_UPDATENAMEREQUEST']._serialized_end=387
+  _globals['_SAVEDADDRESS']._serialized_start=389
+  _globals['_SAVEDADDRESS']._serialized_end=509
+  _globals['_ADDRESSREF']._serialized_start=511
+  _globals['_ADDRESSREF']._serialized_end=560
+  _globals['_ADDADDRESSREQUEST']._serialized_start=562
+  _globals['_ADDADDRESSREQUEST']._serialized_end=669
+  _globals['_LISTADDRESSESRESPONSE']._serialized_start=671
+  _globals['_LISTADDRESSESRESPONSE']._serialized_end=733
+  _globals['_CREATESESSIONREQUEST']._serialized_start=735
+  _globals['_CREATESESSIONREQUEST']._serialized_end=795
+  _globals['_ROTATESESSIONREQUEST']._serialized_start=797
+  _globals['_ROTATESESSIONREQUEST']._serialized_end=860
+  _globals['_SESSIONREF']._serialized_start=862
+  _globals['_SESSIONREF']._serialized_end=894
+  _globals['_SESSION']._serialized_start=896
+  _globals['_SESSION']._serialized_end=1000
+  _globals['_USER']._serialized_start=1003
+  _globals['_USER']._serialized_end=2034
 # @@protoc_insertion_point(module_scope)
@@ -36,6 +36,51 @@ class UserStub(object):
                 request_serializer=user__pb2.UpdateNameRequest.SerializeToString,
                 response_deserializer=user__pb2.UserProfile.FromString,
                 )
+        self.ListAddresses = channel.unary_unary(
+                '/user.User/ListAddresses',
+                request_serializer=common__pb2.UserRef.SerializeToString,
+                response_deserializer=user__pb2.ListAddressesResponse.FromString,
+                )
+        self.GetAddress = channel.unary_unary(
+                '/user.User/GetAddress',
+                request_serializer=user__pb2.AddressRef.SerializeToString,
+                response_deserializer=user__pb2.SavedAddress.FromString,
+                )
+        self.AddAddress = channel.unary_unary(
+                '/user.User/AddAddress',
+                request_serializer=user__pb2.AddAddressRequest.SerializeToString,
+                response_deserializer=user__pb2.SavedAddress.FromString,
+                )
+        self.DeleteAddress = channel.unary_unary(
+                '/user.User/DeleteAddress',
+                request_serializer=user__pb2.AddressRef.SerializeToString,
+                response_deserializer=common__pb2.Ack.FromString,
+                )
+        self.CreateSession = channel.unary_unary(
+                '/user.User/CreateSession',
+                request_serializer=user__pb2.CreateSessionRequest.SerializeToString,
+                response_deserializer=user__pb2.Session.FromString,
+                )
+        self.CheckSession = channel.unary_unary(
+                '/user.User/CheckSession',
+                request_serializer=user__pb2.SessionRef.SerializeToString,
+                response_deserializer=user__pb2.Session.FromString,
+                )
+        self.RotateSession = channel.unary_unary(
+                '/user.User/RotateSession',
+                request_serializer=user__pb2.RotateSessionRequest.SerializeToString,
+                response_deserializer=user__pb2.Session.FromString,
+                )
+        self.RevokeSession = channel.unary_unary(
+                '/user.User/RevokeSession',
+                request_serializer=user__pb2.SessionRef.SerializeToString,
+                response_deserializer=common__pb2.Ack.FromString,
+                )
+        self.RevokeUserSessions = channel.unary_unary(
+                '/user.User/RevokeUserSessions',
+                request_serializer=common__pb2.UserRef.SerializeToString,
+                response_deserializer=common__pb2.Ack.FromString,
+                )
 
 
 class UserServicer(object):
@@ -50,7 +95,7 @@ class UserServicer(object):
         raise NotImplementedError('Method not implemented!')
 
     def Authenticate(self, request, context):
-        """Autentica por email+password. (En esta versión simple no hay JWT).
+        """Autentica por email+password. La sesión se abre aparte con CreateSession.
         """
         context.set_code(grpc.StatusCode.UNIMPLEMENTED)
         context.set_details('Method not implemented!')
@@ -70,6 +115,68 @@ class UserServicer(object):
         context.set_details('Method not implemented!')
         raise NotImplementedError('Method not implemented!')
 
+    def ListAddresses(self, request, context):
+        """Libreta de direcciones del usuario (se eligen al hacer checkout).
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def GetAddress(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def AddAddress(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def DeleteAddress(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CreateSession(self, request, context):
+        """Sesiones web (ver pkg/session): el frontend de usuario abre una al iniciar
+        sesión y los demás frontends la consultan para respetar las revocaciones.
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def CheckSession(self, request, context):
+        """NOT_FOUND si la sesión no existe, venció o fue revocada. Una sesión rotada
+        hace poco devuelve su reemplazo (session_id distinto).
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RotateSession(self, request, context):
+        """Reemplaza la sesión por una nueva y revoca la anterior. Rotar de nuevo la
+        misma sesión poco después devuelve el mismo reemplazo (peticiones en paralelo).
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RevokeSession(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+    def RevokeUserSessions(self, request, context):
+        """Cierra todas las sesiones del usuario (p.ej. "cerrar sesión en todos los dispositivos").
+        """
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
 
 def add_UserServicer_to_server(servicer, server):
     rpc_method_handlers = {
@@ -93,6 +200,51 @@ def add_UserServicer_to_server(servicer, server):
                     request_deserializer=user__pb2.UpdateNameRequest.FromString,
                     response_serializer=user__pb2.UserProfile.SerializeToString,
             ),
+            'ListAddresses': grpc.unary_unary_rpc_method_handler(
+                    servicer.ListAddresses,
+                    request_deserializer=common__pb2.UserRef.FromString,
+                    response_serializer=user__pb2.ListAddressesResponse.SerializeToString,
+            ),
+            'GetAddress': grpc.unary_unary_rpc_method_handler(
+                    servicer.GetAddress,
+                    request_deserializer=user__pb2.AddressRef.FromString,
+                    response_serializer=user__pb2.SavedAddress.SerializeToString,
+            ),
+            'AddAddress': grpc.unary_unary_rpc_method_handler(
+                    servicer.AddAddress,
+                    request_deserializer=user__pb2.AddAddressRequest.FromString,
+                    response_serializer=user__pb2.SavedAddress.SerializeToString,
+            ),
+            'DeleteAddress': grpc.unary_unary_rpc_method_handler(
+                    servicer.DeleteAddress,
+                    request_deserializer=user__pb2.AddressRef.FromString,
+                    response_serializer=common__pb2.Ack.SerializeToString,
+            ),
+            'CreateSession': grpc.unary_unary_rpc_method_handler(
+                    servicer.CreateSession,
+                    request_deserializer=user__pb2.CreateSessionRequest.FromString,
+                    response_serializer=user__pb2.Session.SerializeToString,
+            ),
+            'CheckSession': grpc.unary_unary_rpc_method_handler(
+                    servicer.CheckSession,
+                    request_deserializer=user__pb2.SessionRef.FromString,
+                    response_serializer=user__pb2.Session.SerializeToString,
+            ),
+            'RotateSession': grpc.unary_unary_rpc_method_handler(
+                    servicer.RotateSession,
+                    request_deserializer=user__pb2.RotateSessionRequest.FromString,
+                    response_serializer=user__pb2.Session.SerializeToString,
+            ),
+            'RevokeSession': grpc.unary_unary_rpc_method_handler(
+                    servicer.RevokeSession,
+                    request_deserializer=user__pb2.SessionRef.FromString,
+                    response_serializer=common__pb2.Ack.SerializeToString,
+            ),
+            'RevokeUserSessions': grpc.unary_unary_rpc_method_handler(
+                    servicer.RevokeUserSessions,
+                    request_deserializer=common__pb2.UserRef.FromString,
+                    response_serializer=common__pb2.Ack.SerializeToString,
+            ),
     }
     generic_handler = grpc.method_handlers_generic_handler(
             'user.User', rpc_method_handlers)
@@ -171,3 +323,156 @@ class User(object):
             user__pb2.UserProfile.FromString,
             options, channel_credentials,
             insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def ListAddresses(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/ListAddresses',
+            common__pb2.UserRef.SerializeToString,
+            user__pb2.ListAddressesResponse.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def GetAddress(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/GetAddress',
+            user__pb2.AddressRef.SerializeToString,
+            user__pb2.SavedAddress.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def AddAddress(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/AddAddress',
+            user__pb2.AddAddressRequest.SerializeToString,
+            user__pb2.SavedAddress.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def DeleteAddress(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/DeleteAddress',
+            user__pb2.AddressRef.SerializeToString,
+            common__pb2.Ack.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CreateSession(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/CreateSession',
+            user__pb2.CreateSessionRequest.SerializeToString,
+            user__pb2.Session.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def CheckSession(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/CheckSession',
+            user__pb2.SessionRef.SerializeToString,
+            user__pb2.Session.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RotateSession(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/RotateSession',
+            user__pb2.RotateSessionRequest.SerializeToString,
+            user__pb2.Session.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RevokeSession(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/RevokeSession',
+            user__pb2.SessionRef.SerializeToString,
+            common__pb2.Ack.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
+
+    @staticmethod
+    def RevokeUserSessions(request,
+            target,
+            options=(),
+            channel_credentials=None,
+            call_credentials=None,
+            insecure=False,
+            compression=None,
+            wait_for_ready=None,
+            timeout=None,
+            metadata=None):
+        return grpc.experimental.unary_unary(request, target, '/user.User/RevokeUserSessions',
+            common__pb2.UserRef.SerializeToString,
+            common__pb2.Ack.FromString,
+            options, channel_credentials,
+            insecure, call_credentials, compression, wait_for_ready, timeout, metadata)
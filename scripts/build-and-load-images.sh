@@ -10,7 +10,6 @@ echo "🏗️  Construyendo imágenes Docker para MyBookStore..."
 
 # Lista de servicios
 BACKEND_SERVICES=("user" "catalog" "inventory" "cart" "order" "payment")
-FRONTEND_SERVICES=("user" "catalog" "inventory" "cart" "order" "payment")
 
 build_backend_image() {
     local service="$1"
@@ -22,11 +21,11 @@ build_backend_image() {
     kind load docker-image "$tag" --name "$CLUSTER_NAME"
 }
 
-build_frontend_image() {
-    local service="$1"
-    local df="$ROOT_DIR/Frontend/src/${service}/Dockerfile"
-    local tag="mybookstore-${service}-frontend:${IMAGE_TAG}"
-    echo "🔨 Construyendo imagen frontend: $service (tag: $tag)"
+# El storefront es un solo binario con todas las áreas del frontend
+build_storefront_image() {
+    local df="$ROOT_DIR/Frontend/src/storefront/Dockerfile"
+    local tag="mybookstore-storefront:${IMAGE_TAG}"
+    echo "🔨 Construyendo imagen storefront (tag: $tag)"
     docker build -t "$tag" -f "$df" "$ROOT_DIR"
     echo "📦 Cargando imagen $tag en Kind ($CLUSTER_NAME)..."
     kind load docker-image "$tag" --name "$CLUSTER_NAME"
@@ -46,10 +45,8 @@ for service in "${BACKEND_SERVICES[@]}"; do
     build_backend_image "$service"
 done
 
-echo "🏗️  Construyendo servicios frontend..."
-for service in "${FRONTEND_SERVICES[@]}"; do
-    build_frontend_image "$service"
-done
+echo "🏗️  Construyendo storefront..."
+build_storefront_image
 
 echo "✅ Todas las imágenes han sido construidas y cargadas en Kind!"
 echo
@@ -57,9 +54,7 @@ echo "📋 Imágenes disponibles:"
 for service in "${BACKEND_SERVICES[@]}"; do
     echo "   - mybookstore-${service}-backend:${IMAGE_TAG}"
 done
-for service in "${FRONTEND_SERVICES[@]}"; do
-    echo "   - mybookstore-${service}-frontend:${IMAGE_TAG}"
-done
+echo "   - mybookstore-storefront:${IMAGE_TAG}"
 
 echo
 echo "🎯 Si ya aplicaste los manifiestos, reinicia los deployments para que tomen las imágenes:"
@@ -0,0 +1,33 @@
+// devcerts genera la CA local y los certificados de mTLS para desarrollo:
+//
+//	go run ./scripts/devcerts -out ./certs
+//
+// Volver a ejecutarlo con la misma carpeta reutiliza la CA y rota los
+// certificados; los servicios los recargan solos (ver pkg/mtls).
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ahinestrog/mybookstore/pkg/mtls"
+)
+
+// names son los procesos del proyecto: los backends (también nombre DNS), el
+// storefront y "admin" para herramientas de operación como grpcurl.
+const names = "cart,catalog,inventory,order,payment,user,storefront,admin"
+
+func main() {
+	out := flag.String("out", "certs", "carpeta de salida (GRPC_TLS_DIR)")
+	only := flag.String("names", names, "certificados a generar, separados por coma")
+	validity := flag.Duration("validity", 90*24*time.Hour, "vigencia de los certificados")
+	flag.Parse()
+
+	list := strings.Split(*only, ",")
+	if err := mtls.WriteDevCerts(*out, list, *validity); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("CA y %d certificados en %s", len(list), *out)
+}
@@ -3,6 +3,8 @@ set -euo pipefail
 command -v protoc >/dev/null || { echo "Falta protoc"; exit 1; }
 command -v protoc-gen-go >/dev/null || { echo "Falta protoc-gen-go (go install google.golang.org/protobuf/cmd/protoc-gen-go@latest)"; exit 1; }
 command -v protoc-gen-go-grpc >/dev/null || { echo "Falta protoc-gen-go-grpc (go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest)"; exit 1; }
+command -v protoc-gen-grpc-gateway >/dev/null || { echo "Falta protoc-gen-grpc-gateway (go install github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-grpc-gateway@v2.22.0)"; exit 1; }
+command -v protoc-gen-openapiv2 >/dev/null || { echo "Falta protoc-gen-openapiv2 (go install github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2@v2.22.0)"; exit 1; }
 command -v python3 >/dev/null || { echo "Falta python3"; exit 1; }
 python3 -c "import grpc_tools.protoc" 2>/dev/null || { echo "Falta grpcio-tools (pip install grpcio-tools)"; exit 1; }
 
@@ -12,10 +14,15 @@ OUT_DIR="$PROTO_DIR/gen"
 
 mkdir -p "$OUT_DIR"
 
-# Generar stubs de Go
+# Generar stubs de Go, el gateway REST (/api/v1, anotaciones google.api.http) y
+# su documento OpenAPI en gen/openapi. proto/google/api trae las anotaciones.
 protoc -I "$PROTO_DIR" \
   --go_out="$OUT_DIR" --go_opt=module=github.com/ahinestrog/mybookstore/proto/gen \
   --go-grpc_out="$OUT_DIR" --go-grpc_opt=module=github.com/ahinestrog/mybookstore/proto/gen \
+  --grpc-gateway_out="$OUT_DIR" --grpc-gateway_opt=module=github.com/ahinestrog/mybookstore/proto/gen \
+  --openapiv2_out="$OUT_DIR" \
+  --openapiv2_opt=allow_merge=true,merge_file_name=openapi/mybookstore,json_names_for_fields=false,disable_default_errors=true \
+  --openapiv2_opt=openapi_configuration="$PROTO_DIR/openapi.yaml" \
   "$PROTO_DIR"/common.proto \
   "$PROTO_DIR"/catalog.proto \
   "$PROTO_DIR"/user.proto \